@@ -0,0 +1,270 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// Pump.fun联合曲线参数，PumpPortal消息中的储备已按代币精度换算
+const (
+	// curveInitialVirtualSOL 新代币曲线的虚拟SOL储备
+	curveInitialVirtualSOL = 30.0
+	// curveInitialVirtualTokens 新代币曲线的虚拟代币储备
+	curveInitialVirtualTokens = 1_073_000_000.0
+	// curveSellableTokens 曲线可售出的代币数量，全部售出后毕业
+	curveSellableTokens = 793_100_000.0
+	// curveGraduatedVirtualTokens 毕业时曲线剩余的虚拟代币储备
+	curveGraduatedVirtualTokens = curveInitialVirtualTokens - curveSellableTokens
+)
+
+const (
+	// bondingCurveRearmMargin 进度回落到阈值以下该幅度后才会再次发布即将毕业事件，避免在阈值附近反复告警
+	bondingCurveRearmMargin = 0.05
+	// bondingCurveIdleTTL 超过该时长没有成交的代币不再跟踪
+	bondingCurveIdleTTL = 24 * time.Hour
+)
+
+// GlobalBondingCurveTracker 全局联合曲线进度统计
+var GlobalBondingCurveTracker *BondingCurveTracker
+
+// curveState 单个代币的曲线状态
+type curveState struct {
+	progress models.BondingCurveProgress
+	alerted  bool // 已发布即将毕业事件，进度回落后重置
+}
+
+// BondingCurveTracker 根据PumpPortal消息中的虚拟储备跟踪Pump.fun代币的联合曲线进度
+// 进度达到 alert_progress 时发布 graduation 事件，由规则引擎和关注地址通知转为告警
+type BondingCurveTracker struct {
+	mu            sync.Mutex
+	curves        map[string]*curveState
+	alertProgress float64
+	maxMints      int
+	pipeline      *pipeline.Pipeline
+	log           *zap.Logger
+	cancel        context.CancelFunc
+}
+
+// NewBondingCurveTracker 创建联合曲线进度统计并设置为全局实例
+func NewBondingCurveTracker(config *configs.BondingCurveConfig) *BondingCurveTracker {
+	tracker := &BondingCurveTracker{
+		curves:        make(map[string]*curveState),
+		alertProgress: config.AlertProgress,
+		maxMints:      config.MaxMints,
+		log:           logger.Named("analytics.bonding_curve"),
+	}
+	GlobalBondingCurveTracker = tracker
+	return tracker
+}
+
+// Start 订阅事件管道中的PumpPortal消息
+func (t *BondingCurveTracker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.pipeline = p
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types:        []pipeline.EventType{pipeline.EventPumpPortal},
+		MessageTypes: []resp.MessageType{resp.Create, resp.Buy, resp.Sell, resp.Migrate},
+	})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.bonding_curve", func(ctx context.Context) {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					t.handleEvent(event)
+				case now := <-ticker.C:
+					t.prune(now)
+				}
+			}
+		})
+	}()
+	t.log.Info("联合曲线进度统计已启动", zap.Float64("alert_progress", t.alertProgress), zap.Int("max_mints", t.maxMints))
+}
+
+// Close 停止统计
+func (t *BondingCurveTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// handleEvent 根据创建、买卖和迁移消息更新曲线状态，进度首次达到阈值时发布即将毕业事件
+func (t *BondingCurveTracker) handleEvent(event pipeline.Event) {
+	var graduating *models.BondingCurveProgress
+	switch event.MessageType {
+	case resp.Create:
+		var token resp.NewToken
+		if err := json.Unmarshal(event.Raw, &token); err != nil {
+			return
+		}
+		graduating = t.update(token.Mint, token.BondingCurveKey, token.VSolInBondingCurve, token.VTokensInBondingCurve, token.MarketCapSol, event.Time, func(progress *models.BondingCurveProgress) {
+			progress.Name = token.Name
+			progress.Symbol = token.Symbol
+		})
+	case resp.Buy, resp.Sell:
+		var trade resp.TokenTrade
+		if err := json.Unmarshal(event.Raw, &trade); err != nil {
+			return
+		}
+		graduating = t.update(trade.Mint, trade.BondingCurveKey, trade.VSolInBondingCurve, trade.VTokensInBondingCurve, trade.MarketCapSol, event.Time, func(progress *models.BondingCurveProgress) {
+			progress.Trades++
+		})
+	case resp.Migrate:
+		var migration resp.MigrateMode
+		if err := json.Unmarshal(event.Raw, &migration); err == nil {
+			t.graduate(migration.Mint, event.Time)
+		}
+	}
+	if graduating != nil && t.pipeline != nil {
+		t.log.Info("代币即将毕业", zap.String("mint", graduating.Mint), zap.Float64("progress", graduating.Progress), zap.Float64("sol_to_graduate", graduating.SOLToGraduate))
+		t.pipeline.Publish(pipeline.Event{
+			Type:  pipeline.EventGraduation,
+			Mint:  graduating.Mint,
+			Curve: graduating,
+			Time:  event.Time,
+		})
+	}
+}
+
+// update 按消息中的虚拟储备更新曲线进度
+// 返回:
+//   - *models.BondingCurveProgress: 进度首次达到阈值时返回进度快照，否则返回nil
+func (t *BondingCurveTracker) update(mint, bondingCurve string, vSOL, vTokens, marketCap decimal.Decimal, at time.Time, apply func(*models.BondingCurveProgress)) *models.BondingCurveProgress {
+	virtualSOL, _ := vSOL.Float64()
+	virtualTokens, _ := vTokens.Float64()
+	if mint == "" || virtualSOL <= 0 || virtualTokens <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.stateLocked(mint)
+	progress := &state.progress
+	if bondingCurve != "" {
+		progress.BondingCurve = bondingCurve
+	}
+	progress.VirtualSOL = virtualSOL
+	progress.VirtualTokens = virtualTokens
+	progress.RealSOL = max(virtualSOL-curveInitialVirtualSOL, 0)
+	progress.MarketCapSOL, _ = marketCap.Float64()
+	progress.Progress = min(max((curveInitialVirtualTokens-virtualTokens)/curveSellableTokens, 0), 1)
+	progress.RemainingTokens = max(virtualTokens-curveGraduatedVirtualTokens, 0)
+	progress.SOLToGraduate = 0
+	if virtualTokens > curveGraduatedVirtualTokens {
+		// 恒定乘积曲线，毕业时的虚拟SOL储备为 k / 毕业时的虚拟代币储备
+		progress.SOLToGraduate = virtualSOL*virtualTokens/curveGraduatedVirtualTokens - virtualSOL
+	}
+	progress.UpdatedAt = at
+	apply(progress)
+
+	if progress.Graduated {
+		return nil
+	}
+	if state.alerted && progress.Progress < t.alertProgress-bondingCurveRearmMargin {
+		state.alerted = false
+	}
+	if state.alerted || progress.Progress < t.alertProgress {
+		return nil
+	}
+	state.alerted = true
+	snapshot := *progress
+	return &snapshot
+}
+
+// graduate 记录代币已迁移到AMM
+func (t *BondingCurveTracker) graduate(mint string, at time.Time) {
+	if mint == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	progress := &t.stateLocked(mint).progress
+	progress.Graduated = true
+	progress.Progress = 1
+	progress.RemainingTokens = 0
+	progress.SOLToGraduate = 0
+	progress.UpdatedAt = at
+}
+
+// stateLocked 返回代币的曲线状态，不存在时创建；超过上限时淘汰最久没有更新的代币，调用方需持有锁
+func (t *BondingCurveTracker) stateLocked(mint string) *curveState {
+	if state, ok := t.curves[mint]; ok {
+		return state
+	}
+	if t.maxMints > 0 && len(t.curves) >= t.maxMints {
+		var oldest string
+		var oldestAt time.Time
+		for candidate, state := range t.curves {
+			if oldest == "" || state.progress.UpdatedAt.Before(oldestAt) {
+				oldest, oldestAt = candidate, state.progress.UpdatedAt
+			}
+		}
+		delete(t.curves, oldest)
+	}
+	state := &curveState{progress: models.BondingCurveProgress{Mint: mint}}
+	t.curves[mint] = state
+	return state
+}
+
+// prune 清理长时间没有成交的代币
+func (t *BondingCurveTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for mint, state := range t.curves {
+		if now.Sub(state.progress.UpdatedAt) > bondingCurveIdleTTL {
+			delete(t.curves, mint)
+		}
+	}
+}
+
+// Get 返回代币的曲线进度
+func (t *BondingCurveTracker) Get(mint string) (models.BondingCurveProgress, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.curves[mint]
+	if !ok {
+		return models.BondingCurveProgress{}, false
+	}
+	return state.progress, true
+}
+
+// Top 返回未毕业且进度不低于 minProgress 的代币，按进度降序
+func (t *BondingCurveTracker) Top(minProgress float64, limit int) []models.BondingCurveProgress {
+	t.mu.Lock()
+	curves := make([]models.BondingCurveProgress, 0)
+	for _, state := range t.curves {
+		if !state.progress.Graduated && state.progress.Progress >= minProgress {
+			curves = append(curves, state.progress)
+		}
+	}
+	t.mu.Unlock()
+	slices.SortFunc(curves, func(a, b models.BondingCurveProgress) int {
+		return cmp.Or(cmp.Compare(b.Progress, a.Progress), cmp.Compare(a.Mint, b.Mint))
+	})
+	if limit > 0 && len(curves) > limit {
+		curves = curves[:limit]
+	}
+	return curves
+}
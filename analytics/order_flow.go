@@ -0,0 +1,244 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalOrderFlowTracker 全局买卖盘失衡统计
+var GlobalOrderFlowTracker *OrderFlowTracker
+
+// trade 单笔买卖
+type trade struct {
+	at     time.Time
+	buy    bool
+	volume float64
+}
+
+// OrderFlowTracker 根据swap交易和PumpPortal买卖消息统计关注代币的滚动买卖盘失衡度
+// 失衡度按成交数量加权：(买入数量-卖出数量)/(买入数量+卖出数量)
+type OrderFlowTracker struct {
+	mu        sync.Mutex
+	mints     map[string]struct{}
+	trades    map[string][]trade
+	window    time.Duration
+	interval  time.Duration
+	retention time.Duration
+	log       *zap.Logger
+	cancel    context.CancelFunc
+}
+
+// NewOrderFlowTracker 创建买卖盘失衡统计并设置为全局实例
+func NewOrderFlowTracker(config *configs.OrderFlowConfig) *OrderFlowTracker {
+	tracker := &OrderFlowTracker{
+		trades:    make(map[string][]trade),
+		window:    config.Window,
+		interval:  config.Interval,
+		retention: config.Retention,
+		log:       logger.Named("analytics.order_flow"),
+	}
+	tracker.SetMints(config.Mints)
+	GlobalOrderFlowTracker = tracker
+	return tracker
+}
+
+// SetMints 更新关注的代币，不再关注的代币的统计会被清除
+func (t *OrderFlowTracker) SetMints(mints []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mints = make(map[string]struct{}, len(mints))
+	for _, mint := range mints {
+		t.mints[mint] = struct{}{}
+	}
+	for mint := range t.trades {
+		if _, ok := t.mints[mint]; !ok {
+			delete(t.trades, mint)
+		}
+	}
+}
+
+// Mints 返回关注的代币
+func (t *OrderFlowTracker) Mints() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	mints := make([]string, 0, len(t.mints))
+	for mint := range t.mints {
+		mints = append(mints, mint)
+	}
+	slices.Sort(mints)
+	return mints
+}
+
+// Start 订阅事件管道，并按快照间隔保存时间序列、发布失衡事件
+func (t *OrderFlowTracker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction, pipeline.EventPumpPortal},
+	})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.order_flow", func(ctx context.Context) {
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					t.handleEvent(event)
+				case now := <-ticker.C:
+					t.flush(ctx, p, now)
+				}
+			}
+		})
+	}()
+	t.log.Info("买卖盘失衡统计已启动", zap.Int("代币数", len(t.Mints())), zap.Duration("window", t.window))
+}
+
+// Close 停止统计
+func (t *OrderFlowTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// Snapshots 返回所有关注代币当前的失衡快照
+func (t *OrderFlowTracker) Snapshots() []models.OrderFlowSnapshot {
+	now := clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshots := make([]models.OrderFlowSnapshot, 0, len(t.mints))
+	for mint := range t.mints {
+		snapshots = append(snapshots, t.snapshotLocked(mint, now))
+	}
+	slices.SortFunc(snapshots, func(a, b models.OrderFlowSnapshot) int { return cmp.Compare(a.Mint, b.Mint) })
+	return snapshots
+}
+
+// handleEvent 从事件中提取关注代币的买卖
+func (t *OrderFlowTracker) handleEvent(event pipeline.Event) {
+	switch event.Type {
+	case pipeline.EventTransaction:
+		if event.Transaction == nil || event.Transaction.Type != resp.TransactionTypeSwap {
+			return
+		}
+		// 以手续费支付者在各代币上的净流入判断方向：净流入为买入，净流出为卖出
+		transaction := event.Transaction
+		net := make(map[string]float64)
+		for _, transfer := range transaction.TokenTransfers {
+			amount, _ := transfer.TokenAmount.Float64()
+			if transfer.ToUserAccount == transaction.FeePayer {
+				net[transfer.Mint] += amount
+			}
+			if transfer.FromUserAccount == transaction.FeePayer {
+				net[transfer.Mint] -= amount
+			}
+		}
+		at := time.Unix(transaction.Timestamp, 0)
+		if transaction.Timestamp == 0 {
+			at = event.Time
+		}
+		for mint, amount := range net {
+			if amount != 0 {
+				t.record(mint, at, amount > 0, math.Abs(amount))
+			}
+		}
+	case pipeline.EventPumpPortal:
+		if event.MessageType != resp.Buy && event.MessageType != resp.Sell {
+			return
+		}
+		var tokenTrade resp.TokenTrade
+		if err := json.Unmarshal(event.Raw, &tokenTrade); err != nil {
+			return
+		}
+		amount, _ := tokenTrade.TokenAmount.Float64()
+		if amount > 0 {
+			t.record(tokenTrade.Mint, event.Time, tokenTrade.TxType == resp.Buy, amount)
+		}
+	}
+}
+
+// record 记录关注代币的一笔买卖
+func (t *OrderFlowTracker) record(mint string, at time.Time, buy bool, volume float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.mints[mint]; !ok {
+		return
+	}
+	t.trades[mint] = append(t.trades[mint], trade{at: at, buy: buy, volume: volume})
+}
+
+// flush 生成所有关注代币的快照，保存到时间序列并发布失衡事件
+func (t *OrderFlowTracker) flush(ctx context.Context, p *pipeline.Pipeline, now time.Time) {
+	t.mu.Lock()
+	snapshots := make([]models.OrderFlowSnapshot, 0, len(t.mints))
+	for mint := range t.mints {
+		snapshots = append(snapshots, t.snapshotLocked(mint, now))
+	}
+	t.mu.Unlock()
+
+	analyticsRedis := storage.GetRedisClient(storage.WorkloadAnalytics)
+	for _, snapshot := range snapshots {
+		if snapshot.BuyCount+snapshot.SellCount == 0 {
+			continue
+		}
+		storeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := analyticsRedis.StoreOrderFlowSnapshot(storeCtx, snapshot, t.retention); err != nil {
+			t.log.Error("存储买卖盘失衡快照失败", zap.String("mint", snapshot.Mint), zap.Error(err))
+		}
+		cancel()
+		p.Publish(pipeline.Event{
+			Type:      pipeline.EventOrderFlow,
+			Mint:      snapshot.Mint,
+			OrderFlow: &snapshot,
+			Time:      now,
+		})
+	}
+}
+
+// snapshotLocked 清理窗口外的买卖并计算快照，调用方需持有锁
+func (t *OrderFlowTracker) snapshotLocked(mint string, now time.Time) models.OrderFlowSnapshot {
+	cutoff := now.Add(-t.window)
+	trades := slices.DeleteFunc(t.trades[mint], func(tr trade) bool { return tr.at.Before(cutoff) })
+	t.trades[mint] = trades
+
+	snapshot := models.OrderFlowSnapshot{
+		Mint:      mint,
+		Timestamp: now.Unix(),
+		Window:    int64(t.window.Seconds()),
+	}
+	for _, tr := range trades {
+		if tr.buy {
+			snapshot.BuyVolume += tr.volume
+			snapshot.BuyCount++
+		} else {
+			snapshot.SellVolume += tr.volume
+			snapshot.SellCount++
+		}
+	}
+	if total := snapshot.BuyVolume + snapshot.SellVolume; total > 0 {
+		snapshot.Imbalance = (snapshot.BuyVolume - snapshot.SellVolume) / total
+	}
+	return snapshot
+}
@@ -0,0 +1,215 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalPositionTracker 全局持仓统计
+var GlobalPositionTracker *PositionTracker
+
+// positionKey 持仓增量的分组: 代币 + 钱包 + UTC日
+type positionKey struct {
+	mint   string
+	wallet string
+	day    int64
+}
+
+// PositionTracker 根据TRANSFER(含SPL代币转账)和SWAP交易统计每个钱包在每个代币上的净数量、
+// SOL买入/卖出数量和金额，以及每日净流入，用于查询一段时间内吸筹最多的钱包
+// 增量先在内存中累加，每隔 flush_interval 批量写入Redis
+type PositionTracker struct {
+	mu            sync.Mutex
+	mints         map[string]struct{} // 统计的代币，为空时统计所有代币
+	pending       map[positionKey]*models.PositionDelta
+	flushInterval time.Duration
+	positionTTL   time.Duration
+	retention     time.Duration
+	log           *zap.Logger
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// NewPositionTracker 创建持仓统计并设置为全局实例
+func NewPositionTracker(config *configs.PositionsConfig) *PositionTracker {
+	tracker := &PositionTracker{
+		pending:       make(map[positionKey]*models.PositionDelta),
+		flushInterval: config.FlushInterval,
+		positionTTL:   config.PositionTTL,
+		retention:     config.Retention,
+		log:           logger.Named("analytics.positions"),
+	}
+	tracker.SetMints(config.Mints)
+	GlobalPositionTracker = tracker
+	return tracker
+}
+
+// SetMints 更新统计的代币，为空时统计所有代币
+func (t *PositionTracker) SetMints(mints []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mints = make(map[string]struct{}, len(mints))
+	for _, mint := range mints {
+		t.mints[mint] = struct{}{}
+	}
+}
+
+// Start 订阅事件管道中的解析交易，并定期写入Redis
+func (t *PositionTracker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction},
+	})
+	go func() {
+		defer close(t.done)
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.positions", func(ctx context.Context) {
+			ticker := time.NewTicker(t.flushInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					t.flush()
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if event.Transaction != nil {
+						t.Record(event.Transaction, event.Time)
+					}
+				case <-ticker.C:
+					t.flush()
+				}
+			}
+		})
+	}()
+	t.log.Info("持仓统计已启动", zap.Duration("flush_interval", t.flushInterval))
+}
+
+// Close 停止统计并写入尚未保存的增量
+func (t *PositionTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+		<-t.done
+	}
+}
+
+// Record 记录一笔解析交易，交易时间为0时使用 receivedAt
+// 所有非Wrapped SOL的代币转账计入转出方和转入方的净数量；SWAP交易中手续费支付者用SOL买入或卖出的代币另外计入买卖数量和金额
+func (t *PositionTracker) Record(transaction *resp.ParsedTransaction, receivedAt time.Time) {
+	if transaction.TransactionError != nil {
+		return
+	}
+	if transaction.Type != resp.TransactionTypeTransfer && transaction.Type != resp.TransactionTypeSwap {
+		return
+	}
+	at := time.Unix(transaction.Timestamp, 0)
+	if transaction.Timestamp == 0 {
+		at = receivedAt
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transfer := range transaction.TokenTransfers {
+		if transfer.Mint == WrappedSOLMint || !t.trackedLocked(transfer.Mint) {
+			continue
+		}
+		amount, _ := transfer.TokenAmount.Float64()
+		if amount == 0 {
+			continue
+		}
+		if transfer.FromUserAccount != "" {
+			t.deltaLocked(transfer.Mint, transfer.FromUserAccount, at).Balance -= amount
+		}
+		if transfer.ToUserAccount != "" {
+			t.deltaLocked(transfer.Mint, transfer.ToUserAccount, at).Balance += amount
+		}
+	}
+	if transaction.Type != resp.TransactionTypeSwap {
+		return
+	}
+	for _, trade := range swapTrades(transaction) {
+		if trade.sol == 0 || !t.trackedLocked(trade.mint) {
+			continue
+		}
+		delta := t.deltaLocked(trade.mint, trade.wallet, at)
+		if trade.buy {
+			delta.Bought += trade.tokens
+			delta.BuySOL += trade.sol
+		} else {
+			delta.Sold += trade.tokens
+			delta.SellSOL += trade.sol
+		}
+	}
+}
+
+// trackedLocked 返回是否统计该代币，调用方需持有锁
+func (t *PositionTracker) trackedLocked(mint string) bool {
+	if len(t.mints) == 0 {
+		return true
+	}
+	_, ok := t.mints[mint]
+	return ok
+}
+
+// deltaLocked 返回钱包当天的增量，调用方需持有锁
+func (t *PositionTracker) deltaLocked(mint, wallet string, at time.Time) *models.PositionDelta {
+	key := positionKey{mint: mint, wallet: wallet, day: at.UTC().Truncate(24 * time.Hour).Unix()}
+	delta, ok := t.pending[key]
+	if !ok {
+		delta = &models.PositionDelta{Mint: mint, Wallet: wallet, Day: key.day}
+		t.pending[key] = delta
+	}
+	delta.UpdatedAt = max(delta.UpdatedAt, at.Unix())
+	return delta
+}
+
+// flush 将内存中的增量写入Redis，写入失败时保留增量下次重试
+func (t *PositionTracker) flush() {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[positionKey]*models.PositionDelta)
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	deltas := make([]models.PositionDelta, 0, len(pending))
+	for _, delta := range pending {
+		deltas = append(deltas, *delta)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).IncrPositions(ctx, deltas, t.positionTTL, t.retention); err != nil {
+		t.log.Error("保存持仓失败", zap.Error(err))
+		t.mu.Lock()
+		for key, delta := range pending {
+			if current, ok := t.pending[key]; ok {
+				current.Balance += delta.Balance
+				current.Bought += delta.Bought
+				current.Sold += delta.Sold
+				current.BuySOL += delta.BuySOL
+				current.SellSOL += delta.SellSOL
+				current.UpdatedAt = max(current.UpdatedAt, delta.UpdatedAt)
+			} else {
+				t.pending[key] = delta
+			}
+		}
+		t.mu.Unlock()
+	}
+}
@@ -0,0 +1,150 @@
+package analytics
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/parser"
+)
+
+// GlobalPriorityFeeTracker 全局优先费统计
+var GlobalPriorityFeeTracker *PriorityFeeTracker
+
+// DefaultPriorityFeePercentiles 默认返回的分位数
+var DefaultPriorityFeePercentiles = []float64{25, 50, 75, 90, 95, 99}
+
+// blockFees 单个区块中非投票交易设置的计算预算
+type blockFees struct {
+	slot    uint64
+	budgets []parser.ComputeBudget
+}
+
+// PriorityFeeTracker 保存最近若干个区块中非投票交易的计算单元价格，按分位数给出推荐价格
+type PriorityFeeTracker struct {
+	mu     sync.RWMutex
+	blocks []blockFees
+	next   int
+	window int
+}
+
+// NewPriorityFeeTracker 创建优先费统计并设置为全局实例
+func NewPriorityFeeTracker(config *configs.PriorityFeeConfig) *PriorityFeeTracker {
+	tracker := &PriorityFeeTracker{
+		blocks: make([]blockFees, 0, config.WindowBlocks),
+		window: config.WindowBlocks,
+	}
+	GlobalPriorityFeeTracker = tracker
+	return tracker
+}
+
+// RecordComputeBudgets 记录区块中非投票交易的计算预算，未启用统计时不做任何处理
+func RecordComputeBudgets(slot uint64, budgets []parser.ComputeBudget) {
+	if GlobalPriorityFeeTracker != nil {
+		GlobalPriorityFeeTracker.Record(slot, budgets)
+	}
+}
+
+// Record 记录区块样本，窗口满后覆盖最早的区块
+func (t *PriorityFeeTracker) Record(slot uint64, budgets []parser.ComputeBudget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sample := blockFees{slot: slot, budgets: budgets}
+	if len(t.blocks) < t.window {
+		t.blocks = append(t.blocks, sample)
+		return
+	}
+	t.blocks[t.next] = sample
+	t.next = (t.next + 1) % t.window
+}
+
+// Estimate 计算最近区块的计算单元价格分位数
+// 参数:
+//   - program: 只统计调用了该程序的交易，为空时统计所有交易
+//   - blocks: 只统计槽位最大的若干个区块，不大于0时使用整个窗口
+//   - percentiles: 需要返回的分位数，取值[0, 100]，为空时使用默认分位数
+//
+// 返回:
+//   - models.PriorityFeeEstimate: 分位数和推荐档位，没有样本时价格均为0
+func (t *PriorityFeeTracker) Estimate(program string, blocks int, percentiles []float64) models.PriorityFeeEstimate {
+	if len(percentiles) == 0 {
+		percentiles = DefaultPriorityFeePercentiles
+	}
+
+	t.mu.RLock()
+	samples := slices.Clone(t.blocks)
+	t.mu.RUnlock()
+	// 区块并发处理，写入顺序不一定按槽位，按槽位降序取最近的区块
+	slices.SortFunc(samples, func(a, b blockFees) int { return cmp.Compare(b.slot, a.slot) })
+	if blocks > 0 && blocks < len(samples) {
+		samples = samples[:blocks]
+	}
+
+	estimate := models.PriorityFeeEstimate{
+		Program:     program,
+		Blocks:      len(samples),
+		Percentiles: make(map[string]uint64, len(percentiles)),
+	}
+	var prices []uint64
+	for i, sample := range samples {
+		if i == 0 {
+			estimate.ToSlot = sample.slot
+		}
+		estimate.FromSlot = sample.slot
+		for _, budget := range sample.budgets {
+			if program != "" && !slices.Contains(budget.Programs, program) {
+				continue
+			}
+			prices = append(prices, budget.UnitPrice)
+		}
+	}
+	estimate.Transactions = len(prices)
+	slices.Sort(prices)
+
+	for _, p := range percentiles {
+		estimate.Percentiles[percentileName(p)] = percentile(prices, p)
+	}
+	estimate.Levels = models.PriorityFeeLevels{
+		Min:       percentile(prices, 0),
+		Low:       percentile(prices, 25),
+		Medium:    percentile(prices, 50),
+		High:      percentile(prices, 75),
+		VeryHigh:  percentile(prices, 95),
+		UnsafeMax: percentile(prices, 100),
+	}
+	return estimate
+}
+
+// ParsePercentiles 解析分位数列表，如 "50,75,99.5"
+func ParsePercentiles(value string) ([]float64, error) {
+	values := strings.Split(value, ",")
+	percentiles := make([]float64, 0, len(values))
+	for _, value := range values {
+		p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("分位数必须在[0, 100]之间: %s", value)
+		}
+		percentiles = append(percentiles, p)
+	}
+	return percentiles, nil
+}
+
+// percentile 按最近秩法计算已排序价格的分位数，没有样本时返回0
+func percentile(sorted []uint64, p float64) uint64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// percentileName 分位数的键名，如 50 -> "p50"，99.5 -> "p99.5"
+func percentileName(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
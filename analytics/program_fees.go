@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalProgramFeeTracker 全局按程序统计手续费
+var GlobalProgramFeeTracker *ProgramFeeTracker
+
+// programHour 统计的分组: 程序 + 小时
+type programHour struct {
+	program string
+	hour    int64
+}
+
+// ProgramFeeTracker 按程序统计区块中非投票交易每小时支付的手续费、优先费和设置的计算单元价格
+// 一笔交易计入它调用的每个程序(包括通过CPI调用的程序)；增量先在内存中累加，每隔 flush_interval 批量写入Redis
+type ProgramFeeTracker struct {
+	mu            sync.Mutex
+	programs      map[string]struct{} // 统计的程序ID，为空时统计所有程序
+	pending       map[programHour]*models.ProgramFees
+	flushInterval time.Duration
+	retention     time.Duration
+	log           *zap.Logger
+	cancel        context.CancelFunc
+}
+
+// NewProgramFeeTracker 创建按程序统计手续费并设置为全局实例
+func NewProgramFeeTracker(config *configs.ProgramFeesConfig) *ProgramFeeTracker {
+	tracker := &ProgramFeeTracker{
+		programs:      make(map[string]struct{}, len(config.Programs)),
+		pending:       make(map[programHour]*models.ProgramFees),
+		flushInterval: config.FlushInterval,
+		retention:     config.Retention,
+		log:           logger.Named("analytics.program_fees"),
+	}
+	for _, program := range config.Programs {
+		tracker.programs[parser.ResolveProgram(program)] = struct{}{}
+	}
+	GlobalProgramFeeTracker = tracker
+	return tracker
+}
+
+// RecordTransactionFees 记录区块中非投票交易的手续费，未启用统计时不做任何处理
+func RecordTransactionFees(blockTime int64, fees []parser.TransactionFee) {
+	if GlobalProgramFeeTracker != nil {
+		GlobalProgramFeeTracker.Record(blockTime, fees)
+	}
+}
+
+// Start 定期将增量写入Redis
+func (t *ProgramFeeTracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	supervisor.Go(ctx, "analytics.program_fees", func(ctx context.Context) {
+		ticker := time.NewTicker(t.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.flush()
+			}
+		}
+	})
+	t.log.Info("按程序统计手续费已启动", zap.Int("程序数", len(t.programs)), zap.Duration("flush_interval", t.flushInterval))
+}
+
+// Close 停止统计并写入尚未保存的增量
+func (t *ProgramFeeTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.flush()
+}
+
+// Record 按出块时间所在的小时累加区块中交易的手续费，出块时间为0时使用当前时间
+func (t *ProgramFeeTracker) Record(blockTime int64, fees []parser.TransactionFee) {
+	if len(fees) == 0 {
+		return
+	}
+	at := time.Unix(blockTime, 0)
+	if blockTime == 0 {
+		at = clock.Now()
+	}
+	hour := at.Truncate(time.Hour).Unix()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, fee := range fees {
+		for _, program := range fee.Programs {
+			if len(t.programs) > 0 {
+				if _, ok := t.programs[program]; !ok {
+					continue
+				}
+			}
+			key := programHour{program: program, hour: hour}
+			stats, ok := t.pending[key]
+			if !ok {
+				stats = &models.ProgramFees{Program: program, Hour: hour}
+				t.pending[key] = stats
+			}
+			stats.Transactions++
+			if fee.Failed {
+				stats.Failed++
+			}
+			if fee.UnitPrice > 0 {
+				stats.Prioritized++
+				stats.UnitPriceSum += int64(fee.UnitPrice)
+			}
+			stats.Fees += int64(fee.Fee)
+			stats.PriorityFees += int64(fee.PriorityFee)
+			stats.ComputeUnits += int64(fee.ComputeUnits)
+		}
+	}
+}
+
+// flush 将内存中的增量写入Redis，写入失败时保留增量下次重试
+func (t *ProgramFeeTracker) flush() {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[programHour]*models.ProgramFees)
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	fees := make([]models.ProgramFees, 0, len(pending))
+	for _, fee := range pending {
+		fees = append(fees, *fee)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).IncrProgramFees(ctx, fees, t.retention); err != nil {
+		t.log.Error("保存程序手续费统计失败", zap.Error(err))
+		t.mu.Lock()
+		for key, fee := range pending {
+			if current, ok := t.pending[key]; ok {
+				addProgramFees(current, fee)
+			} else {
+				t.pending[key] = fee
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+// addProgramFees 将 delta 的计数累加到 total
+func addProgramFees(total, delta *models.ProgramFees) {
+	total.Transactions += delta.Transactions
+	total.Failed += delta.Failed
+	total.Prioritized += delta.Prioritized
+	total.Fees += delta.Fees
+	total.PriorityFees += delta.PriorityFees
+	total.ComputeUnits += delta.ComputeUnits
+	total.UnitPriceSum += delta.UnitPriceSum
+}
+
+// FillProgramFeeAverages 计算每条统计的平均手续费、平均优先费和平均计算单元价格
+func FillProgramFeeAverages(fees []models.ProgramFees) {
+	for i := range fees {
+		fee := &fees[i]
+		if fee.Transactions > 0 {
+			fee.AvgFee = float64(fee.Fees) / float64(fee.Transactions)
+			fee.AvgPriorityFee = float64(fee.PriorityFees) / float64(fee.Transactions)
+		}
+		if fee.Prioritized > 0 {
+			fee.AvgUnitPrice = float64(fee.UnitPriceSum) / float64(fee.Prioritized)
+		}
+	}
+}
+
+// SummarizeProgramFees 按程序汇总多个小时的统计并计算平均值，按总手续费降序排列
+func SummarizeProgramFees(fees []models.ProgramFees) []models.ProgramFees {
+	byProgram := make(map[string]*models.ProgramFees)
+	for _, fee := range fees {
+		summary, ok := byProgram[fee.Program]
+		if !ok {
+			summary = &models.ProgramFees{Program: fee.Program}
+			byProgram[fee.Program] = summary
+		}
+		addProgramFees(summary, &fee)
+	}
+	summaries := make([]models.ProgramFees, 0, len(byProgram))
+	for _, summary := range byProgram {
+		summaries = append(summaries, *summary)
+	}
+	FillProgramFeeAverages(summaries)
+	slices.SortFunc(summaries, func(a, b models.ProgramFees) int {
+		if c := cmp.Compare(b.Fees, a.Fees); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Program, b.Program)
+	})
+	return summaries
+}
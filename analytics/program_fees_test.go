@@ -0,0 +1,95 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/storage"
+)
+
+// newProgramFeeTracker 创建使用miniredis的手续费统计，只统计 programs 中的程序
+func newProgramFeeTracker(t *testing.T, programs ...string) (*ProgramFeeTracker, *miniredis.Miniredis) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := miniredis.RunT(t)
+	cfg.Redis.Addr = server.Addr()
+	storage.NewRedisClient(&cfg.Redis)
+	t.Cleanup(storage.CloseRedisClients)
+	tracker := NewProgramFeeTracker(&configs.ProgramFeesConfig{Programs: programs, FlushInterval: time.Minute, Retention: 24 * time.Hour})
+	t.Cleanup(func() { GlobalProgramFeeTracker = nil })
+	return tracker, server
+}
+
+func TestProgramFeeTrackerRecordAndFlush(t *testing.T) {
+	tracker, _ := newProgramFeeTracker(t, "jupiter", "dex")
+	hour := time.Now().Truncate(time.Hour)
+	blockTime := hour.Add(10 * time.Minute).Unix()
+
+	tracker.Record(blockTime, []parser.TransactionFee{
+		{Fee: 10000, PriorityFee: 5000, UnitPrice: 1000, ComputeUnits: 100, Programs: []string{parser.KnownPrograms["jupiter"], "dex"}},
+		{Fee: 5000, ComputeUnits: 50, Failed: true, Programs: []string{"dex", "other"}},
+	})
+	tracker.Record(hour.Add(-time.Minute).Unix(), []parser.TransactionFee{{Fee: 5000, Programs: []string{"dex"}}})
+	if len(tracker.pending) != 3 {
+		t.Fatalf("内存中的增量: %d 组，期望3组(other 不在统计的程序中)", len(tracker.pending))
+	}
+	tracker.flush()
+	if len(tracker.pending) != 0 {
+		t.Fatal("写入成功后应清空增量")
+	}
+
+	fees, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetProgramFees(context.Background(), hour.Unix(), hour.Unix(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.ProgramFees{
+		{Program: "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4", Hour: hour.Unix(), Transactions: 1, Prioritized: 1, Fees: 10000, PriorityFees: 5000, ComputeUnits: 100, UnitPriceSum: 1000},
+		{Program: "dex", Hour: hour.Unix(), Transactions: 2, Failed: 1, Prioritized: 1, Fees: 15000, PriorityFees: 5000, ComputeUnits: 150, UnitPriceSum: 1000},
+	}
+	if len(fees) != len(want) {
+		t.Fatalf("写入的统计: %+v", fees)
+	}
+	for i := range want {
+		if fees[i] != want[i] {
+			t.Fatalf("第 %d 条统计 %+v，期望 %+v", i, fees[i], want[i])
+		}
+	}
+
+	summaries := SummarizeProgramFees(fees)
+	if summaries[0].Program != "dex" || summaries[0].AvgFee != 7500 || summaries[0].AvgUnitPrice != 1000 {
+		t.Fatalf("汇总结果: %+v", summaries[0])
+	}
+}
+
+func TestProgramFeeTrackerKeepsPendingOnFailure(t *testing.T) {
+	tracker, _ := newProgramFeeTracker(t)
+	blockTime := time.Now().Unix()
+	tracker.Record(blockTime, []parser.TransactionFee{{Fee: 5000, Programs: []string{"dex"}}})
+
+	// Redis不可用时写入由降级模式缓存，这里通过移除客户端模拟写入失败
+	client := storage.GlobalRedisClient
+	storage.GlobalRedisClient = nil
+	tracker.flush()
+	storage.GlobalRedisClient = client
+	// 写入失败期间新记录的增量与保留的增量合并
+	tracker.Record(blockTime, []parser.TransactionFee{{Fee: 7000, Programs: []string{"dex"}}})
+	if len(tracker.pending) != 1 {
+		t.Fatalf("写入失败后应保留增量: %d 组", len(tracker.pending))
+	}
+	for _, fee := range tracker.pending {
+		if fee.Transactions != 2 || fee.Fees != 12000 {
+			t.Fatalf("合并后的增量: %+v", fee)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// WrappedSOLMint Wrapped SOL代币地址，swap中以代币形式出现的SOL
+const WrappedSOLMint = "So11111111111111111111111111111111111111112"
+
+// lamportsPerSOL 1 SOL = 10^9 lamports
+const lamportsPerSOL = 1e9
+
+// GlobalSourceVolumeTracker 全局按来源统计
+var GlobalSourceVolumeTracker *SourceVolumeTracker
+
+// sourceHour 统计的分组: 来源 + 小时
+type sourceHour struct {
+	source string
+	hour   int64
+}
+
+// SourceVolumeTracker 按交易来源(RAYDIUM、JUPITER、PUMP_FUN等)统计每小时的交易数和swap的SOL成交量
+// 增量先在内存中累加，每隔 flush_interval 批量写入Redis
+type SourceVolumeTracker struct {
+	mu            sync.Mutex
+	pending       map[sourceHour]*models.SourceVolume
+	flushInterval time.Duration
+	retention     time.Duration
+	log           *zap.Logger
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// NewSourceVolumeTracker 创建按来源统计并设置为全局实例
+func NewSourceVolumeTracker(config *configs.SourceVolumeConfig) *SourceVolumeTracker {
+	tracker := &SourceVolumeTracker{
+		pending:       make(map[sourceHour]*models.SourceVolume),
+		flushInterval: config.FlushInterval,
+		retention:     config.Retention,
+		log:           logger.Named("analytics.source_volume"),
+	}
+	GlobalSourceVolumeTracker = tracker
+	return tracker
+}
+
+// Start 订阅事件管道中的解析交易，并定期写入Redis
+func (t *SourceVolumeTracker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction},
+	})
+	go func() {
+		defer close(t.done)
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.source_volume", func(ctx context.Context) {
+			ticker := time.NewTicker(t.flushInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					t.flush()
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if event.Transaction != nil {
+						t.Record(event.Transaction, event.Time)
+					}
+				case <-ticker.C:
+					t.flush()
+				}
+			}
+		})
+	}()
+	t.log.Info("按来源统计已启动", zap.Duration("flush_interval", t.flushInterval))
+}
+
+// Close 停止统计并写入尚未保存的增量
+func (t *SourceVolumeTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+		<-t.done
+	}
+}
+
+// Record 记录一笔解析交易，交易时间为0时使用 receivedAt
+func (t *SourceVolumeTracker) Record(transaction *resp.ParsedTransaction, receivedAt time.Time) {
+	at := time.Unix(transaction.Timestamp, 0)
+	if transaction.Timestamp == 0 {
+		at = receivedAt
+	}
+	key := sourceHour{
+		source: string(transaction.Source),
+		hour:   at.Truncate(time.Hour).Unix(),
+	}
+	if key.source == "" {
+		key.source = string(resp.SourceUnknown)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	volume, ok := t.pending[key]
+	if !ok {
+		volume = &models.SourceVolume{Source: key.source, Hour: key.hour}
+		t.pending[key] = volume
+	}
+	volume.Transactions++
+	if transaction.Type == resp.TransactionTypeSwap {
+		volume.Swaps++
+		volume.SOLVolume += SwapSOLVolume(transaction)
+	}
+}
+
+// flush 将内存中的增量写入Redis，写入失败时保留增量下次重试
+func (t *SourceVolumeTracker) flush() {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[sourceHour]*models.SourceVolume)
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	volumes := make([]models.SourceVolume, 0, len(pending))
+	for _, volume := range pending {
+		volumes = append(volumes, *volume)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).IncrSourceVolumes(ctx, volumes, t.retention); err != nil {
+		t.log.Error("保存来源统计失败", zap.Error(err))
+		t.mu.Lock()
+		for key, volume := range pending {
+			if current, ok := t.pending[key]; ok {
+				current.Transactions += volume.Transactions
+				current.Swaps += volume.Swaps
+				current.SOLVolume += volume.SOLVolume
+			} else {
+				t.pending[key] = volume
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+// SwapSOLVolume 估算swap交易的SOL成交量(SOL)
+// 优先使用Enhanced API的swap事件中的SOL和Wrapped SOL输入/输出，没有swap事件时按手续费支付者的SOL净流入/流出计算
+func SwapSOLVolume(transaction *resp.ParsedTransaction) float64 {
+	if transaction.Events != nil && transaction.Events.Swap != nil {
+		swap := transaction.Events.Swap
+		var input, output float64
+		if swap.NativeInput != nil {
+			input += lamportsToSOL(swap.NativeInput.Amount)
+		}
+		if swap.NativeOutput != nil {
+			output += lamportsToSOL(swap.NativeOutput.Amount)
+		}
+		input += wrappedSOLAmount(swap.TokenInputs)
+		output += wrappedSOLAmount(swap.TokenOutputs)
+		if volume := max(input, output); volume > 0 {
+			return volume
+		}
+	}
+
+	var in, out float64
+	for _, transfer := range transaction.NativeTransfers {
+		amount := float64(transfer.Amount) / lamportsPerSOL
+		if transfer.ToUserAccount == transaction.FeePayer {
+			in += amount
+		}
+		if transfer.FromUserAccount == transaction.FeePayer {
+			out += amount
+		}
+	}
+	for _, transfer := range transaction.TokenTransfers {
+		if transfer.Mint != WrappedSOLMint {
+			continue
+		}
+		amount, _ := transfer.TokenAmount.Float64()
+		if transfer.ToUserAccount == transaction.FeePayer {
+			in += amount
+		}
+		if transfer.FromUserAccount == transaction.FeePayer {
+			out += amount
+		}
+	}
+	return max(in, out)
+}
+
+// lamportsToSOL 将字符串形式的lamports转换为SOL
+func lamportsToSOL(amount string) float64 {
+	lamports, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return lamports / lamportsPerSOL
+}
+
+// wrappedSOLAmount 汇总余额变化中的Wrapped SOL数量(SOL)
+func wrappedSOLAmount(changes []resp.TokenBalanceChange) float64 {
+	var total float64
+	for _, change := range changes {
+		if change.Mint != WrappedSOLMint {
+			continue
+		}
+		raw, err := strconv.ParseFloat(change.RawTokenAmount.TokenAmount, 64)
+		if err != nil {
+			continue
+		}
+		total += math.Abs(raw) / math.Pow10(change.RawTokenAmount.Decimals)
+	}
+	return total
+}
+
+// SummarizeSourceVolumes 按来源汇总多个小时的统计，计算各来源的成交量占比，按SOL成交量降序排列
+func SummarizeSourceVolumes(volumes []models.SourceVolume) []models.SourceVolume {
+	bySource := make(map[string]*models.SourceVolume)
+	var totalVolume float64
+	for _, volume := range volumes {
+		summary, ok := bySource[volume.Source]
+		if !ok {
+			summary = &models.SourceVolume{Source: volume.Source}
+			bySource[volume.Source] = summary
+		}
+		summary.Transactions += volume.Transactions
+		summary.Swaps += volume.Swaps
+		summary.SOLVolume += volume.SOLVolume
+		totalVolume += volume.SOLVolume
+	}
+	summaries := make([]models.SourceVolume, 0, len(bySource))
+	for _, summary := range bySource {
+		if totalVolume > 0 {
+			summary.VolumeShare = summary.SOLVolume / totalVolume
+		}
+		summaries = append(summaries, *summary)
+	}
+	slices.SortFunc(summaries, func(a, b models.SourceVolume) int {
+		if c := cmp.Compare(b.SOLVolume, a.SOLVolume); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Source, b.Source)
+	})
+	return summaries
+}
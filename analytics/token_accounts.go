@@ -0,0 +1,175 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalTokenAccountTracker 全局代币账户创建/关闭统计
+var GlobalTokenAccountTracker *TokenAccountTracker
+
+// tokenAccountCounter 单个代币在当前区间内的计数
+type tokenAccountCounter struct {
+	created int
+	closed  int
+}
+
+// TokenAccountTracker 按代币统计区块中创建和关闭的代币账户数量，每个快照间隔保存一次时间序列
+type TokenAccountTracker struct {
+	mu        sync.Mutex
+	mints     map[string]struct{} // 为空时统计所有代币
+	counters  map[string]*tokenAccountCounter
+	since     time.Time
+	interval  time.Duration
+	retention time.Duration
+	log       *zap.Logger
+	cancel    context.CancelFunc
+}
+
+// NewTokenAccountTracker 创建代币账户创建/关闭统计并设置为全局实例
+func NewTokenAccountTracker(config *configs.TokenAccountsConfig) *TokenAccountTracker {
+	tracker := &TokenAccountTracker{
+		counters:  make(map[string]*tokenAccountCounter),
+		since:     clock.Now(),
+		interval:  config.Interval,
+		retention: config.Retention,
+		log:       logger.Named("analytics.token_accounts"),
+	}
+	tracker.SetMints(config.Mints)
+	GlobalTokenAccountTracker = tracker
+	return tracker
+}
+
+// RecordTokenAccountEvents 记录区块中创建和关闭的代币账户，未启用统计时不做任何处理
+func RecordTokenAccountEvents(events []parser.TokenAccountEvent) {
+	if GlobalTokenAccountTracker != nil {
+		GlobalTokenAccountTracker.Record(events)
+	}
+}
+
+// SetMints 更新统计的代币，为空时统计所有代币
+func (t *TokenAccountTracker) SetMints(mints []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mints = make(map[string]struct{}, len(mints))
+	for _, mint := range mints {
+		t.mints[mint] = struct{}{}
+	}
+	if len(t.mints) == 0 {
+		return
+	}
+	for mint := range t.counters {
+		if _, ok := t.mints[mint]; !ok {
+			delete(t.counters, mint)
+		}
+	}
+}
+
+// Record 累加代币账户的创建和关闭数量
+func (t *TokenAccountTracker) Record(events []parser.TokenAccountEvent) {
+	if len(events) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, event := range events {
+		if event.Mint == "" {
+			continue
+		}
+		if len(t.mints) > 0 {
+			if _, ok := t.mints[event.Mint]; !ok {
+				continue
+			}
+		}
+		counter, ok := t.counters[event.Mint]
+		if !ok {
+			counter = &tokenAccountCounter{}
+			t.counters[event.Mint] = counter
+		}
+		if event.Closed {
+			counter.closed++
+		} else {
+			counter.created++
+		}
+	}
+}
+
+// Start 按快照间隔保存时间序列
+func (t *TokenAccountTracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	supervisor.Go(ctx, "analytics.token_accounts", func(ctx context.Context) {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				t.flush(ctx, now)
+			}
+		}
+	})
+	t.log.Info("代币账户创建/关闭统计已启动", zap.Int("代币数", len(t.mints)), zap.Duration("interval", t.interval))
+}
+
+// Close 停止统计
+func (t *TokenAccountTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// Pending 返回当前区间内尚未保存的计数，按净增数量降序
+func (t *TokenAccountTracker) Pending() []models.TokenAccountSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshotsLocked(clock.Now())
+}
+
+// flush 保存当前区间的快照并开始新的区间，没有变化的代币不保存
+func (t *TokenAccountTracker) flush(ctx context.Context, now time.Time) {
+	t.mu.Lock()
+	snapshots := t.snapshotsLocked(now)
+	t.counters = make(map[string]*tokenAccountCounter)
+	t.since = now
+	t.mu.Unlock()
+
+	storeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).StoreTokenAccountSnapshots(storeCtx, snapshots, t.retention); err != nil {
+		t.log.Error("存储代币账户快照失败", zap.Int("代币数", len(snapshots)), zap.Error(err))
+	}
+}
+
+// snapshotsLocked 生成当前区间的快照，调用方需持有锁
+func (t *TokenAccountTracker) snapshotsLocked(now time.Time) []models.TokenAccountSnapshot {
+	snapshots := make([]models.TokenAccountSnapshot, 0, len(t.counters))
+	for mint, counter := range t.counters {
+		snapshots = append(snapshots, models.TokenAccountSnapshot{
+			Mint:      mint,
+			Timestamp: now.Unix(),
+			Interval:  int64(now.Sub(t.since).Seconds()),
+			Created:   counter.created,
+			Closed:    counter.closed,
+			Net:       counter.created - counter.closed,
+		})
+	}
+	slices.SortFunc(snapshots, func(a, b models.TokenAccountSnapshot) int {
+		return cmp.Or(cmp.Compare(b.Net, a.Net), cmp.Compare(a.Mint, b.Mint))
+	})
+	return snapshots
+}
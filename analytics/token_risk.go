@@ -0,0 +1,362 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/req"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+const (
+	// tokenAccountsPageSize DAS getTokenAccounts 单页最多返回的账户数
+	tokenAccountsPageSize = 1000
+	// tokenRiskPoolLookup 查找代币的流动性池时最多检查的最近池子创建记录数
+	tokenRiskPoolLookup = 1000
+)
+
+// 各风险项的分值，总分超过100时按100计
+var tokenRiskWeights = map[string]int{
+	models.TokenRiskFreezeAuthority: 60,
+	models.TokenRiskMintAuthority:   30,
+	models.TokenRiskTopHolder:       20,
+	models.TokenRiskTop10Holders:    10,
+	models.TokenRiskNoLiquidity:     20,
+}
+
+// GlobalTokenRiskAnalyzer 全局新代币风险检测，未启用时为nil
+var GlobalTokenRiskAnalyzer *TokenRiskAnalyzer
+
+// tokenRiskCheck 等待检测的代币
+type tokenRiskCheck struct {
+	mint   string
+	source string
+	curve  string // PumpPortal创建消息中的联合曲线账户
+	due    time.Time
+}
+
+// TokenRiskAnalyzer 检测PumpPortal新代币和TOKEN_MINT交易中代币的增发/冻结权限、持仓集中度和流动性状态
+// 发现代币后等待 delay 再检测，结果按代币保存到Redis，每个代币只检测一次
+type TokenRiskAnalyzer struct {
+	mu      sync.Mutex
+	queued  map[string]struct{} // 已入队尚未检测的代币
+	pending chan tokenRiskCheck
+	config  configs.TokenRiskConfig
+	log     *zap.Logger
+	cancel  context.CancelFunc
+}
+
+// NewTokenRiskAnalyzer 创建新代币风险检测并设置为全局实例
+func NewTokenRiskAnalyzer(config *configs.TokenRiskConfig) *TokenRiskAnalyzer {
+	analyzer := &TokenRiskAnalyzer{
+		queued:  make(map[string]struct{}),
+		pending: make(chan tokenRiskCheck, config.QueueSize),
+		config:  *config,
+		log:     logger.Named("analytics.token_risk"),
+	}
+	GlobalTokenRiskAnalyzer = analyzer
+	return analyzer
+}
+
+// Start 订阅PumpPortal创建消息和TOKEN_MINT交易，并启动检测协程
+func (a *TokenRiskAnalyzer) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types:            []pipeline.EventType{pipeline.EventPumpPortal, pipeline.EventTransaction},
+		MessageTypes:     []resp.MessageType{resp.Create},
+		TransactionTypes: []resp.TransactionType{resp.TransactionTypeTokenMint},
+	})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.token_risk", func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					a.handleEvent(event)
+				}
+			}
+		})
+	}()
+	supervisor.Go(ctx, "analytics.token_risk.run", a.run)
+	a.log.Info("新代币风险检测已启动", zap.Duration("delay", a.config.Delay), zap.Int("queue_size", a.config.QueueSize))
+}
+
+// Close 停止检测，队列中尚未检测的代币被丢弃
+func (a *TokenRiskAnalyzer) Close() {
+	if a.cancel != nil {
+		a.cancel()
+	}
+}
+
+// handleEvent 从创建消息或TOKEN_MINT交易中取出代币加入检测队列
+func (a *TokenRiskAnalyzer) handleEvent(event pipeline.Event) {
+	switch event.Type {
+	case pipeline.EventPumpPortal:
+		var token resp.NewToken
+		if err := json.Unmarshal(event.Raw, &token); err != nil || token.Mint == "" {
+			return
+		}
+		a.enqueue(tokenRiskCheck{mint: token.Mint, source: "pump_portal", curve: token.BondingCurveKey})
+	case pipeline.EventTransaction:
+		if event.Transaction == nil {
+			return
+		}
+		for _, mint := range event.Transaction.Mints() {
+			a.enqueue(tokenRiskCheck{mint: mint, source: "transaction"})
+		}
+	}
+}
+
+// enqueue 把代币加入检测队列，已在队列中或队列已满时不加入
+func (a *TokenRiskAnalyzer) enqueue(check tokenRiskCheck) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.queued[check.mint]; ok {
+		return
+	}
+	check.due = clock.Now().Add(a.config.Delay)
+	select {
+	case a.pending <- check:
+		a.queued[check.mint] = struct{}{}
+	default:
+		a.log.Warn("代币风险检测队列已满，丢弃新代币", zap.String("mint", check.mint))
+	}
+}
+
+// run 按入队顺序等待到期后检测，已有检测结果的代币跳过
+func (a *TokenRiskAnalyzer) run(ctx context.Context) {
+	for {
+		var check tokenRiskCheck
+		select {
+		case <-ctx.Done():
+			return
+		case check = <-a.pending:
+		}
+		if wait := check.due.Sub(clock.Now()); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(wait):
+			}
+		}
+		a.check(ctx, check)
+		a.mu.Lock()
+		delete(a.queued, check.mint)
+		a.mu.Unlock()
+	}
+}
+
+// check 检测一个代币并保存结果
+func (a *TokenRiskAnalyzer) check(ctx context.Context, check tokenRiskCheck) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+	defer cancel()
+	redisClient := storage.GetRedisClient(storage.WorkloadAnalytics)
+	if existing, err := redisClient.GetTokenRisk(ctx, check.mint); err == nil && existing != nil {
+		return
+	}
+	report, err := a.Analyze(ctx, check.mint, check.source, check.curve)
+	if err != nil {
+		a.log.Warn("代币风险检测失败", zap.String("mint", check.mint), zap.Error(err))
+		return
+	}
+	if err := redisClient.StoreTokenRisk(ctx, *report, a.config.TTL); err != nil {
+		a.log.Error("保存代币风险检测结果失败", zap.String("mint", check.mint), zap.Error(err))
+		return
+	}
+	a.log.Info("代币风险检测完成",
+		zap.String("mint", report.Mint),
+		zap.String("level", report.Level),
+		zap.Int("score", report.Score),
+		zap.Strings("flags", report.Flags))
+}
+
+// Analyze 立即检测代币的风险，不读取也不保存检测结果
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - source: 发现来源，写入检测结果
+//   - curve: 已知的联合曲线账户，没有时为空
+//
+// 返回:
+//   - *models.TokenRiskReport: 检测结果
+//   - error: 查询Mint账户或代币账户失败时返回错误
+func (a *TokenRiskAnalyzer) Analyze(ctx context.Context, mint, source, curve string) (*models.TokenRiskReport, error) {
+	client := rpc.GlobalHeliusClient
+	if client == nil {
+		return nil, errors.New("Helius HTTP API 客户端尚未初始化")
+	}
+	account, err := client.GetMintAccount(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := strconv.ParseUint(account.Supply, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析代币供应量失败: %w", err)
+	}
+	report := &models.TokenRiskReport{
+		Mint:            mint,
+		Source:          source,
+		Program:         account.Program,
+		MintAuthority:   account.MintAuthority,
+		FreezeAuthority: account.FreezeAuthority,
+		Supply:          float64(supply) / math.Pow10(account.Decimals),
+		Decimals:        account.Decimals,
+		CheckedAt:       clock.Now(),
+	}
+	a.liquidity(ctx, report, curve)
+	if err := a.holders(ctx, report, supply); err != nil {
+		return nil, err
+	}
+	a.score(report)
+	return report, nil
+}
+
+// liquidity 按联合曲线进度统计和最近的池子创建记录判断代币的流动性状态
+func (a *TokenRiskAnalyzer) liquidity(ctx context.Context, report *models.TokenRiskReport, curve string) {
+	if GlobalBondingCurveTracker != nil {
+		if progress, ok := GlobalBondingCurveTracker.Get(report.Mint); ok {
+			report.Liquidity = models.TokenLiquidityBondingCurve
+			if progress.Graduated {
+				report.Liquidity = models.TokenLiquidityMigrated
+			}
+			curve = cmp.Or(curve, progress.BondingCurve)
+		}
+	}
+	if curve != "" {
+		report.Liquidity = cmp.Or(report.Liquidity, models.TokenLiquidityBondingCurve)
+		report.Pool = curve
+		report.Excluded = append(report.Excluded, curve)
+	}
+	if report.Liquidity == models.TokenLiquidityBondingCurve {
+		return
+	}
+
+	creations, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetPoolCreations(ctx, tokenRiskPoolLookup)
+	if err != nil {
+		a.log.Warn("读取池子创建记录失败，不判断流动性池", zap.Error(err))
+	}
+	for _, creation := range creations {
+		if creation.MintA == report.Mint || creation.MintB == report.Mint {
+			report.Liquidity = cmp.Or(report.Liquidity, models.TokenLiquidityPool)
+			report.Pool = creation.Pool
+			report.Excluded = append(report.Excluded, creation.Pool)
+			break
+		}
+	}
+	report.Liquidity = cmp.Or(report.Liquidity, models.TokenLiquidityNone)
+}
+
+// holders 通过DAS分页查询代币账户，按持有者汇总后计算最大持有者和前10持有者的占比
+// 联合曲线、池子和配置中排除的持有者不参与统计
+func (a *TokenRiskAnalyzer) holders(ctx context.Context, report *models.TokenRiskReport, supply uint64) error {
+	excluded := make(map[string]bool)
+	for _, owner := range slices.Concat(report.Excluded, a.config.ExcludedOwners) {
+		excluded[owner] = true
+	}
+	balances := make(map[string]uint64)
+	fetched := 0
+	report.HoldersComplete = true
+	for page := 1; ; page++ {
+		if fetched >= a.config.MaxHolderAccounts {
+			report.HoldersComplete = false
+			break
+		}
+		// DAS按 (page-1)*limit 计算偏移，每页必须使用相同的大小，超出上限的账户在本地截断
+		accounts, err := rpc.GlobalHeliusClient.GetTokenAccounts(ctx, req.GetTokenAccountsParams{Mint: report.Mint, Page: page, Limit: tokenAccountsPageSize})
+		if err != nil {
+			return err
+		}
+		full := len(accounts.TokenAccounts) == tokenAccountsPageSize
+		tokenAccounts := accounts.TokenAccounts[:min(len(accounts.TokenAccounts), a.config.MaxHolderAccounts-fetched)]
+		for _, account := range tokenAccounts {
+			if account.Amount > 0 && !excluded[account.Owner] {
+				balances[account.Owner] += account.Amount
+			}
+		}
+		fetched += len(tokenAccounts)
+		if !full {
+			break
+		}
+	}
+
+	type holder struct {
+		owner  string
+		amount uint64
+	}
+	ranked := make([]holder, 0, len(balances))
+	for owner, amount := range balances {
+		ranked = append(ranked, holder{owner, amount})
+	}
+	slices.SortFunc(ranked, func(a, b holder) int {
+		return cmp.Or(cmp.Compare(b.amount, a.amount), cmp.Compare(a.owner, b.owner))
+	})
+	report.Holders = len(ranked)
+	if len(ranked) == 0 || supply == 0 {
+		return nil
+	}
+	report.TopHolder = ranked[0].owner
+	report.TopHolderShare = float64(ranked[0].amount) / float64(supply)
+	var top10 uint64
+	for _, h := range ranked[:min(10, len(ranked))] {
+		top10 += h.amount
+	}
+	report.Top10Share = float64(top10) / float64(supply)
+	return nil
+}
+
+// score 按命中的风险项计算风险分和等级
+func (a *TokenRiskAnalyzer) score(report *models.TokenRiskReport) {
+	report.Flags = []string{}
+	if report.FreezeAuthority != "" {
+		report.Flags = append(report.Flags, models.TokenRiskFreezeAuthority)
+	}
+	if report.MintAuthority != "" {
+		report.Flags = append(report.Flags, models.TokenRiskMintAuthority)
+	}
+	if report.TopHolderShare > a.config.TopHolderShare {
+		report.Flags = append(report.Flags, models.TokenRiskTopHolder)
+	}
+	if report.Top10Share > a.config.Top10Share {
+		report.Flags = append(report.Flags, models.TokenRiskTop10Holders)
+	}
+	if report.Liquidity == models.TokenLiquidityNone {
+		report.Flags = append(report.Flags, models.TokenRiskNoLiquidity)
+	}
+	for _, flag := range report.Flags {
+		report.Score += tokenRiskWeights[flag]
+	}
+	report.Score = min(report.Score, 100)
+	switch {
+	case report.Score >= 60:
+		report.Level = models.TokenRiskHigh
+	case report.Score >= 30:
+		report.Level = models.TokenRiskMedium
+	default:
+		report.Level = models.TokenRiskLow
+	}
+}
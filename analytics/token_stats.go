@@ -0,0 +1,505 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+const (
+	// tokenStatsBucket 统计桶的时长，m5窗口的精度
+	tokenStatsBucket = 5 * time.Minute
+	// tokenStatsBuckets 保留24小时的桶
+	tokenStatsBuckets = int(24 * time.Hour / tokenStatsBucket)
+	// tokenStatsSchemaVersion 响应结构版本，与Dexscreener接口保持一致
+	tokenStatsSchemaVersion = "1.0.0"
+	// tokenStatsDedupeTTL 签名去重的保留时长，同一笔交易可能同时来自Enhanced API和PumpPortal
+	tokenStatsDedupeTTL = 10 * time.Minute
+)
+
+// tokenStatsWindows 统计窗口，键与Dexscreener相同
+var tokenStatsWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"m5", 5 * time.Minute},
+	{"h1", time.Hour},
+	{"h6", 6 * time.Hour},
+	{"h24", 24 * time.Hour},
+}
+
+// GlobalTokenStatsTracker 全局代币24小时统计
+var GlobalTokenStatsTracker *TokenStatsTracker
+
+// tokenTrade 一笔以SOL计价的成交
+type tokenTrade struct {
+	mint      string
+	signature string
+	wallet    string
+	buy       bool
+	tokens    float64 // 成交的代币数量
+	sol       float64 // 成交的SOL数量，非SOL计价的swap为0
+	source    string
+	at        time.Time
+}
+
+// tokenBucket 5分钟内的成交汇总
+type tokenBucket struct {
+	start   int64 // 桶起始时间(Unix时间戳)
+	buys    int
+	sells   int
+	volume  float64
+	open    float64 // 桶内第一笔成交价
+	close   float64 // 桶内最后一笔成交价
+	wallets map[string]struct{}
+	sources map[string]float64 // 各来源的成交量
+}
+
+// tokenState 单个代币的统计状态
+type tokenState struct {
+	info      models.TokenInfo
+	pair      string
+	buckets   [tokenStatsBuckets]tokenBucket // 按时间取模的环形缓冲
+	price     float64
+	priceAt   time.Time // 最新成交价的时间，乱序到达的较早成交不覆盖价格
+	liquidity *models.Liquidity
+	marketCap float64
+	firstSeen time.Time
+	lastTrade time.Time
+}
+
+// TokenStatsTracker 根据swap交易和PumpPortal买卖消息统计代币相对SOL的24小时价格、成交量、笔数、独立钱包和流动性
+// 统计保存在内存中，按5分钟分桶，重启后从零开始累积
+type TokenStatsTracker struct {
+	mu       sync.Mutex
+	mints    map[string]struct{} // 统计的代币，为空时统计所有代币
+	maxMints int
+	tokens   map[string]*tokenState
+	seen     map[string]time.Time // 最近处理过的签名
+	log      *zap.Logger
+	cancel   context.CancelFunc
+}
+
+// NewTokenStatsTracker 创建代币24小时统计并设置为全局实例
+func NewTokenStatsTracker(config *configs.TokenStatsConfig) *TokenStatsTracker {
+	tracker := &TokenStatsTracker{
+		maxMints: config.MaxMints,
+		tokens:   make(map[string]*tokenState),
+		seen:     make(map[string]time.Time),
+		log:      logger.Named("analytics.token_stats"),
+	}
+	tracker.SetMints(config.Mints)
+	GlobalTokenStatsTracker = tracker
+	return tracker
+}
+
+// SetMints 更新统计的代币，为空时统计所有代币；不再统计的代币会被清除
+func (t *TokenStatsTracker) SetMints(mints []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mints = make(map[string]struct{}, len(mints))
+	for _, mint := range mints {
+		t.mints[mint] = struct{}{}
+	}
+	if len(t.mints) == 0 {
+		return
+	}
+	for mint := range t.tokens {
+		if _, ok := t.mints[mint]; !ok {
+			delete(t.tokens, mint)
+		}
+	}
+}
+
+// Start 订阅事件管道中的swap交易和PumpPortal消息
+func (t *TokenStatsTracker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction, pipeline.EventPumpPortal},
+	})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "analytics.token_stats", func(ctx context.Context) {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					t.handleEvent(event)
+				case now := <-ticker.C:
+					t.prune(now)
+				}
+			}
+		})
+	}()
+	t.log.Info("代币24小时统计已启动", zap.Int("max_mints", t.maxMints))
+}
+
+// Close 停止统计
+func (t *TokenStatsTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// handleEvent 从事件中提取成交
+func (t *TokenStatsTracker) handleEvent(event pipeline.Event) {
+	switch event.Type {
+	case pipeline.EventTransaction:
+		transaction := event.Transaction
+		if transaction == nil || transaction.Type != resp.TransactionTypeSwap || transaction.TransactionError != nil {
+			return
+		}
+		at := time.Unix(transaction.Timestamp, 0)
+		if transaction.Timestamp == 0 {
+			at = event.Time
+		}
+		for _, trade := range swapTrades(transaction) {
+			trade.at = at
+			t.record(trade)
+		}
+	case pipeline.EventPumpPortal:
+		switch event.MessageType {
+		case resp.Create:
+			var token resp.NewToken
+			if err := json.Unmarshal(event.Raw, &token); err == nil {
+				t.recordToken(token, event.Time)
+			}
+		case resp.Buy, resp.Sell:
+			var trade resp.TokenTrade
+			if err := json.Unmarshal(event.Raw, &trade); err != nil {
+				return
+			}
+			t.record(pumpPortalTrade(trade, event.Time))
+			t.recordPool(trade)
+		}
+	}
+}
+
+// pumpPortalTrade 将PumpPortal买卖消息转换为成交
+func pumpPortalTrade(trade resp.TokenTrade, at time.Time) tokenTrade {
+	tokens, _ := trade.TokenAmount.Float64()
+	sol, _ := trade.SolAmount.Float64()
+	return tokenTrade{
+		mint:      trade.Mint,
+		signature: trade.Signature,
+		wallet:    trade.TraderPublicKey,
+		buy:       trade.TxType == resp.Buy,
+		tokens:    tokens,
+		sol:       sol,
+		source:    pumpPortalSource(trade.Pool),
+		at:        at,
+	}
+}
+
+// pumpPortalSource 根据PumpPortal消息中的池子类型推断来源
+func pumpPortalSource(pool string) string {
+	switch pool {
+	case "", "pump":
+		return string(resp.SourcePumpFun)
+	default:
+		return strings.ToUpper(pool)
+	}
+}
+
+// swapTrades 以手续费支付者的净流入/流出判断swap中各代币的买卖方向，SOL和Wrapped SOL的净流量作为成交额
+func swapTrades(transaction *resp.ParsedTransaction) []tokenTrade {
+	tokens := make(map[string]float64)
+	var sol float64
+	for _, transfer := range transaction.NativeTransfers {
+		amount := float64(transfer.Amount) / lamportsPerSOL
+		if transfer.ToUserAccount == transaction.FeePayer {
+			sol += amount
+		}
+		if transfer.FromUserAccount == transaction.FeePayer {
+			sol -= amount
+		}
+	}
+	for _, transfer := range transaction.TokenTransfers {
+		amount, _ := transfer.TokenAmount.Float64()
+		if transfer.Mint == WrappedSOLMint {
+			if transfer.ToUserAccount == transaction.FeePayer {
+				sol += amount
+			}
+			if transfer.FromUserAccount == transaction.FeePayer {
+				sol -= amount
+			}
+			continue
+		}
+		if transfer.ToUserAccount == transaction.FeePayer {
+			tokens[transfer.Mint] += amount
+		}
+		if transfer.FromUserAccount == transaction.FeePayer {
+			tokens[transfer.Mint] -= amount
+		}
+	}
+
+	trades := make([]tokenTrade, 0, len(tokens))
+	for mint, amount := range tokens {
+		if amount == 0 {
+			continue
+		}
+		trade := tokenTrade{
+			mint:      mint,
+			signature: transaction.Signature,
+			wallet:    transaction.FeePayer,
+			buy:       amount > 0,
+			tokens:    math.Abs(amount),
+			source:    string(transaction.Source),
+		}
+		// 只有代币与SOL方向相反时才是以SOL计价的成交，代币之间的swap只计笔数
+		if len(tokens) == 1 && (amount > 0) != (sol > 0) {
+			trade.sol = math.Abs(sol)
+		}
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// record 记录一笔成交
+func (t *TokenStatsTracker) record(trade tokenTrade) {
+	if trade.mint == "" || trade.tokens <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.mints) > 0 {
+		if _, ok := t.mints[trade.mint]; !ok {
+			return
+		}
+	}
+	if trade.signature != "" {
+		key := trade.signature + ":" + trade.mint
+		if _, ok := t.seen[key]; ok {
+			return
+		}
+		t.seen[key] = clock.Now()
+	}
+
+	state := t.stateLocked(trade.mint, trade.at)
+	start := trade.at.Truncate(tokenStatsBucket).Unix()
+	bucket := &state.buckets[(start/int64(tokenStatsBucket.Seconds()))%int64(tokenStatsBuckets)]
+	if bucket.start != start {
+		*bucket = tokenBucket{start: start, wallets: make(map[string]struct{}), sources: make(map[string]float64)}
+	}
+	if trade.buy {
+		bucket.buys++
+	} else {
+		bucket.sells++
+	}
+	bucket.volume += trade.sol
+	if trade.wallet != "" {
+		bucket.wallets[trade.wallet] = struct{}{}
+	}
+	if trade.source != "" {
+		bucket.sources[trade.source] += trade.sol
+	}
+	if trade.sol > 0 {
+		price := trade.sol / trade.tokens
+		if bucket.open == 0 {
+			bucket.open = price
+		}
+		bucket.close = price
+		if !trade.at.Before(state.priceAt) {
+			state.price, state.priceAt = price, trade.at
+		}
+	}
+	if trade.at.After(state.lastTrade) {
+		state.lastTrade = trade.at
+	}
+	if trade.at.Before(state.firstSeen) {
+		state.firstSeen = trade.at
+	}
+}
+
+// recordToken 记录PumpPortal推送的新代币名称和符号
+func (t *TokenStatsTracker) recordToken(token resp.NewToken, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.mints) > 0 {
+		if _, ok := t.mints[token.Mint]; !ok {
+			return
+		}
+	}
+	state := t.stateLocked(token.Mint, at)
+	state.info.Name = token.Name
+	state.info.Symbol = token.Symbol
+	state.pair = token.BondingCurveKey
+}
+
+// recordPool 记录PumpPortal消息中联合曲线的流动性和市值
+func (t *TokenStatsTracker) recordPool(trade resp.TokenTrade) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.tokens[trade.Mint]
+	if !ok {
+		return
+	}
+	base, _ := trade.VTokensInBondingCurve.Float64()
+	quote, _ := trade.VSolInBondingCurve.Float64()
+	if base > 0 || quote > 0 {
+		state.liquidity = &models.Liquidity{Base: base, Quote: quote}
+	}
+	if marketCap, _ := trade.MarketCapSol.Float64(); marketCap > 0 {
+		state.marketCap = marketCap
+	}
+	if trade.BondingCurveKey != "" {
+		state.pair = trade.BondingCurveKey
+	}
+}
+
+// stateLocked 返回代币的统计状态，不存在时创建；超过上限时淘汰最久没有成交的代币，调用方需持有锁
+func (t *TokenStatsTracker) stateLocked(mint string, at time.Time) *tokenState {
+	if state, ok := t.tokens[mint]; ok {
+		return state
+	}
+	if t.maxMints > 0 && len(t.tokens) >= t.maxMints {
+		var oldest string
+		var oldestAt time.Time
+		for candidate, state := range t.tokens {
+			if oldest == "" || state.lastTrade.Before(oldestAt) {
+				oldest, oldestAt = candidate, state.lastTrade
+			}
+		}
+		delete(t.tokens, oldest)
+	}
+	state := &tokenState{info: models.TokenInfo{Address: mint}, firstSeen: at, lastTrade: at}
+	t.tokens[mint] = state
+	return state
+}
+
+// prune 清理过期的去重记录和24小时内没有成交的代币
+func (t *TokenStatsTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, at := range t.seen {
+		if now.Sub(at) > tokenStatsDedupeTTL {
+			delete(t.seen, key)
+		}
+	}
+	for mint, state := range t.tokens {
+		if now.Sub(state.lastTrade) > 24*time.Hour {
+			delete(t.tokens, mint)
+		}
+	}
+}
+
+// Stats 返回代币的24小时统计，没有统计数据的代币不在结果中
+func (t *TokenStatsTracker) Stats(mints []string) models.TokenStatsResponse {
+	now := clock.Now()
+	response := models.TokenStatsResponse{SchemaVersion: tokenStatsSchemaVersion, Pairs: []models.TokenStats{}}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, mint := range mints {
+		if state, ok := t.tokens[mint]; ok {
+			response.Pairs = append(response.Pairs, state.stats(now))
+		}
+	}
+	return response
+}
+
+// Top 返回24小时成交量最大的若干个代币
+func (t *TokenStatsTracker) Top(limit int) models.TokenStatsResponse {
+	now := clock.Now()
+	response := models.TokenStatsResponse{SchemaVersion: tokenStatsSchemaVersion, Pairs: []models.TokenStats{}}
+	t.mu.Lock()
+	for _, state := range t.tokens {
+		response.Pairs = append(response.Pairs, state.stats(now))
+	}
+	t.mu.Unlock()
+	slices.SortFunc(response.Pairs, func(a, b models.TokenStats) int {
+		return cmp.Compare(b.Volume["h24"], a.Volume["h24"])
+	})
+	if limit > 0 && len(response.Pairs) > limit {
+		response.Pairs = response.Pairs[:limit]
+	}
+	return response
+}
+
+// stats 按窗口汇总统计桶
+func (s *tokenState) stats(now time.Time) models.TokenStats {
+	stats := models.TokenStats{
+		ChainID:       "solana",
+		PairAddress:   s.pair,
+		BaseToken:     s.info,
+		QuoteToken:    models.TokenInfo{Address: WrappedSOLMint, Name: "Wrapped SOL", Symbol: "SOL"},
+		PriceNative:   strconv.FormatFloat(s.price, 'g', 10, 64),
+		Txns:          make(map[string]models.TxnCount, len(tokenStatsWindows)),
+		Volume:        make(map[string]float64, len(tokenStatsWindows)),
+		PriceChange:   make(map[string]float64, len(tokenStatsWindows)),
+		UniqueWallets: make(map[string]int, len(tokenStatsWindows)),
+		Liquidity:     s.liquidity,
+		MarketCap:     s.marketCap,
+		UpdatedAt:     s.lastTrade.UnixMilli(),
+		PairCreatedAt: s.firstSeen.UnixMilli(),
+	}
+	current := now.Truncate(tokenStatsBucket).Unix()
+	sources := make(map[string]float64)
+	for _, window := range tokenStatsWindows {
+		// 窗口覆盖当前桶及之前的若干个完整桶
+		since := current - int64(window.duration.Seconds()) + int64(tokenStatsBucket.Seconds())
+		var txns models.TxnCount
+		var volume, open float64
+		var openAt int64
+		wallets := make(map[string]struct{})
+		for i := range s.buckets {
+			bucket := &s.buckets[i]
+			if bucket.start < since || bucket.start > current {
+				continue
+			}
+			txns.Buys += bucket.buys
+			txns.Sells += bucket.sells
+			volume += bucket.volume
+			for wallet := range bucket.wallets {
+				wallets[wallet] = struct{}{}
+			}
+			if bucket.open > 0 && (openAt == 0 || bucket.start < openAt) {
+				open, openAt = bucket.open, bucket.start
+			}
+			if window.name == "h24" {
+				for source, sourceVolume := range bucket.sources {
+					sources[source] += sourceVolume
+				}
+			}
+		}
+		stats.Txns[window.name] = txns
+		stats.Volume[window.name] = volume
+		stats.UniqueWallets[window.name] = len(wallets)
+		if open > 0 && s.price > 0 {
+			stats.PriceChange[window.name] = math.Round((s.price/open-1)*10000) / 100
+		} else {
+			stats.PriceChange[window.name] = 0
+		}
+	}
+	var dexVolume float64
+	for source, volume := range sources {
+		if stats.DexID == "" || volume > dexVolume || (volume == dexVolume && source < stats.DexID) {
+			stats.DexID, dexVolume = source, volume
+		}
+	}
+	stats.DexID = strings.ToLower(stats.DexID)
+	return stats
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/life2you/datas-go/configs"
+)
+
+// adminHandler 管理接口中间件：限制请求体大小，GET/HEAD 以外的请求必须携带 admin.auth_token
+// 未设置 auth_token 时修改类接口全部拒绝，只能查询
+func adminHandler(config *configs.AdminConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if config.AuthToken == "" {
+				writeError(w, http.StatusForbidden, "未设置 admin.auth_token，修改类接口已禁用")
+				return
+			}
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.AuthToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeError(w, http.StatusUnauthorized, "管理接口令牌无效")
+				return
+			}
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, config.MaxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeJSON 读取管理接口的JSON请求体，超过大小上限或格式错误时输出错误响应并返回false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("请求体超过 %d 字节", maxBytesErr.Limit))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, "解析请求失败: "+err.Error())
+		return false
+	}
+	return true
+}
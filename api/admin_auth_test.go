@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/life2you/datas-go/configs"
+)
+
+func TestAdminHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/ping", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("POST /admin/ping", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if !decodeJSON(w, r, &body) {
+			return
+		}
+		writeJSON(w, http.StatusOK, body)
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		auth   string
+		body   string
+		status int
+	}{
+		{"查询不需要令牌", "secret", http.MethodGet, "", "", http.StatusOK},
+		{"未设置令牌时拒绝修改", "", http.MethodPost, "Bearer secret", `{}`, http.StatusForbidden},
+		{"缺少令牌", "secret", http.MethodPost, "", `{}`, http.StatusUnauthorized},
+		{"令牌错误", "secret", http.MethodPost, "Bearer wrong", `{}`, http.StatusUnauthorized},
+		{"令牌正确", "secret", http.MethodPost, "Bearer secret", `{"a":"b"}`, http.StatusOK},
+		{"请求体过大", "secret", http.MethodPost, "Bearer secret", `{"a":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := adminHandler(&configs.AdminConfig{AuthToken: tt.token, MaxBodyBytes: 32}, mux)
+			req := httptest.NewRequest(tt.method, "/admin/ping", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("状态码 = %d, 期望 %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetBlockByHash 按区块哈希查询槽位和区块元数据
+func handleGetBlockByHash(w http.ResponseWriter, r *http.Request) {
+	if !configs.GlobalConfig.BlockIndex.Enabled {
+		writeError(w, http.StatusServiceUnavailable, "区块哈希索引未启用")
+		return
+	}
+	blockhash := r.PathValue("blockhash")
+	meta, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetBlockMeta(r.Context(), blockhash)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meta == nil {
+		writeError(w, http.StatusNotFound, "没有该区块哈希的记录: "+blockhash)
+		return
+	}
+	writeJSON(w, http.StatusOK, meta)
+}
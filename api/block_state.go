@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetBlockStates 按状态统计区块数量
+func handleGetBlockStates(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalBlockStateTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "区块处理状态跟踪未启用")
+		return
+	}
+	counts, err := storage.GetRedisClient(storage.WorkloadQueue).CountBlockStates(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, counts)
+}
+
+// handleGetStuckBlocks 查询卡在FETCHING/PARSING超过阈值的区块
+func handleGetStuckBlocks(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalBlockStateTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "区块处理状态跟踪未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slots, err := monitor.GlobalBlockStateTracker.Stuck(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"slots": slots,
+	})
+}
+
+// handleGetBlockState 查询单个区块的处理状态
+func handleGetBlockState(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalBlockStateTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "区块处理状态跟踪未启用")
+		return
+	}
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "slot 必须为整数")
+		return
+	}
+	status, err := storage.GetRedisClient(storage.WorkloadQueue).GetBlockState(r.Context(), slot)
+	if errors.Is(err, storage.ErrBlockStateNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetBlockSummaries 按槽位范围查询区块交易汇总
+// 查询参数 from、to 为槽位范围(包含)，默认不限制；limit 默认100，从 to 开始向前取
+func handleGetBlockSummaries(w http.ResponseWriter, r *http.Request) {
+	if !configs.GlobalConfig.BlockSummary.Enabled {
+		writeError(w, http.StatusServiceUnavailable, "区块交易汇总未启用")
+		return
+	}
+	from, err := queryInt64(r, "from", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := queryInt64(r, "to", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if from < 0 || to < 0 || (to > 0 && from > to) {
+		writeError(w, http.StatusBadRequest, "from 和 to 不能为负数，且 from 不能大于 to")
+		return
+	}
+	if limit <= 0 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "limit 必须在1到1000之间")
+		return
+	}
+	summaries, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetBlockSummaries(r.Context(), uint64(from), uint64(to), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"summaries": summaries,
+	})
+}
+
+// handleGetBlockSummary 查询单个区块的交易汇总
+func handleGetBlockSummary(w http.ResponseWriter, r *http.Request) {
+	if !configs.GlobalConfig.BlockSummary.Enabled {
+		writeError(w, http.StatusServiceUnavailable, "区块交易汇总未启用")
+		return
+	}
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "slot 必须为整数")
+		return
+	}
+	summary, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetBlockSummary(r.Context(), slot)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if summary == nil {
+		writeError(w, http.StatusNotFound, "没有该区块的交易汇总: "+r.PathValue("slot"))
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/life2you/datas-go/analytics"
+)
+
+// handleGetBondingCurves 查询未毕业的代币中联合曲线进度最高的若干个，min_progress 默认0，limit 默认20
+func handleGetBondingCurves(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalBondingCurveTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "联合曲线进度统计未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 || limit > 500 {
+		writeError(w, http.StatusBadRequest, "limit 必须在1到500之间")
+		return
+	}
+	var minProgress float64
+	if value := r.URL.Query().Get("min_progress"); value != "" {
+		minProgress, err = strconv.ParseFloat(value, 64)
+		if err != nil || minProgress < 0 || minProgress > 1 {
+			writeError(w, http.StatusBadRequest, "min_progress 必须在0到1之间")
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, analytics.GlobalBondingCurveTracker.Top(minProgress, int(limit)))
+}
+
+// handleGetBondingCurve 查询单个代币的联合曲线进度
+func handleGetBondingCurve(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalBondingCurveTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "联合曲线进度统计未启用")
+		return
+	}
+	mint := r.PathValue("mint")
+	progress, ok := analytics.GlobalBondingCurveTracker.Get(mint)
+	if !ok {
+		writeError(w, http.StatusNotFound, "代币没有联合曲线数据: "+mint)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/export"
+)
+
+// handleGetClickHouse 查询ClickHouse写入统计
+func handleGetClickHouse(w http.ResponseWriter, r *http.Request) {
+	if export.GlobalClickHouseSink == nil {
+		writeError(w, http.StatusServiceUnavailable, "ClickHouse写入未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, export.GlobalClickHouseSink.Stats())
+}
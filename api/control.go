@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/handler"
+)
+
+// ControlResponse 运行时控制操作的响应，control 命令通过管理接口获取
+type ControlResponse struct {
+	handler.ControlStatus
+	Enqueued int `json:"enqueued,omitempty"` // 本次推入区块队列的槽位数
+}
+
+// BackfillRequest 回补请求
+type BackfillRequest struct {
+	From uint64 `json:"from"` // 起始槽位(包含)
+	To   uint64 `json:"to"`   // 结束槽位(包含)
+}
+
+// handleGetControl 查询暂停、排空状态和队列长度
+func handleGetControl(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus()})
+}
+
+// handlePauseStage 暂停处理阶段(block_fetch 或 parse)，内存队列保留
+func handlePauseStage(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	if err := handler.Pause(r.PathValue("stage")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus()})
+}
+
+// handleResumeStage 恢复处理阶段
+func handleResumeStage(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	if err := handler.Resume(r.PathValue("stage")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus()})
+}
+
+// handleStartDrain 开始排空队列，状态中 drained 为true时队列已处理完
+func handleStartDrain(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	handler.StartDrain()
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus()})
+}
+
+// handleStopDrain 停止排空，排空期间推迟的槽位重新入队
+func handleStopDrain(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	enqueued := h.StopDrain()
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus(), Enqueued: enqueued})
+}
+
+// handleBackfill 立即将槽位范围推入运行中服务的区块队列
+func handleBackfill(w http.ResponseWriter, r *http.Request) {
+	h, ok := controlHandler(w)
+	if !ok {
+		return
+	}
+	var request BackfillRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	enqueued, err := h.Backfill(request.From, request.To)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ControlResponse{ControlStatus: h.ControlStatus(), Enqueued: enqueued})
+}
+
+// controlHandler 返回运行中的处理器，尚未创建时输出503
+func controlHandler(w http.ResponseWriter) (*handler.Handler, bool) {
+	if handler.GlobalHandler == nil {
+		writeError(w, http.StatusServiceUnavailable, "处理器尚未初始化")
+		return nil, false
+	}
+	return handler.GlobalHandler, true
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/cursor"
+)
+
+// handleGetCursor 查询区块游标
+func handleGetCursor(w http.ResponseWriter, r *http.Request) {
+	if cursor.GlobalSlotCursor == nil {
+		writeError(w, http.StatusServiceUnavailable, "区块游标未加载")
+		return
+	}
+	writeJSON(w, http.StatusOK, cursor.GlobalSlotCursor.Status())
+}
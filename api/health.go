@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// 健康检查状态
+const (
+	healthOK      = "ok"      // 正常
+	healthFail    = "fail"    // 异常
+	healthSkipped = "skipped" // 组件未启用，不参与检查
+)
+
+// HealthCheck 单项检查的结果
+type HealthCheck struct {
+	Status   string `json:"status"`            // ok、fail 或 skipped
+	Message  string `json:"message,omitempty"` // 异常原因或补充信息
+	Liveness bool   `json:"liveness"`          // 是否影响存活检查，异常时 /healthz 也返回503
+}
+
+// HealthReport 健康检查结果
+type HealthReport struct {
+	Status string                 `json:"status"` // ok 或 fail
+	Checks map[string]HealthCheck `json:"checks"` // 各项检查结果
+	Time   time.Time              `json:"time"`   // 检查时间
+}
+
+// handleHealthz 存活检查，仅在数据采集停滞(WebSocket长时间无消息、队列长时间未出队)时返回503，
+// 用于Kubernetes livenessProbe重启进程
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	report := checkHealth(r.Context())
+	report.Status = healthOK
+	for _, check := range report.Checks {
+		if check.Liveness && check.Status == healthFail {
+			report.Status = healthFail
+		}
+	}
+	writeHealth(w, report)
+}
+
+// handleReadyz 就绪检查，任一检查异常时返回503，用于Kubernetes readinessProbe和监控
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := checkHealth(r.Context())
+	report.Status = healthOK
+	for _, check := range report.Checks {
+		if check.Status == healthFail {
+			report.Status = healthFail
+		}
+	}
+	writeHealth(w, report)
+}
+
+// writeHealth 输出健康检查结果，异常时状态码为503
+func writeHealth(w http.ResponseWriter, report HealthReport) {
+	status := http.StatusOK
+	if report.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// checkHealth 执行所有检查
+func checkHealth(ctx context.Context) HealthReport {
+	config := configs.GlobalConfig.Health
+	checks := make(map[string]HealthCheck)
+
+	if pool := rpc.GlobalWebSocketPool; pool != nil {
+		checks["websocket"] = checkConnection(pool.IsConnected(), pool.LastMessageAt(), config.MaxMessageAge, true)
+	} else {
+		checks["websocket"] = HealthCheck{Status: healthSkipped}
+	}
+	if client := rpc.GlobalPumpPortalClient; client != nil {
+		// PumpPortal消息量取决于订阅内容，长时间无消息不一定是异常，因此不影响存活检查
+		checks["pump_portal"] = checkConnection(client.IsConnected(), client.LastMessageAt(), config.MaxMessageAge, false)
+	} else {
+		checks["pump_portal"] = HealthCheck{Status: healthSkipped}
+	}
+
+	checks["redis"] = checkRedis(ctx)
+	checks["enhanced_api"] = checkEnhancedAPI()
+
+	if storage.GlobalBlockQueue != nil {
+		checks["block_queue"] = checkQueue(storage.GlobalBlockQueue.Staleness(), config.MaxQueueStaleness)
+		checks["transaction_queue"] = checkQueue(storage.GlobalTransactionQueue.Staleness(), config.MaxQueueStaleness)
+	} else {
+		checks["block_queue"] = HealthCheck{Status: healthSkipped, Liveness: true}
+		checks["transaction_queue"] = HealthCheck{Status: healthSkipped, Liveness: true}
+	}
+
+	return HealthReport{Checks: checks, Time: clock.Now()}
+}
+
+// checkConnection 检查WebSocket连接状态和最近一次消息的时间
+func checkConnection(connected bool, lastMessageAt time.Time, maxAge time.Duration, liveness bool) HealthCheck {
+	if !connected {
+		return HealthCheck{Status: healthFail, Message: "未连接", Liveness: liveness}
+	}
+	if lastMessageAt.IsZero() {
+		return HealthCheck{Status: healthOK, Message: "尚未收到消息", Liveness: liveness}
+	}
+	age := clock.Since(lastMessageAt).Truncate(time.Second)
+	if maxAge > 0 && age > maxAge {
+		return HealthCheck{Status: healthFail, Message: fmt.Sprintf("%s 未收到消息", age), Liveness: liveness}
+	}
+	return HealthCheck{Status: healthOK, Message: fmt.Sprintf("最近消息 %s 前", age), Liveness: liveness}
+}
+
+// checkRedis 对默认和各负载的Redis执行PING
+func checkRedis(ctx context.Context) HealthCheck {
+	clients := storage.RedisClients()
+	if len(clients) == 0 {
+		return HealthCheck{Status: healthSkipped}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var failed []string
+	for name, client := range clients {
+		if err := client.Ping(ctx); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return HealthCheck{Status: healthFail, Message: strings.Join(failed, "; ")}
+	}
+	return HealthCheck{Status: healthOK}
+}
+
+// checkEnhancedAPI 检查是否有Enhanced API密钥最近被拒绝
+func checkEnhancedAPI() HealthCheck {
+	count := rpc.GetEnhancedApiClientCount()
+	if count == 0 {
+		return HealthCheck{Status: healthSkipped}
+	}
+	var rejected []string
+	for i := range count {
+		if rpc.GetEnhancedApiClientByIndex(i).KeyRejected() {
+			rejected = append(rejected, fmt.Sprint(i))
+		}
+	}
+	if len(rejected) > 0 {
+		return HealthCheck{Status: healthFail, Message: fmt.Sprintf("%d/%d 个API密钥被拒绝，索引: %s", len(rejected), count, strings.Join(rejected, ","))}
+	}
+	return HealthCheck{Status: healthOK}
+}
+
+// checkQueue 检查队列是否长时间未出队
+func checkQueue(staleness, maxStaleness time.Duration) HealthCheck {
+	if maxStaleness > 0 && staleness > maxStaleness {
+		return HealthCheck{Status: healthFail, Message: fmt.Sprintf("%s 未出队", staleness.Truncate(time.Second)), Liveness: true}
+	}
+	return HealthCheck{Status: healthOK, Liveness: true}
+}
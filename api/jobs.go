@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/life2you/datas-go/jobs"
+)
+
+// handleListJobs 查询定时任务的调度状态和最近一次执行记录
+func handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if jobs.GlobalScheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "定时任务未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs.GlobalScheduler.Status(r.Context()))
+}
+
+// handleGetJobRuns 查询定时任务最近的执行记录，limit 默认20
+func handleGetJobRuns(w http.ResponseWriter, r *http.Request) {
+	if jobs.GlobalScheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "定时任务未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "limit 必须在1到1000之间")
+		return
+	}
+	runs, err := jobs.GlobalScheduler.Runs(r.Context(), r.PathValue("name"), limit)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// handleRunJob 手动触发定时任务，获取执行锁后在后台执行，执行结果通过执行记录查询
+func handleRunJob(w http.ResponseWriter, r *http.Request) {
+	if jobs.GlobalScheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "定时任务未启用")
+		return
+	}
+	name := r.PathValue("name")
+	err := jobs.GlobalScheduler.Trigger(name)
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, jobs.ErrJobRunning):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, map[string]string{"job": name, "status": "started"})
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/logger"
+	"go.uber.org/zap"
+)
+
+// logLevelsResponse 日志级别查询响应
+type logLevelsResponse struct {
+	Level  string            `json:"level"`  // 全局级别
+	Levels map[string]string `json:"levels"` // 模块级别
+}
+
+// setLogLevelRequest 日志级别修改请求
+type setLogLevelRequest struct {
+	Module string `json:"module"` // 模块名，为空时修改全局级别
+	Level  string `json:"level"`  // 日志级别，模块级别为空或"default"时恢复为全局级别
+}
+
+// handleGetLogLevels 查询当前日志级别
+func handleGetLogLevels(w http.ResponseWriter, r *http.Request) {
+	level, levels := logger.Levels()
+	writeJSON(w, http.StatusOK, logLevelsResponse{Level: level, Levels: levels})
+}
+
+// handleSetLogLevel 运行时修改日志级别
+func handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var request setLogLevelRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if err := logger.SetLevel(request.Module, request.Level); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	logger.Info("日志级别已修改", zap.String("module", request.Module), zap.String("level", request.Level))
+
+	level, levels := logger.Levels()
+	writeJSON(w, http.StatusOK, logLevelsResponse{Level: level, Levels: levels})
+}
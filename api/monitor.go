@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/monitor"
+)
+
+// handleGetStall 查询出块停滞检测状态
+func handleGetStall(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalStallDetector == nil {
+		writeError(w, http.StatusServiceUnavailable, "出块停滞检测未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, monitor.GlobalStallDetector.Status())
+}
+
+// handleGetCluster 查询分布式处理的实例心跳和当前实例的计数
+func handleGetCluster(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalCoordinator == nil {
+		writeError(w, http.StatusServiceUnavailable, "分布式处理未启用")
+		return
+	}
+	status, err := monitor.GlobalCoordinator.Status(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleGetLeader 查询WebSocket摄取的领导者选举状态
+func handleGetLeader(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalLeaderElector == nil {
+		writeError(w, http.StatusServiceUnavailable, "摄取领导者选举未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, monitor.GlobalLeaderElector.Status(r.Context()))
+}
+
+// handleGetCongestion 查询网络拥堵状态
+func handleGetCongestion(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalCongestionMonitor == nil {
+		writeError(w, http.StatusServiceUnavailable, "网络拥堵检测未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, monitor.GlobalCongestionMonitor.Stats())
+}
+
+// handleGetCapacity 查询容量报告，weeks 为汇总的周数，默认4周
+func handleGetCapacity(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalCapacityRecorder == nil {
+		writeError(w, http.StatusServiceUnavailable, "容量规划指标快照未启用")
+		return
+	}
+	weeks, err := queryInt64(r, "weeks", 4)
+	if err != nil || weeks <= 0 {
+		writeError(w, http.StatusBadRequest, "weeks 必须为正整数")
+		return
+	}
+	report, err := monitor.GlobalCapacityRecorder.Report(r.Context(), int(weeks))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleGetVerification 查询解析结果抽样校验的统计
+func handleGetVerification(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalVerifier == nil {
+		writeError(w, http.StatusServiceUnavailable, "解析结果抽样校验未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, monitor.GlobalVerifier.Stats())
+}
+
+// handleGetDiagnostics 查询运行时诊断快照，不写入日志
+func handleGetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, monitor.CollectDiagnostics(r.Context(), "admin"))
+}
+
+// handleDumpDiagnostics 将运行时诊断快照写入日志，与收到SIGUSR1时相同，并返回写入的快照
+func handleDumpDiagnostics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, monitor.DumpDiagnostics(r.Context(), "admin"))
+}
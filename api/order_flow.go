@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetOrderFlow 查询所有关注代币当前的买卖盘失衡
+func handleGetOrderFlow(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalOrderFlowTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "买卖盘失衡统计未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"order_flow": analytics.GlobalOrderFlowTracker.Snapshots(),
+	})
+}
+
+// handleGetOrderFlowSeries 查询指定代币的买卖盘失衡时间序列
+// since、until 为Unix时间戳，默认查询最近1小时
+func handleGetOrderFlowSeries(w http.ResponseWriter, r *http.Request) {
+	now := clock.Now().Unix()
+	since, err := queryInt64(r, "since", now-int64(time.Hour.Seconds()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := queryInt64(r, "until", now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mint := r.PathValue("mint")
+	series, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetOrderFlowSeries(r.Context(), mint, since, until)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := map[string]interface{}{
+		"mint":     mint,
+		"series":   series,
+		"orphaned": orphanedSlotsForMint(r, mint, since, until),
+	}
+	// 启用代币账户统计时一并返回同一时间范围内的账户创建/关闭序列
+	if analytics.GlobalTokenAccountTracker != nil {
+		accounts, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetTokenAccountSeries(r.Context(), mint, since, until)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response["token_accounts"] = accounts
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// queryInt64 读取整数查询参数，不存在时返回默认值
+func queryInt64(r *http.Request, name string, defaultValue int64) (int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s 必须为整数", name)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetOrphanedSlots 查询处理后没有被最终确认的槽位，按槽位降序，limit 默认100
+func handleGetOrphanedSlots(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 {
+		writeError(w, http.StatusBadRequest, "limit 必须大于0")
+		return
+	}
+	slots, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetOrphanedSlots(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"orphaned": slots,
+	})
+}
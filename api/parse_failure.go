@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetParseFailure 查询签名的解析失败记录及是否已被跳过
+func handleGetParseFailure(w http.ResponseWriter, r *http.Request) {
+	signature := r.PathValue("signature")
+	failures, err := storage.GetRedisClient(storage.WorkloadCache).GetParseFailures(r.Context(), []string{signature})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	failure, ok := failures[signature]
+	if !ok {
+		writeError(w, http.StatusNotFound, "签名没有解析失败记录: "+signature)
+		return
+	}
+	negativeConfig := configs.GlobalConfig.NegativeCache
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"failure": failure,
+		"skipped": negativeConfig.Enabled && failure.Attempts >= negativeConfig.MaxAttempts,
+	})
+}
+
+// handleDeleteParseFailure 删除签名的解析失败记录，下次遇到时重新调用Enhanced API解析
+func handleDeleteParseFailure(w http.ResponseWriter, r *http.Request) {
+	signature := r.PathValue("signature")
+	if err := storage.GetRedisClient(storage.WorkloadCache).ClearParseFailures(r.Context(), []string{signature}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// 每次调用Enhanced API最多解析的签名数量
+const enhancedBatchSize = 100
+
+// ParseSignaturesRequest 按签名解析交易的请求，定义在 models 中供 datasclient 使用
+type ParseSignaturesRequest = models.ParseSignaturesRequest
+
+// ParsedSignature 单个签名的解析结果
+type ParsedSignature = models.ParsedSignature
+
+// ParseSignaturesResponse 按签名解析交易的响应
+type ParseSignaturesResponse = models.ParseSignaturesResponse
+
+// RawTransaction 原始交易，格式与 getBlock 返回的交易或 getTransaction 的结果相同
+type RawTransaction struct {
+	resp.Transactions
+	Slot uint64 `json:"slot,omitempty"` // 区块高度，为空时使用请求中的 slot
+}
+
+// ParseRawRequest 本地解码原始交易的请求
+type ParseRawRequest struct {
+	Slot         uint64           `json:"slot,omitempty"` // 交易所在区块高度
+	Transactions []RawTransaction `json:"transactions"`   // 原始交易
+}
+
+// DecodedTransaction 单个原始交易的本地解码结果
+type DecodedTransaction struct {
+	Transaction        *parser.LocalTransaction   `json:"transaction"`             // 交易摘要和余额变化
+	TokenAccountEvents []parser.TokenAccountEvent `json:"token_account_events"`    // 创建和关闭的代币账户
+	ComputeBudget      parser.ComputeBudget       `json:"compute_budget"`          // 计算预算和调用的程序
+	FilterReason       string                     `json:"filter_reason,omitempty"` // 采集服务区块阶段会过滤该交易的原因，为空表示会被推入解析队列
+}
+
+// ParseRawResponse 本地解码原始交易的响应
+type ParseRawResponse struct {
+	Transactions []DecodedTransaction `json:"transactions"` // 解码结果，顺序与请求一致
+}
+
+// parseServer 独立解析服务，只提供解析能力，不依赖队列和Redis
+type parseServer struct {
+	config configs.ParseServerConfig
+	next   atomic.Uint64 // 轮询选择Enhanced API客户端
+}
+
+// NewParseServer 创建独立解析服务
+// 只注册解析相关路由，供其他采集系统复用本项目的解析逻辑，不使用队列和Redis
+func NewParseServer(config *configs.ParseServerConfig) *Server {
+	server := newServer("解析服务", config.Addr)
+	parse := &parseServer{config: *config}
+	server.HandleFunc("POST /v1/parse/signatures", parse.handleSignatures)
+	server.HandleFunc("POST /v1/parse/raw", parse.handleRaw)
+	server.HandleFunc("GET /healthz", parse.handleHealthz)
+	return server
+}
+
+// handleSignatures 调用Enhanced API按签名解析交易
+func (p *parseServer) handleSignatures(w http.ResponseWriter, r *http.Request) {
+	var request ParseSignaturesRequest
+	if !p.decode(w, r, &request) {
+		return
+	}
+	if len(request.Signatures) == 0 {
+		writeError(w, http.StatusBadRequest, "signatures 不能为空")
+		return
+	}
+	if len(request.Signatures) > p.config.MaxSignatures {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("signatures 最多 %d 个", p.config.MaxSignatures))
+		return
+	}
+	if request.Commitment != "" && request.Commitment != "confirmed" && request.Commitment != "finalized" {
+		writeError(w, http.StatusBadRequest, "commitment 可选值: confirmed, finalized")
+		return
+	}
+	if rpc.GetEnhancedApiClientCount() == 0 {
+		writeError(w, http.StatusServiceUnavailable, "未配置Enhanced API密钥")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), p.config.Timeout)
+	defer cancel()
+	parsed := make(map[string]*resp.ParsedTransaction, len(request.Signatures))
+	for batch := range slices.Chunk(request.Signatures, enhancedBatchSize) {
+		transactions, err := p.parseSignatures(ctx, &rpc.ParseOptions{Commitment: request.Commitment}, batch)
+		if err != nil {
+			// 上游限流、密钥达到预算或都在隔离中时返回429，限流时透传等待时间，便于调用方退避
+			if errors.Is(err, rpc.ErrRateLimited) || errors.Is(err, monitor.ErrAPIKeyBudgetExhausted) || errors.Is(err, monitor.ErrNoHealthyAPIKey) {
+				if wait, ok := rpc.RetryAfter(err); ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				}
+				writeError(w, http.StatusTooManyRequests, err.Error())
+				return
+			}
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		for i := range transactions {
+			parsed[transactions[i].Signature] = &transactions[i]
+		}
+	}
+
+	response := ParseSignaturesResponse{Transactions: []ParsedSignature{}, Missing: []string{}}
+	for _, signature := range request.Signatures {
+		transaction, ok := parsed[signature]
+		if !ok {
+			response.Missing = append(response.Missing, signature)
+			continue
+		}
+		response.Transactions = append(response.Transactions, ParsedSignature{
+			Signature:    signature,
+			Transaction:  transaction,
+			FilterReason: handler.ParsedTransactionFilterReason(*transaction),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseSignatures 轮询选择Enhanced API客户端解析一批签名
+func (p *parseServer) parseSignatures(ctx context.Context, options *rpc.ParseOptions, signatures []string) ([]resp.ParsedTransaction, error) {
+	index, err := monitor.NextAPIKey(int(p.next.Add(1)-1), rpc.GetEnhancedApiClientCount())
+	if err != nil {
+		return nil, err
+	}
+	body, err := rpc.GetEnhancedApiClientByIndex(index).ParseTransactions(ctx, options, signatures...)
+	if err != nil {
+		return nil, err
+	}
+	var transactions []resp.ParsedTransaction
+	if len(body) == 0 {
+		return transactions, nil
+	}
+	if err := json.Unmarshal(body, &transactions); err != nil {
+		return nil, fmt.Errorf("解析Enhanced API响应失败: %w", err)
+	}
+	return transactions, nil
+}
+
+// handleRaw 在本地解码原始交易，不调用任何外部接口
+func (p *parseServer) handleRaw(w http.ResponseWriter, r *http.Request) {
+	var request ParseRawRequest
+	if !p.decode(w, r, &request) {
+		return
+	}
+	if len(request.Transactions) == 0 {
+		writeError(w, http.StatusBadRequest, "transactions 不能为空")
+		return
+	}
+	if len(request.Transactions) > p.config.MaxTransactions {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("transactions 最多 %d 个", p.config.MaxTransactions))
+		return
+	}
+
+	response := ParseRawResponse{Transactions: make([]DecodedTransaction, 0, len(request.Transactions))}
+	for _, raw := range request.Transactions {
+		slot := raw.Slot
+		if slot == 0 {
+			slot = request.Slot
+		}
+		events := parser.DecodeTokenAccountEvents(raw.Transactions)
+		if events == nil {
+			events = []parser.TokenAccountEvent{}
+		}
+		response.Transactions = append(response.Transactions, DecodedTransaction{
+			Transaction:        parser.DecodeTransaction(slot, raw.Transactions),
+			TokenAccountEvents: events,
+			ComputeBudget:      parser.DecodeComputeBudget(raw.Transactions),
+			FilterReason:       handler.BlockTransactionFilterReason(raw.Transactions),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleHealthz 存活检查，解析服务无状态，进程可响应即为正常
+func (p *parseServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":       healthOK,
+		"enhanced_api": checkEnhancedAPI(),
+	})
+}
+
+// decode 读取请求体，超过大小上限或格式错误时输出错误响应并返回false
+func (p *parseServer) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, p.config.MaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("请求体超过 %d 字节", p.config.MaxBodyBytes))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, "解析请求失败: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/payload"
+)
+
+// payloadSampler 返回解析失败原始数据抽样，未启用时输出503并返回nil
+func payloadSampler(w http.ResponseWriter) *payload.Sampler {
+	if payload.GlobalSampler == nil {
+		writeError(w, http.StatusServiceUnavailable, "解析失败的原始数据抽样未启用")
+		return nil
+	}
+	return payload.GlobalSampler
+}
+
+// handleListPayloadSamples 查询解析失败的错误类别，不含原始数据
+func handleListPayloadSamples(w http.ResponseWriter, r *http.Request) {
+	sampler := payloadSampler(w)
+	if sampler == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"groups": sampler.Groups()})
+}
+
+// handleGetPayloadSamples 查询错误类别最近的原始数据样本
+func handleGetPayloadSamples(w http.ResponseWriter, r *http.Request) {
+	sampler := payloadSampler(w)
+	if sampler == nil {
+		return
+	}
+	group, ok := sampler.Group(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "错误类别不存在: "+r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+// handleClearPayloadSamples 删除所有错误类别和样本
+func handleClearPayloadSamples(w http.ResponseWriter, r *http.Request) {
+	sampler := payloadSampler(w)
+	if sampler == nil {
+		return
+	}
+	sampler.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// handleGetSubscribers 查询进程内事件订阅者的投递与丢弃统计
+func handleGetSubscribers(w http.ResponseWriter, r *http.Request) {
+	if pipeline.GlobalPipeline == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"subscribers": []pipeline.SubscriberStats{}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscribers": pipeline.GlobalPipeline.Stats(),
+	})
+}
+
+// handleGetStages 查询采集流程各阶段的启动状态
+func handleGetStages(w http.ResponseWriter, r *http.Request) {
+	if pipeline.GlobalStages == nil {
+		writeError(w, http.StatusServiceUnavailable, "阶段编排未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"stages": pipeline.GlobalStages.Status(),
+	})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetPoolCreations 查询新创建的流动性池，按槽位降序，limit 默认100
+func handleGetPoolCreations(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 {
+		writeError(w, http.StatusBadRequest, "limit 必须大于0")
+		return
+	}
+	pools, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetPoolCreations(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"pools": pools,
+	})
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetPositions 查询代币的持仓，按净数量降序，limit 默认100
+func handleGetPositions(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalPositionTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "持仓统计未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mint := r.PathValue("mint")
+	positions, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetPositions(r.Context(), mint)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	total := len(positions)
+	if limit > 0 && int64(len(positions)) > limit {
+		positions = positions[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"mint":      mint,
+		"wallets":   total,
+		"positions": positions,
+		"orphaned":  orphanedSlotsForMint(r, mint, 0, clock.Now().Unix()),
+	})
+}
+
+// handleGetPosition 查询单个钱包在代币上的持仓
+func handleGetPosition(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalPositionTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "持仓统计未启用")
+		return
+	}
+	mint, wallet := r.PathValue("mint"), r.PathValue("wallet")
+	position, ok, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetPosition(r.Context(), mint, wallet)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "钱包没有该代币的持仓记录: "+wallet)
+		return
+	}
+	writeJSON(w, http.StatusOK, position)
+}
+
+// handleGetAccumulators 查询时间范围内净流入最多的钱包，since、until 为Unix时间戳，默认最近7天，最长90天
+func handleGetAccumulators(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalPositionTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "持仓统计未启用")
+		return
+	}
+	now := clock.Now().Unix()
+	since, err := queryInt64(r, "since", now-int64((7*24*time.Hour).Seconds()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := queryInt64(r, "until", now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := queryInt64(r, "limit", 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if until < since || until-since > int64((90*24*time.Hour).Seconds()) {
+		writeError(w, http.StatusBadRequest, "时间范围无效，最长90天")
+		return
+	}
+	mint := r.PathValue("mint")
+	accumulations, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetAccumulations(r.Context(), mint, since, until, int(limit))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"mint":         mint,
+		"since":        since,
+		"until":        until,
+		"accumulators": accumulations,
+		"orphaned":     orphanedSlotsForMint(r, mint, since, until),
+	})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/analytics"
+)
+
+// handleGetPriorityFees 查询最近区块的推荐计算单元价格(微lamports/CU)
+// program 只统计调用了该程序的交易；blocks 只统计最近的若干个区块；percentiles 为逗号分隔的分位数，如 50,75,99
+func handleGetPriorityFees(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalPriorityFeeTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "计算单元价格统计未启用")
+		return
+	}
+	blocks, err := queryInt64(r, "blocks", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var percentiles []float64
+	if value := r.URL.Query().Get("percentiles"); value != "" {
+		percentiles, err = analytics.ParsePercentiles(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	estimate := analytics.GlobalPriorityFeeTracker.Estimate(r.URL.Query().Get("program"), int(blocks), percentiles)
+	writeJSON(w, http.StatusOK, estimate)
+}
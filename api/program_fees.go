@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetProgramFees 查询各程序每小时的手续费、优先费和平均计算单元价格，以及时间范围内按程序的汇总
+// since、until 为Unix时间戳，默认查询最近24小时；program 为程序ID或别名，只返回该程序的统计
+func handleGetProgramFees(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalProgramFeeTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "按程序统计手续费未启用")
+		return
+	}
+	now := clock.Now().Unix()
+	since, err := queryInt64(r, "since", now-int64((24*time.Hour).Seconds()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := queryInt64(r, "until", now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if until < since || until-since > int64((31*24*time.Hour).Seconds()) {
+		writeError(w, http.StatusBadRequest, "时间范围无效，最长31天")
+		return
+	}
+	program := r.URL.Query().Get("program")
+	if program != "" {
+		program = parser.ResolveProgram(program)
+	}
+	hourly, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetProgramFees(r.Context(), since, until, program)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	analytics.FillProgramFeeAverages(hourly)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":   since,
+		"until":   until,
+		"program": program,
+		"hourly":  hourly,
+		"totals":  analytics.SummarizeProgramFees(hourly),
+	})
+}
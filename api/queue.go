@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/storage"
+)
+
+// QueueStats 队列统计
+type QueueStats struct {
+	Block       int `json:"block"`       // 区块队列长度
+	Transaction int `json:"transaction"` // 交易队列长度，使用Redis Streams时为尚未确认的消息数
+
+	BlockBackfill       int `json:"block_backfill,omitempty"`       // 启用分道调度时，区块队列中回补通道的元素数
+	TransactionBackfill int `json:"transaction_backfill,omitempty"` // 启用分道调度时，交易队列中回补通道的元素数
+}
+
+// handleGetQueueStats 查询队列长度
+func handleGetQueueStats(w http.ResponseWriter, r *http.Request) {
+	var stats QueueStats
+	if storage.GlobalBlockQueue != nil {
+		stats.Block = storage.GlobalBlockQueue.Len()
+		_, stats.BlockBackfill = storage.GlobalBlockQueue.LaneLen()
+	}
+	if storage.GlobalTransactionStream != nil {
+		stats.Transaction = storage.GlobalTransactionStream.TransactionQueueLen()
+	} else if storage.GlobalTransactionQueue != nil {
+		stats.Transaction = storage.GlobalTransactionQueue.Len()
+		_, stats.TransactionBackfill = storage.GlobalTransactionQueue.LaneLen()
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
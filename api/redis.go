@@ -0,0 +1,12 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetRedisStatus 查询各Redis客户端的可用状态和降级期间缓存的写入数
+func handleGetRedisStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, storage.RedisStatus())
+}
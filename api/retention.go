@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleRetentionDryRun 按当前的 retention 配置试运行一次数据保留，返回各策略会处理的键数和键名样例，不修改数据
+// 单个策略失败时其错误记录在该策略的结果中，不影响其他策略
+func handleRetentionDryRun(w http.ResponseWriter, r *http.Request) {
+	config := configs.GlobalConfig.Retention
+	report, _ := storage.ApplyRetention(r.Context(), &config, true)
+	metrics.RecordRetention(report)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleGetRetentionStats 查询数据保留累计处理的键数和最近一次执行的报告
+func handleGetRetentionStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.Retention())
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/life2you/datas-go/rules"
+)
+
+// 查询告警时的默认数量
+const defaultAlertCount = 100
+
+// ruleEngine 获取规则引擎，未启用时输出错误响应
+func ruleEngine(w http.ResponseWriter) *rules.Engine {
+	if rules.GlobalEngine == nil {
+		writeError(w, http.StatusServiceUnavailable, "规则引擎未启用")
+	}
+	return rules.GlobalEngine
+}
+
+// writeRuleError 根据错误类型输出规则操作的错误响应
+func writeRuleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, rules.ErrRuleNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
+// handleListRules 查询所有规则
+func handleListRules(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rules": engine.Rules()})
+}
+
+// handleGetRule 查询指定规则
+func handleGetRule(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	rule, err := engine.GetRule(r.PathValue("id"))
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleCreateRule 创建规则
+func handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	var rule rules.Rule
+	if !decodeJSON(w, r, &rule) {
+		return
+	}
+	created, err := engine.CreateRule(r.Context(), rule)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdateRule 修改指定规则
+func handleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	var rule rules.Rule
+	if !decodeJSON(w, r, &rule) {
+		return
+	}
+	updated, err := engine.UpdateRule(r.Context(), r.PathValue("id"), rule)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteRule 删除指定规则
+func handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	if err := engine.DeleteRule(r.Context(), r.PathValue("id")); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAlerts 查询最近的告警，count 参数指定数量，address 参数只返回该关注地址的通知
+func handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	engine := ruleEngine(w)
+	if engine == nil {
+		return
+	}
+	count := int64(defaultAlertCount)
+	if value := r.URL.Query().Get("count"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "count 必须为正整数")
+			return
+		}
+		count = parsed
+	}
+	alerts, err := engine.Alerts(r.Context(), count)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if address := r.URL.Query().Get("address"); address != "" {
+		alerts = slices.DeleteFunc(alerts, func(alert rules.Alert) bool {
+			return alert.Address != address
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"alerts": alerts})
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"go.uber.org/zap"
+)
+
+// Server HTTP接口服务，管理接口和独立解析服务共用
+type Server struct {
+	httpServer *http.Server
+	mux        *http.ServeMux
+	name       string
+	addr       string
+}
+
+var GlobalServer *Server
+
+// newServer 创建未注册路由的HTTP服务，name 用于日志
+func newServer(name, addr string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		mux:  mux,
+		name: name,
+		addr: addr,
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// NewServer 创建管理HTTP接口服务并注册内置路由
+func NewServer(config *configs.AdminConfig) *Server {
+	server := newServer("管理接口", config.Addr)
+	server.httpServer.Handler = adminHandler(config, server.mux)
+
+	// 内置路由
+	server.HandleFunc("GET /healthz", handleHealthz)
+	server.HandleFunc("GET /readyz", handleReadyz)
+	server.HandleFunc("GET /admin/log/levels", handleGetLogLevels)
+	server.HandleFunc("POST /admin/log/levels", handleSetLogLevel)
+	server.HandleFunc("GET /admin/sources/unknown", handleGetUnknownSources)
+	server.HandleFunc("GET /admin/sources/volume", handleGetSourceVolume)
+	server.HandleFunc("GET /admin/parse-failures/{signature}", handleGetParseFailure)
+	server.HandleFunc("DELETE /admin/parse-failures/{signature}", handleDeleteParseFailure)
+	server.HandleFunc("GET /admin/payload-samples", handleListPayloadSamples)
+	server.HandleFunc("GET /admin/payload-samples/{id}", handleGetPayloadSamples)
+	server.HandleFunc("DELETE /admin/payload-samples", handleClearPayloadSamples)
+	server.HandleFunc("GET /admin/pipeline/subscribers", handleGetSubscribers)
+	server.HandleFunc("GET /admin/pipeline/stages", handleGetStages)
+	server.HandleFunc("GET /admin/queue/stats", handleGetQueueStats)
+	server.HandleFunc("GET /admin/control", handleGetControl)
+	server.HandleFunc("POST /admin/control/{stage}/pause", handlePauseStage)
+	server.HandleFunc("POST /admin/control/{stage}/resume", handleResumeStage)
+	server.HandleFunc("POST /admin/control/drain", handleStartDrain)
+	server.HandleFunc("DELETE /admin/control/drain", handleStopDrain)
+	server.HandleFunc("POST /admin/backfill", handleBackfill)
+	server.HandleFunc("GET /admin/redis", handleGetRedisStatus)
+	server.HandleFunc("GET /admin/rules", handleListRules)
+	server.HandleFunc("POST /admin/rules", handleCreateRule)
+	server.HandleFunc("GET /admin/rules/{id}", handleGetRule)
+	server.HandleFunc("PUT /admin/rules/{id}", handleUpdateRule)
+	server.HandleFunc("DELETE /admin/rules/{id}", handleDeleteRule)
+	server.HandleFunc("GET /admin/watchlist", handleListWatchlist)
+	server.HandleFunc("GET /admin/watchlist/{address}", handleGetWatchedAddress)
+	server.HandleFunc("PUT /admin/watchlist/{address}", handlePutWatchedAddress)
+	server.HandleFunc("DELETE /admin/watchlist/{address}", handleDeleteWatchedAddress)
+	server.HandleFunc("GET /admin/alerts", handleListAlerts)
+	server.HandleFunc("GET /admin/orderflow", handleGetOrderFlow)
+	server.HandleFunc("GET /admin/orderflow/{mint}", handleGetOrderFlowSeries)
+	server.HandleFunc("GET /admin/token-accounts", handleGetTokenAccounts)
+	server.HandleFunc("GET /admin/token-accounts/{mint}", handleGetTokenAccountSeries)
+	server.HandleFunc("GET /admin/tokens", handleGetTopTokenStats)
+	server.HandleFunc("GET /admin/tokens/{mint}/stats", handleGetTokenStats)
+	server.HandleFunc("GET /admin/tokens/{mint}/transactions", handleGetMintTransactions)
+	server.HandleFunc("GET /admin/transactions/{signature}", handleGetTransaction)
+	server.HandleFunc("GET /latest/dex/tokens/{mints}", handleGetDexTokens)
+	server.HandleFunc("GET /admin/curves", handleGetBondingCurves)
+	server.HandleFunc("GET /admin/curves/{mint}", handleGetBondingCurve)
+	server.HandleFunc("GET /admin/token-risk/{mint}", handleGetTokenRisk)
+	server.HandleFunc("GET /admin/positions/{mint}", handleGetPositions)
+	server.HandleFunc("GET /admin/positions/{mint}/accumulators", handleGetAccumulators)
+	server.HandleFunc("GET /admin/positions/{mint}/{wallet}", handleGetPosition)
+	server.HandleFunc("GET /admin/priority-fees", handleGetPriorityFees)
+	server.HandleFunc("GET /admin/websocket", handleGetWebSocket)
+	server.HandleFunc("GET /admin/stall", handleGetStall)
+	server.HandleFunc("GET /admin/cluster", handleGetCluster)
+	server.HandleFunc("GET /admin/leader", handleGetLeader)
+	server.HandleFunc("GET /admin/jobs", handleListJobs)
+	server.HandleFunc("GET /admin/jobs/{name}/runs", handleGetJobRuns)
+	server.HandleFunc("POST /admin/jobs/{name}/run", handleRunJob)
+	server.HandleFunc("POST /admin/retention/dry-run", handleRetentionDryRun)
+	server.HandleFunc("GET /admin/orphaned", handleGetOrphanedSlots)
+	server.HandleFunc("GET /admin/pools", handleGetPoolCreations)
+	server.HandleFunc("GET /admin/congestion", handleGetCongestion)
+	server.HandleFunc("GET /admin/capacity", handleGetCapacity)
+	server.HandleFunc("GET /admin/clickhouse", handleGetClickHouse)
+	server.HandleFunc("GET /admin/diagnostics", handleGetDiagnostics)
+	server.HandleFunc("POST /admin/diagnostics/dump", handleDumpDiagnostics)
+	server.HandleFunc("GET /stats/lag", handleGetLag)
+	server.HandleFunc("GET /stats/webhook", handleGetWebhookStats)
+	server.HandleFunc("GET /stats/retention", handleGetRetentionStats)
+	server.HandleFunc("GET /stats/goroutines", handleGetGoroutines)
+	server.HandleFunc("GET /stats/prefetch", handleGetPrefetchStats)
+	server.HandleFunc("GET /stats/api-keys", handleGetAPIKeyUsage)
+	server.HandleFunc("GET /stats/skipped-slots", handleGetSkippedSlotStats)
+	server.HandleFunc("GET /stats/program-fees", handleGetProgramFees)
+	server.HandleFunc("GET /admin/verification", handleGetVerification)
+	server.HandleFunc("GET /admin/cursor", handleGetCursor)
+	server.HandleFunc("GET /admin/blocks/states", handleGetBlockStates)
+	server.HandleFunc("GET /admin/blocks/stuck", handleGetStuckBlocks)
+	server.HandleFunc("GET /admin/skipped-slots", handleListSkippedSlots)
+	server.HandleFunc("POST /admin/skipped-slots/{slot}/retry", handleRetrySkippedSlot)
+	server.HandleFunc("DELETE /admin/skipped-slots/{slot}", handleDeleteSkippedSlot)
+	server.HandleFunc("GET /admin/blocks/summaries", handleGetBlockSummaries)
+	server.HandleFunc("GET /admin/blocks/{slot}/summary", handleGetBlockSummary)
+	server.HandleFunc("GET /admin/blocks/{slot}", handleGetBlockState)
+	server.HandleFunc("GET /admin/blockhash/{blockhash}", handleGetBlockByHash)
+
+	GlobalServer = server
+	return server
+}
+
+// HandleFunc 注册路由，pattern 格式与 http.ServeMux 相同，如 "GET /admin/log/levels"
+func (s *Server) HandleFunc(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Start 在后台启动HTTP服务
+func (s *Server) Start() {
+	go func() {
+		logger.Info(s.name+"已启动", zap.String("addr", s.addr))
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(s.name+"异常退出", zap.Error(err))
+		}
+	}()
+}
+
+// Close 关闭HTTP服务
+func (s *Server) Close(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("写入响应失败", zap.Error(err))
+	}
+}
+
+// writeError 输出JSON格式的错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/monitor"
+)
+
+// handleListSkippedSlots 查询跳过列表中的槽位，按槽位降序排列，limit 默认100
+func handleListSkippedSlots(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalSlotSkipList == nil {
+		writeError(w, http.StatusServiceUnavailable, "跳过列表未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 {
+		writeError(w, http.StatusBadRequest, "limit 必须大于0")
+		return
+	}
+	skipped, err := monitor.GlobalSlotSkipList.List(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, skipped)
+}
+
+// handleRetrySkippedSlot 立即将跳过列表中的槽位重新推入区块队列，包括已放弃自动重试的槽位
+func handleRetrySkippedSlot(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalSlotSkipList == nil {
+		writeError(w, http.StatusServiceUnavailable, "跳过列表未启用")
+		return
+	}
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "slot 必须为整数")
+		return
+	}
+	err = monitor.GlobalSlotSkipList.Retry(r.Context(), slot)
+	switch {
+	case errors.Is(err, monitor.ErrSkippedSlotNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"slot": slot,
+		})
+	}
+}
+
+// handleDeleteSkippedSlot 从跳过列表中删除槽位，不再重试
+func handleDeleteSkippedSlot(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalSlotSkipList == nil {
+		writeError(w, http.StatusServiceUnavailable, "跳过列表未启用")
+		return
+	}
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "slot 必须为整数")
+		return
+	}
+	err = monitor.GlobalSlotSkipList.Delete(r.Context(), slot)
+	switch {
+	case errors.Is(err, monitor.ErrSkippedSlotNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleGetSkippedSlotStats 查询跳过列表的累计计数
+func handleGetSkippedSlotStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.SkippedSlots())
+}
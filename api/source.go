@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetUnknownSources 查询无法识别的交易来源名称及出现次数，用于补充来源别名映射表
+func handleGetUnknownSources(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"unknown_sources": resp.UnknownSources(),
+	})
+}
+
+// handleGetSourceVolume 查询各交易来源每小时的交易数和SOL成交量，以及时间范围内的汇总和占比
+// since、until 为Unix时间戳，默认查询最近24小时
+func handleGetSourceVolume(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalSourceVolumeTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "按来源统计未启用")
+		return
+	}
+	now := clock.Now().Unix()
+	since, err := queryInt64(r, "since", now-int64((24*time.Hour).Seconds()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := queryInt64(r, "until", now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if until < since || until-since > int64((31*24*time.Hour).Seconds()) {
+		writeError(w, http.StatusBadRequest, "时间范围无效，最长31天")
+		return
+	}
+	hourly, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetSourceVolumes(r.Context(), since, until)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":    since,
+		"until":    until,
+		"hourly":   hourly,
+		"totals":   analytics.SummarizeSourceVolumes(hourly),
+		"orphaned": orphanedSlotsBetween(r, since, until),
+	})
+}
+
+// orphanedSlotsBetween 返回出块时间在范围内、已计入统计但没有被最终确认的槽位，用于标记受影响的统计时段
+func orphanedSlotsBetween(r *http.Request, since, until int64) []models.OrphanedSlot {
+	orphaned := []models.OrphanedSlot{}
+	slots, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetOrphanedSlots(r.Context(), 0)
+	if err != nil {
+		return orphaned
+	}
+	for _, slot := range slots {
+		if slot.Transactions > 0 && slot.BlockTime >= since && slot.BlockTime <= until {
+			orphaned = append(orphaned, slot)
+		}
+	}
+	return orphaned
+}
+
+// orphanedSlotsForMint 返回出块时间在范围内、已索引交易涉及该代币但没有被最终确认的槽位
+// 按代币统计的数据(买卖盘失衡、代币账户、24小时统计、持仓)不会回滚，用于标记可能受影响的数据
+func orphanedSlotsForMint(r *http.Request, mint string, since, until int64) []models.OrphanedSlot {
+	orphaned := []models.OrphanedSlot{}
+	for _, slot := range orphanedSlotsBetween(r, since, until) {
+		if slices.Contains(slot.Mints, mint) {
+			orphaned = append(orphaned, slot)
+		}
+	}
+	return orphaned
+}
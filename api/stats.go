@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/monitor"
+)
+
+// maxAPIKeyUsageDays 查询API密钥每日用量的最大天数
+const maxAPIKeyUsageDays = 90
+
+// handleGetLag 查询采集进度相对网络的延迟和出块速度
+func handleGetLag(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.Lag())
+}
+
+// handleGetWebhookStats 查询Webhook接收的事件数和重复投递比例
+func handleGetWebhookStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.Webhook())
+}
+
+// handleGetPrefetchStats 查询区块预取的命中率和预取数量
+func handleGetPrefetchStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.Prefetch())
+}
+
+// handleGetGoroutines 查询发生过panic的受监管协程的panic次数、重启次数和最近一次panic
+func handleGetGoroutines(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metrics.Goroutines())
+}
+
+// handleGetAPIKeyUsage 查询每个Enhanced API密钥的预算、当天及最近几天的用量
+// 查询参数 days 为返回的天数，默认1(只返回当天)
+func handleGetAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	if monitor.GlobalAPIKeyUsage == nil {
+		writeError(w, http.StatusServiceUnavailable, "未启用API密钥用量统计")
+		return
+	}
+	days, err := queryInt64(r, "days", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if days < 1 || days > maxAPIKeyUsageDays {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("days 必须在1到%d之间", maxAPIKeyUsageDays))
+		return
+	}
+	writeJSON(w, http.StatusOK, monitor.GlobalAPIKeyUsage.Usage(r.Context(), int(days)))
+}
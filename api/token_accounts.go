@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetTokenAccounts 查询当前区间内尚未保存的代币账户创建/关闭计数
+// limit 为返回的代币数量，按净增数量降序，默认100
+func handleGetTokenAccounts(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalTokenAccountTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "代币账户创建/关闭统计未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pending := analytics.GlobalTokenAccountTracker.Pending()
+	if limit > 0 && int64(len(pending)) > limit {
+		pending = pending[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token_accounts": pending,
+	})
+}
+
+// handleGetTokenAccountSeries 查询指定代币的代币账户创建/关闭时间序列
+// since、until 为Unix时间戳，默认查询最近1小时
+func handleGetTokenAccountSeries(w http.ResponseWriter, r *http.Request) {
+	now := clock.Now().Unix()
+	since, err := queryInt64(r, "since", now-int64(time.Hour.Seconds()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := queryInt64(r, "until", now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mint := r.PathValue("mint")
+	series, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetTokenAccountSeries(r.Context(), mint, since, until)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"mint":     mint,
+		"series":   series,
+		"orphaned": orphanedSlotsForMint(r, mint, since, until),
+	})
+}
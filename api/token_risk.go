@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetTokenRisk 查询代币的风险检测结果，refresh=true 时立即重新检测并保存
+func handleGetTokenRisk(w http.ResponseWriter, r *http.Request) {
+	analyzer := analytics.GlobalTokenRiskAnalyzer
+	if analyzer == nil {
+		writeError(w, http.StatusServiceUnavailable, "新代币风险检测未启用")
+		return
+	}
+	mint := r.PathValue("mint")
+	refresh := false
+	if value := r.URL.Query().Get("refresh"); value != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, "refresh 必须是布尔值")
+			return
+		}
+	}
+	redisClient := storage.GetRedisClient(storage.WorkloadAnalytics)
+	if !refresh {
+		report, err := redisClient.GetTokenRisk(r.Context(), mint)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if report == nil {
+			writeError(w, http.StatusNotFound, "代币没有风险检测结果: "+mint)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+
+	report, err := analyzer.Analyze(r.Context(), mint, "api", "")
+	if errors.Is(err, rpc.ErrAccountNotFound) {
+		writeError(w, http.StatusNotFound, "代币不存在: "+mint)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if err := redisClient.StoreTokenRisk(r.Context(), *report, configs.GlobalConfig.TokenRisk.TTL); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
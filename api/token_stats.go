@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+)
+
+// maxDexTokens 单次查询的代币数上限，与Dexscreener接口相同
+const maxDexTokens = 30
+
+// handleGetTopTokenStats 查询24小时成交量最大的代币统计，limit 默认20
+func handleGetTopTokenStats(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalTokenStatsTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "代币24小时统计未启用")
+		return
+	}
+	limit, err := queryInt64(r, "limit", 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 || limit > 500 {
+		writeError(w, http.StatusBadRequest, "limit 必须在1到500之间")
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics.GlobalTokenStatsTracker.Top(int(limit)))
+}
+
+// handleGetTokenStats 查询单个代币的24小时统计
+func handleGetTokenStats(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalTokenStatsTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "代币24小时统计未启用")
+		return
+	}
+	mint := r.PathValue("mint")
+	stats := analytics.GlobalTokenStatsTracker.Stats([]string{mint})
+	if len(stats.Pairs) == 0 {
+		writeError(w, http.StatusNotFound, "代币没有统计数据: "+mint)
+		return
+	}
+	now := clock.Now().Unix()
+	writeJSON(w, http.StatusOK, struct {
+		models.TokenStats
+		Orphaned []models.OrphanedSlot `json:"orphaned"` // 24小时内涉及该代币、没有被最终确认的槽位
+	}{stats.Pairs[0], orphanedSlotsForMint(r, mint, now-int64((24*time.Hour).Seconds()), now)})
+}
+
+// handleGetDexTokens 与Dexscreener /latest/dex/tokens/{tokenAddresses} 兼容的查询接口，多个代币用逗号分隔
+// 没有统计数据的代币不在结果中，全部没有时 pairs 为空数组
+func handleGetDexTokens(w http.ResponseWriter, r *http.Request) {
+	if analytics.GlobalTokenStatsTracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "代币24小时统计未启用")
+		return
+	}
+	mints := make([]string, 0)
+	for _, mint := range strings.Split(r.PathValue("mints"), ",") {
+		if mint = strings.TrimSpace(mint); mint != "" {
+			mints = append(mints, mint)
+		}
+	}
+	if len(mints) == 0 || len(mints) > maxDexTokens {
+		writeError(w, http.StatusBadRequest, "代币地址数必须在1到30之间")
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics.GlobalTokenStatsTracker.Stats(mints))
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+)
+
+// handleGetTransaction 按签名查询缓存的Enhanced API解析结果
+func handleGetTransaction(w http.ResponseWriter, r *http.Request) {
+	signature := r.PathValue("signature")
+	cached, err := storage.GetRedisClient(storage.WorkloadCache).GetEnrichedTransactions(r.Context(), []string{signature})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	raw, ok := cached[signature]
+	if !ok {
+		writeError(w, http.StatusNotFound, "没有该交易的解析结果: "+signature)
+		return
+	}
+	writeJSON(w, http.StatusOK, raw)
+}
+
+// handleGetMintTransactions 查询代币在某一天的交易签名
+// 查询参数 time 为该天内的任意Unix时间戳，默认当天；limit 默认100
+func handleGetMintTransactions(w http.ResponseWriter, r *http.Request) {
+	timestamp, err := queryInt64(r, "time", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := queryInt64(r, "limit", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "limit 必须在1到1000之间")
+		return
+	}
+	mint := r.PathValue("mint")
+	day := storage.IndexDay(timestamp)
+	signatures, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetMintTransactions(r.Context(), mint, day, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, models.MintTransactions{Mint: mint, Day: day, Signatures: signatures})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/life2you/datas-go/watchlist"
+)
+
+// watchedAddresses 获取关注地址列表，未启用时输出错误响应
+func watchedAddresses(w http.ResponseWriter) *watchlist.Watchlist {
+	if watchlist.GlobalWatchlist == nil {
+		writeError(w, http.StatusServiceUnavailable, "关注地址列表未启用，需启用规则引擎或 watchlist.enabled")
+	}
+	return watchlist.GlobalWatchlist
+}
+
+// writeWatchlistError 根据错误类型输出关注地址操作的错误响应
+func writeWatchlistError(w http.ResponseWriter, err error) {
+	if errors.Is(err, watchlist.ErrEntryNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
+// handleListWatchlist 查询所有关注地址及其通知设置
+func handleListWatchlist(w http.ResponseWriter, r *http.Request) {
+	list := watchedAddresses(w)
+	if list == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"watchlist": list.Entries()})
+}
+
+// handleGetWatchedAddress 查询指定地址的通知设置
+func handleGetWatchedAddress(w http.ResponseWriter, r *http.Request) {
+	list := watchedAddresses(w)
+	if list == nil {
+		return
+	}
+	entry, err := list.Get(r.PathValue("address"))
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handlePutWatchedAddress 关注地址或整体替换其通知设置
+func handlePutWatchedAddress(w http.ResponseWriter, r *http.Request) {
+	list := watchedAddresses(w)
+	if list == nil {
+		return
+	}
+	var entry watchlist.Entry
+	if !decodeJSON(w, r, &entry) {
+		return
+	}
+	entry.Address = r.PathValue("address")
+	saved, err := list.Save(r.Context(), entry)
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// handleDeleteWatchedAddress 取消关注指定地址
+func handleDeleteWatchedAddress(w http.ResponseWriter, r *http.Request) {
+	list := watchedAddresses(w)
+	if list == nil {
+		return
+	}
+	if err := list.Delete(r.Context(), r.PathValue("address")); err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
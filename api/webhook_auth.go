@@ -0,0 +1,135 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/life2you/datas-go/configs"
+)
+
+// Webhook回调请求被拒绝的原因，用于拒绝计数
+const (
+	webhookRejectIP         = "ip"          // 来源IP不在允许列表中
+	webhookRejectAuthHeader = "auth_header" // Authorization头不匹配
+	webhookRejectHMAC       = "hmac"        // 请求体签名不匹配
+)
+
+// webhookAuth Webhook回调请求的认证规则，各项未配置时不校验
+type webhookAuth struct {
+	authHeaders    []string       // 接受的Authorization头
+	hmacSecret     []byte         // 请求体HMAC-SHA256签名的共享密钥
+	hmacHeader     string         // 携带签名的请求头
+	allowed        []netip.Prefix // 允许的来源地址
+	clientIPHeader string         // 读取来源IP的请求头，为空时使用连接地址
+}
+
+// newWebhookAuth 按配置创建认证规则
+// receiver.auth_header 为空时接受 webhooks 中声明的任一 auth_header，声明同步到Helius后回调会携带对应的值
+func newWebhookAuth(config *configs.HeliusWebhookConfig) *webhookAuth {
+	receiver := config.Receiver
+	auth := &webhookAuth{
+		hmacHeader:     receiver.HMACHeader,
+		clientIPHeader: receiver.ClientIPHeader,
+	}
+	if receiver.AuthHeader != "" {
+		auth.authHeaders = []string{receiver.AuthHeader}
+	} else {
+		for _, webhook := range config.Webhooks {
+			if webhook.AuthHeader != "" {
+				auth.authHeaders = append(auth.authHeaders, webhook.AuthHeader)
+			}
+		}
+	}
+	if receiver.HMACSecret != "" {
+		auth.hmacSecret = []byte(receiver.HMACSecret)
+	}
+	// 配置校验已保证格式正确，单个IP按完整前缀匹配
+	for _, allowed := range receiver.AllowedIPs {
+		if prefix, err := netip.ParsePrefix(allowed); err == nil {
+			auth.allowed = append(auth.allowed, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(allowed); err == nil {
+			addr = addr.Unmap()
+			auth.allowed = append(auth.allowed, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return auth
+}
+
+// checkRequest 校验来源IP和Authorization头，在读取请求体之前调用
+// 返回:
+//   - string: 拒绝原因，为空表示通过
+func (a *webhookAuth) checkRequest(r *http.Request) string {
+	if len(a.allowed) > 0 && !a.ipAllowed(a.clientIP(r)) {
+		return webhookRejectIP
+	}
+	if len(a.authHeaders) > 0 {
+		header := r.Header.Get("Authorization")
+		matched := false
+		for _, expected := range a.authHeaders {
+			// 逐个比较且不提前返回，耗时与匹配的是哪一个无关
+			if subtle.ConstantTimeCompare([]byte(header), []byte(expected)) == 1 {
+				matched = true
+			}
+		}
+		if !matched {
+			return webhookRejectAuthHeader
+		}
+	}
+	return ""
+}
+
+// checkBody 校验请求体的HMAC签名
+// 返回:
+//   - string: 拒绝原因，为空表示通过
+func (a *webhookAuth) checkBody(r *http.Request, body []byte) string {
+	if a.hmacSecret == nil {
+		return ""
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(r.Header.Get(a.hmacHeader)), "sha256="))
+	if err != nil || len(signature) == 0 {
+		return webhookRejectHMAC
+	}
+	mac := hmac.New(sha256.New, a.hmacSecret)
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return webhookRejectHMAC
+	}
+	return ""
+}
+
+// clientIP 返回请求的来源IP，无法解析时返回无效地址
+func (a *webhookAuth) clientIP(r *http.Request) netip.Addr {
+	if a.clientIPHeader != "" {
+		if value := r.Header.Get(a.clientIPHeader); value != "" {
+			first, _, _ := strings.Cut(value, ",")
+			addr, _ := netip.ParseAddr(strings.TrimSpace(first))
+			return addr
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, _ := netip.ParseAddr(host)
+	return addr
+}
+
+// ipAllowed 判断来源IP是否在允许列表中
+func (a *webhookAuth) ipAllowed(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range a.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// WebhookEventHandler 处理去重后的Webhook事件
+type WebhookEventHandler func(ctx context.Context, events []rpc.WebhookEvent)
+
+// GlobalWebhookServer 全局Webhook回调接收服务
+var GlobalWebhookServer *Server
+
+// webhookServer Helius Webhook回调接收服务
+type webhookServer struct {
+	config configs.WebhookReceiverConfig
+	auth   *webhookAuth
+	handle WebhookEventHandler
+	log    *zap.Logger
+}
+
+// NewWebhookServer 创建Webhook回调接收服务
+// 参数:
+//   - config: Webhook配置，使用其中的 receiver 和声明的 auth_header
+//   - handle: 事件处理函数，只收到去重窗口内首次投递的事件
+//
+// 返回:
+//   - *Server: 只注册回调路由和存活检查的HTTP服务
+func NewWebhookServer(config *configs.HeliusWebhookConfig, handle WebhookEventHandler) *Server {
+	server := newServer("Webhook接收服务", config.Receiver.Addr)
+	receiver := &webhookServer{
+		config: config.Receiver,
+		auth:   newWebhookAuth(config),
+		handle: handle,
+		log:    logger.Named("api.webhook"),
+	}
+	server.HandleFunc("POST "+config.Receiver.Path, receiver.handleWebhook)
+	server.HandleFunc("GET /healthz", handleHealthz)
+	GlobalWebhookServer = server
+	return server
+}
+
+// handleWebhook 接收一次回调，丢弃重复投递的事件后交给处理函数
+// 处理完成后才返回200，Helius收到非2xx响应或超时时会重试投递；未通过认证的请求返回401
+func (s *webhookServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if reason := s.auth.checkRequest(r); reason != "" {
+		s.reject(w, r, reason)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("请求体超过 %d 字节", s.config.MaxBodyBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "读取请求失败: "+err.Error())
+		return
+	}
+
+	if reason := s.auth.checkBody(r, body); reason != "" {
+		s.reject(w, r, reason)
+		return
+	}
+
+	var received, duplicates int
+	err = rpc.HandleWebhookEvent(body, func(events []rpc.WebhookEvent) error {
+		received = len(events)
+		events = s.dedupe(r.Context(), events)
+		duplicates = received - len(events)
+		if len(events) > 0 {
+			s.handle(r.Context(), events)
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	metrics.AddWebhookEvents(received)
+	metrics.AddWebhookDuplicates(duplicates)
+	if duplicates > 0 {
+		s.log.Debug("丢弃重复投递的Webhook事件", zap.Int("received", received), zap.Int("duplicates", duplicates))
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"received": received, "duplicates": duplicates})
+}
+
+// reject 拒绝未通过认证的请求并计数，响应中不说明具体原因
+func (s *webhookServer) reject(w http.ResponseWriter, r *http.Request, reason string) {
+	metrics.IncWebhookRejected(reason)
+	s.log.Warn("拒绝未通过认证的Webhook回调",
+		zap.String("reason", reason),
+		zap.String("client_ip", s.auth.clientIP(r).String()))
+	writeError(w, http.StatusUnauthorized, "unauthorized")
+}
+
+// dedupe 丢弃去重窗口内已接收过的事件
+// Redis不可用时不去重，重复处理的代价小于丢失事件
+func (s *webhookServer) dedupe(ctx context.Context, events []rpc.WebhookEvent) []rpc.WebhookEvent {
+	if s.config.DedupeWindow <= 0 {
+		return events
+	}
+	client := storage.GetRedisClient(storage.WorkloadCache)
+	unique := events[:0]
+	for _, event := range events {
+		if event.Signature == "" {
+			unique = append(unique, event)
+			continue
+		}
+		first, err := client.MarkWebhookEventSeen(ctx, event.Signature, event.Timestamp, s.config.DedupeWindow)
+		if err != nil {
+			s.log.Warn("Webhook事件去重失败，按首次接收处理", zap.String("signature", event.Signature), zap.Error(err))
+			first = true
+		}
+		if first {
+			unique = append(unique, event)
+		}
+	}
+	return unique
+}
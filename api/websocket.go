@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/life2you/datas-go/rpc"
+)
+
+// handleGetWebSocket 查询WebSocket连接池中各连接的状态和订阅数
+func handleGetWebSocket(w http.ResponseWriter, r *http.Request) {
+	if rpc.GlobalWebSocketPool == nil {
+		writeError(w, http.StatusServiceUnavailable, "WebSocket未启用")
+		return
+	}
+	writeJSON(w, http.StatusOK, rpc.GlobalWebSocketPool.Status())
+}
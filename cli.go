@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/idgen"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// 全局命令行参数
+var (
+	configPath string // 配置文件路径
+	profile    string // 运行环境
+)
+
+// newRootCommand 创建根命令，不带子命令时与 serve 相同，启动数据采集服务
+func newRootCommand() *cobra.Command {
+	var checkConfig bool
+	root := &cobra.Command{
+		Use:          "datas-go",
+		Short:        "Solana 区块与交易数据采集服务",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if checkConfig {
+				runCheckConfig()
+				return
+			}
+			runServe()
+		},
+	}
+	root.PersistentFlags().StringVar(&configPath, "config", "", "配置文件路径")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "运行环境，如 development、production，加载对应的 config.<profile>.yaml 覆盖基础配置")
+	root.Flags().BoolVar(&checkConfig, "check-config", false, "仅校验配置文件并输出所有问题，不启动服务")
+
+	root.AddCommand(
+		newServeCommand(),
+		newCheckConfigCommand(),
+		newDiagnoseCommand(),
+		newBackfillCommand(),
+		newParseTxCommand(),
+		newParseServerCommand(),
+		newQueueCommand(),
+		newWebhookCommand(),
+		newWatchlistCommand(),
+		newStorageCommand(),
+		newExportCommand(),
+		newControlCommand(),
+		newTopCommand(),
+	)
+	return root
+}
+
+// newServeCommand 启动数据采集服务
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "启动数据采集服务",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runServe()
+		},
+	}
+}
+
+// newCheckConfigCommand 校验配置文件
+func newCheckConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-config",
+		Short: "校验配置文件并输出所有问题",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runCheckConfig()
+		},
+	}
+}
+
+// runCheckConfig 校验配置文件并输出结果，校验失败时以非零状态码退出
+func runCheckConfig() {
+	configs.SetProfile(profile)
+	if err := configs.CheckConfig(configPath); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("配置校验通过")
+}
+
+// loadConfig 按命令行参数加载配置并初始化日志
+func loadConfig() {
+	configs.SetProfile(profile)
+	configs.LoadConfig(configPath)
+	logger.Init(&configs.GlobalConfig.Log)
+	applyTestMode()
+}
+
+// applyTestMode 启用测试模式时，将全局时钟替换为固定起点且自动推进的时钟，ID改为顺序生成
+func applyTestMode() {
+	testMode := configs.GlobalConfig.App.TestMode
+	if !testMode.Enabled {
+		return
+	}
+	start, err := time.Parse(time.RFC3339, testMode.StartTime)
+	if err != nil {
+		logger.Fatal("解析测试模式起始时间失败", zap.String("start_time", testMode.StartTime), zap.Error(err))
+	}
+	clock.SetClock(clock.NewFake(start, true))
+	idgen.SetGenerator(idgen.NewSequence(testMode.IDPrefix))
+	logger.Warn("已启用测试模式，时钟和ID不再使用真实时间和随机数",
+		zap.Time("start_time", start), zap.String("id_prefix", testMode.IDPrefix))
+}
+
+// applyProxyConfig 启用全局代理时，将代理地址应用到未单独设置 proxy_url 的客户端，
+// 并按配置通过各客户端的代理连接其目标地址，代理不可用时改为直接连接或终止启动
+func applyProxyConfig() {
+	cfg := configs.GlobalConfig
+	websocketTarget := ""
+	if cfg.WebSocket.Enabled {
+		websocketTarget = rpc.WebSocketURL(&cfg.WebSocket)
+	}
+	clickHouseTarget := ""
+	if cfg.ClickHouse.Enabled {
+		clickHouseTarget = cfg.ClickHouse.Endpoint
+	}
+	clients := []struct {
+		name     string
+		proxyURL *string
+		target   string
+	}{
+		{"websocket", &cfg.WebSocket.ProxyURL, websocketTarget},
+		{"helius_api", &cfg.HeliusAPI.ProxyURL, cfg.HeliusAPI.Endpoint},
+		{"helius_enhanced_api", &cfg.HeliusEnhancedAPI.ProxyURL, cfg.HeliusEnhancedAPI.Endpoint},
+		{"helius_webhook", &cfg.HeliusWebhook.ProxyURL, cfg.HeliusWebhook.Endpoint},
+		{"pump_portal", &cfg.PumpPortal.ProxyURL, rpc.PumpPortalWSURL},
+		{"jupiter_price", &cfg.JupiterPrice.ProxyURL, cfg.JupiterPrice.Endpoint},
+		{"pump_portal_trade", &cfg.PumpPortalTrade.ProxyURL, cfg.PumpPortalTrade.Endpoint},
+		{"clickhouse", &cfg.ClickHouse.ProxyURL, clickHouseTarget},
+	}
+
+	// 同一代理和目标只检查一次
+	probed := make(map[string]error)
+	for _, client := range clients {
+		if *client.proxyURL == "" && cfg.Proxy.Enabled {
+			*client.proxyURL = cfg.Proxy.URL
+		}
+		if *client.proxyURL == "" {
+			continue
+		}
+		proxy := redactURL(*client.proxyURL)
+		logger.Info("客户端使用代理", zap.String("client", client.name), zap.String("proxy", proxy))
+		if !cfg.Proxy.Probe || client.target == "" {
+			continue
+		}
+
+		key := *client.proxyURL + " " + client.target
+		err, ok := probed[key]
+		if !ok {
+			err = probeProxy(*client.proxyURL, client.target, cfg.Proxy.ProbeTimeout)
+			probed[key] = err
+		}
+		if err == nil {
+			continue
+		}
+		if !cfg.Proxy.FallbackDirect {
+			logger.Fatal("代理不可用", zap.String("client", client.name), zap.String("proxy", proxy), zap.Error(err))
+		}
+		logger.Warn("代理不可用，改为直接连接", zap.String("client", client.name), zap.String("proxy", proxy), zap.Error(err))
+		*client.proxyURL = ""
+	}
+}
+
+// probeProxy 通过代理连接目标地址，timeout 为0时不限制
+func probeProxy(proxyURL, target string, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return rpc.ProbeProxy(ctx, proxyURL, target)
+}
+
+// redactURL 隐藏URL中的密码，用于日志
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,61 @@
+// Package clock 提供可替换的时间来源，队列、重试、冷却和存储的时间戳都通过它取当前时间，
+// 测试时替换为 Fake 可以不依赖真实等待地验证TTL、冷却和退避逻辑
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock 时间来源
+type Clock interface {
+	Now() time.Time                         // 当前时间
+	Sleep(d time.Duration)                  // 等待指定时长
+	After(d time.Duration) <-chan time.Time // 指定时长后发送当前时间
+}
+
+// Real 使用系统时间的时钟
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// current 全局时钟，默认使用系统时间；后台协程随时会读取，替换和读取都通过原子操作
+var current atomic.Pointer[Clock]
+
+func init() {
+	var real Clock = Real{}
+	current.Store(&real)
+}
+
+// Current 返回当前的全局时钟
+func Current() Clock {
+	return *current.Load()
+}
+
+// SetClock 替换全局时钟，返回恢复原时钟的函数
+func SetClock(c Clock) (restore func()) {
+	previous := current.Swap(&c)
+	return func() { current.Store(previous) }
+}
+
+// Now 返回全局时钟的当前时间
+func Now() time.Time {
+	return Current().Now()
+}
+
+// Since 返回全局时钟自t以来经过的时长
+func Since(t time.Time) time.Duration {
+	return Current().Now().Sub(t)
+}
+
+// Sleep 按全局时钟等待指定时长
+func Sleep(d time.Duration) {
+	Current().Sleep(d)
+}
+
+// After 按全局时钟在指定时长后发送当前时间
+func After(d time.Duration) <-chan time.Time {
+	return Current().After(d)
+}
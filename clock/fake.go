@@ -0,0 +1,106 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// 自动推进的时钟在 Sleep 时真实等待的时长，避免空闲时轮询的循环占满CPU
+const autoAdvanceYield = time.Millisecond
+
+// Fake 手动推进的时钟
+// 自动推进时 Sleep 和 After 立即将时钟推进指定时长，适合整个服务以测试模式运行；
+// 否则它们阻塞到其他协程调用 Advance 使时钟越过到期时间，适合单元测试精确控制时间
+type Fake struct {
+	mu          sync.Mutex
+	now         time.Time
+	autoAdvance bool
+	waiters     []fakeWaiter
+}
+
+// fakeWaiter 等待时钟到达指定时间的调用
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake 创建从start开始的手动时钟
+// 参数:
+//   - start: 初始时间
+//   - autoAdvance: Sleep 和 After 是否自动推进时钟
+//
+// 返回:
+//   - *Fake: 时钟
+func NewFake(start time.Time, autoAdvance bool) *Fake {
+	return &Fake{now: start, autoAdvance: autoAdvance}
+}
+
+// Now 返回时钟的当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep 等待时钟推进指定时长
+func (f *Fake) Sleep(d time.Duration) {
+	if f.autoAdvance && d > 0 {
+		time.Sleep(min(d, autoAdvanceYield))
+	}
+	<-f.After(d)
+}
+
+// After 时钟推进指定时长后发送当前时间
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	if f.autoAdvance {
+		ch <- f.Advance(d)
+		return ch
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deadline := f.now.Add(d)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: deadline, ch: ch})
+	return ch
+}
+
+// Advance 将时钟推进指定时长，唤醒到期的 Sleep 和 After，返回推进后的时间
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.setLocked(f.now.Add(d))
+}
+
+// Set 将时钟设置为指定时间，早于当前时间时不会唤醒任何等待
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(t)
+}
+
+// Waiters 返回正在等待的 Sleep 和 After 数量，测试中用于确认协程已进入等待
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// setLocked 设置当前时间并唤醒到期的等待，调用方须持有锁
+func (f *Fake) setLocked(t time.Time) time.Time {
+	f.now = t
+	pending := f.waiters[:0]
+	for _, waiter := range f.waiters {
+		if waiter.deadline.After(t) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.ch <- t
+	}
+	f.waiters = pending
+	return t
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,14 +13,60 @@ import (
 
 // Config 包含应用程序的所有配置
 type Config struct {
-	App               AppConfig               `mapstructure:"app"`
-	Log               LogConfig               `mapstructure:"log"`
-	Proxy             ProxyConfig             `mapstructure:"proxy"`
-	Redis             RedisConfig             `mapstructure:"redis"`
-	WebSocket         WebSocketConfig         `mapstructure:"websocket"`
-	HeliusAPI         HeliusAPIConfig         `mapstructure:"helius_api"`
-	HeliusEnhancedAPI HeliusEnhancedAPIConfig `mapstructure:"helius_enhanced_api"`
-	PumpPortal        PumpPortalOptions       `mapstructure:"pump_portal"`
+	App                  AppConfig                  `mapstructure:"app"`
+	Log                  LogConfig                  `mapstructure:"log"`
+	Proxy                ProxyConfig                `mapstructure:"proxy"`
+	Redis                RedisConfig                `mapstructure:"redis"`
+	Parser               ParserConfig               `mapstructure:"parser"`
+	WebSocket            WebSocketConfig            `mapstructure:"websocket"`
+	HeliusAPI            HeliusAPIConfig            `mapstructure:"helius_api"`
+	BlockPrefetch        BlockPrefetchConfig        `mapstructure:"block_prefetch"`
+	HeliusEnhancedAPI    HeliusEnhancedAPIConfig    `mapstructure:"helius_enhanced_api"`
+	PumpPortal           PumpPortalOptions          `mapstructure:"pump_portal"`
+	JupiterPrice         JupiterPriceConfig         `mapstructure:"jupiter_price"`
+	PumpPortalTrade      PumpPortalTradeConfig      `mapstructure:"pump_portal_trade"`
+	WalletContext        WalletContextConfig        `mapstructure:"wallet_context"`
+	RawArchive           RawArchiveConfig           `mapstructure:"raw_archive"`
+	ClickHouse           ClickHouseConfig           `mapstructure:"clickhouse"`
+	Admin                AdminConfig                `mapstructure:"admin"`
+	Queue                QueueConfig                `mapstructure:"queue"`
+	Distributed          DistributedConfig          `mapstructure:"distributed"`
+	Jobs                 JobsConfig                 `mapstructure:"jobs"`
+	Retention            RetentionConfig            `mapstructure:"retention"`
+	Supervisor           SupervisorConfig           `mapstructure:"supervisor"`
+	Pipeline             PipelineConfig             `mapstructure:"pipeline"`
+	HeliusWebhook        HeliusWebhookConfig        `mapstructure:"helius_webhook"`
+	Rules                RulesConfig                `mapstructure:"rules"`
+	Watchlist            WatchlistConfig            `mapstructure:"watchlist"`
+	OrderFlow            OrderFlowConfig            `mapstructure:"order_flow"`
+	StallDetection       StallDetectionConfig       `mapstructure:"stall_detection"`
+	SubscriptionWatchdog SubscriptionWatchdogConfig `mapstructure:"subscription_watchdog"`
+	Finality             FinalityConfig             `mapstructure:"finality"`
+	PoolWatcher          PoolWatcherConfig          `mapstructure:"pool_watcher"`
+	Congestion           CongestionConfig           `mapstructure:"congestion"`
+	EnrichmentCache      EnrichmentCacheConfig      `mapstructure:"enrichment_cache"`
+	NegativeCache        NegativeCacheConfig        `mapstructure:"negative_cache"`
+	TransactionIndex     TransactionIndexConfig     `mapstructure:"transaction_index"`
+	BlockIndex           BlockIndexConfig           `mapstructure:"block_index"`
+	BlockSummary         BlockSummaryConfig         `mapstructure:"block_summary"`
+	BlockFilter          BlockFilterConfig          `mapstructure:"block_filter"`
+	RawParse             RawParseConfig             `mapstructure:"raw_parse"`
+	BlockState           BlockStateConfig           `mapstructure:"block_state"`
+	SkippedSlots         SkippedSlotsConfig         `mapstructure:"skipped_slots"`
+	Tracing              TracingConfig              `mapstructure:"tracing"`
+	TokenAccounts        TokenAccountsConfig        `mapstructure:"token_accounts"`
+	SourceVolume         SourceVolumeConfig         `mapstructure:"source_volume"`
+	TokenStats           TokenStatsConfig           `mapstructure:"token_stats"`
+	BondingCurve         BondingCurveConfig         `mapstructure:"bonding_curve"`
+	TokenRisk            TokenRiskConfig            `mapstructure:"token_risk"`
+	Positions            PositionsConfig            `mapstructure:"positions"`
+	PriorityFee          PriorityFeeConfig          `mapstructure:"priority_fee"`
+	ProgramFees          ProgramFeesConfig          `mapstructure:"program_fees"`
+	Capacity             CapacityConfig             `mapstructure:"capacity"`
+	Verification         VerificationConfig         `mapstructure:"verification"`
+	PayloadSamples       PayloadSamplesConfig       `mapstructure:"payload_samples"`
+	Health               HealthConfig               `mapstructure:"health"`
+	ParseServer          ParseServerConfig          `mapstructure:"parse_server"`
 }
 
 // AppConfig 应用基本配置
@@ -27,6 +74,17 @@ type AppConfig struct {
 	Name        string `mapstructure:"name"`
 	Environment string `mapstructure:"environment"`
 	Version     string `mapstructure:"version"`
+	HotReload   bool   `mapstructure:"hot_reload"` // 是否监听配置文件变化并热更新
+
+	TestMode TestModeConfig `mapstructure:"test_mode"` // 测试模式
+}
+
+// TestModeConfig 测试模式配置，集成测试中使用固定起点的时钟和顺序ID，使存储的时间戳和ID可重复
+// 启用后等待和重试不再真实等待，而是直接推进时钟
+type TestModeConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`    // 是否启用测试模式
+	StartTime string `mapstructure:"start_time"` // 时钟的起始时间，RFC3339格式
+	IDPrefix  string `mapstructure:"id_prefix"`  // 生成ID的前缀，ID为前缀加从1开始的序号
 }
 
 // LogConfig 日志配置
@@ -39,6 +97,10 @@ type LogConfig struct {
 	MaxAge     int    `mapstructure:"max_age"`     // 日志文件保留天数
 	Compress   bool   `mapstructure:"compress"`    // 是否压缩
 	Stdout     bool   `mapstructure:"stdout"`      // 是否输出到控制台
+
+	RecentErrors int `mapstructure:"recent_errors"` // 诊断快照中保留的最近错误日志条数，0表示不保留
+
+	Levels map[string]string `mapstructure:"levels"` // 按模块覆盖日志级别，如 rpc: debug, handler: info
 }
 
 // RedisConfig Redis配置
@@ -48,35 +110,180 @@ type RedisConfig struct {
 	DB       int           `mapstructure:"db"`
 	PoolSize int           `mapstructure:"pool_size"`
 	Timeout  time.Duration `mapstructure:"timeout"`
+
+	KeyPrefix string                         `mapstructure:"key_prefix"` // 所有键名和频道的前缀，多个实例共用一个Redis时设置不同的前缀
+	Workloads map[string]RedisWorkloadConfig `mapstructure:"workloads"`  // 按负载拆分的Redis实例/数据库: queue, cache, analytics
+	Degraded  RedisDegradedConfig            `mapstructure:"degraded"`   // Redis不可用时的处理策略
+}
+
+// RedisDegradedConfig Redis不可用时的处理策略配置
+type RedisDegradedConfig struct {
+	Policy        string        `mapstructure:"policy"`         // 处理策略: buffer(缓存写入)、pause(缓存写入并暂停取出区块和交易)、crash(退出进程)
+	BufferSize    int           `mapstructure:"buffer_size"`    // 每个Redis客户端最多缓存的写入数，超过时丢弃最早的写入
+	ProbeInterval time.Duration `mapstructure:"probe_interval"` // 不可用期间探测恢复的间隔
+}
+
+// RedisWorkloadConfig 单个负载的Redis配置，未设置的字段沿用redis下的默认配置
+type RedisWorkloadConfig struct {
+	Addr     string `mapstructure:"addr"`      // Redis服务器地址
+	Password string `mapstructure:"password"`  // Redis密码
+	DB       *int   `mapstructure:"db"`        // 数据库编号
+	PoolSize int    `mapstructure:"pool_size"` // 连接池大小
+}
+
+// ParserConfig 解析器配置
+type ParserConfig struct {
+	StateFilePath string `mapstructure:"state_file_path"` // 区块游标状态文件，为空时只保存到Redis
+	Resume        bool   `mapstructure:"resume"`          // 启动后自动回补游标与第一个槽位之间的缺口
+	MaxGap        uint64 `mapstructure:"max_gap"`         // 自动回补的最大缺口槽位数，超过时只告警
+
+	MaxConcurrentBatches int     `mapstructure:"max_concurrent_batches"` // 全局同时调用Enhanced API解析的批次数上限，0表示不限制
+	KeyRPS               float64 `mapstructure:"key_rps"`                // 每个API密钥每秒最多发起的解析请求数，0表示不限制
 }
 
 // WebSocketConfig WebSocket客户端配置
 type WebSocketConfig struct {
-	Enabled           bool          `mapstructure:"enabled"`            // 是否启用WebSocket
-	NetworkType       string        `mapstructure:"network_type"`       // 网络类型：mainnet, devnet
-	APIKey            string        `mapstructure:"api_key"`            // Helius API密钥
-	ReconnectInterval time.Duration `mapstructure:"reconnect_interval"` // 重连间隔
-	ProxyURL          string        `mapstructure:"proxy_url"`          // 代理服务器URL
-	OnConnect         func()        // 连接建立时的回调函数
+	Enabled            bool          `mapstructure:"enabled"`              // 是否启用WebSocket
+	NetworkType        string        `mapstructure:"network_type"`         // 网络类型：mainnet, devnet
+	Endpoint           string        `mapstructure:"endpoint"`             // WebSocket地址，为空时按 network_type 使用Helius地址，可指向兼容的节点或测试用的模拟服务
+	APIKey             string        `mapstructure:"api_key"`              // Helius API密钥
+	ReconnectInterval  time.Duration `mapstructure:"reconnect_interval"`   // 重连间隔
+	RequestTimeout     time.Duration `mapstructure:"request_timeout"`      // 等待订阅等请求响应的超时
+	PingInterval       time.Duration `mapstructure:"ping_interval"`        // 发送ping的间隔
+	LivenessTimeout    time.Duration `mapstructure:"liveness_timeout"`     // 超过该时长未收到任何消息或pong时断开重连，0表示不检测
+	ProxyURL           string        `mapstructure:"proxy_url"`            // 代理服务器URL
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"` // 是否跳过TLS证书校验，仅用于调试或会替换证书的代理
+	EnableCompression  bool          `mapstructure:"enable_compression"`   // 是否协商permessage-deflate压缩
+	ReadLimit          int64         `mapstructure:"read_limit"`           // 单条消息的最大字节数
+	ReadBufferSize     int           `mapstructure:"read_buffer_size"`     // 读缓冲区大小
+	WriteBufferSize    int           `mapstructure:"write_buffer_size"`    // 写缓冲区大小
+	BlockChunkSize     int           `mapstructure:"block_chunk_size"`     // 分块处理区块通知时每批交易数
+	PoolSize           int           `mapstructure:"pool_size"`            // WebSocket连接数，订阅分散到各连接，每个连接独立重连
+
+	IngestionMode           string `mapstructure:"ingestion_mode"`            // 摄取模式: slot、block-all、block-mentions:<地址>[,<地址>...]
+	BlockCommitment         string `mapstructure:"block_commitment"`          // blockSubscribe的确认级别: confirmed、finalized
+	BlockTransactionDetails string `mapstructure:"block_transaction_details"` // blockSubscribe的交易详情: full(直接摄取完整区块)、none(只通知槽位，再通过getBlock获取)
+	SlotConfirmationDepth   int    `mapstructure:"slot_confirmation_depth"`   // slot 模式下槽位落后最新通知的槽位至少该数量后才推入区块队列，0表示收到通知立即入队
+	OnConnect               func() // 连接建立时的回调函数
+}
+
+// BlockPrefetchConfig 区块预取配置
+// 预先查看区块队列中即将取出的区块并发调用getBlock，取出区块时直接使用预取的结果
+type BlockPrefetchConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 是否启用
+	Lookahead    int           `mapstructure:"lookahead"`     // 预取区块队列中即将取出的区块数
+	Concurrency  int           `mapstructure:"concurrency"`   // 同时进行的getBlock请求数
+	MinInterval  time.Duration `mapstructure:"min_interval"`  // 两次getBlock请求之间的最小间隔，用于遵守RPC限流
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 检查区块队列的间隔
+	TTL          time.Duration `mapstructure:"ttl"`           // 预取的区块没有被取用时的保留时长
 }
 
 // HeliusAPIConfig Helius API配置
 type HeliusAPIConfig struct {
-	APIKey   string `mapstructure:"api_key"`   // Helius API密钥
-	Endpoint string `mapstructure:"endpoint"`  // Helius API端点
-	ProxyURL string `mapstructure:"proxy_url"` // 代理服务器URL
+	APIKey    string           `mapstructure:"api_key"`    // Helius API密钥
+	Endpoint  string           `mapstructure:"endpoint"`   // Helius API端点
+	ProxyURL  string           `mapstructure:"proxy_url"`  // 代理服务器URL
+	BatchSize int              `mapstructure:"batch_size"` // 每次以JSON-RPC批量请求获取的区块数，0或1表示逐个获取
+	HTTP      HTTPClientConfig `mapstructure:"http"`       // HTTP客户端设置
 }
 
 type HeliusEnhancedAPIConfig struct {
-	APIKeys  []string `mapstructure:"api_keys"`  // 多个Helius API密钥
-	Endpoint string   `mapstructure:"endpoint"`  // Helius API端点
-	ProxyURL string   `mapstructure:"proxy_url"` // 代理服务器URL
+	APIKeys  []string         `mapstructure:"api_keys"`  // 多个Helius API密钥
+	Endpoint string           `mapstructure:"endpoint"`  // Helius API端点
+	ProxyURL string           `mapstructure:"proxy_url"` // 代理服务器URL
+	HTTP     HTTPClientConfig `mapstructure:"http"`      // HTTP客户端设置，所有API密钥共用同一个连接池
+	Auth     APIAuthConfig    `mapstructure:"auth"`      // API密钥的传递方式
+
+	Commitment string `mapstructure:"commitment"` // 解析交易的确认级别: confirmed 或 finalized，为空时使用服务端默认的 finalized
+
+	DailyBudgets   []int64       `mapstructure:"daily_budgets"`   // 每个密钥每天(UTC)可消耗的额度，即解析的签名数，与 api_keys 顺序一致，0或未设置表示不限制
+	UsageRetention time.Duration `mapstructure:"usage_retention"` // Redis中每日用量的保留时长
+
+	Quarantine APIKeyQuarantineConfig `mapstructure:"quarantine"` // 失效密钥的隔离
+}
+
+// APIKeyQuarantineConfig 返回401/403或持续429的Enhanced API密钥在冷却期内不再分配解析批次
+type APIKeyQuarantineConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`              // 是否启用密钥隔离
+	RejectedCooldown   time.Duration `mapstructure:"rejected_cooldown"`    // 返回401/403的密钥的隔离时长
+	RateLimitThreshold int           `mapstructure:"rate_limit_threshold"` // 连续返回429多少次后隔离
+	RateLimitCooldown  time.Duration `mapstructure:"rate_limit_cooldown"`  // 持续限流的密钥的隔离时长
+	MinHealthyKeys     int           `mapstructure:"min_healthy_keys"`     // 未被隔离的密钥少于该数量时告警
 }
 
-// ProxyConfig 代理配置
+// APIAuthConfig API密钥的传递方式，自建或经过代理的兼容端点可能只支持其中一部分
+type APIAuthConfig struct {
+	Modes        []string `mapstructure:"modes"`         // 认证方式，可同时使用多种: query、basic、bearer、header，none表示不携带密钥，为空时使用query和basic
+	QueryParam   string   `mapstructure:"query_param"`   // query 方式的参数名
+	Header       string   `mapstructure:"header"`        // header 方式的请求头名称
+	HeaderPrefix string   `mapstructure:"header_prefix"` // header 方式在密钥前添加的前缀，如 "Token "
+}
+
+// HTTPClientConfig HTTP客户端的超时、连接池和TLS设置
+type HTTPClientConfig struct {
+	Timeout               time.Duration `mapstructure:"timeout"`                 // 单个请求的总超时，包括读取响应体
+	DialTimeout           time.Duration `mapstructure:"dial_timeout"`            // 建立TCP连接的超时
+	KeepAlive             time.Duration `mapstructure:"keep_alive"`              // TCP keep-alive 探测间隔
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout"`   // TLS握手超时
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout"` // 发送请求后等待响应头的超时，0表示不限制
+	IdleConnTimeout       time.Duration `mapstructure:"idle_conn_timeout"`       // 空闲连接保留时长
+	MaxIdleConns          int           `mapstructure:"max_idle_conns"`          // 所有主机的最大空闲连接数
+	MaxIdleConnsPerHost   int           `mapstructure:"max_idle_conns_per_host"` // 每个主机的最大空闲连接数
+	MaxConnsPerHost       int           `mapstructure:"max_conns_per_host"`      // 每个主机的最大连接数，0表示不限制
+	HTTP2                 bool          `mapstructure:"http2"`                   // 是否尝试使用HTTP/2
+	InsecureSkipVerify    bool          `mapstructure:"insecure_skip_verify"`    // 是否跳过TLS证书校验，仅用于调试或会替换证书的代理
+}
+
+// HeliusWebhookConfig Helius Webhook管理API配置
+type HeliusWebhookConfig struct {
+	APIKey      string           `mapstructure:"api_key"`      // Helius API密钥
+	Endpoint    string           `mapstructure:"endpoint"`     // Helius API端点
+	CallbackURL string           `mapstructure:"callback_url"` // Webhook回调URL
+	ProxyURL    string           `mapstructure:"proxy_url"`    // 代理服务器URL
+	HTTP        HTTPClientConfig `mapstructure:"http"`         // HTTP客户端设置
+
+	MaxAttempts     int           `mapstructure:"max_attempts"`      // 管理API请求因网络错误、限流或5xx失败时的最大尝试次数，1表示不重试
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`     // 第一次重试前的等待时间，之后每次翻倍，限流时按服务端要求的时间等待
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"` // 重试等待时间的上限
+
+	Sync     bool                `mapstructure:"sync"`     // 启动时按 webhooks 声明同步Helius上的Webhook
+	Prune    bool                `mapstructure:"prune"`    // 同步时删除未在 webhooks 中声明的Webhook
+	Webhooks []WebhookDefinition `mapstructure:"webhooks"` // 声明的Webhook，按回调URL识别
+
+	Receiver WebhookReceiverConfig `mapstructure:"receiver"` // 接收Webhook回调的HTTP服务
+}
+
+// WebhookReceiverConfig Webhook回调接收服务配置
+type WebhookReceiverConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`        // 是否启动接收服务
+	Addr         string        `mapstructure:"addr"`           // 监听地址
+	Path         string        `mapstructure:"path"`           // 回调路径，回调URL应指向该路径
+	MaxBodyBytes int64         `mapstructure:"max_body_bytes"` // 请求体大小上限(字节)
+	DedupeWindow time.Duration `mapstructure:"dedupe_window"`  // 去重窗口，窗口内签名和时间戳相同的事件只处理一次，0表示不去重
+
+	AuthHeader     string   `mapstructure:"auth_header"`      // 回调请求的Authorization头需与之一致，为空时接受 webhooks 中声明的任一 auth_header，都未设置时不校验
+	HMACSecret     string   `mapstructure:"hmac_secret"`      // 请求体HMAC-SHA256签名的共享密钥，为空时不校验
+	HMACHeader     string   `mapstructure:"hmac_header"`      // 携带十六进制HMAC签名的请求头，可带 sha256= 前缀
+	AllowedIPs     []string `mapstructure:"allowed_ips"`      // 允许的来源IP或CIDR，为空时不限制
+	ClientIPHeader string   `mapstructure:"client_ip_header"` // 从该请求头的第一个地址读取来源IP(如 X-Forwarded-For)，为空时使用连接地址，仅在可信的反向代理后设置
+}
+
+// WebhookDefinition 声明式Webhook配置
+type WebhookDefinition struct {
+	URL              string   `mapstructure:"url"`               // 回调URL，为空时使用 callback_url
+	Type             string   `mapstructure:"type"`              // Webhook类型: enhanced, raw, discord, enhancedDevnet, rawDevnet
+	TransactionTypes []string `mapstructure:"transaction_types"` // 监控的交易类型，为空时为ANY
+	Addresses        []string `mapstructure:"addresses"`         // 监控的地址
+	AuthHeader       string   `mapstructure:"auth_header"`       // 回调时携带的Authorization头
+}
+
+// ProxyConfig 代理配置，URL 应用到未单独设置 proxy_url 的客户端
 type ProxyConfig struct {
-	Enabled bool   `mapstructure:"enabled"` // 是否启用代理
-	URL     string `mapstructure:"url"`     // 代理服务器URL
+	Enabled        bool          `mapstructure:"enabled"`         // 是否启用代理
+	URL            string        `mapstructure:"url"`             // 代理服务器URL
+	Probe          bool          `mapstructure:"probe"`           // 启动时通过各客户端的代理连接其目标地址，检查代理是否可用
+	ProbeTimeout   time.Duration `mapstructure:"probe_timeout"`   // 单次检查的超时
+	FallbackDirect bool          `mapstructure:"fallback_direct"` // 检查失败时该客户端改为直接连接，为false时启动失败
 }
 
 type PumpPortalOptions struct {
@@ -85,11 +292,518 @@ type PumpPortalOptions struct {
 	MaxRetryAttempt int           `mapstructure:"max_retry_attempt"` // 最大重试次数
 }
 
-// 全局配置实例
+// PumpPortalTradeConfig PumpPortal交易API配置，用于通过规则自动买卖pump.fun代币
+type PumpPortalTradeConfig struct {
+	Enabled          bool             `mapstructure:"enabled"`             // 是否启用交易，未启用时不创建交易客户端
+	DryRun           bool             `mapstructure:"dry_run"`             // 只记录日志不实际下单
+	RuleIDs          []string         `mapstructure:"rule_ids"`            // 允许下单的交易规则ID，规则引擎只接受这些ID的 trade 规则，为空时不接受交易规则
+	Mode             string           `mapstructure:"mode"`                // 下单方式: lightning(PumpPortal代为签名发送) 或 local(本地签名后通过 helius_api 发送)
+	Endpoint         string           `mapstructure:"endpoint"`            // 交易API地址
+	APIKey           string           `mapstructure:"api_key"`             // Lightning API密钥，mode=lightning时必填
+	PrivateKey       string           `mapstructure:"private_key"`         // 钱包私钥(base58或solana-keygen的JSON数组)，mode=local时必填，建议通过环境变量设置
+	ProxyURL         string           `mapstructure:"proxy_url"`           // 代理服务器URL
+	HTTP             HTTPClientConfig `mapstructure:"http"`                // HTTP客户端设置
+	Pool             string           `mapstructure:"pool"`                // 默认交易池: pump, raydium, pump-amm, launchlab, raydium-cpmm, bonk, auto
+	Slippage         float64          `mapstructure:"slippage"`            // 默认滑点(百分比)
+	PriorityFee      float64          `mapstructure:"priority_fee"`        // 默认优先费(SOL)
+	SkipPreflight    bool             `mapstructure:"skip_preflight"`      // mode=local时发送交易是否跳过预检
+	MaxSOLPerTrade   float64          `mapstructure:"max_sol_per_trade"`   // 以SOL计价的单笔交易上限，买入必须以SOL计价
+	MaxTradesPerHour int              `mapstructure:"max_trades_per_hour"` // 每小时最多下单次数
+	MintCooldown     time.Duration    `mapstructure:"mint_cooldown"`       // 同一代币同一方向两次下单的最小间隔
+}
+
+// JupiterPriceConfig Jupiter价格API配置，用于计算swap交易的美元价值
+type JupiterPriceConfig struct {
+	Enabled     bool             `mapstructure:"enabled"`      // 是否为swap交易计算美元价值
+	Endpoint    string           `mapstructure:"endpoint"`     // 价格API地址
+	APIKey      string           `mapstructure:"api_key"`      // API密钥，通过 x-api-key 请求头发送，为空时不发送
+	ProxyURL    string           `mapstructure:"proxy_url"`    // 代理服务器URL
+	HTTP        HTTPClientConfig `mapstructure:"http"`         // HTTP客户端设置
+	CacheTTL    time.Duration    `mapstructure:"cache_ttl"`    // 价格缓存时长，缓存期内同一代币不重复请求
+	MinInterval time.Duration    `mapstructure:"min_interval"` // 两次请求之间的最小间隔，用于遵守API的限流
+	BatchSize   int              `mapstructure:"batch_size"`   // 后台刷新时每次请求最多查询的代币数量
+}
+
+// WalletContextConfig 钱包历史上下文配置，为swap交易附加手续费支付者的历史交易信息
+type WalletContextConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 是否为swap交易附加钱包上下文
+	HistoryLimit int           `mapstructure:"history_limit"` // 每个钱包最多查询的历史交易数，超过100时分页查询
+	CacheTTL     time.Duration `mapstructure:"cache_ttl"`     // 历史查询结果的缓存时长，缓存期内同一钱包不重复查询，之后的swap交易依次计入
+	Timeout      time.Duration `mapstructure:"timeout"`       // 读取缓存和后台查询一个钱包历史的最长时间
+}
+
+// RawArchiveConfig Enhanced API原始响应归档配置
+type RawArchiveConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`  // 是否保存原始响应
+	Compress bool          `mapstructure:"compress"` // 是否使用gzip压缩
+	TTL      time.Duration `mapstructure:"ttl"`      // 保存时长，0表示不过期
+
+	Dir        string        `mapstructure:"dir"`          // 归档文件输出目录，为空时只保存到Redis
+	FileSlots  uint64        `mapstructure:"file_slots"`   // 单个归档文件覆盖的最大槽位跨度
+	FileMaxAge time.Duration `mapstructure:"file_max_age"` // 单个归档文件的最长写入时间
+}
+
+// ClickHouseConfig 解析结果和区块统计写入ClickHouse的配置，通过HTTP接口批量写入
+type ClickHouseConfig struct {
+	Enabled            bool             `mapstructure:"enabled"`               // 是否启用
+	Endpoint           string           `mapstructure:"endpoint"`              // HTTP接口地址，如 http://localhost:8123
+	Database           string           `mapstructure:"database"`              // 数据库
+	Username           string           `mapstructure:"username"`              // 用户名
+	Password           string           `mapstructure:"password"`              // 密码
+	TransactionsTable  string           `mapstructure:"transactions_table"`    // 解析结果表
+	BlocksTable        string           `mapstructure:"blocks_table"`          // 区块统计表
+	CreateSchema       bool             `mapstructure:"create_schema"`         // 写入前创建数据库和表，并补齐缺少的列
+	TTL                time.Duration    `mapstructure:"ttl"`                   // 建表时设置的数据保留时长(按区块时间)，0表示不过期
+	IncludeRaw         bool             `mapstructure:"include_raw"`           // 是否在 raw 列中写入完整的解析结果JSON
+	BatchSize          int              `mapstructure:"batch_size"`            // 每个表缓冲多少行后写入
+	FlushInterval      time.Duration    `mapstructure:"flush_interval"`        // 缓冲未满时的最长写入间隔
+	MaxBuffered        int              `mapstructure:"max_buffered"`          // 写入失败时每个表最多保留的行数，超过时丢弃最早的行
+	AsyncInsert        bool             `mapstructure:"async_insert"`          // 是否使用服务端异步写入(async_insert)，由ClickHouse合并小批次
+	WaitForAsyncInsert bool             `mapstructure:"wait_for_async_insert"` // 异步写入时是否等待数据落盘后才返回
+	ProxyURL           string           `mapstructure:"proxy_url"`             // 代理服务器URL
+	HTTP               HTTPClientConfig `mapstructure:"http"`                  // HTTP客户端设置
+}
+
+// EnrichmentCacheConfig Enhanced API解析结果缓存配置
+type EnrichmentCacheConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // 是否在调用Enhanced API前查询缓存
+	TTL     time.Duration `mapstructure:"ttl"`     // 缓存时长，0表示不过期
+}
+
+// NegativeCacheConfig 不再解析缓存配置
+type NegativeCacheConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`      // 是否启用
+	MaxAttempts int           `mapstructure:"max_attempts"` // 签名累计失败多少次后不再解析
+	TTL         time.Duration `mapstructure:"ttl"`          // 失败记录保留时长，每次失败后重新计时，0表示不过期
+}
+
+// TransactionIndexConfig 交易索引配置，索引按来源/类型和代币分别以天为单位建键
+type TransactionIndexConfig struct {
+	TTL             time.Duration            `mapstructure:"ttl"`              // 每天的索引在当天结束后的保留时长，0表示不过期
+	TypeTTL         map[string]time.Duration `mapstructure:"type_ttl"`         // 按交易类型覆盖保留时长，如 SWAP: 72h
+	MaxPerKey       int64                    `mapstructure:"max_per_key"`      // 每个索引键最多保留的签名数，超过时删除槽位最小的，0表示不限制
+	IndexMints      bool                     `mapstructure:"index_mints"`      // 是否按代币建立索引
+	CleanupInterval time.Duration            `mapstructure:"cleanup_interval"` // 清理任务的间隔，0表示不运行
+}
+
+// BlockIndexConfig 区块哈希索引配置
+type BlockIndexConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // 是否启用
+	TTL     time.Duration `mapstructure:"ttl"`     // 索引保留时长，0表示不过期
+}
+
+// BlockSummaryConfig 区块交易汇总配置
+type BlockSummaryConfig struct {
+	Enabled    bool  `mapstructure:"enabled"`     // 是否启用
+	MaxEntries int64 `mapstructure:"max_entries"` // 最多保留的区块数，超过时删除槽位最小的汇总，0表示不限制
+}
+
+// BlockFilterConfig 区块阶段的交易预过滤，只将调用指定程序或涉及指定账户的交易推入解析队列，减少Enhanced API调用
+type BlockFilterConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`  // 是否启用
+	Programs []string `mapstructure:"programs"` // 程序ID或别名(如 pump_fun、raydium_amm)，顶层或内部指令调用其中任一程序的交易会被保留
+	Accounts []string `mapstructure:"accounts"` // 账户地址，账户列表包含其中任一账户的交易会被保留
+}
+
+// RawParseConfig 本地解析配置，只调用指定程序的交易(如SOL转账和SPL代币转账)直接按getBlock返回的指令解码，不调用Enhanced API
+type RawParseConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`  // 是否启用
+	Programs []string `mapstructure:"programs"` // 程序ID或别名，只调用其中程序的交易在本地解码为转账，为空时使用默认的转账相关程序
+}
+
+// AdminConfig 管理HTTP接口配置
+type AdminConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`        // 是否启用管理接口
+	Addr         string `mapstructure:"addr"`           // 监听地址，如 127.0.0.1:8090
+	AuthToken    string `mapstructure:"auth_token"`     // 修改类接口(GET以外)的Bearer令牌，为空时修改类接口全部拒绝
+	MaxBodyBytes int64  `mapstructure:"max_body_bytes"` // 请求体大小上限(字节)
+}
+
+// HealthConfig 健康检查配置，用于 /healthz 和 /readyz
+type HealthConfig struct {
+	MaxMessageAge     time.Duration `mapstructure:"max_message_age"`     // WebSocket超过该时长未收到消息视为异常，0表示不检查
+	MaxQueueStaleness time.Duration `mapstructure:"max_queue_staleness"` // 队列非空且超过该时长未出队视为停滞，0表示不检查
+}
+
+// ParseServerConfig 独立解析服务配置，通过 parse-server 命令启动
+type ParseServerConfig struct {
+	Addr            string        `mapstructure:"addr"`             // 监听地址
+	MaxSignatures   int           `mapstructure:"max_signatures"`   // 每个请求最多解析的签名数量
+	MaxTransactions int           `mapstructure:"max_transactions"` // 每个请求最多解码的原始交易数量
+	MaxBodyBytes    int64         `mapstructure:"max_body_bytes"`   // 请求体大小上限(字节)
+	Timeout         time.Duration `mapstructure:"timeout"`          // 调用Enhanced API的超时时间
+}
+
+// QueueConfig 内存队列配置
+type QueueConfig struct {
+	BlockMaxAge       time.Duration `mapstructure:"block_max_age"`       // 区块队列元素最大等待时间，超时移入死信队列，0表示不限制
+	TransactionMaxAge time.Duration `mapstructure:"transaction_max_age"` // 交易队列元素最大等待时间，超时移入死信队列，0表示不限制
+
+	TransactionScheduling string `mapstructure:"transaction_scheduling"` // 交易批次调度模式: block(逐个区块处理) 或 interleaved(多个区块的批次交错处理)
+	InterleaveBlocks      int    `mapstructure:"interleave_blocks"`      // 交错调度时同时派发批次的区块数
+	MaxConcurrentBatches  int    `mapstructure:"max_concurrent_batches"` // 交错调度时全局同时处理的批次数上限
+
+	Backend string            `mapstructure:"backend"` // 交易队列的实现: memory(进程内优先队列) 或 redis-stream(Redis Streams消费者组)
+	Stream  StreamQueueConfig `mapstructure:"stream"`  // redis-stream 交易队列配置
+
+	Lanes QueueLanesConfig `mapstructure:"lanes"` // 内存队列的实时与回补分道调度
+}
+
+// QueueLanesConfig 内存区块队列和交易队列的实时与回补分道调度配置
+// 队列按槽位从小到大出队，回补大量旧区块时新区块会一直排在后面；分道后两个通道按权重轮流出队
+type QueueLanesConfig struct {
+	Enabled        bool  `mapstructure:"enabled"`         // 是否启用
+	LiveWindow     int64 `mapstructure:"live_window"`     // 入队时槽位落后队列中出现过的最大槽位超过该值的元素进入回补通道
+	LiveWeight     int   `mapstructure:"live_weight"`     // 实时通道的权重
+	BackfillWeight int   `mapstructure:"backfill_weight"` // 回补通道的权重
+}
+
+// StreamQueueConfig 基于Redis Streams的交易队列配置
+// 消息在区块的所有批次处理完成后才确认，消费者崩溃时未确认的消息由其他消费者认领
+type StreamQueueConfig struct {
+	Key           string        `mapstructure:"key"`            // Stream的键名，为空时使用 <redis.key_prefix>:transaction:stream
+	Group         string        `mapstructure:"group"`          // 消费者组名称
+	Consumer      string        `mapstructure:"consumer"`       // 消费者名称，为空时使用主机名；重启后沿用同一名称可先处理自己未确认的消息
+	ClaimIdle     time.Duration `mapstructure:"claim_idle"`     // 消息未确认超过该时长时可被其他消费者认领，应大于处理一个区块的耗时
+	ClaimInterval time.Duration `mapstructure:"claim_interval"` // 检查可认领消息的间隔
+	MaxLen        int64         `mapstructure:"max_len"`        // Stream的近似最大长度，超过时删除最早的消息，0表示不限制
+}
+
+// DistributedConfig 多实例分布式处理配置
+// 多个实例共用一个Redis时，每个区块在处理前通过Redis租约分配给一个实例，实例失效后其持有的区块重新分配给存活的实例
+type DistributedConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`            // 是否启用
+	InstanceID        string        `mapstructure:"instance_id"`        // 实例ID，多个实例不能重复，为空时使用 主机名-进程号
+	LeaseTTL          time.Duration `mapstructure:"lease_ttl"`          // 区块租约的有效期，持有租约的实例每次心跳时续期
+	DoneTTL           time.Duration `mapstructure:"done_ttl"`           // 区块处理完成后保留完成标记的时长，期间其他实例收到同一槽位时直接跳过
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"` // 实例心跳间隔，同时检查失效的实例
+	InstanceTTL       time.Duration `mapstructure:"instance_ttl"`       // 超过该时长没有心跳的实例视为失效
+
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"` // WebSocket摄取的领导者选举，不依赖 enabled
+}
+
+// LeaderElectionConfig WebSocket摄取的领导者选举配置
+// 多个实例冗余部署时只有领导者订阅槽位/区块，领导者失效后由备用实例在租期到期后接管
+type LeaderElectionConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	TTL           time.Duration `mapstructure:"ttl"`            // 领导者租期，领导者失效后最迟在该时长之后由其他实例接管
+	RenewInterval time.Duration `mapstructure:"renew_interval"` // 领导者续期和备用实例竞选的间隔
+}
+
+// JobsConfig 定时任务配置
+// 多个实例共用一个Redis时，每个任务的每次调度只由一个实例执行
+type JobsConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // 是否启用
+	History int           `mapstructure:"history"` // 每个任务在Redis中保留的执行记录数
+	Timeout time.Duration `mapstructure:"timeout"` // 任务的默认超时时间，也是执行锁的有效期
+	Jobs    []JobConfig   `mapstructure:"jobs"`    // 调度的任务
+}
+
+// JobConfig 单个定时任务的调度配置
+type JobConfig struct {
+	Name     string        `mapstructure:"name"`     // 任务名称，必须是程序中注册的任务
+	Schedule string        `mapstructure:"schedule"` // 调度表达式，支持5段cron表达式、@daily 等预定义表达式和 @every <时长>，为空时只能手动触发
+	Timeout  time.Duration `mapstructure:"timeout"`  // 超时时间，0表示使用 jobs.timeout
+}
+
+// RetentionConfig Redis数据保留配置，由定时任务 retention 按策略处理没有过期时间的旧键
+type RetentionConfig struct {
+	DryRun    bool              `mapstructure:"dry_run"`    // 只统计会被处理的键，不修改数据
+	BatchSize int64             `mapstructure:"batch_size"` // 每次SCAN的数量
+	Samples   int               `mapstructure:"samples"`    // 报告中每个策略列出的键名样例数
+	Policies  []RetentionPolicy `mapstructure:"policies"`   // 保留策略，为空时使用内置策略
+}
+
+// RetentionPolicy 单个保留策略
+type RetentionPolicy struct {
+	Name     string        `mapstructure:"name"`     // 策略名称
+	Workload string        `mapstructure:"workload"` // 键所在的Redis负载: queue、cache、analytics，为空时使用默认客户端
+	Pattern  string        `mapstructure:"pattern"`  // 键名匹配模式，不含 redis.key_prefix，如 block:[0-9]*
+	Action   string        `mapstructure:"action"`   // 处理方式: expire 为没有过期时间的键设置 max_age 过期，delete 删除超过 max_age 未访问的键，trim 将 ZSet/List 裁剪到 max_len
+	MaxAge   time.Duration `mapstructure:"max_age"`  // expire 设置的过期时间，delete 的最长空闲时间
+	MaxLen   int64         `mapstructure:"max_len"`  // trim 保留的元素数，ZSet保留分数最高的元素，List保留头部的元素
+}
+
+// SupervisorConfig 长期运行协程的panic重启配置
+type SupervisorConfig struct {
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 第一次重启前的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 重启前的最长等待时间，协程运行超过该时长后才panic时重新从 initial_backoff 开始
+}
+
+// PipelineConfig 进程内事件订阅配置
+type PipelineConfig struct {
+	SubscriberBuffer int          `mapstructure:"subscriber_buffer"` // 每个订阅者的默认缓冲大小，缓冲满时丢弃事件
+	Stages           StagesConfig `mapstructure:"stages"`            // 采集流程的阶段编排
+}
+
+// StagesConfig 采集流程按阶段启动: ingest(WebSocket订阅) → block_fetch(获取区块) → parse(解析交易) → sink(结果存储)
+// 每个阶段在依赖的阶段就绪后启动，启动失败时重试
+type StagesConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否按阶段启动采集流程
+	Disabled      []string      `mapstructure:"disabled"`       // 不启动的阶段，依赖它的阶段视为其已就绪
+	StartRetries  int           `mapstructure:"start_retries"`  // 启动失败后的重试次数
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // 重试间隔
+	StartTimeout  time.Duration `mapstructure:"start_timeout"`  // 单次启动的超时，0表示不限制
+}
+
+// RulesConfig 告警/路由规则配置，规则本身通过管理接口维护并保存在Redis中
+type RulesConfig struct {
+	Enabled        bool `mapstructure:"enabled"`          // 是否启用规则引擎
+	AlertHistory   int  `mapstructure:"alert_history"`    // Redis中最多保留的告警数量
+	TradeQueueSize int  `mapstructure:"trade_queue_size"` // 等待下单的交易规则命中数上限，队列已满时丢弃
+}
+
+// WatchlistConfig 关注地址同步配置，关注地址变化后同步到各个接入渠道
+type WatchlistConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 未启用规则引擎时也加载关注地址列表并同步
+	WebhookID    string        `mapstructure:"webhook_id"`    // 地址与关注地址列表保持一致的Helius Webhook ID，为空时不同步
+	PumpPortal   bool          `mapstructure:"pump_portal"`   // 通过PumpPortal subscribeAccountTrade订阅关注地址的交易
+	SyncInterval time.Duration `mapstructure:"sync_interval"` // 定期全量同步的间隔，用于修复同步失败和手动修改造成的差异
+	AllowEmpty   bool          `mapstructure:"allow_empty"`   // 关注地址为空时是否清空Webhook的监控地址，默认拒绝同步，避免误删或加载失败时清空Webhook
+}
+
+// OrderFlowConfig 代币买卖盘失衡统计配置
+type OrderFlowConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`   // 是否启用
+	Mints     []string      `mapstructure:"mints"`     // 关注的代币地址，支持热更新
+	Window    time.Duration `mapstructure:"window"`    // 滚动窗口长度
+	Interval  time.Duration `mapstructure:"interval"`  // 快照间隔，即时间序列的精度
+	Retention time.Duration `mapstructure:"retention"` // 时间序列保留时长
+}
+
+// TokenAccountsConfig 代币账户创建/关闭统计配置
+type TokenAccountsConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`   // 是否启用
+	Mints     []string      `mapstructure:"mints"`     // 统计的代币地址，为空时统计所有代币，支持热更新
+	Interval  time.Duration `mapstructure:"interval"`  // 快照间隔，即时间序列的精度
+	Retention time.Duration `mapstructure:"retention"` // 时间序列保留时长
+}
+
+// SourceVolumeConfig 按交易来源统计配置
+type SourceVolumeConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 内存中的增量写入Redis的间隔
+	Retention     time.Duration `mapstructure:"retention"`      // 小时统计保留时长
+}
+
+// TokenStatsConfig 代币24小时统计配置
+type TokenStatsConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`   // 是否启用
+	Mints    []string `mapstructure:"mints"`     // 统计的代币地址，为空时统计所有代币，支持热更新
+	MaxMints int      `mapstructure:"max_mints"` // 内存中最多统计的代币数，超过时淘汰最久没有成交的代币
+}
+
+// BondingCurveConfig Pump.fun联合曲线进度统计配置
+type BondingCurveConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`        // 是否启用
+	Mints         []string `mapstructure:"mints"`          // 额外订阅PumpPortal买卖消息的代币，支持热更新；其他已订阅代币的买卖消息同样统计
+	AlertProgress float64  `mapstructure:"alert_progress"` // 进度达到该值时发布即将毕业事件，0到1之间
+	MaxMints      int      `mapstructure:"max_mints"`      // 内存中最多跟踪的代币数，超过时淘汰最久没有成交的代币
+}
+
+// PositionsConfig 持仓统计配置
+type PositionsConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	Mints         []string      `mapstructure:"mints"`          // 统计的代币地址，为空时统计所有代币，支持热更新
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 内存中的增量写入Redis的间隔
+	PositionTTL   time.Duration `mapstructure:"position_ttl"`   // 代币持仓在没有变化后的保留时长，0表示不过期
+	Retention     time.Duration `mapstructure:"retention"`      // 每日净流入保留时长
+}
+
+// PriorityFeeConfig 计算单元价格统计配置
+type PriorityFeeConfig struct {
+	Enabled      bool `mapstructure:"enabled"`       // 是否启用
+	WindowBlocks int  `mapstructure:"window_blocks"` // 保留最近多少个区块的样本
+}
+
+// ProgramFeesConfig 按程序统计手续费配置
+type ProgramFeesConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	Programs      []string      `mapstructure:"programs"`       // 统计的程序ID或别名，为空时统计所有程序
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 内存中的增量写入Redis的间隔
+	Retention     time.Duration `mapstructure:"retention"`      // 小时统计保留时长
+}
+
+// CapacityConfig 容量规划指标快照配置
+type CapacityConfig struct {
+	Enabled                bool          `mapstructure:"enabled"`                  // 是否定期保存指标快照
+	Interval               time.Duration `mapstructure:"interval"`                 // 快照间隔
+	Retention              time.Duration `mapstructure:"retention"`                // 快照保留时长
+	RPCRequestCredits      int64         `mapstructure:"rpc_request_credits"`      // 每次Helius RPC请求消耗的额度
+	EnhancedRequestCredits int64         `mapstructure:"enhanced_request_credits"` // 每次Enhanced API请求消耗的额度
+	CreditsPerAPIKey       int64         `mapstructure:"credits_per_api_key"`      // 每个API密钥每月的额度，用于推荐密钥数量，0表示不推荐
+}
+
+// VerificationConfig 解析结果抽样校验配置
+type VerificationConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`         // 是否启用
+	SampleRate     float64       `mapstructure:"sample_rate"`     // 抽样比例，0到1之间
+	MaxPending     int           `mapstructure:"max_pending"`     // 最多同时等待解析结果的抽样数
+	PendingTTL     time.Duration `mapstructure:"pending_ttl"`     // 超过该时长未返回解析结果时记为签名不一致
+	ReportInterval time.Duration `mapstructure:"report_interval"` // 输出不一致比例的间隔
+	AlertThreshold float64       `mapstructure:"alert_threshold"` // 不一致比例超过该值时输出警告，0表示不告警
+}
+
+// PayloadSamplesConfig 解析失败的原始数据抽样配置
+type PayloadSamplesConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`           // 是否启用
+	MaxGroups       int           `mapstructure:"max_groups"`        // 最多保留的错误类别数，超过时淘汰最久未出现的类别
+	SamplesPerGroup int           `mapstructure:"samples_per_group"` // 每个错误类别保留的最近样本数
+	MaxPayloadBytes int           `mapstructure:"max_payload_bytes"` // 每个样本最多保留的字节数，超过时截断
+	LogInterval     time.Duration `mapstructure:"log_interval"`      // 同一错误类别在该间隔内只输出一次日志
+}
+
+// StallDetectionConfig 出块停滞检测配置
+type StallDetectionConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	Threshold     time.Duration `mapstructure:"threshold"`      // WebSocket已连接但超过该时长未收到槽位通知时告警
+	CheckInterval time.Duration `mapstructure:"check_interval"` // 检查间隔
+	ProbeTimeout  time.Duration `mapstructure:"probe_timeout"`  // HTTP getSlot探测超时
+}
+
+// SubscriptionWatchdogConfig WebSocket订阅静默检测配置，订阅超过阈值未收到通知时告警，并可断开所在连接重连恢复订阅
+type SubscriptionWatchdogConfig struct {
+	Enabled       bool                     `mapstructure:"enabled"`        // 是否启用
+	CheckInterval time.Duration            `mapstructure:"check_interval"` // 检查间隔
+	MaxSilence    map[string]time.Duration `mapstructure:"max_silence"`    // 按订阅类型(方法名去掉 Subscribe，如 slot、block、logs)的最长静默时长，未列出的订阅不检查
+	Reconnect     bool                     `mapstructure:"reconnect"`      // 超过阈值时是否断开该订阅所在的连接重连
+}
+
+// FinalityConfig 最终确认检查配置
+type FinalityConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用，仅对以 confirmed/processed 承诺级别处理的区块生效
+	Delay         time.Duration `mapstructure:"delay"`          // 区块处理完成后等待多久再检查
+	CheckInterval time.Duration `mapstructure:"check_interval"` // 检查间隔
+	MaxAttempts   int           `mapstructure:"max_attempts"`   // 检查请求失败或区块尚未最终确认时的最大检查次数
+	MaxPending    int           `mapstructure:"max_pending"`    // 内存中最多等待检查的槽位数
+	MaxHistory    int64         `mapstructure:"max_history"`    // Redis中最多保留的未确认槽位记录数
+}
+
+// TokenRiskConfig 新代币风险检测配置
+type TokenRiskConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`             // 是否启用
+	Delay             time.Duration `mapstructure:"delay"`               // 发现新代币后等待多久再检测，留出交易时间使持仓分布有意义
+	Timeout           time.Duration `mapstructure:"timeout"`             // 检测一个代币的最长时间
+	QueueSize         int           `mapstructure:"queue_size"`          // 等待检测的代币队列长度，队列满时丢弃新发现的代币
+	MaxHolderAccounts int           `mapstructure:"max_holder_accounts"` // 统计持仓集中度时最多查询的代币账户数
+	TopHolderShare    float64       `mapstructure:"top_holder_share"`    // 最大持有者占供应量的比例超过该值时标记持仓集中
+	Top10Share        float64       `mapstructure:"top10_share"`         // 前10持有者占供应量的比例超过该值时标记持仓集中
+	ExcludedOwners    []string      `mapstructure:"excluded_owners"`     // 统计持仓集中度时排除的持有者，如AMM池子的权限账户
+	TTL               time.Duration `mapstructure:"ttl"`                 // 检测结果保存时长，0表示不过期
+}
+
+// PoolWatcherConfig 流动性池创建监控配置
+type PoolWatcherConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 是否启用
+	Programs      []string      `mapstructure:"programs"`       // 监控的程序，支持别名 raydium_amm、raydium_cpmm、meteora_dlmm、meteora_amm
+	Commitment    string        `mapstructure:"commitment"`     // logsSubscribe和getTransaction的确认级别
+	CheckInterval time.Duration `mapstructure:"check_interval"` // 检查订阅是否有效的间隔，连接断开后在下次检查时重新订阅
+	FetchAttempts int           `mapstructure:"fetch_attempts"` // getTransaction尚未返回交易时的最大尝试次数
+	FetchDelay    time.Duration `mapstructure:"fetch_delay"`    // getTransaction重试间隔
+	MaxHistory    int64         `mapstructure:"max_history"`    // Redis中最多保留的池子创建记录数
+}
+
+// CongestionConfig 网络拥堵感知限流配置
+type CongestionConfig struct {
+	Enabled                       bool    `mapstructure:"enabled"`                          // 是否启用
+	WindowBlocks                  int     `mapstructure:"window_blocks"`                    // 统计最近多少个区块
+	SkippedSlotRate               float64 `mapstructure:"skipped_slot_rate"`                // 跳过槽位比例超过该值时判定为拥堵
+	FailedTxRate                  float64 `mapstructure:"failed_tx_rate"`                   // 非投票交易失败比例超过该值时判定为拥堵
+	RecoveryRatio                 float64 `mapstructure:"recovery_ratio"`                   // 各比例降到阈值的该倍数以下时恢复，避免频繁切换
+	LatencyTargetMultiplier       float64 `mapstructure:"latency_target_multiplier"`        // 拥堵期间队列最大等待时间的放宽倍数
+	EnhancedAPIIntervalMultiplier float64 `mapstructure:"enhanced_api_interval_multiplier"` // 拥堵期间Enhanced API请求间隔的放大倍数
+}
+
+// BlockStateConfig 区块处理状态跟踪配置
+type BlockStateConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`         // 是否在Redis中记录每个区块的处理状态
+	StuckThreshold time.Duration `mapstructure:"stuck_threshold"` // 在FETCHING/PARSING状态停留超过该时长视为卡住
+	CheckInterval  time.Duration `mapstructure:"check_interval"`  // 卡住检测间隔
+	MaxRetries     int           `mapstructure:"max_retries"`     // 卡住后自动重试的最大次数，超过后标记为FAILED
+	Retention      time.Duration `mapstructure:"retention"`       // DONE/FAILED状态的保留时长
+}
+
+// SkippedSlotsConfig 多次获取失败的槽位跳过列表配置
+// 区块获取重试用尽后槽位加入Redis中的跳过列表，按逐渐变长的间隔重新推入区块队列
+type SkippedSlotsConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`            // 是否启用
+	RetryInterval    time.Duration `mapstructure:"retry_interval"`     // 第一次慢速重试前的等待时间，之后每次失败翻倍
+	MaxRetryInterval time.Duration `mapstructure:"max_retry_interval"` // 重试间隔的上限
+	MaxAttempts      int           `mapstructure:"max_attempts"`       // 进入跳过列表后最多失败的次数，达到后不再自动重试，0表示不限制
+	CheckInterval    time.Duration `mapstructure:"check_interval"`     // 检查需要重试的槽位的间隔
+}
+
+// TracingConfig OpenTelemetry链路追踪配置
+// 为每个槽位创建一条链路，记录区块获取、签名分批、Enhanced API解析和结果写入的耗时，通过OTLP/HTTP导出
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`      // 是否启用
+	Endpoint    string            `mapstructure:"endpoint"`     // OTLP/HTTP接收端地址(host:port)，如 localhost:4318
+	URLPath     string            `mapstructure:"url_path"`     // 接收端路径，为空时使用 /v1/traces
+	Insecure    bool              `mapstructure:"insecure"`     // 是否使用HTTP而不是HTTPS
+	Headers     map[string]string `mapstructure:"headers"`      // 导出时附加的请求头，如接收端的鉴权
+	ServiceName string            `mapstructure:"service_name"` // 上报的服务名称
+	SampleRatio float64           `mapstructure:"sample_ratio"` // 按槽位采样的比例，0到1
+	MaxSlots    int               `mapstructure:"max_slots"`    // 同时进行中的槽位链路上限
+	SlotTimeout time.Duration     `mapstructure:"slot_timeout"` // 槽位链路超过该时长仍未结束时，在达到上限后结束
+}
+
+// 全局配置实例，启动时加载后不再替换；热更新后的配置通过 Current 或 OnChange 获取
 var GlobalConfig *Config
 
-// LoadConfig 加载配置文件
+// current 最近一次加载的配置，热更新时原子替换
+var current atomic.Pointer[Config]
+
+// Current 返回最近一次加载的配置，开启热更新时反映配置文件的最新内容
+// 返回的配置只读，未加载配置时返回nil
+func Current() *Config {
+	return current.Load()
+}
+
+// LoadConfig 加载配置文件，读取、解析或校验失败时panic
 func LoadConfig(configPath string) {
+	cfg, v, err := readConfig(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	// 校验配置，汇总所有问题后一并报告
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	// 设置全局配置
+	SetGlobalConfig(cfg)
+	globalViper = v
+}
+
+// SetGlobalConfig 设置全局配置和 Current 返回的配置，需在各模块启动前调用，测试可用它注入配置
+func SetGlobalConfig(cfg *Config) {
+	GlobalConfig = cfg
+	current.Store(cfg)
+}
+
+// CheckConfig 读取并校验配置文件，不设置全局配置，用于 --check-config 模式
+func CheckConfig(configPath string) error {
+	cfg, _, err := readConfig(configPath)
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// DefaultConfig 返回只包含默认值的配置，不读取配置文件和环境变量，用于测试或作为库嵌入时在代码中构造配置
+func DefaultConfig() (*Config, error) {
+	v := viper.New()
+	setDefaultConfig(v)
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("解析默认配置失败: %w", err)
+	}
+	return cfg, nil
+}
+
+// readConfig 读取并解析配置文件
+func readConfig(configPath string) (*Config, *viper.Viper, error) {
 	v := viper.New()
 
 	// 设置默认配置
@@ -132,20 +846,35 @@ func LoadConfig(configPath string) {
 	if err := v.ReadInConfig(); err != nil {
 		// 如果找不到配置文件，创建默认配置文件
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			panic(fmt.Errorf("找不到指定的配置文件: %s, 错误: %w", configPath, err))
-		} else {
-			panic(fmt.Errorf("读取配置文件失败: %w", err))
+			return nil, nil, fmt.Errorf("找不到指定的配置文件: %s, 错误: %w", configPath, err)
 		}
+		return nil, nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	cfg, err := decodeConfig(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, v, nil
+}
+
+// decodeConfig 合并环境配置文件、解析配置并替换密钥引用
+func decodeConfig(v *viper.Viper) (*Config, error) {
+	if err := mergeProfileConfig(v); err != nil {
+		return nil, err
 	}
 
 	// 解析配置
 	cfg := &Config{}
 	if err := v.Unmarshal(cfg); err != nil {
-		panic(fmt.Errorf("解析配置失败: %w", err))
+		return nil, fmt.Errorf("解析配置失败: %w", err)
 	}
 
-	// 设置全局配置
-	GlobalConfig = cfg
+	// 替换密钥引用
+	if err := resolveSecrets(cfg); err != nil {
+		return nil, fmt.Errorf("解析密钥引用失败: %w", err)
+	}
+	return cfg, nil
 }
 
 // setDefaultConfig 设置默认配置
@@ -154,6 +883,10 @@ func setDefaultConfig(v *viper.Viper) {
 	v.SetDefault("app.name", "datas-go")
 	v.SetDefault("app.environment", "development")
 	v.SetDefault("app.version", "0.1.0")
+	v.SetDefault("app.hot_reload", false)
+	v.SetDefault("app.test_mode.enabled", false)
+	v.SetDefault("app.test_mode.start_time", "2024-01-01T00:00:00Z")
+	v.SetDefault("app.test_mode.id_prefix", "test-")
 
 	// 日志配置
 	v.SetDefault("log.level", "info")
@@ -164,6 +897,7 @@ func setDefaultConfig(v *viper.Viper) {
 	v.SetDefault("log.max_age", 7)
 	v.SetDefault("log.compress", true)
 	v.SetDefault("log.stdout", true)
+	v.SetDefault("log.recent_errors", 100)
 
 	// RPC配置
 	v.SetDefault("rpc.endpoint", "https://api.mainnet-beta.solana.com")
@@ -173,29 +907,412 @@ func setDefaultConfig(v *viper.Viper) {
 	v.SetDefault("rpc.retries", 3)
 	v.SetDefault("rpc.retry_interval", 1*time.Second)
 
+	// 代理配置
+	v.SetDefault("proxy.enabled", false)
+	v.SetDefault("proxy.url", "")
+	v.SetDefault("proxy.probe", true)
+	v.SetDefault("proxy.probe_timeout", 10*time.Second)
+	v.SetDefault("proxy.fallback_direct", false)
+
 	// Redis配置
 	v.SetDefault("redis.addr", "localhost:6379")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
 	v.SetDefault("redis.pool_size", 10)
 	v.SetDefault("redis.timeout", 5*time.Second)
+	v.SetDefault("redis.key_prefix", "solana")
+	v.SetDefault("redis.degraded.policy", "buffer")
+	v.SetDefault("redis.degraded.buffer_size", 100000)
+	v.SetDefault("redis.degraded.probe_interval", 5*time.Second)
 
 	// 解析器配置
 	v.SetDefault("parser.poll_interval", 1*time.Second)
 	v.SetDefault("parser.batch_size", 100)
 	v.SetDefault("parser.state_file_path", "./data/last_slot.dat")
 	v.SetDefault("parser.concurrent_workers", 5)
+	v.SetDefault("parser.resume", true)
+	v.SetDefault("parser.max_gap", 1000)
+	v.SetDefault("parser.max_concurrent_batches", 16)
+	v.SetDefault("parser.key_rps", 5)
 
 	// WebSocket配置
 	v.SetDefault("websocket.enabled", false)
 	v.SetDefault("websocket.network_type", "mainnet")
+	v.SetDefault("websocket.endpoint", "")
 	v.SetDefault("websocket.api_key", "")
 	v.SetDefault("websocket.reconnect_interval", 5*time.Second)
+	v.SetDefault("websocket.request_timeout", 10*time.Second)
+	v.SetDefault("websocket.ping_interval", 30*time.Second)
+	v.SetDefault("websocket.liveness_timeout", 90*time.Second)
 	v.SetDefault("websocket.proxy_url", "")
+	v.SetDefault("websocket.insecure_skip_verify", false)
+	v.SetDefault("websocket.enable_compression", true)
+	v.SetDefault("websocket.read_limit", 256<<20)
+	v.SetDefault("websocket.read_buffer_size", 64<<10)
+	v.SetDefault("websocket.write_buffer_size", 4<<10)
+	v.SetDefault("websocket.block_chunk_size", 100)
+	v.SetDefault("websocket.pool_size", 1)
+	v.SetDefault("websocket.ingestion_mode", "slot")
+	v.SetDefault("websocket.block_commitment", "confirmed")
+	v.SetDefault("websocket.block_transaction_details", "full")
+	v.SetDefault("websocket.slot_confirmation_depth", 0)
+
+	// 区块预取配置
+	v.SetDefault("block_prefetch.enabled", false)
+	v.SetDefault("block_prefetch.lookahead", 8)
+	v.SetDefault("block_prefetch.concurrency", 4)
+	v.SetDefault("block_prefetch.min_interval", 50*time.Millisecond)
+	v.SetDefault("block_prefetch.poll_interval", 200*time.Millisecond)
+	v.SetDefault("block_prefetch.ttl", 2*time.Minute)
+
+	// Enhanced API解析结果缓存配置
+	v.SetDefault("enrichment_cache.enabled", false)
+	v.SetDefault("enrichment_cache.ttl", 24*time.Hour)
+
+	// 不再解析缓存配置
+	v.SetDefault("negative_cache.enabled", false)
+	v.SetDefault("negative_cache.max_attempts", 3)
+	v.SetDefault("negative_cache.ttl", 7*24*time.Hour)
+
+	// 交易索引配置
+	v.SetDefault("transaction_index.ttl", 7*24*time.Hour)
+	v.SetDefault("transaction_index.type_ttl", map[string]time.Duration{})
+	v.SetDefault("transaction_index.max_per_key", 200000)
+	v.SetDefault("transaction_index.index_mints", true)
+	v.SetDefault("transaction_index.cleanup_interval", time.Hour)
+
+	// 区块哈希索引配置
+	v.SetDefault("block_index.enabled", false)
+	v.SetDefault("block_index.ttl", 72*time.Hour)
+	v.SetDefault("block_summary.enabled", false)
+	v.SetDefault("block_summary.max_entries", 200000)
+
+	// 区块交易预过滤配置
+	v.SetDefault("block_filter.enabled", false)
+	v.SetDefault("block_filter.programs", []string{})
+	v.SetDefault("block_filter.accounts", []string{})
+	v.SetDefault("raw_parse.enabled", false)
+	v.SetDefault("raw_parse.programs", []string{})
+
+	// 管理接口配置
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.addr", "127.0.0.1:8090")
+	v.SetDefault("admin.auth_token", "")
+	v.SetDefault("admin.max_body_bytes", 1<<20)
+
+	// 队列配置
+	v.SetDefault("queue.block_max_age", 0)
+	v.SetDefault("queue.transaction_max_age", 0)
+	v.SetDefault("queue.transaction_scheduling", "block")
+	v.SetDefault("queue.interleave_blocks", 4)
+	v.SetDefault("queue.max_concurrent_batches", 8)
+	v.SetDefault("queue.backend", "memory")
+	v.SetDefault("queue.stream.key", "")
+	v.SetDefault("queue.stream.group", "parsers")
+	v.SetDefault("queue.stream.consumer", "")
+	v.SetDefault("queue.stream.claim_idle", 5*time.Minute)
+	v.SetDefault("queue.stream.claim_interval", 30*time.Second)
+	v.SetDefault("queue.stream.max_len", 0)
+	v.SetDefault("queue.lanes.enabled", false)
+	v.SetDefault("queue.lanes.live_window", 300)
+	v.SetDefault("queue.lanes.live_weight", 3)
+	v.SetDefault("queue.lanes.backfill_weight", 1)
+
+	// 分布式处理配置
+	v.SetDefault("distributed.enabled", false)
+	v.SetDefault("distributed.instance_id", "")
+	v.SetDefault("distributed.lease_ttl", time.Minute)
+	v.SetDefault("distributed.done_ttl", time.Hour)
+	v.SetDefault("distributed.heartbeat_interval", 5*time.Second)
+	v.SetDefault("distributed.instance_ttl", 30*time.Second)
+	v.SetDefault("distributed.leader_election.enabled", false)
+	v.SetDefault("distributed.leader_election.ttl", 10*time.Second)
+	v.SetDefault("distributed.leader_election.renew_interval", 2*time.Second)
+
+	// 定时任务配置
+	v.SetDefault("jobs.enabled", false)
+	v.SetDefault("jobs.history", 50)
+	v.SetDefault("jobs.timeout", 10*time.Minute)
+
+	// Redis数据保留配置
+	v.SetDefault("retention.dry_run", false)
+	v.SetDefault("retention.batch_size", 500)
+	v.SetDefault("retention.samples", 20)
+
+	// 协程panic重启配置
+	v.SetDefault("supervisor.initial_backoff", time.Second)
+	v.SetDefault("supervisor.max_backoff", time.Minute)
+
+	// 进程内事件订阅配置
+	v.SetDefault("pipeline.subscriber_buffer", 1024)
+	v.SetDefault("pipeline.stages.enabled", false)
+	v.SetDefault("pipeline.stages.disabled", []string{})
+	v.SetDefault("pipeline.stages.start_retries", 3)
+	v.SetDefault("pipeline.stages.retry_interval", 5*time.Second)
+	v.SetDefault("pipeline.stages.start_timeout", 60*time.Second)
+
+	// 规则引擎配置
+	v.SetDefault("rules.enabled", false)
+	v.SetDefault("rules.alert_history", 1000)
+	v.SetDefault("rules.trade_queue_size", 16)
+	v.SetDefault("watchlist.enabled", false)
+	v.SetDefault("watchlist.webhook_id", "")
+	v.SetDefault("watchlist.pump_portal", false)
+	v.SetDefault("watchlist.sync_interval", 10*time.Minute)
+	v.SetDefault("watchlist.allow_empty", false)
+
+	// 买卖盘失衡统计配置
+	v.SetDefault("order_flow.enabled", false)
+	v.SetDefault("order_flow.window", 5*time.Minute)
+	v.SetDefault("order_flow.interval", time.Minute)
+	v.SetDefault("order_flow.retention", 24*time.Hour)
+
+	// 代币账户创建/关闭统计配置
+	v.SetDefault("token_accounts.enabled", false)
+	v.SetDefault("token_accounts.interval", time.Minute)
+	v.SetDefault("token_accounts.retention", 7*24*time.Hour)
+
+	// 按交易来源统计配置
+	v.SetDefault("source_volume.enabled", false)
+	v.SetDefault("source_volume.flush_interval", 10*time.Second)
+	v.SetDefault("source_volume.retention", 30*24*time.Hour)
+
+	// 代币24小时统计配置
+	v.SetDefault("token_stats.enabled", false)
+	v.SetDefault("token_stats.max_mints", 5000)
+
+	// 联合曲线进度统计配置
+	v.SetDefault("bonding_curve.enabled", false)
+	v.SetDefault("bonding_curve.alert_progress", 0.9)
+	v.SetDefault("bonding_curve.max_mints", 10000)
+
+	// 新代币风险检测配置
+	v.SetDefault("token_risk.enabled", false)
+	v.SetDefault("token_risk.delay", 30*time.Second)
+	v.SetDefault("token_risk.timeout", 15*time.Second)
+	v.SetDefault("token_risk.queue_size", 1000)
+	v.SetDefault("token_risk.max_holder_accounts", 5000)
+	v.SetDefault("token_risk.top_holder_share", 0.2)
+	v.SetDefault("token_risk.top10_share", 0.5)
+	v.SetDefault("token_risk.excluded_owners", []string{"5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1"})
+	v.SetDefault("token_risk.ttl", 7*24*time.Hour)
+
+	// 持仓统计配置
+	v.SetDefault("positions.enabled", false)
+	v.SetDefault("positions.flush_interval", 10*time.Second)
+	v.SetDefault("positions.position_ttl", 0)
+	v.SetDefault("positions.retention", 30*24*time.Hour)
+
+	// 计算单元价格统计配置
+	v.SetDefault("priority_fee.enabled", false)
+	v.SetDefault("priority_fee.window_blocks", 150)
+	v.SetDefault("program_fees.enabled", false)
+	v.SetDefault("program_fees.programs", []string{})
+	v.SetDefault("program_fees.flush_interval", 10*time.Second)
+	v.SetDefault("program_fees.retention", 7*24*time.Hour)
+
+	// 容量规划指标快照配置
+	v.SetDefault("capacity.enabled", false)
+	v.SetDefault("capacity.interval", 5*time.Minute)
+	v.SetDefault("capacity.retention", 90*24*time.Hour)
+	v.SetDefault("capacity.rpc_request_credits", 1)
+	v.SetDefault("capacity.enhanced_request_credits", 100)
+	v.SetDefault("capacity.credits_per_api_key", 0)
+
+	// 健康检查配置
+	v.SetDefault("health.max_message_age", 2*time.Minute)
+	v.SetDefault("health.max_queue_staleness", 5*time.Minute)
+
+	// 独立解析服务配置
+	v.SetDefault("parse_server.addr", "127.0.0.1:8091")
+	v.SetDefault("parse_server.max_signatures", 100)
+	v.SetDefault("parse_server.max_transactions", 1000)
+	v.SetDefault("parse_server.max_body_bytes", 16<<20)
+	v.SetDefault("parse_server.timeout", 60*time.Second)
+
+	// 解析结果抽样校验配置
+	v.SetDefault("verification.enabled", false)
+	v.SetDefault("verification.sample_rate", 0.01)
+	v.SetDefault("verification.max_pending", 10000)
+	v.SetDefault("verification.pending_ttl", 10*time.Minute)
+	v.SetDefault("verification.report_interval", 5*time.Minute)
+	v.SetDefault("verification.alert_threshold", 0.05)
+
+	// 解析失败的原始数据抽样配置
+	v.SetDefault("payload_samples.enabled", false)
+	v.SetDefault("payload_samples.max_groups", 100)
+	v.SetDefault("payload_samples.samples_per_group", 5)
+	v.SetDefault("payload_samples.max_payload_bytes", 64<<10)
+	v.SetDefault("payload_samples.log_interval", time.Minute)
+
+	// 出块停滞检测配置
+	v.SetDefault("stall_detection.enabled", false)
+	v.SetDefault("stall_detection.threshold", 30*time.Second)
+	v.SetDefault("stall_detection.check_interval", 5*time.Second)
+	v.SetDefault("stall_detection.probe_timeout", 10*time.Second)
+	v.SetDefault("subscription_watchdog.enabled", false)
+	v.SetDefault("subscription_watchdog.check_interval", 5*time.Second)
+	v.SetDefault("subscription_watchdog.max_silence", map[string]interface{}{"slot": "30s", "block": "60s"})
+	v.SetDefault("subscription_watchdog.reconnect", true)
+
+	// 流动性池创建监控配置
+	v.SetDefault("pool_watcher.enabled", false)
+	v.SetDefault("pool_watcher.programs", []string{"raydium_amm", "raydium_cpmm", "meteora_dlmm", "meteora_amm"})
+	v.SetDefault("pool_watcher.commitment", "confirmed")
+	v.SetDefault("pool_watcher.check_interval", 30*time.Second)
+	v.SetDefault("pool_watcher.fetch_attempts", 5)
+	v.SetDefault("pool_watcher.fetch_delay", 2*time.Second)
+	v.SetDefault("pool_watcher.max_history", 10000)
+
+	// 最终确认检查配置
+	v.SetDefault("finality.enabled", false)
+	v.SetDefault("finality.delay", time.Minute)
+	v.SetDefault("finality.check_interval", 10*time.Second)
+	v.SetDefault("finality.max_attempts", 5)
+	v.SetDefault("finality.max_pending", 10000)
+	v.SetDefault("finality.max_history", 10000)
+
+	// 网络拥堵感知限流配置
+	v.SetDefault("congestion.enabled", false)
+	v.SetDefault("congestion.window_blocks", 100)
+	v.SetDefault("congestion.skipped_slot_rate", 0.2)
+	v.SetDefault("congestion.failed_tx_rate", 0.4)
+	v.SetDefault("congestion.recovery_ratio", 0.8)
+	v.SetDefault("congestion.latency_target_multiplier", 3.0)
+	v.SetDefault("congestion.enhanced_api_interval_multiplier", 2.0)
+
+	// 区块处理状态跟踪配置
+	v.SetDefault("block_state.enabled", false)
+	v.SetDefault("block_state.stuck_threshold", 5*time.Minute)
+	v.SetDefault("block_state.check_interval", time.Minute)
+	v.SetDefault("block_state.max_retries", 3)
+	v.SetDefault("block_state.retention", 24*time.Hour)
+
+	// 跳过列表配置
+	v.SetDefault("skipped_slots.enabled", false)
+	v.SetDefault("skipped_slots.retry_interval", 5*time.Minute)
+	v.SetDefault("skipped_slots.max_retry_interval", 6*time.Hour)
+	v.SetDefault("skipped_slots.max_attempts", 10)
+	v.SetDefault("skipped_slots.check_interval", time.Minute)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.url_path", "")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.service_name", "datas-go")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.max_slots", 10000)
+	v.SetDefault("tracing.slot_timeout", 10*time.Minute)
+
+	// 原始响应归档配置
+	v.SetDefault("raw_archive.enabled", false)
+	v.SetDefault("raw_archive.compress", true)
+	v.SetDefault("raw_archive.ttl", 7*24*time.Hour)
+	v.SetDefault("raw_archive.dir", "")
+	v.SetDefault("raw_archive.file_slots", 1000)
+	v.SetDefault("raw_archive.file_max_age", 10*time.Minute)
+	v.SetDefault("clickhouse.enabled", false)
+	v.SetDefault("clickhouse.endpoint", "http://localhost:8123")
+	v.SetDefault("clickhouse.database", "solana")
+	v.SetDefault("clickhouse.username", "default")
+	v.SetDefault("clickhouse.password", "")
+	v.SetDefault("clickhouse.transactions_table", "transactions")
+	v.SetDefault("clickhouse.blocks_table", "blocks")
+	v.SetDefault("clickhouse.create_schema", true)
+	v.SetDefault("clickhouse.ttl", 0)
+	v.SetDefault("clickhouse.include_raw", false)
+	v.SetDefault("clickhouse.batch_size", 5000)
+	v.SetDefault("clickhouse.flush_interval", 5*time.Second)
+	v.SetDefault("clickhouse.max_buffered", 100000)
+	v.SetDefault("clickhouse.async_insert", false)
+	v.SetDefault("clickhouse.wait_for_async_insert", true)
+	setHTTPClientDefaults(v, "clickhouse.http", 30*time.Second)
+
+	// Helius API 配置
+	v.SetDefault("helius_api.batch_size", 0)
+	setHTTPClientDefaults(v, "helius_api.http", 120*time.Second)
+	setHTTPClientDefaults(v, "helius_enhanced_api.http", 120*time.Second)
+	v.SetDefault("helius_enhanced_api.auth.modes", []string{"query", "basic"})
+	v.SetDefault("helius_enhanced_api.auth.query_param", "api-key")
+	v.SetDefault("helius_enhanced_api.auth.header", "X-API-Key")
+	v.SetDefault("helius_enhanced_api.auth.header_prefix", "")
+	v.SetDefault("helius_enhanced_api.usage_retention", 30*24*time.Hour)
+	v.SetDefault("helius_enhanced_api.quarantine.enabled", true)
+	v.SetDefault("helius_enhanced_api.quarantine.rejected_cooldown", 10*time.Minute)
+	v.SetDefault("helius_enhanced_api.quarantine.rate_limit_threshold", 5)
+	v.SetDefault("helius_enhanced_api.quarantine.rate_limit_cooldown", time.Minute)
+	v.SetDefault("helius_enhanced_api.quarantine.min_healthy_keys", 1)
+	setHTTPClientDefaults(v, "helius_webhook.http", 30*time.Second)
+	setHTTPClientDefaults(v, "jupiter_price.http", 10*time.Second)
+
+	// PumpPortal交易API配置
+	setHTTPClientDefaults(v, "pump_portal_trade.http", 15*time.Second)
+	v.SetDefault("pump_portal_trade.enabled", false)
+	v.SetDefault("pump_portal_trade.dry_run", true)
+	v.SetDefault("pump_portal_trade.rule_ids", []string{})
+	v.SetDefault("pump_portal_trade.mode", "lightning")
+	v.SetDefault("pump_portal_trade.endpoint", "https://pumpportal.fun/api")
+	v.SetDefault("pump_portal_trade.api_key", "")
+	v.SetDefault("pump_portal_trade.private_key", "")
+	v.SetDefault("pump_portal_trade.proxy_url", "")
+	v.SetDefault("pump_portal_trade.pool", "auto")
+	v.SetDefault("pump_portal_trade.slippage", 10)
+	v.SetDefault("pump_portal_trade.priority_fee", 0.00005)
+	v.SetDefault("pump_portal_trade.skip_preflight", false)
+	v.SetDefault("pump_portal_trade.max_sol_per_trade", 0.1)
+	v.SetDefault("pump_portal_trade.max_trades_per_hour", 10)
+	v.SetDefault("pump_portal_trade.mint_cooldown", time.Hour)
+
+	// Jupiter价格API配置
+	v.SetDefault("jupiter_price.enabled", false)
+	v.SetDefault("jupiter_price.endpoint", "https://lite-api.jup.ag/price/v3")
+	v.SetDefault("jupiter_price.api_key", "")
+	v.SetDefault("jupiter_price.cache_ttl", 30*time.Second)
+	v.SetDefault("jupiter_price.min_interval", time.Second)
+	v.SetDefault("jupiter_price.batch_size", 50)
+
+	// 钱包历史上下文配置
+	v.SetDefault("wallet_context.enabled", false)
+	v.SetDefault("wallet_context.history_limit", 100)
+	v.SetDefault("wallet_context.cache_ttl", 10*time.Minute)
+	v.SetDefault("wallet_context.timeout", 5*time.Second)
 
 	// Helius Webhook 配置
 	v.SetDefault("helius_webhook.api_key", "")
+	v.SetDefault("helius_webhook.endpoint", "https://api.helius.xyz")
 	v.SetDefault("helius_webhook.callback_url", "")
+	v.SetDefault("helius_webhook.max_attempts", 4)
+	v.SetDefault("helius_webhook.retry_backoff", time.Second)
+	v.SetDefault("helius_webhook.max_retry_backoff", 30*time.Second)
+	v.SetDefault("helius_webhook.sync", false)
+	v.SetDefault("helius_webhook.prune", false)
+	v.SetDefault("helius_webhook.receiver.enabled", false)
+	v.SetDefault("helius_webhook.receiver.addr", ":8092")
+	v.SetDefault("helius_webhook.receiver.path", "/webhooks/helius")
+	v.SetDefault("helius_webhook.receiver.max_body_bytes", 16<<20)
+	v.SetDefault("helius_webhook.receiver.dedupe_window", time.Hour)
+	v.SetDefault("helius_webhook.receiver.auth_header", "")
+	v.SetDefault("helius_webhook.receiver.hmac_secret", "")
+	v.SetDefault("helius_webhook.receiver.hmac_header", "X-Signature")
+	v.SetDefault("helius_webhook.receiver.client_ip_header", "")
+}
+
+// setHTTPClientDefaults 设置HTTP客户端的默认值
+// 参数:
+//   - v: viper实例
+//   - prefix: 配置键前缀，如 helius_api.http
+//   - timeout: 单个请求的默认总超时
+func setHTTPClientDefaults(v *viper.Viper, prefix string, timeout time.Duration) {
+	v.SetDefault(prefix+".timeout", timeout)
+	v.SetDefault(prefix+".dial_timeout", 10*time.Second)
+	v.SetDefault(prefix+".keep_alive", 30*time.Second)
+	v.SetDefault(prefix+".tls_handshake_timeout", 10*time.Second)
+	v.SetDefault(prefix+".response_header_timeout", 0)
+	v.SetDefault(prefix+".idle_conn_timeout", 90*time.Second)
+	v.SetDefault(prefix+".max_idle_conns", 100)
+	v.SetDefault(prefix+".max_idle_conns_per_host", 32)
+	v.SetDefault(prefix+".max_conns_per_host", 0)
+	v.SetDefault(prefix+".http2", true)
+	v.SetDefault(prefix+".insecure_skip_verify", false)
 }
 
 // createDefaultConfigFile 创建默认配置文件
@@ -254,6 +1371,7 @@ websocket:
 # Helius Webhook 配置
 helius_webhook:
   api_key: ""            # Helius API密钥
+  endpoint: https://api.helius.xyz # Helius API端点
   callback_url: ""       # Webhook回调URL
 `
 
@@ -0,0 +1,62 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// 通过命令行指定的环境，优先于配置文件中的 app.environment
+var profile string
+
+// SetProfile 指定加载配置时使用的环境，如 development、production
+// 需在 LoadConfig 之前调用，为空时使用配置中的 app.environment
+func SetProfile(name string) {
+	profile = name
+}
+
+// ProfileConfigFile 返回基础配置文件对应环境的配置文件路径
+// 如 config.yaml 在 production 环境下对应 config.production.yaml
+func ProfileConfigFile(baseFile, profile string) string {
+	ext := filepath.Ext(baseFile)
+	return strings.TrimSuffix(baseFile, ext) + "." + profile + ext
+}
+
+// mergeProfileConfig 将环境配置文件合并到基础配置之上
+// 通过 SetProfile 指定环境时环境配置文件必须存在；使用 app.environment 时不存在则忽略
+func mergeProfileConfig(v *viper.Viper) error {
+	name := profile
+	explicit := name != ""
+	if !explicit {
+		name = v.GetString("app.environment")
+	}
+	if name == "" || v.ConfigFileUsed() == "" {
+		return nil
+	}
+
+	profileFile := ProfileConfigFile(v.ConfigFileUsed(), name)
+	if _, err := os.Stat(profileFile); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("读取环境配置文件失败: %s, 错误: %w", profileFile, err)
+	}
+
+	// 使用独立的viper读取环境配置，避免改变基础配置文件路径，热更新时仍监听基础配置文件
+	pv := viper.New()
+	pv.SetConfigFile(profileFile)
+	if err := pv.ReadInConfig(); err != nil {
+		return fmt.Errorf("读取环境配置文件失败: %s, 错误: %w", profileFile, err)
+	}
+	if err := v.MergeConfigMap(pv.AllSettings()); err != nil {
+		return fmt.Errorf("合并环境配置文件失败: %s, 错误: %w", profileFile, err)
+	}
+	if explicit {
+		v.Set("app.environment", name)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package configs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// 密钥引用格式，配置中的字符串值以下列前缀开头时，加载配置时替换为实际的密钥内容:
+//   - env://VAR_NAME                    读取环境变量
+//   - file:///path/to/secret            读取文件内容(去除首尾空白)
+//   - vault://secret/data/helius#api_key 读取Vault KV(v1/v2)中的字段，需设置 VAULT_ADDR 和 VAULT_TOKEN
+//   - awssm://secret-id#api_key          读取AWS Secrets Manager中的密钥，指定字段时按JSON解析，使用默认的AWS凭证链
+const (
+	secretSchemeEnv   = "env://"
+	secretSchemeFile  = "file://"
+	secretSchemeVault = "vault://"
+	secretSchemeAWSSM = "awssm://"
+)
+
+// 获取外部密钥的超时时间
+const secretFetchTimeout = 10 * time.Second
+
+// secretResolver 解析去除前缀后的密钥引用
+type secretResolver func(ctx context.Context, ref string) (string, error)
+
+// secretResolvers 按前缀注册的密钥解析器
+var secretResolvers = map[string]secretResolver{
+	secretSchemeEnv:   resolveEnvSecret,
+	secretSchemeFile:  resolveFileSecret,
+	secretSchemeVault: resolveVaultSecret,
+	secretSchemeAWSSM: resolveAWSSecret,
+}
+
+// resolveSecrets 遍历配置中的字符串字段，将密钥引用替换为实际的密钥内容
+// 同一引用只解析一次，所有解析失败的字段汇总后一并返回
+func resolveSecrets(cfg *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretFetchTimeout)
+	defer cancel()
+
+	cache := make(map[string]string)
+	var errs []error
+	resolve := func(path, value string) string {
+		scheme, ref, ok := secretReference(value)
+		if !ok {
+			return value
+		}
+		if secret, ok := cache[value]; ok {
+			return secret
+		}
+		secret, err := secretResolvers[scheme](ctx, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: 解析密钥引用 %s 失败: %w", path, scheme+"...", err))
+			return value
+		}
+		cache[value] = secret
+		return secret
+	}
+	walkStrings(reflect.ValueOf(cfg).Elem(), "", resolve)
+	return errors.Join(errs...)
+}
+
+// secretReference 判断字符串是否为密钥引用，返回前缀和引用内容
+func secretReference(value string) (string, string, bool) {
+	for scheme := range secretResolvers {
+		if strings.HasPrefix(value, scheme) {
+			return scheme, strings.TrimPrefix(value, scheme), true
+		}
+	}
+	return "", "", false
+}
+
+// walkStrings 递归遍历结构体、切片和映射中的字符串，使用 fn 的返回值替换原值
+// path 使用 mapstructure 标签拼接，用于错误提示
+func walkStrings(v reflect.Value, path string, fn func(path, value string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(fn(path, v.String()))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			walkStrings(v.Field(i), joinPath(path, name), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			walkStrings(elem, joinPath(path, key.String()), fn)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), path, fn)
+		}
+	}
+}
+
+// joinPath 拼接配置项路径
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// resolveEnvSecret 从环境变量读取密钥
+func resolveEnvSecret(_ context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", ref)
+	}
+	return value, nil
+}
+
+// resolveFileSecret 从文件读取密钥，适用于 Kubernetes/Docker secret 挂载
+func resolveFileSecret(_ context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveVaultSecret 通过 Vault HTTP API 读取密钥，引用格式为 <path>#<field>
+func resolveVaultSecret(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("Vault引用格式应为 vault://<path>#<field>")
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("未设置 VAULT_ADDR 或 VAULT_TOKEN")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vault返回状态码 %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("解析Vault响应失败: %w", err)
+	}
+	// KV v2 的字段位于 data.data 下
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("Vault密钥中不存在字段 %s", field)
+	}
+	return value, nil
+}
+
+// resolveAWSSecret 从 AWS Secrets Manager 读取密钥，引用格式为 <secret-id>[#<field>]
+func resolveAWSSecret(ctx context.Context, ref string) (string, error) {
+	secretID, field, _ := strings.Cut(ref, "#")
+	if secretID == "" {
+		return "", fmt.Errorf("AWS Secrets Manager引用格式应为 awssm://<secret-id>[#<field>]")
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("加载AWS配置失败: %w", err)
+	}
+	output, err := secretsmanager.NewFromConfig(awsConfig).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: &secretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if output.SecretString == nil {
+		return "", fmt.Errorf("密钥 %s 不是字符串类型", secretID)
+	}
+	if field == "" {
+		return *output.SecretString, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(*output.SecretString), &values); err != nil {
+		return "", fmt.Errorf("密钥 %s 不是JSON格式，无法读取字段 %s", secretID, field)
+	}
+	value, ok := values[field].(string)
+	if !ok {
+		return "", fmt.Errorf("密钥 %s 中不存在字段 %s", secretID, field)
+	}
+	return value, nil
+}
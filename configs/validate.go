@@ -0,0 +1,1075 @@
+package configs
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/jobs/cron"
+)
+
+// ValidationError 汇总配置校验发现的所有问题
+type ValidationError struct {
+	Problems []string
+}
+
+// Error 实现error接口，逐行列出所有问题
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败，共%d个问题:", len(e.Problems))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// 支持的日志级别
+var validLogLevels = []string{"debug", "info", "warn", "warning", "error", "dpanic", "panic", "fatal"}
+
+// JSON-RPC批量请求中允许的最大区块数，getBlock响应较大，过大的批次容易超时
+const maxHeliusBatchSize = 100
+
+// 支持的API密钥传递方式
+var validAuthModes = []string{"query", "basic", "bearer", "header", "none"}
+
+// 支持的Webhook类型
+var validWebhookTypes = []string{"enhanced", "raw", "discord", "enhancedDevnet", "rawDevnet"}
+
+// 单个Webhook最多监控的地址数，与 rpc.MaxWebhookAddresses 一致
+const maxWebhookAddresses = 100000
+
+// 采集流程的阶段
+var validStages = []string{"ingest", "block_fetch", "parse", "sink"}
+
+// ClickHouse的数据库名和表名，会直接拼接到SQL中
+var clickHouseIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 支持的Redis负载
+var validRedisWorkloads = []string{"queue", "cache", "analytics"}
+
+// Validate 校验配置的语义约束，收集所有问题后一并返回，没有问题时返回nil
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// 日志
+	if !containsFold(validLogLevels, c.Log.Level) {
+		addf("log.level 无效: %q，可选值: %s", c.Log.Level, strings.Join(validLogLevels, ", "))
+	}
+	for module, level := range c.Log.Levels {
+		if !containsFold(validLogLevels, level) {
+			addf("log.levels.%s 无效: %q", module, level)
+		}
+	}
+	if format := strings.ToLower(c.Log.Format); format != "json" && format != "console" {
+		addf("log.format 无效: %q，可选值: json, console", c.Log.Format)
+	}
+	if c.Log.Path == "" && !c.Log.Stdout {
+		addf("log.path 为空且 log.stdout=false，日志将没有任何输出")
+	}
+	if c.Log.RecentErrors < 0 {
+		addf("log.recent_errors 不能为负数: %d", c.Log.RecentErrors)
+	}
+
+	// 测试模式
+	if c.App.TestMode.Enabled {
+		if _, err := time.Parse(time.RFC3339, c.App.TestMode.StartTime); err != nil {
+			addf("app.test_mode.start_time 不是RFC3339格式: %q", c.App.TestMode.StartTime)
+		}
+	}
+
+	// 代理
+	if c.Proxy.Enabled {
+		if c.Proxy.URL == "" {
+			addf("proxy.enabled=true 但未设置 proxy.url")
+		} else if err := validateProxyURL(c.Proxy.URL); err != nil {
+			addf("proxy.url 无效: %v", err)
+		}
+	}
+	if c.Proxy.ProbeTimeout < 0 {
+		addf("proxy.probe_timeout 不能为负数: %s", c.Proxy.ProbeTimeout)
+	}
+
+	// Redis
+	if c.Redis.Addr == "" {
+		addf("redis.addr 不能为空")
+	}
+	if c.Redis.PoolSize < 0 {
+		addf("redis.pool_size 不能为负数: %d", c.Redis.PoolSize)
+	}
+	// 前缀会出现在 SCAN 的匹配模式中，不能包含通配符
+	if strings.ContainsAny(c.Redis.KeyPrefix, "*?[] \t\n") {
+		addf("redis.key_prefix 不能包含空白或通配符 *?[]: %q", c.Redis.KeyPrefix)
+	}
+	for name, workload := range c.Redis.Workloads {
+		if !containsFold(validRedisWorkloads, name) {
+			addf("redis.workloads.%s 不是支持的负载，可选值: %s", name, strings.Join(validRedisWorkloads, ", "))
+		}
+		if workload.PoolSize < 0 {
+			addf("redis.workloads.%s.pool_size 不能为负数: %d", name, workload.PoolSize)
+		}
+		if workload.DB != nil && *workload.DB < 0 {
+			addf("redis.workloads.%s.db 不能为负数: %d", name, *workload.DB)
+		}
+	}
+	switch c.Redis.Degraded.Policy {
+	case "", "buffer", "pause", "crash":
+	default:
+		addf("redis.degraded.policy 无效: %q，可选值: buffer, pause, crash", c.Redis.Degraded.Policy)
+	}
+	if c.Redis.Degraded.BufferSize < 0 {
+		addf("redis.degraded.buffer_size 不能为负数: %d", c.Redis.Degraded.BufferSize)
+	}
+
+	// WebSocket 及 Helius 采集链路
+	if c.WebSocket.NetworkType != "mainnet" && c.WebSocket.NetworkType != "devnet" {
+		addf("websocket.network_type 无效: %q，可选值: mainnet, devnet", c.WebSocket.NetworkType)
+	}
+	if c.WebSocket.Endpoint != "" {
+		if err := validateURL(c.WebSocket.Endpoint); err != nil {
+			addf("websocket.endpoint 无效: %v", err)
+		}
+	}
+	if c.WebSocket.ReadLimit < 0 || c.WebSocket.ReadBufferSize < 0 || c.WebSocket.WriteBufferSize < 0 {
+		addf("websocket.read_limit、read_buffer_size、write_buffer_size 不能为负数")
+	}
+	if c.WebSocket.BlockChunkSize < 0 {
+		addf("websocket.block_chunk_size 不能为负数: %d", c.WebSocket.BlockChunkSize)
+	}
+	if c.WebSocket.RequestTimeout < 0 {
+		addf("websocket.request_timeout 不能为负数: %s", c.WebSocket.RequestTimeout)
+	}
+	if c.WebSocket.PingInterval <= 0 {
+		addf("websocket.ping_interval 必须大于0: %s", c.WebSocket.PingInterval)
+	}
+	if c.WebSocket.LivenessTimeout < 0 {
+		addf("websocket.liveness_timeout 不能为负数: %s", c.WebSocket.LivenessTimeout)
+	} else if c.WebSocket.LivenessTimeout > 0 && c.WebSocket.LivenessTimeout <= c.WebSocket.PingInterval {
+		addf("websocket.liveness_timeout(%s) 必须大于 websocket.ping_interval(%s)，否则两次ping之间就会判定连接失效", c.WebSocket.LivenessTimeout, c.WebSocket.PingInterval)
+	}
+	if c.WebSocket.PoolSize < 1 {
+		addf("websocket.pool_size 必须大于0: %d", c.WebSocket.PoolSize)
+	}
+	switch mode := c.WebSocket.IngestionMode; {
+	case mode == "", mode == "slot", mode == "block-all":
+	case strings.HasPrefix(mode, "block-mentions:"):
+		for i, address := range strings.Split(strings.TrimPrefix(mode, "block-mentions:"), ",") {
+			if strings.TrimSpace(address) == "" {
+				addf("websocket.ingestion_mode=block-mentions 的第 %d 个地址为空，格式: block-mentions:<地址>[,<地址>...]", i+1)
+			}
+		}
+	default:
+		addf("websocket.ingestion_mode 无效: %q，可选值: slot, block-all, block-mentions:<地址>[,<地址>...]", mode)
+	}
+	if c.WebSocket.BlockCommitment != "" && !containsFold([]string{"confirmed", "finalized"}, c.WebSocket.BlockCommitment) {
+		addf("websocket.block_commitment 无效: %q，可选值: confirmed, finalized", c.WebSocket.BlockCommitment)
+	}
+	if c.WebSocket.BlockTransactionDetails != "" && !containsFold([]string{"full", "none"}, c.WebSocket.BlockTransactionDetails) {
+		addf("websocket.block_transaction_details 无效: %q，可选值: full, none", c.WebSocket.BlockTransactionDetails)
+	}
+	if c.WebSocket.SlotConfirmationDepth < 0 {
+		addf("websocket.slot_confirmation_depth 不能为负数: %d", c.WebSocket.SlotConfirmationDepth)
+	}
+	if c.WebSocket.Enabled {
+		if c.WebSocket.APIKey == "" {
+			addf("websocket.enabled=true 但未设置 websocket.api_key")
+		}
+		if c.HeliusAPI.APIKey == "" {
+			addf("websocket.enabled=true 但未设置 helius_api.api_key，无法获取区块数据")
+		}
+		if c.HeliusAPI.Endpoint == "" {
+			addf("websocket.enabled=true 但未设置 helius_api.endpoint")
+		}
+		if len(c.HeliusEnhancedAPI.APIKeys) == 0 {
+			addf("websocket.enabled=true 但 helius_enhanced_api.api_keys 为空，无法解析交易")
+		}
+		for i, apiKey := range c.HeliusEnhancedAPI.APIKeys {
+			if strings.TrimSpace(apiKey) == "" {
+				addf("helius_enhanced_api.api_keys[%d] 为空", i)
+			}
+		}
+		if c.HeliusEnhancedAPI.Endpoint == "" {
+			addf("websocket.enabled=true 但未设置 helius_enhanced_api.endpoint")
+		}
+	}
+	for name, httpConfig := range map[string]HTTPClientConfig{
+		"helius_api.http":          c.HeliusAPI.HTTP,
+		"helius_enhanced_api.http": c.HeliusEnhancedAPI.HTTP,
+		"helius_webhook.http":      c.HeliusWebhook.HTTP,
+		"jupiter_price.http":       c.JupiterPrice.HTTP,
+		"pump_portal_trade.http":   c.PumpPortalTrade.HTTP,
+		"clickhouse.http":          c.ClickHouse.HTTP,
+	} {
+		for field, duration := range map[string]time.Duration{
+			"timeout":                 httpConfig.Timeout,
+			"dial_timeout":            httpConfig.DialTimeout,
+			"keep_alive":              httpConfig.KeepAlive,
+			"tls_handshake_timeout":   httpConfig.TLSHandshakeTimeout,
+			"response_header_timeout": httpConfig.ResponseHeaderTimeout,
+			"idle_conn_timeout":       httpConfig.IdleConnTimeout,
+		} {
+			if duration < 0 {
+				addf("%s.%s 不能为负数: %s", name, field, duration)
+			}
+		}
+		if httpConfig.MaxIdleConns < 0 || httpConfig.MaxIdleConnsPerHost < 0 || httpConfig.MaxConnsPerHost < 0 {
+			addf("%s 的连接数设置不能为负数", name)
+		}
+	}
+	for _, mode := range c.HeliusEnhancedAPI.Auth.Modes {
+		if !slices.Contains(validAuthModes, mode) {
+			addf("helius_enhanced_api.auth.modes 无效: %q，可选值: %s", mode, strings.Join(validAuthModes, ", "))
+		}
+	}
+	if slices.Contains(c.HeliusEnhancedAPI.Auth.Modes, "query") && c.HeliusEnhancedAPI.Auth.QueryParam == "" {
+		addf("helius_enhanced_api.auth.modes 包含 query 但未设置 helius_enhanced_api.auth.query_param")
+	}
+	if slices.Contains(c.HeliusEnhancedAPI.Auth.Modes, "header") && c.HeliusEnhancedAPI.Auth.Header == "" {
+		addf("helius_enhanced_api.auth.modes 包含 header 但未设置 helius_enhanced_api.auth.header")
+	}
+	switch c.HeliusEnhancedAPI.Commitment {
+	case "", "confirmed", "finalized":
+	default:
+		addf("helius_enhanced_api.commitment 无效: %q，可选值: confirmed, finalized", c.HeliusEnhancedAPI.Commitment)
+	}
+	if len(c.HeliusEnhancedAPI.DailyBudgets) > len(c.HeliusEnhancedAPI.APIKeys) {
+		addf("helius_enhanced_api.daily_budgets 有 %d 项，多于 api_keys 的 %d 个密钥", len(c.HeliusEnhancedAPI.DailyBudgets), len(c.HeliusEnhancedAPI.APIKeys))
+	}
+	for i, budget := range c.HeliusEnhancedAPI.DailyBudgets {
+		if budget < 0 {
+			addf("helius_enhanced_api.daily_budgets[%d] 不能为负数: %d", i, budget)
+		}
+	}
+	if c.HeliusEnhancedAPI.UsageRetention < 0 {
+		addf("helius_enhanced_api.usage_retention 不能为负数: %s", c.HeliusEnhancedAPI.UsageRetention)
+	}
+	if quarantine := c.HeliusEnhancedAPI.Quarantine; quarantine.Enabled {
+		if quarantine.RejectedCooldown <= 0 || quarantine.RateLimitCooldown <= 0 {
+			addf("helius_enhanced_api.quarantine 的 rejected_cooldown 和 rate_limit_cooldown 必须大于0")
+		}
+		if quarantine.RateLimitThreshold < 1 {
+			addf("helius_enhanced_api.quarantine.rate_limit_threshold 必须大于0: %d", quarantine.RateLimitThreshold)
+		}
+		if quarantine.MinHealthyKeys < 0 {
+			addf("helius_enhanced_api.quarantine.min_healthy_keys 不能为负数: %d", quarantine.MinHealthyKeys)
+		}
+	}
+	if c.HeliusAPI.BatchSize < 0 || c.HeliusAPI.BatchSize > maxHeliusBatchSize {
+		addf("helius_api.batch_size 必须在0到%d之间: %d", maxHeliusBatchSize, c.HeliusAPI.BatchSize)
+	}
+	for name, proxyURL := range map[string]string{
+		"websocket.proxy_url":           c.WebSocket.ProxyURL,
+		"helius_api.proxy_url":          c.HeliusAPI.ProxyURL,
+		"helius_enhanced_api.proxy_url": c.HeliusEnhancedAPI.ProxyURL,
+		"pump_portal.proxy_url":         c.PumpPortal.ProxyURL,
+		"helius_webhook.proxy_url":      c.HeliusWebhook.ProxyURL,
+		"helius_webhook.endpoint":       c.HeliusWebhook.Endpoint,
+		"helius_webhook.callback_url":   c.HeliusWebhook.CallbackURL,
+		"jupiter_price.proxy_url":       c.JupiterPrice.ProxyURL,
+		"jupiter_price.endpoint":        c.JupiterPrice.Endpoint,
+		"pump_portal_trade.proxy_url":   c.PumpPortalTrade.ProxyURL,
+		"pump_portal_trade.endpoint":    c.PumpPortalTrade.Endpoint,
+		"clickhouse.proxy_url":          c.ClickHouse.ProxyURL,
+	} {
+		if proxyURL == "" {
+			continue
+		}
+		validate := validateURL
+		if strings.HasSuffix(name, ".proxy_url") {
+			validate = validateProxyURL
+		}
+		if err := validate(proxyURL); err != nil {
+			addf("%s 无效: %v", name, err)
+		}
+	}
+
+	// 区块预取
+	if c.BlockPrefetch.Enabled {
+		if c.BlockPrefetch.Lookahead <= 0 {
+			addf("block_prefetch.lookahead 必须大于0: %d", c.BlockPrefetch.Lookahead)
+		}
+		if c.BlockPrefetch.Concurrency <= 0 {
+			addf("block_prefetch.concurrency 必须大于0: %d", c.BlockPrefetch.Concurrency)
+		}
+		if c.BlockPrefetch.MinInterval < 0 {
+			addf("block_prefetch.min_interval 不能为负数: %s", c.BlockPrefetch.MinInterval)
+		}
+		if c.BlockPrefetch.PollInterval <= 0 {
+			addf("block_prefetch.poll_interval 必须大于0: %s", c.BlockPrefetch.PollInterval)
+		}
+		if c.BlockPrefetch.TTL <= 0 {
+			addf("block_prefetch.ttl 必须大于0: %s", c.BlockPrefetch.TTL)
+		}
+	}
+
+	// PumpPortal
+	if c.PumpPortal.ReconnectDelay < 0 {
+		addf("pump_portal.reconnect_delay 不能为负数: %s", c.PumpPortal.ReconnectDelay)
+	}
+
+	// PumpPortal交易
+	if trade := c.PumpPortalTrade; trade.Enabled {
+		switch trade.Mode {
+		case "lightning":
+			if trade.APIKey == "" && !trade.DryRun {
+				addf("pump_portal_trade.mode=lightning 但未设置 pump_portal_trade.api_key")
+			}
+		case "local":
+			if trade.PrivateKey == "" {
+				addf("pump_portal_trade.mode=local 但未设置 pump_portal_trade.private_key")
+			}
+			if c.HeliusAPI.APIKey == "" && !trade.DryRun {
+				addf("pump_portal_trade.mode=local 通过 helius_api 发送交易，但未设置 helius_api.api_key")
+			}
+		default:
+			addf("pump_portal_trade.mode 无效: %q，可选值: lightning, local", trade.Mode)
+		}
+		if trade.Endpoint == "" {
+			addf("pump_portal_trade.enabled=true 但未设置 pump_portal_trade.endpoint")
+		}
+		if trade.Slippage < 0 || trade.Slippage > 100 {
+			addf("pump_portal_trade.slippage 必须在0到100之间: %v", trade.Slippage)
+		}
+		if trade.PriorityFee < 0 {
+			addf("pump_portal_trade.priority_fee 不能为负数: %v", trade.PriorityFee)
+		}
+		if trade.MaxSOLPerTrade <= 0 {
+			addf("pump_portal_trade.max_sol_per_trade 必须大于0: %v", trade.MaxSOLPerTrade)
+		}
+		if trade.MaxTradesPerHour <= 0 {
+			addf("pump_portal_trade.max_trades_per_hour 必须大于0: %d", trade.MaxTradesPerHour)
+		}
+		if trade.MintCooldown < 0 {
+			addf("pump_portal_trade.mint_cooldown 不能为负数: %s", trade.MintCooldown)
+		}
+		for i, id := range trade.RuleIDs {
+			if strings.TrimSpace(id) == "" {
+				addf("pump_portal_trade.rule_ids[%d] 不能为空", i)
+			}
+		}
+	}
+
+	// Jupiter价格API
+	if c.JupiterPrice.Enabled {
+		if c.JupiterPrice.Endpoint == "" {
+			addf("jupiter_price.enabled=true 但未设置 jupiter_price.endpoint")
+		}
+		if c.JupiterPrice.CacheTTL < 0 || c.JupiterPrice.MinInterval < 0 {
+			addf("jupiter_price.cache_ttl 和 jupiter_price.min_interval 不能为负数")
+		}
+		if c.JupiterPrice.BatchSize <= 0 {
+			addf("jupiter_price.batch_size 必须大于0: %d", c.JupiterPrice.BatchSize)
+		}
+	}
+
+	// 钱包历史上下文
+	if c.WalletContext.Enabled {
+		if c.WalletContext.HistoryLimit <= 0 {
+			addf("wallet_context.history_limit 必须大于0: %d", c.WalletContext.HistoryLimit)
+		}
+		if c.WalletContext.CacheTTL <= 0 {
+			addf("wallet_context.cache_ttl 必须大于0: %s", c.WalletContext.CacheTTL)
+		}
+		if c.WalletContext.Timeout <= 0 {
+			addf("wallet_context.timeout 必须大于0: %s", c.WalletContext.Timeout)
+		}
+		if len(c.HeliusEnhancedAPI.APIKeys) == 0 {
+			addf("wallet_context.enabled=true 但未设置 helius_enhanced_api.api_keys")
+		}
+	}
+
+	// Helius Webhook
+	webhookURLs := make(map[string]int)
+	for i, webhook := range c.HeliusWebhook.Webhooks {
+		webhookURL := webhook.URL
+		if webhookURL == "" {
+			webhookURL = c.HeliusWebhook.CallbackURL
+		}
+		if webhookURL == "" {
+			addf("helius_webhook.webhooks[%d].url 为空且未设置 helius_webhook.callback_url", i)
+		} else if j, ok := webhookURLs[webhookURL]; ok {
+			addf("helius_webhook.webhooks[%d] 与 webhooks[%d] 的回调URL重复: %s", i, j, webhookURL)
+		} else {
+			webhookURLs[webhookURL] = i
+		}
+		if webhook.Type != "" && !slices.Contains(validWebhookTypes, webhook.Type) {
+			addf("helius_webhook.webhooks[%d].type 无效: %q，可选值: %s", i, webhook.Type, strings.Join(validWebhookTypes, ", "))
+		}
+		if len(webhook.Addresses) == 0 {
+			addf("helius_webhook.webhooks[%d].addresses 不能为空", i)
+		} else if len(webhook.Addresses) > maxWebhookAddresses {
+			addf("helius_webhook.webhooks[%d].addresses 超过Helius上限: %d > %d", i, len(webhook.Addresses), maxWebhookAddresses)
+		}
+	}
+	if c.HeliusWebhook.MaxAttempts < 1 {
+		addf("helius_webhook.max_attempts 必须大于0")
+	}
+	if c.HeliusWebhook.RetryBackoff < 0 || c.HeliusWebhook.MaxRetryBackoff < 0 {
+		addf("helius_webhook.retry_backoff 和 max_retry_backoff 不能为负数")
+	}
+	if c.HeliusWebhook.Sync && c.HeliusWebhook.APIKey == "" {
+		addf("helius_webhook.sync=true 但未设置 helius_webhook.api_key")
+	}
+	if receiver := c.HeliusWebhook.Receiver; receiver.Enabled {
+		if receiver.Addr == "" {
+			addf("helius_webhook.receiver.enabled=true 但未设置 helius_webhook.receiver.addr")
+		}
+		if !strings.HasPrefix(receiver.Path, "/") {
+			addf("helius_webhook.receiver.path 必须以 / 开头: %q", receiver.Path)
+		}
+		if receiver.MaxBodyBytes <= 0 {
+			addf("helius_webhook.receiver.max_body_bytes 必须大于0")
+		}
+		if receiver.DedupeWindow < 0 {
+			addf("helius_webhook.receiver.dedupe_window 不能为负数: %s", receiver.DedupeWindow)
+		}
+		if receiver.HMACSecret != "" && receiver.HMACHeader == "" {
+			addf("helius_webhook.receiver.hmac_secret 已设置但 hmac_header 为空")
+		}
+		for i, allowed := range receiver.AllowedIPs {
+			if _, err := netip.ParsePrefix(allowed); err == nil {
+				continue
+			}
+			if _, err := netip.ParseAddr(allowed); err != nil {
+				addf("helius_webhook.receiver.allowed_ips[%d] 不是有效的IP或CIDR: %q", i, allowed)
+			}
+		}
+	}
+
+	// 原始响应归档
+	if c.RawArchive.TTL < 0 {
+		addf("raw_archive.ttl 不能为负数: %s", c.RawArchive.TTL)
+	}
+	if c.RawArchive.Enabled && c.RawArchive.Dir != "" {
+		if c.RawArchive.FileSlots == 0 {
+			addf("raw_archive.file_slots 必须大于0")
+		}
+		if c.RawArchive.FileMaxAge <= 0 {
+			addf("raw_archive.file_max_age 必须大于0: %s", c.RawArchive.FileMaxAge)
+		}
+	}
+
+	// 管理接口
+	if c.Admin.Enabled && c.Admin.Addr == "" {
+		addf("admin.enabled=true 但未设置 admin.addr")
+	}
+	if c.Admin.Enabled && c.Admin.MaxBodyBytes <= 0 {
+		addf("admin.max_body_bytes 必须大于0: %d", c.Admin.MaxBodyBytes)
+	}
+
+	// 解析并发
+	if c.Parser.MaxConcurrentBatches < 0 {
+		addf("parser.max_concurrent_batches 不能为负数: %d", c.Parser.MaxConcurrentBatches)
+	}
+	if c.Parser.KeyRPS < 0 {
+		addf("parser.key_rps 不能为负数: %g", c.Parser.KeyRPS)
+	}
+
+	// 队列
+	if c.Queue.BlockMaxAge < 0 {
+		addf("queue.block_max_age 不能为负数: %s", c.Queue.BlockMaxAge)
+	}
+	if c.Queue.TransactionMaxAge < 0 {
+		addf("queue.transaction_max_age 不能为负数: %s", c.Queue.TransactionMaxAge)
+	}
+	switch c.Queue.TransactionScheduling {
+	case "", "block":
+	case "interleaved":
+		if c.Queue.InterleaveBlocks <= 0 {
+			addf("queue.interleave_blocks 必须大于0: %d", c.Queue.InterleaveBlocks)
+		}
+		if c.Queue.MaxConcurrentBatches <= 0 {
+			addf("queue.max_concurrent_batches 必须大于0: %d", c.Queue.MaxConcurrentBatches)
+		}
+	default:
+		addf("queue.transaction_scheduling 无效: %q，可选值: block, interleaved", c.Queue.TransactionScheduling)
+	}
+	switch c.Queue.Backend {
+	case "", "memory":
+	case "redis-stream":
+		if c.Queue.Stream.Group == "" {
+			addf("queue.stream.group 不能为空")
+		}
+		if c.Queue.Stream.ClaimIdle <= 0 {
+			addf("queue.stream.claim_idle 必须大于0: %s", c.Queue.Stream.ClaimIdle)
+		}
+		if c.Queue.Stream.ClaimInterval <= 0 {
+			addf("queue.stream.claim_interval 必须大于0: %s", c.Queue.Stream.ClaimInterval)
+		}
+		if c.Queue.Stream.MaxLen < 0 {
+			addf("queue.stream.max_len 不能为负数: %d", c.Queue.Stream.MaxLen)
+		}
+	default:
+		addf("queue.backend 无效: %q，可选值: memory, redis-stream", c.Queue.Backend)
+	}
+	if c.Queue.Lanes.Enabled {
+		if c.Queue.Lanes.LiveWindow <= 0 {
+			addf("queue.lanes.live_window 必须大于0: %d", c.Queue.Lanes.LiveWindow)
+		}
+		if c.Queue.Lanes.LiveWeight <= 0 || c.Queue.Lanes.BackfillWeight <= 0 {
+			addf("queue.lanes.live_weight 和 backfill_weight 必须大于0: %d, %d", c.Queue.Lanes.LiveWeight, c.Queue.Lanes.BackfillWeight)
+		}
+	}
+
+	// 分布式处理
+	if c.Distributed.Enabled || c.Distributed.LeaderElection.Enabled || c.Jobs.Enabled {
+		if strings.ContainsAny(c.Distributed.InstanceID, " \t\n") || c.Distributed.InstanceID == "done" {
+			addf("distributed.instance_id 无效: %q，不能包含空白，也不能为 done", c.Distributed.InstanceID)
+		}
+	}
+	if election := c.Distributed.LeaderElection; election.Enabled {
+		if election.RenewInterval <= 0 {
+			addf("distributed.leader_election.renew_interval 必须大于0: %s", election.RenewInterval)
+		}
+		if election.TTL <= election.RenewInterval {
+			addf("distributed.leader_election.ttl(%s) 必须大于 renew_interval(%s)，否则领导者会在续期前失去租期", election.TTL, election.RenewInterval)
+		}
+	}
+	if c.Distributed.Enabled {
+		if c.Distributed.HeartbeatInterval <= 0 {
+			addf("distributed.heartbeat_interval 必须大于0: %s", c.Distributed.HeartbeatInterval)
+		}
+		if c.Distributed.LeaseTTL <= c.Distributed.HeartbeatInterval {
+			addf("distributed.lease_ttl(%s) 必须大于 heartbeat_interval(%s)，否则租约会在续期前过期", c.Distributed.LeaseTTL, c.Distributed.HeartbeatInterval)
+		}
+		if c.Distributed.InstanceTTL <= c.Distributed.HeartbeatInterval {
+			addf("distributed.instance_ttl(%s) 必须大于 heartbeat_interval(%s)，否则存活的实例会被判定为失效", c.Distributed.InstanceTTL, c.Distributed.HeartbeatInterval)
+		}
+		if c.Distributed.DoneTTL <= 0 {
+			addf("distributed.done_ttl 必须大于0: %s", c.Distributed.DoneTTL)
+		}
+	}
+
+	// 定时任务
+	if c.Jobs.Enabled {
+		if c.Jobs.History <= 0 {
+			addf("jobs.history 必须大于0: %d", c.Jobs.History)
+		}
+		if c.Jobs.Timeout <= 0 {
+			addf("jobs.timeout 必须大于0: %s", c.Jobs.Timeout)
+		}
+		jobNames := make(map[string]int)
+		for i, job := range c.Jobs.Jobs {
+			if job.Name == "" {
+				addf("jobs.jobs[%d].name 不能为空", i)
+			} else if j, ok := jobNames[job.Name]; ok {
+				addf("jobs.jobs[%d] 与 jobs[%d] 的任务名称重复: %s", i, j, job.Name)
+			} else {
+				jobNames[job.Name] = i
+			}
+			if job.Schedule != "" {
+				if _, err := cron.Parse(job.Schedule); err != nil {
+					addf("jobs.jobs[%d].schedule 无效: %v", i, err)
+				}
+			}
+			if job.Timeout < 0 {
+				addf("jobs.jobs[%d].timeout 不能为负数: %s", i, job.Timeout)
+			}
+		}
+	}
+
+	// Redis数据保留
+	if c.Retention.BatchSize <= 0 {
+		addf("retention.batch_size 必须大于0: %d", c.Retention.BatchSize)
+	}
+	if c.Retention.Samples < 0 {
+		addf("retention.samples 不能为负数: %d", c.Retention.Samples)
+	}
+	for i, policy := range c.Retention.Policies {
+		if policy.Name == "" {
+			addf("retention.policies[%d].name 不能为空", i)
+		}
+		if policy.Pattern == "" {
+			addf("retention.policies[%d].pattern 不能为空", i)
+		}
+		switch policy.Action {
+		case "expire", "delete":
+			if policy.MaxAge <= 0 {
+				addf("retention.policies[%d].max_age 必须大于0: %s", i, policy.MaxAge)
+			}
+		case "trim":
+			if policy.MaxLen <= 0 {
+				addf("retention.policies[%d].max_len 必须大于0: %d", i, policy.MaxLen)
+			}
+		default:
+			addf("retention.policies[%d].action 必须是 expire、delete 或 trim: %q", i, policy.Action)
+		}
+	}
+
+	// 协程panic重启
+	if c.Supervisor.InitialBackoff <= 0 {
+		addf("supervisor.initial_backoff 必须大于0: %s", c.Supervisor.InitialBackoff)
+	}
+	if c.Supervisor.MaxBackoff < c.Supervisor.InitialBackoff {
+		addf("supervisor.max_backoff(%s) 不能小于 initial_backoff(%s)", c.Supervisor.MaxBackoff, c.Supervisor.InitialBackoff)
+	}
+
+	// 进程内事件订阅
+	if c.Pipeline.SubscriberBuffer <= 0 {
+		addf("pipeline.subscriber_buffer 必须大于0: %d", c.Pipeline.SubscriberBuffer)
+	}
+	for _, stage := range c.Pipeline.Stages.Disabled {
+		if !slices.Contains(validStages, stage) {
+			addf("pipeline.stages.disabled 包含未知的阶段: %q，可选值: %s", stage, strings.Join(validStages, ", "))
+		}
+	}
+	if c.Pipeline.Stages.StartRetries < 0 {
+		addf("pipeline.stages.start_retries 不能为负数: %d", c.Pipeline.Stages.StartRetries)
+	}
+	if c.Pipeline.Stages.RetryInterval < 0 || c.Pipeline.Stages.StartTimeout < 0 {
+		addf("pipeline.stages.retry_interval 和 start_timeout 不能为负数")
+	}
+
+	// Enhanced API解析结果缓存
+	if c.EnrichmentCache.TTL < 0 {
+		addf("enrichment_cache.ttl 不能为负数: %s", c.EnrichmentCache.TTL)
+	}
+
+	// 不再解析缓存
+	if c.NegativeCache.Enabled && c.NegativeCache.MaxAttempts <= 0 {
+		addf("negative_cache.max_attempts 必须大于0: %d", c.NegativeCache.MaxAttempts)
+	}
+	if c.NegativeCache.TTL < 0 {
+		addf("negative_cache.ttl 不能为负数: %s", c.NegativeCache.TTL)
+	}
+
+	// 交易索引
+	if c.TransactionIndex.TTL < 0 {
+		addf("transaction_index.ttl 不能为负数: %s", c.TransactionIndex.TTL)
+	}
+	for transactionType, ttl := range c.TransactionIndex.TypeTTL {
+		if ttl < 0 {
+			addf("transaction_index.type_ttl.%s 不能为负数: %s", transactionType, ttl)
+		}
+	}
+	if c.TransactionIndex.MaxPerKey < 0 {
+		addf("transaction_index.max_per_key 不能为负数: %d", c.TransactionIndex.MaxPerKey)
+	}
+	if c.TransactionIndex.CleanupInterval < 0 {
+		addf("transaction_index.cleanup_interval 不能为负数: %s", c.TransactionIndex.CleanupInterval)
+	}
+
+	// 区块哈希索引
+	if c.BlockIndex.TTL < 0 {
+		addf("block_index.ttl 不能为负数: %s", c.BlockIndex.TTL)
+	}
+
+	// 区块交易汇总
+	if c.BlockSummary.MaxEntries < 0 {
+		addf("block_summary.max_entries 不能为负数: %d", c.BlockSummary.MaxEntries)
+	}
+
+	// 区块交易预过滤
+	if c.BlockFilter.Enabled && len(c.BlockFilter.Programs) == 0 && len(c.BlockFilter.Accounts) == 0 {
+		addf("block_filter.enabled=true 但 programs 和 accounts 都为空，所有交易都会被过滤")
+	}
+	for i, program := range c.BlockFilter.Programs {
+		if strings.TrimSpace(program) == "" {
+			addf("block_filter.programs[%d] 不能为空", i)
+		}
+	}
+	for i, account := range c.BlockFilter.Accounts {
+		if strings.TrimSpace(account) == "" {
+			addf("block_filter.accounts[%d] 不能为空", i)
+		}
+	}
+
+	// 本地解析
+	for i, program := range c.RawParse.Programs {
+		if strings.TrimSpace(program) == "" {
+			addf("raw_parse.programs[%d] 不能为空", i)
+		}
+	}
+
+	// 规则引擎
+	if c.Rules.AlertHistory < 0 {
+		addf("rules.alert_history 不能为负数: %d", c.Rules.AlertHistory)
+	}
+	if c.Rules.TradeQueueSize <= 0 {
+		addf("rules.trade_queue_size 必须大于0: %d", c.Rules.TradeQueueSize)
+	}
+
+	// 关注地址同步
+	if c.Watchlist.WebhookID != "" && c.HeliusWebhook.APIKey == "" {
+		addf("watchlist.webhook_id 已设置但未设置 helius_webhook.api_key")
+	}
+	if c.Watchlist.SyncInterval <= 0 {
+		addf("watchlist.sync_interval 必须大于0: %s", c.Watchlist.SyncInterval)
+	}
+
+	// 买卖盘失衡统计
+	if c.OrderFlow.Enabled {
+		if c.OrderFlow.Window <= 0 {
+			addf("order_flow.window 必须大于0: %s", c.OrderFlow.Window)
+		}
+		if c.OrderFlow.Interval <= 0 {
+			addf("order_flow.interval 必须大于0: %s", c.OrderFlow.Interval)
+		}
+		if c.OrderFlow.Retention < 0 {
+			addf("order_flow.retention 不能为负数: %s", c.OrderFlow.Retention)
+		}
+	}
+
+	// 代币账户创建/关闭统计
+	if c.TokenAccounts.Enabled {
+		if c.TokenAccounts.Interval <= 0 {
+			addf("token_accounts.interval 必须大于0: %s", c.TokenAccounts.Interval)
+		}
+		if c.TokenAccounts.Retention < 0 {
+			addf("token_accounts.retention 不能为负数: %s", c.TokenAccounts.Retention)
+		}
+	}
+
+	// 按交易来源统计
+	if c.SourceVolume.Enabled {
+		if c.SourceVolume.FlushInterval <= 0 {
+			addf("source_volume.flush_interval 必须大于0: %s", c.SourceVolume.FlushInterval)
+		}
+		if c.SourceVolume.Retention < 0 {
+			addf("source_volume.retention 不能为负数: %s", c.SourceVolume.Retention)
+		}
+	}
+
+	// 代币24小时统计
+	if c.TokenStats.Enabled && c.TokenStats.MaxMints <= 0 {
+		addf("token_stats.max_mints 必须大于0: %d", c.TokenStats.MaxMints)
+	}
+
+	// 联合曲线进度统计
+	if c.BondingCurve.Enabled {
+		if c.BondingCurve.AlertProgress <= 0 || c.BondingCurve.AlertProgress > 1 {
+			addf("bonding_curve.alert_progress 必须大于0且不超过1: %v", c.BondingCurve.AlertProgress)
+		}
+		if c.BondingCurve.MaxMints <= 0 {
+			addf("bonding_curve.max_mints 必须大于0: %d", c.BondingCurve.MaxMints)
+		}
+	}
+
+	// 新代币风险检测
+	if c.TokenRisk.Enabled {
+		if c.TokenRisk.Delay < 0 || c.TokenRisk.TTL < 0 {
+			addf("token_risk.delay 和 token_risk.ttl 不能为负数")
+		}
+		if c.TokenRisk.Timeout <= 0 {
+			addf("token_risk.timeout 必须大于0: %s", c.TokenRisk.Timeout)
+		}
+		if c.TokenRisk.QueueSize <= 0 {
+			addf("token_risk.queue_size 必须大于0: %d", c.TokenRisk.QueueSize)
+		}
+		if c.TokenRisk.MaxHolderAccounts <= 0 {
+			addf("token_risk.max_holder_accounts 必须大于0: %d", c.TokenRisk.MaxHolderAccounts)
+		}
+		for name, share := range map[string]float64{
+			"top_holder_share": c.TokenRisk.TopHolderShare,
+			"top10_share":      c.TokenRisk.Top10Share,
+		} {
+			if share <= 0 || share > 1 {
+				addf("token_risk.%s 必须大于0且不超过1: %v", name, share)
+			}
+		}
+	}
+
+	// 持仓统计
+	if c.Positions.Enabled {
+		if c.Positions.FlushInterval <= 0 {
+			addf("positions.flush_interval 必须大于0: %s", c.Positions.FlushInterval)
+		}
+		if c.Positions.PositionTTL < 0 {
+			addf("positions.position_ttl 不能为负数: %s", c.Positions.PositionTTL)
+		}
+		if c.Positions.Retention < 0 {
+			addf("positions.retention 不能为负数: %s", c.Positions.Retention)
+		}
+	}
+
+	// 计算单元价格统计
+	if c.PriorityFee.Enabled && c.PriorityFee.WindowBlocks <= 0 {
+		addf("priority_fee.window_blocks 必须大于0: %d", c.PriorityFee.WindowBlocks)
+	}
+
+	// 按程序统计手续费
+	if c.ProgramFees.Enabled {
+		if c.ProgramFees.FlushInterval <= 0 {
+			addf("program_fees.flush_interval 必须大于0: %s", c.ProgramFees.FlushInterval)
+		}
+		if c.ProgramFees.Retention < 0 {
+			addf("program_fees.retention 不能为负数: %s", c.ProgramFees.Retention)
+		}
+	}
+
+	// 容量规划指标快照
+	if c.Capacity.Enabled {
+		if c.Capacity.Interval <= 0 {
+			addf("capacity.interval 必须大于0: %s", c.Capacity.Interval)
+		}
+		if c.Capacity.Retention < 0 {
+			addf("capacity.retention 不能为负数: %s", c.Capacity.Retention)
+		}
+		if c.Capacity.RPCRequestCredits < 0 || c.Capacity.EnhancedRequestCredits < 0 || c.Capacity.CreditsPerAPIKey < 0 {
+			addf("capacity 的额度配置不能为负数")
+		}
+	}
+
+	// 健康检查
+	if c.Health.MaxMessageAge < 0 || c.Health.MaxQueueStaleness < 0 {
+		addf("health.max_message_age 和 health.max_queue_staleness 不能为负数")
+	}
+
+	// 独立解析服务
+	if c.ParseServer.MaxSignatures <= 0 || c.ParseServer.MaxTransactions <= 0 || c.ParseServer.MaxBodyBytes <= 0 {
+		addf("parse_server.max_signatures、max_transactions 和 max_body_bytes 必须大于0")
+	}
+	if c.ParseServer.Timeout <= 0 {
+		addf("parse_server.timeout 必须大于0: %s", c.ParseServer.Timeout)
+	}
+
+	// 解析结果抽样校验
+	if c.Verification.Enabled {
+		if c.Verification.SampleRate <= 0 || c.Verification.SampleRate > 1 {
+			addf("verification.sample_rate 必须在0到1之间: %v", c.Verification.SampleRate)
+		}
+		if c.Verification.MaxPending <= 0 {
+			addf("verification.max_pending 必须大于0: %d", c.Verification.MaxPending)
+		}
+		if c.Verification.PendingTTL <= 0 || c.Verification.ReportInterval <= 0 {
+			addf("verification.pending_ttl 和 verification.report_interval 必须大于0")
+		}
+		if c.Verification.AlertThreshold < 0 || c.Verification.AlertThreshold > 1 {
+			addf("verification.alert_threshold 必须在0到1之间: %v", c.Verification.AlertThreshold)
+		}
+	}
+
+	// 解析失败的原始数据抽样
+	if c.PayloadSamples.Enabled {
+		if c.PayloadSamples.MaxGroups <= 0 || c.PayloadSamples.SamplesPerGroup <= 0 || c.PayloadSamples.MaxPayloadBytes <= 0 {
+			addf("payload_samples.max_groups、samples_per_group 和 max_payload_bytes 必须大于0")
+		}
+		if c.PayloadSamples.LogInterval < 0 {
+			addf("payload_samples.log_interval 不能为负数: %s", c.PayloadSamples.LogInterval)
+		}
+	}
+
+	// 出块停滞检测
+	if c.StallDetection.Enabled {
+		if c.StallDetection.Threshold <= 0 {
+			addf("stall_detection.threshold 必须大于0: %s", c.StallDetection.Threshold)
+		}
+		if c.StallDetection.CheckInterval <= 0 {
+			addf("stall_detection.check_interval 必须大于0: %s", c.StallDetection.CheckInterval)
+		}
+		if c.StallDetection.ProbeTimeout <= 0 {
+			addf("stall_detection.probe_timeout 必须大于0: %s", c.StallDetection.ProbeTimeout)
+		}
+		if c.HeliusAPI.Endpoint == "" {
+			addf("stall_detection.enabled=true 但未设置 helius_api.endpoint，无法通过getSlot探测")
+		}
+	}
+
+	// 订阅静默检测
+	if c.SubscriptionWatchdog.Enabled {
+		if c.SubscriptionWatchdog.CheckInterval <= 0 {
+			addf("subscription_watchdog.check_interval 必须大于0: %s", c.SubscriptionWatchdog.CheckInterval)
+		}
+		if len(c.SubscriptionWatchdog.MaxSilence) == 0 {
+			addf("subscription_watchdog.enabled=true 但 max_silence 为空，不会检查任何订阅")
+		}
+		for _, kind := range slices.Sorted(maps.Keys(c.SubscriptionWatchdog.MaxSilence)) {
+			if silence := c.SubscriptionWatchdog.MaxSilence[kind]; silence <= 0 {
+				addf("subscription_watchdog.max_silence.%s 必须大于0: %s", kind, silence)
+			}
+		}
+	}
+
+	// ClickHouse写入
+	if c.ClickHouse.Enabled {
+		if c.ClickHouse.Endpoint == "" {
+			addf("clickhouse.endpoint 不能为空")
+		} else if u, err := url.Parse(c.ClickHouse.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addf("clickhouse.endpoint 无效: %q，应为 http(s)://host:port", c.ClickHouse.Endpoint)
+		}
+		for _, identifier := range [][2]string{
+			{"database", c.ClickHouse.Database},
+			{"transactions_table", c.ClickHouse.TransactionsTable},
+			{"blocks_table", c.ClickHouse.BlocksTable},
+		} {
+			if !clickHouseIdentifier.MatchString(identifier[1]) {
+				addf("clickhouse.%s 无效: %q，只能包含字母、数字和下划线，且不能以数字开头", identifier[0], identifier[1])
+			}
+		}
+		if c.ClickHouse.TTL < 0 {
+			addf("clickhouse.ttl 不能为负数: %s", c.ClickHouse.TTL)
+		}
+		if c.ClickHouse.BatchSize <= 0 {
+			addf("clickhouse.batch_size 必须大于0: %d", c.ClickHouse.BatchSize)
+		}
+		if c.ClickHouse.FlushInterval <= 0 {
+			addf("clickhouse.flush_interval 必须大于0: %s", c.ClickHouse.FlushInterval)
+		}
+		if c.ClickHouse.MaxBuffered < c.ClickHouse.BatchSize {
+			addf("clickhouse.max_buffered 不能小于 batch_size: %d < %d", c.ClickHouse.MaxBuffered, c.ClickHouse.BatchSize)
+		}
+	}
+
+	// 流动性池创建监控
+	if c.PoolWatcher.Enabled {
+		if len(c.PoolWatcher.Programs) == 0 {
+			addf("pool_watcher.programs 不能为空")
+		}
+		if c.PoolWatcher.Commitment != "confirmed" && c.PoolWatcher.Commitment != "finalized" {
+			addf("pool_watcher.commitment 无效: %q，可选值: confirmed, finalized", c.PoolWatcher.Commitment)
+		}
+		if c.PoolWatcher.CheckInterval <= 0 {
+			addf("pool_watcher.check_interval 必须大于0: %s", c.PoolWatcher.CheckInterval)
+		}
+		if c.PoolWatcher.FetchAttempts <= 0 {
+			addf("pool_watcher.fetch_attempts 必须大于0: %d", c.PoolWatcher.FetchAttempts)
+		}
+		if c.PoolWatcher.MaxHistory < 0 {
+			addf("pool_watcher.max_history 不能为负数: %d", c.PoolWatcher.MaxHistory)
+		}
+		if c.HeliusAPI.Endpoint == "" {
+			addf("pool_watcher.enabled=true 但未设置 helius_api.endpoint，无法通过getTransaction获取交易")
+		}
+	}
+
+	// 最终确认检查
+	if c.Finality.Enabled {
+		if c.Finality.Delay <= 0 {
+			addf("finality.delay 必须大于0: %s", c.Finality.Delay)
+		}
+		if c.Finality.CheckInterval <= 0 {
+			addf("finality.check_interval 必须大于0: %s", c.Finality.CheckInterval)
+		}
+		if c.Finality.MaxAttempts <= 0 {
+			addf("finality.max_attempts 必须大于0: %d", c.Finality.MaxAttempts)
+		}
+		if c.Finality.MaxPending <= 0 {
+			addf("finality.max_pending 必须大于0: %d", c.Finality.MaxPending)
+		}
+		if c.Finality.MaxHistory < 0 {
+			addf("finality.max_history 不能为负数: %d", c.Finality.MaxHistory)
+		}
+		if c.HeliusAPI.Endpoint == "" {
+			addf("finality.enabled=true 但未设置 helius_api.endpoint，无法通过getBlock检查")
+		}
+	}
+
+	// 网络拥堵感知限流
+	if c.Congestion.Enabled {
+		if c.Congestion.WindowBlocks <= 0 {
+			addf("congestion.window_blocks 必须大于0: %d", c.Congestion.WindowBlocks)
+		}
+		if c.Congestion.SkippedSlotRate <= 0 || c.Congestion.SkippedSlotRate > 1 {
+			addf("congestion.skipped_slot_rate 必须在(0, 1]之间: %g", c.Congestion.SkippedSlotRate)
+		}
+		if c.Congestion.FailedTxRate <= 0 || c.Congestion.FailedTxRate > 1 {
+			addf("congestion.failed_tx_rate 必须在(0, 1]之间: %g", c.Congestion.FailedTxRate)
+		}
+		if c.Congestion.RecoveryRatio <= 0 || c.Congestion.RecoveryRatio > 1 {
+			addf("congestion.recovery_ratio 必须在(0, 1]之间: %g", c.Congestion.RecoveryRatio)
+		}
+		if c.Congestion.LatencyTargetMultiplier < 1 {
+			addf("congestion.latency_target_multiplier 不能小于1: %g", c.Congestion.LatencyTargetMultiplier)
+		}
+		if c.Congestion.EnhancedAPIIntervalMultiplier < 1 {
+			addf("congestion.enhanced_api_interval_multiplier 不能小于1: %g", c.Congestion.EnhancedAPIIntervalMultiplier)
+		}
+	}
+
+	// 区块处理状态跟踪
+	if c.BlockState.Enabled {
+		if c.BlockState.StuckThreshold <= 0 {
+			addf("block_state.stuck_threshold 必须大于0: %s", c.BlockState.StuckThreshold)
+		}
+		if c.BlockState.CheckInterval <= 0 {
+			addf("block_state.check_interval 必须大于0: %s", c.BlockState.CheckInterval)
+		}
+		if c.BlockState.MaxRetries < 0 {
+			addf("block_state.max_retries 不能为负数: %d", c.BlockState.MaxRetries)
+		}
+		if c.BlockState.Retention < 0 {
+			addf("block_state.retention 不能为负数: %s", c.BlockState.Retention)
+		}
+	}
+
+	// 跳过列表
+	if c.SkippedSlots.Enabled {
+		if c.SkippedSlots.RetryInterval <= 0 {
+			addf("skipped_slots.retry_interval 必须大于0: %s", c.SkippedSlots.RetryInterval)
+		}
+		if c.SkippedSlots.MaxRetryInterval < c.SkippedSlots.RetryInterval {
+			addf("skipped_slots.max_retry_interval(%s) 不能小于 retry_interval(%s)", c.SkippedSlots.MaxRetryInterval, c.SkippedSlots.RetryInterval)
+		}
+		if c.SkippedSlots.MaxAttempts < 0 {
+			addf("skipped_slots.max_attempts 不能为负数: %d", c.SkippedSlots.MaxAttempts)
+		}
+		if c.SkippedSlots.CheckInterval <= 0 {
+			addf("skipped_slots.check_interval 必须大于0: %s", c.SkippedSlots.CheckInterval)
+		}
+	}
+
+	// 链路追踪
+	if c.Tracing.Enabled {
+		if c.Tracing.Endpoint == "" {
+			addf("tracing.enabled=true 但未设置 tracing.endpoint")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			addf("tracing.sample_ratio 必须在0到1之间: %g", c.Tracing.SampleRatio)
+		}
+		if c.Tracing.MaxSlots <= 0 {
+			addf("tracing.max_slots 必须大于0: %d", c.Tracing.MaxSlots)
+		}
+		if c.Tracing.SlotTimeout <= 0 {
+			addf("tracing.slot_timeout 必须大于0: %s", c.Tracing.SlotTimeout)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// containsFold 忽略大小写判断是否包含
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL 校验URL是否包含协议和主机
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("缺少协议或主机: %q", rawURL)
+	}
+	return nil
+}
+
+// validateProxyURL 校验代理URL，协议须为 http、https、socks5 或 socks5h
+func validateProxyURL(rawURL string) error {
+	if err := validateURL(rawURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(rawURL)
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	default:
+		return fmt.Errorf("不支持的代理协议: %q", u.Scheme)
+	}
+}
@@ -0,0 +1,83 @@
+package configs
+
+import (
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ChangeHandler 配置变更回调，oldConfig 为变更前的配置，newConfig 为变更后的配置
+type ChangeHandler func(oldConfig, newConfig *Config)
+
+// 加载配置时使用的viper实例，用于监听配置文件变化
+var globalViper *viper.Viper
+
+// changeHandlers 已注册的配置变更回调，按模块名区分
+var changeHandlers = struct {
+	sync.Mutex
+	handlers map[string]ChangeHandler
+	order    []string
+}{handlers: make(map[string]ChangeHandler)}
+
+// OnChange 注册配置变更回调，同一模块名重复注册时覆盖之前的回调
+// 回调按注册顺序在配置热更新后依次调用
+func OnChange(module string, handler ChangeHandler) {
+	changeHandlers.Lock()
+	defer changeHandlers.Unlock()
+	if _, ok := changeHandlers.handlers[module]; !ok {
+		changeHandlers.order = append(changeHandlers.order, module)
+	}
+	changeHandlers.handlers[module] = handler
+}
+
+// WatchConfig 监听配置文件变化，变化后重新解析配置、替换 Current 返回的配置并通知已注册的模块
+// 新配置解析失败时保留原配置；GlobalConfig 保持启动时的配置，没有注册回调的模块需要重启才能使用新配置
+func WatchConfig() {
+	if globalViper == nil {
+		return
+	}
+	globalViper.OnConfigChange(func(event fsnotify.Event) {
+		reloadConfig(event.Name)
+	})
+	globalViper.WatchConfig()
+	zap.L().Info("已开启配置文件热更新", zap.String("file", globalViper.ConfigFileUsed()))
+}
+
+// reloadConfig 重新解析配置并广播变更
+func reloadConfig(file string) {
+	cfg, err := decodeConfig(globalViper)
+	if err != nil {
+		zap.L().Error("热更新配置失败，继续使用原配置", zap.String("file", file), zap.Error(err))
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		zap.L().Error("热更新配置校验失败，继续使用原配置", zap.String("file", file), zap.Error(err))
+		return
+	}
+
+	// GlobalConfig 在启动后被各模块并发读取，不能替换，只原子替换 Current 返回的配置
+	oldConfig := current.Swap(cfg)
+	zap.L().Info("配置文件已变更，已重新加载", zap.String("file", file))
+
+	changeHandlers.Lock()
+	handlers := make([]ChangeHandler, 0, len(changeHandlers.order))
+	modules := make([]string, 0, len(changeHandlers.order))
+	for _, module := range changeHandlers.order {
+		handlers = append(handlers, changeHandlers.handlers[module])
+		modules = append(modules, module)
+	}
+	changeHandlers.Unlock()
+
+	for i, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					zap.L().Error("配置变更回调发生意外", zap.String("module", modules[i]), zap.Any("panic", r))
+				}
+			}()
+			handler(oldConfig, cfg)
+		}()
+	}
+}
@@ -0,0 +1,69 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestReloadConfigPublishesCurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := "websocket:\n  api_key: test\nhelius_api:\n  api_key: test\nhelius_enhanced_api:\n  api_keys: [test]\n"
+	write(base + "parser:\n  key_rps: 5\n")
+	LoadConfig(path)
+	startup := GlobalConfig
+
+	var got []float64
+	var mu sync.Mutex
+	OnChange("test", func(oldConfig, newConfig *Config) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, oldConfig.Parser.KeyRPS, newConfig.Parser.KeyRPS)
+	})
+
+	write(base + "parser:\n  key_rps: 20\n")
+	if err := globalViper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reloadConfig(path)
+	}()
+	// 热更新期间读取全局配置不应产生数据竞争
+	for range 100 {
+		_ = GlobalConfig.Parser.KeyRPS
+		_ = Current().Parser.KeyRPS
+	}
+	<-done
+
+	if GlobalConfig != startup || GlobalConfig.Parser.KeyRPS != 5 {
+		t.Fatalf("GlobalConfig 不应被替换: key_rps=%v", GlobalConfig.Parser.KeyRPS)
+	}
+	if Current().Parser.KeyRPS != 20 {
+		t.Fatalf("Current().Parser.KeyRPS = %v, 期望 20", Current().Parser.KeyRPS)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 || got[0] != 5 || got[1] != 20 {
+		t.Fatalf("回调参数 = %v, 期望 [5 20]", got)
+	}
+
+	// 校验失败时保留原配置
+	write(base + "parser:\n  key_rps: -1\n")
+	if err := globalViper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	reloadConfig(path)
+	if Current().Parser.KeyRPS != 20 {
+		t.Fatalf("校验失败后 Current().Parser.KeyRPS = %v, 期望 20", Current().Parser.KeyRPS)
+	}
+}
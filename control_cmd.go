@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/api"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/handler"
+)
+
+// newControlCommand 运行时控制命令，通过管理接口暂停、恢复运行中的服务，内存队列不丢失
+func newControlCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "control",
+		Short: "运行时暂停/恢复区块获取和交易解析、排空队列、立即回补",
+		Long: `通过管理接口控制运行中的服务，用于Helius额度耗尽等事故处理，无需重启，内存队列不会丢失。
+
+阶段名:
+  block_fetch  从区块队列取出槽位并获取区块
+  parse        从交易队列取出签名并解析`,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "status",
+			Short: "查看暂停、排空状态和队列长度",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(http.MethodGet, "/admin/control", nil)
+			},
+		},
+		&cobra.Command{
+			Use:       "pause <stage>",
+			Short:     "暂停处理阶段，正在进行的批次会处理完",
+			Args:      cobra.ExactArgs(1),
+			ValidArgs: []string{handler.ControlBlockFetch, handler.ControlParse},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(http.MethodPost, "/admin/control/"+args[0]+"/pause", nil)
+			},
+		},
+		&cobra.Command{
+			Use:       "resume <stage>",
+			Short:     "恢复处理阶段",
+			Args:      cobra.ExactArgs(1),
+			ValidArgs: []string{handler.ControlBlockFetch, handler.ControlParse},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(http.MethodPost, "/admin/control/"+args[0]+"/resume", nil)
+			},
+		},
+		&cobra.Command{
+			Use:   "drain",
+			Short: "开始排空队列：新槽位暂不入队，已入队的区块和交易继续处理",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(http.MethodPost, "/admin/control/drain", nil)
+			},
+		},
+		&cobra.Command{
+			Use:   "undrain",
+			Short: "停止排空，排空期间收到的槽位重新入队",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(http.MethodDelete, "/admin/control/drain", nil)
+			},
+		},
+		newControlBackfillCommand(),
+	)
+	return cmd
+}
+
+// newControlBackfillCommand 立即将槽位范围推入运行中服务的区块队列
+func newControlBackfillCommand() *cobra.Command {
+	var request api.BackfillRequest
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "立即将槽位范围推入运行中服务的区块队列",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if request.From == 0 || request.To < request.From {
+				return fmt.Errorf("必须指定 --from，且 --to 不能小于 --from")
+			}
+			return runControl(http.MethodPost, "/admin/backfill", &request)
+		},
+	}
+	cmd.Flags().Uint64Var(&request.From, "from", 0, "起始槽位(包含)")
+	cmd.Flags().Uint64Var(&request.To, "to", 0, "结束槽位(包含)")
+	return cmd
+}
+
+// runControl 调用运行时控制管理接口并输出返回的状态
+// 参数:
+//   - method: HTTP方法
+//   - path: 管理接口路径
+//   - body: 请求体，为nil时不发送
+//
+// 返回:
+//   - error: 错误信息
+func runControl(method, path string, body any) error {
+	loadConfig()
+	adminConfig := configs.GlobalConfig.Admin
+	if !adminConfig.Enabled {
+		return fmt.Errorf("未启用管理接口")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+adminConfig.Addr+path, reader)
+	if err != nil {
+		return err
+	}
+	if adminConfig.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+adminConfig.AuthToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("服务未运行或管理接口不可达")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return fmt.Errorf("管理接口返回错误: %s", failure.Error)
+		}
+		return fmt.Errorf("管理接口返回状态码 %d", resp.StatusCode)
+	}
+	var status api.ControlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return fmt.Errorf("解析管理接口响应失败: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "区块获取\t%s\n", pausedLabel(status.BlockFetchPaused))
+	fmt.Fprintf(w, "交易解析\t%s\n", pausedLabel(status.ParsePaused))
+	switch {
+	case status.Drained:
+		fmt.Fprintln(w, "排空\t已完成")
+	case status.Draining:
+		fmt.Fprintln(w, "排空\t进行中")
+	default:
+		fmt.Fprintln(w, "排空\t否")
+	}
+	if status.DeferredFrom > 0 {
+		fmt.Fprintf(w, "推迟入队的槽位\t%d-%d\n", status.DeferredFrom, status.DeferredTo)
+	}
+	fmt.Fprintf(w, "内存区块队列\t%d\n", status.BlockQueue)
+	fmt.Fprintf(w, "内存交易队列\t%d\n", status.TransactionQueue)
+	if status.Enqueued > 0 {
+		fmt.Fprintf(w, "本次入队槽位\t%d\n", status.Enqueued)
+	}
+	return w.Flush()
+}
+
+// pausedLabel 返回暂停状态的显示文本
+func pausedLabel(paused bool) string {
+	if paused {
+		return "已暂停"
+	}
+	return "运行中"
+}
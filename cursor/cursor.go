@@ -0,0 +1,225 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/storage"
+)
+
+// GlobalSlotCursor 全局区块游标
+var GlobalSlotCursor *SlotCursor
+
+// Status 区块游标状态
+type Status struct {
+	Slot          uint64    `json:"slot"`                 // 已处理完成的最大槽位
+	UpdatedAt     time.Time `json:"updated_at"`           // 最后一次推进的时间
+	StateFile     string    `json:"state_file,omitempty"` // 持久化文件
+	ResumedFrom   uint64    `json:"resumed_from"`         // 启动时读取到的游标
+	FirstSlot     uint64    `json:"first_slot,omitempty"` // 启动后收到的第一个槽位
+	Gap           uint64    `json:"gap"`                  // 启动时游标与第一个槽位之间缺失的槽位数
+	GapBackfilled bool      `json:"gap_backfilled"`       // 缺口是否已推入区块队列回补
+	LastError     string    `json:"last_error,omitempty"` // 最近一次持久化失败的原因
+}
+
+// SlotCursor 记录已处理完成的最大槽位，并发安全
+// 每个区块处理完成后推进，同时保存到Redis和可选的状态文件，重启后用于续传或发现大范围缺口
+type SlotCursor struct {
+	mu       sync.Mutex
+	status   Status
+	resume   bool
+	maxGap   uint64
+	observed bool
+	log      *zap.Logger
+}
+
+// NewSlotCursor 创建区块游标并设置为全局实例，会从Redis和状态文件中读取上次的游标，取两者的较大值
+func NewSlotCursor(config *configs.ParserConfig) (*SlotCursor, error) {
+	cursor := &SlotCursor{
+		status: Status{StateFile: config.StateFilePath},
+		resume: config.Resume,
+		maxGap: config.MaxGap,
+		log:    logger.Named("cursor"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	slot, err := storage.GetRedisClient(storage.WorkloadQueue).GetSlotCursor(ctx)
+	if err != nil {
+		// Redis不可用时以状态文件为准，没有状态文件则无法确定续传位置
+		if !errors.Is(err, storage.ErrRedisUnavailable) || config.StateFilePath == "" {
+			return nil, err
+		}
+		cursor.log.Warn("Redis不可用，从状态文件加载区块游标", zap.Error(err))
+	}
+	if config.StateFilePath != "" {
+		fileSlot, err := readStateFile(config.StateFilePath)
+		if err != nil {
+			return nil, err
+		}
+		slot = max(slot, fileSlot)
+	}
+	cursor.status.Slot = slot
+	cursor.status.ResumedFrom = slot
+
+	GlobalSlotCursor = cursor
+	cursor.log.Info("区块游标已加载", zap.Uint64("slot", slot), zap.String("stateFile", config.StateFilePath))
+	return cursor, nil
+}
+
+// Advance 区块处理完成后推进游标，未启用游标时不做任何处理
+func Advance(slot uint64) {
+	if GlobalSlotCursor != nil {
+		GlobalSlotCursor.Advance(slot)
+	}
+}
+
+// Observe 记录收到的槽位通知，启动后的第一个槽位用于检测缺口，未启用游标时不做任何处理
+func Observe(slot uint64) {
+	if GlobalSlotCursor != nil {
+		GlobalSlotCursor.Observe(slot)
+	}
+}
+
+// Resync 从Redis重新读取游标，并对之后收到的第一个槽位重新检测缺口，未启用游标时不做任何处理
+func Resync(ctx context.Context) error {
+	if GlobalSlotCursor == nil {
+		return nil
+	}
+	return GlobalSlotCursor.Resync(ctx)
+}
+
+// Resync 从Redis重新读取游标，并对之后收到的第一个槽位重新检测缺口
+// 备用实例接管摄取时调用：备用期间游标由领导者推进，接管前缺失的槽位按 resume 和 max_gap 自动回补
+func (c *SlotCursor) Resync(ctx context.Context) error {
+	slot, err := storage.GetRedisClient(storage.WorkloadQueue).GetSlotCursor(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Slot = max(c.status.Slot, slot)
+	c.status.ResumedFrom = c.status.Slot
+	c.status.FirstSlot = 0
+	c.status.Gap = 0
+	c.status.GapBackfilled = false
+	c.observed = false
+	c.log.Info("区块游标已重新同步", zap.Uint64("slot", c.status.Slot))
+	return nil
+}
+
+// Advance 推进游标，槽位不大于当前游标时保持不变
+// 区块并发处理、完成顺序不固定，游标表示已处理完成的最大槽位
+func (c *SlotCursor) Advance(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slot <= c.status.Slot {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := storage.GetRedisClient(storage.WorkloadQueue).AdvanceSlotCursor(ctx, slot)
+	if err != nil {
+		c.fail(err)
+		cursor = slot
+	}
+	c.status.Slot = max(cursor, slot)
+	c.status.UpdatedAt = clock.Now()
+	if c.status.StateFile != "" {
+		if err := writeStateFile(c.status.StateFile, c.status.Slot); err != nil {
+			c.fail(err)
+		}
+	}
+}
+
+// Observe 记录收到的槽位通知，第一次调用时比较游标与槽位之间的缺口：
+// 缺口不超过 max_gap 且开启 resume 时将缺失的槽位推入区块队列，超过时只告警，需要通过 backfill 命令回补
+func (c *SlotCursor) Observe(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.observed {
+		return
+	}
+	c.observed = true
+	c.status.FirstSlot = slot
+
+	from := c.status.Slot
+	if from == 0 || slot <= from+1 {
+		return
+	}
+	gap := slot - from - 1
+	c.status.Gap = gap
+	if gap > c.maxGap {
+		c.log.Error("检测到大范围区块缺口，超过自动回补上限，请使用 backfill 命令回补",
+			zap.Uint64("from", from+1), zap.Uint64("to", slot-1), zap.Uint64("gap", gap), zap.Uint64("maxGap", c.maxGap))
+		return
+	}
+	if !c.resume {
+		c.log.Warn("检测到区块缺口，未开启自动回补", zap.Uint64("from", from+1), zap.Uint64("to", slot-1), zap.Uint64("gap", gap))
+		return
+	}
+	for missing := from + 1; missing < slot; missing++ {
+		monitor.SetBlockState(missing, models.BlockQueued, nil)
+		storage.GlobalBlockQueue.Push(missing, int64(missing))
+	}
+	c.status.GapBackfilled = true
+	c.log.Info("已将区块缺口推入区块队列回补", zap.Uint64("from", from+1), zap.Uint64("to", slot-1), zap.Uint64("gap", gap))
+}
+
+// Status 返回游标状态
+func (c *SlotCursor) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// fail 记录持久化失败，调用方需持有锁
+func (c *SlotCursor) fail(err error) {
+	c.status.LastError = err.Error()
+	c.log.Error("保存区块游标失败", zap.Error(err))
+}
+
+// readStateFile 读取状态文件中的游标，文件不存在时返回0
+func readStateFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("读取游标状态文件失败: %w", err)
+	}
+	slot, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析游标状态文件失败: %w", err)
+	}
+	return slot, nil
+}
+
+// writeStateFile 先写入临时文件再重命名，避免进程中途退出留下不完整的状态文件
+func writeStateFile(path string, slot uint64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建游标状态目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(slot, 10)), 0644); err != nil {
+		return fmt.Errorf("写入游标状态文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入游标状态文件失败: %w", err)
+	}
+	return nil
+}
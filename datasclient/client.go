@@ -0,0 +1,329 @@
+// Package datasclient 供其他Go服务读取本服务产出的数据：解析后的交易、代币统计和区块处理状态
+// 通过管理接口(admin.addr)和独立解析服务(parse_server.addr)的HTTP接口访问，调用方不需要了解Redis中的存储结构；
+// 本包只依赖 models，不会引入采集服务的其他依赖
+package datasclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+var (
+	// ErrNotFound 查询的数据不存在(HTTP 404)
+	ErrNotFound = errors.New("数据不存在")
+	// ErrUnavailable 服务端没有启用对应功能(HTTP 503)
+	ErrUnavailable = errors.New("服务端未启用该功能")
+	// ErrRateLimited 独立解析服务被上游限流(HTTP 429)，可通过 APIError.RetryAfter 获取等待时间
+	ErrRateLimited = errors.New("请求被限流")
+	// ErrNoParseURL 没有通过 WithParseURL 设置独立解析服务的地址
+	ErrNoParseURL = errors.New("未设置独立解析服务地址")
+)
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	StatusCode int           // HTTP状态码
+	Message    string        // 服务端返回的错误信息
+	RetryAfter time.Duration // 限流时服务端要求的等待时间
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Is 按状态码匹配 ErrNotFound、ErrUnavailable 和 ErrRateLimited
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusServiceUnavailable:
+		return target == ErrUnavailable
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+	return false
+}
+
+// Client 本服务HTTP接口的客户端，可并发使用
+type Client struct {
+	baseURL    string
+	parseURL   string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的HTTP客户端，默认超时10秒
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithParseURL 设置独立解析服务的地址，如 http://127.0.0.1:8091，ParseSignatures 需要
+func WithParseURL(parseURL string) Option {
+	return func(c *Client) {
+		c.parseURL = strings.TrimRight(parseURL, "/")
+	}
+}
+
+// WithHeader 为每个请求添加请求头，如经过鉴权代理时的 Authorization
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// New 创建客户端
+// 参数:
+//   - baseURL: 管理接口的地址，如 http://127.0.0.1:8090
+//   - options: 客户端选项
+//
+// 返回:
+//   - *Client: 客户端
+func New(baseURL string, options ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		header:     make(http.Header),
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// Transaction 按签名查询已解析并缓存的交易
+// 返回:
+//   - *resp.ParsedTransaction: Enhanced API解析结果
+//   - error: 没有该交易的解析结果或缓存已过期时 errors.Is(err, ErrNotFound) 为true
+func (c *Client) Transaction(ctx context.Context, signature string) (*resp.ParsedTransaction, error) {
+	var transaction resp.ParsedTransaction
+	if err := c.get(ctx, "/admin/transactions/"+url.PathEscape(signature), nil, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// MintTransactions 查询代币在某一天的交易签名，可再通过 Transaction 查询解析结果
+// 参数:
+//   - mint: 代币地址
+//   - day: 该天内的任意时间，零值表示当天(UTC)
+//   - limit: 最多返回的签名数，1到1000
+//
+// 返回:
+//   - *models.MintTransactions: 按槽位降序排列的签名
+//   - error: 错误信息
+func (c *Client) MintTransactions(ctx context.Context, mint string, day time.Time, limit int) (*models.MintTransactions, error) {
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if !day.IsZero() {
+		query.Set("time", strconv.FormatInt(day.Unix(), 10))
+	}
+	var transactions models.MintTransactions
+	if err := c.get(ctx, "/admin/tokens/"+url.PathEscape(mint)+"/transactions", query, &transactions); err != nil {
+		return nil, err
+	}
+	return &transactions, nil
+}
+
+// ParseSignatures 通过独立解析服务按签名解析交易，不依赖交易是否已被采集
+// 返回:
+//   - *models.ParseSignaturesResponse: 解析结果，Enhanced API未返回的签名在 Missing 中
+//   - error: 没有设置 WithParseURL 时返回 ErrNoParseURL，上游限流时 errors.Is(err, ErrRateLimited) 为true
+func (c *Client) ParseSignatures(ctx context.Context, signatures []string) (*models.ParseSignaturesResponse, error) {
+	return c.Parse(ctx, models.ParseSignaturesRequest{Signatures: signatures})
+}
+
+// Parse 与 ParseSignatures 相同，可以通过 request.Commitment 指定确认级别，
+// 如低延迟告警使用 confirmed 在交易最终确认前拿到解析结果
+// 返回:
+//   - *models.ParseSignaturesResponse: 解析结果，Enhanced API未返回的签名在 Missing 中
+//   - error: 没有设置 WithParseURL 时返回 ErrNoParseURL，上游限流时 errors.Is(err, ErrRateLimited) 为true
+func (c *Client) Parse(ctx context.Context, request models.ParseSignaturesRequest) (*models.ParseSignaturesResponse, error) {
+	if c.parseURL == "" {
+		return nil, ErrNoParseURL
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.parseURL+"/v1/parse/signatures", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	var response models.ParseSignaturesResponse
+	if err := c.do(httpRequest, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// TopTokenStats 查询24小时成交量最大的代币统计
+// 参数:
+//   - limit: 返回的代币数，1到500
+//
+// 返回:
+//   - *models.TokenStatsResponse: 按成交量降序排列的代币统计
+//   - error: 服务端未启用代币统计时 errors.Is(err, ErrUnavailable) 为true
+func (c *Client) TopTokenStats(ctx context.Context, limit int) (*models.TokenStatsResponse, error) {
+	var stats models.TokenStatsResponse
+	if err := c.get(ctx, "/admin/tokens", url.Values{"limit": {strconv.Itoa(limit)}}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// TokenStats 查询单个代币的24小时统计
+// 返回:
+//   - *models.TokenStats: 代币统计
+//   - error: 代币没有统计数据时 errors.Is(err, ErrNotFound) 为true
+func (c *Client) TokenStats(ctx context.Context, mint string) (*models.TokenStats, error) {
+	var stats models.TokenStats
+	if err := c.get(ctx, "/admin/tokens/"+url.PathEscape(mint)+"/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// BlockStatus 查询单个区块的处理状态
+// 返回:
+//   - *models.BlockStatus: 区块处理状态
+//   - error: 没有该区块的记录时 errors.Is(err, ErrNotFound) 为true，服务端未启用状态跟踪时 errors.Is(err, ErrUnavailable) 为true
+func (c *Client) BlockStatus(ctx context.Context, slot uint64) (*models.BlockStatus, error) {
+	var status models.BlockStatus
+	if err := c.get(ctx, "/admin/blocks/"+strconv.FormatUint(slot, 10), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// BlockStateCounts 按状态统计区块数量
+func (c *Client) BlockStateCounts(ctx context.Context) (map[models.BlockState]int64, error) {
+	var counts map[models.BlockState]int64
+	if err := c.get(ctx, "/admin/blocks/states", nil, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// StuckBlocks 查询卡在拉取或解析阶段超过阈值的区块槽位
+func (c *Client) StuckBlocks(ctx context.Context, limit int) ([]uint64, error) {
+	var stuck struct {
+		Slots []uint64 `json:"slots"`
+	}
+	if err := c.get(ctx, "/admin/blocks/stuck", url.Values{"limit": {strconv.Itoa(limit)}}, &stuck); err != nil {
+		return nil, err
+	}
+	return stuck.Slots, nil
+}
+
+// BlockSummary 查询单个区块的交易汇总
+// 返回:
+//   - *models.BlockSummary: 交易总数、投票交易数、失败交易数、手续费和计算单元合计
+//   - error: 没有该区块的汇总时 errors.Is(err, ErrNotFound) 为true，服务端未启用区块交易汇总时 errors.Is(err, ErrUnavailable) 为true
+func (c *Client) BlockSummary(ctx context.Context, slot uint64) (*models.BlockSummary, error) {
+	var summary models.BlockSummary
+	if err := c.get(ctx, "/admin/blocks/"+strconv.FormatUint(slot, 10)+"/summary", nil, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// BlockSummaries 按槽位范围查询区块交易汇总
+// 参数:
+//   - fromSlot: 起始槽位(包含)，0表示不限制
+//   - toSlot: 结束槽位(包含)，0表示不限制
+//   - limit: 最多返回的区块数，1到1000，从结束槽位开始向前取
+//
+// 返回:
+//   - []models.BlockSummary: 按槽位降序排列的汇总
+//   - error: 错误信息
+func (c *Client) BlockSummaries(ctx context.Context, fromSlot, toSlot uint64, limit int) ([]models.BlockSummary, error) {
+	query := url.Values{
+		"from":  {strconv.FormatUint(fromSlot, 10)},
+		"to":    {strconv.FormatUint(toSlot, 10)},
+		"limit": {strconv.Itoa(limit)},
+	}
+	var summaries struct {
+		Summaries []models.BlockSummary `json:"summaries"`
+	}
+	if err := c.get(ctx, "/admin/blocks/summaries", query, &summaries); err != nil {
+		return nil, err
+	}
+	return summaries.Summaries, nil
+}
+
+// BlockByHash 按区块哈希查询槽位和区块元数据
+// 返回:
+//   - *models.BlockMeta: 区块元数据
+//   - error: 没有该区块哈希的记录时 errors.Is(err, ErrNotFound) 为true
+func (c *Client) BlockByHash(ctx context.Context, blockhash string) (*models.BlockMeta, error) {
+	var meta models.BlockMeta
+	if err := c.get(ctx, "/admin/blockhash/"+url.PathEscape(blockhash), nil, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// get 向管理接口发送GET请求并解析JSON响应
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	return c.do(request, v)
+}
+
+// do 发送请求，状态码不是200时返回 *APIError
+func (c *Client) do(request *http.Request, v any) error {
+	for key, values := range c.header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("请求 %s 失败: %w", request.URL.Path, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: response.StatusCode}
+		var body struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		if json.Unmarshal(data, &body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 的响应失败: %w", request.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// newDiagnoseCommand 诊断单个交易签名
+func newDiagnoseCommand() *cobra.Command {
+	var signature string
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "诊断单个交易的解析结果与过滤判定",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if signature == "" {
+				return fmt.Errorf("必须指定交易签名，使用 --signature 参数")
+			}
+			runDiagnose(signature)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&signature, "signature", "", "需要诊断的交易签名")
+	return cmd
+}
+
+// runDiagnose 诊断单个交易签名：分别经过Enhanced API解析、本地解码和过滤规则，
+// 并排输出各路径的结果以及过滤器接受/拒绝的原因，便于排查解析问题
+func runDiagnose(signature string) {
+	loadConfig()
+	applyProxyConfig()
+	rpc.NewHeliusClient(&configs.GlobalConfig.HeliusAPI)
+	rpc.NewHeliusEnhancedApiClient(&configs.GlobalConfig.HeliusEnhancedAPI)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// 1. Enhanced API解析
+	var enhanced *resp.ParsedTransaction
+	var enhancedErr error
+	if rpc.GetEnhancedApiClientCount() == 0 {
+		enhancedErr = fmt.Errorf("没有可用的Enhanced API客户端")
+	} else if body, err := rpc.GetEnhancedApiClientByIndex(0).ParseTransactions(ctx, nil, signature); err != nil {
+		enhancedErr = err
+	} else {
+		var parsedTransactions []resp.ParsedTransaction
+		if err := json.Unmarshal(body, &parsedTransactions); err != nil {
+			enhancedErr = fmt.Errorf("解析Enhanced API响应失败: %w", err)
+		} else if len(parsedTransactions) == 0 {
+			enhancedErr = fmt.Errorf("Enhanced API未返回交易")
+		} else {
+			enhanced = &parsedTransactions[0]
+		}
+	}
+
+	// 2. 原始交易本地解码
+	var local *parser.LocalTransaction
+	var rawTransaction resp.Transactions
+	var localErr error
+	if body, err := rpc.GlobalHeliusClient.GetTransaction(ctx, signature, nil); err != nil {
+		localErr = err
+	} else if len(body) == 0 || string(body) == "null" {
+		localErr = fmt.Errorf("getTransaction未找到该交易")
+	} else {
+		var transactionResp resp.GetTransactionResp
+		if err := json.Unmarshal(body, &transactionResp); err != nil {
+			localErr = fmt.Errorf("解析交易数据失败: %w", err)
+		} else {
+			rawTransaction = resp.Transactions{
+				Meta:        transactionResp.Meta,
+				Transaction: transactionResp.Transaction,
+				Version:     transactionResp.Version,
+			}
+			local = parser.DecodeTransaction(transactionResp.Slot, rawTransaction)
+		}
+	}
+
+	printDiagnoseReport(signature, enhanced, enhancedErr, local, rawTransaction, localErr)
+}
+
+// printDiagnoseReport 并排打印诊断结果
+func printDiagnoseReport(signature string, enhanced *resp.ParsedTransaction, enhancedErr error, local *parser.LocalTransaction, rawTransaction resp.Transactions, localErr error) {
+	fmt.Printf("交易签名: %s\n\n", signature)
+	if enhancedErr != nil {
+		fmt.Printf("Enhanced API解析失败: %v\n", enhancedErr)
+	}
+	if localErr != nil {
+		fmt.Printf("本地解码失败: %v\n", localErr)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "字段\tEnhanced API\t本地解码\t一致")
+	fmt.Fprintln(w, "----\t------------\t--------\t----")
+
+	row := func(field, enhancedValue, localValue string) {
+		mark := "是"
+		if enhanced == nil || local == nil {
+			mark = "-"
+		} else if enhancedValue != localValue {
+			mark = "否"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", field, enhancedValue, localValue, mark)
+	}
+	pick := func(enhancedValue func() string, localValue func() string) (string, string) {
+		e, l := "-", "-"
+		if enhanced != nil {
+			e = enhancedValue()
+		}
+		if local != nil {
+			l = localValue()
+		}
+		return e, l
+	}
+
+	e, l := pick(func() string { return fmt.Sprint(enhanced.Slot) }, func() string { return fmt.Sprint(local.Slot) })
+	row("区块", e, l)
+	e, l = pick(func() string { return fmt.Sprint(enhanced.Fee) }, func() string { return fmt.Sprint(local.Fee) })
+	row("手续费", e, l)
+	e, l = pick(func() string { return enhanced.FeePayer }, func() string { return local.FeePayer })
+	row("手续费支付者", e, l)
+	e, l = pick(func() string {
+		return fmt.Sprint(enhanced.TransactionError != nil && len(enhanced.TransactionError.InstructionError) > 0)
+	}, func() string { return fmt.Sprint(local.Failed) })
+	row("执行失败", e, l)
+	e, l = pick(func() string { return string(enhanced.Type) + "/" + string(enhanced.Source) }, func() string {
+		if local.IsVote {
+			return "VOTE"
+		}
+		return "-"
+	})
+	fmt.Fprintf(w, "类型/来源\t%s\t%s\t-\n", e, l)
+
+	// SOL余额变化逐账户对比
+	enhancedNative := make(map[string]int64)
+	if enhanced != nil {
+		for _, accountData := range enhanced.AccountData {
+			if accountData.NativeBalanceChange != 0 {
+				enhancedNative[accountData.Account] = accountData.NativeBalanceChange
+			}
+		}
+	}
+	localNative := make(map[string]int64)
+	if local != nil {
+		localNative = local.NativeBalanceChanges
+	}
+	for _, account := range unionKeys(enhancedNative, localNative) {
+		e, l = pick(func() string { return formatChange(enhancedNative, account) }, func() string { return formatChange(localNative, account) })
+		row("SOL变化 "+account, e, l)
+	}
+
+	// 代币余额变化按 代币账户/mint 对比
+	enhancedToken := make(map[string]string)
+	if enhanced != nil {
+		for _, accountData := range enhanced.AccountData {
+			for _, change := range accountData.TokenBalanceChanges {
+				enhancedToken[change.TokenAccount+" "+change.Mint] = change.RawTokenAmount.TokenAmount
+			}
+		}
+	}
+	localToken := make(map[string]string)
+	if local != nil {
+		for _, change := range local.TokenBalanceChanges {
+			localToken[change.TokenAccount+" "+change.Mint] = change.RawAmount.String()
+		}
+	}
+	for _, key := range unionKeys(enhancedToken, localToken) {
+		e, l = pick(func() string { return valueOr(enhancedToken, key) }, func() string { return valueOr(localToken, key) })
+		row("代币变化 "+key, e, l)
+	}
+	w.Flush()
+
+	// 过滤器判定
+	fmt.Println()
+	fmt.Println("过滤器判定:")
+	if local != nil {
+		fmt.Printf("  区块阶段: %s\n", verdict(handler.BlockTransactionFilterReason(rawTransaction)))
+	} else {
+		fmt.Println("  区块阶段: 无原始交易数据，无法判定")
+	}
+	if enhanced != nil {
+		fmt.Printf("  解析阶段: %s\n", verdict(handler.ParsedTransactionFilterReason(*enhanced)))
+	} else {
+		fmt.Println("  解析阶段: 无Enhanced API结果，无法判定")
+	}
+}
+
+// verdict 将过滤原因格式化为判定结果
+func verdict(reason string) string {
+	if reason == "" {
+		return "接受"
+	}
+	return "拒绝 (" + reason + ")"
+}
+
+// unionKeys 返回两个映射键的有序并集
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]struct{})
+	for k := range a {
+		seen[k] = struct{}{}
+	}
+	for k := range b {
+		seen[k] = struct{}{}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatChange 格式化余额变化，不存在时返回"-"
+func formatChange(changes map[string]int64, account string) string {
+	if change, ok := changes[account]; ok {
+		return fmt.Sprint(change)
+	}
+	return "-"
+}
+
+// valueOr 获取映射中的值，不存在时返回"-"
+func valueOr(values map[string]string, key string) string {
+	if value, ok := values[key]; ok {
+		return value
+	}
+	return "-"
+}
@@ -10,15 +10,40 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
 	"github.com/life2you/datas-go/rpc"
 )
 
 func main() {
+	// 初始化日志，客户端内部日志通过zap输出
+	logger.Init(&configs.LogConfig{Level: "info", Format: "console", Stdout: true})
+
+	// 处理所有PumpPortal事件，根据txType区分事件类型
+	messageHandler := func(data json.RawMessage) {
+		var event map[string]interface{}
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("解析PumpPortal数据失败: %v", err)
+			return
+		}
+		switch event["txType"] {
+		case "create":
+			log.Printf("收到新代币创建事件: %+v", event)
+		case "migrate":
+			log.Printf("收到代币迁移事件: %+v", event)
+		case "buy", "sell":
+			log.Printf("收到交易事件: %+v", event)
+		default:
+			log.Printf("收到其他事件: %+v", event)
+		}
+	}
+
 	// 创建PumpPortal客户端
 	options := rpc.DefaultPumpPortalOptions()
 	// 如果需要使用代理，可以设置代理URL
 	// options.ProxyURL = "http://your-proxy-url:port"
-	client := rpc.NewPumpPortalClient(options)
+	rpc.NewPumpPortalClient(options, messageHandler)
+	client := rpc.GlobalPumpPortalClient
 
 	// 建立连接
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -28,48 +53,8 @@ func main() {
 	}
 	defer client.Close()
 
-	// 处理新代币创建事件
-	newTokenHandler := func(data json.RawMessage) {
-		var token map[string]interface{}
-		if err := json.Unmarshal(data, &token); err != nil {
-			log.Printf("解析新代币数据失败: %v", err)
-			return
-		}
-		log.Printf("收到新代币创建事件: %+v", token)
-	}
-
-	// 处理代币交易事件
-	tokenTradeHandler := func(data json.RawMessage) {
-		var trade map[string]interface{}
-		if err := json.Unmarshal(data, &trade); err != nil {
-			log.Printf("解析代币交易数据失败: %v", err)
-			return
-		}
-		log.Printf("收到代币交易事件: %+v", trade)
-	}
-
-	// 处理账户交易事件
-	accountTradeHandler := func(data json.RawMessage) {
-		var trade map[string]interface{}
-		if err := json.Unmarshal(data, &trade); err != nil {
-			log.Printf("解析账户交易数据失败: %v", err)
-			return
-		}
-		log.Printf("收到账户交易事件: %+v", trade)
-	}
-
-	// 处理代币迁移事件
-	migrationHandler := func(data json.RawMessage) {
-		var migration map[string]interface{}
-		if err := json.Unmarshal(data, &migration); err != nil {
-			log.Printf("解析代币迁移数据失败: %v", err)
-			return
-		}
-		log.Printf("收到代币迁移事件: %+v", migration)
-	}
-
 	// 订阅新代币创建
-	if err := client.SubscribeNewToken(newTokenHandler); err != nil {
+	if err := client.SubscribeNewToken(); err != nil {
 		log.Printf("订阅新代币创建失败: %v", err)
 	} else {
 		log.Println("已订阅新代币创建事件")
@@ -77,7 +62,7 @@ func main() {
 
 	// 订阅特定代币的交易
 	tokenAddresses := []string{"91WNez8D22NwBssQbkzjy4s2ipFrzpmn5hfvWVe2aY5p"}
-	if err := client.SubscribeTokenTrade(tokenAddresses, tokenTradeHandler); err != nil {
+	if err := client.SubscribeTokenTrade(tokenAddresses); err != nil {
 		log.Printf("订阅代币交易失败: %v", err)
 	} else {
 		log.Printf("已订阅代币 %v 的交易事件", tokenAddresses)
@@ -85,14 +70,14 @@ func main() {
 
 	// 订阅特定账户的交易
 	accountAddresses := []string{"AArPXm8JatJiuyEffuC1un2Sc835SULa4uQqDcaGpAjV"}
-	if err := client.SubscribeAccountTrade(accountAddresses, accountTradeHandler); err != nil {
+	if err := client.SubscribeAccountTrade(accountAddresses); err != nil {
 		log.Printf("订阅账户交易失败: %v", err)
 	} else {
 		log.Printf("已订阅账户 %v 的交易事件", accountAddresses)
 	}
 
 	// 订阅代币迁移事件
-	if err := client.SubscribeMigration(migrationHandler); err != nil {
+	if err := client.SubscribeMigration(); err != nil {
 		log.Printf("订阅代币迁移事件失败: %v", err)
 	} else {
 		log.Println("已订阅代币迁移事件")
@@ -0,0 +1,365 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// clickHouseCloseTimeout 未设置请求超时时，退出前写入剩余行的最长等待时间
+const clickHouseCloseTimeout = 30 * time.Second
+
+// GlobalClickHouseSink 全局ClickHouse写入，未启用时为nil
+var GlobalClickHouseSink *ClickHouseSink
+
+// clickHouseTransaction 解析结果表的一行
+type clickHouseTransaction struct {
+	Signature       string   `json:"signature"`
+	Slot            uint64   `json:"slot"`
+	BlockTime       int64    `json:"block_time"`
+	Source          string   `json:"source"`
+	Type            string   `json:"type"`
+	Fee             int64    `json:"fee"`
+	FeePayer        string   `json:"fee_payer"`
+	Description     string   `json:"description"`
+	Failed          bool     `json:"failed"`
+	Mints           []string `json:"mints"`
+	NativeTransfers int      `json:"native_transfers"`
+	TokenTransfers  int      `json:"token_transfers"`
+	SOLAmount       float64  `json:"sol_amount"`
+	USDValue        float64  `json:"usd_value"`
+	Raw             string   `json:"raw,omitempty"`
+}
+
+// clickHouseBlock 区块统计表的一行
+type clickHouseBlock struct {
+	Slot         uint64 `json:"slot"`
+	Blockhash    string `json:"blockhash"`
+	ParentSlot   uint64 `json:"parent_slot"`
+	BlockTime    int64  `json:"block_time"`
+	Transactions int    `json:"transactions"`
+	Failed       int    `json:"failed"`
+	Signatures   int    `json:"signatures"`
+	Prefiltered  int    `json:"prefiltered"`
+	ProcessedAt  int64  `json:"processed_at"`
+}
+
+// clickHouseTables 返回解析结果表和区块统计表的结构
+// 使用ReplacingMergeTree按排序键去重，回补或写入重试产生的重复行会在合并时消除
+func clickHouseTables(config *configs.ClickHouseConfig) []ClickHouseTable {
+	return []ClickHouseTable{
+		{
+			Name: config.TransactionsTable,
+			Columns: []ClickHouseColumn{
+				{Name: "signature", Type: "String"},
+				{Name: "slot", Type: "UInt64"},
+				{Name: "block_time", Type: "DateTime"},
+				{Name: "source", Type: "LowCardinality(String)"},
+				{Name: "type", Type: "LowCardinality(String)"},
+				{Name: "fee", Type: "UInt64"},
+				{Name: "fee_payer", Type: "String"},
+				{Name: "description", Type: "String"},
+				{Name: "failed", Type: "Bool"},
+				{Name: "mints", Type: "Array(String)"},
+				{Name: "native_transfers", Type: "UInt32"},
+				{Name: "token_transfers", Type: "UInt32"},
+				{Name: "sol_amount", Type: "Float64"},
+				{Name: "usd_value", Type: "Float64"},
+				{Name: "raw", Type: "String CODEC(ZSTD(3))"},
+				{Name: "inserted_at", Type: "DateTime DEFAULT now()"},
+			},
+			Engine:      "ReplacingMergeTree(inserted_at)",
+			PartitionBy: "toYYYYMM(block_time)",
+			OrderBy:     "(type, source, block_time, signature)",
+			TTLColumn:   "block_time",
+		},
+		{
+			Name: config.BlocksTable,
+			Columns: []ClickHouseColumn{
+				{Name: "slot", Type: "UInt64"},
+				{Name: "blockhash", Type: "String"},
+				{Name: "parent_slot", Type: "UInt64"},
+				{Name: "block_time", Type: "DateTime"},
+				{Name: "transactions", Type: "UInt32"},
+				{Name: "failed", Type: "UInt32"},
+				{Name: "signatures", Type: "UInt32"},
+				{Name: "prefiltered", Type: "UInt32"},
+				{Name: "processed_at", Type: "DateTime"},
+			},
+			Engine:      "ReplacingMergeTree(processed_at)",
+			PartitionBy: "toYYYYMM(block_time)",
+			OrderBy:     "slot",
+			TTLColumn:   "block_time",
+		},
+	}
+}
+
+// ClickHouseStats ClickHouse写入统计
+type ClickHouseStats struct {
+	Transactions int64     `json:"transactions"`         // 已写入的解析结果行数
+	Blocks       int64     `json:"blocks"`               // 已写入的区块统计行数
+	Buffered     int       `json:"buffered"`             // 等待写入的行数
+	Dropped      int64     `json:"dropped"`              // 写入持续失败、超过 max_buffered 后丢弃的行数
+	Failures     int64     `json:"failures"`             // 写入失败次数
+	LastError    string    `json:"last_error,omitempty"` // 最近一次写入失败的原因，写入成功后清空
+	LastFlush    time.Time `json:"last_flush"`           // 最近一次成功写入的时间
+}
+
+// clickHouseBuffer 一个表等待写入的行
+type clickHouseBuffer struct {
+	table string
+	rows  []any
+}
+
+// ClickHouseSink 订阅事件管道，将解析结果和区块统计按批写入ClickHouse
+// 缓冲达到 batch_size 或距上次写入超过 flush_interval 时写入；写入失败的行保留到下次重试，
+// 每个表最多保留 max_buffered 行，超过时丢弃最早的行
+type ClickHouseSink struct {
+	config       configs.ClickHouseConfig
+	client       *ClickHouseClient
+	log          *zap.Logger
+	flush        chan struct{}
+	cancel       context.CancelFunc
+	done         sync.WaitGroup
+	mu           sync.Mutex
+	transactions clickHouseBuffer
+	blocks       clickHouseBuffer
+	schemaReady  bool
+	stats        ClickHouseStats
+}
+
+// NewClickHouseSink 创建ClickHouse写入并设置为全局实例
+func NewClickHouseSink(config *configs.ClickHouseConfig) *ClickHouseSink {
+	sink := &ClickHouseSink{
+		config:       *config,
+		client:       NewClickHouseClient(config),
+		log:          logger.Named("export.clickhouse"),
+		flush:        make(chan struct{}, 1),
+		transactions: clickHouseBuffer{table: config.TransactionsTable},
+		blocks:       clickHouseBuffer{table: config.BlocksTable},
+		schemaReady:  !config.CreateSchema,
+	}
+	GlobalClickHouseSink = sink
+	return sink
+}
+
+// Ping 检查ClickHouse是否可用
+func (s *ClickHouseSink) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx)
+}
+
+// Start 订阅事件管道中的交易和区块事件，并启动后台写入
+func (s *ClickHouseSink) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	// 写入在单独的协程中进行，订阅协程只负责加入缓冲，ClickHouse变慢时不会阻塞事件消费
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction, pipeline.EventBlock},
+	})
+
+	s.done.Add(2)
+	go func() {
+		defer s.done.Done()
+		defer unsubscribe()
+		supervisor.Run(ctx, "export.clickhouse", func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					s.handleEvent(event)
+				}
+			}
+		})
+	}()
+	go func() {
+		defer s.done.Done()
+		supervisor.Run(ctx, "export.clickhouse.flush", func(ctx context.Context) {
+			ticker := time.NewTicker(s.config.FlushInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					// 退出前尽量写入剩余的行，http.timeout 为0(不限制)时最多等待 clickHouseCloseTimeout
+					timeout := s.config.HTTP.Timeout
+					if timeout <= 0 {
+						timeout = clickHouseCloseTimeout
+					}
+					flushCtx, cancel := context.WithTimeout(context.Background(), timeout)
+					s.flushAll(flushCtx)
+					cancel()
+					return
+				case <-ticker.C:
+				case <-s.flush:
+				}
+				s.flushAll(ctx)
+			}
+		})
+	}()
+	s.log.Info("ClickHouse写入已启动",
+		zap.String("endpoint", s.config.Endpoint),
+		zap.String("database", s.config.Database),
+		zap.Bool("async_insert", s.config.AsyncInsert))
+}
+
+// Close 停止订阅并写入剩余的行
+func (s *ClickHouseSink) Close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.done.Wait()
+}
+
+// Stats 返回写入统计
+func (s *ClickHouseSink) Stats() ClickHouseStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Buffered = len(s.transactions.rows) + len(s.blocks.rows)
+	return stats
+}
+
+// handleEvent 将事件转换为行加入缓冲，缓冲达到 batch_size 时通知写入
+func (s *ClickHouseSink) handleEvent(event pipeline.Event) {
+	var buffer *clickHouseBuffer
+	var row any
+	switch {
+	case event.Type == pipeline.EventTransaction && event.Transaction != nil:
+		transaction := event.Transaction
+		record := clickHouseTransaction{
+			Signature:       transaction.Signature,
+			Slot:            transaction.Slot,
+			BlockTime:       transaction.Timestamp,
+			Source:          string(transaction.Source),
+			Type:            string(transaction.Type),
+			Fee:             transaction.Fee,
+			FeePayer:        transaction.FeePayer,
+			Description:     transaction.Description,
+			Failed:          transaction.TransactionError != nil,
+			Mints:           transaction.Mints(),
+			NativeTransfers: len(transaction.NativeTransfers),
+			TokenTransfers:  len(transaction.TokenTransfers),
+		}
+		if record.Mints == nil {
+			record.Mints = []string{}
+		}
+		if transaction.Valuation != nil {
+			record.SOLAmount = transaction.Valuation.SOLAmount
+			record.USDValue = transaction.Valuation.USDValue
+		}
+		if s.config.IncludeRaw {
+			if raw, err := json.Marshal(transaction); err == nil {
+				record.Raw = string(raw)
+			}
+		}
+		buffer, row = &s.transactions, record
+	case event.Type == pipeline.EventBlock && event.Block != nil:
+		block := event.Block
+		buffer, row = &s.blocks, clickHouseBlock{
+			Slot:         block.Slot,
+			Blockhash:    block.Blockhash,
+			ParentSlot:   block.ParentSlot,
+			BlockTime:    block.BlockTime,
+			Transactions: block.Transactions,
+			Failed:       block.Failed,
+			Signatures:   block.Signatures,
+			Prefiltered:  block.Prefiltered,
+			ProcessedAt:  event.Time.Unix(),
+		}
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	buffer.rows = append(buffer.rows, row)
+	full := len(buffer.rows) >= s.config.BatchSize
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flushAll 写入两个表缓冲中的行，需要时先创建表结构
+func (s *ClickHouseSink) flushAll(ctx context.Context) {
+	s.mu.Lock()
+	ready := s.schemaReady
+	s.mu.Unlock()
+	if !ready {
+		if err := s.client.EnsureSchema(ctx, clickHouseTables(&s.config), s.config.TTL); err != nil {
+			s.fail(err)
+			s.log.Error("创建ClickHouse表结构失败，等待下次写入时重试", zap.Error(err))
+			s.trim()
+			return
+		}
+		s.mu.Lock()
+		s.schemaReady = true
+		s.mu.Unlock()
+		s.log.Info("ClickHouse表结构已就绪", zap.String("database", s.config.Database))
+	}
+	s.flushBuffer(ctx, &s.transactions, &s.stats.Transactions)
+	s.flushBuffer(ctx, &s.blocks, &s.stats.Blocks)
+}
+
+// flushBuffer 按 batch_size 分批写入一个表的缓冲，失败时剩余的行放回缓冲
+func (s *ClickHouseSink) flushBuffer(ctx context.Context, buffer *clickHouseBuffer, written *int64) {
+	s.mu.Lock()
+	rows := buffer.rows
+	buffer.rows = nil
+	s.mu.Unlock()
+
+	for len(rows) > 0 {
+		batch := rows[:min(len(rows), s.config.BatchSize)]
+		if err := s.client.Insert(ctx, buffer.table, batch); err != nil {
+			s.fail(err)
+			s.log.Error("写入ClickHouse失败，等待下次重试", zap.String("table", buffer.table), zap.Int("rows", len(rows)), zap.Error(err))
+			s.mu.Lock()
+			// 写入期间新加入的行排在失败的行之后
+			buffer.rows = append(rows, buffer.rows...)
+			s.mu.Unlock()
+			s.trim()
+			return
+		}
+		rows = rows[len(batch):]
+		s.mu.Lock()
+		*written += int64(len(batch))
+		s.stats.LastError = ""
+		s.stats.LastFlush = clock.Now()
+		s.mu.Unlock()
+	}
+}
+
+// fail 记录写入失败
+func (s *ClickHouseSink) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Failures++
+	s.stats.LastError = err.Error()
+}
+
+// trim 每个表只保留最近的 max_buffered 行
+func (s *ClickHouseSink) trim() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, buffer := range []*clickHouseBuffer{&s.transactions, &s.blocks} {
+		if excess := len(buffer.rows) - s.config.MaxBuffered; excess > 0 {
+			buffer.rows = append([]any(nil), buffer.rows[excess:]...)
+			s.stats.Dropped += int64(excess)
+			s.log.Warn("ClickHouse写入持续失败，丢弃最早的行", zap.String("table", buffer.table), zap.Int("dropped", excess))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// ClickHouseColumn ClickHouse表的列
+type ClickHouseColumn struct {
+	Name string
+	Type string // 列类型，可包含 DEFAULT 和 CODEC 子句
+}
+
+// ClickHouseTable ClickHouse表结构，新版本只追加列，已存在的表通过 ADD COLUMN IF NOT EXISTS 补齐
+type ClickHouseTable struct {
+	Name        string
+	Columns     []ClickHouseColumn
+	Engine      string // 表引擎，如 ReplacingMergeTree
+	PartitionBy string // 分区表达式
+	OrderBy     string // 排序键
+	TTLColumn   string // 按该DateTime列计算数据保留时长
+}
+
+// ClickHouseClient 通过HTTP接口访问ClickHouse
+type ClickHouseClient struct {
+	httpClient         *http.Client
+	endpoint           string
+	database           string
+	username           string
+	password           string
+	asyncInsert        bool
+	waitForAsyncInsert bool
+}
+
+// NewClickHouseClient 创建ClickHouse客户端，按 http 和 proxy_url 设置连接池、超时和代理
+func NewClickHouseClient(config *configs.ClickHouseConfig) *ClickHouseClient {
+	return &ClickHouseClient{
+		httpClient:         rpc.NewHTTPClient(&config.HTTP, config.ProxyURL),
+		endpoint:           strings.TrimRight(config.Endpoint, "/"),
+		database:           config.Database,
+		username:           config.Username,
+		password:           config.Password,
+		asyncInsert:        config.AsyncInsert,
+		waitForAsyncInsert: config.WaitForAsyncInsert,
+	}
+}
+
+// Ping 检查ClickHouse是否可用
+func (c *ClickHouseClient) Ping(ctx context.Context) error {
+	return c.Exec(ctx, "SELECT 1")
+}
+
+// Exec 执行不返回数据的语句
+func (c *ClickHouseClient) Exec(ctx context.Context, query string) error {
+	return c.do(ctx, url.Values{"query": {query}}, nil)
+}
+
+// Insert 以JSONEachRow格式批量写入
+// 参数:
+//   - ctx: 上下文
+//   - table: 表名
+//   - rows: 行数据，每行序列化为一个JSON对象，字段名与列名一致
+//
+// 返回:
+//   - error: 错误信息
+func (c *ClickHouseClient) Insert(ctx context.Context, table string, rows []any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("序列化写入数据失败: %w", err)
+		}
+	}
+	params := url.Values{
+		"query":    {fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", c.database, table)},
+		"database": {c.database},
+		// 忽略表中有而数据中没有的列，使用列的默认值
+		"input_format_skip_unknown_fields": {"1"},
+	}
+	if c.asyncInsert {
+		params.Set("async_insert", "1")
+		params.Set("wait_for_async_insert", boolSetting(c.waitForAsyncInsert))
+	}
+	return c.do(ctx, params, &body)
+}
+
+// EnsureSchema 创建数据库和表，已存在的表补齐缺少的列
+// 参数:
+//   - ctx: 上下文
+//   - tables: 表结构
+//   - ttl: 数据保留时长，只在创建表时设置，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (c *ClickHouseClient) EnsureSchema(ctx context.Context, tables []ClickHouseTable, ttl time.Duration) error {
+	if err := c.Exec(ctx, "CREATE DATABASE IF NOT EXISTS "+c.database); err != nil {
+		return fmt.Errorf("创建数据库 %s 失败: %w", c.database, err)
+	}
+	for _, table := range tables {
+		name := c.database + "." + table.Name
+		columns := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			columns[i] = column.Name + " " + column.Type
+		}
+		query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n) ENGINE = %s\nPARTITION BY %s\nORDER BY %s",
+			name, strings.Join(columns, ",\n  "), table.Engine, table.PartitionBy, table.OrderBy)
+		if ttl > 0 && table.TTLColumn != "" {
+			query += fmt.Sprintf("\nTTL %s + INTERVAL %d SECOND", table.TTLColumn, int64(ttl.Seconds()))
+		}
+		if err := c.Exec(ctx, query); err != nil {
+			return fmt.Errorf("创建表 %s 失败: %w", name, err)
+		}
+		for _, column := range columns {
+			if err := c.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", name, column)); err != nil {
+				return fmt.Errorf("为表 %s 补齐列失败: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// do 发送请求，语句放在URL参数中，body为写入的数据
+func (c *ClickHouseClient) do(ctx context.Context, params url.Values, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/?"+params.Encode(), body)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("X-ClickHouse-User", c.username)
+	if c.password != "" {
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求ClickHouse失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("ClickHouse返回错误(HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// boolSetting 将布尔值转换为ClickHouse设置的取值
+func boolSetting(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,266 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+// ManifestFile 每个输出目录下的清单文件名，记录所有已完成的文件
+const ManifestFile = "manifest.json"
+
+// ErrEmptyFile 文件中没有任何记录，不会生成输出文件
+var ErrEmptyFile = errors.New("文件中没有记录")
+
+// fileNamePattern 输出文件名格式: <数据集>-v<结构版本>-<起始槽位>-<结束槽位>.<扩展名>
+var fileNamePattern = regexp.MustCompile(`^(.+)-v(\d+)-(\d{12})-(\d{12})(\..+)$`)
+
+// manifestMu 串行化同一进程内对清单文件的修改
+var manifestMu sync.Mutex
+
+// FileInfo 已完成的输出文件
+type FileInfo struct {
+	Name          string `json:"name"`           // 文件名
+	Dataset       string `json:"dataset"`        // 数据集名称
+	SchemaVersion int    `json:"schema_version"` // 记录结构版本
+	FromSlot      uint64 `json:"from_slot"`      // 文件中最小的槽位
+	ToSlot        uint64 `json:"to_slot"`        // 文件中最大的槽位
+	Records       int64  `json:"records"`        // 记录数
+	Bytes         int64  `json:"bytes"`          // 文件大小
+	SHA256        string `json:"sha256"`         // 文件内容的SHA-256
+	CreatedAt     int64  `json:"created_at"`     // 完成时间(Unix时间戳)
+}
+
+// Manifest 输出目录的清单，下游批量加载时只读取清单中列出的文件
+type Manifest struct {
+	Files     []FileInfo `json:"files"`      // 已完成的文件，按文件名排序
+	UpdatedAt int64      `json:"updated_at"` // 最近更新时间(Unix时间戳)
+}
+
+// FileName 生成包含槽位范围和结构版本的文件名，槽位补齐到12位以便按名称排序
+// 参数:
+//   - dataset: 数据集名称，如 raw_transactions
+//   - schemaVersion: 记录结构版本
+//   - fromSlot: 起始槽位
+//   - toSlot: 结束槽位
+//   - ext: 扩展名，包含点号，如 .jsonl.gz
+//
+// 返回:
+//   - string: 文件名，如 raw_transactions-v1-000250000000-000250000999.jsonl.gz
+func FileName(dataset string, schemaVersion int, fromSlot, toSlot uint64, ext string) string {
+	return fmt.Sprintf("%s-v%d-%012d-%012d%s", dataset, schemaVersion, fromSlot, toSlot, ext)
+}
+
+// ParseFileName 从文件名中解析数据集、结构版本和槽位范围，格式不符时返回false
+func ParseFileName(name string) (FileInfo, bool) {
+	match := fileNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return FileInfo{}, false
+	}
+	schemaVersion, _ := strconv.Atoi(match[2])
+	fromSlot, _ := strconv.ParseUint(match[3], 10, 64)
+	toSlot, _ := strconv.ParseUint(match[4], 10, 64)
+	return FileInfo{Name: name, Dataset: match[1], SchemaVersion: schemaVersion, FromSlot: fromSlot, ToSlot: toSlot}, true
+}
+
+// File 正在写入的输出文件
+// 内容先写入同目录下以点号开头的临时文件，Commit 时按实际槽位范围重命名并登记到清单，
+// 下游只要忽略点号开头的文件或只读取清单，就不会读到写了一半的数据
+type File struct {
+	dir           string
+	dataset       string
+	schemaVersion int
+	ext           string
+	tmp           *os.File
+	hash          hash.Hash
+	writer        io.Writer
+	bytes         int64
+	records       int64
+	fromSlot      uint64
+	toSlot        uint64
+}
+
+// Create 在目录中创建输出文件
+// 参数:
+//   - dir: 输出目录，不存在时自动创建
+//   - dataset: 数据集名称
+//   - schemaVersion: 记录结构版本
+//   - ext: 扩展名，包含点号
+//
+// 返回:
+//   - *File: 输出文件
+//   - error: 错误信息
+func Create(dir, dataset string, schemaVersion int, ext string) (*File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+dataset+"-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	file := &File{
+		dir:           dir,
+		dataset:       dataset,
+		schemaVersion: schemaVersion,
+		ext:           ext,
+		tmp:           tmp,
+		hash:          sha256.New(),
+	}
+	file.writer = io.MultiWriter(tmp, file.hash)
+	return file, nil
+}
+
+// Write 写入文件内容，调用方负责记录格式，每条记录写完后调用 Observe
+func (f *File) Write(p []byte) (int, error) {
+	n, err := f.writer.Write(p)
+	f.bytes += int64(n)
+	return n, err
+}
+
+// Observe 登记一条已写入的记录及其槽位，用于统计记录数和槽位范围
+func (f *File) Observe(slot uint64) {
+	if f.records == 0 || slot < f.fromSlot {
+		f.fromSlot = slot
+	}
+	if f.records == 0 || slot > f.toSlot {
+		f.toSlot = slot
+	}
+	f.records++
+}
+
+// Records 返回已登记的记录数
+func (f *File) Records() int64 {
+	return f.records
+}
+
+// SlotRange 返回已登记记录的槽位范围
+func (f *File) SlotRange() (uint64, uint64) {
+	return f.fromSlot, f.toSlot
+}
+
+// Commit 将临时文件落盘后重命名为正式文件名并登记到清单
+// 没有任何记录时删除临时文件并返回 ErrEmptyFile
+func (f *File) Commit() (FileInfo, error) {
+	if f.records == 0 {
+		f.Abort()
+		return FileInfo{}, ErrEmptyFile
+	}
+	if err := f.tmp.Sync(); err != nil {
+		f.Abort()
+		return FileInfo{}, fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	if err := f.tmp.Close(); err != nil {
+		os.Remove(f.tmp.Name())
+		return FileInfo{}, fmt.Errorf("写入输出文件失败: %w", err)
+	}
+
+	info := FileInfo{
+		Name:          FileName(f.dataset, f.schemaVersion, f.fromSlot, f.toSlot, f.ext),
+		Dataset:       f.dataset,
+		SchemaVersion: f.schemaVersion,
+		FromSlot:      f.fromSlot,
+		ToSlot:        f.toSlot,
+		Records:       f.records,
+		Bytes:         f.bytes,
+		SHA256:        hex.EncodeToString(f.hash.Sum(nil)),
+		CreatedAt:     clock.Now().Unix(),
+	}
+	path := filepath.Join(f.dir, info.Name)
+	if _, err := os.Stat(path); err == nil {
+		// 相同槽位范围的文件已存在(如回补)，追加序号避免覆盖
+		for i := 1; ; i++ {
+			name := strings.TrimSuffix(info.Name, f.ext) + "." + strconv.Itoa(i) + f.ext
+			if _, err := os.Stat(filepath.Join(f.dir, name)); errors.Is(err, os.ErrNotExist) {
+				info.Name, path = name, filepath.Join(f.dir, name)
+				break
+			}
+		}
+	}
+	if err := os.Rename(f.tmp.Name(), path); err != nil {
+		os.Remove(f.tmp.Name())
+		return FileInfo{}, fmt.Errorf("重命名输出文件失败: %w", err)
+	}
+	if err := addToManifest(f.dir, info); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// Abort 放弃写入并删除临时文件
+func (f *File) Abort() {
+	f.tmp.Close()
+	os.Remove(f.tmp.Name())
+}
+
+// ReadManifest 读取输出目录的清单，清单不存在时返回空清单
+func ReadManifest(dir string) (Manifest, error) {
+	manifest := Manifest{Files: []FileInfo{}}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, fmt.Errorf("读取清单失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("解析清单失败: %w", err)
+	}
+	return manifest, nil
+}
+
+// addToManifest 将文件登记到清单，清单同样先写临时文件再重命名
+func addToManifest(dir string, info FileInfo) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, info)
+	slices.SortFunc(manifest.Files, func(a, b FileInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	manifest.UpdatedAt = clock.Now().Unix()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化清单失败: %w", err)
+	}
+	return WriteFileAtomic(filepath.Join(dir, ManifestFile), data)
+}
+
+// WriteFileAtomic 先写入同目录下的临时文件并落盘，再重命名为目标文件，读取方不会看到不完整的内容
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("重命名文件失败: %w", err)
+	}
+	return nil
+}
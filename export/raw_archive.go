@@ -0,0 +1,107 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/life2you/datas-go/configs"
+	"go.uber.org/zap"
+)
+
+const (
+	// RawArchiveDataset 原始响应归档文件的数据集名称
+	RawArchiveDataset = "raw_transactions"
+	// RawArchiveSchemaVersion 原始响应归档记录的结构版本，RawArchiveRecord 变化时递增
+	RawArchiveSchemaVersion = 1
+)
+
+// GlobalRawArchive 原始响应归档文件输出，未配置 raw_archive.dir 时为nil
+var GlobalRawArchive *RollingWriter
+
+// RawArchiveRecord 归档文件中的一行记录
+type RawArchiveRecord struct {
+	Signature string          `json:"signature"` // 交易签名
+	Slot      uint64          `json:"slot"`      // 所属区块高度
+	Raw       json.RawMessage `json:"raw"`       // Enhanced API原始JSON
+}
+
+// NewRawArchive 创建原始响应归档文件输出并设置为全局实例
+func NewRawArchive(config *configs.RawArchiveConfig) *RollingWriter {
+	GlobalRawArchive = NewRollingWriter(config.Dir, RawArchiveDataset, RawArchiveSchemaVersion, config.Compress, config.FileSlots, config.FileMaxAge)
+	return GlobalRawArchive
+}
+
+// ArchiveRawTransaction 将原始响应写入归档文件，未启用文件输出时直接返回
+func ArchiveRawTransaction(slot uint64, signature string, raw json.RawMessage) {
+	if GlobalRawArchive == nil {
+		return
+	}
+	record := RawArchiveRecord{Signature: signature, Slot: slot, Raw: raw}
+	if err := GlobalRawArchive.Write(slot, record); err != nil {
+		GlobalRawArchive.log.Error("写入原始响应归档文件失败",
+			zap.String("signature", signature),
+			zap.Uint64("区块", slot),
+			zap.Error(err))
+	}
+}
+
+// ReadRawArchive 按文件名顺序读取归档目录清单中与槽位范围有交集的文件，逐条回调其中的记录
+// 参数:
+//   - dir: 归档目录
+//   - fromSlot: 起始槽位(包含)
+//   - toSlot: 结束槽位(包含)，0表示不限制
+//   - fn: 记录回调，返回错误时停止读取
+//
+// 返回:
+//   - error: 错误信息
+func ReadRawArchive(dir string, fromSlot, toSlot uint64, fn func(RawArchiveRecord) error) error {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range manifest.Files {
+		if info.Dataset != RawArchiveDataset || info.ToSlot < fromSlot || (toSlot > 0 && info.FromSlot > toSlot) {
+			continue
+		}
+		if err := readRawArchiveFile(filepath.Join(dir, info.Name), fn); err != nil {
+			return fmt.Errorf("%s: %w", info.Name, err)
+		}
+	}
+	return nil
+}
+
+// readRawArchiveFile 读取单个归档文件，按扩展名判断是否gzip压缩
+func readRawArchiveFile(path string, fn func(RawArchiveRecord) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开归档文件失败: %w", err)
+	}
+	defer file.Close()
+	var in io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("读取归档文件失败: %w", err)
+		}
+		defer reader.Close()
+		in = reader
+	}
+	decoder := json.NewDecoder(in)
+	for {
+		var record RawArchiveRecord
+		if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("解析归档记录失败: %w", err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
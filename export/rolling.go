@@ -0,0 +1,183 @@
+package export
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/supervisor"
+	"go.uber.org/zap"
+)
+
+// RollingWriter 按槽位跨度和时长滚动的JSONL输出，每个文件完成后原子地重命名并登记到清单
+type RollingWriter struct {
+	mu            sync.Mutex
+	dir           string
+	dataset       string
+	schemaVersion int
+	compress      bool
+	fileSlots     uint64
+	maxAge        time.Duration
+	file          *File
+	gzip          *gzip.Writer
+	encoder       *json.Encoder
+	openedAt      time.Time
+	closed        bool
+	log           *zap.Logger
+	cancel        context.CancelFunc
+}
+
+// NewRollingWriter 创建滚动输出
+// 参数:
+//   - dir: 输出目录
+//   - dataset: 数据集名称，作为文件名前缀
+//   - schemaVersion: 记录结构版本，记录格式变化时递增
+//   - compress: 是否使用gzip压缩
+//   - fileSlots: 单个文件覆盖的最大槽位跨度
+//   - maxAge: 单个文件的最长写入时间，到期后即使槽位跨度未满也会完成
+//
+// 返回:
+//   - *RollingWriter: 滚动输出
+func NewRollingWriter(dir, dataset string, schemaVersion int, compress bool, fileSlots uint64, maxAge time.Duration) *RollingWriter {
+	return &RollingWriter{
+		dir:           dir,
+		dataset:       dataset,
+		schemaVersion: schemaVersion,
+		compress:      compress,
+		fileSlots:     fileSlots,
+		maxAge:        maxAge,
+		log:           logger.Named("export").With(zap.String("dataset", dataset)),
+	}
+}
+
+// Start 定期完成写入时间超过 maxAge 的文件，使低流量时下游也能及时读到数据
+func (w *RollingWriter) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	supervisor.Go(ctx, "export.rolling", func(ctx context.Context) {
+		ticker := time.NewTicker(w.maxAge / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				w.mu.Lock()
+				if w.file != nil && now.Sub(w.openedAt) >= w.maxAge {
+					w.commit()
+				}
+				w.mu.Unlock()
+			}
+		}
+	})
+}
+
+// Close 停止定时器并完成当前文件，之后写入的记录会被丢弃
+func (w *RollingWriter) Close() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commit()
+	w.closed = true
+}
+
+// Write 写入一条记录，记录所在槽位超出当前文件的槽位跨度时先完成当前文件
+// 参数:
+//   - slot: 记录所属的槽位
+//   - record: 记录内容，序列化为一行JSON
+//
+// 返回:
+//   - error: 错误信息
+func (w *RollingWriter) Write(slot uint64, record any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("输出已关闭")
+	}
+	if w.file != nil {
+		fromSlot, toSlot := w.file.SlotRange()
+		if max(toSlot, slot)-min(fromSlot, slot) >= w.fileSlots {
+			w.commit()
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if err := w.encoder.Encode(record); err != nil {
+		// 写入失败的文件无法保证完整，直接放弃
+		w.abort()
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	w.file.Observe(slot)
+	return nil
+}
+
+// open 创建新的临时文件
+func (w *RollingWriter) open() error {
+	ext := ".jsonl"
+	if w.compress {
+		ext += ".gz"
+	}
+	file, err := Create(w.dir, w.dataset, w.schemaVersion, ext)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = file
+	w.gzip = nil
+	if w.compress {
+		w.gzip = gzip.NewWriter(file)
+		out = w.gzip
+	}
+	w.file = file
+	w.encoder = json.NewEncoder(out)
+	w.openedAt = clock.Now()
+	return nil
+}
+
+// commit 完成当前文件，调用方需持有锁
+func (w *RollingWriter) commit() {
+	if w.file == nil {
+		return
+	}
+	file := w.file
+	w.file = nil
+	if w.gzip != nil {
+		if err := w.gzip.Close(); err != nil {
+			file.Abort()
+			w.log.Error("写入输出文件失败", zap.Error(err))
+			return
+		}
+	}
+	info, err := file.Commit()
+	if errors.Is(err, ErrEmptyFile) {
+		return
+	}
+	if err != nil {
+		w.log.Error("完成输出文件失败", zap.String("file", info.Name), zap.Error(err))
+		return
+	}
+	w.log.Info("输出文件已完成",
+		zap.String("file", info.Name),
+		zap.Int64("records", info.Records),
+		zap.Int64("bytes", info.Bytes))
+}
+
+// abort 放弃当前文件，调用方需持有锁
+func (w *RollingWriter) abort() {
+	if w.file == nil {
+		return
+	}
+	w.file.Abort()
+	w.file = nil
+}
@@ -0,0 +1,256 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+const (
+	// TransactionsDataset 解析结果导出文件的数据集名称
+	TransactionsDataset = "transactions"
+	// TransactionsSchemaVersion 解析结果导出记录的结构版本，TransactionRow 变化时递增
+	TransactionsSchemaVersion = 1
+)
+
+// Format 导出文件格式
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// TransactionRow 导出文件中的一行解析结果，展开为便于数仓加载的平铺列
+type TransactionRow struct {
+	Signature      string  `parquet:"signature"`        // 交易签名
+	Slot           uint64  `parquet:"slot"`             // 所属槽位
+	Timestamp      int64   `parquet:"timestamp"`        // 区块时间(Unix时间戳)
+	Source         string  `parquet:"source"`           // 交易来源
+	Type           string  `parquet:"type"`             // 交易类型
+	Fee            int64   `parquet:"fee"`              // 手续费(lamports)
+	FeePayer       string  `parquet:"fee_payer"`        // 手续费支付者
+	Description    string  `parquet:"description"`      // Helius生成的交易描述
+	Failed         bool    `parquet:"failed"`           // 交易是否执行失败
+	Mints          string  `parquet:"mints"`            // 代币转账涉及的代币，逗号分隔
+	NativeTransfer int32   `parquet:"native_transfers"` // SOL转账笔数
+	TokenTransfer  int32   `parquet:"token_transfers"`  // 代币转账笔数
+	SOLAmount      float64 `parquet:"sol_amount"`       // swap的SOL成交量，未计价时为0
+	USDValue       float64 `parquet:"usd_value"`        // swap的美元价值，未计价时为0
+	Raw            string  `parquet:"raw"`              // 完整的解析结果JSON，未要求导出时为空
+}
+
+// transactionColumns CSV文件的表头，与 TransactionRow 的字段顺序一致
+var transactionColumns = []string{
+	"signature", "slot", "timestamp", "source", "type", "fee", "fee_payer", "description",
+	"failed", "mints", "native_transfers", "token_transfers", "sol_amount", "usd_value", "raw",
+}
+
+// NewTransactionRow 将解析结果转换为导出记录
+// 参数:
+//   - transaction: 解析结果
+//   - raw: 解析结果的原始JSON，为nil时不导出 raw 列
+//
+// 返回:
+//   - TransactionRow: 导出记录
+func NewTransactionRow(transaction *resp.ParsedTransaction, raw json.RawMessage) TransactionRow {
+	row := TransactionRow{
+		Signature:      transaction.Signature,
+		Slot:           transaction.Slot,
+		Timestamp:      transaction.Timestamp,
+		Source:         string(transaction.Source),
+		Type:           string(transaction.Type),
+		Fee:            transaction.Fee,
+		FeePayer:       transaction.FeePayer,
+		Description:    transaction.Description,
+		Failed:         transaction.TransactionError != nil,
+		Mints:          strings.Join(transaction.Mints(), ","),
+		NativeTransfer: int32(len(transaction.NativeTransfers)),
+		TokenTransfer:  int32(len(transaction.TokenTransfers)),
+		Raw:            string(raw),
+	}
+	if transaction.Valuation != nil {
+		row.SOLAmount = transaction.Valuation.SOLAmount
+		row.USDValue = transaction.Valuation.USDValue
+	}
+	return row
+}
+
+// csvRecord 返回记录在CSV文件中的各列
+func (r TransactionRow) csvRecord() []string {
+	return []string{
+		r.Signature,
+		strconv.FormatUint(r.Slot, 10),
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Source,
+		r.Type,
+		strconv.FormatInt(r.Fee, 10),
+		r.FeePayer,
+		r.Description,
+		strconv.FormatBool(r.Failed),
+		r.Mints,
+		strconv.FormatInt(int64(r.NativeTransfer), 10),
+		strconv.FormatInt(int64(r.TokenTransfer), 10),
+		strconv.FormatFloat(r.SOLAmount, 'f', -1, 64),
+		strconv.FormatFloat(r.USDValue, 'f', -1, 64),
+		r.Raw,
+	}
+}
+
+// partitionFile 一个分区正在写入的文件
+type partitionFile struct {
+	file    *File
+	gzip    *gzip.Writer
+	csv     *csv.Writer
+	parquet *parquet.GenericWriter[TransactionRow]
+}
+
+// PartitionedWriter 按区块日期和交易类型分区写入解析结果，每个分区一个文件，
+// 目录结构为 <输出目录>/date=<YYYY-MM-DD>/type=<交易类型>/，DuckDB、ClickHouse等可按Hive分区读取
+// 文件在 Close 时完成并登记到所在分区目录的清单
+type PartitionedWriter struct {
+	dir      string
+	format   Format
+	compress bool
+	files    map[string]*partitionFile // 分区目录 -> 正在写入的文件
+}
+
+// NewPartitionedWriter 创建分区输出
+// 参数:
+//   - dir: 输出目录
+//   - format: 文件格式
+//   - compress: CSV文件是否使用gzip压缩，Parquet文件始终按列压缩
+//
+// 返回:
+//   - *PartitionedWriter: 分区输出
+//   - error: 格式不支持时的错误信息
+func NewPartitionedWriter(dir string, format Format, compress bool) (*PartitionedWriter, error) {
+	if format != FormatCSV && format != FormatParquet {
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	return &PartitionedWriter{
+		dir:      dir,
+		format:   format,
+		compress: compress,
+		files:    make(map[string]*partitionFile),
+	}, nil
+}
+
+// Write 将记录写入所属分区的文件，区块时间为0的记录归入 date=unknown
+func (w *PartitionedWriter) Write(row TransactionRow) error {
+	date := "unknown"
+	if row.Timestamp > 0 {
+		date = time.Unix(row.Timestamp, 0).UTC().Format(time.DateOnly)
+	}
+	transactionType := row.Type
+	if transactionType == "" {
+		transactionType = string(resp.TransactionTypeUnknown)
+	}
+	dir := filepath.Join(w.dir, "date="+date, "type="+transactionType)
+	partition, ok := w.files[dir]
+	if !ok {
+		var err error
+		if partition, err = w.open(dir); err != nil {
+			return err
+		}
+		w.files[dir] = partition
+	}
+	var err error
+	if partition.parquet != nil {
+		_, err = partition.parquet.Write([]TransactionRow{row})
+	} else {
+		err = partition.csv.Write(row.csvRecord())
+	}
+	if err != nil {
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	partition.file.Observe(row.Slot)
+	return nil
+}
+
+// open 在分区目录中创建文件并写入CSV表头
+func (w *PartitionedWriter) open(dir string) (*partitionFile, error) {
+	ext := "." + string(w.format)
+	if w.format == FormatCSV && w.compress {
+		ext += ".gz"
+	}
+	file, err := Create(dir, TransactionsDataset, TransactionsSchemaVersion, ext)
+	if err != nil {
+		return nil, err
+	}
+	partition := &partitionFile{file: file}
+	if w.format == FormatParquet {
+		partition.parquet = parquet.NewGenericWriter[TransactionRow](file, parquet.Compression(&parquet.Zstd))
+		return partition, nil
+	}
+	var out io.Writer = file
+	if w.compress {
+		partition.gzip = gzip.NewWriter(file)
+		out = partition.gzip
+	}
+	partition.csv = csv.NewWriter(out)
+	if err := partition.csv.Write(transactionColumns); err != nil {
+		file.Abort()
+		return nil, fmt.Errorf("写入表头失败: %w", err)
+	}
+	return partition, nil
+}
+
+// Close 完成所有分区的文件
+// 返回:
+//   - []FileInfo: 已完成的文件，Name 为相对输出目录的路径
+//   - error: 第一个完成失败的错误信息，其余分区仍会完成
+func (w *PartitionedWriter) Close() ([]FileInfo, error) {
+	var (
+		files    []FileInfo
+		firstErr error
+	)
+	for dir, partition := range w.files {
+		info, err := partition.commit()
+		if errors.Is(err, ErrEmptyFile) {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", dir, err)
+			}
+			continue
+		}
+		if rel, err := filepath.Rel(w.dir, filepath.Join(dir, info.Name)); err == nil {
+			info.Name = rel
+		}
+		files = append(files, info)
+	}
+	clear(w.files)
+	return files, firstErr
+}
+
+// commit 刷新格式写入器的缓冲后完成文件
+func (p *partitionFile) commit() (FileInfo, error) {
+	var err error
+	if p.parquet != nil {
+		err = p.parquet.Close()
+	} else {
+		p.csv.Flush()
+		err = p.csv.Error()
+		if err == nil && p.gzip != nil {
+			err = p.gzip.Close()
+		}
+	}
+	if err != nil {
+		p.file.Abort()
+		return FileInfo{}, fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	return p.file.Commit()
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/export"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/storage"
+)
+
+// exportBatchSize 每次从解析结果缓存读取的签名数
+const exportBatchSize = 500
+
+// exportOptions 导出命令的参数
+type exportOptions struct {
+	fromSlot, toSlot uint64
+	since, until     time.Time // 区块时间范围，零值表示不限制；until 不包含
+	types, sources   []string
+	archiveDir       string // 非空时从原始响应归档读取，否则按交易索引从解析结果缓存读取
+	includeRaw       bool
+}
+
+// exportStats 导出统计
+type exportStats struct {
+	rows    int64
+	missing int64 // 索引中有、但解析结果缓存中已不存在的交易
+	skipped int64 // 不在时间、类型或来源范围内，或被解析阶段过滤的交易
+}
+
+// newExportCommand 导出解析结果，按日期和交易类型分区写入Parquet或CSV文件
+func newExportCommand() *cobra.Command {
+	var (
+		options      exportOptions
+		since, until string
+		format       string
+		out          string
+		compress     bool
+		fromArchive  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "按槽位或时间范围导出解析结果为Parquet/CSV文件，按日期和交易类型分区",
+		Long: `按槽位或时间范围导出已存储的解析结果，供ClickHouse、DuckDB等数仓批量加载。
+
+默认按交易索引从解析结果缓存(enrichment_cache)读取，缓存已过期的交易会被计为缺失；
+指定 --archive 时改为读取 raw_archive.dir 中的原始响应归档。
+输出目录结构为 <out>/date=<YYYY-MM-DD>/type=<交易类型>/transactions-v1-<起始槽位>-<结束槽位>.<格式>，
+每个分区目录下的 manifest.json 记录已完成的文件。`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if options.since, err = parseExportTime(since, false); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			if options.until, err = parseExportTime(until, true); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+			if options.fromSlot == 0 && options.toSlot == 0 && options.since.IsZero() && options.until.IsZero() {
+				return fmt.Errorf("必须至少指定 --from-slot、--to-slot、--since、--until 中的一个")
+			}
+			if options.toSlot > 0 && options.toSlot < options.fromSlot {
+				return fmt.Errorf("--to-slot 不能小于 --from-slot")
+			}
+			if !options.until.IsZero() && !options.until.After(options.since) {
+				return fmt.Errorf("--until 必须晚于 --since")
+			}
+			if out == "" {
+				return fmt.Errorf("必须指定 --out")
+			}
+			writer, err := export.NewPartitionedWriter(out, export.Format(format), compress)
+			if err != nil {
+				return err
+			}
+
+			loadConfig()
+			if fromArchive {
+				options.archiveDir = configs.GlobalConfig.RawArchive.Dir
+				if options.archiveDir == "" {
+					return fmt.Errorf("未配置 raw_archive.dir，无法从归档导出")
+				}
+			} else {
+				storage.NewRedisClient(&configs.GlobalConfig.Redis)
+				defer storage.CloseRedisClients()
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			stats, exportErr := runExport(ctx, &options, writer)
+			// 中断或出错时已写入的记录仍完成为文件，清单只登记完整的文件
+			files, closeErr := writer.Close()
+			slices.SortFunc(files, func(a, b export.FileInfo) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "文件\t记录数\t大小\t槽位范围")
+			for _, file := range files {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d-%d\n", file.Name, file.Records, file.Bytes, file.FromSlot, file.ToSlot)
+			}
+			w.Flush()
+			fmt.Printf("导出完成: %d 个文件，%d 条记录，跳过 %d 条", len(files), stats.rows, stats.skipped)
+			if options.archiveDir == "" {
+				fmt.Printf("，解析结果缓存中缺失 %d 条", stats.missing)
+			}
+			fmt.Println()
+			if exportErr != nil {
+				return exportErr
+			}
+			return closeErr
+		},
+	}
+	cmd.Flags().Uint64Var(&options.fromSlot, "from-slot", 0, "起始槽位(包含)")
+	cmd.Flags().Uint64Var(&options.toSlot, "to-slot", 0, "结束槽位(包含)，0表示不限制")
+	cmd.Flags().StringVar(&since, "since", "", "起始区块时间(包含)，RFC3339 或 YYYY-MM-DD(UTC)")
+	cmd.Flags().StringVar(&until, "until", "", "结束区块时间(不包含)，RFC3339 或 YYYY-MM-DD(UTC，包含当天)")
+	cmd.Flags().StringSliceVar(&options.types, "types", nil, "只导出这些交易类型，如 SWAP,TRANSFER")
+	cmd.Flags().StringSliceVar(&options.sources, "sources", nil, "只导出这些交易来源，如 PUMP_FUN,RAYDIUM")
+	cmd.Flags().StringVar(&format, "format", string(export.FormatParquet), "文件格式: parquet 或 csv")
+	cmd.Flags().StringVar(&out, "out", "", "输出目录")
+	cmd.Flags().BoolVar(&compress, "compress", false, "CSV文件使用gzip压缩")
+	cmd.Flags().BoolVar(&fromArchive, "archive", false, "从 raw_archive.dir 中的原始响应归档读取")
+	cmd.Flags().BoolVar(&options.includeRaw, "raw", false, "在 raw 列中导出完整的解析结果JSON")
+	return cmd
+}
+
+// parseExportTime 解析RFC3339时间或UTC日期，endOfDay 为true时日期表示当天结束
+func parseExportTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("时间格式应为 RFC3339 或 YYYY-MM-DD: %s", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// runExport 读取范围内的解析结果并写入分区文件
+func runExport(ctx context.Context, options *exportOptions, writer *export.PartitionedWriter) (exportStats, error) {
+	var stats exportStats
+	write := func(raw json.RawMessage) error {
+		var transaction resp.ParsedTransaction
+		if err := json.Unmarshal(raw, &transaction); err != nil {
+			stats.skipped++
+			return nil
+		}
+		if !options.match(&transaction) {
+			stats.skipped++
+			return nil
+		}
+		var rawColumn json.RawMessage
+		if options.includeRaw {
+			rawColumn = raw
+		}
+		if err := writer.Write(export.NewTransactionRow(&transaction, rawColumn)); err != nil {
+			return err
+		}
+		stats.rows++
+		return nil
+	}
+
+	if options.archiveDir != "" {
+		// 回补可能重复归档同一笔交易
+		seen := make(map[string]struct{})
+		err := export.ReadRawArchive(options.archiveDir, options.fromSlot, options.toSlot, func(record export.RawArchiveRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, ok := seen[record.Signature]; ok {
+				return nil
+			}
+			seen[record.Signature] = struct{}{}
+			return write(record.Raw)
+		})
+		return stats, err
+	}
+
+	var fromDay, toDay int64
+	if !options.since.IsZero() {
+		fromDay = storage.IndexDay(options.since.Unix())
+	}
+	if !options.until.IsZero() {
+		toDay = storage.IndexDay(options.until.Add(-time.Second).Unix())
+	}
+	indexes, err := storage.GetRedisClient(storage.WorkloadAnalytics).ListTransactionDayIndexes(ctx, fromDay, toDay)
+	if err != nil {
+		return stats, err
+	}
+	for _, index := range indexes {
+		if !matchName(options.types, index.Type) || !matchName(options.sources, index.Source) {
+			continue
+		}
+		signatures, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetIndexedTransactionsInSlots(ctx, index, options.fromSlot, options.toSlot)
+		if err != nil {
+			return stats, err
+		}
+		for batch := range slices.Chunk(signatures, exportBatchSize) {
+			names := make([]string, len(batch))
+			for i, signature := range batch {
+				names[i] = signature.Signature
+			}
+			cached, err := storage.GetRedisClient(storage.WorkloadCache).GetEnrichedTransactions(ctx, names)
+			if err != nil {
+				return stats, err
+			}
+			for _, name := range names {
+				raw, ok := cached[name]
+				if !ok {
+					stats.missing++
+					continue
+				}
+				if err := write(raw); err != nil {
+					return stats, err
+				}
+			}
+		}
+	}
+	return stats, nil
+}
+
+// match 判断交易是否在导出范围内，解析阶段会被过滤的交易不导出
+func (o *exportOptions) match(transaction *resp.ParsedTransaction) bool {
+	if transaction.Slot < o.fromSlot || (o.toSlot > 0 && transaction.Slot > o.toSlot) {
+		return false
+	}
+	if !o.since.IsZero() && transaction.Timestamp < o.since.Unix() {
+		return false
+	}
+	if !o.until.IsZero() && transaction.Timestamp >= o.until.Unix() {
+		return false
+	}
+	if !matchName(o.types, string(transaction.Type)) || !matchName(o.sources, string(transaction.Source)) {
+		return false
+	}
+	return handler.ParsedTransactionFilterReason(*transaction) == ""
+}
+
+// matchName 名称列表为空或包含该名称(不区分大小写)时返回true
+func matchName(names []string, name string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(names, func(candidate string) bool {
+		return strings.EqualFold(candidate, name)
+	})
+}
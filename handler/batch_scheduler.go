@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/tracing"
+)
+
+// 交易批次调度模式
+const (
+	TransactionSchedulingBlock       = "block"       // 一个区块的所有批次处理完后再处理下一个区块
+	TransactionSchedulingInterleaved = "interleaved" // 多个区块的批次轮流派发，受全局并发上限约束
+)
+
+// transactionBatchSize 每次调用Enhanced API解析的签名数量
+const transactionBatchSize = 50
+
+// inflightBlock 正在交错处理的区块
+type inflightBlock struct {
+	item    models.TransactionQueueModel
+	batches [][]string
+	next    int   // 下一个待派发的批次
+	pending int   // 已派发、尚未完成的批次数
+	err     error // 第一个失败批次的错误
+}
+
+// batchScheduler 交错调度器，在最多 interleave_blocks 个区块之间轮流派发批次，
+// 避免超大区块的几百个批次阻塞后续区块，同时用 max_concurrent_batches 限制同时调用Enhanced API的批次数
+type batchScheduler struct {
+	mu        sync.Mutex
+	maxBlocks int
+	blocks    []*inflightBlock // 仍有批次待派发的区块
+	cursor    int              // 轮转位置
+	inflight  int              // 已取出、尚未全部完成的区块数
+	slots     chan struct{}    // 交错调度的并发令牌
+}
+
+// newBatchScheduler 创建交错调度器
+func newBatchScheduler(config *configs.QueueConfig) *batchScheduler {
+	return &batchScheduler{
+		maxBlocks: config.InterleaveBlocks,
+		slots:     make(chan struct{}, config.MaxConcurrentBatches),
+	}
+}
+
+// batchScheduler 返回处理器的交错调度器，首次使用时按配置创建
+func (h *Handler) batchScheduler() *batchScheduler {
+	h.schedulerOnce.Do(func() {
+		h.scheduler = newBatchScheduler(&configs.GlobalConfig.Queue)
+	})
+	return h.scheduler
+}
+
+// TransactionsInFlight 返回已从交易队列取出、尚未处理完的区块数，仅交错调度时可能大于0
+func (h *Handler) TransactionsInFlight() int {
+	if h.scheduler == nil {
+		return 0
+	}
+	h.scheduler.mu.Lock()
+	defer h.scheduler.mu.Unlock()
+	return h.scheduler.inflight
+}
+
+// dispatchInterleavedBatch 派发一个批次：先获取并发令牌，再从进行中的区块里轮流选取下一个批次，
+// 进行中的区块不足时从交易队列补充。区块的最后一个批次完成后统一判断重试或更新区块状态
+func (h *Handler) dispatchInterleavedBatch(clientCount int) {
+	s := h.batchScheduler()
+	s.slots <- struct{}{}
+
+	block, batch, ok := s.next(h)
+	if !ok {
+		<-s.slots
+		if h.TransactionsInFlight() > 0 {
+			clock.Sleep(100 * time.Millisecond)
+		} else {
+			clock.Sleep(1000 * time.Millisecond)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(tracing.SlotContext(context.Background(), block.item.Slot, block.item.TraceParent), 60*time.Second)
+	// 等待解析调度器的并发令牌和密钥的请求间隔，没有可用的密钥时批次按失败完成
+	parser := h.parseScheduler()
+	clientIndex, err := parser.acquire(ctx, clientCount)
+	if err != nil {
+		cancel()
+		<-s.slots
+		if s.complete(block, err) {
+			h.finishTransactions(block.item, block.err)
+		}
+		return
+	}
+	go func() {
+		defer func() { <-s.slots }()
+		defer cancel()
+		err := h.processTransactionBatch(ctx, clientIndex, block.item.Slot, batch...)
+		parser.release()
+		if s.complete(block, err) {
+			h.finishTransactions(block.item, block.err)
+		}
+	}()
+}
+
+// next 轮流选取下一个待派发的批次
+func (s *batchScheduler) next(h *Handler) (*inflightBlock, []string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.blocks) < s.maxBlocks {
+		item, ok := h.transactions.PopTransactions()
+		if !ok {
+			break
+		}
+		if len(item.Signatures) == 0 {
+			monitor.SetBlockState(item.Slot, models.BlockDone, nil)
+			h.ackTransactions(item)
+			continue
+		}
+		s.blocks = append(s.blocks, &inflightBlock{
+			item:    item,
+			batches: slices.Collect(slices.Chunk(item.Signatures, transactionBatchSize)),
+		})
+		s.inflight++
+	}
+	if len(s.blocks) == 0 {
+		return nil, nil, false
+	}
+
+	index := s.cursor % len(s.blocks)
+	block := s.blocks[index]
+	batch := block.batches[block.next]
+	block.next++
+	block.pending++
+	if block.next == len(block.batches) {
+		// 所有批次已派发，让出位置给下一个区块
+		s.blocks = slices.Delete(s.blocks, index, index+1)
+	} else {
+		index++
+	}
+	s.cursor = index
+	return block, batch, true
+}
+
+// complete 记录批次完成，返回区块是否已全部处理完
+func (s *batchScheduler) complete(block *inflightBlock, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	block.pending--
+	if err != nil && block.err == nil {
+		block.err = err
+	}
+	done := block.pending == 0 && block.next == len(block.batches)
+	if done {
+		s.inflight--
+	}
+	return done
+}
@@ -1,36 +1,66 @@
 package handler
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
-	"strings"
+	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/cursor"
 	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
 	"github.com/life2you/datas-go/models"
 	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/pipeline"
 	"github.com/life2you/datas-go/rpc"
 	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
 // 轮训扫描区块队列
-func StartScanBlockQueue() {
+func (h *Handler) StartScanBlockQueue() {
+	// Redis不可用且策略为pause，或通过管理接口暂停时暂停取出区块，等待恢复
+	if storage.IntakePaused() || blockFetchPaused() {
+		clock.Sleep(time.Second)
+		return
+	}
+
 	// 创建有超时控制的上下文
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
+	// 启用批量请求时一次取出一批区块，在一次HTTP请求中获取
+	batchSize := configs.GlobalConfig.HeliusAPI.BatchSize
+	maxSlots := 3
+	if batchSize > 1 {
+		maxSlots = batchSize
+	}
 	slotList := make([]uint64, 0)
 	for {
 		// 获取最小区块
-		slotAny, _, ok := storage.GlobalBlockQueue.Pop()
+		slot, ok := h.blocks.PopBlock()
 		if !ok {
 			break
 		}
-		slot := slotAny.(uint64)
+		// 启用分布式处理时跳过已由其他实例持有或处理完成的区块
+		if !monitor.AcquireSlot(slot) {
+			continue
+		}
 		slotList = append(slotList, slot)
-		if len(slotList) >= 3 {
+		if len(slotList) >= maxSlots {
 			break
 		}
 	}
@@ -39,40 +69,68 @@ func StartScanBlockQueue() {
 		logger.Info("没有区块需要处理")
 		return
 	}
+	if batchSize > 1 {
+		slotList = h.handleBlockBatch(ctx, slotList)
+	}
 
 	wg := sync.WaitGroup{}
 	for _, slot := range slotList {
 		wg.Add(1)
-		time.Sleep(200 * time.Millisecond)
+		clock.Sleep(200 * time.Millisecond)
 		go func(slot uint64) {
 			defer wg.Done()
-			handleBlock(ctx, slot)
+			// panic的区块停留在拉取状态，由区块处理状态跟踪按卡住重试
+			defer supervisor.Recover("handler.block", nil)
+			h.handleBlock(ctx, slot)
 		}(slot)
 	}
 	wg.Wait()
 }
 
-func handleBlock(ctx context.Context, slot uint64) {
+func (h *Handler) handleBlock(ctx context.Context, slot uint64) {
 	logger.Info("开始处理区块", zap.Uint64("slot", slot))
+	monitor.SetBlockState(slot, models.BlockFetching, nil)
+	if len(h.processPrefetched(ctx, []uint64{slot})) == 0 {
+		return
+	}
 	// 如果报错，则重试
 	var blockResp json.RawMessage
+	var lastErr error
 	i := 0
 	for {
 		if i > 5 {
 			logger.Error("重试5次获取区块数据失败", zap.Uint64("slot", slot))
+			monitor.SetBlockState(slot, models.BlockFailed, errors.New("重试5次获取区块数据失败"))
+			// 加入跳过列表，按更长的间隔继续重试
+			monitor.SkipFailedSlot(slot, cmp.Or(lastErr, errors.New("getBlock返回空结果")))
 			return
 		}
-		innerBlockResp, err := rpc.GlobalHeliusClient.GetBlock(ctx, slot, nil)
-		if err != nil {
+		fetchCtx, span := tracing.Start(tracing.SlotContext(ctx, slot, ""), "block.fetch",
+			attribute.Int64("solana.slot", int64(slot)), attribute.Int("attempt", i+1))
+		innerBlockResp, err := rpc.GlobalHeliusClient.GetBlock(fetchCtx, slot, nil)
+		tracing.End(span, err)
+		switch {
+		case errors.Is(err, rpc.ErrSlotSkipped):
+			skipSlot(slot, err)
+			return
+		case errors.Is(err, rpc.ErrUnauthorized):
+			// 密钥无效时立即重试不会成功；区块状态跟踪不会重试FAILED的区块，
+			// 因此加入跳过列表，按更长的间隔重试，密钥恢复后即可处理
+			logger.Error("获取区块数据被拒绝，请检查API密钥", zap.Uint64("slot", slot), zap.Error(err))
+			monitor.SetBlockState(slot, models.BlockFailed, err)
+			monitor.SkipFailedSlot(slot, err)
+			return
+		case err != nil:
 			i++
+			lastErr = err
 			logger.Error("获取区块数据失败", zap.Uint64("slot", slot), zap.Error(err))
-			time.Sleep(2 * time.Second)
+			clock.Sleep(retryDelay(err, 2*time.Second))
 			continue
 		}
 		if innerBlockResp == nil {
 			i++
 			logger.Info("获取区块失败", zap.Uint64("slot", slot))
-			time.Sleep(2 * time.Second)
+			clock.Sleep(2 * time.Second)
 			continue
 		}
 		if innerBlockResp != nil && len(innerBlockResp) > 0 {
@@ -82,58 +140,278 @@ func handleBlock(ctx context.Context, slot uint64) {
 
 		i++
 	}
+	h.processBlock(slot, blockResp)
+}
+
+// handleBlockBatch 以一次JSON-RPC批量请求获取一批区块并处理，返回需要逐个重新获取的槽位
+// 整个批量请求失败时返回全部槽位，单个区块获取失败时只返回该槽位
+func (h *Handler) handleBlockBatch(ctx context.Context, slots []uint64) []uint64 {
+	for _, slot := range slots {
+		monitor.SetBlockState(slot, models.BlockFetching, nil)
+	}
+	if slots = h.processPrefetched(ctx, slots); len(slots) == 0 {
+		return nil
+	}
+	results, err := rpc.GlobalHeliusClient.BatchGetBlocks(ctx, slots, nil)
+	if err != nil {
+		logger.Error("批量获取区块数据失败，改为逐个获取", zap.Int("区块数", len(slots)), zap.Error(err))
+		if errors.Is(err, rpc.ErrRateLimited) {
+			clock.Sleep(retryDelay(err, 0))
+		}
+		return slots
+	}
+	var retry []uint64
+	for i, result := range results {
+		slot := slots[i]
+		switch {
+		case errors.Is(result.Err, rpc.ErrSlotSkipped):
+			skipSlot(slot, result.Err)
+		case result.Err != nil || len(result.Result) == 0 || string(result.Result) == "null":
+			logger.Warn("批量获取区块失败，改为逐个获取", zap.Uint64("slot", slot), zap.Error(result.Err))
+			retry = append(retry, slot)
+		default:
+			h.processBlock(slot, result.Result)
+		}
+	}
+	logger.Info("批量获取区块完成", zap.Int("区块数", len(slots)), zap.Int("逐个重试", len(retry)))
+	return retry
+}
+
+// retryDelay 返回获取失败后重试前的等待时间，限流时按服务端要求的时间等待
+func retryDelay(err error, fallback time.Duration) time.Duration {
+	if wait, ok := rpc.RetryAfter(err); ok {
+		return wait
+	}
+	return fallback
+}
+
+// skipSlot 槽位被跳过，没有区块，重试也不会成功
+func skipSlot(slot uint64, err error) {
+	logger.Info("槽位被跳过", zap.Uint64("slot", slot))
+	monitor.SetBlockState(slot, models.BlockOrphaned, err)
+	monitor.ResolveSkippedSlot(slot)
+	cursor.Advance(slot)
+}
+
+// processBlock 解析getBlock返回的区块数据，汇总交易签名并推入交易队列
+func (h *Handler) processBlock(slot uint64, blockResp json.RawMessage) {
 	// 解析区块
 	var blockData resp.BlockResp
 	err := json.Unmarshal(blockResp, &blockData)
 	if err != nil {
-		logger.Error("解析区块数据失败", zap.Uint64("slot", slot), zap.Error(err))
+		if payload.Sample("block", strconv.FormatUint(slot, 10), blockResp, err) {
+			logger.Error("解析区块数据失败", zap.Uint64("slot", slot), zap.Error(err))
+		}
+		monitor.SetBlockState(slot, models.BlockFailed, fmt.Errorf("解析区块数据失败: %w", err))
+		monitor.SkipFailedSlot(slot, fmt.Errorf("解析区块数据失败: %w", err))
 		return
 	}
 
 	logger.Info("获取区块成功", zap.Uint64("slot", slot))
 
-	// 收集签名
-	trans := make([]resp.Transactions, 0)
-	for _, transaction := range blockData.Transactions {
-		vote := false
-		if transaction.Meta.LogMessages != nil && len(transaction.Meta.LogMessages) > 0 {
-			for _, logMessage := range transaction.Meta.LogMessages {
-				if strings.Contains(logMessage, "Vote111111111111111111111111111111111111111") {
-					vote = true
-					break
-				}
+	block := newBlockAccumulator(slot)
+	block.blockhash = blockData.Blockhash
+	block.previousBlockhash = blockData.PreviousBlockhash
+	block.add(blockData.Transactions)
+	h.finishBlock(block, blockData.ParentSlot, blockData.BlockTime)
+}
+
+// blockAccumulator 汇总区块中需要解析的交易签名和统计数据
+// 通过getBlock获取的区块一次性加入全部交易，WebSocket分块推送的区块按批加入，不需要保留完整的交易数据
+type blockAccumulator struct {
+	slot               uint64
+	blockhash          string
+	previousBlockhash  string
+	unfinalized        bool // 以 confirmed/processed 承诺级别获取，处理完成后需要检查是否被最终确认
+	signatures         []string
+	total              int    // 非投票交易数
+	failed             int    // 执行失败的非投票交易数
+	votes              int    // 投票交易数
+	fees               uint64 // 所有交易的手续费合计(lamports)
+	computeUnits       uint64 // 所有交易消耗的计算单元合计
+	tokenAccountEvents []parser.TokenAccountEvent
+	computeBudgets     []parser.ComputeBudget
+	transactionFees    []parser.TransactionFee
+	prefiltered        int                       // 被 block_filter 预过滤的交易数
+	decoded            []*resp.ParsedTransaction // 启用 raw_parse 时在本地解码的转账交易
+}
+
+// newBlockAccumulator 创建区块汇总
+func newBlockAccumulator(slot uint64) *blockAccumulator {
+	return &blockAccumulator{slot: slot}
+}
+
+// add 加入一批交易：收集需要解析的签名，同时统计非投票交易的失败比例用于拥堵检测，以及创建和关闭的代币账户
+func (b *blockAccumulator) add(transactions []resp.Transactions) {
+	trans := make([]resp.Transactions, 0, len(transactions))
+	for _, transaction := range transactions {
+		b.tokenAccountEvents = append(b.tokenAccountEvents, parser.DecodeTokenAccountEvents(transaction)...)
+		b.fees += transaction.Meta.Fee
+		b.computeUnits += transaction.Meta.ComputeUnitsConsumed
+		if parser.IsVoteTransaction(transaction) {
+			b.votes++
+		} else {
+			b.total++
+			if analytics.GlobalPriorityFeeTracker != nil {
+				b.computeBudgets = append(b.computeBudgets, parser.DecodeComputeBudget(transaction))
+			}
+			if analytics.GlobalProgramFeeTracker != nil {
+				b.transactionFees = append(b.transactionFees, parser.DecodeTransactionFee(transaction))
+			}
+			if parser.IsFailedTransaction(transaction) {
+				b.failed++
 			}
 		}
-		if vote {
+		if reason := BlockTransactionFilterReason(transaction); reason != "" {
+			if reason == blockFilterReason {
+				b.prefiltered++
+			}
 			continue
 		}
-		if transaction.Meta.Status.Err.InstructionError != nil && len(transaction.Meta.Status.Err.InstructionError) > 0 {
-			continue
+		if isRawParseTransaction(transaction) {
+			// 区块时间在区块处理完成时补齐
+			if decoded := parser.DecodeTransfer(b.slot, 0, transaction); decoded != nil {
+				b.decoded = append(b.decoded, decoded)
+				continue
+			}
 		}
 		trans = append(trans, transaction)
 	}
 
-	signatures := make([]string, 0)
+	monitor.SampleBlock(b.slot, trans)
 	for _, transaction := range trans {
-		signatures = append(signatures, transaction.Transaction.Signatures...)
+		b.signatures = append(b.signatures, transaction.Transaction.Signatures...)
+	}
+}
+
+// finishBlock 记录区块统计，存储本地解码的交易，并将其余签名推入交易队列
+func (h *Handler) finishBlock(block *blockAccumulator, parentSlot uint64, blockTime int64) {
+	slot := block.slot
+	ctx, span := tracing.Start(tracing.SlotContext(context.Background(), slot, ""), "block.process",
+		attribute.Int64("solana.slot", int64(slot)), attribute.Int("signatures", len(block.signatures)))
+	defer span.End()
+	monitor.RecordBlock(slot, parentSlot, block.total, block.failed)
+	monitor.ResolveSkippedSlot(slot)
+	analytics.RecordTokenAccountEvents(block.tokenAccountEvents)
+	analytics.RecordComputeBudgets(slot, block.computeBudgets)
+	analytics.RecordTransactionFees(blockTime, block.transactionFees)
+	metrics.AddFilteredTransactions(block.prefiltered)
+	if block.unfinalized {
+		signatures := block.signatures
+		for _, transaction := range block.decoded {
+			signatures = append(signatures, transaction.Signature)
+		}
+		monitor.TrackFinality(slot, block.blockhash, blockTime, signatures)
 	}
+	h.indexBlock(block, parentSlot, blockTime)
+	h.summarizeBlock(block, parentSlot, blockTime)
+	h.storeDecodedTransactions(block, blockTime)
 
-	// 将签名存入Redis队列，使用区块高度进行分组
-	if len(signatures) > 0 {
-		// if err := storage.GlobalRedisClient.PushTransactionsForBlock(ctx, slot, signatures); err != nil {
-		// 	logger.Error("将交易签名推送到队列失败", zap.Error(err), zap.Uint64("slot", slot))
-		// 	return
-		// }
+	// 将签名存入交易队列，使用区块高度进行分组
+	if len(block.signatures) > 0 {
 		transactionQueueModel := models.TransactionQueueModel{
-			Signatures: signatures,
-			Slot:       slot,
+			Signatures:  block.signatures,
+			Slot:        slot,
+			BlockTime:   blockTime,
+			TraceParent: tracing.TraceParent(ctx),
+		}
+		monitor.SetBlockState(slot, models.BlockParsing, nil)
+		h.transactions.PushTransactions(transactionQueueModel)
+		// 共享的交易队列由消费者组重新分配未确认的消息，区块租约到此完成
+		if _, shared := h.transactions.(storage.TransactionAcker); shared {
+			monitor.CompleteSlot(slot)
 		}
-		storage.GlobalTransactionQueue.Push(transactionQueueModel, int64(slot))
-		logger.Info("交易签名已推送到区块队列", zap.Int("交易数", len(signatures)), zap.Uint64("slot", slot))
+		logger.Info("交易签名已推送到区块队列", zap.Int("交易数", len(block.signatures)), zap.Uint64("slot", slot))
 	} else {
 		logger.Info("没有有效交易需要解析", zap.Uint64("slot", slot))
+		monitor.SetBlockState(slot, models.BlockDone, nil)
 	}
+	// 没有需要解析的交易的区块同样发布，供区块统计(如ClickHouse的区块表)记录每个区块
+	pipeline.Publish(pipeline.Event{
+		Type:       pipeline.EventBlock,
+		Slot:       slot,
+		Signatures: block.signatures,
+		Block: &models.BlockStats{
+			Slot:         slot,
+			Blockhash:    block.blockhash,
+			ParentSlot:   parentSlot,
+			BlockTime:    blockTime,
+			Transactions: block.total,
+			Failed:       block.failed,
+			Signatures:   len(block.signatures),
+			Prefiltered:  block.prefiltered,
+			Decoded:      len(block.decoded),
+		},
+	})
 
+	cursor.Advance(slot)
+	metrics.BlockProcessed(slot)
 	logger.Info("区块处理完成", zap.Uint64("slot", slot))
+}
+
+// storeDecodedTransactions 存储启用 raw_parse 时在本地解码的转账交易，与Enhanced API的解析结果一样索引并发布交易事件
+func (h *Handler) storeDecodedTransactions(block *blockAccumulator, blockTime int64) {
+	if len(block.decoded) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, transaction := range block.decoded {
+		transaction.Timestamp = blockTime
+		h.storeTransaction(ctx, block.slot, transaction)
+	}
+	metrics.AddDecodedTransactions(len(block.decoded))
+	logger.Info("本地解码的转账交易已存储", zap.Int("交易数", len(block.decoded)), zap.Uint64("slot", block.slot))
+}
+
+// summarizeBlock 启用区块交易汇总时保存区块的交易数、手续费和计算单元合计，写入失败不影响区块处理
+func (h *Handler) summarizeBlock(block *blockAccumulator, parentSlot uint64, blockTime int64) {
+	summaryConfig := configs.GlobalConfig.BlockSummary
+	if !summaryConfig.Enabled {
+		return
+	}
+	summary := models.BlockSummary{
+		Slot:               block.slot,
+		Blockhash:          block.blockhash,
+		ParentSlot:         parentSlot,
+		BlockTime:          blockTime,
+		TotalTransactions:  block.total + block.votes,
+		VoteTransactions:   block.votes,
+		FailedTransactions: block.failed,
+		TotalFees:          block.fees,
+		ComputeUnits:       block.computeUnits,
+		Signatures:         len(block.signatures),
+		Prefiltered:        block.prefiltered,
+		Decoded:            len(block.decoded),
+		ProcessedAt:        clock.Now(),
+	}
+	if summary.TotalTransactions > 0 {
+		summary.VoteRatio = float64(summary.VoteTransactions) / float64(summary.TotalTransactions)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.results.StoreBlockSummary(ctx, summary, summaryConfig.MaxEntries); err != nil {
+		logger.Warn("写入区块交易汇总失败", zap.Uint64("slot", block.slot), zap.Error(err))
+	}
+}
 
+// indexBlock 启用区块哈希索引时记录区块哈希到槽位的映射，写入失败不影响区块处理
+func (h *Handler) indexBlock(block *blockAccumulator, parentSlot uint64, blockTime int64) {
+	indexConfig := configs.GlobalConfig.BlockIndex
+	if !indexConfig.Enabled || block.blockhash == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	meta := models.BlockMeta{
+		Slot:              block.slot,
+		Blockhash:         block.blockhash,
+		PreviousBlockhash: block.previousBlockhash,
+		ParentSlot:        parentSlot,
+		BlockTime:         blockTime,
+		Transactions:      block.total,
+	}
+	if err := h.results.StoreBlockMeta(ctx, meta, indexConfig.TTL); err != nil {
+		logger.Warn("写入区块哈希索引失败", zap.Uint64("slot", block.slot), zap.Error(err))
+	}
 }
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// prefetchRequestTimeout 单个getBlock预取请求的超时
+const prefetchRequestTimeout = 60 * time.Second
+
+// prefetchedBlock 预取的区块，done 关闭后 raw 和 err 可读
+type prefetchedBlock struct {
+	done      chan struct{}
+	raw       json.RawMessage
+	err       error
+	fetchedAt time.Time
+}
+
+// blockPrefetcher 预先查看区块队列中即将取出的区块并发调用getBlock，结果保存在内存中
+type blockPrefetcher struct {
+	config      configs.BlockPrefetchConfig
+	peeker      storage.BlockPeeker
+	requests    chan struct{} // 限制同时进行的请求数
+	lastRequest time.Time
+	pausedUntil time.Time // 被限流后暂停预取到该时间
+
+	mu     sync.Mutex
+	blocks map[uint64]*prefetchedBlock
+	taken  map[uint64]time.Time // 已被处理流程取用的槽位及取用时间，查看与出队之间被取出的槽位不再预取
+
+	log *zap.Logger
+}
+
+// StartBlockPrefetch 按 block_prefetch 配置预取区块队列中即将取出的区块，需要在开始扫描区块队列之前调用
+// 返回预取循环，由调用方在后台运行直到 ctx 取消；区块队列不支持预先查看时不启动，返回nil
+func (h *Handler) StartBlockPrefetch(config *configs.BlockPrefetchConfig) func(ctx context.Context) {
+	log := logger.Named("handler.block_prefetch")
+	peeker, ok := h.blocks.(storage.BlockPeeker)
+	if !ok {
+		log.Warn("区块队列不支持预先查看，不启动区块预取")
+		return nil
+	}
+	p := &blockPrefetcher{
+		config:   *config,
+		peeker:   peeker,
+		requests: make(chan struct{}, config.Concurrency),
+		blocks:   make(map[uint64]*prefetchedBlock),
+		taken:    make(map[uint64]time.Time),
+		log:      log,
+	}
+	h.prefetcher = p
+	log.Info("区块预取已启动", zap.Int("lookahead", config.Lookahead), zap.Int("concurrency", config.Concurrency))
+	return p.run
+}
+
+// run 按检查间隔预取即将取出的区块，并丢弃超过保留时长没有被取用的区块
+func (p *blockPrefetcher) run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		p.prefetch(ctx)
+		p.expire(clock.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prefetch 为即将取出、尚未预取的区块发起getBlock请求，达到并发上限时等待
+func (p *blockPrefetcher) prefetch(ctx context.Context) {
+	// 暂停取出区块时也暂停预取
+	if storage.IntakePaused() || blockFetchPaused() || rpc.GlobalHeliusClient == nil {
+		return
+	}
+	for _, slot := range p.peeker.PeekBlocks(p.config.Lookahead) {
+		if p.has(slot) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case p.requests <- struct{}{}:
+		}
+		p.throttle()
+		block := p.add(slot)
+		if block == nil {
+			<-p.requests
+			continue
+		}
+		go func() {
+			defer func() { <-p.requests }()
+			p.fetch(ctx, slot, block)
+		}()
+	}
+}
+
+// throttle 等待到距上一次请求至少 min_interval，被限流时等待到暂停结束
+func (p *blockPrefetcher) throttle() {
+	p.mu.Lock()
+	next := p.lastRequest.Add(p.config.MinInterval)
+	if p.pausedUntil.After(next) {
+		next = p.pausedUntil
+	}
+	p.mu.Unlock()
+	if wait := next.Sub(clock.Now()); wait > 0 {
+		clock.Sleep(wait)
+	}
+	p.mu.Lock()
+	p.lastRequest = clock.Now()
+	p.mu.Unlock()
+}
+
+// fetch 获取区块并保存结果，被跳过的槽位也保存，取用时直接按跳过处理
+func (p *blockPrefetcher) fetch(ctx context.Context, slot uint64, block *prefetchedBlock) {
+	defer close(block.done)
+	defer supervisor.Recover("handler.block_prefetch.fetch", &block.err)
+
+	requestCtx, cancel := context.WithTimeout(ctx, prefetchRequestTimeout)
+	defer cancel()
+	raw, err := rpc.GlobalHeliusClient.GetBlock(requestCtx, slot, nil)
+	block.raw, block.err, block.fetchedAt = raw, err, clock.Now()
+	if err == nil || errors.Is(err, rpc.ErrSlotSkipped) {
+		metrics.IncPrefetchFetched()
+		return
+	}
+	metrics.IncPrefetchFailed()
+	if errors.Is(err, rpc.ErrRateLimited) {
+		wait := retryDelay(err, time.Second)
+		p.mu.Lock()
+		p.pausedUntil = clock.Now().Add(wait)
+		p.mu.Unlock()
+		p.log.Warn("预取区块被限流，暂停预取", zap.Uint64("slot", slot), zap.Duration("wait", wait))
+		return
+	}
+	p.log.Debug("预取区块失败，取出时重新获取", zap.Uint64("slot", slot), zap.Error(err))
+}
+
+// has 返回槽位是否已预取、正在预取或已被取用
+func (p *blockPrefetcher) has(slot uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.blocks[slot]
+	_, taken := p.taken[slot]
+	return ok || taken
+}
+
+// add 记录正在预取的槽位，已存在或已被取用时返回nil
+// 查看队列之后槽位可能已被出队并由处理流程自行获取，此时再预取会重复调用getBlock
+func (p *blockPrefetcher) add(slot uint64) *prefetchedBlock {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.blocks[slot]; ok {
+		return nil
+	}
+	if _, ok := p.taken[slot]; ok {
+		return nil
+	}
+	block := &prefetchedBlock{done: make(chan struct{})}
+	p.blocks[slot] = block
+	return block
+}
+
+// expire 丢弃超过保留时长没有被取用的区块，如分布式处理时由其他实例取出的区块，同时清理超过保留时长的取用记录
+func (p *blockPrefetcher) expire(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for slot, takenAt := range p.taken {
+		if now.Sub(takenAt) > p.config.TTL {
+			delete(p.taken, slot)
+		}
+	}
+	expired := 0
+	for slot, block := range p.blocks {
+		select {
+		case <-block.done:
+		default:
+			continue
+		}
+		if now.Sub(block.fetchedAt) > p.config.TTL {
+			delete(p.blocks, slot)
+			expired++
+		}
+	}
+	if expired > 0 {
+		metrics.AddPrefetchExpired(expired)
+	}
+}
+
+// take 取出槽位的预取结果，正在预取时等待完成
+// 没有预取或预取失败时返回nil，调用方需要自行获取；槽位被跳过时返回的结果中 err 为 rpc.ErrSlotSkipped
+func (p *blockPrefetcher) take(ctx context.Context, slot uint64) *prefetchedBlock {
+	p.mu.Lock()
+	block, ok := p.blocks[slot]
+	delete(p.blocks, slot)
+	p.taken[slot] = clock.Now()
+	p.mu.Unlock()
+	if !ok {
+		metrics.IncPrefetchMisses()
+		return nil
+	}
+	select {
+	case <-block.done:
+	case <-ctx.Done():
+		metrics.IncPrefetchMisses()
+		return nil
+	}
+	if !errors.Is(block.err, rpc.ErrSlotSkipped) && (block.err != nil || len(block.raw) == 0 || string(block.raw) == "null") {
+		metrics.IncPrefetchMisses()
+		return nil
+	}
+	metrics.IncPrefetchHits()
+	return block
+}
+
+// processPrefetched 处理已预取的区块，返回仍需要获取的槽位
+func (h *Handler) processPrefetched(ctx context.Context, slots []uint64) []uint64 {
+	if h.prefetcher == nil {
+		return slots
+	}
+	remaining := make([]uint64, 0, len(slots))
+	for _, slot := range slots {
+		block := h.prefetcher.take(ctx, slot)
+		switch {
+		case block == nil:
+			remaining = append(remaining, slot)
+		case block.err != nil:
+			skipSlot(slot, block.err)
+		default:
+			h.processBlock(slot, block.raw)
+		}
+	}
+	return remaining
+}
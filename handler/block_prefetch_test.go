@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+func TestBlockPrefetchSkipsTakenSlots(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+	p := &blockPrefetcher{
+		config: configs.BlockPrefetchConfig{TTL: time.Minute},
+		blocks: make(map[uint64]*prefetchedBlock),
+		taken:  make(map[uint64]time.Time),
+		log:    logger.Named("handler.block_prefetch"),
+	}
+
+	// 查看队列后、发起预取前槽位已被出队并由处理流程自行获取，不应再预取
+	if block := p.take(context.Background(), 100); block != nil {
+		t.Fatal("没有预取的槽位应返回nil")
+	}
+	if !p.has(100) || p.add(100) != nil {
+		t.Fatal("已被取用的槽位不应再预取")
+	}
+	if p.add(101) == nil {
+		t.Fatal("未取用的槽位应可以预取")
+	}
+
+	// 取用记录超过保留时长后清理
+	fake.Advance(2 * time.Minute)
+	p.expire(clock.Now())
+	if p.has(100) {
+		t.Fatal("超过保留时长的取用记录应清理")
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/tracing"
+)
+
+// 可在运行时暂停的处理阶段，与采集流程的阶段名一致
+const (
+	ControlBlockFetch = "block_fetch" // 从区块队列取出槽位并获取区块
+	ControlParse      = "parse"       // 从交易队列取出签名并通过Enhanced API解析
+)
+
+// MaxControlBackfillSlots 单次通过管理接口回补的最大槽位数
+const MaxControlBackfillSlots = 100000
+
+// ControlStatus 运行时控制状态
+type ControlStatus struct {
+	BlockFetchPaused bool       `json:"block_fetch_paused"`         // 是否暂停获取区块
+	ParsePaused      bool       `json:"parse_paused"`               // 是否暂停解析交易
+	Draining         bool       `json:"draining"`                   // 是否正在排空队列，期间新槽位不入队
+	DrainStartedAt   *time.Time `json:"drain_started_at,omitempty"` // 开始排空的时间
+	Drained          bool       `json:"drained"`                    // 排空中且区块队列和交易队列都已为空
+	DeferredFrom     uint64     `json:"deferred_from,omitempty"`    // 排空期间收到、推迟入队的最小槽位
+	DeferredTo       uint64     `json:"deferred_to,omitempty"`      // 排空期间收到、推迟入队的最大槽位
+	BlockQueue       int        `json:"block_queue"`                // 区块队列长度
+	TransactionQueue int        `json:"transaction_queue"`          // 交易队列长度
+}
+
+// control 运行时控制状态，供运维在事故期间(如Helius额度耗尽)暂停处理而不重启、不丢失内存队列
+var control struct {
+	mu               sync.Mutex
+	blockFetchPaused bool
+	parsePaused      bool
+	draining         bool
+	drainStartedAt   time.Time
+	deferredFrom     uint64
+	deferredTo       uint64
+}
+
+// controlLog 运行时控制的日志
+var controlLog = logger.Named("handler.control")
+
+// Pause 暂停处理阶段，正在进行的批次会处理完
+// 参数:
+//   - stage: ControlBlockFetch 或 ControlParse
+//
+// 返回:
+//   - error: 阶段名无效时的错误信息
+func Pause(stage string) error {
+	return setPaused(stage, true)
+}
+
+// Resume 恢复处理阶段
+func Resume(stage string) error {
+	return setPaused(stage, false)
+}
+
+// setPaused 设置处理阶段的暂停状态
+func setPaused(stage string, paused bool) error {
+	control.mu.Lock()
+	defer control.mu.Unlock()
+	switch stage {
+	case ControlBlockFetch:
+		control.blockFetchPaused = paused
+	case ControlParse:
+		control.parsePaused = paused
+	default:
+		return fmt.Errorf("无效的阶段: %q，可选值: %s, %s", stage, ControlBlockFetch, ControlParse)
+	}
+	controlLog.Info("处理阶段状态已修改", zap.String("stage", stage), zap.Bool("paused", paused))
+	return nil
+}
+
+// blockFetchPaused 是否暂停获取区块
+func blockFetchPaused() bool {
+	control.mu.Lock()
+	defer control.mu.Unlock()
+	return control.blockFetchPaused
+}
+
+// parsePaused 是否暂停解析交易
+func parsePaused() bool {
+	control.mu.Lock()
+	defer control.mu.Unlock()
+	return control.parsePaused
+}
+
+// StartDrain 开始排空队列：新收到的槽位不再入队，只记录槽位范围，已入队的区块和交易继续处理
+// 队列排空后可安全地重启或修改配置，调用 StopDrain 后推迟的槽位重新入队
+func StartDrain() {
+	control.mu.Lock()
+	defer control.mu.Unlock()
+	if control.draining {
+		return
+	}
+	control.draining = true
+	control.drainStartedAt = clock.Now()
+	controlLog.Info("开始排空队列，新槽位暂不入队")
+}
+
+// StopDrain 停止排空，将排空期间推迟的槽位范围推入区块队列
+// 返回:
+//   - int: 重新入队的槽位数
+func (h *Handler) StopDrain() int {
+	control.mu.Lock()
+	from, to := control.deferredFrom, control.deferredTo
+	wasDraining := control.draining
+	control.draining = false
+	control.drainStartedAt = time.Time{}
+	control.deferredFrom, control.deferredTo = 0, 0
+	control.mu.Unlock()
+	if !wasDraining || from == 0 {
+		return 0
+	}
+	count := h.enqueueSlots(from, to)
+	controlLog.Info("已停止排空，推迟的槽位已重新入队", zap.Uint64("from", from), zap.Uint64("to", to), zap.Int("slots", count))
+	return count
+}
+
+// deferSlot 排空期间记录新收到的槽位，返回false表示未在排空，槽位应正常入队
+func deferSlot(slot uint64) bool {
+	control.mu.Lock()
+	defer control.mu.Unlock()
+	if !control.draining {
+		return false
+	}
+	if control.deferredFrom == 0 || slot < control.deferredFrom {
+		control.deferredFrom = slot
+	}
+	if slot > control.deferredTo {
+		control.deferredTo = slot
+	}
+	return true
+}
+
+// queueBlock 将新收到的槽位推入区块队列，排空期间只记录槽位范围
+func (h *Handler) queueBlock(slot uint64) {
+	if deferSlot(slot) {
+		return
+	}
+	tracing.StartSlot(slot)
+	monitor.SetBlockState(slot, models.BlockQueued, nil)
+	h.blocks.PushBlock(slot)
+}
+
+// Backfill 将槽位范围推入运行中服务的区块队列，由区块获取和交易解析流程处理
+// 参数:
+//   - from: 起始槽位(包含)
+//   - to: 结束槽位(包含)
+//
+// 返回:
+//   - int: 入队的槽位数
+//   - error: 范围无效或超过 MaxControlBackfillSlots 时的错误信息
+func (h *Handler) Backfill(from, to uint64) (int, error) {
+	if from == 0 || to < from {
+		return 0, fmt.Errorf("无效的槽位范围: %d - %d", from, to)
+	}
+	if to-from+1 > MaxControlBackfillSlots {
+		return 0, fmt.Errorf("单次最多回补 %d 个槽位", MaxControlBackfillSlots)
+	}
+	count := h.enqueueSlots(from, to)
+	controlLog.Info("已将回补的槽位推入区块队列", zap.Uint64("from", from), zap.Uint64("to", to), zap.Int("slots", count))
+	return count, nil
+}
+
+// enqueueSlots 将槽位范围推入区块队列，分布式处理时先清除完成标记，避免已处理过的区块被跳过
+func (h *Handler) enqueueSlots(from, to uint64) int {
+	monitor.ResetSlots(from, to)
+	count := 0
+	for slot := from; slot <= to; slot++ {
+		monitor.SetBlockState(slot, models.BlockQueued, nil)
+		h.blocks.PushBlock(slot)
+		count++
+	}
+	return count
+}
+
+// ControlStatus 返回运行时控制状态和处理器的队列长度
+func (h *Handler) ControlStatus() ControlStatus {
+	control.mu.Lock()
+	status := ControlStatus{
+		BlockFetchPaused: control.blockFetchPaused,
+		ParsePaused:      control.parsePaused,
+		Draining:         control.draining,
+		DeferredFrom:     control.deferredFrom,
+		DeferredTo:       control.deferredTo,
+	}
+	if control.draining {
+		startedAt := control.drainStartedAt
+		status.DrainStartedAt = &startedAt
+	}
+	control.mu.Unlock()
+
+	status.BlockQueue = h.blocks.BlockQueueLen()
+	status.TransactionQueue = h.transactions.TransactionQueueLen()
+	status.Drained = status.Draining && status.BlockQueue == 0 && status.TransactionQueue == 0
+	return status
+}
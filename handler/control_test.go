@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/storage"
+)
+
+func TestControlUsesInjectedBlockStore(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	store := storage.NewMemoryStore()
+	h := NewHandler(store, store, store)
+	t.Cleanup(func() { h.StopDrain() })
+
+	count, err := h.Backfill(100, 104)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 || store.BlockQueueLen() != 5 {
+		t.Fatalf("回补入队 %d 个槽位，队列长度 %d，期望都为5", count, store.BlockQueueLen())
+	}
+	if _, err := h.Backfill(10, 9); err == nil {
+		t.Fatal("无效的槽位范围应返回错误")
+	}
+	if _, err := h.Backfill(1, MaxControlBackfillSlots+1); err == nil {
+		t.Fatal("超过单次回补上限应返回错误")
+	}
+
+	// 排空期间新槽位只记录范围，队列处理完后视为已排空
+	StartDrain()
+	h.queueBlock(200)
+	h.queueBlock(198)
+	if store.BlockQueueLen() != 5 {
+		t.Fatalf("排空期间不应入队，队列长度 %d", store.BlockQueueLen())
+	}
+	for {
+		if _, ok := store.PopBlock(); !ok {
+			break
+		}
+	}
+	status := h.ControlStatus()
+	if !status.Draining || !status.Drained || status.DeferredFrom != 198 || status.DeferredTo != 200 {
+		t.Fatalf("排空状态不符合预期: %+v", status)
+	}
+
+	if enqueued := h.StopDrain(); enqueued != 3 {
+		t.Fatalf("停止排空后重新入队 %d 个槽位，期望3", enqueued)
+	}
+	for _, want := range []uint64{198, 199, 200} {
+		if slot, ok := store.PopBlock(); !ok || slot != want {
+			t.Fatalf("出队槽位 %d，期望 %d", slot, want)
+		}
+	}
+	if status := h.ControlStatus(); status.Draining || status.BlockQueue != 0 || status.TransactionQueue != 0 {
+		t.Fatalf("停止排空后的状态不符合预期: %+v", status)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"slices"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/parser"
+)
+
+// 交易被 block_filter 预过滤时的原因
+const blockFilterReason = "未调用关注的程序且未涉及关注的账户"
+
+// BlockTransactionFilterReason 返回区块阶段过滤该交易的原因，返回空字符串表示交易会被推入解析队列
+func BlockTransactionFilterReason(transaction resp.Transactions) string {
+	if parser.IsVoteTransaction(transaction) {
+		return "投票交易"
+	}
+	if parser.IsFailedTransaction(transaction) {
+		return "交易执行失败(InstructionError)"
+	}
+	if !matchesBlockFilter(transaction) {
+		return blockFilterReason
+	}
+	return ""
+}
+
+// matchesBlockFilter 判断交易是否调用 block_filter.programs 中的程序或涉及 block_filter.accounts 中的账户，未启用时总是返回true
+// 读取最新的配置，过滤列表支持热更新
+func matchesBlockFilter(transaction resp.Transactions) bool {
+	cfg := configs.Current()
+	if cfg == nil || !cfg.BlockFilter.Enabled {
+		return true
+	}
+	filter := cfg.BlockFilter
+	if len(filter.Programs) > 0 {
+		for _, program := range parser.InvokedPrograms(transaction) {
+			if slices.ContainsFunc(filter.Programs, func(configured string) bool {
+				return parser.ResolveProgram(configured) == program
+			}) {
+				return true
+			}
+		}
+	}
+	return len(filter.Accounts) > 0 && parser.ReferencesAccount(transaction, filter.Accounts)
+}
+
+// isRawParseTransaction 判断启用 raw_parse 时交易是否只调用 raw_parse.programs 中的程序，可以在本地解码而不调用Enhanced API
+// 读取最新的配置，程序列表支持热更新
+func isRawParseTransaction(transaction resp.Transactions) bool {
+	cfg := configs.Current()
+	if cfg == nil || !cfg.RawParse.Enabled {
+		return false
+	}
+	programs := parser.TransferPrograms
+	if configured := cfg.RawParse.Programs; len(configured) > 0 {
+		programs = make([]string, len(configured))
+		for i, program := range configured {
+			programs[i] = parser.ResolveProgram(program)
+		}
+	}
+	return parser.IsTransferTransaction(transaction, programs)
+}
+
+// ParsedTransactionFilterReason 返回解析阶段过滤该交易的原因，返回空字符串表示交易会被存储
+func ParsedTransactionFilterReason(transaction resp.ParsedTransaction) string {
+	if transaction.TransactionError != nil &&
+		transaction.TransactionError.InstructionError != nil &&
+		len(transaction.TransactionError.InstructionError) > 0 {
+		return "交易执行失败(InstructionError)"
+	}
+	if !slices.Contains(resp.NeedToParseTransactionType, transaction.Type) {
+		return "交易类型不在解析列表中: " + string(transaction.Type)
+	}
+	return ""
+}
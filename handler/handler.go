@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"sync"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+)
+
+// Handler 区块与交易处理器
+// 队列和解析结果的存储通过构造函数注入，测试时可使用 storage.MemoryStore 替代内存队列和Redis
+type Handler struct {
+	blocks       storage.BlockStore
+	transactions storage.TransactionQueueStore
+	results      storage.ResultStore
+
+	schedulerOnce sync.Once
+	scheduler     *batchScheduler // 交错调度器，queue.transaction_scheduling 为 interleaved 时使用
+
+	parseSchedulerOnce sync.Once
+	parser             *parseScheduler // 解析调度器，限制解析批次的并发数和每个密钥的请求速率
+
+	walletHistoryOnce sync.Once
+	walletHistory     *walletHistoryFetcher // 在后台查询缓存中没有的钱包历史，启用 wallet_context 时首次使用时创建
+
+	pendingSlots slotBuffer       // 按确认深度等待入队的槽位
+	prefetcher   *blockPrefetcher // 区块预取，未启用时为nil
+}
+
+// GlobalHandler 最近创建的处理器，管理接口的运行时控制通过它操作队列
+var GlobalHandler *Handler
+
+// NewHandler 创建处理器并设置为全局实例
+// 参数:
+//   - blocks: 区块队列
+//   - transactions: 交易队列
+//   - results: 解析结果存储
+func NewHandler(blocks storage.BlockStore, transactions storage.TransactionQueueStore, results storage.ResultStore) *Handler {
+	h := &Handler{
+		blocks:       blocks,
+		transactions: transactions,
+		results:      results,
+	}
+	GlobalHandler = h
+	return h
+}
+
+// NewDefaultHandler 创建使用全局队列和Redis的处理器，交易队列按 queue.backend 选择，需在 storage.InitQueue 之后调用
+func NewDefaultHandler() *Handler {
+	return NewHandler(
+		storage.NewQueueBlockStore(storage.GlobalBlockQueue),
+		storage.NewDefaultTransactionStore(),
+		storage.NewRedisResultStore(),
+	)
+}
+
+// Blocks 返回处理器的区块队列，需要重新推入槽位的组件(如跳过列表)与处理器共用同一个队列
+func (h *Handler) Blocks() storage.BlockStore {
+	return h.blocks
+}
+
+// ackTransactions 交易队列需要确认时(如Redis Streams)，确认区块的交易已处理完成
+func (h *Handler) ackTransactions(item models.TransactionQueueModel) {
+	if acker, ok := h.transactions.(storage.TransactionAcker); ok {
+		acker.AckTransactions(item)
+	}
+}
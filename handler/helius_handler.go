@@ -2,28 +2,117 @@ package handler
 
 import (
 	"encoding/json"
+	"sync"
 
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/cursor"
 	"github.com/life2you/datas-go/logger"
-	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/tracing"
 	"go.uber.org/zap"
 )
 
-// HeliusSlotHandler 处理来自 Helius blockSubscribe 的 WebSocket 消息
-// 它解析区块数据并将其存储到 Redis 中
-func HeliusSlotHandler(result json.RawMessage) {
-	var slotInfo struct {
-		Parent uint64 `json:"parent"`
-		Root   uint64 `json:"root"`
-		Slot   uint64 `json:"slot"`
+// HeliusBlockStreamHandler 处理 blockSubscribe 分块推送的完整区块，直接汇总交易签名，不再调用getBlock
+// 实现 rpc.BlockStreamHandler
+type HeliusBlockStreamHandler struct {
+	handler *Handler
+	mu      sync.Mutex
+	blocks  map[uint64]*blockAccumulator // 正在接收的区块
+}
+
+// NewHeliusBlockStreamHandler 创建区块订阅处理器
+func (h *Handler) NewHeliusBlockStreamHandler() *HeliusBlockStreamHandler {
+	return &HeliusBlockStreamHandler{
+		handler: h,
+		blocks:  make(map[uint64]*blockAccumulator),
+	}
+}
+
+// OnTransactions 汇总一批交易
+func (s *HeliusBlockStreamHandler) OnTransactions(slot uint64, transactions []resp.Transactions) {
+	if !monitor.IsIngestLeader() {
+		return
+	}
+	s.mu.Lock()
+	block, ok := s.blocks[slot]
+	if !ok {
+		block = newBlockAccumulator(slot)
+		s.blocks[slot] = block
+		monitor.SetBlockState(slot, models.BlockFetching, nil)
+	}
+	s.mu.Unlock()
+	block.add(transactions)
+}
+
+// OnBlock 区块接收完成后推入交易队列；通知带有错误时改为推入区块队列，通过getBlock重新获取
+func (s *HeliusBlockStreamHandler) OnBlock(slot uint64, blockData resp.BlockResp, notificationErr json.RawMessage) {
+	s.mu.Lock()
+	block, ok := s.blocks[slot]
+	delete(s.blocks, slot)
+	s.mu.Unlock()
+	if !monitor.IsIngestLeader() {
+		return
+	}
+
+	logger.Debug("收到区块通知", zap.Uint64("slot", slot))
+	tracing.StartSlot(slot)
+	monitor.RecordSlot(slot)
+	metrics.ObserveSlot(slot)
+	cursor.Observe(slot)
+
+	if notificationErr != nil {
+		logger.Warn("区块通知带有错误，改为通过getBlock获取", zap.Uint64("slot", slot), zap.ByteString("err", notificationErr))
+		s.handler.queueBlock(slot)
+		return
+	}
+	// 排空队列期间完整区块也不再处理，停止排空后通过getBlock重新获取
+	if deferSlot(slot) {
+		return
+	}
+	// 启用分布式处理时每个实例都会收到完整区块，只由获取到租约的实例处理
+	if !monitor.AcquireSlot(slot) {
+		return
+	}
+	if !ok {
+		block = newBlockAccumulator(slot)
 	}
+	block.blockhash = blockData.Blockhash
+	block.previousBlockhash = blockData.PreviousBlockhash
+	block.unfinalized = configs.GlobalConfig.WebSocket.BlockCommitment != "finalized"
+	go func() {
+		defer supervisor.Recover("handler.finish_block", nil)
+		s.handler.finishBlock(block, blockData.ParentSlot, blockData.BlockTime)
+	}()
+}
 
-	if err := json.Unmarshal(result, &slotInfo); err != nil {
-		logger.Error("解析槽位数据失败", zap.Error(err))
+// HeliusBlockHandler 处理不含交易的 blockSubscribe 通知(transactionDetails=none)，将区块槽位推入区块队列
+func (h *Handler) HeliusBlockHandler(result json.RawMessage) {
+	if !monitor.IsIngestLeader() {
+		return
+	}
+	var notification struct {
+		Value struct {
+			Slot uint64          `json:"slot"`
+			Err  json.RawMessage `json:"err"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(result, &notification); err != nil {
+		if payload.Sample("block_notification", "", result, err) {
+			logger.Error("解析区块通知失败", zap.Error(err))
+		}
 		return
 	}
+	slot := notification.Value.Slot
 
-	logger.Debug("收到新槽位通知", zap.Uint64("slot", slotInfo.Slot))
+	logger.Debug("收到区块通知", zap.Uint64("slot", slot))
+	monitor.RecordSlot(slot)
+	metrics.ObserveSlot(slot)
+	cursor.Observe(slot)
 
-	// storage.GlobalRedisClient.StoreBlock(context.Background(), slotInfo.Slot)
-	storage.GlobalBlockQueue.Push(slotInfo.Slot, int64(slotInfo.Slot))
+	h.queueBlock(slot)
 }
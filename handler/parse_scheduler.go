@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/monitor"
+	"go.uber.org/zap"
+)
+
+// parseScheduler 限制同时调用Enhanced API的解析批次数，并按每个密钥的请求速率分配批次：
+// 每次选取最早可以发起请求的可用密钥，预约它的下一个请求时间，等待到预约时间后再发起请求
+type parseScheduler struct {
+	slots chan struct{} // 全局并发令牌，不限制并发时为nil
+
+	mu     sync.Mutex
+	keyRPS float64     // 每个密钥每秒的请求数，支持热更新
+	next   []time.Time // 每个密钥下一次可以发起请求的时间
+}
+
+// newParseScheduler 创建解析调度器
+func newParseScheduler(config *configs.ParserConfig) *parseScheduler {
+	s := &parseScheduler{keyRPS: config.KeyRPS}
+	if config.MaxConcurrentBatches > 0 {
+		s.slots = make(chan struct{}, config.MaxConcurrentBatches)
+	}
+	return s
+}
+
+// parseScheduler 返回处理器的解析调度器，首次使用时按配置创建
+func (h *Handler) parseScheduler() *parseScheduler {
+	h.parseSchedulerOnce.Do(func() {
+		scheduler := newParseScheduler(&configs.GlobalConfig.Parser)
+		configs.OnChange("parser", func(oldConfig, newConfig *configs.Config) {
+			if oldConfig.Parser.KeyRPS != newConfig.Parser.KeyRPS {
+				scheduler.setKeyRPS(newConfig.Parser.KeyRPS)
+				logger.Info("解析请求速率已热更新", zap.Float64("key_rps", newConfig.Parser.KeyRPS))
+			}
+		})
+		h.parser = scheduler
+	})
+	return h.parser
+}
+
+// setKeyRPS 修改每个密钥的请求速率，已预约的请求时间不变
+func (s *parseScheduler) setKeyRPS(keyRPS float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyRPS = keyRPS
+}
+
+// interval 返回单个密钥两次请求之间的间隔，网络拥堵期间按配置放大，调用方需持有锁
+func (s *parseScheduler) interval() time.Duration {
+	if s.keyRPS <= 0 {
+		return 0
+	}
+	return monitor.EnhancedAPIInterval(time.Duration(float64(time.Second) / s.keyRPS))
+}
+
+// acquire 获取并发令牌并为批次分配密钥，等待到该密钥可以发起请求时返回，批次完成后需要调用 release
+// 参数:
+//   - clientCount: 密钥数量
+//
+// 返回:
+//   - int: 分配的密钥索引
+//   - error: 上下文结束或没有可用的密钥时返回错误，此时不需要调用 release
+func (s *parseScheduler) acquire(ctx context.Context, clientCount int) (int, error) {
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	index, at, err := s.reserve(clientCount)
+	if err != nil {
+		s.release()
+		return 0, err
+	}
+	if wait := at.Sub(clock.Now()); wait > 0 {
+		select {
+		case <-clock.After(wait):
+		case <-ctx.Done():
+			s.release()
+			return 0, ctx.Err()
+		}
+	}
+	return index, nil
+}
+
+// reserve 选取最早可以发起请求的可用密钥并预约请求时间
+func (s *parseScheduler) reserve(clientCount int) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 热更新后密钥数量可能变化
+	if len(s.next) != clientCount {
+		s.next = append(s.next[:0:0], s.next[:min(len(s.next), clientCount)]...)
+		for len(s.next) < clientCount {
+			s.next = append(s.next, time.Time{})
+		}
+	}
+
+	chosen := -1
+	for i := range clientCount {
+		// 跳过隔离中和当天用量已达到预算的密钥
+		if index, err := monitor.NextAPIKey(i, clientCount); err != nil {
+			return 0, time.Time{}, err
+		} else if index != i {
+			continue
+		}
+		if chosen < 0 || s.next[i].Before(s.next[chosen]) {
+			chosen = i
+		}
+	}
+	// 扫描期间密钥状态可能变化，没有选出密钥时视为没有可用的密钥
+	if chosen < 0 {
+		return 0, time.Time{}, monitor.ErrNoHealthyAPIKey
+	}
+	now := clock.Now()
+	at := s.next[chosen]
+	if at.Before(now) {
+		at = now
+	}
+	s.next[chosen] = at.Add(s.interval())
+	return chosen, at, nil
+}
+
+// release 归还并发令牌
+func (s *parseScheduler) release() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+// backoff 密钥被限流时推迟它的下一次请求时间
+func (s *parseScheduler) backoff(index int, wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index >= len(s.next) {
+		return
+	}
+	if until := clock.Now().Add(wait); until.After(s.next[index]) {
+		s.next[index] = until
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/monitor"
+)
+
+func newTestParseScheduler(t *testing.T, config configs.ParserConfig) (*parseScheduler, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+	return newParseScheduler(&config), fake
+}
+
+func TestParseSchedulerReserveNoKeys(t *testing.T) {
+	s, _ := newTestParseScheduler(t, configs.ParserConfig{KeyRPS: 1})
+	if _, _, err := s.reserve(0); !errors.Is(err, monitor.ErrNoHealthyAPIKey) {
+		t.Fatalf("没有密钥时应返回 ErrNoHealthyAPIKey: %v", err)
+	}
+}
+
+func TestParseSchedulerReserveSpreadsKeys(t *testing.T) {
+	s, fake := newTestParseScheduler(t, configs.ParserConfig{KeyRPS: 2})
+	now := fake.Now()
+	for _, want := range []struct {
+		index int
+		at    time.Time
+	}{
+		{0, now},
+		{1, now},
+		{0, now.Add(500 * time.Millisecond)},
+		{1, now.Add(500 * time.Millisecond)},
+		{0, now.Add(time.Second)},
+	} {
+		index, at, err := s.reserve(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != want.index || !at.Equal(want.at) {
+			t.Fatalf("预约 = (%d, %s)，期望 (%d, %s)", index, at, want.index, want.at)
+		}
+	}
+
+	// 被限流的密钥推迟到限流结束后，在此之前只分配其他密钥
+	s.backoff(1, 10*time.Second)
+	for range 3 {
+		if index, _, _ := s.reserve(2); index != 0 {
+			t.Fatalf("密钥1被限流时应选择密钥0，实际为 %d", index)
+		}
+	}
+}
+
+func TestParseSchedulerReserveResizes(t *testing.T) {
+	s, fake := newTestParseScheduler(t, configs.ParserConfig{KeyRPS: 1})
+	s.reserve(1)
+	// 热更新增加密钥后新密钥立即可用
+	if index, at, _ := s.reserve(2); index != 1 || !at.Equal(fake.Now()) {
+		t.Fatalf("新增的密钥应立即可用: (%d, %s)", index, at)
+	}
+}
+
+func TestParseSchedulerAcquireWaits(t *testing.T) {
+	s, fake := newTestParseScheduler(t, configs.ParserConfig{KeyRPS: 1, MaxConcurrentBatches: 1})
+	if _, err := s.acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	s.release()
+
+	// 第二个批次需要等待1秒
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.acquire(context.Background(), 1)
+		done <- err
+	}()
+	for fake.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("未到预约时间不应返回")
+	default:
+	}
+	fake.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	s.release()
+
+	// 等待期间上下文结束时归还并发令牌
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, err := s.acquire(ctx, 1)
+		done <- err
+	}()
+	for fake.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("上下文结束时应返回错误: %v", err)
+	}
+	if len(s.slots) != 0 {
+		t.Fatalf("上下文结束时应归还并发令牌，占用 %d", len(s.slots))
+	}
+}
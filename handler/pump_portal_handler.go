@@ -5,6 +5,8 @@ import (
 
 	"github.com/life2you/datas-go/logger"
 	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/pipeline"
 	"go.uber.org/zap"
 )
 
@@ -13,12 +15,19 @@ func PumpPortalHandler(message json.RawMessage) {
 	var msg resp.ClassifyType
 	err := json.Unmarshal(message, &msg)
 	if err != nil {
-		logger.Error("PumpPortalHandler", zap.String("error", err.Error()))
+		if payload.Sample("pump_portal", "", message, err) {
+			logger.Error("PumpPortalHandler", zap.String("error", err.Error()))
+		}
 		return
 	}
 	if msg.TxType == "" {
 		return
 	}
+	pipeline.Publish(pipeline.Event{
+		Type:        pipeline.EventPumpPortal,
+		MessageType: msg.TxType,
+		Raw:         message,
+	})
 	switch msg.TxType {
 	case resp.Create:
 	//logger.Info("create", zap.String("message", string(message)))
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/cursor"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/tracing"
+)
+
+// HeliusSlotHandler 处理来自 Helius slotSubscribe 的槽位通知，将槽位推入区块队列，随后通过getBlock获取区块
+// 配置了 websocket.slot_confirmation_depth 时，槽位落后最新通知的槽位达到该深度后才入队
+func (h *Handler) HeliusSlotHandler(result json.RawMessage) {
+	// 失去摄取领导者身份后，取消订阅前仍可能收到通知
+	if !monitor.IsIngestLeader() {
+		return
+	}
+	var notification resp.SlotNotification
+	if err := json.Unmarshal(result, &notification); err != nil {
+		if payload.Sample("slot_notification", "", result, err) {
+			logger.Error("解析槽位通知失败", zap.Error(err))
+		}
+		return
+	}
+	if notification.Slot == 0 {
+		err := errors.New("槽位通知中没有槽位")
+		if payload.Sample("slot_notification", "", result, err) {
+			logger.Error("解析槽位通知失败", zap.Error(err))
+		}
+		return
+	}
+
+	logger.Debug("收到新槽位通知", zap.Uint64("slot", notification.Slot), zap.Uint64("parent", notification.Parent))
+	tracing.StartSlot(notification.Slot)
+	monitor.RecordSlot(notification.Slot)
+	metrics.ObserveSlot(notification.Slot)
+	cursor.Observe(notification.Slot)
+
+	depth := uint64(configs.GlobalConfig.WebSocket.SlotConfirmationDepth)
+	for _, slot := range h.pendingSlots.release(notification.Slot, depth) {
+		h.queueBlock(slot)
+	}
+}
+
+// slotBuffer 按确认深度等待入队的槽位
+type slotBuffer struct {
+	mu    sync.Mutex
+	slots []uint64 // 已收到通知、尚未入队的槽位，升序排列
+}
+
+// release 记录新通知的槽位，返回落后已通知的最大槽位至少 depth 个槽位、可以入队的槽位，按升序排列
+// depth 为0时直接返回该槽位；没有通知的槽位(跳过的槽位)不会入队
+func (b *slotBuffer) release(slot, depth uint64) []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if depth == 0 && len(b.slots) == 0 {
+		return []uint64{slot}
+	}
+	if i, found := slices.BinarySearch(b.slots, slot); !found {
+		b.slots = slices.Insert(b.slots, i, slot)
+	}
+	latest := b.slots[len(b.slots)-1]
+	if latest < depth {
+		return nil
+	}
+	n, _ := slices.BinarySearch(b.slots, latest-depth+1)
+	ready := slices.Clone(b.slots[:n])
+	b.slots = slices.Delete(b.slots, 0, n)
+	return ready
+}
@@ -3,20 +3,42 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"slices"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/export"
 	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
 	"github.com/life2you/datas-go/models"
 	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/pipeline"
 	"github.com/life2you/datas-go/rpc"
 	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+// 区块交易解析失败后最多重新入队的次数，超过后标记为失败
+const maxTransactionRetries = 3
+
 // 处理队列中的交易签名
-func StartProcessTransactionQueue() {
+func (h *Handler) StartProcessTransactionQueue() {
+	// Redis不可用且策略为pause，或通过管理接口暂停时暂停取出交易，等待恢复
+	if storage.IntakePaused() || parsePaused() {
+		clock.Sleep(time.Second)
+		return
+	}
+
 	// 创建有超时控制的上下文
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
@@ -26,89 +48,332 @@ func StartProcessTransactionQueue() {
 		logger.Error("没有可用的API客户端")
 		return
 	}
+	// 所有密钥都在隔离中或当天的用量都达到预算时不取出交易，交易留在队列中等待隔离结束或次日
+	if err := monitor.APIKeyAvailable(); err != nil {
+		logger.Warn("没有可用的API密钥，暂停解析交易", zap.Error(err))
+		if errors.Is(err, monitor.ErrAPIKeyBudgetExhausted) {
+			clock.Sleep(time.Minute)
+		} else {
+			clock.Sleep(5 * time.Second)
+		}
+		return
+	}
+	if configs.GlobalConfig.Queue.TransactionScheduling == TransactionSchedulingInterleaved {
+		h.dispatchInterleavedBatch(clientCount)
+		return
+	}
 	// transactionItem, err := storage.GlobalRedisClient.LPopTransactionQueue(ctx)
-	transactionItemAny, _, ok := storage.GlobalTransactionQueue.Pop()
+	transactionItem, ok := h.transactions.PopTransactions()
 	if !ok {
-		time.Sleep(1000 * time.Millisecond)
+		clock.Sleep(1000 * time.Millisecond)
 		return
 	}
-	transactionItem := transactionItemAny.(models.TransactionQueueModel)
-	signatures := slices.Chunk(transactionItem.Signatures, 50)
+	ctx, span := tracing.Start(tracing.SlotContext(ctx, transactionItem.Slot, transactionItem.TraceParent), "transactions.parse",
+		attribute.Int64("solana.slot", int64(transactionItem.Slot)),
+		attribute.Int("signatures", len(transactionItem.Signatures)),
+		attribute.Int("retries", transactionItem.Retries))
+	signatures := slices.Chunk(transactionItem.Signatures, transactionBatchSize)
 	var wg sync.WaitGroup
-	var i = 0
+	var errOnce sync.Once
+	var batchErr error
+	scheduler := h.parseScheduler()
 	for signature := range signatures {
-		clientIndex := i % clientCount
-		time.Sleep(200 * time.Millisecond)
+		// 等待并发令牌和密钥的请求间隔，没有可用的密钥时剩余批次不再派发，区块按失败处理
+		clientIndex, err := scheduler.acquire(ctx, clientCount)
+		if err != nil {
+			errOnce.Do(func() { batchErr = err })
+			break
+		}
 		wg.Add(1)
 		go func(clientIndex int, signature []string) {
 			defer wg.Done()
-			processTransactionBatch(ctx, clientIndex, transactionItem.Slot, signature...)
+			defer scheduler.release()
+			if err := h.processTransactionBatch(ctx, clientIndex, transactionItem.Slot, signature...); err != nil {
+				errOnce.Do(func() { batchErr = err })
+			}
 		}(clientIndex, signature)
-		i++
-
 	}
 	// 等待所有处理完成
 	wg.Wait()
+	tracing.End(span, batchErr)
+	h.finishTransactions(transactionItem, batchErr)
+}
+
+// finishTransactions 区块的所有批次处理完后，失败时重新入队，超过重试次数或成功时更新区块状态
+// 交易队列需要确认时，重新入队的元素是一条新消息，原消息在各种情况下都会确认
+func (h *Handler) finishTransactions(transactionItem models.TransactionQueueModel, batchErr error) {
+	defer h.ackTransactions(transactionItem)
+	// 限流和没有可用的密钥不是区块本身的问题，按服务端要求等待后重新入队，不计入重试次数
+	if errors.Is(batchErr, rpc.ErrRateLimited) || errors.Is(batchErr, monitor.ErrAPIKeyBudgetExhausted) || errors.Is(batchErr, monitor.ErrNoHealthyAPIKey) {
+		logger.Warn("交易解析被限流，等待后重新入队", zap.Uint64("slot", transactionItem.Slot), zap.Error(batchErr))
+		h.transactions.PushTransactions(transactionItem)
+		clock.Sleep(retryDelay(batchErr, time.Second))
+		return
+	}
+	if batchErr != nil && transactionItem.Retries < maxTransactionRetries {
+		// 重新入队，已解析的签名命中缓存，不会重复消耗API额度
+		transactionItem.Retries++
+		logger.Warn("交易解析失败，重新入队",
+			zap.Uint64("slot", transactionItem.Slot),
+			zap.Int("retries", transactionItem.Retries),
+			zap.Error(batchErr))
+		h.transactions.PushTransactions(transactionItem)
+		return
+	}
+	if batchErr != nil {
+		monitor.SetBlockState(transactionItem.Slot, models.BlockFailed, batchErr)
+	} else {
+		monitor.SetBlockState(transactionItem.Slot, models.BlockDone, nil)
+	}
 	logger.Info("交易数据解析完成，区块  ",
 		zap.Any("solana_slot", transactionItem.Slot))
 }
 
-// 并行处理交易数据
-func processTransactionBatch(ctx context.Context, clientIndex int, blockSlot uint64, signatures ...string) {
+// 并行处理交易数据，返回的错误表示该批次解析失败，处理中panic时也作为失败返回，区块按失败重新入队
+func (h *Handler) processTransactionBatch(ctx context.Context, clientIndex int, blockSlot uint64, signatures ...string) (err error) {
+	ctx, span := tracing.Start(ctx, "transactions.batch",
+		attribute.Int64("solana.slot", int64(blockSlot)), attribute.Int("signatures", len(signatures)))
+	// 在恢复panic之后结束，panic也记录为失败
+	defer func() { tracing.End(span, err) }()
+	defer supervisor.Recover("handler.transaction_batch", &err)
+	// 跳过隔离中和当天用量已达到预算的密钥
+	clientIndex, err = monitor.NextAPIKey(clientIndex, rpc.GetEnhancedApiClientCount())
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Int("api_key", clientIndex))
 	client := rpc.GetEnhancedApiClientByIndex(clientIndex)
 	if client == nil {
 		logger.Error("获取API客户端失败", zap.Int("clientIndex", clientIndex))
-		return
+		return fmt.Errorf("获取API客户端失败: %d", clientIndex)
 	}
 
-	// 创建批次专用上下文
-	batchCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// 创建批次专用上下文，不随调用方取消，但保留链路上下文
+	batchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 60*time.Second)
 	defer cancel()
 
-	// 使用指定客户端解析交易
-	transactionResp, err := client.ParseTransactions(batchCtx, signatures...)
+	// 使用指定客户端解析交易，已缓存的签名不再调用Enhanced API
+	rawTransactions, err := h.parseTransactionsCached(batchCtx, client, blockSlot, signatures)
 	if err != nil {
 		logger.Error("解析交易失败",
 			zap.Int("clientIndex", clientIndex),
 			zap.Uint64("区块", blockSlot),
 			zap.Error(err))
-		return
+		if errors.Is(err, rpc.ErrRateLimited) {
+			// 被限流的密钥按服务端要求推迟后续请求
+			h.parseScheduler().backoff(clientIndex, retryDelay(err, time.Second))
+		}
+		return err
 	}
 
-	if len(transactionResp) == 0 {
+	if len(rawTransactions) == 0 {
 		logger.Warn("交易响应为空",
 			zap.Int("clientIndex", clientIndex),
 			zap.Uint64("区块", blockSlot))
-		return
+		return nil
 	}
 
-	// 解析交易响应
-	var parsedTransactions []resp.ParsedTransaction
-	if err := json.Unmarshal(transactionResp, &parsedTransactions); err != nil {
-		logger.Error("解析交易数据失败",
-			zap.Int("clientIndex", clientIndex),
-			zap.Uint64("区块", blockSlot),
-			zap.Error(err))
-		return
-	}
+	metrics.AddTransactions(len(rawTransactions))
 
 	// 处理每个交易
-	for _, transaction := range parsedTransactions {
-		if transaction.TransactionError != nil &&
-			transaction.TransactionError.InstructionError != nil &&
-			len(transaction.TransactionError.InstructionError) > 0 {
+	ctx, sinkSpan := tracing.Start(ctx, "sink.write", attribute.Int("transactions", len(rawTransactions)))
+	defer sinkSpan.End()
+	for _, rawTransaction := range rawTransactions {
+		var transaction resp.ParsedTransaction
+		if err := json.Unmarshal(rawTransaction, &transaction); err != nil {
+			if payload.Sample("enhanced_transaction", strconv.FormatUint(blockSlot, 10), rawTransaction, err) {
+				logger.Error("解析交易数据失败",
+					zap.Int("clientIndex", clientIndex),
+					zap.Uint64("区块", blockSlot),
+					zap.Error(err))
+			}
 			continue
 		}
-		if slices.Contains(resp.NeedToParseTransactionType, transaction.Type) {
-			logger.Info("解析交易", zap.Any("transaction", transaction))
-			// 存储交易数据
-			if err := storage.GlobalRedisClient.StoreHash(ctx, transaction.Source, transaction.Source, string(transaction.Type), 0); err != nil {
-				logger.Error("存储交易哈希失败1", zap.Error(err))
+		h.archiveRawTransaction(ctx, blockSlot, transaction.Signature, rawTransaction)
+		monitor.VerifyTransaction(&transaction)
+		h.storeTransaction(ctx, blockSlot, &transaction)
+	}
+	return nil
+}
+
+// storeTransaction 索引未被过滤的解析结果，为swap交易计算美元价值并附加钱包上下文后发布交易事件
+func (h *Handler) storeTransaction(ctx context.Context, slot uint64, transaction *resp.ParsedTransaction) {
+	if ParsedTransactionFilterReason(*transaction) != "" {
+		return
+	}
+	logger.Info("解析交易", zap.Any("transaction", transaction))
+	// 按来源/类型和代币按天索引交易，来源已规范化，未知来源统一归入UNKNOWN，不会产生无界的键名
+	h.indexTransaction(ctx, transaction)
+	valueSwap(transaction)
+	h.attachWalletContext(ctx, transaction)
+
+	pipeline.Publish(pipeline.Event{
+		Type:        pipeline.EventTransaction,
+		Slot:        slot,
+		Signature:   transaction.Signature,
+		Transaction: transaction,
+	})
+}
+
+// indexTransaction 按配置的保留时长和签名数上限索引交易
+func (h *Handler) indexTransaction(ctx context.Context, transaction *resp.ParsedTransaction) {
+	indexConfig := &configs.GlobalConfig.TransactionIndex
+	indexed := models.IndexedTransaction{
+		Signature: transaction.Signature,
+		Source:    string(transaction.Source),
+		Type:      string(transaction.Type),
+		Slot:      transaction.Slot,
+		Timestamp: transaction.Timestamp,
+	}
+	if indexConfig.IndexMints {
+		indexed.Mints = transaction.Mints()
+	}
+	ttl := storage.TransactionIndexTTL(indexConfig, indexed.Type)
+	if err := h.results.IndexTransaction(ctx, indexed, ttl, indexConfig.TTL, indexConfig.MaxPerKey); err != nil {
+		logger.Error("索引交易失败", zap.Error(err))
+	}
+}
+
+// parseTransactionsCached 解析交易并返回每笔交易的原始JSON
+// 启用解析结果缓存时先按签名查询Redis，仅对未命中的签名调用Enhanced API，解析后写入缓存；
+// 启用不再解析缓存时跳过累计失败次数达到上限的签名，并记录本次解析失败的签名；
+// 缓存读写失败不影响解析
+func (h *Handler) parseTransactionsCached(ctx context.Context, client *rpc.HeliusEnhancedApiClient, blockSlot uint64, signatures []string) ([]json.RawMessage, error) {
+	cacheConfig := configs.GlobalConfig.EnrichmentCache
+	negativeConfig := configs.GlobalConfig.NegativeCache
+
+	var rawTransactions []json.RawMessage
+	missing := signatures
+	if cacheConfig.Enabled {
+		cached, err := h.results.GetEnrichedTransactions(ctx, signatures)
+		if err != nil {
+			logger.Warn("读取解析结果缓存失败，直接调用Enhanced API", zap.Error(err))
+		}
+		missing = make([]string, 0, len(signatures))
+		for _, signature := range signatures {
+			if raw, ok := cached[signature]; ok {
+				rawTransactions = append(rawTransactions, raw)
+			} else {
+				missing = append(missing, signature)
+			}
+		}
+		if len(cached) > 0 {
+			logger.Debug("解析结果缓存命中", zap.Int("命中", len(cached)), zap.Int("未命中", len(missing)))
+		}
+	}
+	var failures map[string]models.ParseFailure
+	if negativeConfig.Enabled && len(missing) > 0 {
+		var err error
+		failures, err = h.results.GetParseFailures(ctx, missing)
+		if err != nil {
+			logger.Warn("读取解析失败记录失败，不跳过任何签名", zap.Error(err))
+		}
+		remaining := make([]string, 0, len(missing))
+		for _, signature := range missing {
+			if failure, ok := failures[signature]; ok && failure.Attempts >= negativeConfig.MaxAttempts {
+				continue
+			}
+			remaining = append(remaining, signature)
+		}
+		if skipped := len(missing) - len(remaining); skipped > 0 {
+			metrics.AddSkippedTransactions(skipped)
+			logger.Debug("跳过多次解析失败的签名", zap.Uint64("区块", blockSlot), zap.Int("跳过", skipped))
+		}
+		missing = remaining
+	}
+	if len(missing) == 0 {
+		return rawTransactions, nil
+	}
+
+	transactionResp, err := client.ParseTransactions(ctx, nil, missing...)
+	if err != nil {
+		return nil, err
+	}
+	var parsed []json.RawMessage
+	if len(transactionResp) > 0 {
+		if err := json.Unmarshal(transactionResp, &parsed); err != nil {
+			payload.Sample("enhanced_response", strconv.FormatUint(blockSlot, 10), transactionResp, err)
+			return nil, fmt.Errorf("解析交易数据失败: %w", err)
+		}
+	}
+	rawTransactions = append(rawTransactions, parsed...)
+	if negativeConfig.Enabled {
+		h.recordParseFailures(ctx, blockSlot, missing, parsed, failures)
+	}
+
+	if cacheConfig.Enabled {
+		entries := make(map[string]json.RawMessage, len(parsed))
+		for _, raw := range parsed {
+			var transaction struct {
+				Signature string `json:"signature"`
 			}
-			err := storage.GlobalRedisClient.StoreHash(ctx, transaction.Source+"_"+string(transaction.Type), transaction.Signature, string(transaction.Type), 0)
-			if err != nil {
-				logger.Error("存储交易哈希失败2", zap.Error(err))
+			if err := json.Unmarshal(raw, &transaction); err == nil && transaction.Signature != "" {
+				entries[transaction.Signature] = raw
 			}
 		}
+		if err := h.results.StoreEnrichedTransactions(ctx, entries, cacheConfig.TTL); err != nil {
+			logger.Warn("写入解析结果缓存失败", zap.Error(err))
+		}
+	}
+	return rawTransactions, nil
+}
+
+// recordParseFailures 对比请求的签名与Enhanced API的响应，记录没有返回、返回UNKNOWN或无法解码的签名，
+// 之前失败过、这次成功解析的签名删除失败记录
+func (h *Handler) recordParseFailures(ctx context.Context, blockSlot uint64, requested []string, parsed []json.RawMessage, previous map[string]models.ParseFailure) {
+	returned := make(map[string]models.ParseFailureReason, len(parsed))
+	for _, raw := range parsed {
+		var header struct {
+			Signature string               `json:"signature"`
+			Type      resp.TransactionType `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil || header.Signature == "" {
+			continue
+		}
+		var transaction resp.ParsedTransaction
+		switch {
+		case json.Unmarshal(raw, &transaction) != nil:
+			returned[header.Signature] = models.ParseFailureDecode
+		case header.Type == resp.TransactionTypeUnknown:
+			returned[header.Signature] = models.ParseFailureUnknown
+		default:
+			returned[header.Signature] = ""
+		}
+	}
+
+	failures := make(map[string]models.ParseFailureReason)
+	var recovered []string
+	for _, signature := range requested {
+		reason, ok := returned[signature]
+		if !ok {
+			reason = models.ParseFailureMissing
+		}
+		if reason != "" {
+			failures[signature] = reason
+		} else if _, failed := previous[signature]; failed {
+			recovered = append(recovered, signature)
+		}
+	}
+	ttl := configs.GlobalConfig.NegativeCache.TTL
+	if err := h.results.RecordParseFailures(ctx, blockSlot, failures, ttl); err != nil {
+		logger.Warn("记录解析失败的签名失败", zap.Error(err))
+	}
+	if err := h.results.ClearParseFailures(ctx, recovered); err != nil {
+		logger.Warn("删除解析失败记录失败", zap.Error(err))
+	}
+}
+
+// archiveRawTransaction 按配置将Enhanced API原始响应归档到Redis，以签名与解析记录关联；配置了目录时同时写入归档文件
+func (h *Handler) archiveRawTransaction(ctx context.Context, blockSlot uint64, signature string, raw json.RawMessage) {
+	archiveConfig := configs.GlobalConfig.RawArchive
+	if !archiveConfig.Enabled {
+		return
+	}
+	if err := h.results.StoreRawTransaction(ctx, signature, blockSlot, raw, archiveConfig.Compress, archiveConfig.TTL); err != nil {
+		logger.Error("归档交易原始响应失败",
+			zap.String("signature", signature),
+			zap.Uint64("区块", blockSlot),
+			zap.Error(err))
 	}
+	export.ArchiveRawTransaction(blockSlot, signature, raw)
 }
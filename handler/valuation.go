@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// valueSwap 按缓存的Jupiter价格计算swap交易的美元价值并写入 transaction.Valuation
+// 有SOL一侧时按SOL成交量和SOL价格计算，代币之间的swap按手续费支付者转出的代币计算；
+// 只读价格缓存，不在解析路径上等待请求，缓存中没有价格的代币由后台刷新，之后的交易才会计价。
+// 未启用价格客户端、不是swap或缓存中没有价格时不设置
+func valueSwap(transaction *resp.ParsedTransaction) {
+	client := rpc.GlobalJupiterPriceClient
+	if client == nil || transaction.Type != resp.TransactionTypeSwap {
+		return
+	}
+	valuation := resp.SwapValuation{SOLAmount: analytics.SwapSOLVolume(transaction)}
+	if valuation.SOLAmount > 0 {
+		valuation.PricedMint = analytics.WrappedSOLMint
+		valuation.PricedAmount = valuation.SOLAmount
+	} else {
+		valuation.PricedMint, valuation.PricedAmount = soldToken(transaction)
+	}
+	if valuation.PricedMint == "" || valuation.PricedAmount <= 0 {
+		return
+	}
+
+	price, pricedAt, ok := client.CachedPrice(valuation.PricedMint)
+	if !ok {
+		return
+	}
+	valuation.PriceUSD = price
+	valuation.USDValue = price * valuation.PricedAmount
+	valuation.PricedAt = pricedAt.Unix()
+	transaction.Valuation = &valuation
+}
+
+// soldToken 返回手续费支付者在swap中转出的第一个代币及其数量
+func soldToken(transaction *resp.ParsedTransaction) (string, float64) {
+	for _, transfer := range transaction.TokenTransfers {
+		if transfer.FromUserAccount != transaction.FeePayer || transfer.Mint == "" {
+			continue
+		}
+		amount, _ := transfer.TokenAmount.Float64()
+		if amount > 0 {
+			return transfer.Mint, amount
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/watchlist"
+)
+
+const (
+	// Helius 地址历史接口单次最多返回的交易数
+	walletHistoryPageSize = 100
+	// 等待后台查询历史的钱包数量上限，队列满时不再加入，该钱包的下一笔交易时重新加入
+	walletHistoryQueueSize = 256
+)
+
+// 查询钱包历史时轮流使用的API密钥序号
+var walletHistoryKey atomic.Uint64
+
+// historyTransaction 计算钱包历史摘要时用到的交易字段
+type historyTransaction struct {
+	Signature string               `json:"signature"`
+	Type      resp.TransactionType `json:"type"`
+	Slot      uint64               `json:"slot"`
+	Timestamp int64                `json:"timestamp"`
+}
+
+// attachWalletContext 为swap交易附加手续费支付者的历史信息并写入 transaction.WalletContext
+// 只读Redis中缓存的历史摘要，不在解析路径上查询Enhanced API：未缓存的钱包交给后台查询，本交易只附加关注列表信息；
+// 已缓存时按摘要附加并把本交易计入摘要，之后同一钱包的交易看到的 priorTrades 包含本交易
+func (h *Handler) attachWalletContext(ctx context.Context, transaction *resp.ParsedTransaction) {
+	config := &configs.GlobalConfig.WalletContext
+	if !config.Enabled || transaction.Type != resp.TransactionTypeSwap || transaction.FeePayer == "" {
+		return
+	}
+	walletContext := &resp.WalletContext{Wallet: transaction.FeePayer}
+	if watchlist.GlobalWatchlist != nil {
+		if entry, err := watchlist.GlobalWatchlist.Get(transaction.FeePayer); err == nil {
+			walletContext.Watchlisted = true
+			walletContext.WatchlistLabel = entry.Label
+		}
+	}
+	transaction.WalletContext = walletContext
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	history, counted, err := storage.GetRedisClient(storage.WorkloadCache).AdvanceWalletHistory(ctx, transaction.FeePayer, transaction.Slot, transaction.Timestamp)
+	switch {
+	case err != nil:
+		logger.Warn("读取钱包历史缓存失败，不附加历史信息",
+			zap.String("signature", transaction.Signature),
+			zap.String("wallet", transaction.FeePayer),
+			zap.Error(err))
+	case history == nil:
+		h.walletHistoryFetcher().schedule(transaction.FeePayer)
+	case counted:
+		// 槽位不晚于摘要中最新的交易时，摘要可能已包含本交易或更晚的交易，不附加历史信息
+		walletContext.FirstSeen = history.FirstSeen
+		walletContext.PriorTrades = history.Trades
+		walletContext.HistoryComplete = history.Complete
+		walletContext.FetchedAt = history.FetchedAt
+	}
+}
+
+// walletHistoryFetcher 在后台查询钱包历史并写入缓存，同一钱包同时只查询一次
+type walletHistoryFetcher struct {
+	config  configs.WalletContextConfig
+	queue   chan string
+	mu      sync.Mutex
+	pending map[string]struct{} // 在队列中或正在查询的钱包
+}
+
+// walletHistoryFetcher 返回处理器的钱包历史查询，首次使用时按配置创建并启动
+func (h *Handler) walletHistoryFetcher() *walletHistoryFetcher {
+	h.walletHistoryOnce.Do(func() {
+		h.walletHistory = &walletHistoryFetcher{
+			config:  configs.GlobalConfig.WalletContext,
+			queue:   make(chan string, walletHistoryQueueSize),
+			pending: make(map[string]struct{}),
+		}
+		supervisor.Go(context.Background(), "handler.wallet_history", h.walletHistory.run)
+	})
+	return h.walletHistory
+}
+
+// schedule 把钱包加入后台查询，已在查询中或队列已满时忽略
+func (f *walletHistoryFetcher) schedule(wallet string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.pending[wallet]; ok {
+		return
+	}
+	select {
+	case f.queue <- wallet:
+		f.pending[wallet] = struct{}{}
+	default:
+	}
+}
+
+// run 依次查询队列中的钱包历史并写入缓存，失败时只记录日志，该钱包的下一笔交易时重新查询
+func (f *walletHistoryFetcher) run(ctx context.Context) {
+	for {
+		var wallet string
+		select {
+		case <-ctx.Done():
+			return
+		case wallet = <-f.queue:
+		}
+		f.fetch(ctx, wallet)
+		f.mu.Lock()
+		delete(f.pending, wallet)
+		f.mu.Unlock()
+	}
+}
+
+// fetch 查询一个钱包的历史并写入缓存
+func (f *walletHistoryFetcher) fetch(ctx context.Context, wallet string) {
+	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+	history, err := fetchWalletHistory(ctx, f.config.HistoryLimit, wallet)
+	if err != nil {
+		logger.Warn("查询钱包历史失败", zap.String("wallet", wallet), zap.Error(err))
+		return
+	}
+	if err := storage.GetRedisClient(storage.WorkloadCache).StoreWalletHistory(ctx, *history, f.config.CacheTTL); err != nil {
+		logger.Warn("缓存钱包历史失败", zap.String("wallet", wallet), zap.Error(err))
+	}
+}
+
+// fetchWalletHistory 分页查询钱包最近 limit 笔历史交易并汇总
+// 每页单独选择可用的API密钥，按该页请求的交易数计入密钥用量
+func fetchWalletHistory(ctx context.Context, limit int, wallet string) (*models.WalletHistory, error) {
+	history := &models.WalletHistory{Wallet: wallet}
+	params := rpc.AddressTransactionsParams{}
+	fetched := 0
+	for fetched < limit {
+		index, err := monitor.NextAPIKey(int(walletHistoryKey.Add(1)-1), rpc.GetEnhancedApiClientCount())
+		if err != nil {
+			return nil, err
+		}
+		params.Limit = min(walletHistoryPageSize, limit-fetched)
+		body, err := rpc.GetEnhancedApiClientByIndex(index).GetAddressTransactions(ctx, wallet, params)
+		if err != nil {
+			return nil, err
+		}
+		var page []historyTransaction
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("解析钱包历史失败: %w", err)
+		}
+		for _, item := range page {
+			history.Transactions++
+			if item.Type == resp.TransactionTypeSwap {
+				history.Trades++
+			}
+			history.LatestSlot = max(history.LatestSlot, item.Slot)
+			// 历史按时间倒序返回，最后一笔是最早的交易
+			if item.Timestamp > 0 {
+				history.FirstSeen = item.Timestamp
+			}
+		}
+		fetched += len(page)
+		if len(page) < params.Limit {
+			history.Complete = true
+			break
+		}
+		params.Before = page[len(page)-1].Signature
+	}
+	history.FetchedAt = clock.Now().Unix()
+	return history, nil
+}
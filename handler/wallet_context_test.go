@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// usageRecord 记录每次Enhanced API请求计入的密钥和额度
+type usageRecord struct {
+	mu      sync.Mutex
+	indexes []int
+	credits []int
+}
+
+func (r *usageRecord) RecordUsage(index int, credits int, code string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.indexes = append(r.indexes, index)
+	r.credits = append(r.credits, credits)
+}
+
+// setupWalletContext 启用 wallet_context，使用miniredis和模拟的地址历史接口，history 为按时间倒序的历史交易
+func setupWalletContext(t *testing.T, history []historyTransaction) *usageRecord {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.WalletContext = configs.WalletContextConfig{Enabled: true, HistoryLimit: 5, CacheTTL: time.Minute, Timeout: 5 * time.Second}
+	previous := configs.GlobalConfig
+	configs.SetGlobalConfig(cfg)
+	t.Cleanup(func() { configs.SetGlobalConfig(previous) })
+
+	redisServer := miniredis.RunT(t)
+	cfg.Redis.Addr = redisServer.Addr()
+	storage.NewRedisClient(&cfg.Redis)
+	t.Cleanup(storage.CloseRedisClients)
+
+	// 按 before 和 limit 分页返回历史
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		if before := r.URL.Query().Get("before"); before != "" {
+			for i, item := range history {
+				if item.Signature == before {
+					start = i + 1
+				}
+			}
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		_ = json.NewEncoder(w).Encode(history[start:min(start+limit, len(history))])
+	}))
+	t.Cleanup(server.Close)
+	rpc.GlobalHeliusEnhancedApiClients = nil
+	rpc.NewHeliusEnhancedApiClient(&configs.HeliusEnhancedAPIConfig{Endpoint: server.URL, APIKeys: []string{"a", "b"}})
+	usage := &usageRecord{}
+	rpc.SetEnhancedUsageRecorder(usage)
+	t.Cleanup(func() {
+		rpc.GlobalHeliusEnhancedApiClients = nil
+		rpc.SetEnhancedUsageRecorder(nil)
+	})
+	return usage
+}
+
+func TestFetchWalletHistoryPages(t *testing.T) {
+	usage := setupWalletContext(t, []historyTransaction{
+		{Signature: "s4", Type: resp.TransactionTypeSwap, Slot: 40, Timestamp: 400},
+		{Signature: "s3", Type: resp.TransactionTypeTransfer, Slot: 30, Timestamp: 300},
+		{Signature: "s2", Type: resp.TransactionTypeSwap, Slot: 20, Timestamp: 200},
+		{Signature: "s1", Type: resp.TransactionTypeSwap, Slot: 10, Timestamp: 100},
+	})
+
+	// 每页3笔，第二页不足一页时视为查询到了全部历史
+	history, err := fetchWalletHistory(context.Background(), 7, "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.WalletHistory{Wallet: "wallet", Transactions: 4, Trades: 3, FirstSeen: 100, LatestSlot: 40, Complete: true, FetchedAt: history.FetchedAt}
+	if *history != want {
+		t.Fatalf("历史摘要 = %+v，期望 %+v", *history, want)
+	}
+	if len(usage.credits) != 1 || usage.credits[0] != 7 {
+		t.Fatalf("每次请求应按请求的交易数计入用量: %v", usage.credits)
+	}
+
+	usage.credits, usage.indexes = nil, nil
+	history, err = fetchWalletHistory(context.Background(), 2, "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Complete || history.Transactions != 2 || history.FirstSeen != 300 {
+		t.Fatalf("达到 history_limit 时历史不完整: %+v", *history)
+	}
+	if len(usage.credits) != 1 || usage.credits[0] != 2 {
+		t.Fatalf("每次请求应按请求的交易数计入用量: %v", usage.credits)
+	}
+}
+
+func TestFetchWalletHistoryChargesEachPage(t *testing.T) {
+	history := make([]historyTransaction, 0, 150)
+	for i := 150; i > 0; i-- {
+		history = append(history, historyTransaction{Signature: "s" + strconv.Itoa(i), Type: resp.TransactionTypeSwap, Slot: uint64(i), Timestamp: int64(i)})
+	}
+	usage := setupWalletContext(t, history)
+
+	summary, err := fetchWalletHistory(context.Background(), 150, "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Transactions != 150 || summary.FirstSeen != 1 || summary.LatestSlot != 150 {
+		t.Fatalf("历史摘要错误: %+v", *summary)
+	}
+	if len(usage.credits) != 2 || usage.credits[0] != walletHistoryPageSize || usage.credits[1] != 50 {
+		t.Fatalf("每页按请求的交易数计入用量: %v", usage.credits)
+	}
+	if usage.indexes[0] == usage.indexes[1] {
+		t.Fatalf("每页应轮流选择API密钥: %v", usage.indexes)
+	}
+}
+
+func TestAttachWalletContextCountsLaterTrades(t *testing.T) {
+	setupWalletContext(t, nil)
+	ctx := context.Background()
+	h := NewHandler(storage.NewMemoryStore(), storage.NewMemoryStore(), storage.NewMemoryStore())
+	swap := func(signature string, slot uint64) *resp.ParsedTransaction {
+		return &resp.ParsedTransaction{Signature: signature, Type: resp.TransactionTypeSwap, FeePayer: "wallet", Slot: slot, Timestamp: int64(slot) * 10}
+	}
+
+	// 未缓存时交给后台查询，本交易不附加历史
+	first := swap("a", 100)
+	h.walletHistoryOnce.Do(func() {
+		h.walletHistory = &walletHistoryFetcher{queue: make(chan string, 1), pending: make(map[string]struct{})}
+	})
+	h.attachWalletContext(ctx, first)
+	if first.WalletContext == nil || first.WalletContext.FetchedAt != 0 {
+		t.Fatalf("未缓存时只附加钱包地址: %+v", first.WalletContext)
+	}
+	if wallet := <-h.walletHistory.queue; wallet != "wallet" {
+		t.Fatalf("未缓存的钱包应交给后台查询: %s", wallet)
+	}
+
+	history := models.WalletHistory{Wallet: "wallet", FirstSeen: 500, Transactions: 5, Trades: 3, Complete: true, FetchedAt: 1000, LatestSlot: 100}
+	if err := storage.GetRedisClient(storage.WorkloadCache).StoreWalletHistory(ctx, history, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{3, 4} {
+		transaction := swap(strconv.Itoa(i), uint64(101+i))
+		h.attachWalletContext(ctx, transaction)
+		got := transaction.WalletContext
+		if got.PriorTrades != want || got.FirstSeen != 500 || !got.HistoryComplete || got.FetchedAt != 1000 {
+			t.Fatalf("第 %d 笔交易的钱包上下文 = %+v，期望 priorTrades=%d", i+1, *got, want)
+		}
+	}
+
+	// 不晚于摘要中最新交易的交易可能已计入摘要，不附加历史
+	late := swap("late", 101)
+	h.attachWalletContext(ctx, late)
+	if late.WalletContext.FetchedAt != 0 || late.WalletContext.PriorTrades != 0 {
+		t.Fatalf("乱序的交易不应附加历史: %+v", *late.WalletContext)
+	}
+	cached, err := storage.GetRedisClient(storage.WorkloadCache).GetWalletHistory(ctx, "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Trades != 5 || cached.LatestSlot != 102 {
+		t.Fatalf("摘要应计入之后的两笔交易: %+v", *cached)
+	}
+}
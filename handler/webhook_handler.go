@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/life2you/datas-go/rpc"
+)
+
+// HandleWebhookEvents 处理Enhanced Webhook推送的交易，与Enhanced API的解析结果相同，
+// 未被过滤的交易建立索引并发布到事件管道
+// 参数:
+//   - ctx: 上下文
+//   - events: 回调中的交易，调用方已丢弃重复投递的事件
+func (h *Handler) HandleWebhookEvents(ctx context.Context, events []rpc.WebhookEvent) {
+	for i := range events {
+		h.storeTransaction(ctx, events[i].Slot, &events[i])
+	}
+}
@@ -0,0 +1,59 @@
+// Package idgen 提供可替换的ID生成器，测试时替换为 Sequence 可以得到固定的ID
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+// Generator ID生成器
+type Generator interface {
+	NewID() string
+}
+
+// Random 生成16位十六进制随机ID
+type Random struct{}
+
+// NewID 生成随机ID，读取随机数失败时使用当前时间
+func (Random) NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", clock.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Sequence 按顺序生成 前缀+序号 形式的ID，如 test-1、test-2
+type Sequence struct {
+	prefix string
+	next   atomic.Uint64
+}
+
+// NewSequence 创建从1开始的顺序ID生成器
+func NewSequence(prefix string) *Sequence {
+	return &Sequence{prefix: prefix}
+}
+
+// NewID 生成下一个ID
+func (s *Sequence) NewID() string {
+	return fmt.Sprintf("%s%d", s.prefix, s.next.Add(1))
+}
+
+// GlobalGenerator 全局ID生成器，默认生成随机ID
+var GlobalGenerator Generator = Random{}
+
+// SetGenerator 替换全局ID生成器，返回恢复原生成器的函数
+func SetGenerator(g Generator) (restore func()) {
+	previous := GlobalGenerator
+	GlobalGenerator = g
+	return func() { GlobalGenerator = previous }
+}
+
+// New 使用全局ID生成器生成ID
+func New() string {
+	return GlobalGenerator.NewID()
+}
@@ -0,0 +1,206 @@
+// Package cron 解析定时任务的调度表达式，支持标准的5段cron表达式(分 时 日 月 周)、
+// @hourly、@daily 等预定义表达式和 @every <时长>，按本地时区计算下一次执行时间
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 调度表达式
+type Schedule interface {
+	// Next 返回严格晚于 t 的下一次执行时间
+	Next(t time.Time) time.Time
+}
+
+// 预定义表达式
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field 单个字段的取值范围和别名
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = []field{
+	{name: "分", min: 0, max: 59},
+	{name: "时", min: 0, max: 23},
+	{name: "日", min: 1, max: 31},
+	{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// 周日可以写0或7
+	{name: "周", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// Parse 解析调度表达式
+// 参数:
+//   - spec: 5段cron表达式、预定义表达式或 @every <时长>
+//
+// 返回:
+//   - Schedule: 调度表达式
+//   - error: 表达式无效时返回错误
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("调度间隔无效: %w", err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("调度间隔不能小于1秒: %s", interval)
+		}
+		return everySchedule(interval), nil
+	}
+	if expanded, ok := descriptors[spec]; ok {
+		spec = expanded
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron表达式需要5段(分 时 日 月 周): %q", spec)
+	}
+	var schedule cronSchedule
+	sets := []*uint64{&schedule.minute, &schedule.hour, &schedule.dom, &schedule.month, &schedule.dow}
+	for i, part := range parts {
+		bits, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		*sets[i] = bits
+	}
+	// 周日统一为0
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow = schedule.dow&^(1<<7) | 1
+	}
+	schedule.domStar = parts[2] == "*" || parts[2] == "?"
+	schedule.dowStar = parts[4] == "*" || parts[4] == "?"
+	return schedule, nil
+}
+
+// parseField 解析一个字段，返回按位表示的取值集合
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s字段的步长无效: %q", f.name, item)
+			}
+		}
+		low, high := f.min, f.max
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = parseValue(lowExpr, f); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highExpr, f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s字段的范围无效: %q", f.name, item)
+			}
+		default:
+			value, err := parseValue(rangeExpr, f)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			// 只有起始值时步长作用到字段的最大值，如 5/15 表示 5,20,35,50
+			if !hasStep {
+				high = value
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// parseValue 解析字段中的单个数值或别名
+func parseValue(expr string, f field) (int, error) {
+	if value, ok := f.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s字段的取值必须在%d到%d之间: %q", f.name, f.min, f.max, expr)
+	}
+	return value, nil
+}
+
+// everySchedule 按固定间隔执行，从整点对齐的时间开始计算
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	interval := time.Duration(s)
+	return t.Truncate(interval).Add(interval)
+}
+
+// cronSchedule 5段cron表达式，每个字段按位表示允许的取值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Next 按分钟、小时、日期逐级查找下一次匹配的时间，最多查找5年
+func (s cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日和周都有限制时满足其一即可，与标准cron一致
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dowMatch
+	case s.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
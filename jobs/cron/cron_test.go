@@ -0,0 +1,114 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		expr  string
+		field int
+		want  []int
+	}{
+		{"*", 1, rangeOf(0, 23)},
+		{"5", 0, []int{5}},
+		{"1-5", 4, []int{1, 2, 3, 4, 5}},
+		{"*/15", 0, []int{0, 15, 30, 45}},
+		{"5/20", 0, []int{5, 25, 45}},
+		{"10-20/5", 0, []int{10, 15, 20}},
+		{"1,15,31", 2, []int{1, 15, 31}},
+		{"1-3,10-11", 1, []int{1, 2, 3, 10, 11}},
+		{"jan,Mar-may", 3, []int{1, 3, 4, 5}},
+		{"mon-fri", 4, []int{1, 2, 3, 4, 5}},
+		{"?", 2, rangeOf(1, 31)},
+	}
+	for _, tt := range tests {
+		bits, err := parseField(tt.expr, fields[tt.field])
+		if err != nil {
+			t.Fatalf("%s字段 %q: %v", fields[tt.field].name, tt.expr, err)
+		}
+		var want uint64
+		for _, value := range tt.want {
+			want |= 1 << value
+		}
+		if bits != want {
+			t.Errorf("%s字段 %q = %b，期望 %b", fields[tt.field].name, tt.expr, bits, want)
+		}
+	}
+}
+
+// rangeOf 返回 [low, high] 内的所有整数
+func rangeOf(low, high int) []int {
+	var values []int
+	for value := low; value <= high; value++ {
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@every 500ms",
+		"@every soon",
+		"@fortnightly",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("表达式 %q 应解析失败", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2024-03-15 是周五
+	from := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * mon-fri", time.Date(2024, 3, 18, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// 日和周都有限制时满足其一即可
+		{"0 0 20 * 6", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		// 周日可以写7
+		{"0 12 * * 7", time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", time.Date(2024, 3, 15, 10, 10, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.spec, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q 的下一次执行时间 %s，期望 %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNeverMatches(t *testing.T) {
+	schedule, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Fatalf("不存在的日期不应匹配，实际 %s", next)
+	}
+}
@@ -0,0 +1,319 @@
+// Package jobs 在服务内按调度表达式执行定时任务
+// 任务由各模块通过 Register 注册实现，通过 jobs.jobs 配置调度；多个实例共用一个Redis时，
+// 每次调度只由获取到执行锁的实例执行，执行记录保存在Redis中，也可以通过管理接口手动触发
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/jobs/cron"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+)
+
+// 调度标记的最长保留时长，调度间隔更短时按间隔保留
+const maxFiredTTL = 24 * time.Hour
+
+var (
+	// ErrJobNotFound 任务没有配置或没有注册
+	ErrJobNotFound = errors.New("定时任务不存在")
+	// ErrJobRunning 任务正在执行
+	ErrJobRunning = errors.New("定时任务正在执行")
+)
+
+// Func 任务的实现，ctx 在超时或服务退出时取消
+type Func func(ctx context.Context) error
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Func)
+)
+
+// Register 注册任务的实现，同名任务后注册的覆盖先注册的，需要在创建调度器之前注册
+func Register(name string, fn Func) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = fn
+}
+
+// Registered 返回已注册的任务名称，按名称排序
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// GlobalScheduler 全局定时任务调度器，未启用时为nil
+var GlobalScheduler *Scheduler
+
+// job 调度中的任务
+type job struct {
+	name     string
+	spec     string
+	schedule cron.Schedule // 只能手动触发时为nil
+	timeout  time.Duration
+	fn       Func
+
+	mu      sync.Mutex
+	next    time.Time
+	running bool
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	jobs     []*job
+	instance string
+	history  int64
+	log      *zap.Logger
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewScheduler 按配置创建定时任务调度器并设置为全局实例
+// 参数:
+//   - config: 定时任务配置
+//   - instance: 当前实例ID，作为执行锁的持有者
+//
+// 返回:
+//   - *Scheduler: 调度器
+//   - error: 配置的任务没有注册或调度表达式无效时返回错误
+func NewScheduler(config *configs.JobsConfig, instance string) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler := &Scheduler{
+		instance: instance,
+		history:  int64(config.History),
+		log:      logger.Named("jobs").With(zap.String("instance", instance)),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, jobConfig := range config.Jobs {
+		registryMu.RLock()
+		fn, ok := registry[jobConfig.Name]
+		registryMu.RUnlock()
+		if !ok {
+			cancel()
+			return nil, fmt.Errorf("%w: %s，已注册的任务: %v", ErrJobNotFound, jobConfig.Name, Registered())
+		}
+		j := &job{
+			name:    jobConfig.Name,
+			spec:    jobConfig.Schedule,
+			timeout: cmp.Or(jobConfig.Timeout, config.Timeout),
+			fn:      fn,
+		}
+		if jobConfig.Schedule != "" {
+			schedule, err := cron.Parse(jobConfig.Schedule)
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("任务 %s 的调度表达式无效: %w", jobConfig.Name, err)
+			}
+			j.schedule = schedule
+		}
+		scheduler.jobs = append(scheduler.jobs, j)
+	}
+	GlobalScheduler = scheduler
+	return scheduler, nil
+}
+
+// Start 为每个有调度表达式的任务启动调度协程
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		if j.schedule == nil {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	s.log.Info("定时任务调度已启动", zap.Int("jobs", len(s.jobs)))
+}
+
+// Close 停止调度并取消正在执行的任务，等待调度协程退出
+func (s *Scheduler) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop 等待任务的下一次计划执行时间并执行，执行期间到期的调度被跳过
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+	for {
+		next := j.schedule.Next(clock.Now())
+		if next.IsZero() {
+			s.log.Warn("任务没有下一次执行时间，停止调度", zap.String("job", j.name), zap.String("schedule", j.spec))
+			return
+		}
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-clock.After(next.Sub(clock.Now())):
+		}
+		// 调度标记保留到下一次调度，覆盖各实例之间的时钟偏差
+		firedTTL := min(j.schedule.Next(next).Sub(next), maxFiredTTL)
+		if err := s.acquire(j, next, firedTTL); err != nil {
+			if !errors.Is(err, ErrJobRunning) {
+				s.log.Warn("获取任务执行锁失败，跳过本次调度", zap.String("job", j.name), zap.Error(err))
+			}
+			continue
+		}
+		s.run(j, models.JobTriggerSchedule, next)
+	}
+}
+
+// Trigger 手动执行任务，获取执行锁后在后台执行
+// 返回:
+//   - error: 任务不存在时返回 ErrJobNotFound，任务正在执行时返回 ErrJobRunning
+func (s *Scheduler) Trigger(name string) error {
+	j := s.find(name)
+	if j == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if err := s.acquire(j, time.Time{}, 0); err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(j, models.JobTriggerManual, time.Time{})
+	}()
+	return nil
+}
+
+// find 按名称查找任务
+func (s *Scheduler) find(name string) *job {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// acquire 获取任务的执行锁，任务正在执行或本次调度已由其他实例执行时返回 ErrJobRunning
+func (s *Scheduler) acquire(j *job, scheduledAt time.Time, firedTTL time.Duration) error {
+	j.mu.Lock()
+	running := j.running
+	j.mu.Unlock()
+	if running {
+		return ErrJobRunning
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	acquired, err := storage.GetRedisClient(storage.WorkloadQueue).AcquireJobLock(ctx, j.name, s.instance, scheduledAt, j.timeout, firedTTL)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrJobRunning
+	}
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+	return nil
+}
+
+// run 执行已获取执行锁的任务，结束后释放执行锁并记录执行结果
+func (s *Scheduler) run(j *job, trigger string, scheduledAt time.Time) {
+	run := models.JobRun{
+		Job:         j.name,
+		Instance:    s.instance,
+		Trigger:     trigger,
+		ScheduledAt: scheduledAt,
+		StartedAt:   clock.Now(),
+	}
+	s.log.Info("开始执行任务", zap.String("job", j.name), zap.String("trigger", trigger))
+
+	ctx, cancel := context.WithTimeout(s.ctx, j.timeout)
+	err := s.call(ctx, j)
+	cancel()
+
+	run.FinishedAt = clock.Now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Status = models.JobRunSuccess
+	if err != nil {
+		run.Status = models.JobRunFailed
+		run.Error = err.Error()
+		s.log.Error("任务执行失败", zap.String("job", j.name), zap.Int64("duration_ms", run.DurationMs), zap.Error(err))
+	} else {
+		s.log.Info("任务执行完成", zap.String("job", j.name), zap.Int64("duration_ms", run.DurationMs))
+	}
+
+	// 服务退出时 s.ctx 已取消，释放锁和记录结果使用独立的上下文
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	if err := redisClient.ReleaseJobLock(ctx, j.name, s.instance); err != nil {
+		s.log.Warn("释放任务执行锁失败，锁在超时后自动释放", zap.String("job", j.name), zap.Error(err))
+	}
+	if err := redisClient.RecordJobRun(ctx, run, s.history); err != nil {
+		s.log.Warn("记录任务执行结果失败", zap.String("job", j.name), zap.Error(err))
+	}
+	j.mu.Lock()
+	j.running = false
+	j.mu.Unlock()
+}
+
+// call 执行任务，任务panic时转为错误
+func (s *Scheduler) call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("任务panic: %v", recovered)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+// Status 返回所有任务的调度状态，按配置顺序排列
+func (s *Scheduler) Status(ctx context.Context) []models.JobStatus {
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	statuses := make([]models.JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		status := models.JobStatus{
+			Name:     j.name,
+			Schedule: j.spec,
+			Timeout:  j.timeout,
+			NextRun:  j.next,
+			Running:  j.running,
+		}
+		j.mu.Unlock()
+		if owner, err := redisClient.GetJobLockOwner(ctx, j.name); err == nil {
+			status.LockedBy = owner
+		}
+		if runs, err := redisClient.GetJobRuns(ctx, j.name, 1); err == nil && len(runs) > 0 {
+			status.LastRun = &runs[0]
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Runs 返回任务最近的执行记录
+// 返回:
+//   - []models.JobRun: 执行记录，最新的在前
+//   - error: 任务不存在时返回 ErrJobNotFound
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int64) ([]models.JobRun, error) {
+	if s.find(name) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return storage.GetRedisClient(storage.WorkloadQueue).GetJobRuns(ctx, name, limit)
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/life2you/datas-go/configs"
+	"go.uber.org/zap/zapcore"
+)
+
+// 项目模块路径前缀，用于从调用位置推断模块名
+const modulePathPrefix = "github.com/life2you/datas-go/"
+
+// levelRegistry 维护全局日志级别和按模块覆盖的日志级别，支持运行时修改
+type levelRegistry struct {
+	mu      sync.RWMutex
+	global  zapcore.Level
+	modules map[string]zapcore.Level
+}
+
+var levels = &levelRegistry{
+	global:  zapcore.InfoLevel,
+	modules: make(map[string]zapcore.Level),
+}
+
+// enabled 判断指定模块是否输出该级别的日志
+func (r *levelRegistry) enabled(module string, level zapcore.Level) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if moduleLevel, ok := r.modules[module]; ok {
+		return level >= moduleLevel
+	}
+	return level >= r.global
+}
+
+// anyEnabled 判断是否有任一模块会输出该级别的日志
+func (r *levelRegistry) anyEnabled(level zapcore.Level) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if level >= r.global {
+		return true
+	}
+	for _, moduleLevel := range r.modules {
+		if level >= moduleLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// reset 使用配置重置所有级别
+func (r *levelRegistry) reset(global zapcore.Level, modules map[string]zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global = global
+	r.modules = modules
+}
+
+// ApplyLevels 使用新的日志配置重置全局级别和模块级别，用于配置热更新
+// 通过管理接口临时修改的级别会被配置文件中的级别覆盖
+func ApplyLevels(cfg *configs.LogConfig) {
+	moduleLevels := make(map[string]zapcore.Level, len(cfg.Levels))
+	for module, moduleLevel := range cfg.Levels {
+		moduleLevels[module] = parseLogLevel(moduleLevel)
+	}
+	levels.reset(parseLogLevel(cfg.Level), moduleLevels)
+}
+
+// SetLevel 运行时修改日志级别
+// 参数:
+//   - module: 模块名，为空时修改全局级别
+//   - level: 日志级别，为空或"default"时删除该模块的覆盖配置
+//
+// 返回:
+//   - error: 错误信息
+func SetLevel(module string, level string) error {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if module != "" && (level == "" || strings.ToLower(level) == "default") {
+		delete(levels.modules, module)
+		return nil
+	}
+
+	parsed, err := zapcore.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return fmt.Errorf("无效的日志级别: %s", level)
+	}
+	if module == "" {
+		levels.global = parsed
+	} else {
+		levels.modules[module] = parsed
+	}
+	return nil
+}
+
+// Levels 返回当前的全局级别和各模块级别
+func Levels() (string, map[string]string) {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	modules := make(map[string]string, len(levels.modules))
+	for module, level := range levels.modules {
+		modules[module] = level.String()
+	}
+	return levels.global.String(), modules
+}
+
+// moduleOf 根据日志名或调用位置推断模块名
+// 日志名取第一段(如 rpc.websocket -> rpc)，否则取调用函数所在的顶层包名
+func moduleOf(entry zapcore.Entry) string {
+	if entry.LoggerName != "" {
+		name, _, _ := strings.Cut(entry.LoggerName, ".")
+		return name
+	}
+	function := entry.Caller.Function
+	if !strings.HasPrefix(function, modulePathPrefix) {
+		return "main"
+	}
+	function = strings.TrimPrefix(function, modulePathPrefix)
+	if i := strings.IndexAny(function, "/."); i >= 0 {
+		return function[:i]
+	}
+	return function
+}
+
+// moduleCore 在底层输出核心之前按模块级别过滤日志
+type moduleCore struct {
+	zapcore.Core
+}
+
+// Enabled 只要有模块需要该级别即返回true，具体过滤在Check/Write中进行
+func (c *moduleCore) Enabled(level zapcore.Level) bool {
+	return levels.anyEnabled(level)
+}
+
+// With 添加字段
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{Core: c.Core.With(fields)}
+}
+
+// Check 命名日志在此处即可过滤，未命名日志需等到调用位置确定后在Write中过滤
+func (c *moduleCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !levels.anyEnabled(entry.Level) {
+		return checked
+	}
+	if entry.LoggerName != "" && !levels.enabled(moduleOf(entry), entry.Level) {
+		return checked
+	}
+	return checked.AddCore(entry, c)
+}
+
+// Write 按模块级别过滤后写入
+func (c *moduleCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !levels.enabled(moduleOf(entry), entry.Level) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
@@ -28,8 +28,9 @@ func Init(cfg *configs.LogConfig) {
 		}
 	}
 
-	// 解析日志级别
-	level := parseLogLevel(cfg.Level)
+	// 解析日志级别，按模块的覆盖级别由moduleCore过滤，底层输出核心接收所有级别
+	ApplyLevels(cfg)
+	level := zapcore.DebugLevel
 
 	// 创建Encoder
 	encoderConfig := zapcore.EncoderConfig{
@@ -88,16 +89,22 @@ func Init(cfg *configs.LogConfig) {
 		cores = append(cores, consoleCore)
 	}
 
+	// 记录最近的错误日志，供诊断快照使用
+	recentErrors.reset(cfg.RecentErrors)
+	if cfg.RecentErrors > 0 {
+		cores = append(cores, &errorRecorderCore{})
+	}
+
 	// 创建Logger
-	core := zapcore.NewTee(cores...)
+	core := &moduleCore{Core: zapcore.NewTee(cores...)}
 	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
 	// 替换全局Logger
 	Logger = logger
 	Sugar = logger.Sugar()
 
-	// 替换全局zap logger
-	zap.ReplaceGlobals(logger)
+	// 替换全局zap logger，直接调用zap.L()时不需要额外跳过封装函数这一层
+	zap.ReplaceGlobals(logger.WithOptions(zap.AddCallerSkip(-1)))
 }
 
 // Close 关闭日志系统
@@ -184,6 +191,16 @@ func With(fields ...zap.Field) *zap.Logger {
 	return Logger.With(fields...)
 }
 
+// Named 返回带名称的Logger，可直接调用其方法输出日志
+// 名称的第一段作为模块名参与按模块的级别过滤，如 rpc.websocket 属于 rpc 模块
+// 日志系统尚未初始化时返回空Logger
+func Named(name string) *zap.Logger {
+	if Logger == nil {
+		return zap.NewNop()
+	}
+	return Logger.WithOptions(zap.AddCallerSkip(-1)).Named(name)
+}
+
 // Withf 返回一个带有字段的SugaredLogger
 func Withf(args ...interface{}) *zap.SugaredLogger {
 	return Sugar.With(args...)
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrorEntry 最近记录的一条错误日志
+type ErrorEntry struct {
+	Time    time.Time      `json:"time"`             // 记录时间
+	Level   string         `json:"level"`            // 日志级别
+	Logger  string         `json:"logger,omitempty"` // 模块日志名称
+	Message string         `json:"msg"`              // 日志内容
+	Caller  string         `json:"caller,omitempty"` // 调用位置
+	Fields  map[string]any `json:"fields,omitempty"` // 结构化字段
+}
+
+// errorRing 保存最近N条错误日志的环形缓冲区
+type errorRing struct {
+	mu      sync.Mutex
+	entries []ErrorEntry
+	next    int  // 下一条写入的位置
+	full    bool // 缓冲区是否已写满一轮
+}
+
+// recentErrors 全局错误日志缓冲区，容量由 log.recent_errors 设置
+var recentErrors errorRing
+
+// reset 设置缓冲区容量并清空已记录的日志，size为0时不再记录
+func (r *errorRing) reset(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make([]ErrorEntry, max(size, 0))
+	r.next = 0
+	r.full = false
+}
+
+// add 记录一条日志，缓冲区已满时覆盖最早的一条
+func (r *errorRing) add(entry ErrorEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list 按记录时间从新到旧返回所有日志
+func (r *errorRing) list() []ErrorEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []ErrorEntry
+	if r.full {
+		entries = append(slices.Clone(r.entries[r.next:]), r.entries[:r.next]...)
+	} else {
+		entries = slices.Clone(r.entries[:r.next])
+	}
+	slices.Reverse(entries)
+	return entries
+}
+
+// RecentErrors 返回最近记录的错误日志(error及以上级别)，最新的在前
+func RecentErrors() []ErrorEntry {
+	return recentErrors.list()
+}
+
+// errorRecorderCore 将error及以上级别的日志写入 recentErrors 的输出核心
+type errorRecorderCore struct {
+	fields []zapcore.Field
+}
+
+// Enabled 只记录error及以上级别
+func (c *errorRecorderCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.ErrorLevel
+}
+
+// With 添加字段
+func (c *errorRecorderCore) With(fields []zapcore.Field) zapcore.Core {
+	return &errorRecorderCore{fields: append(slices.Clone(c.fields), fields...)}
+}
+
+// Check 级别满足时加入输出核心
+func (c *errorRecorderCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 将日志及其字段转为 ErrorEntry 记录
+// moduleCore 通过Tee直接调用Write，不经过本核心的Check，因此在这里再按级别过滤
+func (c *errorRecorderCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(entry.Level) {
+		return nil
+	}
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	record := ErrorEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+	}
+	if entry.Caller.Defined {
+		record.Caller = entry.Caller.TrimmedPath()
+	}
+	if len(encoder.Fields) > 0 {
+		record.Fields = encoder.Fields
+	}
+	recentErrors.add(record)
+	return nil
+}
+
+// Sync 无需刷新
+func (c *errorRecorderCore) Sync() error {
+	return nil
+}
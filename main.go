@@ -1,32 +1,95 @@
 package main
 
 import (
+	"context"
 	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/jobs"
+	"maps"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/api"
 	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/cursor"
+	"github.com/life2you/datas-go/export"
 	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/pipeline"
 	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/rules"
 	"github.com/life2you/datas-go/service"
 	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/tracing"
+	"github.com/life2you/datas-go/watchlist"
 )
 
 func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runServe 启动数据采集服务，阻塞直到收到退出信号
+func runServe() {
 	// 启动步骤
 	// 1. 初始化配置
-	configs.LoadConfig("")
-
 	// 2. 初始化日志
-	logger.Init(&configs.GlobalConfig.Log)
+	loadConfig()
+
+	// 2.1 配置热更新
+	if configs.GlobalConfig.App.HotReload {
+		configs.OnChange("log", func(oldConfig, newConfig *configs.Config) {
+			if oldConfig.Log.Level != newConfig.Log.Level || !maps.Equal(oldConfig.Log.Levels, newConfig.Log.Levels) {
+				logger.ApplyLevels(&newConfig.Log)
+				logger.Info("日志级别已热更新", zap.String("level", newConfig.Log.Level), zap.Any("levels", newConfig.Log.Levels))
+			}
+		})
+		configs.WatchConfig()
+	}
+
+	// 2.2 链路追踪，需在各客户端开始接收数据之前启用
+	if err := tracing.Init(&configs.GlobalConfig.Tracing, monitor.InstanceID()); err != nil {
+		logger.Fatal("启用链路追踪失败", zap.Error(err))
+	}
+
+	// 2.3 启动管理接口
+	if configs.GlobalConfig.Admin.Enabled {
+		api.NewServer(&configs.GlobalConfig.Admin).Start()
+		if configs.GlobalConfig.Admin.AuthToken == "" {
+			logger.Warn("未设置 admin.auth_token，管理接口只提供查询，修改类接口已禁用")
+		}
+	}
+
+	// 2.4 初始化进程内事件管道
+	pipeline.NewPipeline(&configs.GlobalConfig.Pipeline)
+
+	// 2.5 解析失败的原始数据抽样，需在各客户端开始接收数据之前创建
+	if configs.GlobalConfig.PayloadSamples.Enabled {
+		payload.NewSampler(&configs.GlobalConfig.PayloadSamples)
+	}
 
 	// 3. 初始化redis
 	storage.NewRedisClient(&configs.GlobalConfig.Redis)
 
+	// 3.1 加载关注地址并启动规则引擎，关注地址的通知由规则引擎按各地址的通知设置发送
+	if configs.GlobalConfig.Rules.Enabled || configs.GlobalConfig.Watchlist.Enabled {
+		if err := watchlist.NewWatchlist().Start(); err != nil {
+			logger.Fatal("加载关注地址失败", zap.Error(err))
+		}
+	}
+	if configs.GlobalConfig.Rules.Enabled {
+		if err := rules.NewEngine(&configs.GlobalConfig.Rules).Start(pipeline.GlobalPipeline); err != nil {
+			logger.Fatal("启动规则引擎失败", zap.Error(err))
+		}
+	}
+
 	// 4. 定义RPC回调函数
 	rpcCallBack := func() {
 		logger.Info("WebSocket连接成功")
@@ -34,26 +97,189 @@ func main() {
 
 	// 5. 初始化队列
 	initQueue()
+	// 区块和交易队列的处理器，跳过列表重试的槽位推入其区块队列
+	eventHandler := handler.NewDefaultHandler()
+
+	// 载入上次停止时未处理完的交易
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if restored, err := storage.RestoreTransactionQueue(ctx); err != nil {
+		logger.Error("载入未处理完的交易失败", zap.Error(err))
+	} else if restored > 0 {
+		logger.Info("已载入未处理完的交易", zap.Int("区块数", restored))
+	}
+	cancel()
+
+	// 加载区块游标，用于续传和发现缺口
+	if _, err := cursor.NewSlotCursor(&configs.GlobalConfig.Parser); err != nil {
+		logger.Fatal("加载区块游标失败", zap.Error(err))
+	}
+
+	// 网络拥堵感知限流，需在队列初始化之后创建
+	if configs.GlobalConfig.Congestion.Enabled {
+		monitor.NewCongestionMonitor(&configs.GlobalConfig.Congestion)
+	}
+
+	// Enhanced API密钥用量统计，当天用量达到预算的密钥不再分配解析批次，需在Redis初始化之后创建
+	rpc.SetEnhancedUsageRecorder(monitor.NewAPIKeyUsageTracker(&configs.GlobalConfig.HeliusEnhancedAPI))
+
+	// 计算单元价格统计，用于推荐优先费
+	if configs.GlobalConfig.PriorityFee.Enabled {
+		analytics.NewPriorityFeeTracker(&configs.GlobalConfig.PriorityFee)
+	}
+
+	// 按程序统计手续费，需在Redis初始化之后创建
+	if configs.GlobalConfig.ProgramFees.Enabled {
+		analytics.NewProgramFeeTracker(&configs.GlobalConfig.ProgramFees).Start()
+	}
+
+	// 容量规划指标快照，需在队列初始化之后创建
+	if configs.GlobalConfig.Capacity.Enabled {
+		monitor.NewCapacityRecorder(&configs.GlobalConfig.Capacity).Start()
+	}
+
+	// 解析结果抽样校验，核对Enhanced API结果与原始区块
+	if configs.GlobalConfig.Verification.Enabled {
+		monitor.NewVerifier(&configs.GlobalConfig.Verification).Start()
+	}
+
+	// 原始响应归档文件，按槽位范围滚动并原子地写入输出目录
+	if configs.GlobalConfig.RawArchive.Enabled && configs.GlobalConfig.RawArchive.Dir != "" {
+		export.NewRawArchive(&configs.GlobalConfig.RawArchive).Start()
+	}
+
+	// 解析结果和区块统计按批写入ClickHouse，供长期保存和分析查询
+	if configs.GlobalConfig.ClickHouse.Enabled {
+		export.NewClickHouseSink(&configs.GlobalConfig.ClickHouse).Start(pipeline.GlobalPipeline)
+	}
+
+	// 区块处理状态跟踪，卡住的区块会重新推入区块队列
+	if configs.GlobalConfig.BlockState.Enabled {
+		monitor.NewBlockStateTracker(&configs.GlobalConfig.BlockState).Start()
+	}
+
+	// 跳过列表，多次获取失败的槽位按更长的间隔继续重试
+	if configs.GlobalConfig.SkippedSlots.Enabled {
+		monitor.NewSlotSkipList(&configs.GlobalConfig.SkippedSlots, eventHandler.Blocks()).Start()
+	}
+
+	// 多实例分布式处理，同一区块只由获取到租约的实例处理
+	if configs.GlobalConfig.Distributed.Enabled {
+		monitor.NewCoordinator(&configs.GlobalConfig.Distributed).Start()
+	}
+
+	// 最终确认检查，未被最终确认的槽位会删除其数据
+	if configs.GlobalConfig.Finality.Enabled {
+		monitor.NewFinalityChecker(&configs.GlobalConfig.Finality).Start(pipeline.GlobalPipeline)
+	}
 
 	// 5. 配置WebSocket
 	configs.GlobalConfig.WebSocket.OnConnect = rpcCallBack
-	// 如果RPC配置中有代理URL，则使用它
-	if configs.GlobalConfig.Proxy.Enabled && configs.GlobalConfig.Proxy.URL != "" {
-		logger.Info("使用代理连接Helius", zap.String("proxy", configs.GlobalConfig.Proxy.URL))
-		configs.GlobalConfig.WebSocket.ProxyURL = configs.GlobalConfig.Proxy.URL
-		configs.GlobalConfig.HeliusAPI.ProxyURL = configs.GlobalConfig.Proxy.URL
-		configs.GlobalConfig.HeliusEnhancedAPI.ProxyURL = configs.GlobalConfig.Proxy.URL
-		configs.GlobalConfig.PumpPortal.ProxyURL = configs.GlobalConfig.Proxy.URL
+	// 将全局代理应用到未单独设置代理的客户端，并检查各客户端的代理是否可用
+	applyProxyConfig()
+	// 5.1 按配置声明同步Helius Webhook
+	if configs.GlobalConfig.HeliusWebhook.Sync {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		if err := service.SyncWebhooks(ctx, &configs.GlobalConfig.HeliusWebhook); err != nil {
+			logger.Error("同步Webhook失败", zap.Error(err))
+		}
+		cancel()
+	}
+	// 热更新后Webhook声明(监控的地址、交易类型)有变化时重新同步
+	configs.OnChange("helius_webhook", func(oldConfig, newConfig *configs.Config) {
+		if !newConfig.HeliusWebhook.Sync || reflect.DeepEqual(oldConfig.HeliusWebhook.Webhooks, newConfig.HeliusWebhook.Webhooks) {
+			return
+		}
+		config := newConfig.HeliusWebhook
+		if config.ProxyURL == "" {
+			config.ProxyURL = configs.GlobalConfig.HeliusWebhook.ProxyURL // 启动时应用的全局代理
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		if err := service.SyncWebhooks(ctx, &config); err != nil {
+			logger.Error("热更新后同步Webhook失败", zap.Error(err))
+		}
+	})
+	if configs.GlobalConfig.JupiterPrice.Enabled {
+		rpc.NewJupiterPriceClient(&configs.GlobalConfig.JupiterPrice).Start()
+	}
+	// 5.2 PumpPortal交易API，仅在显式启用时创建
+	if configs.GlobalConfig.PumpPortalTrade.Enabled {
+		if _, err := rpc.NewPumpPortalTradeClient(&configs.GlobalConfig.PumpPortalTrade); err != nil {
+			logger.Fatal("创建PumpPortal交易客户端失败", zap.Error(err))
+		}
+		if !configs.GlobalConfig.PumpPortalTrade.DryRun {
+			logger.Warn("PumpPortal自动交易已启用，交易规则将实际下单",
+				zap.String("mode", configs.GlobalConfig.PumpPortalTrade.Mode),
+				zap.Strings("rule_ids", configs.GlobalConfig.PumpPortalTrade.RuleIDs))
+		}
 	}
 	rpc.NewPumpPortalClient(&configs.GlobalConfig.PumpPortal, handler.PumpPortalHandler)
 	service.StartPumpPortalService()
-	//initClient()
-	// 7. 启动服务，不需要阻塞
-	// initStartService()
+	// 关注地址增删后同步到Helius Webhook和PumpPortal账户交易订阅
+	if watchlist.GlobalWatchlist != nil {
+		service.StartWatchlistSync(watchlist.GlobalWatchlist, &configs.GlobalConfig.Watchlist)
+	}
+	if configs.GlobalConfig.OrderFlow.Enabled {
+		service.StartOrderFlowService()
+	}
+	if configs.GlobalConfig.TokenAccounts.Enabled {
+		service.StartTokenAccountService()
+	}
+	if configs.GlobalConfig.SourceVolume.Enabled {
+		analytics.NewSourceVolumeTracker(&configs.GlobalConfig.SourceVolume).Start(pipeline.GlobalPipeline)
+	}
+	if configs.GlobalConfig.TokenStats.Enabled {
+		service.StartTokenStatsService()
+	}
+	if configs.GlobalConfig.BondingCurve.Enabled {
+		service.StartBondingCurveService()
+	}
+	if configs.GlobalConfig.TokenRisk.Enabled {
+		analytics.NewTokenRiskAnalyzer(&configs.GlobalConfig.TokenRisk).Start(pipeline.GlobalPipeline)
+	}
+	if configs.GlobalConfig.Positions.Enabled {
+		service.StartPositionService()
+	}
+	// 流动性池创建监控，Helius WebSocket连接建立后订阅AMM程序日志
+	if configs.GlobalConfig.PoolWatcher.Enabled {
+		service.StartPoolWatcher(&configs.GlobalConfig.PoolWatcher)
+	}
+	service.StartTransactionIndexCleanup()
+	if configs.GlobalConfig.Jobs.Enabled {
+		if err := service.StartJobs(); err != nil {
+			logger.Error("启动定时任务失败", zap.Error(err))
+		}
+	}
+	// 7. 按依赖顺序启动采集流程各阶段（接入 → 区块拉取 → 解析 → 存储），不需要阻塞
+	if configs.GlobalConfig.Pipeline.Stages.Enabled {
+		stages := pipeline.NewStages(&configs.GlobalConfig.Pipeline.Stages)
+		service.RegisterStages(stages, eventHandler)
+		go func() {
+			if err := stages.Run(context.Background()); err != nil {
+				logger.Error("采集流程启动失败", zap.Error(err))
+			}
+		}()
+	}
+
+	// 7.1 接收Helius Webhook回调，与解析结果一样建立索引并发布到事件管道
+	if configs.GlobalConfig.HeliusWebhook.Receiver.Enabled {
+		api.NewWebhookServer(&configs.GlobalConfig.HeliusWebhook, eventHandler.HandleWebhookEvents).Start()
+	}
 
 	// 8. 在主协程中打印状态信息
 	logger.Info("程序已启动，正在等待区块数据...")
 
+	// 收到SIGUSR1时将运行时诊断快照写入日志，如 kill -USR1 <pid>
+	dump := make(chan os.Signal, 1)
+	signal.Notify(dump, syscall.SIGUSR1)
+	go func() {
+		for range dump {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			monitor.DumpDiagnostics(ctx, "signal")
+			cancel()
+		}
+	}()
+
 	// 9. 阻止程序退出
 	// 添加信号处理代码
 	c := make(chan os.Signal, 1)
@@ -62,50 +288,104 @@ func main() {
 		<-c
 		logger.Info("接收到退出信号，程序即将关闭...")
 		// 执行清理操作
-		if rpc.GlobalWebSocketClient != nil {
-			rpc.GlobalWebSocketClient.Close()
+		if api.GlobalServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			api.GlobalServer.Close(ctx)
+			cancel()
+		}
+		if api.GlobalWebhookServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			api.GlobalWebhookServer.Close(ctx)
+			cancel()
+		}
+		// 先放弃摄取领导者身份并取消订阅，备用实例随即接管
+		if monitor.GlobalLeaderElector != nil {
+			monitor.GlobalLeaderElector.Close()
+		}
+		if rpc.GlobalWebSocketPool != nil {
+			rpc.GlobalWebSocketPool.Close()
+		}
+		if monitor.GlobalStallDetector != nil {
+			monitor.GlobalStallDetector.Close()
+		}
+		if monitor.GlobalSubscriptionWatchdog != nil {
+			monitor.GlobalSubscriptionWatchdog.Close()
+		}
+		if monitor.GlobalFinalityChecker != nil {
+			monitor.GlobalFinalityChecker.Close()
+		}
+		if monitor.GlobalBlockStateTracker != nil {
+			monitor.GlobalBlockStateTracker.Close()
+		}
+		if monitor.GlobalSlotSkipList != nil {
+			monitor.GlobalSlotSkipList.Close()
 		}
-		if storage.GlobalRedisClient != nil {
-			storage.GlobalRedisClient.Close()
+		if monitor.GlobalCoordinator != nil {
+			monitor.GlobalCoordinator.Close()
 		}
+		if jobs.GlobalScheduler != nil {
+			jobs.GlobalScheduler.Close()
+		}
+		if monitor.GlobalCapacityRecorder != nil {
+			monitor.GlobalCapacityRecorder.Close()
+		}
+		if monitor.GlobalVerifier != nil {
+			monitor.GlobalVerifier.Close()
+		}
+		if rpc.GlobalJupiterPriceClient != nil {
+			rpc.GlobalJupiterPriceClient.Close()
+		}
+		if rules.GlobalEngine != nil {
+			rules.GlobalEngine.Close()
+		}
+		if watchlist.GlobalWatchlist != nil {
+			watchlist.GlobalWatchlist.Close()
+		}
+		if analytics.GlobalSourceVolumeTracker != nil {
+			analytics.GlobalSourceVolumeTracker.Close()
+		}
+		if analytics.GlobalProgramFeeTracker != nil {
+			analytics.GlobalProgramFeeTracker.Close()
+		}
+		if analytics.GlobalTokenStatsTracker != nil {
+			analytics.GlobalTokenStatsTracker.Close()
+		}
+		if analytics.GlobalBondingCurveTracker != nil {
+			analytics.GlobalBondingCurveTracker.Close()
+		}
+		if analytics.GlobalTokenRiskAnalyzer != nil {
+			analytics.GlobalTokenRiskAnalyzer.Close()
+		}
+		if analytics.GlobalPositionTracker != nil {
+			analytics.GlobalPositionTracker.Close()
+		}
+		if export.GlobalRawArchive != nil {
+			export.GlobalRawArchive.Close()
+		}
+		if export.GlobalClickHouseSink != nil {
+			export.GlobalClickHouseSink.Close()
+		}
+		// 未处理完的交易转存到Redis，下次启动时继续处理
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if saved, err := storage.SaveTransactionQueue(ctx); err != nil {
+			logger.Error("保存未处理完的交易失败", zap.Error(err))
+		} else if saved > 0 {
+			logger.Info("已保存未处理完的交易", zap.Int("区块数", saved))
+		}
+		cancel()
+		// 结束进行中的槽位span并导出剩余的span
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		if err := tracing.Shutdown(ctx); err != nil {
+			logger.Error("导出剩余的span失败", zap.Error(err))
+		}
+		cancel()
+		storage.CloseRedisClients()
 		os.Exit(0)
 	}()
 
 	select {}
 }
 
-func initClient() {
-	// 6. 初始化WebSocket客户端
-	rpc.NewWebSocketClientOptions(&configs.GlobalConfig.WebSocket)
-	if rpc.GlobalWebSocketClient == nil {
-		logger.Fatal("WebSocket客户端初始化失败")
-	}
-	logger.Info("WebSocket客户端初始化成功")
-
-	// 6.1 初始化Helius HTTP API客户端
-	rpc.NewHeliusClient(&configs.GlobalConfig.HeliusAPI)
-	if rpc.GlobalHeliusClient == nil {
-		logger.Fatal("Helius HTTP API客户端初始化失败")
-	}
-	logger.Info("Helius HTTP API客户端初始化成功")
-
-	// 6.2 初始化Helius Enhanced API客户端
-	rpc.NewHeliusEnhancedApiClient(&configs.GlobalConfig.HeliusEnhancedAPI)
-	if rpc.GlobalHeliusEnhancedApiClients == nil || len(rpc.GlobalHeliusEnhancedApiClients) == 0 {
-		logger.Fatal("Helius Enhanced API客户端初始化失败")
-	}
-	logger.Info("Helius Enhanced API客户端初始化成功")
-}
-
-func initStartService() {
-	service.StartHeliusService()
-	time.Sleep(5 * time.Second)
-	service.ScanBlockQueue()
-	service.ProcessTransactionQueue()
-	service.StartPumpPortalService()
-	logger.Info("所有服务已启动: 区块队列扫描服务、交易队列处理服务、PumpPortal服务")
-}
-
 func initQueue() {
 	storage.InitQueue()
 }
@@ -0,0 +1,58 @@
+package metrics
+
+import "sync"
+
+// APIKeyCounters 单个Enhanced API密钥在进程启动以来的累计用量
+type APIKeyCounters struct {
+	Requests int64            `json:"requests"`        // 请求数
+	Credits  int64            `json:"credits"`         // 消耗的额度，即成功解析请求中的签名数
+	Errors   int64            `json:"errors"`          // 失败的请求数
+	Codes    map[string]int64 `json:"codes,omitempty"` // 按错误码统计的失败请求数
+}
+
+var (
+	apiKeysMu sync.Mutex
+	apiKeys   = make(map[string]*APIKeyCounters)
+)
+
+// AddAPIKeyUsage 记录一次Enhanced API请求
+// 参数:
+//   - keyID: 密钥指纹
+//   - credits: 消耗的额度
+//   - code: 失败请求的错误码，为空表示请求成功
+func AddAPIKeyUsage(keyID string, credits int, code string) {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+	counters, ok := apiKeys[keyID]
+	if !ok {
+		counters = &APIKeyCounters{}
+		apiKeys[keyID] = counters
+	}
+	counters.Requests++
+	counters.Credits += int64(credits)
+	if code != "" {
+		counters.Errors++
+		if counters.Codes == nil {
+			counters.Codes = make(map[string]int64)
+		}
+		counters.Codes[code]++
+	}
+}
+
+// APIKey 返回密钥在进程启动以来的累计用量
+func APIKey(keyID string) APIKeyCounters {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+	counters, ok := apiKeys[keyID]
+	if !ok {
+		return APIKeyCounters{}
+	}
+	snapshot := *counters
+	if counters.Codes != nil {
+		snapshot.Codes = make(map[string]int64, len(counters.Codes))
+		for code, n := range counters.Codes {
+			snapshot.Codes[code] = n
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+const (
+	// 保留的槽位通知时间数量，按每秒约2.5个槽位可覆盖约7分钟
+	slotHistorySize = 1024
+	// 计算出块速度的时间窗口
+	slotRateWindow = time.Minute
+)
+
+// LagStats 采集进度相对网络的延迟
+type LagStats struct {
+	NetworkSlot    uint64    `json:"network_slot"`    // 槽位通知中的最新槽位
+	ProcessedSlot  uint64    `json:"processed_slot"`  // 处理完成的最大槽位
+	LagSlots       uint64    `json:"lag_slots"`       // 落后的槽位数
+	LagSeconds     float64   `json:"lag_seconds"`     // 落后的时长：距离收到第一个未处理槽位通知的时间
+	LagEstimated   bool      `json:"lag_estimated"`   // 第一个未处理槽位的通知时间已不在记录中，落后时长按出块速度估算
+	SlotRate       float64   `json:"slot_rate"`       // 最近一分钟的出块速度(槽位/秒)
+	LastSlotAt     time.Time `json:"last_slot_at"`    // 收到最新槽位通知的时间
+	LastProcessAt  time.Time `json:"last_process_at"` // 最近一次推进已处理槽位的时间
+	GeneratedAt    time.Time `json:"generated_at"`    // 统计时间
+	HistoryEntries int       `json:"history_entries"` // 记录的槽位通知时间数量
+}
+
+// slotObservation 收到某个槽位通知的时间
+type slotObservation struct {
+	slot uint64
+	at   time.Time
+}
+
+// slotHistory 按槽位递增顺序记录槽位通知时间的环形缓冲区
+type slotHistory struct {
+	mu            sync.Mutex
+	entries       [slotHistorySize]slotObservation
+	start, count  int
+	lastProcessAt time.Time
+}
+
+var history slotHistory
+
+// add 记录新的最新槽位
+func (h *slotHistory) add(slot uint64, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count > 0 && h.entries[(h.start+h.count-1)%slotHistorySize].slot >= slot {
+		return
+	}
+	if h.count < slotHistorySize {
+		h.entries[(h.start+h.count)%slotHistorySize] = slotObservation{slot: slot, at: at}
+		h.count++
+		return
+	}
+	h.entries[h.start] = slotObservation{slot: slot, at: at}
+	h.start = (h.start + 1) % slotHistorySize
+}
+
+// processed 记录已处理槽位推进的时间
+func (h *slotHistory) processed(at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastProcessAt = at
+}
+
+// Lag 计算当前的采集延迟和出块速度
+// 落后时长取第一个大于已处理槽位的槽位通知至今的时间，即网络到达该槽位后采集还没有跟上的时长
+func Lag() LagStats {
+	now := clock.Now()
+	stats := LagStats{
+		NetworkSlot:   latestSlot.Load(),
+		ProcessedSlot: processedSlot.Load(),
+		GeneratedAt:   now,
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	stats.HistoryEntries = history.count
+	stats.LastProcessAt = history.lastProcessAt
+	if history.count == 0 {
+		return stats
+	}
+	entry := func(i int) slotObservation {
+		return history.entries[(history.start+i)%slotHistorySize]
+	}
+	newest := entry(history.count - 1)
+	stats.LastSlotAt = newest.at
+
+	// 出块速度：时间窗口内最早和最新通知之间的槽位增量
+	for i := 0; i < history.count-1; i++ {
+		oldest := entry(i)
+		if newest.at.Sub(oldest.at) > slotRateWindow {
+			continue
+		}
+		if elapsed := newest.at.Sub(oldest.at).Seconds(); elapsed > 0 {
+			stats.SlotRate = float64(newest.slot-oldest.slot) / elapsed
+		}
+		break
+	}
+
+	if stats.ProcessedSlot == 0 || stats.NetworkSlot <= stats.ProcessedSlot {
+		return stats
+	}
+	stats.LagSlots = stats.NetworkSlot - stats.ProcessedSlot
+	for i := 0; i < history.count; i++ {
+		if observation := entry(i); observation.slot > stats.ProcessedSlot {
+			if i > 0 || observation.slot == stats.ProcessedSlot+1 {
+				stats.LagSeconds = now.Sub(observation.at).Seconds()
+				return stats
+			}
+			break
+		}
+	}
+	// 已处理槽位早于记录中最早的通知，落后时长至少为最早通知至今的时间，再按出块速度估算取较大值
+	stats.LagEstimated = true
+	stats.LagSeconds = now.Sub(entry(0).at).Seconds()
+	if stats.SlotRate > 0 {
+		stats.LagSeconds = max(stats.LagSeconds, float64(stats.LagSlots)/stats.SlotRate)
+	}
+	return stats
+}
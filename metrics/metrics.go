@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+// Counters 进程启动以来的累计计数
+type Counters struct {
+	Blocks           int64  // 处理完成的区块数
+	Transactions     int64  // Enhanced API解析出的交易数
+	Skipped          int64  // 命中不再解析缓存而跳过的交易数
+	Filtered         int64  // 区块阶段被预过滤、没有推入解析队列的交易数
+	Decoded          int64  // 启用 raw_parse 时在本地解码、没有调用Enhanced API的交易数
+	RPCRequests      int64  // Helius RPC请求数
+	EnhancedRequests int64  // Helius Enhanced API请求数
+	LatestSlot       uint64 // 收到的最新槽位
+	ProcessedSlot    uint64 // 处理完成的最大槽位
+}
+
+var (
+	blocks           atomic.Int64
+	transactions     atomic.Int64
+	skipped          atomic.Int64
+	filtered         atomic.Int64
+	decoded          atomic.Int64
+	rpcRequests      atomic.Int64
+	enhancedRequests atomic.Int64
+	latestSlot       atomic.Uint64
+	processedSlot    atomic.Uint64
+)
+
+// BlockProcessed 记录一个区块处理完成
+func BlockProcessed(slot uint64) {
+	blocks.Add(1)
+	if storeMax(&processedSlot, slot) {
+		history.processed(clock.Now())
+	}
+}
+
+// AddTransactions 累加解析出的交易数
+func AddTransactions(n int) {
+	transactions.Add(int64(n))
+}
+
+// AddSkippedTransactions 累加因多次解析失败而跳过的交易数
+func AddSkippedTransactions(n int) {
+	skipped.Add(int64(n))
+}
+
+// AddFilteredTransactions 累加区块阶段被预过滤的交易数
+func AddFilteredTransactions(n int) {
+	filtered.Add(int64(n))
+}
+
+// AddDecodedTransactions 累加在本地解码的交易数
+func AddDecodedTransactions(n int) {
+	decoded.Add(int64(n))
+}
+
+// IncRPCRequests 记录一次Helius RPC请求
+func IncRPCRequests() {
+	rpcRequests.Add(1)
+}
+
+// IncEnhancedRequests 记录一次Helius Enhanced API请求
+func IncEnhancedRequests() {
+	enhancedRequests.Add(1)
+}
+
+// ObserveSlot 记录收到的槽位通知
+func ObserveSlot(slot uint64) {
+	if storeMax(&latestSlot, slot) {
+		history.add(slot, clock.Now())
+	}
+}
+
+// Snapshot 返回当前的累计计数
+func Snapshot() Counters {
+	return Counters{
+		Blocks:           blocks.Load(),
+		Transactions:     transactions.Load(),
+		Skipped:          skipped.Load(),
+		Filtered:         filtered.Load(),
+		Decoded:          decoded.Load(),
+		RPCRequests:      rpcRequests.Load(),
+		EnhancedRequests: enhancedRequests.Load(),
+		LatestSlot:       latestSlot.Load(),
+		ProcessedSlot:    processedSlot.Load(),
+	}
+}
+
+// storeMax 仅在新值更大时更新，返回是否更新
+func storeMax(value *atomic.Uint64, candidate uint64) bool {
+	for {
+		current := value.Load()
+		if candidate <= current {
+			return false
+		}
+		if value.CompareAndSwap(current, candidate) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import "sync/atomic"
+
+// PrefetchStats 区块预取统计
+type PrefetchStats struct {
+	Fetched int64   `json:"fetched"`  // 预取成功的区块数，包括被跳过的槽位
+	Failed  int64   `json:"failed"`   // 预取失败的区块数，取用时改为重新获取
+	Hits    int64   `json:"hits"`     // 取出区块时直接使用预取结果的次数
+	Misses  int64   `json:"misses"`   // 取出区块时没有可用预取结果的次数
+	Expired int64   `json:"expired"`  // 超过保留时长没有被取用而丢弃的区块数
+	HitRate float64 `json:"hit_rate"` // 命中次数占取出区块次数的比例
+}
+
+var (
+	prefetchFetched atomic.Int64
+	prefetchFailed  atomic.Int64
+	prefetchHits    atomic.Int64
+	prefetchMisses  atomic.Int64
+	prefetchExpired atomic.Int64
+)
+
+// IncPrefetchFetched 记录一个区块预取成功
+func IncPrefetchFetched() {
+	prefetchFetched.Add(1)
+}
+
+// IncPrefetchFailed 记录一个区块预取失败
+func IncPrefetchFailed() {
+	prefetchFailed.Add(1)
+}
+
+// IncPrefetchHits 记录一次取出区块时命中预取结果
+func IncPrefetchHits() {
+	prefetchHits.Add(1)
+}
+
+// IncPrefetchMisses 记录一次取出区块时没有可用的预取结果
+func IncPrefetchMisses() {
+	prefetchMisses.Add(1)
+}
+
+// AddPrefetchExpired 累加没有被取用而丢弃的预取区块数
+func AddPrefetchExpired(n int) {
+	prefetchExpired.Add(int64(n))
+}
+
+// Prefetch 返回进程启动以来的区块预取统计
+func Prefetch() PrefetchStats {
+	stats := PrefetchStats{
+		Fetched: prefetchFetched.Load(),
+		Failed:  prefetchFailed.Load(),
+		Hits:    prefetchHits.Load(),
+		Misses:  prefetchMisses.Load(),
+		Expired: prefetchExpired.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/life2you/datas-go/models"
+)
+
+// RetentionStats 数据保留统计
+type RetentionStats struct {
+	Runs     int64                           `json:"runs"`               // 执行次数，不包括试运行
+	Expired  int64                           `json:"expired"`            // 设置了过期时间的键数
+	Deleted  int64                           `json:"deleted"`            // 删除的键数
+	Trimmed  int64                           `json:"trimmed"`            // 裁剪掉的元素数
+	Policies map[string]RetentionPolicyStats `json:"policies"`           // 按策略统计
+	LastRun  *models.RetentionReport         `json:"last_run,omitempty"` // 最近一次执行的报告，包括试运行
+}
+
+// RetentionPolicyStats 单个保留策略的累计处理数量
+type RetentionPolicyStats struct {
+	Expired int64 `json:"expired"`
+	Deleted int64 `json:"deleted"`
+	Trimmed int64 `json:"trimmed"`
+}
+
+var (
+	retentionMu       sync.Mutex
+	retentionRuns     int64
+	retentionPolicies = make(map[string]RetentionPolicyStats)
+	retentionLastRun  *models.RetentionReport
+)
+
+// RecordRetention 记录一次数据保留的执行报告，试运行只更新最近一次报告，不计入累计数量
+func RecordRetention(report models.RetentionReport) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	retentionLastRun = &report
+	if report.DryRun {
+		return
+	}
+	retentionRuns++
+	for _, result := range report.Policies {
+		stats := retentionPolicies[result.Policy]
+		stats.Expired += result.Expired
+		stats.Deleted += result.Deleted
+		stats.Trimmed += result.Trimmed
+		retentionPolicies[result.Policy] = stats
+	}
+}
+
+// Retention 返回进程启动以来的数据保留统计
+func Retention() RetentionStats {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	stats := RetentionStats{
+		Runs:     retentionRuns,
+		Policies: make(map[string]RetentionPolicyStats, len(retentionPolicies)),
+		LastRun:  retentionLastRun,
+	}
+	for policy, policyStats := range retentionPolicies {
+		stats.Policies[policy] = policyStats
+		stats.Expired += policyStats.Expired
+		stats.Deleted += policyStats.Deleted
+		stats.Trimmed += policyStats.Trimmed
+	}
+	return stats
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import "sync/atomic"
+
+// SkippedSlotStats 跳过列表统计
+type SkippedSlotStats struct {
+	Skipped   int64 `json:"skipped"`   // 槽位获取失败后加入跳过列表的次数，包括慢速重试后再次失败
+	Retried   int64 `json:"retried"`   // 慢速重试的次数，包括通过管理接口手动重试
+	Recovered int64 `json:"recovered"` // 重试后处理成功、移出跳过列表的槽位数
+	Abandoned int64 `json:"abandoned"` // 达到最大重试次数、不再自动重试的槽位数
+}
+
+var (
+	skippedSlots   atomic.Int64
+	retriedSlots   atomic.Int64
+	recoveredSlots atomic.Int64
+	abandonedSlots atomic.Int64
+)
+
+// IncSkippedSlots 记录一次槽位加入跳过列表
+func IncSkippedSlots() {
+	skippedSlots.Add(1)
+}
+
+// IncRetriedSlots 记录一次跳过的槽位重试
+func IncRetriedSlots() {
+	retriedSlots.Add(1)
+}
+
+// IncRecoveredSlots 记录一个跳过的槽位重试成功
+func IncRecoveredSlots() {
+	recoveredSlots.Add(1)
+}
+
+// IncAbandonedSlots 记录一个跳过的槽位放弃自动重试
+func IncAbandonedSlots() {
+	abandonedSlots.Add(1)
+}
+
+// SkippedSlots 返回进程启动以来的跳过列表统计
+func SkippedSlots() SkippedSlotStats {
+	return SkippedSlotStats{
+		Skipped:   skippedSlots.Load(),
+		Retried:   retriedSlots.Load(),
+		Recovered: recoveredSlots.Load(),
+		Abandoned: abandonedSlots.Load(),
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+// GoroutineStats 单个受监管协程的panic和重启统计
+type GoroutineStats struct {
+	Name        string    `json:"name"`                   // 协程名称
+	Panics      int64     `json:"panics"`                 // panic次数
+	Restarts    int64     `json:"restarts"`               // 重启次数，一次性处理的协程panic后不重启
+	LastPanic   string    `json:"last_panic,omitempty"`   // 最近一次panic的值
+	LastPanicAt time.Time `json:"last_panic_at,omitzero"` // 最近一次panic的时间
+}
+
+var (
+	goroutinesMu sync.Mutex
+	goroutines   = make(map[string]*GoroutineStats)
+)
+
+// goroutineStats 返回协程的统计，不存在时创建，调用方需持有 goroutinesMu
+func goroutineStats(name string) *GoroutineStats {
+	stats, ok := goroutines[name]
+	if !ok {
+		stats = &GoroutineStats{Name: name}
+		goroutines[name] = stats
+	}
+	return stats
+}
+
+// RecordGoroutinePanic 记录一次协程panic
+func RecordGoroutinePanic(name string, value string) {
+	goroutinesMu.Lock()
+	defer goroutinesMu.Unlock()
+	stats := goroutineStats(name)
+	stats.Panics++
+	stats.LastPanic = value
+	stats.LastPanicAt = clock.Now()
+}
+
+// IncGoroutineRestarts 记录一次协程panic后的重启
+func IncGoroutineRestarts(name string) {
+	goroutinesMu.Lock()
+	defer goroutinesMu.Unlock()
+	goroutineStats(name).Restarts++
+}
+
+// Goroutines 返回进程启动以来发生过panic的协程统计，按名称排序
+func Goroutines() []GoroutineStats {
+	goroutinesMu.Lock()
+	defer goroutinesMu.Unlock()
+	stats := make([]GoroutineStats, 0, len(goroutines))
+	for _, s := range goroutines {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b GoroutineStats) int { return strings.Compare(a.Name, b.Name) })
+	return stats
+}
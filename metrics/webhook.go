@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WebhookStats Webhook接收统计
+type WebhookStats struct {
+	Received      int64   `json:"received"`       // 接收的事件数，包括重复事件
+	Duplicates    int64   `json:"duplicates"`     // 去重窗口内重复投递而丢弃的事件数
+	DuplicateRate float64 `json:"duplicate_rate"` // 重复事件占接收事件的比例
+
+	Rejected         int64            `json:"rejected"`           // 未通过认证而拒绝的回调请求数
+	RejectedByReason map[string]int64 `json:"rejected_by_reason"` // 按原因统计的拒绝请求数，如 ip、auth_header、hmac
+}
+
+var (
+	webhookReceived   atomic.Int64
+	webhookDuplicates atomic.Int64
+
+	webhookRejectedMu sync.Mutex
+	webhookRejected   = make(map[string]int64)
+)
+
+// AddWebhookEvents 累加接收的Webhook事件数
+func AddWebhookEvents(n int) {
+	webhookReceived.Add(int64(n))
+}
+
+// AddWebhookDuplicates 累加重复投递而丢弃的Webhook事件数
+func AddWebhookDuplicates(n int) {
+	webhookDuplicates.Add(int64(n))
+}
+
+// IncWebhookRejected 记录一次未通过认证而拒绝的回调请求
+func IncWebhookRejected(reason string) {
+	webhookRejectedMu.Lock()
+	defer webhookRejectedMu.Unlock()
+	webhookRejected[reason]++
+}
+
+// Webhook 返回进程启动以来的Webhook接收统计
+func Webhook() WebhookStats {
+	stats := WebhookStats{
+		Received:   webhookReceived.Load(),
+		Duplicates: webhookDuplicates.Load(),
+	}
+	webhookRejectedMu.Lock()
+	stats.RejectedByReason = make(map[string]int64, len(webhookRejected))
+	for reason, n := range webhookRejected {
+		stats.RejectedByReason[reason] = n
+		stats.Rejected += n
+	}
+	webhookRejectedMu.Unlock()
+	if stats.Received > 0 {
+		stats.DuplicateRate = float64(stats.Duplicates) / float64(stats.Received)
+	}
+	return stats
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// APIKeyDailyUsage 单个Enhanced API密钥一天内的用量
+type APIKeyDailyUsage struct {
+	Day      int64            `json:"day"`             // UTC日期起始时间(Unix时间戳)
+	Requests int64            `json:"requests"`        // 请求数
+	Credits  int64            `json:"credits"`         // 消耗的额度，即成功解析请求中的签名数
+	Errors   int64            `json:"errors"`          // 失败的请求数
+	Codes    map[string]int64 `json:"codes,omitempty"` // 按错误码统计的失败请求数，HTTP状态码或 network
+}
+
+// APIKeyHealthReport 未被隔离的Enhanced API密钥数变化，低于下限时告警，恢复后再报告一次
+type APIKeyHealthReport struct {
+	Healthy     int       `json:"healthy"`               // 未被隔离的密钥数
+	Total       int       `json:"total"`                 // 密钥总数
+	MinHealthy  int       `json:"min_healthy"`           // 告警下限
+	Quarantined []int     `json:"quarantined,omitempty"` // 被隔离的密钥索引
+	Recovered   bool      `json:"recovered"`             // 是否已恢复到下限以上
+	Message     string    `json:"message"`               // 说明
+	Time        time.Time `json:"time"`                  // 状态变化时间
+}
@@ -0,0 +1,11 @@
+package models
+
+// BlockMeta 区块元数据，用于按区块哈希查询槽位
+type BlockMeta struct {
+	Slot              uint64 `json:"slot"`               // 槽位
+	Blockhash         string `json:"blockhash"`          // 区块哈希
+	PreviousBlockhash string `json:"previous_blockhash"` // 父区块哈希
+	ParentSlot        uint64 `json:"parent_slot"`        // 父区块槽位
+	BlockTime         int64  `json:"block_time"`         // 出块时间(Unix秒)
+	Transactions      int    `json:"transactions"`       // 非投票交易数
+}
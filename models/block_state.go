@@ -0,0 +1,30 @@
+package models
+
+// BlockState 区块处理状态
+type BlockState string
+
+// 区块处理状态机: QUEUED → FETCHING → PARSING → DONE/FAILED，DONE之后没有被最终确认的区块标记为ORPHANED
+const (
+	BlockQueued   BlockState = "QUEUED"   // 已推入区块队列
+	BlockFetching BlockState = "FETCHING" // 正在获取区块
+	BlockParsing  BlockState = "PARSING"  // 交易签名已推入交易队列，等待解析
+	BlockDone     BlockState = "DONE"     // 处理完成
+	BlockFailed   BlockState = "FAILED"   // 处理失败
+	BlockOrphaned BlockState = "ORPHANED" // 槽位被跳过或区块没有被最终确认，数据已删除
+)
+
+// BlockStates 所有区块处理状态
+var BlockStates = []BlockState{BlockQueued, BlockFetching, BlockParsing, BlockDone, BlockFailed, BlockOrphaned}
+
+// BlockStatus 单个区块的处理状态，时间均为Unix时间戳
+type BlockStatus struct {
+	Slot       uint64     `json:"slot"`                  // 区块槽位
+	State      BlockState `json:"state"`                 // 当前状态
+	Attempts   int        `json:"attempts"`              // 获取区块的次数
+	Error      string     `json:"error,omitempty"`       // 失败原因
+	QueuedAt   int64      `json:"queued_at,omitempty"`   // 最近一次入队时间
+	FetchingAt int64      `json:"fetching_at,omitempty"` // 最近一次开始获取区块的时间
+	ParsingAt  int64      `json:"parsing_at,omitempty"`  // 最近一次开始解析交易的时间
+	FinishedAt int64      `json:"finished_at,omitempty"` // 完成或失败的时间
+	UpdatedAt  int64      `json:"updated_at"`            // 最近一次状态变化的时间
+}
@@ -0,0 +1,14 @@
+package models
+
+// BlockStats 区块处理完成时的统计，随区块事件发布
+type BlockStats struct {
+	Slot         uint64 `json:"slot"`         // 槽位
+	Blockhash    string `json:"blockhash"`    // 区块哈希
+	ParentSlot   uint64 `json:"parent_slot"`  // 父区块槽位
+	BlockTime    int64  `json:"block_time"`   // 出块时间(Unix秒)
+	Transactions int    `json:"transactions"` // 非投票交易数
+	Failed       int    `json:"failed"`       // 执行失败的非投票交易数
+	Signatures   int    `json:"signatures"`   // 推入交易队列等待解析的签名数
+	Prefiltered  int    `json:"prefiltered"`  // 被 block_filter 预过滤的交易数
+	Decoded      int    `json:"decoded"`      // 启用 raw_parse 时在本地解码、没有调用Enhanced API的交易数
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// BlockSummary 单个区块的交易汇总，用于观察网络状况和核对采集是否完整
+type BlockSummary struct {
+	Slot               uint64    `json:"slot"`                // 槽位
+	Blockhash          string    `json:"blockhash"`           // 区块哈希
+	ParentSlot         uint64    `json:"parent_slot"`         // 父区块槽位
+	BlockTime          int64     `json:"block_time"`          // 出块时间(Unix秒)
+	TotalTransactions  int       `json:"total_transactions"`  // 交易总数，包括投票交易
+	VoteTransactions   int       `json:"vote_transactions"`   // 投票交易数，不参与解析
+	VoteRatio          float64   `json:"vote_ratio"`          // 投票交易占比
+	FailedTransactions int       `json:"failed_transactions"` // 执行失败的非投票交易数
+	TotalFees          uint64    `json:"total_fees"`          // 所有交易的手续费合计(lamports)
+	ComputeUnits       uint64    `json:"compute_units"`       // 所有交易消耗的计算单元合计
+	Signatures         int       `json:"signatures"`          // 推入交易队列等待解析的签名数
+	Prefiltered        int       `json:"prefiltered"`         // 被 block_filter 预过滤的交易数
+	Decoded            int       `json:"decoded"`             // 在本地解码、没有调用Enhanced API的交易数
+	ProcessedAt        time.Time `json:"processed_at"`        // 区块处理完成的时间
+}
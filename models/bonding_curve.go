@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// BondingCurveProgress Pump.fun代币联合曲线的毕业进度
+// 进度按已售出的代币占曲线可售代币的比例计算，全部售出后代币迁移到AMM
+type BondingCurveProgress struct {
+	Mint            string    `json:"mint"`                    // 代币地址
+	BondingCurve    string    `json:"bonding_curve,omitempty"` // 联合曲线账户
+	Name            string    `json:"name,omitempty"`          // 代币名称，只有收到过创建消息时才有
+	Symbol          string    `json:"symbol,omitempty"`        // 代币符号，只有收到过创建消息时才有
+	VirtualSOL      float64   `json:"virtual_sol"`             // 曲线中的虚拟SOL储备
+	VirtualTokens   float64   `json:"virtual_tokens"`          // 曲线中的虚拟代币储备
+	RealSOL         float64   `json:"real_sol"`                // 买入者实际投入曲线的SOL
+	MarketCapSOL    float64   `json:"market_cap_sol"`          // 以SOL计价的市值
+	Progress        float64   `json:"progress"`                // 毕业进度，0到1之间
+	RemainingTokens float64   `json:"remaining_tokens"`        // 距离毕业还可售出的代币数量
+	SOLToGraduate   float64   `json:"sol_to_graduate"`         // 按当前储备估算的毕业还需买入的SOL
+	Graduated       bool      `json:"graduated"`               // 是否已收到迁移消息
+	Trades          int64     `json:"trades"`                  // 统计以来的买卖笔数
+	UpdatedAt       time.Time `json:"updated_at"`              // 最后一次更新的时间
+}
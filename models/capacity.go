@@ -0,0 +1,48 @@
+package models
+
+// CapacitySnapshot 一个快照间隔内的关键指标，用于容量规划
+type CapacitySnapshot struct {
+	Timestamp             int64            `json:"timestamp"`               // 快照时间(Unix时间戳)
+	Interval              int64            `json:"interval"`                // 统计区间长度(秒)
+	Blocks                int64            `json:"blocks"`                  // 区间内处理完成的区块数
+	Transactions          int64            `json:"transactions"`            // 区间内解析出的交易数
+	SkippedTransactions   int64            `json:"skipped_transactions"`    // 区间内命中不再解析缓存而跳过的交易数
+	FilteredTransactions  int64            `json:"filtered_transactions"`   // 区间内区块阶段被预过滤的交易数
+	TransactionsPerSecond float64          `json:"transactions_per_second"` // 区间内平均每秒解析的交易数
+	SlotLag               uint64           `json:"slot_lag"`                // 快照时最新槽位与已处理最大槽位之差
+	SlotLagSeconds        float64          `json:"slot_lag_seconds"`        // 快照时采集落后网络的时长(秒)
+	RPCRequests           int64            `json:"rpc_requests"`            // 区间内Helius RPC请求数
+	EnhancedRequests      int64            `json:"enhanced_requests"`       // 区间内Enhanced API请求数
+	Credits               int64            `json:"credits"`                 // 区间内估算的Helius额度消耗
+	BlockQueuePeak        int              `json:"block_queue_peak"`        // 区间内区块队列的最大长度
+	TransactionQueuePeak  int              `json:"transaction_queue_peak"`  // 区间内交易队列的最大长度
+	RedisMemory           map[string]int64 `json:"redis_memory,omitempty"`  // 各负载Redis实例已用内存(字节)
+}
+
+// CapacityReport 按周汇总的容量报告
+type CapacityReport struct {
+	GeneratedAt            int64          `json:"generated_at"`                   // 生成时间(Unix时间戳)
+	Weeks                  []CapacityWeek `json:"weeks"`                          // 按时间升序的周汇总
+	MonthlyCreditsEstimate int64          `json:"monthly_credits_estimate"`       // 按最近7天的消耗速度估算的每月额度
+	RecommendedAPIKeys     int            `json:"recommended_api_keys,omitempty"` // 按每个密钥的月额度推荐的密钥数量
+}
+
+// CapacityWeek 一周内的峰值、总量和相对上一周的增长
+type CapacityWeek struct {
+	Week                      string   `json:"week"`                               // ISO周，如 2026-W42
+	Snapshots                 int      `json:"snapshots"`                          // 快照数量
+	Blocks                    int64    `json:"blocks"`                             // 处理完成的区块数
+	Transactions              int64    `json:"transactions"`                       // 解析出的交易数
+	SkippedTransactions       int64    `json:"skipped_transactions"`               // 命中不再解析缓存而跳过的交易数
+	FilteredTransactions      int64    `json:"filtered_transactions"`              // 区块阶段被预过滤的交易数
+	Credits                   int64    `json:"credits"`                            // 估算的额度消耗
+	PeakTransactionsPerSecond float64  `json:"peak_transactions_per_second"`       // 每秒解析交易数的峰值
+	PeakSlotLag               uint64   `json:"peak_slot_lag"`                      // 槽位延迟的峰值
+	PeakSlotLagSeconds        float64  `json:"peak_slot_lag_seconds"`              // 落后时长的峰值(秒)
+	PeakBlockQueue            int      `json:"peak_block_queue"`                   // 区块队列长度的峰值
+	PeakTransactionQueue      int      `json:"peak_transaction_queue"`             // 交易队列长度的峰值
+	PeakRedisMemory           int64    `json:"peak_redis_memory"`                  // 各负载Redis已用内存之和的峰值(字节)
+	TransactionsGrowth        *float64 `json:"transactions_growth,omitempty"`      // 交易数相对上一周的增长比例
+	CreditsGrowth             *float64 `json:"credits_growth,omitempty"`           // 额度消耗相对上一周的增长比例
+	PeakRedisMemoryGrowth     *float64 `json:"peak_redis_memory_growth,omitempty"` // Redis内存峰值相对上一周的增长比例
+}
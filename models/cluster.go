@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ClusterInstance 分布式处理中的一个实例
+type ClusterInstance struct {
+	ID        string `json:"id"`        // 实例ID
+	Heartbeat int64  `json:"heartbeat"` // 最近一次心跳的Unix时间戳，正常退出的实例为0
+	Alive     bool   `json:"alive"`     // 心跳是否在 distributed.instance_ttl 之内
+	Claims    int64  `json:"claims"`    // 持有租约、尚未处理完成的区块数
+	Self      bool   `json:"self"`      // 是否为当前实例
+}
+
+// ClusterStatus 当前实例看到的分布式处理状态
+type ClusterStatus struct {
+	Instance  string            `json:"instance"`  // 当前实例ID
+	Instances []ClusterInstance `json:"instances"` // 所有登记过心跳、尚未被回收的实例
+	Acquired  int64             `json:"acquired"`  // 当前实例获取租约处理的区块数
+	Skipped   int64             `json:"skipped"`   // 已被其他实例持有或已处理完成而跳过的区块数
+	Reclaimed int64             `json:"reclaimed"` // 从失效实例回收、重新推入本实例区块队列的区块数
+}
+
+// LeaderStatus WebSocket摄取的领导者选举状态
+type LeaderStatus struct {
+	Instance string    `json:"instance"`        // 当前实例ID
+	Leader   string    `json:"leader"`          // Redis中记录的领导者，没有领导者时为空
+	IsLeader bool      `json:"is_leader"`       // 当前实例是否为领导者且租期未过期
+	Since    time.Time `json:"since,omitzero"`  // 当前实例成为领导者的时间
+	Elected  int64     `json:"elected"`         // 当前实例成为领导者的次数
+	Error    string    `json:"error,omitempty"` // 最近一次竞选失败的原因
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 定时任务的触发方式
+const (
+	JobTriggerSchedule = "schedule" // 按调度表达式触发
+	JobTriggerManual   = "manual"   // 通过管理接口手动触发
+)
+
+// 定时任务的执行结果
+const (
+	JobRunSuccess = "success"
+	JobRunFailed  = "failed"
+)
+
+// JobRun 定时任务的一次执行记录
+type JobRun struct {
+	Job         string    `json:"job"`                   // 任务名称
+	Instance    string    `json:"instance"`              // 执行任务的实例ID
+	Trigger     string    `json:"trigger"`               // 触发方式: schedule、manual
+	ScheduledAt time.Time `json:"scheduled_at,omitzero"` // 计划执行时间，手动触发时为空
+	StartedAt   time.Time `json:"started_at"`            // 开始时间
+	FinishedAt  time.Time `json:"finished_at"`           // 结束时间
+	DurationMs  int64     `json:"duration_ms"`           // 执行耗时(毫秒)
+	Status      string    `json:"status"`                // 执行结果: success、failed
+	Error       string    `json:"error,omitempty"`       // 失败原因
+}
+
+// JobStatus 定时任务的调度状态
+type JobStatus struct {
+	Name     string        `json:"name"`                // 任务名称
+	Schedule string        `json:"schedule,omitempty"`  // 调度表达式，为空时只能手动触发
+	Timeout  time.Duration `json:"timeout"`             // 超时时间(纳秒)
+	NextRun  time.Time     `json:"next_run,omitzero"`   // 下一次计划执行时间
+	Running  bool          `json:"running"`             // 当前实例是否正在执行
+	LockedBy string        `json:"locked_by,omitempty"` // 持有执行锁的实例，没有实例在执行时为空
+	LastRun  *JobRun       `json:"last_run,omitempty"`  // 最近一次执行记录
+}
@@ -1,6 +1,25 @@
 package models
 
+import "encoding/json"
+
+// TransactionQueueModel 交易队列中的元素，即一个区块中需要解析的交易签名
+// 实现了 encoding.BinaryMarshaler，可直接写入Redis列表，服务重启时不会丢失
 type TransactionQueueModel struct {
-	Signatures []string `json:"signatures"`
-	Slot       uint64   `json:"slot"`
+	Signatures  []string `json:"signatures"`             // 交易签名
+	Slot        uint64   `json:"slot"`                   // 区块高度
+	BlockTime   int64    `json:"block_time,omitempty"`   // 区块时间(Unix时间戳)
+	Retries     int      `json:"retries,omitempty"`      // 解析失败后已重新入队的次数
+	TraceParent string   `json:"trace_parent,omitempty"` // 区块所在链路的W3C traceparent，由取出的实例继续同一条链路
+
+	StreamID string `json:"-"` // 从Redis Streams取出时的消息ID，处理完成后用于确认消息
+}
+
+// MarshalBinary 序列化为JSON，用于写入Redis
+func (m TransactionQueueModel) MarshalBinary() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// UnmarshalBinary 从Redis中读取的JSON反序列化
+func (m *TransactionQueueModel) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, m)
 }
@@ -0,0 +1,13 @@
+package models
+
+// OrderFlowSnapshot 单个代币在滚动窗口内的买卖盘失衡快照
+type OrderFlowSnapshot struct {
+	Mint       string  `json:"mint"`        // 代币地址
+	Timestamp  int64   `json:"timestamp"`   // 快照时间(Unix时间戳)
+	Window     int64   `json:"window"`      // 窗口长度(秒)
+	BuyVolume  float64 `json:"buy_volume"`  // 窗口内买入数量(代币单位)
+	SellVolume float64 `json:"sell_volume"` // 窗口内卖出数量(代币单位)
+	BuyCount   int     `json:"buy_count"`   // 窗口内买入笔数
+	SellCount  int     `json:"sell_count"`  // 窗口内卖出笔数
+	Imbalance  float64 `json:"imbalance"`   // 成交量加权失衡度，(买-卖)/(买+卖)，范围[-1, 1]
+}
@@ -0,0 +1,23 @@
+package models
+
+// OrphanedSlot 处理后没有被最终确认的槽位(被跳过或分叉后被丢弃)，其数据已从存储中删除
+type OrphanedSlot struct {
+	Slot         uint64   `json:"slot"`                 // 槽位
+	Blockhash    string   `json:"blockhash,omitempty"`  // 处理时的区块哈希
+	BlockTime    int64    `json:"block_time,omitempty"` // 处理时的出块时间(Unix时间戳)，用于定位受影响的统计时段
+	Reason       string   `json:"reason"`               // 判定原因
+	Signatures   int      `json:"signatures"`           // 入队解析的交易签名数
+	Transactions int      `json:"transactions"`         // 已解析并索引、随后被删除的交易数
+	Mints        []string `json:"mints,omitempty"`      // 已索引交易涉及的代币，用于标记按代币统计的受影响数据
+	DetectedAt   int64    `json:"detected_at"`          // 发现时间(Unix时间戳)
+}
+
+// IndexedTransaction 已按来源、类型和代币索引的交易
+type IndexedTransaction struct {
+	Signature string
+	Source    string
+	Type      string
+	Slot      uint64   // 交易所在槽位，作为索引中的分数
+	Timestamp int64    // 区块时间(Unix时间戳)，决定交易归入哪一天的索引，为0时使用当前时间
+	Mints     []string // 交易涉及的代币，为空时不建立代币索引
+}
@@ -0,0 +1,20 @@
+package models
+
+// ParseFailureReason 交易签名解析失败的原因
+type ParseFailureReason string
+
+const (
+	ParseFailureMissing ParseFailureReason = "MISSING"      // Enhanced API的响应中没有该签名
+	ParseFailureUnknown ParseFailureReason = "UNKNOWN"      // 解析结果的交易类型为UNKNOWN
+	ParseFailureDecode  ParseFailureReason = "DECODE_ERROR" // 解析结果无法解码
+)
+
+// ParseFailure 签名的解析失败记录，失败次数达到上限后不再调用Enhanced API解析，时间均为Unix时间戳
+type ParseFailure struct {
+	Signature string             `json:"signature"`  // 交易签名
+	Reason    ParseFailureReason `json:"reason"`     // 最近一次失败的原因
+	Attempts  int                `json:"attempts"`   // 累计失败次数
+	Slot      uint64             `json:"slot"`       // 所在区块槽位
+	FirstSeen int64              `json:"first_seen"` // 第一次失败的时间
+	LastSeen  int64              `json:"last_seen"`  // 最近一次失败的时间
+}
@@ -0,0 +1,29 @@
+package models
+
+import "github.com/life2you/datas-go/models/resp"
+
+// ParseSignaturesRequest 独立解析服务按签名解析交易的请求
+type ParseSignaturesRequest struct {
+	Signatures []string `json:"signatures"`           // 交易签名
+	Commitment string   `json:"commitment,omitempty"` // 确认级别: confirmed 或 finalized，为空时使用服务的默认配置
+}
+
+// ParsedSignature 单个签名的解析结果
+type ParsedSignature struct {
+	Signature    string                  `json:"signature"`               // 交易签名
+	Transaction  *resp.ParsedTransaction `json:"transaction"`             // Enhanced API解析结果
+	FilterReason string                  `json:"filter_reason,omitempty"` // 采集服务解析阶段会过滤该交易的原因，为空表示会被存储
+}
+
+// ParseSignaturesResponse 独立解析服务按签名解析交易的响应
+type ParseSignaturesResponse struct {
+	Transactions []ParsedSignature `json:"transactions"` // 解析结果，顺序与请求一致
+	Missing      []string          `json:"missing"`      // Enhanced API未返回的签名
+}
+
+// MintTransactions 按代币和天索引的交易签名
+type MintTransactions struct {
+	Mint       string   `json:"mint"`       // 代币地址
+	Day        int64    `json:"day"`        // UTC日起始时间(Unix时间戳)
+	Signatures []string `json:"signatures"` // 按槽位降序排列的签名
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PayloadSample 一次解析失败的原始数据
+type PayloadSample struct {
+	Time      time.Time `json:"time"`              // 解析失败的时间
+	Context   string    `json:"context,omitempty"` // 上下文，如槽位或签名
+	Size      int       `json:"size"`              // 原始数据的字节数
+	Truncated bool      `json:"truncated"`         // 是否因超过 max_payload_bytes 被截断
+	Payload   string    `json:"payload"`           // 原始数据
+}
+
+// PayloadSampleGroup 同一来源、同一类错误的解析失败及最近的样本
+type PayloadSampleGroup struct {
+	ID         string          `json:"id"`                // 类别ID，由来源和错误类别计算
+	Source     string          `json:"source"`            // 数据来源，如 block、enhanced_transaction
+	Error      string          `json:"error"`             // 最近一次的错误信息
+	Count      int64           `json:"count"`             // 累计失败次数
+	Suppressed int64           `json:"suppressed"`        // 因限流未输出日志的次数
+	FirstSeen  time.Time       `json:"first_seen"`        // 第一次出现的时间
+	LastSeen   time.Time       `json:"last_seen"`         // 最近一次出现的时间
+	Samples    []PayloadSample `json:"samples,omitempty"` // 最近的样本，按时间从新到旧
+}
@@ -0,0 +1,17 @@
+package models
+
+// PoolCreation 新创建的流动性池，由池子初始化指令解析得到
+type PoolCreation struct {
+	Signature  string  `json:"signature"`            // 初始化交易签名
+	Slot       uint64  `json:"slot"`                 // 交易所在槽位
+	BlockTime  int64   `json:"block_time,omitempty"` // 出块时间(Unix时间戳)
+	Program    string  `json:"program"`              // 池子所属程序ID
+	Dex        string  `json:"dex"`                  // 程序别名，如 raydium_amm、meteora_dlmm
+	Pool       string  `json:"pool"`                 // 池子账户
+	Creator    string  `json:"creator"`              // 创建者，即交易的手续费支付者
+	MintA      string  `json:"mint_a"`               // 池子的第一个代币，Raydium AMM为coin，Meteora DLMM为X
+	MintB      string  `json:"mint_b"`               // 池子的第二个代币，Raydium AMM为pc，Meteora DLMM为Y
+	AmountA    float64 `json:"amount_a"`             // 初始化时注入的第一个代币数量，按交易前后金库余额计算
+	AmountB    float64 `json:"amount_b"`             // 初始化时注入的第二个代币数量
+	DetectedAt int64   `json:"detected_at"`          // 发现时间(Unix时间戳)
+}
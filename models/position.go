@@ -0,0 +1,35 @@
+package models
+
+// Position 钱包在某个代币上的持仓，数量均为开始统计以来的累计值，不是链上余额
+type Position struct {
+	Mint        string  `json:"mint"`                    // 代币地址
+	Wallet      string  `json:"wallet"`                  // 钱包地址
+	Balance     float64 `json:"balance"`                 // 转入减转出的净数量
+	Bought      float64 `json:"bought"`                  // 通过swap用SOL买入的代币数量
+	Sold        float64 `json:"sold"`                    // 通过swap卖出换成SOL的代币数量
+	BuySOL      float64 `json:"buy_sol"`                 // 买入花费的SOL
+	SellSOL     float64 `json:"sell_sol"`                // 卖出收到的SOL
+	AvgBuyPrice float64 `json:"avg_buy_price,omitempty"` // 平均买入价(SOL)，没有SOL买入记录时为0
+	CostBasis   float64 `json:"cost_basis,omitempty"`    // 按平均买入价计算的当前持仓成本(SOL)
+	RealizedPnL float64 `json:"realized_pnl,omitempty"`  // 按平均买入价计算的已实现盈亏(SOL)
+	UpdatedAt   int64   `json:"updated_at"`              // 最近一次变化的时间(Unix时间戳)
+}
+
+// PositionDelta 持仓在某一天内的增量
+type PositionDelta struct {
+	Mint      string
+	Wallet    string
+	Day       int64 // 所在UTC日的起始时间(Unix时间戳)
+	Balance   float64
+	Bought    float64
+	Sold      float64
+	BuySOL    float64
+	SellSOL   float64
+	UpdatedAt int64
+}
+
+// Accumulation 钱包在时间范围内对某个代币的净流入
+type Accumulation struct {
+	Wallet    string  `json:"wallet"`     // 钱包地址
+	NetAmount float64 `json:"net_amount"` // 转入减转出的净数量
+}
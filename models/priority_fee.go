@@ -0,0 +1,22 @@
+package models
+
+// PriorityFeeEstimate 最近区块的计算单元价格分位数，价格单位为微lamports/CU
+type PriorityFeeEstimate struct {
+	Program      string            `json:"program,omitempty"` // 过滤的程序，为空时为所有交易
+	Blocks       int               `json:"blocks"`            // 参与统计的区块数
+	Transactions int               `json:"transactions"`      // 参与统计的交易数
+	FromSlot     uint64            `json:"from_slot"`         // 起始槽位
+	ToSlot       uint64            `json:"to_slot"`           // 结束槽位
+	Percentiles  map[string]uint64 `json:"percentiles"`       // 分位数 -> 价格，如 "p50"
+	Levels       PriorityFeeLevels `json:"levels"`            // 按档位给出的推荐价格
+}
+
+// PriorityFeeLevels 推荐价格档位，分位数与Helius getPriorityFeeEstimate一致
+type PriorityFeeLevels struct {
+	Min       uint64 `json:"min"`        // p0
+	Low       uint64 `json:"low"`        // p25
+	Medium    uint64 `json:"medium"`     // p50
+	High      uint64 `json:"high"`       // p75
+	VeryHigh  uint64 `json:"very_high"`  // p95
+	UnsafeMax uint64 `json:"unsafe_max"` // p100
+}
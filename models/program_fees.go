@@ -0,0 +1,18 @@
+package models
+
+// ProgramFees 调用了某个程序的非投票交易在一小时内支付的手续费和设置的计算单元价格
+type ProgramFees struct {
+	Program      string `json:"program"`        // 程序ID
+	Hour         int64  `json:"hour,omitempty"` // 小时起始时间(Unix时间戳)，汇总结果中为0
+	Transactions int64  `json:"transactions"`   // 交易数，包括执行失败的交易
+	Failed       int64  `json:"failed"`         // 执行失败的交易数
+	Prioritized  int64  `json:"prioritized"`    // 设置了计算单元价格的交易数
+	Fees         int64  `json:"fees"`           // 总手续费(lamports)
+	PriorityFees int64  `json:"priority_fees"`  // 优先费(lamports)
+	ComputeUnits int64  `json:"compute_units"`  // 消耗的计算单元
+	UnitPriceSum int64  `json:"-"`              // 计算单元价格之和(微lamports/CU)，用于计算平均价格
+
+	AvgFee         float64 `json:"avg_fee"`          // 平均每笔交易的手续费(lamports)
+	AvgPriorityFee float64 `json:"avg_priority_fee"` // 平均每笔交易的优先费(lamports)
+	AvgUnitPrice   float64 `json:"avg_unit_price"`   // 设置了计算单元价格的交易的平均价格(微lamports/CU)
+}
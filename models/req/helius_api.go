@@ -8,3 +8,18 @@ type GetBlockParams struct {
 	MaxSupportedTransactionVersion int    `json:"maxSupportedTransactionVersion"`
 	Commitment                     string `json:"commitment"`
 }
+
+// GetTransactionParams 表示 getTransaction 请求的参数选项
+type GetTransactionParams struct {
+	Encoding                       string `json:"encoding"`
+	MaxSupportedTransactionVersion int    `json:"maxSupportedTransactionVersion"`
+	Commitment                     string `json:"commitment"`
+}
+
+// GetTokenAccountsParams 表示 DAS getTokenAccounts 请求的参数
+type GetTokenAccountsParams struct {
+	Mint  string `json:"mint,omitempty"`  // 按代币查询
+	Owner string `json:"owner,omitempty"` // 按持有者查询
+	Page  int    `json:"page,omitempty"`  // 页码，从1开始
+	Limit int    `json:"limit,omitempty"` // 每页数量，最多1000
+}
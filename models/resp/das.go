@@ -0,0 +1,28 @@
+package resp
+
+// MintAccount getAccountInfo(jsonParsed) 返回的代币Mint账户信息
+type MintAccount struct {
+	Program         string `json:"program"`         // 所属程序，spl-token 或 spl-token-2022
+	MintAuthority   string `json:"mintAuthority"`   // 增发权限，已放弃时为空
+	FreezeAuthority string `json:"freezeAuthority"` // 冻结权限，已放弃时为空
+	Supply          string `json:"supply"`          // 总供应量(最小单位)
+	Decimals        int    `json:"decimals"`        // 精度
+	IsInitialized   bool   `json:"isInitialized"`   // 是否已初始化
+}
+
+// TokenAccounts DAS getTokenAccounts 的返回结果
+type TokenAccounts struct {
+	Total         int            `json:"total"`          // 本页返回的账户数
+	Limit         int            `json:"limit"`          // 每页数量
+	Page          int            `json:"page"`           // 页码
+	TokenAccounts []TokenAccount `json:"token_accounts"` // 代币账户
+}
+
+// TokenAccount DAS 返回的代币账户
+type TokenAccount struct {
+	Address string `json:"address"` // 代币账户地址
+	Mint    string `json:"mint"`    // 代币地址
+	Owner   string `json:"owner"`   // 持有者
+	Amount  uint64 `json:"amount"`  // 余额(最小单位)
+	Frozen  bool   `json:"frozen"`  // 是否被冻结
+}
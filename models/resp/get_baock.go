@@ -1,31 +1,83 @@
 package resp
 
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// BlockResp getBlock 的返回结果，lamports、槽位等数值使用无符号64位整数，避免超过 2^31 时溢出
 type BlockResp struct {
-	BlockTime         int            `json:"blockTime"`
+	BlockTime         int64          `json:"blockTime"`
 	Blockhash         string         `json:"blockhash"`
-	ParentSlot        int            `json:"parentSlot"`
+	ParentSlot        uint64         `json:"parentSlot"`
 	PreviousBlockhash string         `json:"previousBlockhash"`
 	Transactions      []Transactions `json:"transactions"`
 }
 type Rewards struct {
-	Commission  interface{} `json:"commission"`
-	Lamports    int         `json:"lamports"`
-	PostBalance int64       `json:"postBalance"`
-	Pubkey      string      `json:"pubkey"`
-	RewardType  string      `json:"rewardType"`
+	Commission  *uint8 `json:"commission"` // 投票奖励和质押奖励的佣金比例，其它奖励为null
+	Lamports    int64  `json:"lamports"`   // 奖励金额，租金等扣款为负数
+	PostBalance uint64 `json:"postBalance"`
+	Pubkey      string `json:"pubkey"`
+	RewardType  string `json:"rewardType"`
 }
+
+// Err 交易执行错误，指令错误为 {"InstructionError":[指令索引, 错误]}，
+// 其它错误为字符串(如 "AccountInUse")或以错误名为键的对象，原样保留以便重新编码
 type Err struct {
 	InstructionError []interface{} `json:"InstructionError"`
+
+	raw json.RawMessage
 }
+
+// UnmarshalJSON 解码交易执行错误，不是指令错误时只保留原始JSON
+func (e *Err) UnmarshalJSON(data []byte) error {
+	*e = Err{}
+	if string(data) == "null" {
+		return nil
+	}
+	e.raw = append(json.RawMessage(nil), data...)
+	if len(data) == 0 || data[0] != '{' {
+		return nil
+	}
+	var instructionErr struct {
+		InstructionError []interface{} `json:"InstructionError"`
+	}
+	if err := json.Unmarshal(data, &instructionErr); err != nil {
+		return err
+	}
+	e.InstructionError = instructionErr.InstructionError
+	return nil
+}
+
+// IsNil 返回是否没有执行错误，任何非null的错误(指令错误、字符串或对象错误)都视为有错误
+func (e Err) IsNil() bool {
+	return e.InstructionError == nil && e.raw == nil
+}
+
+// MarshalJSON 编码交易执行错误，没有错误时为null
+func (e Err) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.InstructionError != nil:
+		return json.Marshal(map[string][]interface{}{"InstructionError": e.InstructionError})
+	case e.raw != nil:
+		return e.raw, nil
+	default:
+		return []byte("null"), nil
+	}
+}
+
 type LoadedAddresses struct {
-	Readonly []string      `json:"readonly"`
-	Writable []interface{} `json:"writable"`
+	Readonly []string `json:"readonly"`
+	Writable []string `json:"writable"`
 }
+
+// UITokenAmount 代币余额，Amount 为最小单位的数量，超过 uint64 范围也不会丢失精度
 type UITokenAmount struct {
-	Amount         string  `json:"amount"`
-	Decimals       int     `json:"decimals"`
-	UIAmount       float64 `json:"uiAmount"`
-	UIAmountString string  `json:"uiAmountString"`
+	Amount         decimal.Decimal `json:"amount"`
+	Decimals       int             `json:"decimals"`
+	UIAmount       float64         `json:"uiAmount"`
+	UIAmountString string          `json:"uiAmountString"`
 }
 type PostTokenBalances struct {
 	AccountIndex  int           `json:"accountIndex"`
@@ -41,24 +93,49 @@ type PreTokenBalances struct {
 	ProgramID     string        `json:"programId"`
 	UITokenAmount UITokenAmount `json:"uiTokenAmount"`
 }
+
+// Meta 交易执行结果，getBlock 和 getTransaction 在各RPC版本中返回的字段都使用此结构
+// 旧版本缺少的字段(如 loadedAddresses、computeUnitsConsumed、returnData)解码为零值，未知字段忽略
 type Meta struct {
-	ComputeUnitsConsumed int                 `json:"computeUnitsConsumed"`
+	ComputeUnitsConsumed uint64              `json:"computeUnitsConsumed"`
+	CostUnits            uint64              `json:"costUnits,omitempty"` // 交易的调度成本，较新的RPC版本才返回
 	Err                  Err                 `json:"err"`
-	Fee                  int                 `json:"fee"`
-	InnerInstructions    []interface{}       `json:"innerInstructions"`
+	Fee                  uint64              `json:"fee"`
+	InnerInstructions    []InnerInstructions `json:"innerInstructions"`
 	LoadedAddresses      LoadedAddresses     `json:"loadedAddresses"`
 	LogMessages          []string            `json:"logMessages"`
-	PostBalances         []interface{}       `json:"postBalances"`
+	PostBalances         []uint64            `json:"postBalances"` // 交易后各账户的SOL余额(lamports)，与完整账户列表一一对应
 	PostTokenBalances    []PostTokenBalances `json:"postTokenBalances"`
-	PreBalances          []interface{}       `json:"preBalances"`
+	PreBalances          []uint64            `json:"preBalances"` // 交易前各账户的SOL余额(lamports)
 	PreTokenBalances     []PreTokenBalances  `json:"preTokenBalances"`
-	Rewards              []interface{}       `json:"rewards"`
-	Status               Status              `json:"status"`
+	ReturnData           *ReturnData         `json:"returnData,omitempty"`
+	Rewards              []Rewards           `json:"rewards"`
+	Status               Status              `json:"status"` // 已废弃，部分RPC版本不再返回
+}
+
+// UnmarshalJSON 解码交易执行结果，RPC没有返回已废弃的 status 时按 err 补齐，使按 Status 判断失败交易的代码在各版本中一致
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	type meta Meta
+	var decoded struct {
+		meta
+		Status *Status `json:"status"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = Meta(decoded.meta)
+	if decoded.Status != nil {
+		m.Status = *decoded.Status
+	} else {
+		m.Status = Status{Err: m.Err}
+	}
+	return nil
 }
+
 type AddressTableLookups struct {
-	AccountKey      string        `json:"accountKey"`
-	ReadonlyIndexes []int         `json:"readonlyIndexes"`
-	WritableIndexes []interface{} `json:"writableIndexes"`
+	AccountKey      string `json:"accountKey"`
+	ReadonlyIndexes []int  `json:"readonlyIndexes"`
+	WritableIndexes []int  `json:"writableIndexes"`
 }
 type Header struct {
 	NumReadonlySignedAccounts   int `json:"numReadonlySignedAccounts"`
@@ -66,10 +143,10 @@ type Header struct {
 	NumRequiredSignatures       int `json:"numRequiredSignatures"`
 }
 type Instructions struct {
-	Accounts       []interface{} `json:"accounts"`
-	Data           string        `json:"data"`
-	ProgramIDIndex int           `json:"programIdIndex"`
-	StackHeight    interface{}   `json:"stackHeight"`
+	Accounts       []int  `json:"accounts"`
+	Data           string `json:"data"`
+	ProgramIDIndex int    `json:"programIdIndex"`
+	StackHeight    *int   `json:"stackHeight"` // 指令调用深度，旧区块中为null
 }
 type Message struct {
 	AccountKeys         []string              `json:"accountKeys"`
@@ -94,53 +171,17 @@ type Status struct {
 	Ok  interface{} `json:"Ok"`
 	Err Err         `json:"Err"`
 }
-type Meta0 struct {
-	ComputeUnitsConsumed int                 `json:"computeUnitsConsumed"`
-	Err                  interface{}         `json:"err"`
-	Fee                  int                 `json:"fee"`
-	InnerInstructions    []InnerInstructions `json:"innerInstructions"`
-	LoadedAddresses      LoadedAddresses     `json:"loadedAddresses"`
-	LogMessages          []string            `json:"logMessages"`
-	PostBalances         []interface{}       `json:"postBalances"`
-	PostTokenBalances    []PostTokenBalances `json:"postTokenBalances"`
-	PreBalances          []interface{}       `json:"preBalances"`
-	PreTokenBalances     []PreTokenBalances  `json:"preTokenBalances"`
-	ReturnData           ReturnData          `json:"returnData"`
-	Rewards              []interface{}       `json:"rewards"`
-	Status               Status              `json:"status"`
-}
-type Meta1 struct {
-	ComputeUnitsConsumed int                 `json:"computeUnitsConsumed"`
-	Err                  interface{}         `json:"err"`
-	Fee                  int                 `json:"fee"`
-	InnerInstructions    []InnerInstructions `json:"innerInstructions"`
-	LoadedAddresses      LoadedAddresses     `json:"loadedAddresses"`
-	LogMessages          []string            `json:"logMessages"`
-	PostBalances         []interface{}       `json:"postBalances"`
-	PostTokenBalances    []PostTokenBalances `json:"postTokenBalances"`
-	PreBalances          []interface{}       `json:"preBalances"`
-	PreTokenBalances     []PreTokenBalances  `json:"preTokenBalances"`
-	ReturnData           ReturnData          `json:"returnData"`
-	Rewards              []interface{}       `json:"rewards"`
-	Status               Status              `json:"status"`
-}
-type Meta2 struct {
-	ComputeUnitsConsumed int                 `json:"computeUnitsConsumed"`
-	Err                  interface{}         `json:"err"`
-	Fee                  int                 `json:"fee"`
-	InnerInstructions    []InnerInstructions `json:"innerInstructions"`
-	LoadedAddresses      LoadedAddresses     `json:"loadedAddresses"`
-	LogMessages          []string            `json:"logMessages"`
-	PostBalances         []interface{}       `json:"postBalances"`
-	PostTokenBalances    []PostTokenBalances `json:"postTokenBalances"`
-	PreBalances          []interface{}       `json:"preBalances"`
-	PreTokenBalances     []PreTokenBalances  `json:"preTokenBalances"`
-	ReturnData           ReturnData          `json:"returnData"`
-	Rewards              []interface{}       `json:"rewards"`
-	Status               Status              `json:"status"`
+
+// MarshalJSON 按RPC的格式编码交易状态，成功为 {"Ok":null}，失败为 {"Err":错误}
+func (s Status) MarshalJSON() ([]byte, error) {
+	if s.Err.IsNil() {
+		return json.Marshal(map[string]interface{}{"Ok": s.Ok})
+	}
+	return json.Marshal(map[string]Err{"Err": s.Err})
 }
+
 type Transactions struct {
-	Meta        Meta        `json:"meta"`
-	Transaction Transaction `json:"transaction"`
-	Version     any         `json:"version"`
+	Meta        Meta               `json:"meta"`
+	Transaction Transaction        `json:"transaction"`
+	Version     TransactionVersion `json:"version"`
 }
@@ -0,0 +1,265 @@
+package resp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 区块级别的字段，BlockResp 没有解码，重新编码后不会出现
+var unmodeledBlockFields = map[string]bool{"blockHeight": true, "rewards": true}
+
+// RPC可能不返回、重新编码为null的字段: legacy 交易的 addressTableLookups，未设置 maxSupportedTransactionVersion 时的 version
+var nullWhenAbsentFields = map[string]bool{"addressTableLookups": true, "version": true}
+
+// loadBlockFixture 读取 testdata 下的 getBlock 返回结果，返回原始JSON和解码后的区块
+func loadBlockFixture(t *testing.T, name string) ([]byte, BlockResp) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取测试数据失败: %v", err)
+	}
+	var block BlockResp
+	if err := json.Unmarshal(data, &block); err != nil {
+		t.Fatalf("解码区块失败: %v", err)
+	}
+	return data, block
+}
+
+// decodeGeneric 按 json.Number 解码JSON，避免大整数经 float64 丢失精度
+func decodeGeneric(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("解码JSON失败: %v", err)
+	}
+	return value
+}
+
+func TestBlockRespRoundTrip(t *testing.T) {
+	for _, name := range []string{"get_block.json", "get_block_without_status.json"} {
+		t.Run(name, func(t *testing.T) {
+			data, block := loadBlockFixture(t, name)
+			encoded, err := json.Marshal(block)
+			if err != nil {
+				t.Fatalf("编码区块失败: %v", err)
+			}
+			var diffs []string
+			compareJSON("$", decodeGeneric(t, data), decodeGeneric(t, encoded), &diffs)
+			if len(diffs) > 0 {
+				t.Fatalf("重新编码后与原始数据不一致:\n%s", strings.Join(diffs, "\n"))
+			}
+
+			var decoded BlockResp
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("解码重新编码的区块失败: %v", err)
+			}
+			again, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("再次编码区块失败: %v", err)
+			}
+			if !bytes.Equal(encoded, again) {
+				t.Fatalf("两次编码结果不一致:\n%s\n%s", encoded, again)
+			}
+		})
+	}
+}
+
+// compareJSON 比较原始JSON和重新编码的JSON，差异追加到 diffs
+// 允许的差异: 结构体未解码的区块级别字段、RPC可能不返回而重新编码为null的字段、
+// 旧版本RPC缺少的 status(按 err 补齐)、RPC对零余额返回的 uiAmount 为null(解码为0)
+func compareJSON(path string, want, got interface{}, diffs *[]string) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望对象，实际为 %v", path, got))
+			return
+		}
+		for _, key := range unionKeys(w, g) {
+			child := path + "." + key
+			wantValue, inWant := w[key]
+			gotValue, inGot := g[key]
+			switch {
+			case !inGot:
+				if !(path == "$" && unmodeledBlockFields[key]) {
+					*diffs = append(*diffs, fmt.Sprintf("%s: 重新编码后缺失", child))
+				}
+			case !inWant:
+				if key == "status" {
+					compareJSON(child, synthesizedStatus(g["err"]), gotValue, diffs)
+				} else if !nullWhenAbsentFields[key] || gotValue != nil {
+					*diffs = append(*diffs, fmt.Sprintf("%s: 原始数据中不存在，实际为 %v", child, gotValue))
+				}
+			case key == "uiAmount" && wantValue == nil:
+				compareJSON(child, json.Number("0"), gotValue, diffs)
+			default:
+				compareJSON(child, wantValue, gotValue, diffs)
+			}
+		}
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(w) != len(g) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望 %v，实际为 %v", path, want, got))
+			return
+		}
+		for i := range w {
+			compareJSON(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], diffs)
+		}
+	case json.Number:
+		g, ok := got.(json.Number)
+		if !ok || !equalNumber(w, g) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望 %s，实际为 %v", path, w, got))
+		}
+	default:
+		if want != got {
+			*diffs = append(*diffs, fmt.Sprintf("%s: 期望 %v，实际为 %v", path, want, got))
+		}
+	}
+}
+
+// equalNumber 整数按原文比较，不能经 float64 丢失精度；小数按 float64 比较，不同编码器的格式可能不同
+func equalNumber(want, got json.Number) bool {
+	if want == got {
+		return true
+	}
+	if !strings.ContainsAny(want.String(), ".eE") {
+		return false
+	}
+	wantFloat, err := strconv.ParseFloat(want.String(), 64)
+	if err != nil {
+		return false
+	}
+	gotFloat, err := strconv.ParseFloat(got.String(), 64)
+	return err == nil && wantFloat == gotFloat
+}
+
+// synthesizedStatus 返回RPC没有 status 时按 err 补齐的交易状态
+func synthesizedStatus(err interface{}) interface{} {
+	if err == nil {
+		return map[string]interface{}{"Ok": nil}
+	}
+	return map[string]interface{}{"Err": err}
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestBlockRespDecode(t *testing.T) {
+	_, block := loadBlockFixture(t, "get_block.json")
+	if block.ParentSlot != 374921875 || block.BlockTime != 1760745600 {
+		t.Fatalf("区块头解码错误: parentSlot=%d blockTime=%d", block.ParentSlot, block.BlockTime)
+	}
+	if len(block.Transactions) != 6 {
+		t.Fatalf("期望6笔交易，实际为 %d", len(block.Transactions))
+	}
+	swap, instructionFailed, stringInstructionFailed, feeOnly, rentFailed, vote :=
+		block.Transactions[0], block.Transactions[1], block.Transactions[2],
+		block.Transactions[3], block.Transactions[4], block.Transactions[5]
+
+	t.Run("lamports超过2^31", func(t *testing.T) {
+		if swap.Meta.PreBalances[0] != 2147483648123 || swap.Meta.PostBalances[3] != 40906918933763 {
+			t.Fatalf("SOL余额解码错误: pre=%v post=%v", swap.Meta.PreBalances, swap.Meta.PostBalances)
+		}
+		if feeOnly.Meta.PreBalances[0] != 4294967296000 {
+			t.Fatalf("SOL余额解码错误: %v", feeOnly.Meta.PreBalances)
+		}
+		_, old := loadBlockFixture(t, "get_block_without_status.json")
+		if old.Transactions[1].Meta.PreBalances[0] != 9223372036854775807 {
+			t.Fatalf("SOL余额解码错误: %v", old.Transactions[1].Meta.PreBalances)
+		}
+	})
+
+	t.Run("u64代币数量", func(t *testing.T) {
+		amount := swap.Meta.PreTokenBalances[1].UITokenAmount.Amount
+		if amount.String() != "18446744073709551615" {
+			t.Fatalf("代币数量解码错误: %s", amount)
+		}
+		change := swap.Meta.PostTokenBalances[3].UITokenAmount.Amount.Sub(swap.Meta.PreTokenBalances[3].UITokenAmount.Amount)
+		if change.String() != "155000000" {
+			t.Fatalf("超过 2^53 的代币数量相减丢失精度: %s", change)
+		}
+	})
+
+	t.Run("对象错误", func(t *testing.T) {
+		got := instructionFailed.Meta.Err.InstructionError
+		if len(got) != 2 || got[0] != float64(2) {
+			t.Fatalf("指令错误解码错误: %v", got)
+		}
+		if custom, ok := got[1].(map[string]interface{}); !ok || custom["Custom"] != float64(6001) {
+			t.Fatalf("指令错误解码错误: %v", got[1])
+		}
+		if len(instructionFailed.Meta.Status.Err.InstructionError) != 2 {
+			t.Fatalf("status 中的指令错误解码错误: %+v", instructionFailed.Meta.Status)
+		}
+		if got := stringInstructionFailed.Meta.Err.InstructionError; len(got) != 2 || got[1] != "InvalidAccountData" {
+			t.Fatalf("指令错误解码错误: %v", got)
+		}
+		if rentFailed.Meta.Err.InstructionError != nil || rentFailed.Meta.Err.IsNil() {
+			t.Fatalf("非指令的对象错误应只保留原始JSON: %+v", rentFailed.Meta.Err)
+		}
+	})
+
+	t.Run("字符串错误", func(t *testing.T) {
+		if feeOnly.Meta.Err.InstructionError != nil || string(feeOnly.Meta.Err.raw) != `"ProgramAccountNotFound"` {
+			t.Fatalf("字符串错误解码错误: %+v", feeOnly.Meta.Err)
+		}
+		if !swap.Meta.Err.IsNil() || !vote.Meta.Err.IsNil() {
+			t.Fatal("成功交易不应有执行错误")
+		}
+	})
+
+	t.Run("stackHeight为null", func(t *testing.T) {
+		for _, instruction := range swap.Transaction.Message.Instructions {
+			if instruction.StackHeight != nil {
+				t.Fatalf("外层指令的 stackHeight 应为null，实际为 %d", *instruction.StackHeight)
+			}
+		}
+		inner := swap.Meta.InnerInstructions[0].Instructions[0]
+		if inner.StackHeight == nil || *inner.StackHeight != 2 {
+			t.Fatalf("内部指令的 stackHeight 解码错误: %v", inner.StackHeight)
+		}
+	})
+
+	t.Run("交易版本", func(t *testing.T) {
+		if swap.Version != TransactionVersion0 || !instructionFailed.Version.IsLegacy() {
+			t.Fatalf("交易版本解码错误: %q %q", swap.Version, instructionFailed.Version)
+		}
+		if keys := swap.ResolveAccountKeys(); len(keys) != 13 {
+			t.Fatalf("v0 交易应包含地址查找表加载的账户，实际为 %d 个", len(keys))
+		}
+	})
+}
+
+func TestBlockRespWithoutStatus(t *testing.T) {
+	_, block := loadBlockFixture(t, "get_block_without_status.json")
+	failed, succeeded := block.Transactions[0], block.Transactions[1]
+	if len(failed.Meta.Status.Err.InstructionError) != 2 {
+		t.Fatalf("缺少 status 时应按 err 补齐: %+v", failed.Meta.Status)
+	}
+	if !succeeded.Meta.Status.Err.IsNil() {
+		t.Fatalf("成功交易补齐的 status 不应有错误: %+v", succeeded.Meta.Status)
+	}
+	if !failed.Version.IsLegacy() || failed.Version != "" {
+		t.Fatalf("没有返回版本时应按 legacy 处理: %q", failed.Version)
+	}
+}
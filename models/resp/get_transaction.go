@@ -0,0 +1,10 @@
+package resp
+
+// GetTransactionResp 表示 getTransaction 返回的交易数据
+type GetTransactionResp struct {
+	Slot        uint64             `json:"slot"`
+	BlockTime   int64              `json:"blockTime"`
+	Meta        Meta               `json:"meta"`
+	Transaction Transaction        `json:"transaction"`
+	Version     TransactionVersion `json:"version"`
+}
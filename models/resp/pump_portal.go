@@ -7,6 +7,8 @@ type MessageType string
 const (
 	Create  MessageType = "create"
 	Migrate MessageType = "migrate"
+	Buy     MessageType = "buy"
+	Sell    MessageType = "sell"
 )
 
 type ClassifyType struct {
@@ -36,3 +38,19 @@ type MigrateMode struct {
 	TxType    MessageType `json:"txType"`
 	Pool      string      `json:"pool"`
 }
+
+// TokenTrade 表示PumpPortal推送的代币买卖
+type TokenTrade struct {
+	Signature             string          `json:"signature"`
+	Mint                  string          `json:"mint"`
+	TraderPublicKey       string          `json:"traderPublicKey"`
+	TxType                MessageType     `json:"txType"`
+	TokenAmount           decimal.Decimal `json:"tokenAmount"`
+	SolAmount             decimal.Decimal `json:"solAmount"`
+	NewTokenBalance       decimal.Decimal `json:"newTokenBalance"`
+	BondingCurveKey       string          `json:"bondingCurveKey"`
+	VTokensInBondingCurve decimal.Decimal `json:"vTokensInBondingCurve"`
+	VSolInBondingCurve    decimal.Decimal `json:"vSolInBondingCurve"`
+	MarketCapSol          decimal.Decimal `json:"marketCapSol"`
+	Pool                  string          `json:"pool"`
+}
@@ -0,0 +1,8 @@
+package resp
+
+// SlotNotification slotSubscribe 推送的槽位通知，只包含槽位号，区块数据需要再通过getBlock获取
+type SlotNotification struct {
+	Parent uint64 `json:"parent"` // 父槽位
+	Root   uint64 `json:"root"`   // 当前的根槽位
+	Slot   uint64 `json:"slot"`   // 新的槽位
+}
@@ -0,0 +1,114 @@
+package resp
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// TransactionSource 定义了规范化后的交易来源(DEX/程序)
+type TransactionSource string
+
+// 定义交易来源常量
+const (
+	SourceUnknown              TransactionSource = "UNKNOWN"                // 未知来源
+	SourceSystemProgram        TransactionSource = "SYSTEM_PROGRAM"         // 系统程序
+	SourceSolanaProgramLibrary TransactionSource = "SOLANA_PROGRAM_LIBRARY" // SPL程序
+	SourceJupiter              TransactionSource = "JUPITER"                // Jupiter聚合器
+	SourceRaydium              TransactionSource = "RAYDIUM"                // Raydium
+	SourceOrca                 TransactionSource = "ORCA"                   // Orca
+	SourceMeteora              TransactionSource = "METEORA"                // Meteora
+	SourcePumpFun              TransactionSource = "PUMP_FUN"               // pump.fun 联合曲线
+	SourcePumpAMM              TransactionSource = "PUMP_AMM"               // pump.fun AMM(PumpSwap)
+	SourcePhoenix              TransactionSource = "PHOENIX"                // Phoenix
+	SourceLifinity             TransactionSource = "LIFINITY"               // Lifinity
+	SourceOpenbook             TransactionSource = "OPENBOOK"               // Openbook
+	SourceMoonshot             TransactionSource = "MOONSHOT"               // Moonshot
+	SourceOKXDexRouter         TransactionSource = "OKX_DEX_ROUTER"         // OKX DEX路由
+)
+
+// sourceAliases 来源别名映射表，键为大写的原始名称
+// Helius 对同一DEX的不同程序版本会返回不同名称，统一归并到同一来源
+var sourceAliases = map[string]TransactionSource{
+	"UNKNOWN":                SourceUnknown,
+	"SYSTEM_PROGRAM":         SourceSystemProgram,
+	"SOLANA_PROGRAM_LIBRARY": SourceSolanaProgramLibrary,
+	"JUPITER":                SourceJupiter,
+	"JUPITER_LIMIT_ORDER":    SourceJupiter,
+	"JUPITER_DCA":            SourceJupiter,
+	"RAYDIUM":                SourceRaydium,
+	"RAYDIUM_AMM":            SourceRaydium,
+	"RAYDIUM_CLMM":           SourceRaydium,
+	"RAYDIUM_CPMM":           SourceRaydium,
+	"RAYDIUM_LAUNCHLAB":      SourceRaydium,
+	"ORCA":                   SourceOrca,
+	"WHIRLPOOL":              SourceOrca,
+	"METEORA":                SourceMeteora,
+	"METEORA_DLMM":           SourceMeteora,
+	"METEORA_POOLS":          SourceMeteora,
+	"METEORA_DAMM":           SourceMeteora,
+	"PUMP_FUN":               SourcePumpFun,
+	"PUMPFUN":                SourcePumpFun,
+	"PUMP.FUN":               SourcePumpFun,
+	"PUMP_AMM":               SourcePumpAMM,
+	"PUMP_SWAP":              SourcePumpAMM,
+	"PHOENIX":                SourcePhoenix,
+	"LIFINITY":               SourceLifinity,
+	"OPENBOOK":               SourceOpenbook,
+	"SERUM":                  SourceOpenbook,
+	"MOONSHOT":               SourceMoonshot,
+	"OKX_DEX_ROUTER":         SourceOKXDexRouter,
+}
+
+// 最多记录的未知来源名称数量，超出部分计入 maxUnknownSourceOverflow，避免无界增长
+const maxUnknownSources = 256
+
+// 超出记录上限的未知来源统一计入该名称
+const maxUnknownSourceOverflow = "<其他>"
+
+// unknownSources 记录无法识别的原始来源名称及出现次数
+var unknownSources = struct {
+	sync.Mutex
+	counts map[string]int64
+}{counts: make(map[string]int64)}
+
+// NormalizeSource 将原始来源名称规范化为 TransactionSource
+// 无法识别的名称返回 SourceUnknown，并记录到未知来源统计中
+func NormalizeSource(raw string) TransactionSource {
+	name := strings.ToUpper(strings.TrimSpace(raw))
+	if name == "" {
+		return SourceUnknown
+	}
+	if source, ok := sourceAliases[name]; ok {
+		return source
+	}
+
+	unknownSources.Lock()
+	if _, ok := unknownSources.counts[name]; !ok && len(unknownSources.counts) >= maxUnknownSources {
+		name = maxUnknownSourceOverflow
+	}
+	unknownSources.counts[name]++
+	unknownSources.Unlock()
+	return SourceUnknown
+}
+
+// UnknownSources 返回未识别的原始来源名称及出现次数
+func UnknownSources() map[string]int64 {
+	unknownSources.Lock()
+	defer unknownSources.Unlock()
+	counts := make(map[string]int64, len(unknownSources.counts))
+	for name, count := range unknownSources.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+// UnmarshalJSON 反序列化时自动规范化来源名称
+func (s *TransactionSource) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = NormalizeSource(raw)
+	return nil
+}
@@ -1,6 +1,10 @@
 package resp
 
-import "github.com/shopspring/decimal"
+import (
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
 
 var NeedToParseTransactionType = []TransactionType{
 	TransactionTypeTransfer,
@@ -25,14 +29,16 @@ const (
 	TransactionTypeBurn              TransactionType = "BURN"               // 销毁代币
 	TransactionTypeInitializeAccount TransactionType = "INITIALIZE_ACCOUNT" // 初始化代币账户
 	TransactionTypeTokenMint         TransactionType = "TOKEN_MINT"
-	TransactionTypeSwap              TransactionType = "SWAP" // 代币交换
+	TransactionTypeSwap              TransactionType = "SWAP"     // 代币交换
+	TransactionTypeNFTSale           TransactionType = "NFT_SALE" // NFT销售
+	TransactionTypeAny               TransactionType = "ANY"      // 任意类型，仅用于Webhook订阅
 )
 
 // ParsedTransaction 表示解析后的交易数据
 type ParsedTransaction struct {
 	Description      string            `json:"description"`
-	Type             TransactionType   `json:"type"` // 使用枚举类型
-	Source           string            `json:"source"`
+	Type             TransactionType   `json:"type"`   // 使用枚举类型
+	Source           TransactionSource `json:"source"` // 规范化后的来源
 	Fee              int64             `json:"fee"`
 	FeePayer         string            `json:"feePayer"`
 	Signature        string            `json:"signature"`
@@ -44,6 +50,40 @@ type ParsedTransaction struct {
 	TransactionError *TransactionError `json:"transactionError,omitempty"`
 	Instructions     []Instruction     `json:"instructions"`
 	Events           *Events           `json:"events,omitempty"`
+	Valuation        *SwapValuation    `json:"valuation,omitempty"`     // 处理时计算的swap美元价值，不是Helius返回的字段
+	WalletContext    *WalletContext    `json:"walletContext,omitempty"` // 处理时附加的手续费支付者历史信息，不是Helius返回的字段
+}
+
+// SwapValuation 按处理时的Jupiter价格计算的swap美元价值
+type SwapValuation struct {
+	SOLAmount    float64 `json:"solAmount"`    // swap的SOL成交量(SOL)，代币之间的swap为0
+	PricedMint   string  `json:"pricedMint"`   // 用于计价的代币，有SOL一侧时为Wrapped SOL
+	PricedAmount float64 `json:"pricedAmount"` // 计价代币的数量
+	PriceUSD     float64 `json:"priceUsd"`     // 计价代币的美元价格
+	USDValue     float64 `json:"usdValue"`     // 美元价值
+	PricedAt     int64   `json:"pricedAt"`     // 所用价格的获取时间(Unix时间戳)
+}
+
+// WalletContext swap交易发起钱包(手续费支付者)的历史信息
+type WalletContext struct {
+	Wallet          string `json:"wallet"`                   // 钱包地址
+	Watchlisted     bool   `json:"watchlisted"`              // 是否在关注地址列表中
+	WatchlistLabel  string `json:"watchlistLabel,omitempty"` // 关注地址的备注
+	FirstSeen       int64  `json:"firstSeen,omitempty"`      // 查询到的最早一笔交易的时间(Unix时间戳)
+	PriorTrades     int    `json:"priorTrades"`              // 本交易之前的swap交易数
+	HistoryComplete bool   `json:"historyComplete"`          // 是否查询到了全部历史，为false时 firstSeen 和 priorTrades 只是查询范围内的值
+	FetchedAt       int64  `json:"fetchedAt,omitempty"`      // 历史查询时间(Unix时间戳)，查询失败时为0
+}
+
+// Mints 返回交易中代币转账涉及的代币地址，按首次出现的顺序去重
+func (t *ParsedTransaction) Mints() []string {
+	var mints []string
+	for _, transfer := range t.TokenTransfers {
+		if transfer.Mint != "" && !slices.Contains(mints, transfer.Mint) {
+			mints = append(mints, transfer.Mint)
+		}
+	}
+	return mints
 }
 
 // NativeTransfer 表示原生代币(SOL)转账
@@ -166,10 +206,10 @@ type InnerSwap struct {
 
 // ProgramInfo 表示程序信息
 type ProgramInfo struct {
-	Source          string `json:"source"`
-	Account         string `json:"account"`
-	ProgramName     string `json:"programName"`
-	InstructionName string `json:"instructionName"`
+	Source          TransactionSource `json:"source"`
+	Account         string            `json:"account"`
+	ProgramName     string            `json:"programName"`
+	InstructionName string            `json:"instructionName"`
 }
 
 // CompressedEvent 表示压缩NFT事件
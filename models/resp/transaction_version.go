@@ -0,0 +1,79 @@
+package resp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// TransactionVersion 交易版本，RPC对 legacy 交易返回字符串 "legacy"，对版本化交易返回数字
+// 请求未设置 maxSupportedTransactionVersion 时RPC不返回版本，解码为空字符串，按 legacy 处理
+type TransactionVersion string
+
+const (
+	TransactionVersionLegacy TransactionVersion = "legacy" // legacy 交易，账户列表只包含消息中的静态账户
+	TransactionVersion0      TransactionVersion = "0"      // v0 交易，可以通过地址查找表加载账户
+)
+
+// UnmarshalJSON 解码字符串或数字形式的交易版本
+func (v *TransactionVersion) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*v = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var version string
+		if err := json.Unmarshal(data, &version); err != nil {
+			return err
+		}
+		*v = TransactionVersion(version)
+		return nil
+	}
+	var version uint8
+	if err := json.Unmarshal(data, &version); err != nil {
+		return err
+	}
+	*v = TransactionVersion(strconv.Itoa(int(version)))
+	return nil
+}
+
+// MarshalJSON 按RPC的格式编码交易版本，legacy 为字符串，版本化交易为数字
+func (v TransactionVersion) MarshalJSON() ([]byte, error) {
+	if v == "" {
+		return []byte("null"), nil
+	}
+	if _, err := strconv.ParseUint(string(v), 10, 8); err == nil {
+		return []byte(v), nil
+	}
+	return json.Marshal(string(v))
+}
+
+// IsLegacy 返回是否为 legacy 交易
+func (v TransactionVersion) IsLegacy() bool {
+	return v == "" || v == TransactionVersionLegacy
+}
+
+// ResolveAccountKeys 返回交易的完整账户列表，指令中的账户索引按此列表解析
+// legacy 交易只有消息中的静态账户；v0 交易在静态账户后依次追加地址查找表加载的可写账户和只读账户，
+// 与运行时的账户顺序一致。RPC没有返回 loadedAddresses 时只能返回静态账户，查找表账户的索引会越界
+func (t Transactions) ResolveAccountKeys() []string {
+	return resolveAccountKeys(t.Version, t.Meta, t.Transaction)
+}
+
+// ResolveAccountKeys 返回交易的完整账户列表，见 Transactions.ResolveAccountKeys
+func (t GetTransactionResp) ResolveAccountKeys() []string {
+	return resolveAccountKeys(t.Version, t.Meta, t.Transaction)
+}
+
+// resolveAccountKeys 按交易版本拼接静态账户和地址查找表加载的账户
+func resolveAccountKeys(version TransactionVersion, meta Meta, transaction Transaction) []string {
+	static := transaction.Message.AccountKeys
+	loaded := meta.LoadedAddresses
+	if version.IsLegacy() && len(transaction.Message.AddressTableLookups) == 0 {
+		return append([]string(nil), static...)
+	}
+	keys := make([]string, 0, len(static)+len(loaded.Writable)+len(loaded.Readonly))
+	keys = append(keys, static...)
+	keys = append(keys, loaded.Writable...)
+	return append(keys, loaded.Readonly...)
+}
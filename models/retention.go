@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// 数据保留策略的处理方式
+const (
+	RetentionActionExpire = "expire" // 为没有过期时间的键设置过期时间
+	RetentionActionDelete = "delete" // 删除长时间未访问的键
+	RetentionActionTrim   = "trim"   // 将 ZSet/List 裁剪到指定长度
+)
+
+// RetentionResult 单个保留策略的执行结果，试运行时各计数为会被处理的数量
+type RetentionResult struct {
+	Policy   string   `json:"policy"`             // 策略名称
+	Workload string   `json:"workload,omitempty"` // 键所在的Redis负载
+	Pattern  string   `json:"pattern"`            // 键名匹配模式
+	Action   string   `json:"action"`             // 处理方式
+	Scanned  int64    `json:"scanned"`            // 扫描到的键数
+	Matched  int64    `json:"matched"`            // 符合策略需要处理的键数
+	Expired  int64    `json:"expired"`            // 设置了过期时间的键数
+	Deleted  int64    `json:"deleted"`            // 删除的键数
+	Trimmed  int64    `json:"trimmed"`            // 裁剪掉的元素数
+	Samples  []string `json:"samples,omitempty"`  // 需要处理的键名样例
+	Error    string   `json:"error,omitempty"`    // 执行失败的原因，失败前已处理的键仍计入
+}
+
+// RetentionReport 一次数据保留的执行报告
+type RetentionReport struct {
+	DryRun     bool              `json:"dry_run"`     // 是否为试运行
+	StartedAt  time.Time         `json:"started_at"`  // 开始时间
+	DurationMs int64             `json:"duration_ms"` // 执行耗时(毫秒)
+	Policies   []RetentionResult `json:"policies"`    // 各策略的执行结果
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SkippedSlot 多次获取失败、暂时跳过的槽位，按较慢的间隔自动重试
+// 与没有出块的槽位(ORPHANED)不同，这些槽位有区块但一直获取或处理失败，需要人工排查
+type SkippedSlot struct {
+	Slot          uint64     `json:"slot"`                    // 槽位
+	Attempts      int        `json:"attempts"`                // 进入跳过列表后累计失败的次数
+	LastError     string     `json:"last_error"`              // 最近一次失败的原因
+	FirstFailedAt time.Time  `json:"first_failed_at"`         // 第一次进入跳过列表的时间
+	LastFailedAt  time.Time  `json:"last_failed_at"`          // 最近一次失败的时间
+	NextRetryAt   *time.Time `json:"next_retry_at,omitempty"` // 下一次自动重试的时间，放弃自动重试后为空
+	Abandoned     bool       `json:"abandoned"`               // 是否已达到最大重试次数，不再自动重试
+}
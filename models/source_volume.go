@@ -0,0 +1,11 @@
+package models
+
+// SourceVolume 单个交易来源在一小时内的交易数和SOL成交量
+type SourceVolume struct {
+	Source       string  `json:"source"`                 // 规范化后的交易来源，如 RAYDIUM、JUPITER、PUMP_FUN
+	Hour         int64   `json:"hour,omitempty"`         // 小时起始时间(Unix时间戳)，汇总结果中为0
+	Transactions int64   `json:"transactions"`           // 解析出的交易数
+	Swaps        int64   `json:"swaps"`                  // 其中swap交易数
+	SOLVolume    float64 `json:"sol_volume"`             // swap交易的SOL成交量
+	VolumeShare  float64 `json:"volume_share,omitempty"` // 占所有来源SOL成交量的比例，仅汇总结果中填充
+}
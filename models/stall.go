@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StallKind 出块停滞的判定原因
+type StallKind string
+
+const (
+	StallNone         StallKind = ""             // 未停滞
+	StallSubscription StallKind = "subscription" // HTTP探测到槽位仍在推进，本地WebSocket订阅失效
+	StallCluster      StallKind = "cluster"      // HTTP探测到槽位也未推进，集群出块停滞
+	StallNetwork      StallKind = "network"      // HTTP探测失败，网络或RPC节点不可用
+)
+
+// StallReport 出块停滞检测结果
+type StallReport struct {
+	Kind             StallKind `json:"kind"`                  // 停滞原因，为空表示已恢复
+	Message          string    `json:"message"`               // 说明
+	LastSlot         uint64    `json:"last_slot"`             // 最后一次通过WebSocket收到的槽位
+	LastNotification time.Time `json:"last_notification"`     // 最后一次收到槽位通知的时间
+	Silence          int64     `json:"silence"`               // 未收到槽位通知的时长(秒)
+	ProbeSlot        uint64    `json:"probe_slot,omitempty"`  // HTTP getSlot探测到的槽位
+	ProbeError       string    `json:"probe_error,omitempty"` // HTTP探测失败原因
+	Time             time.Time `json:"time"`                  // 检测时间
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SubscriptionSilenceReport WebSocket订阅超过阈值未收到通知或恢复，恢复后再报告一次
+type SubscriptionSilenceReport struct {
+	Connection    int       `json:"connection"`      // 连接序号
+	Subscription  int       `json:"subscription"`    // 客户端订阅ID
+	Method        string    `json:"method"`          // 订阅方法，如 slotSubscribe
+	Silence       int64     `json:"silence"`         // 未收到通知的时长(秒)
+	MaxSilence    int64     `json:"max_silence"`     // 告警阈值(秒)
+	LastMessageAt time.Time `json:"last_message_at"` // 最近一次收到通知的时间，从未收到时为订阅确认时间
+	Reconnected   bool      `json:"reconnected"`     // 是否已断开该连接重连
+	Recovered     bool      `json:"recovered"`       // 是否已恢复
+	Message       string    `json:"message"`         // 说明
+	Time          time.Time `json:"time"`            // 检测时间
+}
@@ -0,0 +1,11 @@
+package models
+
+// TokenAccountSnapshot 单个代币在一个快照间隔内创建和关闭的代币账户数量，可作为持有人增长的近似指标
+type TokenAccountSnapshot struct {
+	Mint      string `json:"mint"`      // 代币地址
+	Timestamp int64  `json:"timestamp"` // 快照时间(Unix时间戳)
+	Interval  int64  `json:"interval"`  // 统计区间长度(秒)
+	Created   int    `json:"created"`   // 区间内创建的代币账户数
+	Closed    int    `json:"closed"`    // 区间内关闭的代币账户数
+	Net       int    `json:"net"`       // 净增数量，创建-关闭
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// 新代币风险检测的风险项
+const (
+	TokenRiskMintAuthority   = "mint_authority"   // 增发权限未放弃，创建者可以随时增发稀释持有者
+	TokenRiskFreezeAuthority = "freeze_authority" // 冻结权限未放弃，创建者可以冻结买入者的代币账户使其无法卖出
+	TokenRiskTopHolder       = "top_holder"       // 最大持有者占供应量的比例过高
+	TokenRiskTop10Holders    = "top10_holders"    // 前10持有者占供应量的比例过高
+	TokenRiskNoLiquidity     = "no_liquidity"     // 没有找到联合曲线或流动性池
+)
+
+// 新代币的流动性状态
+const (
+	TokenLiquidityBondingCurve = "bonding_curve" // 在Pump.fun联合曲线上交易，流动性无法被撤走
+	TokenLiquidityMigrated     = "migrated"      // 已从联合曲线迁移到AMM
+	TokenLiquidityPool         = "pool"          // 发现了该代币的流动性池
+	TokenLiquidityNone         = "none"          // 没有找到联合曲线或流动性池
+)
+
+// 新代币的风险等级
+const (
+	TokenRiskLow    = "low"
+	TokenRiskMedium = "medium"
+	TokenRiskHigh   = "high"
+)
+
+// TokenRiskReport 新代币的风险检测结果，按代币保存
+type TokenRiskReport struct {
+	Mint            string    `json:"mint"`                       // 代币地址
+	Source          string    `json:"source"`                     // 发现来源: pump_portal、transaction、api
+	Program         string    `json:"program,omitempty"`          // 代币程序，spl-token 或 spl-token-2022
+	MintAuthority   string    `json:"mint_authority,omitempty"`   // 增发权限，已放弃时为空
+	FreezeAuthority string    `json:"freeze_authority,omitempty"` // 冻结权限，已放弃时为空
+	Supply          float64   `json:"supply"`                     // 总供应量，已按精度换算
+	Decimals        int       `json:"decimals"`                   // 精度
+	Holders         int       `json:"holders"`                    // 余额大于0的持有者数，不含排除的持有者
+	HoldersComplete bool      `json:"holders_complete"`           // 是否查询了全部代币账户，为false时持有者数只是下限
+	TopHolder       string    `json:"top_holder,omitempty"`       // 最大持有者
+	TopHolderShare  float64   `json:"top_holder_share"`           // 最大持有者占供应量的比例
+	Top10Share      float64   `json:"top10_share"`                // 前10持有者占供应量的比例
+	Excluded        []string  `json:"excluded,omitempty"`         // 统计持仓集中度时排除的持有者，如联合曲线和池子账户
+	Liquidity       string    `json:"liquidity"`                  // 流动性状态
+	Pool            string    `json:"pool,omitempty"`             // 流动性池或联合曲线账户
+	Flags           []string  `json:"flags"`                      // 命中的风险项
+	Score           int       `json:"score"`                      // 风险分，0到100，越高越危险
+	Level           string    `json:"level"`                      // 风险等级: low、medium、high
+	CheckedAt       time.Time `json:"checked_at"`                 // 检测时间
+}
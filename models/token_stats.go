@@ -0,0 +1,46 @@
+package models
+
+// 与Dexscreener交易对接口兼容的代币统计，价格、成交量、流动性和市值均以SOL计价
+
+// TokenStatsResponse 代币统计响应，与 /latest/dex/tokens/{addresses} 的结构相同
+type TokenStatsResponse struct {
+	SchemaVersion string       `json:"schemaVersion"` // 结构版本
+	Pairs         []TokenStats `json:"pairs"`         // 每个代币一条记录
+}
+
+// TokenStats 单个代币相对SOL的24小时统计
+type TokenStats struct {
+	ChainID       string              `json:"chainId"`                 // 固定为 solana
+	DexID         string              `json:"dexId"`                   // 24小时内成交量最大的来源，如 raydium、pump_fun
+	PairAddress   string              `json:"pairAddress,omitempty"`   // 交易对地址，已知时为PumpPortal推送的联合曲线或池子地址
+	BaseToken     TokenInfo           `json:"baseToken"`               // 统计的代币
+	QuoteToken    TokenInfo           `json:"quoteToken"`              // 计价代币，固定为Wrapped SOL
+	PriceNative   string              `json:"priceNative"`             // 最新成交价(SOL)
+	Txns          map[string]TxnCount `json:"txns"`                    // 各窗口(m5、h1、h6、h24)的买卖笔数
+	Volume        map[string]float64  `json:"volume"`                  // 各窗口的成交量(SOL)
+	PriceChange   map[string]float64  `json:"priceChange"`             // 各窗口的价格变化(百分比)
+	Liquidity     *Liquidity          `json:"liquidity,omitempty"`     // 最近一次已知的流动性，仅联合曲线/池子数据可用时返回
+	MarketCap     float64             `json:"marketCap,omitempty"`     // 最近一次已知的市值(SOL)
+	UniqueWallets map[string]int      `json:"uniqueWallets"`           // 各窗口的独立交易钱包数(扩展字段)
+	UpdatedAt     int64               `json:"updatedAt"`               // 最近一次成交时间(Unix毫秒)
+	PairCreatedAt int64               `json:"pairCreatedAt,omitempty"` // 首次出现时间(Unix毫秒)
+}
+
+// TokenInfo 代币信息
+type TokenInfo struct {
+	Address string `json:"address"` // 代币地址
+	Name    string `json:"name"`    // 名称，未知时为空
+	Symbol  string `json:"symbol"`  // 符号，未知时为空
+}
+
+// TxnCount 买卖笔数
+type TxnCount struct {
+	Buys  int `json:"buys"`
+	Sells int `json:"sells"`
+}
+
+// Liquidity 流动性
+type Liquidity struct {
+	Base  float64 `json:"base"`  // 池中的代币数量
+	Quote float64 `json:"quote"` // 池中的SOL数量
+}
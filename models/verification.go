@@ -0,0 +1,23 @@
+package models
+
+// VerificationMismatch 抽样校验发现的一处不一致
+type VerificationMismatch struct {
+	Signature string `json:"signature"` // 交易签名
+	Slot      uint64 `json:"slot"`      // 区块高度
+	Field     string `json:"field"`     // 不一致的字段：fee、slot、token_transfers、signature
+	Raw       string `json:"raw"`       // 原始区块中的值
+	Enhanced  string `json:"enhanced"`  // Enhanced API解析结果中的值
+	Time      int64  `json:"time"`      // 发现时间(Unix时间戳)
+}
+
+// VerificationStats 抽样校验的统计，用于衡量Enhanced API解析结果的数据质量
+type VerificationStats struct {
+	Sampled          int64                  `json:"sampled"`           // 已抽样的交易数
+	Pending          int                    `json:"pending"`           // 等待解析结果的抽样数
+	Checked          int64                  `json:"checked"`           // 已完成校验的抽样数，包括超时未返回的
+	Mismatched       int64                  `json:"mismatched"`        // 至少一个字段不一致的抽样数
+	Dropped          int64                  `json:"dropped"`           // 等待数量达到上限而放弃的抽样数
+	MismatchRate     float64                `json:"mismatch_rate"`     // 不一致比例，Mismatched / Checked
+	Fields           map[string]int64       `json:"fields"`            // 各字段的不一致次数
+	RecentMismatches []VerificationMismatch `json:"recent_mismatches"` // 最近的不一致记录，最新的在前
+}
@@ -0,0 +1,12 @@
+package models
+
+// WalletHistory 通过Enhanced API查询到的钱包历史摘要，按钱包缓存，之后处理的该钱包swap交易依次计入
+type WalletHistory struct {
+	Wallet       string `json:"wallet"`               // 钱包地址
+	FirstSeen    int64  `json:"first_seen,omitempty"` // 查询到的最早一笔交易的时间(Unix时间戳)，没有历史时为0
+	Transactions int    `json:"transactions"`         // 查询到的历史交易数
+	Trades       int    `json:"trades"`               // 其中swap交易的数量
+	Complete     bool   `json:"complete"`             // 是否查询到了全部历史，为false时 FirstSeen 和交易数只是查询范围内的值
+	FetchedAt    int64  `json:"fetched_at"`           // 查询时间(Unix时间戳)
+	LatestSlot   uint64 `json:"latest_slot"`          // 摘要包含的最新一笔交易的槽位，只有更晚的交易才会计入
+}
@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// observeHealth 按请求结果更新密钥的隔离状态，调用方需持有锁
+// 401/403 立即隔离，连续返回429达到阈值后隔离，请求成功时清零连续限流次数
+func (t *APIKeyUsageTracker) observeHealth(index int, code string) {
+	if !t.quarantine.Enabled {
+		return
+	}
+	state := &t.keys[index]
+	switch code {
+	case "":
+		state.rateLimited = 0
+	case "401", "403":
+		t.quarantineKey(index, t.quarantine.RejectedCooldown, "API密钥无效或无权访问(HTTP "+code+")")
+	case "429":
+		state.rateLimited++
+		if state.rateLimited >= t.quarantine.RateLimitThreshold {
+			t.quarantineKey(index, t.quarantine.RateLimitCooldown, fmt.Sprintf("连续 %d 次被限流", state.rateLimited))
+		}
+	}
+}
+
+// quarantineKey 隔离密钥，已在隔离中时只延长隔离时间，调用方需持有锁
+func (t *APIKeyUsageTracker) quarantineKey(index int, cooldown time.Duration, reason string) {
+	state := &t.keys[index]
+	wasHealthy := state.quarantinedUntil.IsZero()
+	state.quarantinedUntil = clock.Now().Add(cooldown)
+	state.quarantineReason = reason
+	state.rateLimited = 0
+	if wasHealthy {
+		t.log.Warn("API密钥已被隔离，冷却期内不再分配解析批次",
+			zap.Int("index", index), zap.String("key", state.key), zap.String("reason", reason), zap.Duration("cooldown", cooldown))
+		t.checkHealthy()
+	}
+}
+
+// quarantined 返回密钥是否在隔离中，冷却期已过时解除隔离，调用方需持有锁
+func (t *APIKeyUsageTracker) quarantined(index int, now time.Time) bool {
+	state := &t.keys[index]
+	if state.quarantinedUntil.IsZero() {
+		return false
+	}
+	if now.Before(state.quarantinedUntil) {
+		return true
+	}
+	state.quarantinedUntil = time.Time{}
+	state.quarantineReason = ""
+	t.log.Info("API密钥隔离期结束，重新分配解析批次", zap.Int("index", index), zap.String("key", state.key))
+	t.checkHealthy()
+	return false
+}
+
+// checkHealthy 未被隔离的密钥数低于告警下限时记录错误日志并发布API密钥事件，恢复后再发布一次，调用方需持有锁
+func (t *APIKeyUsageTracker) checkHealthy() {
+	report := models.APIKeyHealthReport{
+		Total:      len(t.keys),
+		MinHealthy: t.quarantine.MinHealthyKeys,
+		Time:       clock.Now(),
+	}
+	for i := range t.keys {
+		if t.keys[i].quarantinedUntil.IsZero() {
+			report.Healthy++
+		} else {
+			report.Quarantined = append(report.Quarantined, i)
+		}
+	}
+	unhealthy := report.Healthy < report.MinHealthy
+	if unhealthy == t.unhealthy {
+		return
+	}
+	t.unhealthy = unhealthy
+	if unhealthy {
+		report.Message = fmt.Sprintf("未被隔离的API密钥只剩 %d/%d 个，低于下限 %d，被隔离的密钥索引: %v", report.Healthy, report.Total, report.MinHealthy, report.Quarantined)
+		t.log.Error(report.Message)
+	} else {
+		report.Recovered = true
+		report.Message = fmt.Sprintf("未被隔离的API密钥已恢复到 %d/%d 个", report.Healthy, report.Total)
+		t.log.Info(report.Message)
+	}
+	pipeline.Publish(pipeline.Event{
+		Type:      pipeline.EventAPIKeys,
+		KeyHealth: &report,
+		Time:      report.Time,
+	})
+}
+
+// APIKeyRateLimit 单个密钥当前的限流和隔离状态
+type APIKeyRateLimit struct {
+	Index            int        `json:"index"`                       // 密钥在 api_keys 中的索引
+	Key              string     `json:"key"`                         // 脱敏后的密钥
+	RateLimited      int        `json:"rate_limited"`                // 连续返回429的次数
+	Healthy          bool       `json:"healthy"`                     // 是否未被隔离
+	QuarantinedUntil *time.Time `json:"quarantined_until,omitempty"` // 隔离结束时间
+	QuarantineReason string     `json:"quarantine_reason,omitempty"` // 隔离原因
+	Exhausted        bool       `json:"exhausted"`                   // 当天用量是否已达到预算
+}
+
+// RateLimits 返回各密钥当前的限流和隔离状态，只读取内存中的状态，不访问Redis
+func (t *APIKeyUsageTracker) RateLimits() []APIKeyRateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := clock.Now()
+	limits := make([]APIKeyRateLimit, len(t.keys))
+	for i, state := range t.keys {
+		limit := APIKeyRateLimit{
+			Index:       i,
+			Key:         state.key,
+			RateLimited: state.rateLimited,
+			Healthy:     !now.Before(state.quarantinedUntil),
+			Exhausted:   state.budget > 0 && state.credits >= state.budget,
+		}
+		if !limit.Healthy {
+			until := state.quarantinedUntil
+			limit.QuarantinedUntil = &until
+			limit.QuarantineReason = state.quarantineReason
+		}
+		limits[i] = limit
+	}
+	return limits
+}
@@ -0,0 +1,235 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+var (
+	// ErrAPIKeyBudgetExhausted 所有Enhanced API密钥当天的用量都已达到预算
+	ErrAPIKeyBudgetExhausted = errors.New("所有API密钥当天的用量都已达到预算")
+	// ErrNoHealthyAPIKey 未达到预算的Enhanced API密钥都在隔离中
+	ErrNoHealthyAPIKey = errors.New("没有未被隔离的API密钥")
+)
+
+// GlobalAPIKeyUsage 全局Enhanced API密钥用量统计
+var GlobalAPIKeyUsage *APIKeyUsageTracker
+
+// APIKeyUsage 单个Enhanced API密钥的用量和预算
+type APIKeyUsage struct {
+	Index       int    `json:"index"`        // 密钥在 api_keys 中的索引
+	ID          string `json:"id"`           // 密钥指纹，与Redis键名中的一致
+	Key         string `json:"key"`          // 脱敏后的密钥
+	DailyBudget int64  `json:"daily_budget"` // 每天可消耗的额度，0表示不限制
+	Remaining   int64  `json:"remaining"`    // 当天剩余额度，不限制时为-1
+	Exhausted   bool   `json:"exhausted"`    // 当天用量是否已达到预算，达到后不再分配解析批次
+
+	Healthy          bool       `json:"healthy"`                     // 是否未被隔离
+	QuarantinedUntil *time.Time `json:"quarantined_until,omitempty"` // 隔离结束时间
+	QuarantineReason string     `json:"quarantine_reason,omitempty"` // 隔离原因
+
+	Days    []models.APIKeyDailyUsage `json:"days"`    // 从当天开始按日期降序的每日用量
+	Process metrics.APIKeyCounters    `json:"process"` // 本进程启动以来的累计用量
+}
+
+// apiKeyState 单个密钥当天的用量
+type apiKeyState struct {
+	id      string
+	key     string
+	budget  int64
+	day     int64
+	credits int64 // 当天消耗的额度，写入Redis成功时为所有进程的合计
+
+	rateLimited      int       // 连续返回429的次数
+	quarantinedUntil time.Time // 隔离结束时间，零值表示未被隔离
+	quarantineReason string
+}
+
+// APIKeyUsageTracker 按密钥和UTC日期在Redis中统计Enhanced API的请求数、消耗的额度和错误码，
+// 当天用量达到预算的密钥不再分配解析批次；Redis不可用时只按本进程的用量判断
+// 启用隔离时返回401/403或持续429的密钥在冷却期内也不再分配解析批次
+type APIKeyUsageTracker struct {
+	mu         sync.Mutex
+	keys       []apiKeyState
+	retention  time.Duration
+	quarantine configs.APIKeyQuarantineConfig
+	unhealthy  bool // 未被隔离的密钥数是否低于告警下限
+	log        *zap.Logger
+}
+
+// NewAPIKeyUsageTracker 创建密钥用量统计并设置为全局实例，从Redis载入当天已消耗的额度
+func NewAPIKeyUsageTracker(config *configs.HeliusEnhancedAPIConfig) *APIKeyUsageTracker {
+	tracker := &APIKeyUsageTracker{
+		keys:       make([]apiKeyState, len(config.APIKeys)),
+		retention:  config.UsageRetention,
+		quarantine: config.Quarantine,
+		log:        logger.Named("monitor.api_key_usage"),
+	}
+	day := storage.IndexDay(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i, apiKey := range config.APIKeys {
+		state := apiKeyState{id: rpc.KeyID(apiKey), key: rpc.MaskKey(apiKey), day: day}
+		if i < len(config.DailyBudgets) {
+			state.budget = config.DailyBudgets[i]
+		}
+		if usages, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetAPIKeyUsage(ctx, state.id, day, 1); err == nil {
+			state.credits = usages[0].Credits
+		}
+		tracker.keys[i] = state
+	}
+	GlobalAPIKeyUsage = tracker
+	return tracker
+}
+
+// RecordUsage 记录一次请求，实现 rpc.UsageRecorder
+func (t *APIKeyUsageTracker) RecordUsage(index int, credits int, code string) {
+	if index < 0 || index >= len(t.keys) {
+		return
+	}
+	day := storage.IndexDay(0)
+	t.mu.Lock()
+	id := t.keys[index].id
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	total, err := storage.GetRedisClient(storage.WorkloadAnalytics).IncrAPIKeyUsage(ctx, id, day, int64(credits), code, t.retention)
+	if err != nil {
+		t.log.Debug("记录API密钥用量失败，只按本进程的用量判断预算", zap.Int("index", index), zap.Error(err))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := &t.keys[index]
+	if state.day != day {
+		state.day = day
+		state.credits = 0
+	}
+	if err != nil {
+		state.credits += int64(credits)
+	} else {
+		state.credits = total
+	}
+	if state.budget > 0 && state.credits >= state.budget && state.credits-int64(credits) < state.budget {
+		t.log.Warn("API密钥当天用量已达到预算，不再分配解析批次",
+			zap.Int("index", index), zap.String("key", state.key), zap.Int64("budget", state.budget))
+	}
+	t.observeHealth(index, code)
+}
+
+// Available 返回密钥是否可以分配解析批次
+// 返回:
+//   - error: 密钥在隔离中时返回 ErrNoHealthyAPIKey，当天用量达到预算时返回 ErrAPIKeyBudgetExhausted
+func (t *APIKeyUsageTracker) Available(index int) error {
+	if index < 0 || index >= len(t.keys) {
+		return nil
+	}
+	now := clock.Now()
+	day := storage.IndexDay(now.Unix())
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := &t.keys[index]
+	if state.day != day {
+		state.day = day
+		state.credits = 0
+	}
+	if t.quarantined(index, now) {
+		return ErrNoHealthyAPIKey
+	}
+	if state.budget > 0 && state.credits >= state.budget {
+		return ErrAPIKeyBudgetExhausted
+	}
+	return nil
+}
+
+// Usage 返回所有密钥的用量和预算
+// 参数:
+//   - ctx: 上下文
+//   - days: 返回的天数，从当天开始
+//
+// 返回:
+//   - []APIKeyUsage: 按索引排列的用量，Redis不可用时每日用量只包含本进程当天的额度
+func (t *APIKeyUsageTracker) Usage(ctx context.Context, days int) []APIKeyUsage {
+	day := storage.IndexDay(0)
+	usages := make([]APIKeyUsage, len(t.keys))
+	for i := range t.keys {
+		t.Available(i)
+		t.mu.Lock()
+		state := t.keys[i]
+		t.mu.Unlock()
+
+		usage := APIKeyUsage{
+			Index:            i,
+			ID:               state.id,
+			Key:              state.key,
+			DailyBudget:      state.budget,
+			Remaining:        -1,
+			Exhausted:        state.budget > 0 && state.credits >= state.budget,
+			Healthy:          state.quarantinedUntil.IsZero(),
+			QuarantineReason: state.quarantineReason,
+			Process:          metrics.APIKey(state.id),
+		}
+		if !state.quarantinedUntil.IsZero() {
+			until := state.quarantinedUntil
+			usage.QuarantinedUntil = &until
+		}
+		if state.budget > 0 {
+			usage.Remaining = max(state.budget-state.credits, 0)
+		}
+		daily, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetAPIKeyUsage(ctx, state.id, day, days)
+		if err != nil {
+			daily = []models.APIKeyDailyUsage{{Day: day, Credits: state.credits}}
+		}
+		usage.Days = daily
+		usages[i] = usage
+	}
+	return usages
+}
+
+// NextAPIKey 从 start 开始轮流查找未被隔离且当天用量未达到预算的密钥，未创建用量统计时直接返回 start 对应的密钥
+// 参数:
+//   - start: 按轮询顺序分配的序号
+//   - count: 密钥数量
+//
+// 返回:
+//   - int: 密钥索引
+//   - error: 没有可用的密钥时，所有密钥都达到预算返回 ErrAPIKeyBudgetExhausted，否则返回 ErrNoHealthyAPIKey
+func NextAPIKey(start, count int) (int, error) {
+	if count <= 0 {
+		return 0, ErrNoHealthyAPIKey
+	}
+	if GlobalAPIKeyUsage == nil {
+		return start % count, nil
+	}
+	err := ErrAPIKeyBudgetExhausted
+	for i := range count {
+		index := (start + i) % count
+		switch GlobalAPIKeyUsage.Available(index) {
+		case nil:
+			return index, nil
+		case ErrNoHealthyAPIKey:
+			// 隔离会在冷却期后结束，比预算更早恢复
+			err = ErrNoHealthyAPIKey
+		}
+	}
+	return 0, err
+}
+
+// APIKeyAvailable 返回是否还有可以分配解析批次的密钥，没有时返回原因
+func APIKeyAvailable() error {
+	_, err := NextAPIKey(0, rpc.GetEnhancedApiClientCount())
+	return err
+}
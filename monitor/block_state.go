@@ -0,0 +1,156 @@
+package monitor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/tracing"
+)
+
+// GlobalBlockStateTracker 全局区块处理状态跟踪
+var GlobalBlockStateTracker *BlockStateTracker
+
+// stuckStates 需要检测是否卡住的状态
+var stuckStates = []models.BlockState{models.BlockFetching, models.BlockParsing}
+
+// BlockStateTracker 在Redis中记录每个区块的处理状态，
+// 定期将卡在FETCHING/PARSING超过阈值的区块重新推入区块队列，超过最大重试次数后标记为FAILED
+type BlockStateTracker struct {
+	threshold  time.Duration
+	interval   time.Duration
+	maxRetries int
+	retention  time.Duration
+	log        *zap.Logger
+	cancel     context.CancelFunc
+}
+
+// NewBlockStateTracker 创建区块处理状态跟踪并设置为全局实例
+func NewBlockStateTracker(config *configs.BlockStateConfig) *BlockStateTracker {
+	tracker := &BlockStateTracker{
+		threshold:  config.StuckThreshold,
+		interval:   config.CheckInterval,
+		maxRetries: config.MaxRetries,
+		retention:  config.Retention,
+		log:        logger.Named("monitor.block_state"),
+	}
+	GlobalBlockStateTracker = tracker
+	return tracker
+}
+
+// SetBlockState 记录区块状态变化，未启用状态跟踪时不做任何处理
+// err 不为nil时作为失败原因记录；启用分布式处理时区块进入终态后同时更新租约
+func SetBlockState(slot uint64, state models.BlockState, err error) {
+	finishSlot(slot, state)
+	tracing.SlotEvent(slot, string(state))
+	switch state {
+	case models.BlockDone, models.BlockOrphaned:
+		tracing.EndSlot(slot, nil)
+	case models.BlockFailed:
+		tracing.EndSlot(slot, cmp.Or(err, errors.New("区块处理失败")))
+	}
+	if GlobalBlockStateTracker != nil {
+		GlobalBlockStateTracker.SetBlockState(slot, state, err)
+	}
+}
+
+// SetBlockState 记录区块状态变化，写入失败只记录日志，不影响区块处理
+func (t *BlockStateTracker) SetBlockState(slot uint64, state models.BlockState, err error) {
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).SetBlockState(ctx, slot, state, reason, t.retention); err != nil {
+		t.log.Warn("记录区块处理状态失败", zap.Uint64("slot", slot), zap.String("state", string(state)), zap.Error(err))
+	}
+}
+
+// Start 按检查间隔检测卡住的区块并清理过期的状态索引
+func (t *BlockStateTracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	supervisor.Go(ctx, "monitor.block_state", func(ctx context.Context) {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				t.check(ctx, now)
+			}
+		}
+	})
+	t.log.Info("区块处理状态跟踪已启动", zap.Duration("stuckThreshold", t.threshold), zap.Int("maxRetries", t.maxRetries))
+}
+
+// Close 停止检测
+func (t *BlockStateTracker) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// Stuck 返回卡在FETCHING/PARSING超过阈值的区块
+func (t *BlockStateTracker) Stuck(ctx context.Context, limit int64) ([]uint64, error) {
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	before := clock.Now().Add(-t.threshold).Unix()
+	var stuck []uint64
+	for _, state := range stuckStates {
+		slots, err := redisClient.GetBlocksInState(ctx, state, before, limit)
+		if err != nil {
+			return nil, err
+		}
+		stuck = append(stuck, slots...)
+	}
+	return stuck, nil
+}
+
+// check 重试卡住的区块，并清理超过保留时长的DONE/FAILED索引
+func (t *BlockStateTracker) check(ctx context.Context, now time.Time) {
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	stuck, err := t.Stuck(ctx, 1000)
+	if err != nil {
+		t.log.Error("查询卡住的区块失败", zap.Error(err))
+		return
+	}
+	for _, slot := range stuck {
+		status, err := redisClient.GetBlockState(ctx, slot)
+		if err != nil {
+			t.log.Warn("读取区块处理状态失败", zap.Uint64("slot", slot), zap.Error(err))
+			continue
+		}
+		if status.Attempts >= t.maxRetries {
+			SetBlockState(slot, models.BlockFailed, fmt.Errorf("在 %s 状态卡住超过 %s，已重试 %d 次", status.State, t.threshold, status.Attempts))
+			t.log.Error("区块处理多次卡住，标记为失败", zap.Uint64("slot", slot), zap.String("state", string(status.State)), zap.Int("attempts", status.Attempts))
+			SkipFailedSlot(slot, fmt.Errorf("在 %s 状态卡住", status.State))
+			continue
+		}
+		t.log.Warn("区块处理卡住，重新推入区块队列", zap.Uint64("slot", slot), zap.String("state", string(status.State)), zap.Int("attempts", status.Attempts))
+		// 卡住的区块可能仍由其他实例持有租约，释放后由任意实例重新获取
+		ReleaseSlot(slot)
+		t.SetBlockState(slot, models.BlockQueued, nil)
+		storage.GlobalBlockQueue.Push(slot, int64(slot))
+	}
+
+	if t.retention > 0 {
+		before := now.Add(-t.retention).Unix()
+		for _, state := range []models.BlockState{models.BlockDone, models.BlockFailed} {
+			if _, err := redisClient.TrimBlockStates(ctx, state, before); err != nil {
+				t.log.Warn("清理区块处理状态失败", zap.String("state", string(state)), zap.Error(err))
+			}
+		}
+	}
+}
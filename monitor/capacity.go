@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalCapacityRecorder 全局容量规划指标快照
+var GlobalCapacityRecorder *CapacityRecorder
+
+// capacityWorkloads 需要统计内存的Redis负载
+var capacityWorkloads = []storage.Workload{storage.WorkloadQueue, storage.WorkloadCache, storage.WorkloadAnalytics}
+
+// CapacityRecorder 定期将吞吐量、延迟、额度消耗和队列峰值等指标保存为快照，用于生成容量报告
+type CapacityRecorder struct {
+	config configs.CapacityConfig
+	last   metrics.Counters
+	lastAt time.Time
+	log    *zap.Logger
+	cancel context.CancelFunc
+}
+
+// NewCapacityRecorder 创建容量规划指标快照并设置为全局实例
+func NewCapacityRecorder(config *configs.CapacityConfig) *CapacityRecorder {
+	recorder := &CapacityRecorder{
+		config: *config,
+		last:   metrics.Snapshot(),
+		lastAt: clock.Now(),
+		log:    logger.Named("monitor.capacity"),
+	}
+	GlobalCapacityRecorder = recorder
+	return recorder
+}
+
+// Start 按快照间隔保存指标快照
+func (c *CapacityRecorder) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	supervisor.Go(ctx, "monitor.capacity", func(ctx context.Context) {
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				c.record(ctx, now)
+			}
+		}
+	})
+	c.log.Info("容量规划指标快照已启动", zap.Duration("interval", c.config.Interval))
+}
+
+// Close 停止保存快照
+func (c *CapacityRecorder) Close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Report 读取最近若干周的快照并生成容量报告
+func (c *CapacityRecorder) Report(ctx context.Context, weeks int) (models.CapacityReport, error) {
+	now := clock.Now()
+	since := now.AddDate(0, 0, -7*weeks).Unix()
+	snapshots, err := storage.GetRedisClient(storage.WorkloadAnalytics).GetCapacitySnapshots(ctx, since, now.Unix())
+	if err != nil {
+		return models.CapacityReport{}, err
+	}
+	return BuildCapacityReport(snapshots, now, c.config.CreditsPerAPIKey), nil
+}
+
+// record 计算与上一次快照之间的增量并保存
+func (c *CapacityRecorder) record(ctx context.Context, now time.Time) {
+	counters := metrics.Snapshot()
+	interval := now.Sub(c.lastAt)
+	snapshot := models.CapacitySnapshot{
+		Timestamp:            now.Unix(),
+		Interval:             int64(interval.Seconds()),
+		Blocks:               counters.Blocks - c.last.Blocks,
+		Transactions:         counters.Transactions - c.last.Transactions,
+		SkippedTransactions:  counters.Skipped - c.last.Skipped,
+		FilteredTransactions: counters.Filtered - c.last.Filtered,
+		RPCRequests:          counters.RPCRequests - c.last.RPCRequests,
+		EnhancedRequests:     counters.EnhancedRequests - c.last.EnhancedRequests,
+	}
+	c.last, c.lastAt = counters, now
+
+	if interval > 0 {
+		snapshot.TransactionsPerSecond = float64(snapshot.Transactions) / interval.Seconds()
+	}
+	lag := metrics.Lag()
+	snapshot.SlotLag, snapshot.SlotLagSeconds = lag.LagSlots, lag.LagSeconds
+	snapshot.Credits = snapshot.RPCRequests*c.config.RPCRequestCredits + snapshot.EnhancedRequests*c.config.EnhancedRequestCredits
+	if storage.GlobalBlockQueue != nil {
+		snapshot.BlockQueuePeak = storage.GlobalBlockQueue.TakePeak()
+	}
+	if storage.GlobalTransactionQueue != nil {
+		snapshot.TransactionQueuePeak = storage.GlobalTransactionQueue.TakePeak()
+	}
+
+	storeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	snapshot.RedisMemory = make(map[string]int64, len(capacityWorkloads))
+	for _, workload := range capacityWorkloads {
+		used, err := storage.GetRedisClient(workload).GetUsedMemory(storeCtx)
+		if err != nil {
+			c.log.Warn("读取Redis内存失败", zap.String("workload", string(workload)), zap.Error(err))
+			continue
+		}
+		snapshot.RedisMemory[string(workload)] = used
+	}
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).StoreCapacitySnapshot(storeCtx, snapshot, c.config.Retention); err != nil {
+		c.log.Error("保存指标快照失败", zap.Error(err))
+	}
+}
+
+// BuildCapacityReport 按ISO周汇总快照，计算每周峰值、总量和相对上一周的增长
+// 每月额度按最近7天快照的消耗速度估算，creditsPerAPIKey 大于0时给出推荐的API密钥数量
+func BuildCapacityReport(snapshots []models.CapacitySnapshot, now time.Time, creditsPerAPIKey int64) models.CapacityReport {
+	report := models.CapacityReport{GeneratedAt: now.Unix(), Weeks: []models.CapacityWeek{}}
+	var recentCredits, recentSeconds int64
+	recentSince := now.AddDate(0, 0, -7).Unix()
+	for _, snapshot := range snapshots {
+		year, week := time.Unix(snapshot.Timestamp, 0).UTC().ISOWeek()
+		name := fmt.Sprintf("%d-W%02d", year, week)
+		if len(report.Weeks) == 0 || report.Weeks[len(report.Weeks)-1].Week != name {
+			report.Weeks = append(report.Weeks, models.CapacityWeek{Week: name})
+		}
+		current := &report.Weeks[len(report.Weeks)-1]
+		current.Snapshots++
+		current.Blocks += snapshot.Blocks
+		current.Transactions += snapshot.Transactions
+		current.SkippedTransactions += snapshot.SkippedTransactions
+		current.FilteredTransactions += snapshot.FilteredTransactions
+		current.Credits += snapshot.Credits
+		current.PeakTransactionsPerSecond = max(current.PeakTransactionsPerSecond, snapshot.TransactionsPerSecond)
+		current.PeakSlotLag = max(current.PeakSlotLag, snapshot.SlotLag)
+		current.PeakSlotLagSeconds = max(current.PeakSlotLagSeconds, snapshot.SlotLagSeconds)
+		current.PeakBlockQueue = max(current.PeakBlockQueue, snapshot.BlockQueuePeak)
+		current.PeakTransactionQueue = max(current.PeakTransactionQueue, snapshot.TransactionQueuePeak)
+		current.PeakRedisMemory = max(current.PeakRedisMemory, sumRedisMemory(snapshot.RedisMemory))
+
+		if snapshot.Timestamp >= recentSince {
+			recentCredits += snapshot.Credits
+			recentSeconds += snapshot.Interval
+		}
+	}
+	for i := 1; i < len(report.Weeks); i++ {
+		previous, current := report.Weeks[i-1], &report.Weeks[i]
+		current.TransactionsGrowth = growth(float64(previous.Transactions), float64(current.Transactions))
+		current.CreditsGrowth = growth(float64(previous.Credits), float64(current.Credits))
+		current.PeakRedisMemoryGrowth = growth(float64(previous.PeakRedisMemory), float64(current.PeakRedisMemory))
+	}
+
+	if recentSeconds > 0 {
+		month := int64((30 * 24 * time.Hour).Seconds())
+		report.MonthlyCreditsEstimate = recentCredits * month / recentSeconds
+	}
+	if creditsPerAPIKey > 0 {
+		report.RecommendedAPIKeys = int(math.Ceil(float64(report.MonthlyCreditsEstimate) / float64(creditsPerAPIKey)))
+	}
+	return report
+}
+
+// sumRedisMemory 各负载Redis已用内存之和，多个负载共用同一实例时只计一次
+func sumRedisMemory(memory map[string]int64) int64 {
+	seen := make(map[*storage.RedisClient]bool, len(memory))
+	var total int64
+	for _, workload := range capacityWorkloads {
+		used, ok := memory[string(workload)]
+		client := storage.GetRedisClient(workload)
+		if !ok || seen[client] {
+			continue
+		}
+		seen[client] = true
+		total += used
+	}
+	return total
+}
+
+// growth 计算相对增长比例，上一周为0时无法计算
+func growth(previous, current float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	value := (current - previous) / previous
+	return &value
+}
@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/storage"
+)
+
+// GlobalCongestionMonitor 全局网络拥堵检测
+var GlobalCongestionMonitor *CongestionMonitor
+
+// blockSample 单个区块的拥堵统计样本
+type blockSample struct {
+	skipped int // 与父区块之间跳过的槽位数
+	total   int // 非投票交易数
+	failed  int // 执行失败的非投票交易数
+}
+
+// CongestionStats 网络拥堵状态
+type CongestionStats struct {
+	Congested       bool      `json:"congested"`         // 是否处于拥堵状态
+	Since           time.Time `json:"since"`             // 进入当前状态的时间
+	Blocks          int       `json:"blocks"`            // 统计窗口内的区块数
+	SkippedSlotRate float64   `json:"skipped_slot_rate"` // 跳过槽位比例
+	FailedTxRate    float64   `json:"failed_tx_rate"`    // 非投票交易失败比例
+	Transitions     int64     `json:"transitions"`       // 状态切换次数
+	CongestedTime   int64     `json:"congested_time"`    // 累计拥堵时长(秒)
+}
+
+// CongestionMonitor 根据区块元数据中的跳过槽位比例和交易失败比例判断Solana网络是否拥堵
+// 拥堵期间放宽队列最大等待时间、拉长Enhanced API请求间隔，恢复后还原
+type CongestionMonitor struct {
+	mu            sync.Mutex
+	config        configs.CongestionConfig
+	samples       []blockSample
+	next          int
+	congested     bool
+	since         time.Time
+	transitions   int64
+	congestedTime time.Duration
+	log           *zap.Logger
+}
+
+// NewCongestionMonitor 创建网络拥堵检测并设置为全局实例
+func NewCongestionMonitor(config *configs.CongestionConfig) *CongestionMonitor {
+	congestion := &CongestionMonitor{
+		config:  *config,
+		samples: make([]blockSample, 0, config.WindowBlocks),
+		since:   clock.Now(),
+		log:     logger.Named("monitor.congestion"),
+	}
+	GlobalCongestionMonitor = congestion
+	return congestion
+}
+
+// RecordBlock 记录区块统计样本，未启用检测时不做任何处理
+// 参数:
+//   - slot: 区块槽位
+//   - parentSlot: 父区块槽位
+//   - total: 非投票交易数
+//   - failed: 执行失败的非投票交易数
+func RecordBlock(slot, parentSlot uint64, total, failed int) {
+	if GlobalCongestionMonitor != nil {
+		GlobalCongestionMonitor.RecordBlock(slot, parentSlot, total, failed)
+	}
+}
+
+// EnhancedAPIInterval 返回当前的Enhanced API请求间隔，拥堵期间按配置放大
+func EnhancedAPIInterval(base time.Duration) time.Duration {
+	if GlobalCongestionMonitor == nil {
+		return base
+	}
+	return GlobalCongestionMonitor.EnhancedAPIInterval(base)
+}
+
+// RecordBlock 记录区块统计样本并重新评估拥堵状态
+func (m *CongestionMonitor) RecordBlock(slot, parentSlot uint64, total, failed int) {
+	sample := blockSample{total: total, failed: failed}
+	if slot > parentSlot+1 {
+		sample.skipped = int(slot - parentSlot - 1)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.samples) < m.config.WindowBlocks {
+		m.samples = append(m.samples, sample)
+	} else {
+		m.samples[m.next] = sample
+		m.next = (m.next + 1) % m.config.WindowBlocks
+	}
+	// 窗口未满时样本太少，不做判断
+	if len(m.samples) < m.config.WindowBlocks {
+		return
+	}
+
+	skippedRate, failedRate := m.rates()
+	switch {
+	case !m.congested && (skippedRate > m.config.SkippedSlotRate || failedRate > m.config.FailedTxRate):
+		m.transition(true, skippedRate, failedRate)
+	case m.congested && skippedRate < m.config.SkippedSlotRate*m.config.RecoveryRatio && failedRate < m.config.FailedTxRate*m.config.RecoveryRatio:
+		m.transition(false, skippedRate, failedRate)
+	}
+}
+
+// EnhancedAPIInterval 返回当前的Enhanced API请求间隔，拥堵期间按配置放大
+func (m *CongestionMonitor) EnhancedAPIInterval(base time.Duration) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.congested {
+		return base
+	}
+	return time.Duration(float64(base) * m.config.EnhancedAPIIntervalMultiplier)
+}
+
+// Stats 返回当前拥堵状态
+func (m *CongestionMonitor) Stats() CongestionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	skippedRate, failedRate := m.rates()
+	congestedTime := m.congestedTime
+	if m.congested {
+		congestedTime += clock.Since(m.since)
+	}
+	return CongestionStats{
+		Congested:       m.congested,
+		Since:           m.since,
+		Blocks:          len(m.samples),
+		SkippedSlotRate: skippedRate,
+		FailedTxRate:    failedRate,
+		Transitions:     m.transitions,
+		CongestedTime:   int64(congestedTime.Seconds()),
+	}
+}
+
+// rates 计算窗口内的跳过槽位比例和交易失败比例，调用方需持有锁
+func (m *CongestionMonitor) rates() (skippedRate, failedRate float64) {
+	var skipped, total, failed int
+	for _, sample := range m.samples {
+		skipped += sample.skipped
+		total += sample.total
+		failed += sample.failed
+	}
+	if slots := skipped + len(m.samples); slots > 0 {
+		skippedRate = float64(skipped) / float64(slots)
+	}
+	if total > 0 {
+		failedRate = float64(failed) / float64(total)
+	}
+	return skippedRate, failedRate
+}
+
+// transition 切换拥堵状态并调整队列最大等待时间，调用方需持有锁
+func (m *CongestionMonitor) transition(congested bool, skippedRate, failedRate float64) {
+	now := clock.Now()
+	if m.congested {
+		m.congestedTime += now.Sub(m.since)
+	}
+	m.congested = congested
+	m.since = now
+	m.transitions++
+
+	queueConfig := configs.GlobalConfig.Queue
+	if congested {
+		multiplier := m.config.LatencyTargetMultiplier
+		storage.SetQueueMaxAge(
+			time.Duration(float64(queueConfig.BlockMaxAge)*multiplier),
+			time.Duration(float64(queueConfig.TransactionMaxAge)*multiplier))
+		m.log.Warn("检测到网络拥堵，放宽延迟目标并降低Enhanced API请求频率",
+			zap.Float64("skippedSlotRate", skippedRate),
+			zap.Float64("failedTxRate", failedRate),
+			zap.Float64("latencyTargetMultiplier", multiplier),
+			zap.Float64("enhancedAPIIntervalMultiplier", m.config.EnhancedAPIIntervalMultiplier))
+		return
+	}
+	storage.SetQueueMaxAge(queueConfig.BlockMaxAge, queueConfig.TransactionMaxAge)
+	m.log.Info("网络拥堵已缓解，恢复正常速率",
+		zap.Float64("skippedSlotRate", skippedRate),
+		zap.Float64("failedTxRate", failedRate))
+}
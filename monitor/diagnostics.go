@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// QueueDiagnostics 单个队列的深度和等待时长
+type QueueDiagnostics struct {
+	Name        string `json:"name"`                   // 队列名称
+	Backend     string `json:"backend"`                // 队列后端: memory, redis-stream, redis
+	Length      int64  `json:"length"`                 // 队列中的元素数
+	OldestAgeMs int64  `json:"oldest_age_ms"`          // 等待最久的元素已等待的时长，不支持时为0
+	StalenessMs int64  `json:"staleness_ms,omitempty"` // 队列非空时距最近一次出队的时长
+	Error       string `json:"error,omitempty"`        // 查询失败的原因
+}
+
+// GoroutineDiagnostics 协程数量和受监管协程的panic统计
+type GoroutineDiagnostics struct {
+	Total      int                      `json:"total"`      // 当前协程总数
+	Supervised []metrics.GoroutineStats `json:"supervised"` // 发生过panic的受监管协程
+}
+
+// Diagnostics 运行时诊断快照，用于在线排查问题
+type Diagnostics struct {
+	Reason        string                          `json:"reason"`                // 触发原因: signal, admin
+	CollectedAt   time.Time                       `json:"collected_at"`          // 采集时间
+	Queues        []QueueDiagnostics              `json:"queues"`                // 队列深度
+	Subscriptions []rpc.WebSocketConnectionStatus `json:"subscriptions"`         // 各WebSocket连接上的订阅
+	PumpPortal    *bool                           `json:"pump_portal,omitempty"` // PumpPortal是否已连接，未启用时为空
+	APIKeys       []APIKeyRateLimit               `json:"api_keys"`              // 各Enhanced API密钥的限流状态
+	Goroutines    GoroutineDiagnostics            `json:"goroutines"`            // 协程统计
+	RecentErrors  []logger.ErrorEntry             `json:"recent_errors"`         // 最近的错误日志，最新的在前
+}
+
+// CollectDiagnostics 采集运行时诊断快照，尚未初始化的组件不包含在快照中
+// 参数:
+//   - ctx: 上下文，用于查询Redis中的队列
+//   - reason: 触发原因
+//
+// 返回:
+//   - Diagnostics: 诊断快照
+func CollectDiagnostics(ctx context.Context, reason string) Diagnostics {
+	diagnostics := Diagnostics{
+		Reason:      reason,
+		CollectedAt: clock.Now(),
+		Queues:      collectQueueDiagnostics(ctx),
+		Goroutines: GoroutineDiagnostics{
+			Total:      runtime.NumGoroutine(),
+			Supervised: metrics.Goroutines(),
+		},
+		RecentErrors: logger.RecentErrors(),
+	}
+	if rpc.GlobalWebSocketPool != nil {
+		diagnostics.Subscriptions = rpc.GlobalWebSocketPool.Status()
+	}
+	if rpc.GlobalPumpPortalClient != nil {
+		connected := rpc.GlobalPumpPortalClient.IsConnected()
+		diagnostics.PumpPortal = &connected
+	}
+	if GlobalAPIKeyUsage != nil {
+		diagnostics.APIKeys = GlobalAPIKeyUsage.RateLimits()
+	}
+	return diagnostics
+}
+
+// collectQueueDiagnostics 采集区块队列、交易队列和死信队列的深度
+func collectQueueDiagnostics(ctx context.Context) []QueueDiagnostics {
+	var queues []QueueDiagnostics
+	if storage.GlobalBlockQueue != nil {
+		queues = append(queues, QueueDiagnostics{
+			Name:        "block",
+			Backend:     storage.QueueBackendMemory,
+			Length:      int64(storage.GlobalBlockQueue.Len()),
+			OldestAgeMs: storage.GlobalBlockQueue.OldestAge().Milliseconds(),
+			StalenessMs: storage.GlobalBlockQueue.Staleness().Milliseconds(),
+		})
+	}
+	if storage.GlobalTransactionStream != nil {
+		queue := QueueDiagnostics{
+			Name:    "transaction",
+			Backend: storage.QueueBackendRedisStream,
+			Length:  int64(storage.GlobalTransactionStream.TransactionQueueLen()),
+		}
+		if age, err := storage.GlobalTransactionStream.OldestAge(ctx); err != nil {
+			queue.Error = err.Error()
+		} else {
+			queue.OldestAgeMs = age.Milliseconds()
+		}
+		queues = append(queues, queue)
+	} else if storage.GlobalTransactionQueue != nil {
+		queues = append(queues, QueueDiagnostics{
+			Name:        "transaction",
+			Backend:     storage.QueueBackendMemory,
+			Length:      int64(storage.GlobalTransactionQueue.Len()),
+			OldestAgeMs: storage.GlobalTransactionQueue.OldestAge().Milliseconds(),
+			StalenessMs: storage.GlobalTransactionQueue.Staleness().Milliseconds(),
+		})
+	}
+	for _, name := range []string{"block", "transaction"} {
+		queue := QueueDiagnostics{Name: "dead_letter:" + name, Backend: "redis"}
+		length, err := storage.GetRedisClient(storage.WorkloadQueue).GetDeadLetterLength(ctx, name)
+		if err != nil {
+			queue.Error = err.Error()
+		}
+		queue.Length = length
+		queues = append(queues, queue)
+	}
+	return queues
+}
+
+// DumpDiagnostics 采集诊断快照并以结构化字段写入日志，使用warn级别，日志级别设为warn时也会写入
+// 参数:
+//   - ctx: 上下文，用于查询Redis中的队列
+//   - reason: 触发原因
+//
+// 返回:
+//   - Diagnostics: 写入日志的诊断快照
+func DumpDiagnostics(ctx context.Context, reason string) Diagnostics {
+	diagnostics := CollectDiagnostics(ctx, reason)
+	logger.Named("monitor.diagnostics").Warn("运行时诊断快照",
+		zap.String("reason", diagnostics.Reason),
+		zap.Any("queues", diagnostics.Queues),
+		zap.Any("subscriptions", diagnostics.Subscriptions),
+		zap.Any("pump_portal", diagnostics.PumpPortal),
+		zap.Any("api_keys", diagnostics.APIKeys),
+		zap.Any("goroutines", diagnostics.Goroutines),
+		zap.Any("recent_errors", diagnostics.RecentErrors))
+	return diagnostics
+}
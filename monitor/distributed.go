@@ -0,0 +1,235 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalCoordinator 全局分布式处理协调器，未启用分布式处理时为nil
+var GlobalCoordinator *Coordinator
+
+// Coordinator 多个实例共用区块来源时，通过Redis租约保证同一区块只由一个实例处理
+// 每个实例定期心跳并为持有的租约续期，超过 instance_ttl 没有心跳的实例视为失效，
+// 其持有、尚未处理完成的区块由最先发现的实例回收并推入自己的区块队列
+type Coordinator struct {
+	instance    string
+	leaseTTL    time.Duration
+	doneTTL     time.Duration
+	interval    time.Duration
+	instanceTTL time.Duration
+	acquired    atomic.Int64
+	skipped     atomic.Int64
+	reclaimed   atomic.Int64
+	log         *zap.Logger
+	cancel      context.CancelFunc
+}
+
+// NewCoordinator 创建分布式处理协调器并设置为全局实例
+func NewCoordinator(config *configs.DistributedConfig) *Coordinator {
+	instance := instanceID(config)
+	coordinator := &Coordinator{
+		instance:    instance,
+		leaseTTL:    config.LeaseTTL,
+		doneTTL:     config.DoneTTL,
+		interval:    config.HeartbeatInterval,
+		instanceTTL: config.InstanceTTL,
+		log:         logger.Named("monitor.distributed").With(zap.String("instance", instance)),
+	}
+	GlobalCoordinator = coordinator
+	return coordinator
+}
+
+// instanceID 返回配置的实例ID，未配置时使用 主机名-进程号
+func instanceID(config *configs.DistributedConfig) string {
+	if config.InstanceID != "" {
+		return config.InstanceID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "datas-go"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// InstanceID 按分布式处理配置返回当前实例ID，未启用分布式处理时同样可用
+func InstanceID() string {
+	return instanceID(&configs.GlobalConfig.Distributed)
+}
+
+// InstanceID 返回当前实例ID
+func (c *Coordinator) InstanceID() string {
+	return c.instance
+}
+
+// Start 登记心跳，之后按心跳间隔续期租约并回收失效实例的区块
+func (c *Coordinator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.heartbeat(ctx)
+	supervisor.Go(ctx, "monitor.distributed", func(ctx context.Context) {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.heartbeat(ctx)
+				c.reclaim(ctx)
+			}
+		}
+	})
+	c.log.Info("分布式处理已启动", zap.Duration("leaseTTL", c.leaseTTL), zap.Duration("instanceTTL", c.instanceTTL))
+}
+
+// Close 停止心跳并标记实例已退出，其他实例下一次检查时立即回收本实例尚未处理完成的区块
+func (c *Coordinator) Close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).MarkInstanceDown(ctx, c.instance); err != nil {
+		c.log.Warn("标记实例退出失败，持有的区块在 instance_ttl 之后由其他实例回收", zap.Error(err))
+	}
+}
+
+// heartbeat 记录心跳并为持有的租约续期
+func (c *Coordinator) heartbeat(ctx context.Context) {
+	if _, err := storage.GetRedisClient(storage.WorkloadQueue).HeartbeatInstance(ctx, c.instance, clock.Now(), c.leaseTTL); err != nil {
+		c.log.Warn("记录实例心跳失败", zap.Error(err))
+	}
+}
+
+// reclaim 回收失效实例持有的区块，推入本实例的区块队列重新处理
+func (c *Coordinator) reclaim(ctx context.Context) {
+	reclaimed, err := storage.GetRedisClient(storage.WorkloadQueue).ReclaimDeadInstances(ctx, clock.Now().Add(-c.instanceTTL))
+	if err != nil {
+		c.log.Warn("回收失效实例的区块失败", zap.Error(err))
+	}
+	for instance, slots := range reclaimed {
+		c.log.Warn("实例失效，回收其未处理完成的区块", zap.String("dead", instance), zap.Int("blocks", len(slots)))
+		for _, slot := range slots {
+			SetBlockState(slot, models.BlockQueued, nil)
+			storage.GlobalBlockQueue.Push(slot, int64(slot))
+		}
+		c.reclaimed.Add(int64(len(slots)))
+	}
+}
+
+// Acquire 获取区块租约，Redis不可用时按获取成功处理，宁可重复处理也不丢失区块
+func (c *Coordinator) Acquire(slot uint64) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	acquired, err := storage.GetRedisClient(storage.WorkloadQueue).AcquireSlotLease(ctx, slot, c.instance, c.leaseTTL)
+	if err != nil {
+		c.log.Warn("获取区块租约失败，按本实例持有处理", zap.Uint64("slot", slot), zap.Error(err))
+		acquired = true
+	}
+	if acquired {
+		c.acquired.Add(1)
+	} else {
+		c.skipped.Add(1)
+		c.log.Debug("区块已由其他实例处理，跳过", zap.Uint64("slot", slot))
+	}
+	return acquired
+}
+
+// Complete 区块处理完成，保留完成标记，done_ttl 内其他实例收到同一槽位时直接跳过
+func (c *Coordinator) Complete(slot uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).CompleteSlotLease(ctx, slot, c.doneTTL); err != nil {
+		c.log.Warn("完成区块租约失败", zap.Uint64("slot", slot), zap.Error(err))
+	}
+}
+
+// Release 释放区块租约，之后任意实例都可以重新处理该区块
+func (c *Coordinator) Release(slot uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).ReleaseSlotLease(ctx, slot); err != nil {
+		c.log.Warn("释放区块租约失败", zap.Uint64("slot", slot), zap.Error(err))
+	}
+}
+
+// Reset 删除槽位范围内的完成标记，显式重新入队的区块在本实例或其他实例上都可以重新处理
+func (c *Coordinator) Reset(from, to uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).ClearSlotDone(ctx, from, to); err != nil {
+		c.log.Warn("清除区块完成标记失败，done_ttl 内已处理完成的区块会被跳过",
+			zap.Uint64("from", from), zap.Uint64("to", to), zap.Error(err))
+	}
+}
+
+// Status 返回所有实例的心跳和当前实例的计数
+func (c *Coordinator) Status(ctx context.Context) (models.ClusterStatus, error) {
+	instances, err := storage.GetRedisClient(storage.WorkloadQueue).ListInstances(ctx, clock.Now().Add(-c.instanceTTL))
+	if err != nil {
+		return models.ClusterStatus{}, err
+	}
+	for i := range instances {
+		instances[i].Self = instances[i].ID == c.instance
+	}
+	return models.ClusterStatus{
+		Instance:  c.instance,
+		Instances: instances,
+		Acquired:  c.acquired.Load(),
+		Skipped:   c.skipped.Load(),
+		Reclaimed: c.reclaimed.Load(),
+	}, nil
+}
+
+// AcquireSlot 在开始处理区块前获取租约，返回false时区块已由其他实例持有或处理完成，应跳过
+// 未启用分布式处理时总是返回true
+func AcquireSlot(slot uint64) bool {
+	if GlobalCoordinator == nil {
+		return true
+	}
+	return GlobalCoordinator.Acquire(slot)
+}
+
+// CompleteSlot 区块交由共享的交易队列(queue.backend: redis-stream)继续处理时提前完成租约，
+// 之后由消费者组负责重新分配，避免实例失效时区块被重新获取、交易重复入队
+func CompleteSlot(slot uint64) {
+	if GlobalCoordinator != nil {
+		GlobalCoordinator.Complete(slot)
+	}
+}
+
+// ReleaseSlot 释放区块租约，用于需要由任意实例重新处理的区块
+func ReleaseSlot(slot uint64) {
+	if GlobalCoordinator != nil {
+		GlobalCoordinator.Release(slot)
+	}
+}
+
+// ResetSlots 显式重新入队(如回补、停止排空)前删除槽位范围内的完成标记，未启用分布式处理时不做任何处理
+func ResetSlots(from, to uint64) {
+	if GlobalCoordinator != nil {
+		GlobalCoordinator.Reset(from, to)
+	}
+}
+
+// finishSlot 区块进入终态时更新租约：DONE/ORPHANED 保留完成标记，FAILED 释放租约以便重试
+func finishSlot(slot uint64, state models.BlockState) {
+	switch state {
+	case models.BlockDone, models.BlockOrphaned:
+		CompleteSlot(slot)
+	case models.BlockFailed:
+		ReleaseSlot(slot)
+	}
+}
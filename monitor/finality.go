@@ -0,0 +1,297 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/req"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalFinalityChecker 全局最终确认检查
+var GlobalFinalityChecker *FinalityChecker
+
+// orphanedRetention 已判定为未确认的槽位在内存中的保留时长，期间解析完成的交易会立即删除
+const orphanedRetention = 10 * time.Minute
+
+// pendingSlot 等待最终确认的槽位
+type pendingSlot struct {
+	slot       uint64
+	blockhash  string
+	blockTime  int64
+	signatures []string
+	indexed    []models.IndexedTransaction
+	observedAt time.Time
+	attempts   int
+}
+
+// FinalityChecker 检查以 confirmed/processed 承诺级别处理的槽位是否被最终确认
+// 槽位处理完成 delay 之后以 finalized 调用getBlock：槽位被跳过或区块哈希不一致时，删除该槽位交易的缓存、原始响应和来源/类型索引，
+// 将区块状态标记为ORPHANED，记录到 solana:orphaned:slots 并发布 orphaned 事件，由下游统计自行回滚或标记
+type FinalityChecker struct {
+	mu          sync.Mutex
+	pending     map[uint64]*pendingSlot
+	orphaned    map[uint64]time.Time // 最近判定为未确认的槽位
+	delay       time.Duration
+	interval    time.Duration
+	maxAttempts int
+	maxPending  int
+	maxHistory  int64
+	log         *zap.Logger
+	cancel      context.CancelFunc
+}
+
+// NewFinalityChecker 创建最终确认检查并设置为全局实例
+func NewFinalityChecker(config *configs.FinalityConfig) *FinalityChecker {
+	checker := &FinalityChecker{
+		pending:     make(map[uint64]*pendingSlot),
+		orphaned:    make(map[uint64]time.Time),
+		delay:       config.Delay,
+		interval:    config.CheckInterval,
+		maxAttempts: config.MaxAttempts,
+		maxPending:  config.MaxPending,
+		maxHistory:  config.MaxHistory,
+		log:         logger.Named("monitor.finality"),
+	}
+	GlobalFinalityChecker = checker
+	return checker
+}
+
+// TrackFinality 记录已处理、尚未最终确认的槽位，未启用检查时不做任何处理
+func TrackFinality(slot uint64, blockhash string, blockTime int64, signatures []string) {
+	if GlobalFinalityChecker != nil {
+		GlobalFinalityChecker.Track(slot, blockhash, blockTime, signatures)
+	}
+}
+
+// Track 记录已处理、尚未最终确认的槽位，等待检查的槽位超过上限时丢弃槽位最小的
+func (c *FinalityChecker) Track(slot uint64, blockhash string, blockTime int64, signatures []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) >= c.maxPending {
+		var oldest uint64
+		for candidate := range c.pending {
+			if oldest == 0 || candidate < oldest {
+				oldest = candidate
+			}
+		}
+		delete(c.pending, oldest)
+		c.log.Warn("等待最终确认的槽位过多，丢弃最早的槽位", zap.Uint64("slot", oldest))
+	}
+	c.pending[slot] = &pendingSlot{
+		slot:       slot,
+		blockhash:  blockhash,
+		blockTime:  blockTime,
+		signatures: signatures,
+		observedAt: clock.Now(),
+	}
+}
+
+// Start 订阅解析交易以记录各槽位的索引，并按检查间隔检查到期的槽位
+func (c *FinalityChecker) Start(p *pipeline.Pipeline) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{
+		Types: []pipeline.EventType{pipeline.EventTransaction},
+	})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "monitor.finality", func(ctx context.Context) {
+			ticker := time.NewTicker(c.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					c.recordTransaction(ctx, event)
+				case now := <-ticker.C:
+					c.check(ctx, now)
+				}
+			}
+		})
+	}()
+	c.log.Info("最终确认检查已启动", zap.Duration("delay", c.delay))
+}
+
+// Close 停止检查
+func (c *FinalityChecker) Close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// recordTransaction 记录等待确认的槽位中已索引的交易；槽位已判定为未确认时立即删除该交易
+func (c *FinalityChecker) recordTransaction(ctx context.Context, event pipeline.Event) {
+	if event.Transaction == nil {
+		return
+	}
+	indexed := models.IndexedTransaction{
+		Signature: event.Transaction.Signature,
+		Source:    string(event.Transaction.Source),
+		Type:      string(event.Transaction.Type),
+		Slot:      event.Transaction.Slot,
+		Timestamp: event.Transaction.Timestamp,
+		Mints:     event.Transaction.Mints(),
+	}
+	c.mu.Lock()
+	if pending, ok := c.pending[event.Slot]; ok {
+		pending.indexed = append(pending.indexed, indexed)
+		c.mu.Unlock()
+		return
+	}
+	_, orphaned := c.orphaned[event.Slot]
+	c.mu.Unlock()
+	if !orphaned {
+		return
+	}
+
+	removeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadCache).RemoveSlotTransactions(removeCtx, []string{indexed.Signature}, nil); err != nil {
+		c.log.Warn("删除未确认槽位的交易失败", zap.Uint64("slot", event.Slot), zap.Error(err))
+	}
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).RemoveSlotTransactions(removeCtx, nil, []models.IndexedTransaction{indexed}); err != nil {
+		c.log.Warn("删除未确认槽位的交易索引失败", zap.Uint64("slot", event.Slot), zap.Error(err))
+	}
+}
+
+// check 检查到期的槽位
+func (c *FinalityChecker) check(ctx context.Context, now time.Time) {
+	c.mu.Lock()
+	var due []*pendingSlot
+	for _, pending := range c.pending {
+		if now.Sub(pending.observedAt) >= c.delay {
+			due = append(due, pending)
+		}
+	}
+	for slot, orphanedAt := range c.orphaned {
+		if now.Sub(orphanedAt) > orphanedRetention {
+			delete(c.orphaned, slot)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, pending := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		finalized, reason, err := c.checkSlot(ctx, pending)
+		if err != nil {
+			pending.attempts++
+			if pending.attempts < c.maxAttempts {
+				continue
+			}
+			c.log.Warn("多次检查最终确认失败，放弃检查", zap.Uint64("slot", pending.slot), zap.Error(err))
+		}
+		c.mu.Lock()
+		delete(c.pending, pending.slot)
+		if err == nil && !finalized {
+			c.orphaned[pending.slot] = now
+		}
+		c.mu.Unlock()
+		if err == nil && !finalized {
+			c.orphan(ctx, pending, reason)
+		}
+	}
+}
+
+// checkSlot 以 finalized 获取区块，返回槽位是否被最终确认，以及未确认时的原因
+func (c *FinalityChecker) checkSlot(ctx context.Context, pending *pendingSlot) (bool, string, error) {
+	rewards := false
+	requestCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	result, err := rpc.GlobalHeliusClient.GetBlock(requestCtx, pending.slot, &req.GetBlockParams{
+		Encoding:                       "json",
+		TransactionDetails:             "none",
+		Rewards:                        &rewards,
+		MaxSupportedTransactionVersion: 0,
+		Commitment:                     "finalized",
+	})
+	if errors.Is(err, rpc.ErrSlotSkipped) {
+		return false, "槽位被跳过", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	var block struct {
+		Blockhash string `json:"blockhash"`
+	}
+	if err := json.Unmarshal(result, &block); err != nil || block.Blockhash == "" {
+		return false, "", errors.New("区块尚未最终确认")
+	}
+	if pending.blockhash != "" && block.Blockhash != pending.blockhash {
+		return false, "区块哈希与最终确认的区块不一致", nil
+	}
+	return true, "", nil
+}
+
+// orphan 删除未确认槽位的数据并通知下游
+func (c *FinalityChecker) orphan(ctx context.Context, pending *pendingSlot, reason string) {
+	c.log.Warn("已处理的槽位没有被最终确认，删除该槽位的数据",
+		zap.Uint64("slot", pending.slot),
+		zap.String("reason", reason),
+		zap.Int("signatures", len(pending.signatures)),
+		zap.Int("transactions", len(pending.indexed)))
+
+	storeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadCache).RemoveSlotTransactions(storeCtx, pending.signatures, nil); err != nil {
+		c.log.Error("删除未确认槽位的交易缓存失败", zap.Uint64("slot", pending.slot), zap.Error(err))
+	}
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).RemoveSlotTransactions(storeCtx, nil, pending.indexed); err != nil {
+		c.log.Error("删除未确认槽位的交易索引失败", zap.Uint64("slot", pending.slot), zap.Error(err))
+	}
+	orphaned := models.OrphanedSlot{
+		Slot:         pending.slot,
+		Blockhash:    pending.blockhash,
+		BlockTime:    pending.blockTime,
+		Reason:       reason,
+		Signatures:   len(pending.signatures),
+		Transactions: len(pending.indexed),
+		Mints:        indexedMints(pending.indexed),
+		DetectedAt:   clock.Now().Unix(),
+	}
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).DeleteBlockMeta(storeCtx, pending.blockhash); err != nil {
+		c.log.Error("删除未确认区块的哈希索引失败", zap.Uint64("slot", pending.slot), zap.Error(err))
+	}
+	if err := storage.GetRedisClient(storage.WorkloadAnalytics).RecordOrphanedSlot(storeCtx, orphaned, c.maxHistory); err != nil {
+		c.log.Error("记录未确认的槽位失败", zap.Uint64("slot", pending.slot), zap.Error(err))
+	}
+	SetBlockState(pending.slot, models.BlockOrphaned, errors.New(reason))
+	pipeline.Publish(pipeline.Event{
+		Type:       pipeline.EventOrphaned,
+		Slot:       pending.slot,
+		Signatures: pending.signatures,
+	})
+}
+
+// indexedMints 返回已索引交易涉及的代币，按出现顺序去重
+func indexedMints(indexed []models.IndexedTransaction) []string {
+	var mints []string
+	for _, transaction := range indexed {
+		for _, mint := range transaction.Mints {
+			if !slices.Contains(mints, mint) {
+				mints = append(mints, mint)
+			}
+		}
+	}
+	return mints
+}
@@ -0,0 +1,201 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalLeaderElector 全局WebSocket摄取领导者选举，未启用时为nil
+var GlobalLeaderElector *LeaderElector
+
+// LeaderElector 通过Redis中带租期的键选举WebSocket摄取的领导者
+// 领导者每隔 renew_interval 续期，备用实例以相同间隔竞选；领导者在租期内没有续期成功时自行降级，
+// 因此同一时间最多只有一个实例认为自己是领导者
+type LeaderElector struct {
+	mu         sync.Mutex
+	instance   string
+	ttl        time.Duration
+	interval   time.Duration
+	leader     bool
+	leaseUntil time.Time // 最近一次续期成功后租期的到期时间
+	since      time.Time
+	elected    int64
+	lastError  string
+	onElected  func(ctx context.Context)
+	onDemoted  func()
+	electedCtx context.CancelFunc // 成为领导者时创建，降级时取消，用于中止仍在进行的订阅
+	log        *zap.Logger
+	cancel     context.CancelFunc
+}
+
+// NewLeaderElector 创建领导者选举并设置为全局实例
+// 参数:
+//   - config: 分布式处理配置，使用其中的实例ID和领导者选举配置
+//   - onElected: 成为领导者后调用，在独立的协程中执行，ctx 在降级时取消
+//   - onDemoted: 失去领导者身份后调用
+func NewLeaderElector(config *configs.DistributedConfig, onElected func(ctx context.Context), onDemoted func()) *LeaderElector {
+	instance := instanceID(config)
+	elector := &LeaderElector{
+		instance:  instance,
+		ttl:       config.LeaderElection.TTL,
+		interval:  config.LeaderElection.RenewInterval,
+		onElected: onElected,
+		onDemoted: onDemoted,
+		log:       logger.Named("monitor.leader").With(zap.String("instance", instance)),
+	}
+	GlobalLeaderElector = elector
+	return elector
+}
+
+// Start 立即竞选一次，之后按间隔续期或竞选
+func (e *LeaderElector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.campaign(ctx)
+	supervisor.Go(ctx, "monitor.leader", func(ctx context.Context) {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.campaign(ctx)
+			}
+		}
+	})
+	e.log.Info("摄取领导者选举已启动", zap.Duration("ttl", e.ttl), zap.Duration("renewInterval", e.interval))
+}
+
+// Close 停止选举，是领导者时降级并放弃领导者身份，备用实例下一次竞选时立即接管
+func (e *LeaderElector) Close() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.mu.Lock()
+	leader := e.leader
+	e.mu.Unlock()
+	if !leader {
+		return
+	}
+	e.demote("实例退出")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.GetRedisClient(storage.WorkloadQueue).ResignIngestLeader(ctx, e.instance); err != nil {
+		e.log.Warn("放弃摄取领导者失败，备用实例在租期到期后接管", zap.Error(err))
+	}
+}
+
+// campaign 竞选或续期，身份变化时调用回调
+func (e *LeaderElector) campaign(ctx context.Context) {
+	now := clock.Now()
+	requestCtx, cancel := context.WithTimeout(ctx, e.interval)
+	leader, err := storage.GetRedisClient(storage.WorkloadQueue).AcquireIngestLeader(requestCtx, e.instance, e.ttl)
+	cancel()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	if err != nil {
+		e.lastError = err.Error()
+		// 无法确认是否续期成功，租期内仍视为领导者，到期后降级，避免与接管的实例同时摄取
+		expired := wasLeader && !now.Before(e.leaseUntil)
+		e.mu.Unlock()
+		e.log.Warn("竞选摄取领导者失败", zap.Bool("leader", wasLeader), zap.Error(err))
+		if expired {
+			e.demote("租期内未能续期")
+		}
+		return
+	}
+	e.lastError = ""
+	if leader {
+		e.leaseUntil = now.Add(e.ttl)
+	}
+	e.mu.Unlock()
+
+	switch {
+	case leader && !wasLeader:
+		e.elect(now)
+	case !leader && wasLeader:
+		e.demote("领导者已由其他实例持有")
+	}
+}
+
+// elect 成为领导者并在独立的协程中开始摄取
+func (e *LeaderElector) elect(now time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.mu.Lock()
+	e.leader = true
+	e.since = now
+	e.elected++
+	e.electedCtx = cancel
+	e.mu.Unlock()
+	e.log.Info("成为摄取领导者，开始订阅")
+	if e.onElected != nil {
+		supervisor.Go(ctx, "monitor.leader.elected", e.onElected)
+	}
+}
+
+// demote 失去领导者身份并停止摄取
+func (e *LeaderElector) demote(reason string) {
+	e.mu.Lock()
+	if !e.leader {
+		e.mu.Unlock()
+		return
+	}
+	e.leader = false
+	e.since = time.Time{}
+	if e.electedCtx != nil {
+		e.electedCtx()
+		e.electedCtx = nil
+	}
+	e.mu.Unlock()
+	e.log.Warn("失去摄取领导者身份，停止订阅", zap.String("reason", reason))
+	if e.onDemoted != nil {
+		e.onDemoted()
+	}
+}
+
+// IsLeader 返回当前实例是否为领导者且租期未过期
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader && clock.Now().Before(e.leaseUntil)
+}
+
+// Status 返回领导者选举状态
+func (e *LeaderElector) Status(ctx context.Context) models.LeaderStatus {
+	e.mu.Lock()
+	status := models.LeaderStatus{
+		Instance: e.instance,
+		IsLeader: e.leader && clock.Now().Before(e.leaseUntil),
+		Since:    e.since,
+		Elected:  e.elected,
+		Error:    e.lastError,
+	}
+	e.mu.Unlock()
+	leader, err := storage.GetRedisClient(storage.WorkloadQueue).GetIngestLeader(ctx)
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.Leader = leader
+	return status
+}
+
+// IsIngestLeader 返回当前实例是否负责WebSocket摄取，未启用领导者选举时总是返回true
+// 备用实例在降级后仍可能收到尚未取消的订阅推送的通知，处理前需要检查
+func IsIngestLeader() bool {
+	if GlobalLeaderElector == nil {
+		return true
+	}
+	return GlobalLeaderElector.IsLeader()
+}
@@ -0,0 +1,220 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// ErrSkippedSlotNotFound 槽位不在跳过列表中
+var ErrSkippedSlotNotFound = errors.New("槽位不在跳过列表中")
+
+// GlobalSlotSkipList 全局跳过列表，未启用时为nil
+var GlobalSlotSkipList *SlotSkipList
+
+// SlotSkipList 区块获取重试用尽的槽位加入Redis中的跳过列表并记录最后的错误，
+// 按逐渐变长的间隔重新推入区块队列，处理成功后移出；达到最大重试次数后保留在列表中等待人工排查
+type SlotSkipList struct {
+	config configs.SkippedSlotsConfig
+	blocks storage.BlockStore // 重试的槽位推入的区块队列，与处理器使用的队列相同
+	log    *zap.Logger
+	cancel context.CancelFunc
+}
+
+// NewSlotSkipList 创建跳过列表并设置为全局实例
+// 参数:
+//   - config: 跳过列表配置
+//   - blocks: 处理器的区块队列，重试的槽位推入其中
+func NewSlotSkipList(config *configs.SkippedSlotsConfig, blocks storage.BlockStore) *SlotSkipList {
+	skipList := &SlotSkipList{
+		config: *config,
+		blocks: blocks,
+		log:    logger.Named("monitor.skipped_slots"),
+	}
+	GlobalSlotSkipList = skipList
+	return skipList
+}
+
+// SkipFailedSlot 区块获取或处理多次失败后将槽位加入跳过列表，未启用时不做任何处理
+func SkipFailedSlot(slot uint64, err error) {
+	if GlobalSlotSkipList != nil {
+		GlobalSlotSkipList.Skip(slot, err)
+	}
+}
+
+// ResolveSkippedSlot 区块处理完成或确认没有出块时调用，重试成功的槽位移出跳过列表
+func ResolveSkippedSlot(slot uint64) {
+	if GlobalSlotSkipList != nil {
+		GlobalSlotSkipList.Resolve(slot)
+	}
+}
+
+// Start 按检查间隔重新推入重试时间已到的槽位
+func (l *SlotSkipList) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	supervisor.Go(ctx, "monitor.skipped_slots", func(ctx context.Context) {
+		ticker := time.NewTicker(l.config.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				l.check(ctx, now)
+			}
+		}
+	})
+	l.log.Info("跳过列表已启动", zap.Duration("retryInterval", l.config.RetryInterval), zap.Int("maxAttempts", l.config.MaxAttempts))
+}
+
+// Close 停止重试
+func (l *SlotSkipList) Close() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
+
+// Skip 将槽位加入跳过列表，已在列表中时累加失败次数并按翻倍的间隔安排下一次重试
+func (l *SlotSkipList) Skip(slot uint64, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	skipped, err := redisClient.GetSkippedSlot(ctx, slot)
+	if err != nil {
+		l.log.Warn("读取跳过的槽位失败", zap.Uint64("slot", slot), zap.Error(err))
+		return
+	}
+	now := clock.Now()
+	if skipped == nil {
+		skipped = &models.SkippedSlot{Slot: slot, FirstFailedAt: now}
+	}
+	skipped.Attempts++
+	skipped.LastFailedAt = now
+	if cause != nil {
+		skipped.LastError = cause.Error()
+	}
+	if l.config.MaxAttempts > 0 && skipped.Attempts >= l.config.MaxAttempts {
+		skipped.Abandoned = true
+		skipped.NextRetryAt = nil
+		metrics.IncAbandonedSlots()
+		l.log.Error("槽位多次重试仍然失败，不再自动重试，请人工排查",
+			zap.Uint64("slot", slot), zap.Int("attempts", skipped.Attempts), zap.String("lastError", skipped.LastError))
+	} else {
+		next := now.Add(l.backoff(skipped.Attempts))
+		skipped.NextRetryAt = &next
+		l.log.Warn("槽位获取失败，加入跳过列表稍后重试",
+			zap.Uint64("slot", slot), zap.Int("attempts", skipped.Attempts), zap.Time("nextRetryAt", next), zap.String("lastError", skipped.LastError))
+	}
+	metrics.IncSkippedSlots()
+	if err := redisClient.SaveSkippedSlot(ctx, *skipped); err != nil {
+		l.log.Warn("写入跳过列表失败", zap.Uint64("slot", slot), zap.Error(err))
+	}
+}
+
+// backoff 返回第 attempts 次失败后的重试间隔，从 retry_interval 开始翻倍，不超过 max_retry_interval
+func (l *SlotSkipList) backoff(attempts int) time.Duration {
+	interval := l.config.RetryInterval
+	for i := 1; i < attempts && interval < l.config.MaxRetryInterval; i++ {
+		interval *= 2
+	}
+	return min(interval, l.config.MaxRetryInterval)
+}
+
+// Resolve 槽位处理成功时移出跳过列表
+// 槽位可能由其他实例加入跳过列表，或在本实例重启前加入，因此总是在Redis中删除
+func (l *SlotSkipList) Resolve(slot uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	deleted, err := storage.GetRedisClient(storage.WorkloadQueue).DeleteSkippedSlot(ctx, slot)
+	if err != nil {
+		l.log.Warn("从跳过列表中删除槽位失败", zap.Uint64("slot", slot), zap.Error(err))
+		return
+	}
+	if deleted {
+		metrics.IncRecoveredSlots()
+		l.log.Info("跳过的槽位重试成功，已移出跳过列表", zap.Uint64("slot", slot))
+	}
+}
+
+// check 重新推入重试时间已到的槽位
+func (l *SlotSkipList) check(ctx context.Context, now time.Time) {
+	slots, err := storage.GetRedisClient(storage.WorkloadQueue).DueSkippedSlots(ctx, now, 100)
+	if err != nil {
+		l.log.Error("查询需要重试的槽位失败", zap.Error(err))
+		return
+	}
+	for _, slot := range slots {
+		if err := l.retry(ctx, slot); err != nil {
+			l.log.Warn("重试跳过的槽位失败", zap.Uint64("slot", slot), zap.Error(err))
+		}
+	}
+}
+
+// Retry 立即重试跳过列表中的槽位，包括已放弃自动重试的槽位
+// 返回:
+//   - error: 槽位不在跳过列表中时返回 ErrSkippedSlotNotFound
+func (l *SlotSkipList) Retry(ctx context.Context, slot uint64) error {
+	skipped, err := storage.GetRedisClient(storage.WorkloadQueue).GetSkippedSlot(ctx, slot)
+	if err != nil {
+		return err
+	}
+	if skipped == nil {
+		return fmt.Errorf("%w: %d", ErrSkippedSlotNotFound, slot)
+	}
+	return l.retry(ctx, slot)
+}
+
+// retry 将槽位重新推入区块队列
+// 在结果出来之前把下一次重试推迟到 max_retry_interval 之后，避免重复入队；再次失败时由 Skip 重新安排，
+// 处理成功时由 Resolve 移出，进程在此期间退出时按推迟的时间再次重试
+func (l *SlotSkipList) retry(ctx context.Context, slot uint64) error {
+	if l.blocks == nil {
+		return errors.New("区块队列尚未初始化")
+	}
+	if err := storage.GetRedisClient(storage.WorkloadQueue).ScheduleSkippedSlot(ctx, slot, clock.Now().Add(l.config.MaxRetryInterval)); err != nil {
+		return err
+	}
+
+	// 失败的区块可能仍保留其他实例的完成标记或租约，释放后由任意实例重新获取
+	ReleaseSlot(slot)
+	SetBlockState(slot, models.BlockQueued, nil)
+	l.blocks.PushBlock(slot)
+	metrics.IncRetriedSlots()
+	l.log.Info("重新推入跳过的槽位", zap.Uint64("slot", slot))
+	return nil
+}
+
+// List 返回跳过列表中的槽位，按槽位降序排列
+func (l *SlotSkipList) List(ctx context.Context, limit int64) ([]models.SkippedSlot, error) {
+	skipped, err := storage.GetRedisClient(storage.WorkloadQueue).ListSkippedSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return skipped[:min(int(limit), len(skipped))], nil
+}
+
+// Delete 从跳过列表中删除槽位，不再重试
+// 返回:
+//   - error: 槽位不在跳过列表中时返回 ErrSkippedSlotNotFound
+func (l *SlotSkipList) Delete(ctx context.Context, slot uint64) error {
+	deleted, err := storage.GetRedisClient(storage.WorkloadQueue).DeleteSkippedSlot(ctx, slot)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %d", ErrSkippedSlotNotFound, slot)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/testutil"
+)
+
+// newSkipList 创建使用miniredis和内存区块队列的跳过列表
+func newSkipList(t *testing.T) (*monitor.SlotSkipList, *storage.MemoryStore) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := configs.GlobalConfig
+	configs.SetGlobalConfig(cfg)
+	t.Cleanup(func() { configs.SetGlobalConfig(previous) })
+	testutil.NewRedis(t, &cfg.Redis)
+
+	blocks := storage.NewMemoryStore()
+	skipList := monitor.NewSlotSkipList(&configs.SkippedSlotsConfig{
+		RetryInterval:    time.Minute,
+		MaxRetryInterval: time.Hour,
+		MaxAttempts:      5,
+	}, blocks)
+	t.Cleanup(func() { monitor.GlobalSlotSkipList = nil })
+	return skipList, blocks
+}
+
+// TestSlotSkipListRetryPushesToHandlerQueue 重试的槽位推入注入的区块队列
+func TestSlotSkipListRetryPushesToHandlerQueue(t *testing.T) {
+	skipList, blocks := newSkipList(t)
+	skipList.Skip(100, context.DeadlineExceeded)
+	if err := skipList.Retry(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	if slot, ok := blocks.PopBlock(); !ok || slot != 100 {
+		t.Fatalf("重试的槽位应推入区块队列: slot=%d ok=%v", slot, ok)
+	}
+}
+
+// TestSlotSkipListResolveOtherInstance 由其他实例(或重启前)加入跳过列表的槽位处理成功后也会移出
+func TestSlotSkipListResolveOtherInstance(t *testing.T) {
+	skipList, _ := newSkipList(t)
+	ctx := context.Background()
+	redisClient := storage.GetRedisClient(storage.WorkloadQueue)
+	if err := redisClient.SaveSkippedSlot(ctx, models.SkippedSlot{Slot: 200, Attempts: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	skipList.Resolve(200)
+	skipped, err := redisClient.GetSkippedSlot(ctx, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != nil {
+		t.Fatalf("处理成功的槽位应移出跳过列表: %+v", skipped)
+	}
+}
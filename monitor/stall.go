@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalStallDetector 全局出块停滞检测
+var GlobalStallDetector *StallDetector
+
+// StallDetector 检测WebSocket已连接但长时间收不到槽位通知的情况
+// 超过阈值后通过HTTP getSlot探测区分本地订阅失效与集群/网络停滞
+type StallDetector struct {
+	mu               sync.Mutex
+	lastSlot         uint64
+	lastNotification time.Time
+	status           models.StallReport
+	threshold        time.Duration
+	interval         time.Duration
+	probeTimeout     time.Duration
+	log              *zap.Logger
+	cancel           context.CancelFunc
+}
+
+// NewStallDetector 创建出块停滞检测并设置为全局实例
+func NewStallDetector(config *configs.StallDetectionConfig) *StallDetector {
+	detector := &StallDetector{
+		lastNotification: clock.Now(),
+		threshold:        config.Threshold,
+		interval:         config.CheckInterval,
+		probeTimeout:     config.ProbeTimeout,
+		log:              logger.Named("monitor.stall"),
+	}
+	GlobalStallDetector = detector
+	return detector
+}
+
+// RecordSlot 记录收到的槽位通知，未启用检测时不做任何处理
+func RecordSlot(slot uint64) {
+	if GlobalStallDetector != nil {
+		GlobalStallDetector.RecordSlot(slot)
+	}
+}
+
+// RecordSlot 记录收到的槽位通知
+func (d *StallDetector) RecordSlot(slot uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if slot > d.lastSlot {
+		d.lastSlot = slot
+	}
+	d.lastNotification = clock.Now()
+}
+
+// Start 按检查间隔检测出块停滞
+func (d *StallDetector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	supervisor.Go(ctx, "monitor.stall", func(ctx context.Context) {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				d.check(ctx, now)
+			}
+		}
+	})
+	d.log.Info("出块停滞检测已启动", zap.Duration("threshold", d.threshold))
+}
+
+// Close 停止检测
+func (d *StallDetector) Close() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// Status 返回最近一次检测结果，Kind 为空表示未停滞
+func (d *StallDetector) Status() models.StallReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.status
+	status.LastSlot = d.lastSlot
+	status.LastNotification = d.lastNotification
+	return status
+}
+
+// check 执行一次检测，停滞原因变化时告警，恢复时发布恢复事件
+func (d *StallDetector) check(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	lastSlot, lastNotification, previous := d.lastSlot, d.lastNotification, d.status.Kind
+	d.mu.Unlock()
+
+	// WebSocket未连接时由重连逻辑处理，断线期间收不到通知不算停滞；备用实例没有订阅，也不检测
+	if rpc.GlobalWebSocketPool == nil || !rpc.GlobalWebSocketPool.IsConnected() || !IsIngestLeader() {
+		return
+	}
+
+	silence := now.Sub(lastNotification)
+	report := models.StallReport{
+		LastSlot:         lastSlot,
+		LastNotification: lastNotification,
+		Silence:          int64(silence.Seconds()),
+		Time:             now,
+	}
+	if silence < d.threshold {
+		if previous == models.StallNone {
+			return
+		}
+		report.Message = fmt.Sprintf("槽位通知已恢复，最新槽位 %d", lastSlot)
+		d.update(report)
+		d.log.Info("槽位通知已恢复", zap.Uint64("slot", lastSlot), zap.String("previous", string(previous)))
+		d.publish(report)
+		return
+	}
+
+	d.probe(ctx, &report)
+	d.update(report)
+	if report.Kind == previous {
+		return
+	}
+	d.log.Error("检测到出块停滞",
+		zap.String("kind", string(report.Kind)),
+		zap.Uint64("lastSlot", lastSlot),
+		zap.Uint64("probeSlot", report.ProbeSlot),
+		zap.Duration("silence", silence),
+		zap.String("probeError", report.ProbeError))
+	d.publish(report)
+}
+
+// probe 通过HTTP getSlot判定停滞原因
+func (d *StallDetector) probe(ctx context.Context, report *models.StallReport) {
+	silence := time.Duration(report.Silence) * time.Second
+	if rpc.GlobalHeliusClient == nil {
+		report.Kind = models.StallNetwork
+		report.ProbeError = "Helius HTTP API客户端尚未初始化"
+		report.Message = fmt.Sprintf("已 %s 未收到槽位通知，无法通过HTTP探测判定原因", silence)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.probeTimeout)
+	defer cancel()
+	slot, err := rpc.GlobalHeliusClient.GetSlot(ctx, "processed")
+	if err != nil {
+		report.Kind = models.StallNetwork
+		report.ProbeError = err.Error()
+		report.Message = fmt.Sprintf("已 %s 未收到槽位通知，HTTP探测也失败，网络或RPC节点不可用", silence)
+		return
+	}
+	report.ProbeSlot = slot
+	if slot > report.LastSlot {
+		report.Kind = models.StallSubscription
+		report.Message = fmt.Sprintf("已 %s 未收到槽位通知，但HTTP探测到槽位 %d 仍在推进(最后通知槽位 %d)，本地订阅已失效", silence, slot, report.LastSlot)
+		return
+	}
+	report.Kind = models.StallCluster
+	report.Message = fmt.Sprintf("已 %s 未收到槽位通知，HTTP探测槽位 %d 也未推进，集群出块停滞", silence, slot)
+}
+
+// update 保存检测结果
+func (d *StallDetector) update(report models.StallReport) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status = report
+}
+
+// publish 以出块停滞事件发布检测结果，可配合规则引擎告警
+func (d *StallDetector) publish(report models.StallReport) {
+	pipeline.Publish(pipeline.Event{
+		Type:  pipeline.EventStall,
+		Slot:  report.LastSlot,
+		Stall: &report,
+		Time:  report.Time,
+	})
+}
@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalSubscriptionWatchdog 全局订阅静默检测
+var GlobalSubscriptionWatchdog *SubscriptionWatchdog
+
+// SubscriptionWatchdog 检测连接正常但单个订阅长时间收不到通知的情况
+// 槽位通知停止时连接上的ping/pong仍然正常，存活检测发现不了，只能等队列耗尽后才被注意到
+type SubscriptionWatchdog struct {
+	mu         sync.Mutex
+	silent     map[int]models.SubscriptionSilenceReport // 客户端订阅ID -> 当前的静默告警
+	interval   time.Duration
+	maxSilence map[string]time.Duration
+	reconnect  bool
+	log        *zap.Logger
+	cancel     context.CancelFunc
+}
+
+// NewSubscriptionWatchdog 创建订阅静默检测并设置为全局实例
+func NewSubscriptionWatchdog(config *configs.SubscriptionWatchdogConfig) *SubscriptionWatchdog {
+	maxSilence := make(map[string]time.Duration, len(config.MaxSilence))
+	for kind, silence := range config.MaxSilence {
+		maxSilence[strings.ToLower(kind)] = silence
+	}
+	watchdog := &SubscriptionWatchdog{
+		silent:     make(map[int]models.SubscriptionSilenceReport),
+		interval:   config.CheckInterval,
+		maxSilence: maxSilence,
+		reconnect:  config.Reconnect,
+		log:        logger.Named("monitor.subscription_watchdog"),
+	}
+	GlobalSubscriptionWatchdog = watchdog
+	return watchdog
+}
+
+// Start 按检查间隔检测所有订阅
+func (w *SubscriptionWatchdog) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	supervisor.Go(ctx, "monitor.subscription_watchdog", func(ctx context.Context) {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.check(clock.Now())
+			}
+		}
+	})
+	w.log.Info("订阅静默检测已启动", zap.Any("max_silence", w.maxSilence), zap.Bool("reconnect", w.reconnect))
+}
+
+// Close 停止检测
+func (w *SubscriptionWatchdog) Close() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+}
+
+// check 执行一次检测，订阅进入静默时告警并按配置重连，恢复时发布恢复事件
+// 连接未建立或订阅等待重新订阅时由重连逻辑处理，不计入静默
+func (w *SubscriptionWatchdog) check(now time.Time) {
+	pool := rpc.GlobalWebSocketPool
+	if pool == nil {
+		return
+	}
+	active := make(map[int]bool)
+	for _, connection := range pool.Status() {
+		reconnect := false
+		for _, stats := range connection.SubscriptionStats {
+			active[stats.ID] = true
+			maxSilence, ok := w.maxSilence[strings.ToLower(strings.TrimSuffix(stats.Method, "Subscribe"))]
+			if !ok || !connection.Connected || stats.ServerID == 0 {
+				continue
+			}
+			// 从未收到通知或重新订阅后尚未收到通知时，从订阅确认时开始计算
+			last := stats.SubscribedAt
+			if stats.LastMessageAt != nil && stats.LastMessageAt.After(last) {
+				last = *stats.LastMessageAt
+			}
+			silence := now.Sub(last)
+			report := models.SubscriptionSilenceReport{
+				Connection:    connection.Index,
+				Subscription:  stats.ID,
+				Method:        stats.Method,
+				Silence:       int64(silence.Seconds()),
+				MaxSilence:    int64(maxSilence.Seconds()),
+				LastMessageAt: last,
+				Time:          now,
+			}
+			if silence < maxSilence {
+				w.recover(report, stats.LastMessageAt)
+				continue
+			}
+			if w.reconnect {
+				reconnect = true
+				report.Reconnected = true
+			}
+			w.alert(report, silence)
+		}
+		if reconnect {
+			// 重连后订阅确认时间更新，下一次告警至少在一个阈值之后
+			pool.Reconnect(connection.Index)
+		}
+	}
+
+	// 已取消的订阅不再跟踪
+	w.mu.Lock()
+	for id := range w.silent {
+		if !active[id] {
+			delete(w.silent, id)
+		}
+	}
+	w.mu.Unlock()
+}
+
+// alert 记录静默的订阅，首次进入静默或再次重连时发布订阅事件
+func (w *SubscriptionWatchdog) alert(report models.SubscriptionSilenceReport, silence time.Duration) {
+	w.mu.Lock()
+	_, alerted := w.silent[report.Subscription]
+	w.silent[report.Subscription] = report
+	w.mu.Unlock()
+	if alerted && !report.Reconnected {
+		return
+	}
+	report.Message = fmt.Sprintf("连接 %d 的订阅 %d(%s)已 %s 未收到通知，超过阈值 %s",
+		report.Connection, report.Subscription, report.Method, silence.Truncate(time.Second), time.Duration(report.MaxSilence)*time.Second)
+	if report.Reconnected {
+		report.Message += "，断开连接重连后恢复订阅"
+	}
+	w.log.Error(report.Message,
+		zap.Int("connection", report.Connection),
+		zap.Int("subscription", report.Subscription),
+		zap.String("method", report.Method),
+		zap.Duration("silence", silence))
+	w.publish(report)
+}
+
+// recover 之前静默的订阅在告警后重新收到通知时发布恢复事件，只是重新订阅后尚未超过阈值时继续等待
+func (w *SubscriptionWatchdog) recover(report models.SubscriptionSilenceReport, lastMessageAt *time.Time) {
+	w.mu.Lock()
+	previous, alerted := w.silent[report.Subscription]
+	if alerted && (lastMessageAt == nil || !lastMessageAt.After(previous.LastMessageAt)) {
+		w.mu.Unlock()
+		return
+	}
+	delete(w.silent, report.Subscription)
+	w.mu.Unlock()
+	if !alerted {
+		return
+	}
+	report.Recovered = true
+	report.Message = fmt.Sprintf("连接 %d 的订阅 %d(%s)已恢复接收通知", report.Connection, report.Subscription, report.Method)
+	w.log.Info(report.Message)
+	w.publish(report)
+}
+
+// publish 以订阅事件发布检测结果，可配合规则引擎告警
+func (w *SubscriptionWatchdog) publish(report models.SubscriptionSilenceReport) {
+	pipeline.Publish(pipeline.Event{
+		Type:    pipeline.EventSubscription,
+		Silence: &report,
+		Time:    report.Time,
+	})
+}
@@ -0,0 +1,265 @@
+package monitor
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// 保留的最近不一致记录数量
+const recentMismatchLimit = 100
+
+// GlobalVerifier 全局解析结果抽样校验
+var GlobalVerifier *Verifier
+
+// verificationSample 抽样交易在原始区块中的关键字段
+type verificationSample struct {
+	slot      uint64
+	fee       int64
+	received  map[string]decimal.Decimal // 代币 -> 各账户余额增加量之和
+	sampledAt time.Time
+}
+
+// Verifier 从原始区块中抽样交易，与Enhanced API的解析结果核对手续费、槽位和代币转账总量，
+// 统计不一致比例，用于及时发现Enhanced API的解析回归
+type Verifier struct {
+	config     configs.VerificationConfig
+	mu         sync.Mutex
+	pending    map[string]verificationSample
+	stats      models.VerificationStats
+	mismatches []models.VerificationMismatch
+	log        *zap.Logger
+	cancel     context.CancelFunc
+}
+
+// NewVerifier 创建解析结果抽样校验并设置为全局实例
+func NewVerifier(config *configs.VerificationConfig) *Verifier {
+	verifier := &Verifier{
+		config:  *config,
+		pending: make(map[string]verificationSample),
+		stats:   models.VerificationStats{Fields: make(map[string]int64)},
+		log:     logger.Named("monitor.verification"),
+	}
+	GlobalVerifier = verifier
+	return verifier
+}
+
+// Start 定期清理超时未返回解析结果的抽样并输出不一致比例
+func (v *Verifier) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+	supervisor.Go(ctx, "monitor.verification", func(ctx context.Context) {
+		ticker := time.NewTicker(v.config.ReportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				v.expire(now)
+				v.report()
+			}
+		}
+	})
+	v.log.Info("解析结果抽样校验已启动", zap.Float64("sample_rate", v.config.SampleRate))
+}
+
+// Close 停止定期清理和输出
+func (v *Verifier) Close() {
+	if v.cancel != nil {
+		v.cancel()
+	}
+}
+
+// SampleBlock 按抽样比例记录区块中交易的原始字段，未启用时不做任何处理
+// 参数:
+//   - slot: 区块高度
+//   - transactions: 将交给Enhanced API解析的交易
+func SampleBlock(slot uint64, transactions []resp.Transactions) {
+	if GlobalVerifier != nil {
+		GlobalVerifier.Sample(slot, transactions)
+	}
+}
+
+// VerifyTransaction 将解析结果与抽样记录核对，未启用或未被抽样时不做任何处理
+func VerifyTransaction(transaction *resp.ParsedTransaction) {
+	if GlobalVerifier != nil {
+		GlobalVerifier.Verify(transaction, clock.Now())
+	}
+}
+
+// Sample 按抽样比例记录区块中交易的原始字段
+func (v *Verifier) Sample(slot uint64, transactions []resp.Transactions) {
+	now := clock.Now()
+	for _, transaction := range transactions {
+		if len(transaction.Transaction.Signatures) == 0 || rand.Float64() >= v.config.SampleRate {
+			continue
+		}
+		sample := verificationSample{
+			slot:      slot,
+			fee:       int64(transaction.Meta.Fee),
+			received:  receivedTokens(transaction.Meta),
+			sampledAt: now,
+		}
+		v.mu.Lock()
+		if len(v.pending) >= v.config.MaxPending {
+			v.stats.Dropped++
+		} else {
+			v.pending[transaction.Transaction.Signatures[0]] = sample
+			v.stats.Sampled++
+		}
+		v.mu.Unlock()
+	}
+}
+
+// Verify 将解析结果与抽样记录核对，记录不一致的字段
+func (v *Verifier) Verify(transaction *resp.ParsedTransaction, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	sample, ok := v.pending[transaction.Signature]
+	if !ok {
+		return
+	}
+	delete(v.pending, transaction.Signature)
+
+	var mismatches []models.VerificationMismatch
+	mismatch := func(field, raw, enhanced string) {
+		mismatches = append(mismatches, models.VerificationMismatch{
+			Signature: transaction.Signature,
+			Slot:      sample.slot,
+			Field:     field,
+			Raw:       raw,
+			Enhanced:  enhanced,
+			Time:      now.Unix(),
+		})
+	}
+	if sample.fee != transaction.Fee {
+		mismatch("fee", strconv.FormatInt(sample.fee, 10), strconv.FormatInt(transaction.Fee, 10))
+	}
+	if sample.slot != transaction.Slot {
+		mismatch("slot", strconv.FormatUint(sample.slot, 10), strconv.FormatUint(transaction.Slot, 10))
+	}
+	transferred := make(map[string]decimal.Decimal)
+	for _, transfer := range transaction.TokenTransfers {
+		transferred[transfer.Mint] = transferred[transfer.Mint].Add(transfer.TokenAmount.Abs())
+	}
+	// 经过中间账户的转账会在解析结果中重复计算，因此只检查解析结果是否少于余额实际增加的数量
+	for mint, received := range sample.received {
+		if transferred[mint].LessThan(received) {
+			mismatch("token_transfers", mint+": "+received.String(), mint+": "+transferred[mint].String())
+		}
+	}
+	v.record(mismatches)
+}
+
+// expire 将超时未返回解析结果的抽样记为签名不一致
+func (v *Verifier) expire(now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for signature, sample := range v.pending {
+		if now.Sub(sample.sampledAt) < v.config.PendingTTL {
+			continue
+		}
+		delete(v.pending, signature)
+		v.record([]models.VerificationMismatch{{
+			Signature: signature,
+			Slot:      sample.slot,
+			Field:     "signature",
+			Raw:       signature,
+			Time:      now.Unix(),
+		}})
+	}
+}
+
+// record 记录一个抽样的校验结果，调用方需持有锁
+func (v *Verifier) record(mismatches []models.VerificationMismatch) {
+	v.stats.Checked++
+	if len(mismatches) == 0 {
+		return
+	}
+	v.stats.Mismatched++
+	for _, mismatch := range mismatches {
+		v.stats.Fields[mismatch.Field]++
+		v.log.Debug("解析结果与原始区块不一致",
+			zap.String("signature", mismatch.Signature),
+			zap.String("field", mismatch.Field),
+			zap.String("raw", mismatch.Raw),
+			zap.String("enhanced", mismatch.Enhanced))
+	}
+	v.mismatches = append(v.mismatches, mismatches...)
+	if len(v.mismatches) > recentMismatchLimit {
+		v.mismatches = v.mismatches[len(v.mismatches)-recentMismatchLimit:]
+	}
+}
+
+// report 输出当前的不一致比例，超过告警阈值时输出警告
+func (v *Verifier) report() {
+	stats := v.Stats()
+	if stats.Checked == 0 {
+		return
+	}
+	fields := []zap.Field{
+		zap.Int64("checked", stats.Checked),
+		zap.Int64("mismatched", stats.Mismatched),
+		zap.Float64("mismatch_rate", stats.MismatchRate),
+		zap.Any("fields", stats.Fields),
+	}
+	if v.config.AlertThreshold > 0 && stats.MismatchRate > v.config.AlertThreshold {
+		v.log.Warn("Enhanced API解析结果不一致比例超过阈值", append(fields, zap.Float64("threshold", v.config.AlertThreshold))...)
+		return
+	}
+	v.log.Info("解析结果抽样校验", fields...)
+}
+
+// Stats 返回抽样校验的统计
+func (v *Verifier) Stats() models.VerificationStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	stats := v.stats
+	stats.Pending = len(v.pending)
+	stats.Fields = make(map[string]int64, len(v.stats.Fields))
+	for field, count := range v.stats.Fields {
+		stats.Fields[field] = count
+	}
+	if stats.Checked > 0 {
+		stats.MismatchRate = float64(stats.Mismatched) / float64(stats.Checked)
+	}
+	stats.RecentMismatches = make([]models.VerificationMismatch, 0, len(v.mismatches))
+	for i := len(v.mismatches) - 1; i >= 0; i-- {
+		stats.RecentMismatches = append(stats.RecentMismatches, v.mismatches[i])
+	}
+	return stats
+}
+
+// receivedTokens 按代币汇总原始区块中各账户的余额增加量
+func receivedTokens(meta resp.Meta) map[string]decimal.Decimal {
+	pre := make(map[int]decimal.Decimal, len(meta.PreTokenBalances))
+	for _, balance := range meta.PreTokenBalances {
+		pre[balance.AccountIndex] = uiAmount(balance.UITokenAmount)
+	}
+	received := make(map[string]decimal.Decimal)
+	for _, balance := range meta.PostTokenBalances {
+		delta := uiAmount(balance.UITokenAmount).Sub(pre[balance.AccountIndex])
+		if delta.IsPositive() {
+			received[balance.Mint] = received[balance.Mint].Add(delta)
+		}
+	}
+	return received
+}
+
+// uiAmount 将余额转换为考虑精度后的数量
+func uiAmount(amount resp.UITokenAmount) decimal.Decimal {
+	return amount.Amount.Shift(-int32(amount.Decimals))
+}
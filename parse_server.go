@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/api"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// newParseServerCommand 只运行解析阶段的独立HTTP服务
+func newParseServerCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "parse-server",
+		Short: "启动独立解析服务，按签名或原始交易返回解析结果，不使用队列和Redis",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runParseServer()
+		},
+	}
+}
+
+// runParseServer 启动独立解析服务，阻塞直到收到退出信号
+func runParseServer() {
+	loadConfig()
+	applyProxyConfig()
+	rpc.NewHeliusEnhancedApiClient(&configs.GlobalConfig.HeliusEnhancedAPI)
+	// 独立解析服务不使用Redis，密钥预算只按本进程的用量判断
+	rpc.SetEnhancedUsageRecorder(monitor.NewAPIKeyUsageTracker(&configs.GlobalConfig.HeliusEnhancedAPI))
+	if rpc.GetEnhancedApiClientCount() == 0 {
+		logger.Warn("未配置Enhanced API密钥，只能解码原始交易")
+	}
+
+	server := api.NewParseServer(&configs.GlobalConfig.ParseServer)
+	server.Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	logger.Info("接收到退出信号，解析服务即将关闭...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Close(shutdownCtx); err != nil {
+		logger.Error("关闭解析服务失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// newParseTxCommand 使用Enhanced API解析单个交易
+func newParseTxCommand() *cobra.Command {
+	var raw bool
+	var commitment string
+	cmd := &cobra.Command{
+		Use:   "parse-tx <signature>",
+		Short: "使用Enhanced API解析单个交易并输出结果",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runParseTx(args[0], raw, commitment)
+		},
+	}
+	cmd.Flags().BoolVar(&raw, "raw", false, "输出Enhanced API原始响应")
+	cmd.Flags().StringVar(&commitment, "commitment", "", "确认级别: confirmed 或 finalized，默认使用 helius_enhanced_api.commitment")
+	return cmd
+}
+
+// runParseTx 解析交易并以JSON格式输出解析结果及过滤判定
+func runParseTx(signature string, raw bool, commitment string) error {
+	loadConfig()
+	applyProxyConfig()
+	rpc.NewHeliusEnhancedApiClient(&configs.GlobalConfig.HeliusEnhancedAPI)
+	if rpc.GetEnhancedApiClientCount() == 0 {
+		return fmt.Errorf("没有可用的Enhanced API客户端")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	body, err := rpc.GetEnhancedApiClientByIndex(0).ParseTransactions(ctx, &rpc.ParseOptions{Commitment: commitment}, signature)
+	if err != nil {
+		return err
+	}
+	if raw {
+		_, err := os.Stdout.Write(append(body, '\n'))
+		return err
+	}
+
+	var parsedTransactions []resp.ParsedTransaction
+	if err := json.Unmarshal(body, &parsedTransactions); err != nil {
+		return fmt.Errorf("解析Enhanced API响应失败: %w", err)
+	}
+	if len(parsedTransactions) == 0 {
+		return fmt.Errorf("Enhanced API未返回交易")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(parsedTransactions[0]); err != nil {
+		return err
+	}
+	fmt.Printf("过滤器判定: %s\n", verdict(handler.ParsedTransactionFilterReason(parsedTransactions[0])))
+	return nil
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"encoding/binary"
+	"slices"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// ComputeBudgetProgramID 计算预算程序地址
+const ComputeBudgetProgramID = "ComputeBudget111111111111111111111111111111"
+
+// 计算预算指令类型
+const (
+	computeBudgetSetComputeUnitLimit = 2 // SetComputeUnitLimit(u32)
+	computeBudgetSetComputeUnitPrice = 3 // SetComputeUnitPrice(u64，微lamports/CU)
+)
+
+// ComputeBudget 交易设置的计算预算
+type ComputeBudget struct {
+	UnitPrice uint64   `json:"unit_price"` // 计算单元价格(微lamports/CU)，未设置时为0
+	UnitLimit uint32   `json:"unit_limit"` // 计算单元上限，未设置时为0
+	Programs  []string `json:"programs"`   // 交易顶层指令调用的程序(不含计算预算程序)，按出现顺序去重
+}
+
+// DecodeComputeBudget 解码交易顶层指令中的 SetComputeUnitPrice/SetComputeUnitLimit，并收集调用的程序
+func DecodeComputeBudget(transaction resp.Transactions) ComputeBudget {
+	var budget ComputeBudget
+	keys := transaction.Transaction.Message.AccountKeys
+	for _, instruction := range transaction.Transaction.Message.Instructions {
+		programID := accountAt(keys, instruction.ProgramIDIndex)
+		if programID != ComputeBudgetProgramID {
+			if programID != "" && !slices.Contains(budget.Programs, programID) {
+				budget.Programs = append(budget.Programs, programID)
+			}
+			continue
+		}
+		data, err := base58.Decode(instruction.Data)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case computeBudgetSetComputeUnitPrice:
+			if len(data) >= 9 {
+				budget.UnitPrice = binary.LittleEndian.Uint64(data[1:9])
+			}
+		case computeBudgetSetComputeUnitLimit:
+			if len(data) >= 5 {
+				budget.UnitLimit = binary.LittleEndian.Uint32(data[1:5])
+			}
+		}
+	}
+	return budget
+}
+
+// lamportsPerSignature 每个签名的基础手续费(lamports)
+const lamportsPerSignature = 5000
+
+// TransactionFee 交易实际支付的手续费和设置的计算单元价格
+type TransactionFee struct {
+	Fee          uint64   // 总手续费(lamports)
+	PriorityFee  uint64   // 优先费(lamports)，总手续费减去每个签名5000 lamports的基础手续费
+	UnitPrice    uint64   // 计算单元价格(微lamports/CU)，未设置时为0
+	ComputeUnits uint64   // 实际消耗的计算单元
+	Failed       bool     // 交易是否执行失败，失败的交易同样支付手续费
+	Programs     []string // 交易调用的程序，包括通过CPI调用的程序，不含计算预算程序
+}
+
+// DecodeTransactionFee 从交易的元数据和计算预算指令中取出手续费、优先费和计算单元价格
+func DecodeTransactionFee(transaction resp.Transactions) TransactionFee {
+	fee := TransactionFee{
+		Fee:          transaction.Meta.Fee,
+		UnitPrice:    DecodeComputeBudget(transaction).UnitPrice,
+		ComputeUnits: transaction.Meta.ComputeUnitsConsumed,
+		Failed:       IsFailedTransaction(transaction),
+	}
+	if baseFee := uint64(len(transaction.Transaction.Signatures)) * lamportsPerSignature; fee.Fee > baseFee {
+		fee.PriorityFee = fee.Fee - baseFee
+	}
+	for _, program := range InvokedPrograms(transaction) {
+		if program != ComputeBudgetProgramID {
+			fee.Programs = append(fee.Programs, program)
+		}
+	}
+	return fee
+}
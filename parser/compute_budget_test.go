@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+func TestDecodeTransactionFee(t *testing.T) {
+	price := base58.Encode(binary.LittleEndian.AppendUint64([]byte{computeBudgetSetComputeUnitPrice}, 25000))
+	limit := base58.Encode(binary.LittleEndian.AppendUint32([]byte{computeBudgetSetComputeUnitLimit}, 200000))
+	var transaction resp.Transactions
+	transaction.Transaction.Signatures = []string{"sig"}
+	transaction.Transaction.Message.AccountKeys = []string{"payer", ComputeBudgetProgramID, "dex"}
+	transaction.Transaction.Message.Instructions = []resp.Instructions{
+		{ProgramIDIndex: 1, Data: limit},
+		{ProgramIDIndex: 1, Data: price},
+		{ProgramIDIndex: 2},
+	}
+	transaction.Meta.Fee = 10000
+	transaction.Meta.ComputeUnitsConsumed = 120000
+	transaction.Meta.LogMessages = []string{
+		"Program dex invoke [1]",
+		"Program " + TokenProgramID + " invoke [2]",
+		"Program " + TokenProgramID + " success",
+		"Program dex success",
+	}
+
+	fee := DecodeTransactionFee(transaction)
+	if fee.Fee != 10000 || fee.PriorityFee != 5000 || fee.UnitPrice != 25000 || fee.ComputeUnits != 120000 || fee.Failed {
+		t.Fatalf("手续费解码错误: %+v", fee)
+	}
+	// 包括通过CPI调用的程序，不含计算预算程序
+	if !slices.Equal(fee.Programs, []string{"dex", TokenProgramID}) {
+		t.Fatalf("调用的程序: %v", fee.Programs)
+	}
+
+	// 没有设置计算单元价格且只支付基础手续费
+	transaction.Transaction.Message.Instructions = transaction.Transaction.Message.Instructions[2:]
+	transaction.Meta.Fee = 5000
+	transaction.Meta.Err.InstructionError = []interface{}{0, "Custom"}
+	fee = DecodeTransactionFee(transaction)
+	if fee.PriorityFee != 0 || fee.UnitPrice != 0 || !fee.Failed {
+		t.Fatalf("手续费解码错误: %+v", fee)
+	}
+}
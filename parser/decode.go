@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/shopspring/decimal"
+)
+
+// VoteProgramID 投票程序地址
+const VoteProgramID = "Vote111111111111111111111111111111111111111"
+
+// LocalTransaction 表示根据原始交易数据在本地解码出的交易摘要
+type LocalTransaction struct {
+	Signature            string              `json:"signature"`              // 交易签名
+	Slot                 uint64              `json:"slot"`                   // 区块高度
+	Fee                  int64               `json:"fee"`                    // 手续费(lamports)
+	FeePayer             string              `json:"fee_payer"`              // 手续费支付者
+	Failed               bool                `json:"failed"`                 // 是否执行失败
+	IsVote               bool                `json:"is_vote"`                // 是否为投票交易
+	AccountKeys          []string            `json:"account_keys"`           // 完整账户列表(含地址查找表加载的账户)
+	NativeBalanceChanges map[string]int64    `json:"native_balance_changes"` // 各账户SOL余额变化(lamports)
+	TokenBalanceChanges  []TokenBalanceDelta `json:"token_balance_changes"`  // 代币余额变化
+}
+
+// TokenBalanceDelta 表示单个代币账户的余额变化
+type TokenBalanceDelta struct {
+	TokenAccount string          `json:"token_account"` // 代币账户
+	Owner        string          `json:"owner"`         // 账户所有者
+	Mint         string          `json:"mint"`          // 代币地址
+	RawAmount    decimal.Decimal `json:"raw_amount"`    // 变化数量(最小单位)
+	Decimals     int             `json:"decimals"`      // 精度
+}
+
+// IsVoteTransaction 根据日志判断是否为投票交易
+func IsVoteTransaction(transaction resp.Transactions) bool {
+	for _, logMessage := range transaction.Meta.LogMessages {
+		if strings.Contains(logMessage, VoteProgramID) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFailedTransaction 判断交易是否执行失败，meta.err 不为null即为失败，包括指令错误以外的错误(如 InsufficientFundsForRent)
+func IsFailedTransaction(transaction resp.Transactions) bool {
+	return !transaction.Meta.Err.IsNil() || !transaction.Meta.Status.Err.IsNil()
+}
+
+// DecodeTransaction 根据前后余额在本地解码交易，不依赖Enhanced API
+func DecodeTransaction(slot uint64, transaction resp.Transactions) *LocalTransaction {
+	local := &LocalTransaction{
+		Slot:                 slot,
+		Fee:                  int64(transaction.Meta.Fee),
+		Failed:               IsFailedTransaction(transaction),
+		IsVote:               IsVoteTransaction(transaction),
+		NativeBalanceChanges: make(map[string]int64),
+	}
+	if len(transaction.Transaction.Signatures) > 0 {
+		local.Signature = transaction.Transaction.Signatures[0]
+	}
+
+	local.AccountKeys = accountKeys(transaction)
+	if len(local.AccountKeys) > 0 {
+		local.FeePayer = local.AccountKeys[0]
+	}
+
+	// SOL余额变化
+	for i, account := range local.AccountKeys {
+		if i >= len(transaction.Meta.PreBalances) || i >= len(transaction.Meta.PostBalances) {
+			break
+		}
+		change := int64(transaction.Meta.PostBalances[i]) - int64(transaction.Meta.PreBalances[i])
+		if change != 0 {
+			local.NativeBalanceChanges[account] = change
+		}
+	}
+
+	// 代币余额变化，按账户索引对齐前后余额
+	type tokenBalance struct {
+		owner    string
+		mint     string
+		amount   decimal.Decimal
+		decimals int
+	}
+	preTokenBalances := make(map[int]tokenBalance)
+	for _, balance := range transaction.Meta.PreTokenBalances {
+		preTokenBalances[balance.AccountIndex] = tokenBalance{balance.Owner, balance.Mint, balance.UITokenAmount.Amount, balance.UITokenAmount.Decimals}
+	}
+	for _, balance := range transaction.Meta.PostTokenBalances {
+		amount := balance.UITokenAmount.Amount
+		pre, ok := preTokenBalances[balance.AccountIndex]
+		delete(preTokenBalances, balance.AccountIndex)
+		if ok {
+			amount = amount.Sub(pre.amount)
+		}
+		if amount.IsZero() {
+			continue
+		}
+		local.TokenBalanceChanges = append(local.TokenBalanceChanges, TokenBalanceDelta{
+			TokenAccount: accountAt(local.AccountKeys, balance.AccountIndex),
+			Owner:        balance.Owner,
+			Mint:         balance.Mint,
+			RawAmount:    amount,
+			Decimals:     balance.UITokenAmount.Decimals,
+		})
+	}
+	// 交易后不存在的代币账户(已关闭)，余额全部转出
+	for index, pre := range preTokenBalances {
+		if pre.amount.IsZero() {
+			continue
+		}
+		local.TokenBalanceChanges = append(local.TokenBalanceChanges, TokenBalanceDelta{
+			TokenAccount: accountAt(local.AccountKeys, index),
+			Owner:        pre.owner,
+			Mint:         pre.mint,
+			RawAmount:    pre.amount.Neg(),
+			Decimals:     pre.decimals,
+		})
+	}
+
+	return local
+}
+
+// accountKeys 返回完整账户列表，见 resp.Transactions.ResolveAccountKeys
+func accountKeys(transaction resp.Transactions) []string {
+	return transaction.ResolveAccountKeys()
+}
+
+// accountAt 按索引安全获取账户地址
+func accountAt(accountKeys []string, index int) string {
+	if index < 0 || index >= len(accountKeys) {
+		return ""
+	}
+	return accountKeys[index]
+}
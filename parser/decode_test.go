@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+func TestIsFailedTransaction(t *testing.T) {
+	tests := []struct {
+		meta string
+		want bool
+	}{
+		{`{"err":null,"status":{"Ok":null}}`, false},
+		{`{"err":null}`, false},
+		{`{"err":{"InstructionError":[0,{"Custom":1}]},"status":{"Err":{"InstructionError":[0,{"Custom":1}]}}}`, true},
+		{`{"err":{"InsufficientFundsForRent":{"account_index":2}}}`, true},
+		{`{"err":"AccountInUse"}`, true},
+	}
+	for _, tt := range tests {
+		var transaction resp.Transactions
+		if err := json.Unmarshal([]byte(`{"meta":`+tt.meta+`}`), &transaction); err != nil {
+			t.Fatal(err)
+		}
+		if got := IsFailedTransaction(transaction); got != tt.want {
+			t.Errorf("meta %s: IsFailedTransaction = %v，期望 %v", tt.meta, got, tt.want)
+		}
+	}
+}
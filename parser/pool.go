@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"slices"
+	"strings"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// poolLayout 池子初始化指令的识别方式和账户布局，账户位置为指令账户列表中的下标
+type poolLayout struct {
+	dex           string
+	logMarker     string // 初始化时程序输出的日志，用于在获取交易前预先过滤
+	discriminator []byte // 指令数据的前缀
+	pool          int
+	mintA         int
+	mintB         int
+	vaultA        int // 存放第一个代币的金库
+	vaultB        int // 存放第二个代币的金库
+}
+
+// poolLayouts 支持识别池子创建的程序，程序ID -> 初始化指令布局
+var poolLayouts = map[string][]poolLayout{
+	// Raydium AMM v4 的 initialize2，指令数据首字节为1
+	KnownPrograms["raydium_amm"]: {
+		{dex: "raydium_amm", logMarker: "initialize2", discriminator: []byte{1}, pool: 4, mintA: 8, mintB: 9, vaultA: 10, vaultB: 11},
+	},
+	KnownPrograms["raydium_cpmm"]: {
+		{dex: "raydium_cpmm", logMarker: "Instruction: Initialize", discriminator: anchorDiscriminator("initialize"), pool: 3, mintA: 4, mintB: 5, vaultA: 10, vaultB: 11},
+	},
+	KnownPrograms["meteora_dlmm"]: {
+		{dex: "meteora_dlmm", logMarker: "Instruction: InitializeLbPair", discriminator: anchorDiscriminator("initialize_lb_pair"), pool: 0, mintA: 2, mintB: 3, vaultA: 4, vaultB: 5},
+		{dex: "meteora_dlmm", logMarker: "Instruction: InitializeCustomizablePermissionlessLbPair", discriminator: anchorDiscriminator("initialize_customizable_permissionless_lb_pair"), pool: 0, mintA: 2, mintB: 3, vaultA: 4, vaultB: 5},
+	},
+	// Meteora Dynamic AMM的代币存放在共享的金库中，注入数量按金库余额的变化计算
+	KnownPrograms["meteora_amm"]: {
+		{dex: "meteora_amm", logMarker: "Instruction: InitializePermissionlessPool", discriminator: anchorDiscriminator("initialize_permissionless_pool"), pool: 0, mintA: 2, mintB: 3, vaultA: 6, vaultB: 7},
+	},
+}
+
+// anchorDiscriminator Anchor程序指令数据的前8字节，为 sha256("global:<指令名>") 的前8字节
+func anchorDiscriminator(name string) []byte {
+	sum := sha256.Sum256([]byte("global:" + name))
+	return sum[:8]
+}
+
+// PoolPrograms 返回支持识别池子创建的程序ID
+func PoolPrograms() []string {
+	programs := make([]string, 0, len(poolLayouts))
+	for program := range poolLayouts {
+		programs = append(programs, program)
+	}
+	slices.Sort(programs)
+	return programs
+}
+
+// IsPoolCreationLog 根据交易日志判断交易是否可能创建了池子，用于在获取完整交易前过滤掉普通swap
+func IsPoolCreationLog(programID string, logs []string) bool {
+	for _, layout := range poolLayouts[programID] {
+		for _, log := range logs {
+			if strings.Contains(log, layout.logMarker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DecodePoolCreations 解析交易顶层和内部指令中的池子初始化指令
+// 只返回 Signature、Program、Dex、Pool、Creator、代币和注入数量，槽位和时间由调用方填写
+func DecodePoolCreations(transaction resp.Transactions) []models.PoolCreation {
+	if IsFailedTransaction(transaction) {
+		return nil
+	}
+	keys := accountKeys(transaction)
+	instructions := slices.Clone(transaction.Transaction.Message.Instructions)
+	for _, inner := range transaction.Meta.InnerInstructions {
+		instructions = append(instructions, inner.Instructions...)
+	}
+
+	var creations []models.PoolCreation
+	for _, instruction := range instructions {
+		programID := accountAt(keys, instruction.ProgramIDIndex)
+		layouts, ok := poolLayouts[programID]
+		if !ok {
+			continue
+		}
+		data, err := base58.Decode(instruction.Data)
+		if err != nil {
+			continue
+		}
+		for _, layout := range layouts {
+			if !bytes.HasPrefix(data, layout.discriminator) {
+				continue
+			}
+			account := func(position int) int {
+				if position >= len(instruction.Accounts) {
+					return -1
+				}
+				return instruction.Accounts[position]
+			}
+			creation := models.PoolCreation{
+				Program: programID,
+				Dex:     layout.dex,
+				Pool:    accountAt(keys, account(layout.pool)),
+				Creator: accountAt(keys, 0),
+				MintA:   accountAt(keys, account(layout.mintA)),
+				MintB:   accountAt(keys, account(layout.mintB)),
+				AmountA: tokenBalanceChange(transaction, account(layout.vaultA)),
+				AmountB: tokenBalanceChange(transaction, account(layout.vaultB)),
+			}
+			if len(transaction.Transaction.Signatures) > 0 {
+				creation.Signature = transaction.Transaction.Signatures[0]
+			}
+			if creation.Pool != "" && creation.MintA != "" && creation.MintB != "" {
+				creations = append(creations, creation)
+			}
+			break
+		}
+	}
+	return creations
+}
+
+// tokenBalanceChange 返回代币账户在交易前后的余额变化，账户不是代币账户时返回0
+func tokenBalanceChange(transaction resp.Transactions, accountIndex int) float64 {
+	var change float64
+	for _, balance := range transaction.Meta.PostTokenBalances {
+		if balance.AccountIndex == accountIndex {
+			change += balance.UITokenAmount.UIAmount
+		}
+	}
+	for _, balance := range transaction.Meta.PreTokenBalances {
+		if balance.AccountIndex == accountIndex {
+			change -= balance.UITokenAmount.UIAmount
+		}
+	}
+	return change
+}
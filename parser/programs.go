@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// KnownPrograms 常用程序的别名，配置中可以用别名代替程序ID
+var KnownPrograms = map[string]string{
+	"pump_fun":          "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+	"pump_amm":          "pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA",
+	"raydium_amm":       "675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8",
+	"raydium_clmm":      "CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK",
+	"raydium_cpmm":      "CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C",
+	"raydium_launchpad": "LanMV9sAd7wArD4vJFi2qDdfnVhFxYSUg6eADduJ3uj",
+	"jupiter":           "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4",
+	"meteora_dlmm":      "LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo",
+	"meteora_amm":       "Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB",
+	"orca_whirlpool":    "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",
+	"system":            SystemProgramID,
+	"token":             TokenProgramID,
+	"token_2022":        Token2022ProgramID,
+	"associated_token":  AssociatedTokenProgramID,
+	"compute_budget":    ComputeBudgetProgramID,
+	"memo":              MemoProgramID,
+}
+
+// ResolveProgram 将程序别名转换为程序ID，不是已知别名时原样返回
+func ResolveProgram(nameOrID string) string {
+	if programID, ok := KnownPrograms[strings.ToLower(nameOrID)]; ok {
+		return programID
+	}
+	return nameOrID
+}
+
+// InvokedPrograms 返回交易调用的所有程序，按出现顺序去重
+// 顶层指令的程序从账户列表中按索引取得，内部指令(CPI)调用的程序从 "Program <ID> invoke [n]" 日志中取得
+func InvokedPrograms(transaction resp.Transactions) []string {
+	var programs []string
+	add := func(programID string) {
+		if programID != "" && !slices.Contains(programs, programID) {
+			programs = append(programs, programID)
+		}
+	}
+	keys := accountKeys(transaction)
+	for _, instruction := range transaction.Transaction.Message.Instructions {
+		add(accountAt(keys, instruction.ProgramIDIndex))
+	}
+	for _, logMessage := range transaction.Meta.LogMessages {
+		rest, ok := strings.CutPrefix(logMessage, "Program ")
+		if !ok {
+			continue
+		}
+		programID, invoke, ok := strings.Cut(rest, " ")
+		if ok && strings.HasPrefix(invoke, "invoke [") {
+			add(programID)
+		}
+	}
+	return programs
+}
+
+// ReferencesAccount 判断交易的账户列表(含地址查找表加载的账户)是否包含 accounts 中的任一账户
+func ReferencesAccount(transaction resp.Transactions, accounts []string) bool {
+	for _, account := range accountKeys(transaction) {
+		if slices.Contains(accounts, account) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// TokenAccountEvent 表示交易中创建或关闭的代币账户
+type TokenAccountEvent struct {
+	TokenAccount string `json:"token_account"` // 代币账户
+	Owner        string `json:"owner"`         // 账户所有者
+	Mint         string `json:"mint"`          // 代币地址
+	Closed       bool   `json:"closed"`        // true为关闭(CloseAccount)，false为创建(InitializeAccount)
+}
+
+// DecodeTokenAccountEvents 根据前后代币余额识别交易中创建和关闭的代币账户
+// InitializeAccount/InitializeAccount2/InitializeAccount3 创建的账户只出现在交易后余额中，
+// CloseAccount 关闭的账户只出现在交易前余额中；余额记录带有代币地址，无需解码指令数据。
+// 同一交易内创建又关闭的临时账户(如包装SOL)不会出现在前后余额中，不计入统计
+func DecodeTokenAccountEvents(transaction resp.Transactions) []TokenAccountEvent {
+	if IsFailedTransaction(transaction) {
+		return nil
+	}
+	pre := make(map[int]struct{}, len(transaction.Meta.PreTokenBalances))
+	for _, balance := range transaction.Meta.PreTokenBalances {
+		pre[balance.AccountIndex] = struct{}{}
+	}
+	post := make(map[int]struct{}, len(transaction.Meta.PostTokenBalances))
+	for _, balance := range transaction.Meta.PostTokenBalances {
+		post[balance.AccountIndex] = struct{}{}
+	}
+
+	var keys []string
+	var events []TokenAccountEvent
+	for _, balance := range transaction.Meta.PostTokenBalances {
+		if _, ok := pre[balance.AccountIndex]; ok {
+			continue
+		}
+		if keys == nil {
+			keys = accountKeys(transaction)
+		}
+		events = append(events, TokenAccountEvent{
+			TokenAccount: accountAt(keys, balance.AccountIndex),
+			Owner:        balance.Owner,
+			Mint:         balance.Mint,
+		})
+	}
+	for _, balance := range transaction.Meta.PreTokenBalances {
+		if _, ok := post[balance.AccountIndex]; ok {
+			continue
+		}
+		if keys == nil {
+			keys = accountKeys(transaction)
+		}
+		events = append(events, TokenAccountEvent{
+			TokenAccount: accountAt(keys, balance.AccountIndex),
+			Owner:        balance.Owner,
+			Mint:         balance.Mint,
+			Closed:       true,
+		})
+	}
+	return events
+}
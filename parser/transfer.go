@@ -1,7 +1,263 @@
 package parser
 
-import "github.com/life2you/datas-go/models/resp"
+import (
+	"cmp"
+	"encoding/binary"
+	"math/big"
+	"slices"
+
+	"github.com/mr-tron/base58"
+	"github.com/shopspring/decimal"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// 只做转账的交易会调用的程序
+const (
+	SystemProgramID          = "11111111111111111111111111111111"
+	TokenProgramID           = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	Token2022ProgramID       = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+	AssociatedTokenProgramID = "ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA1knL"
+	MemoProgramID            = "MemoSq4gqABAXKb96qnH8TysNAWkPfj2HvsobxYzfE4"
+	MemoV1ProgramID          = "Memo1UhkJRfHyvLMcVucJwxXeuD728EQVGtLJVSfDHn"
+)
+
+// TransferPrograms 默认可在本地解码为转账的程序：系统程序、SPL代币程序、关联代币账户程序、计算预算和Memo
+var TransferPrograms = []string{
+	SystemProgramID,
+	TokenProgramID,
+	Token2022ProgramID,
+	AssociatedTokenProgramID,
+	ComputeBudgetProgramID,
+	MemoProgramID,
+	MemoV1ProgramID,
+}
 
 func ParserTransfer(trans resp.ParsedTransaction) {
 
 }
+
+// IsTransferTransaction 判断交易是否只调用 programs 中的程序，是则可以按前后余额在本地解码为转账
+func IsTransferTransaction(transaction resp.Transactions, programs []string) bool {
+	invoked := InvokedPrograms(transaction)
+	if len(invoked) == 0 {
+		return false
+	}
+	for _, program := range invoked {
+		if !slices.Contains(programs, program) {
+			return false
+		}
+	}
+	return true
+}
+
+// 系统程序指令类型(u32)
+const (
+	systemCreateAccount    = 0  // CreateAccount(lamports u64, space u64, owner)，账户: [付款方, 新账户]
+	systemTransfer         = 2  // Transfer(lamports u64)，账户: [付款方, 收款方]
+	systemTransferWithSeed = 11 // TransferWithSeed(lamports u64, ...)，账户: [付款方, 基础账户, 收款方]
+)
+
+// SPL代币程序指令类型(u8)，Token-2022 的这些指令与SPL代币程序相同
+const (
+	tokenTransfer        = 3  // Transfer(amount u64)，账户: [来源, 目标, 授权]
+	tokenMintTo          = 7  // MintTo(amount u64)，账户: [代币, 目标, 铸币授权]
+	tokenBurn            = 8  // Burn(amount u64)，账户: [来源, 代币, 授权]
+	tokenCloseAccount    = 9  // CloseAccount，账户: [代币账户, 租金接收方, 所有者]
+	tokenTransferChecked = 12 // TransferChecked(amount u64, decimals u8)，账户: [来源, 代币, 目标, 授权]
+	tokenMintToChecked   = 14 // MintToChecked(amount u64, decimals u8)，账户: [代币, 目标, 铸币授权]
+	tokenBurnChecked     = 15 // BurnChecked(amount u64, decimals u8)，账户: [来源, 代币, 授权]
+)
+
+// DecodeTransfer 解码系统程序和SPL代币程序的指令(含内部指令)，生成与Enhanced API相同结构的解析结果，不调用Enhanced API
+// 每笔转账的双方取自指令的账户，代币账户的所有者、代币地址和精度取自前后代币余额；
+// SOL转账金额为lamports，代币转账金额按精度换算，与Enhanced API一致。
+// 包含 MintTo 的交易类型为 TOKEN_MINT，包含 Burn 的为 BURN(铸造的来源账户和销毁的目标账户为空)，其余为 TRANSFER；
+// CloseAccount 只回收租金，不生成转账
+// 参数:
+//   - slot: 区块槽位
+//   - blockTime: 区块时间(Unix时间戳)
+//   - transaction: getBlock返回的交易
+//
+// 返回:
+//   - *resp.ParsedTransaction: 解析结果，执行失败或没有任何转账(如只关闭代币账户)时返回nil，由Enhanced API解析
+func DecodeTransfer(slot uint64, blockTime int64, transaction resp.Transactions) *resp.ParsedTransaction {
+	local := DecodeTransaction(slot, transaction)
+	if local.Failed {
+		return nil
+	}
+	parsed := &resp.ParsedTransaction{
+		Type:            resp.TransactionTypeTransfer,
+		Source:          resp.SourceSystemProgram,
+		Fee:             local.Fee,
+		FeePayer:        local.FeePayer,
+		Signature:       local.Signature,
+		Slot:            slot,
+		Timestamp:       blockTime,
+		NativeTransfers: []resp.NativeTransfer{},
+		TokenTransfers:  []resp.TokenTransfer{},
+		Instructions:    []resp.Instruction{},
+	}
+
+	accounts := tokenAccounts(transaction, local.AccountKeys)
+	var minted, burned bool
+	for _, instruction := range orderedInstructions(transaction) {
+		programID := accountAt(local.AccountKeys, instruction.ProgramIDIndex)
+		account := func(i int) string {
+			if i >= len(instruction.Accounts) {
+				return ""
+			}
+			return accountAt(local.AccountKeys, instruction.Accounts[i])
+		}
+		data, err := base58.Decode(instruction.Data)
+		if err != nil {
+			continue
+		}
+		switch programID {
+		case SystemProgramID:
+			if len(data) < 12 {
+				continue
+			}
+			to := account(1)
+			switch binary.LittleEndian.Uint32(data[:4]) {
+			case systemCreateAccount, systemTransfer:
+			case systemTransferWithSeed:
+				to = account(2)
+			default:
+				continue
+			}
+			if lamports := binary.LittleEndian.Uint64(data[4:12]); lamports > 0 {
+				parsed.NativeTransfers = append(parsed.NativeTransfers, resp.NativeTransfer{
+					FromUserAccount: account(0),
+					ToUserAccount:   to,
+					Amount:          int64(lamports),
+				})
+			}
+		case TokenProgramID, Token2022ProgramID:
+			if len(data) < 9 {
+				continue
+			}
+			amount := binary.LittleEndian.Uint64(data[1:9])
+			var from, to, mint string
+			switch data[0] {
+			case tokenTransfer, tokenTransferChecked:
+				from, to = account(0), account(1)
+				if data[0] == tokenTransferChecked {
+					mint, to = account(1), account(2)
+				}
+			case tokenMintTo, tokenMintToChecked:
+				mint, to = account(0), account(1)
+				minted = true
+			case tokenBurn, tokenBurnChecked:
+				from, mint = account(0), account(1)
+				burned = true
+			default:
+				continue
+			}
+			if amount == 0 {
+				continue
+			}
+			source, destination := accounts[from], accounts[to]
+			if mint == "" {
+				mint = cmp.Or(source.mint, destination.mint)
+			}
+			decimals := cmp.Or(source.decimals, destination.decimals)
+			// Checked 指令在金额之后带有精度
+			if data[0] >= tokenTransferChecked && len(data) >= 10 {
+				decimals = int(data[9])
+			}
+			parsed.TokenTransfers = append(parsed.TokenTransfers, resp.TokenTransfer{
+				FromUserAccount:  source.owner,
+				ToUserAccount:    destination.owner,
+				FromTokenAccount: from,
+				ToTokenAccount:   to,
+				TokenAmount:      decimal.NewFromBigInt(new(big.Int).SetUint64(amount), 0).Shift(int32(-decimals)),
+				Mint:             mint,
+			})
+		}
+	}
+	if len(parsed.NativeTransfers) == 0 && len(parsed.TokenTransfers) == 0 {
+		return nil
+	}
+	if len(parsed.TokenTransfers) > 0 {
+		parsed.Source = resp.SourceSolanaProgramLibrary
+	}
+	switch {
+	case minted:
+		parsed.Type = resp.TransactionTypeTokenMint
+	case burned:
+		parsed.Type = resp.TransactionTypeBurn
+	}
+	parsed.AccountData = accountData(local)
+	return parsed
+}
+
+// tokenAccount 代币账户的所有者、代币地址和精度
+type tokenAccount struct {
+	owner    string
+	mint     string
+	decimals int
+}
+
+// tokenAccounts 从前后代币余额中取出交易涉及的代币账户，交易中关闭的账户只出现在交易前余额中
+func tokenAccounts(transaction resp.Transactions, keys []string) map[string]tokenAccount {
+	accounts := make(map[string]tokenAccount)
+	for _, balance := range transaction.Meta.PreTokenBalances {
+		accounts[accountAt(keys, balance.AccountIndex)] = tokenAccount{balance.Owner, balance.Mint, balance.UITokenAmount.Decimals}
+	}
+	for _, balance := range transaction.Meta.PostTokenBalances {
+		accounts[accountAt(keys, balance.AccountIndex)] = tokenAccount{balance.Owner, balance.Mint, balance.UITokenAmount.Decimals}
+	}
+	return accounts
+}
+
+// orderedInstructions 按执行顺序返回交易的指令，每条顶层指令之后紧跟它调用的内部指令
+func orderedInstructions(transaction resp.Transactions) []resp.Instructions {
+	inner := make(map[int][]resp.Instructions, len(transaction.Meta.InnerInstructions))
+	for _, instructions := range transaction.Meta.InnerInstructions {
+		inner[instructions.Index] = append(inner[instructions.Index], instructions.Instructions...)
+	}
+	var ordered []resp.Instructions
+	for i, instruction := range transaction.Transaction.Message.Instructions {
+		ordered = append(ordered, instruction)
+		ordered = append(ordered, inner[i]...)
+	}
+	return ordered
+}
+
+// accountData 按账户列表顺序汇总各账户的SOL和代币余额变化，SOL余额变化包含手续费
+func accountData(local *LocalTransaction) []resp.AccountData {
+	data := make(map[string]*resp.AccountData)
+	var accounts []string
+	dataFor := func(account string) *resp.AccountData {
+		accountData, ok := data[account]
+		if !ok {
+			accountData = &resp.AccountData{Account: account}
+			data[account] = accountData
+			accounts = append(accounts, account)
+		}
+		return accountData
+	}
+	for _, account := range local.AccountKeys {
+		if change, ok := local.NativeBalanceChanges[account]; ok {
+			dataFor(account).NativeBalanceChange = change
+		}
+	}
+	for _, delta := range local.TokenBalanceChanges {
+		accountData := dataFor(delta.Owner)
+		accountData.TokenBalanceChanges = append(accountData.TokenBalanceChanges, resp.TokenBalanceChange{
+			UserAccount:  delta.Owner,
+			TokenAccount: delta.TokenAccount,
+			Mint:         delta.Mint,
+			RawTokenAmount: resp.RawTokenAmount{
+				TokenAmount: delta.RawAmount.String(),
+				Decimals:    delta.Decimals,
+			},
+		})
+	}
+	result := make([]resp.AccountData, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, *data[account])
+	}
+	return result
+}
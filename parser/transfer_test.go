@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/shopspring/decimal"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// 测试交易的账户列表，索引与 transferFixture 的账户一一对应
+var transferKeys = []string{
+	"payer", "alice", "bob", "aliceUSDC", "bobUSDC", "carolUSDC", "usdcMint",
+	SystemProgramID, TokenProgramID,
+}
+
+const (
+	keyPayer = iota
+	keyAlice
+	keyBob
+	keyAliceUSDC
+	keyBobUSDC
+	keyCarolUSDC
+	keyUSDCMint
+	keySystem
+	keyToken
+)
+
+// systemData 编码系统程序指令数据
+func systemData(instruction uint32, lamports uint64) string {
+	data := binary.LittleEndian.AppendUint32(nil, instruction)
+	return base58.Encode(binary.LittleEndian.AppendUint64(data, lamports))
+}
+
+// tokenData 编码代币程序指令数据，decimals 小于0时不带精度
+func tokenData(instruction byte, amount uint64, decimals int) string {
+	data := binary.LittleEndian.AppendUint64([]byte{instruction}, amount)
+	if decimals >= 0 {
+		data = append(data, byte(decimals))
+	}
+	return base58.Encode(data)
+}
+
+// tokenBalance 返回USDC代币账户的余额记录
+func tokenBalance(index int, owner string, amount int64) resp.PostTokenBalances {
+	return resp.PostTokenBalances{
+		AccountIndex:  index,
+		Mint:          "usdcMint",
+		Owner:         owner,
+		UITokenAmount: resp.UITokenAmount{Amount: decimal.NewFromInt(amount), Decimals: 6},
+	}
+}
+
+// transferFixture 构造只调用系统程序和代币程序的交易，inner 为第一条顶层指令调用的内部指令
+func transferFixture(instructions, inner []resp.Instructions, pre, post []resp.PostTokenBalances) resp.Transactions {
+	var transaction resp.Transactions
+	transaction.Transaction.Signatures = []string{"sig"}
+	transaction.Transaction.Message.AccountKeys = transferKeys
+	transaction.Transaction.Message.Instructions = instructions
+	if len(inner) > 0 {
+		transaction.Meta.InnerInstructions = []resp.InnerInstructions{{Index: 0, Instructions: inner}}
+	}
+	transaction.Meta.Fee = 5000
+	transaction.Meta.PreBalances = make([]uint64, len(transferKeys))
+	transaction.Meta.PostBalances = make([]uint64, len(transferKeys))
+	transaction.Meta.PreBalances[keyPayer] = 5000
+	for _, balance := range pre {
+		transaction.Meta.PreTokenBalances = append(transaction.Meta.PreTokenBalances, resp.PreTokenBalances(balance))
+	}
+	transaction.Meta.PostTokenBalances = post
+	return transaction
+}
+
+func TestDecodeTransferPairsByInstructionAccounts(t *testing.T) {
+	// alice 分别转给 bob 和 payer，余额变化量相同的账户无法按余额区分，必须按指令的账户配对
+	transaction := transferFixture([]resp.Instructions{
+		{ProgramIDIndex: keySystem, Accounts: []int{keyAlice, keyPayer}, Data: systemData(systemTransfer, 300)},
+		{ProgramIDIndex: keySystem, Accounts: []int{keyAlice, keyBob}, Data: systemData(systemTransfer, 200)},
+		{ProgramIDIndex: keyToken, Accounts: []int{keyAliceUSDC, keyUSDCMint, keyCarolUSDC, keyAlice}, Data: tokenData(tokenTransferChecked, 1_500_000, 6)},
+		{ProgramIDIndex: keyToken, Accounts: []int{keyBobUSDC, keyAliceUSDC, keyBob}, Data: tokenData(tokenTransfer, 2_000_000, -1)},
+	}, nil, []resp.PostTokenBalances{
+		tokenBalance(keyAliceUSDC, "alice", 10_000_000),
+		tokenBalance(keyBobUSDC, "bob", 2_000_000),
+		tokenBalance(keyCarolUSDC, "carol", 0),
+	}, []resp.PostTokenBalances{
+		tokenBalance(keyAliceUSDC, "alice", 10_500_000),
+		tokenBalance(keyBobUSDC, "bob", 0),
+		tokenBalance(keyCarolUSDC, "carol", 1_500_000),
+	})
+
+	parsed := DecodeTransfer(100, 1700000000, transaction)
+	if parsed == nil {
+		t.Fatal("转账交易应在本地解码")
+	}
+	if parsed.Type != resp.TransactionTypeTransfer || parsed.Source != resp.SourceSolanaProgramLibrary {
+		t.Fatalf("类型 %s 来源 %s", parsed.Type, parsed.Source)
+	}
+	wantNative := []resp.NativeTransfer{
+		{FromUserAccount: "alice", ToUserAccount: "payer", Amount: 300},
+		{FromUserAccount: "alice", ToUserAccount: "bob", Amount: 200},
+	}
+	if len(parsed.NativeTransfers) != len(wantNative) {
+		t.Fatalf("SOL转账: %+v", parsed.NativeTransfers)
+	}
+	for i, want := range wantNative {
+		if parsed.NativeTransfers[i] != want {
+			t.Fatalf("第 %d 笔SOL转账 %+v，期望 %+v", i, parsed.NativeTransfers[i], want)
+		}
+	}
+	wantToken := []resp.TokenTransfer{
+		{FromUserAccount: "alice", ToUserAccount: "carol", FromTokenAccount: "aliceUSDC", ToTokenAccount: "carolUSDC", TokenAmount: decimal.RequireFromString("1.5"), Mint: "usdcMint"},
+		{FromUserAccount: "bob", ToUserAccount: "alice", FromTokenAccount: "bobUSDC", ToTokenAccount: "aliceUSDC", TokenAmount: decimal.RequireFromString("2"), Mint: "usdcMint"},
+	}
+	if len(parsed.TokenTransfers) != len(wantToken) {
+		t.Fatalf("代币转账: %+v", parsed.TokenTransfers)
+	}
+	for i, want := range wantToken {
+		got := parsed.TokenTransfers[i]
+		if !got.TokenAmount.Equal(want.TokenAmount) {
+			t.Fatalf("第 %d 笔代币转账金额 %s，期望 %s", i, got.TokenAmount, want.TokenAmount)
+		}
+		got.TokenAmount = want.TokenAmount
+		if got != want {
+			t.Fatalf("第 %d 笔代币转账 %+v，期望 %+v", i, got, want)
+		}
+	}
+	if len(parsed.AccountData) == 0 {
+		t.Fatal("应汇总各账户的余额变化")
+	}
+}
+
+func TestDecodeTransferMintAndBurn(t *testing.T) {
+	tests := []struct {
+		name        string
+		instruction resp.Instructions
+		pre, post   int64
+		wantType    resp.TransactionType
+		want        resp.TokenTransfer
+	}{
+		{
+			name:        "MintTo",
+			instruction: resp.Instructions{ProgramIDIndex: keyToken, Accounts: []int{keyUSDCMint, keyBobUSDC, keyPayer}, Data: tokenData(tokenMintTo, 3_000_000, -1)},
+			pre:         0,
+			post:        3_000_000,
+			wantType:    resp.TransactionTypeTokenMint,
+			want:        resp.TokenTransfer{ToUserAccount: "bob", ToTokenAccount: "bobUSDC", TokenAmount: decimal.NewFromInt(3), Mint: "usdcMint"},
+		},
+		{
+			name:        "BurnChecked",
+			instruction: resp.Instructions{ProgramIDIndex: keyToken, Accounts: []int{keyBobUSDC, keyUSDCMint, keyBob}, Data: tokenData(tokenBurnChecked, 1_000_000, 6)},
+			pre:         3_000_000,
+			post:        2_000_000,
+			wantType:    resp.TransactionTypeBurn,
+			want:        resp.TokenTransfer{FromUserAccount: "bob", FromTokenAccount: "bobUSDC", TokenAmount: decimal.NewFromInt(1), Mint: "usdcMint"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := transferFixture([]resp.Instructions{tt.instruction}, nil,
+				[]resp.PostTokenBalances{tokenBalance(keyBobUSDC, "bob", tt.pre)},
+				[]resp.PostTokenBalances{tokenBalance(keyBobUSDC, "bob", tt.post)})
+			parsed := DecodeTransfer(100, 0, transaction)
+			if parsed == nil || parsed.Type != tt.wantType || len(parsed.TokenTransfers) != 1 {
+				t.Fatalf("解码结果: %+v", parsed)
+			}
+			got := parsed.TokenTransfers[0]
+			if !got.TokenAmount.Equal(tt.want.TokenAmount) {
+				t.Fatalf("金额 %s，期望 %s", got.TokenAmount, tt.want.TokenAmount)
+			}
+			got.TokenAmount = tt.want.TokenAmount
+			if got != tt.want {
+				t.Fatalf("代币转账 %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTransferInnerInstructions(t *testing.T) {
+	// 创建关联代币账户时由内部指令支付租金
+	transaction := transferFixture([]resp.Instructions{
+		{ProgramIDIndex: keyToken, Accounts: []int{keyPayer}, Data: base58.Encode([]byte{1})},
+	}, []resp.Instructions{
+		{ProgramIDIndex: keySystem, Accounts: []int{keyPayer, keyCarolUSDC}, Data: systemData(systemCreateAccount, 2039280)},
+	}, nil, nil)
+	parsed := DecodeTransfer(100, 0, transaction)
+	if parsed == nil || len(parsed.NativeTransfers) != 1 || parsed.Source != resp.SourceSystemProgram {
+		t.Fatalf("解码结果: %+v", parsed)
+	}
+	if transfer := parsed.NativeTransfers[0]; transfer.FromUserAccount != "payer" || transfer.ToUserAccount != "carolUSDC" || transfer.Amount != 2039280 {
+		t.Fatalf("内部指令的SOL转账: %+v", transfer)
+	}
+}
+
+func TestDecodeTransferSkipsWithoutTransfers(t *testing.T) {
+	closeOnly := transferFixture([]resp.Instructions{
+		{ProgramIDIndex: keyToken, Accounts: []int{keyBobUSDC, keyBob, keyBob}, Data: base58.Encode([]byte{tokenCloseAccount})},
+	}, nil, []resp.PostTokenBalances{tokenBalance(keyBobUSDC, "bob", 0)}, nil)
+	if parsed := DecodeTransfer(100, 0, closeOnly); parsed != nil {
+		t.Fatalf("只关闭代币账户的交易不应解码为转账: %+v", parsed)
+	}
+
+	failed := transferFixture([]resp.Instructions{
+		{ProgramIDIndex: keySystem, Accounts: []int{keyAlice, keyBob}, Data: systemData(systemTransfer, 200)},
+	}, nil, nil, nil)
+	failed.Meta.Status.Err.InstructionError = []interface{}{0, "InsufficientFunds"}
+	if parsed := DecodeTransfer(100, 0, failed); parsed != nil {
+		t.Fatalf("执行失败的交易不应解码为转账: %+v", parsed)
+	}
+}
@@ -0,0 +1,191 @@
+package payload
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+)
+
+// 错误信息中的数字(偏移量、长度等)在计算错误类别时忽略
+var digitsPattern = regexp.MustCompile(`[0-9]+`)
+
+// GlobalSampler 全局解析失败原始数据抽样
+var GlobalSampler *Sampler
+
+// group 同一类别的解析失败，样本保存在环形缓冲中
+type group struct {
+	summary models.PayloadSampleGroup
+	samples []models.PayloadSample
+	next    int // 下一个样本写入的位置
+	logged  time.Time
+}
+
+// Sampler 上游数据无法解析时，按来源和错误类别保留最近的原始数据样本，并对同一类别的错误日志限流，
+// 避免把完整的原始数据输出到日志，也避免丢失排查所需的数据
+type Sampler struct {
+	config configs.PayloadSamplesConfig
+	mu     sync.Mutex
+	groups map[string]*group
+	log    *zap.Logger
+}
+
+// NewSampler 创建解析失败原始数据抽样并设置为全局实例
+func NewSampler(config *configs.PayloadSamplesConfig) *Sampler {
+	sampler := &Sampler{
+		config: *config,
+		groups: make(map[string]*group),
+		log:    logger.Named("payload"),
+	}
+	GlobalSampler = sampler
+	return sampler
+}
+
+// Sample 记录解析失败的原始数据，未启用抽样时不做任何处理
+// 参数:
+//   - source: 数据来源，如 block、enhanced_transaction
+//   - context: 上下文，如槽位或签名，可以为空
+//   - raw: 无法解析的原始数据
+//   - err: 解析错误
+//
+// 返回:
+//   - bool: 调用方是否需要输出错误日志；未启用抽样时总是需要，启用时同一类别在 log_interval 内只需要输出一次
+func Sample(source, context string, raw []byte, err error) bool {
+	if GlobalSampler == nil {
+		return true
+	}
+	return GlobalSampler.Sample(source, context, raw, err, clock.Now())
+}
+
+// Sample 记录解析失败的原始数据，返回调用方是否需要输出错误日志
+func (s *Sampler) Sample(source, context string, raw []byte, err error, now time.Time) bool {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	id := groupID(source, message)
+
+	sample := models.PayloadSample{Time: now, Context: context, Size: len(raw)}
+	payload := raw
+	if len(payload) > s.config.MaxPayloadBytes {
+		payload = payload[:s.config.MaxPayloadBytes]
+		sample.Truncated = true
+		// 截断位置落在多字节字符中间时去掉不完整的字符
+		for i := 0; i < utf8.UTFMax && len(payload) > 0 && !utf8.Valid(payload); i++ {
+			payload = payload[:len(payload)-1]
+		}
+	}
+	sample.Payload = string(payload)
+	// 非文本数据以转义后的字符串保存
+	if !utf8.ValidString(sample.Payload) {
+		sample.Payload = fmt.Sprintf("%q", payload)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.groups[id]
+	if !ok {
+		s.evict()
+		g = &group{
+			summary: models.PayloadSampleGroup{ID: id, Source: source, FirstSeen: now},
+			samples: make([]models.PayloadSample, 0, s.config.SamplesPerGroup),
+		}
+		s.groups[id] = g
+	}
+	g.summary.Error = message
+	g.summary.Count++
+	g.summary.LastSeen = now
+	if len(g.samples) < s.config.SamplesPerGroup {
+		g.samples = append(g.samples, sample)
+	} else {
+		g.samples[g.next] = sample
+	}
+	g.next = (g.next + 1) % s.config.SamplesPerGroup
+
+	if !g.logged.IsZero() && now.Sub(g.logged) < s.config.LogInterval {
+		g.summary.Suppressed++
+		return false
+	}
+	if g.summary.Suppressed > 0 {
+		s.log.Warn("同类解析失败在限流期间未输出日志，原始数据可通过 /admin/payload-samples 查询",
+			zap.String("id", id),
+			zap.String("source", source),
+			zap.Int64("suppressed", g.summary.Suppressed),
+			zap.Int64("count", g.summary.Count))
+	}
+	g.logged = now
+	return true
+}
+
+// evict 错误类别达到上限时淘汰最久未出现的类别，调用方需持有锁
+func (s *Sampler) evict() {
+	if len(s.groups) < s.config.MaxGroups {
+		return
+	}
+	var oldest *group
+	for _, g := range s.groups {
+		if oldest == nil || g.summary.LastSeen.Before(oldest.summary.LastSeen) {
+			oldest = g
+		}
+	}
+	if oldest != nil {
+		delete(s.groups, oldest.summary.ID)
+	}
+}
+
+// Groups 返回所有错误类别，不含样本，按最近出现时间从新到旧排列
+func (s *Sampler) Groups() []models.PayloadSampleGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make([]models.PayloadSampleGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, g.summary)
+	}
+	slices.SortFunc(groups, func(a, b models.PayloadSampleGroup) int {
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+	return groups
+}
+
+// Group 返回错误类别及其样本，样本按时间从新到旧排列
+func (s *Sampler) Group(id string) (models.PayloadSampleGroup, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.groups[id]
+	if !ok {
+		return models.PayloadSampleGroup{}, false
+	}
+	summary := g.summary
+	summary.Samples = make([]models.PayloadSample, 0, len(g.samples))
+	for i := range g.samples {
+		index := (g.next - 1 - i + 2*len(g.samples)) % len(g.samples)
+		summary.Samples = append(summary.Samples, g.samples[index])
+	}
+	return summary, true
+}
+
+// Clear 删除所有错误类别和样本
+func (s *Sampler) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = make(map[string]*group)
+}
+
+// groupID 由来源和去掉数字后的错误信息计算类别ID
+func groupID(source, message string) string {
+	h := fnv.New64a()
+	h.Write([]byte(source))
+	h.Write([]byte{0})
+	h.Write([]byte(digitsPattern.ReplaceAllString(message, "N")))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
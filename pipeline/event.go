@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// EventType 定义了事件类型
+type EventType string
+
+// 定义事件类型常量
+const (
+	EventBlock        EventType = "block"        // 区块已处理，每个区块都会发布，没有需要解析的交易时签名为空
+	EventTransaction  EventType = "transaction"  // 交易已解析并通过过滤
+	EventPumpPortal   EventType = "pump_portal"  // PumpPortal推送的消息
+	EventOrderFlow    EventType = "order_flow"   // 代币买卖盘失衡快照
+	EventStall        EventType = "stall"        // 出块停滞告警或恢复
+	EventOrphaned     EventType = "orphaned"     // 已处理的槽位没有被最终确认，Signatures 为该槽位的交易签名
+	EventGraduation   EventType = "graduation"   // Pump.fun代币的联合曲线进度达到阈值，即将毕业
+	EventPoolCreated  EventType = "pool_created" // 发现新创建的流动性池
+	EventAPIKeys      EventType = "api_keys"     // 未被隔离的Enhanced API密钥数低于下限或恢复
+	EventSubscription EventType = "subscription" // WebSocket订阅长时间未收到通知或恢复
+)
+
+// Event 是向订阅者发布的事件
+type Event struct {
+	Type        EventType                         `json:"type"`                   // 事件类型
+	Slot        uint64                            `json:"slot,omitempty"`         // 区块高度，PumpPortal事件为0
+	Signature   string                            `json:"signature,omitempty"`    // 交易签名，区块事件为空
+	Signatures  []string                          `json:"signatures,omitempty"`   // 区块事件中入队的交易签名，没有需要解析的交易时为空
+	Transaction *resp.ParsedTransaction           `json:"transaction,omitempty"`  // 解析后的交易，仅交易事件
+	Block       *models.BlockStats                `json:"block,omitempty"`        // 区块统计，仅区块事件
+	MessageType resp.MessageType                  `json:"message_type,omitempty"` // PumpPortal消息类型，仅PumpPortal事件
+	Raw         json.RawMessage                   `json:"raw,omitempty"`          // 原始消息，仅PumpPortal事件
+	Mint        string                            `json:"mint,omitempty"`         // 代币地址，仅买卖盘失衡和即将毕业事件
+	OrderFlow   *models.OrderFlowSnapshot         `json:"order_flow,omitempty"`   // 买卖盘失衡快照，仅买卖盘失衡事件
+	Curve       *models.BondingCurveProgress      `json:"curve,omitempty"`        // 联合曲线进度，仅即将毕业事件
+	Pool        *models.PoolCreation              `json:"pool,omitempty"`         // 新创建的流动性池，仅池子创建事件
+	Stall       *models.StallReport               `json:"stall,omitempty"`        // 出块停滞检测结果，仅出块停滞事件
+	KeyHealth   *models.APIKeyHealthReport        `json:"key_health,omitempty"`   // 密钥健康状态，仅API密钥事件
+	Silence     *models.SubscriptionSilenceReport `json:"silence,omitempty"`      // 订阅静默检测结果，仅订阅事件
+	Time        time.Time                         `json:"time"`                   // 事件产生时间
+}
+
+// Filter 订阅过滤条件，各条件之间为"与"关系，为空的条件不参与过滤
+type Filter struct {
+	Types            []EventType              // 事件类型
+	Sources          []resp.TransactionSource // 交易来源，仅对交易事件生效
+	TransactionTypes []resp.TransactionType   // 交易类型，仅对交易事件生效
+	MessageTypes     []resp.MessageType       // PumpPortal消息类型，仅对PumpPortal事件生效
+	Match            func(Event) bool         // 自定义过滤函数
+	BufferSize       int                      // 订阅缓冲大小，0表示使用配置中的默认值
+}
+
+// Matches 判断事件是否满足过滤条件
+func (f *Filter) Matches(event Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if event.Type == EventTransaction && event.Transaction != nil {
+		if len(f.Sources) > 0 && !slices.Contains(f.Sources, event.Transaction.Source) {
+			return false
+		}
+		if len(f.TransactionTypes) > 0 && !slices.Contains(f.TransactionTypes, event.Transaction.Type) {
+			return false
+		}
+	}
+	if event.Type == EventPumpPortal && len(f.MessageTypes) > 0 && !slices.Contains(f.MessageTypes, event.MessageType) {
+		return false
+	}
+	if f.Match != nil && !f.Match(event) {
+		return false
+	}
+	return true
+}
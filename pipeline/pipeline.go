@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"go.uber.org/zap"
+)
+
+// 默认的订阅缓冲大小
+const defaultSubscriberBuffer = 1024
+
+// GlobalPipeline 全局事件管道
+var GlobalPipeline *Pipeline
+
+// Pipeline 将处理过程中产生的事件分发给进程内的订阅者
+// 每个订阅者拥有独立的有界缓冲，缓冲满时丢弃事件并计数，不会阻塞数据处理
+type Pipeline struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]*subscriber
+	nextID      uint64
+	bufferSize  int
+	log         *zap.Logger
+}
+
+// subscriber 单个订阅者
+type subscriber struct {
+	id        uint64
+	filter    Filter
+	ch        chan Event
+	createdAt time.Time
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
+// SubscriberStats 订阅者统计信息
+type SubscriberStats struct {
+	ID         uint64    `json:"id"`          // 订阅ID
+	BufferSize int       `json:"buffer_size"` // 缓冲大小
+	Pending    int       `json:"pending"`     // 缓冲中尚未消费的事件数
+	Delivered  int64     `json:"delivered"`   // 已投递的事件数
+	Dropped    int64     `json:"dropped"`     // 因缓冲已满丢弃的事件数
+	CreatedAt  time.Time `json:"created_at"`  // 订阅时间
+}
+
+// NewPipeline 创建事件管道并设置为全局管道
+func NewPipeline(config *configs.PipelineConfig) *Pipeline {
+	bufferSize := config.SubscriberBuffer
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriberBuffer
+	}
+	pipeline := &Pipeline{
+		subscribers: make(map[uint64]*subscriber),
+		bufferSize:  bufferSize,
+		log:         logger.Named("pipeline"),
+	}
+	GlobalPipeline = pipeline
+	return pipeline
+}
+
+// Subscribe 订阅满足过滤条件的事件
+// 参数:
+//   - filter: 过滤条件
+//
+// 返回:
+//   - <-chan Event: 事件通道，取消订阅后关闭
+//   - func(): 取消订阅函数，可重复调用
+func (p *Pipeline) Subscribe(filter Filter) (<-chan Event, func()) {
+	bufferSize := filter.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = p.bufferSize
+	}
+
+	p.mu.Lock()
+	p.nextID++
+	sub := &subscriber{
+		id:        p.nextID,
+		filter:    filter,
+		ch:        make(chan Event, bufferSize),
+		createdAt: clock.Now(),
+	}
+	p.subscribers[sub.id] = sub
+	p.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			delete(p.subscribers, sub.id)
+			p.mu.Unlock()
+			// 发布时持有读锁，删除后不会再有发送，此时关闭通道是安全的
+			close(sub.ch)
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Publish 将事件非阻塞地投递给所有匹配的订阅者
+func (p *Pipeline) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = clock.Now()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, sub := range p.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+			sub.delivered.Add(1)
+		default:
+			// 首次丢弃及之后每1000次记录一次日志，避免日志刷屏
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%1000 == 0 {
+				p.log.Warn("订阅者缓冲已满，丢弃事件",
+					zap.Uint64("subscriber", sub.id),
+					zap.String("type", string(event.Type)),
+					zap.Int64("dropped", dropped))
+			}
+		}
+	}
+}
+
+// Stats 返回所有订阅者的统计信息
+func (p *Pipeline) Stats() []SubscriberStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	stats := make([]SubscriberStats, 0, len(p.subscribers))
+	for _, sub := range p.subscribers {
+		stats = append(stats, SubscriberStats{
+			ID:         sub.id,
+			BufferSize: cap(sub.ch),
+			Pending:    len(sub.ch),
+			Delivered:  sub.delivered.Load(),
+			Dropped:    sub.dropped.Load(),
+			CreatedAt:  sub.createdAt,
+		})
+	}
+	slices.SortFunc(stats, func(a, b SubscriberStats) int { return cmp.Compare(a.ID, b.ID) })
+	return stats
+}
+
+// Publish 向全局管道发布事件，全局管道未初始化时忽略
+func Publish(event Event) {
+	if GlobalPipeline != nil {
+		GlobalPipeline.Publish(event)
+	}
+}
@@ -0,0 +1,272 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// 阶段状态
+const (
+	StagePending  = "pending"  // 等待依赖的阶段就绪
+	StageStarting = "starting" // 正在启动
+	StageReady    = "ready"    // 已就绪
+	StageFailed   = "failed"   // 启动失败，或依赖的阶段启动失败
+	StageDisabled = "disabled" // 已在配置中禁用，依赖它的阶段视为其已就绪
+)
+
+// 启动失败后默认的重试间隔
+const defaultStageRetryInterval = 5 * time.Second
+
+// GlobalStages 全局阶段编排器
+var GlobalStages *Stages
+
+// Stage 采集流程中的一个阶段
+type Stage struct {
+	Name      string                          // 阶段名称
+	DependsOn []string                        // 依赖的阶段，全部就绪后才启动
+	Start     func(ctx context.Context) error // 启动阶段，返回nil表示已就绪；后台任务应在返回前启动
+}
+
+// StageStatus 阶段状态
+type StageStatus struct {
+	Name      string    `json:"name"`              // 阶段名称
+	DependsOn []string  `json:"depends_on"`        // 依赖的阶段
+	State     string    `json:"state"`             // 状态
+	Attempts  int       `json:"attempts"`          // 已尝试启动的次数
+	Error     string    `json:"error,omitempty"`   // 最近一次启动失败的原因
+	ReadyAt   time.Time `json:"ready_at,omitzero"` // 就绪时间
+}
+
+// stageEntry 阶段及其运行状态
+type stageEntry struct {
+	stage  Stage
+	status StageStatus
+	done   chan struct{} // 阶段就绪、禁用或失败时关闭
+}
+
+// Stages 按依赖关系启动各阶段，阶段就绪后才启动依赖它的阶段，取代按固定时间等待的启动顺序
+type Stages struct {
+	mu      sync.Mutex
+	config  *configs.StagesConfig
+	entries []*stageEntry
+	log     *zap.Logger
+}
+
+// NewStages 创建阶段编排器并设置为全局编排器
+func NewStages(config *configs.StagesConfig) *Stages {
+	GlobalStages = &Stages{
+		config: config,
+		log:    logger.Named("pipeline.stages"),
+	}
+	return GlobalStages
+}
+
+// Add 添加阶段，需在 Run 之前调用
+func (s *Stages) Add(stage Stage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &stageEntry{
+		stage:  stage,
+		status: StageStatus{Name: stage.Name, DependsOn: stage.DependsOn, State: StagePending},
+		done:   make(chan struct{}),
+	})
+}
+
+// Run 启动所有阶段，阻塞直到每个阶段都已就绪、禁用或失败
+// 参数:
+//   - ctx: 上下文，取消后不再启动或重试
+//
+// 返回:
+//   - error: 依赖关系无效，或有阶段启动失败时的错误信息
+func (s *Stages) Run(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, entry := range s.entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(entry.done)
+			s.run(ctx, entry)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, status := range s.Status() {
+		if status.State == StageFailed {
+			errs = append(errs, fmt.Errorf("阶段 %s 启动失败: %s", status.Name, status.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validate 检查阶段名称唯一、依赖存在且没有循环依赖
+func (s *Stages) validate() error {
+	byName := make(map[string]*stageEntry, len(s.entries))
+	for _, entry := range s.entries {
+		if _, ok := byName[entry.stage.Name]; ok {
+			return fmt.Errorf("阶段名称重复: %s", entry.stage.Name)
+		}
+		byName[entry.stage.Name] = entry
+	}
+	// 0: 未访问, 1: 访问中, 2: 已完成
+	visited := make(map[string]int, len(s.entries))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch visited[name] {
+		case 1:
+			return fmt.Errorf("阶段存在循环依赖: %v", append(path, name))
+		case 2:
+			return nil
+		}
+		visited[name] = 1
+		for _, dependency := range byName[name].stage.DependsOn {
+			if _, ok := byName[dependency]; !ok {
+				return fmt.Errorf("阶段 %s 依赖的阶段不存在: %s", name, dependency)
+			}
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		visited[name] = 2
+		return nil
+	}
+	for _, entry := range s.entries {
+		if err := visit(entry.stage.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run 等待依赖就绪后启动阶段，启动失败时按配置重试
+func (s *Stages) run(ctx context.Context, entry *stageEntry) {
+	name := entry.stage.Name
+	if slices.Contains(s.config.Disabled, name) {
+		s.setState(entry, StageDisabled, nil)
+		s.log.Info("阶段已禁用", zap.String("stage", name))
+		return
+	}
+
+	for _, dependency := range entry.stage.DependsOn {
+		dependencyEntry := s.entry(dependency)
+		select {
+		case <-dependencyEntry.done:
+		case <-ctx.Done():
+			s.setState(entry, StageFailed, ctx.Err())
+			return
+		}
+		if state := s.state(dependencyEntry); state == StageFailed {
+			s.setState(entry, StageFailed, fmt.Errorf("依赖的阶段 %s 启动失败", dependency))
+			return
+		}
+	}
+
+	retryInterval := s.config.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultStageRetryInterval
+	}
+	for attempt := 1; ; attempt++ {
+		s.setState(entry, StageStarting, nil)
+		err := s.start(ctx, entry)
+		if err == nil {
+			s.setState(entry, StageReady, nil)
+			s.log.Info("阶段已就绪", zap.String("stage", name), zap.Int("attempts", attempt))
+			return
+		}
+		if attempt > s.config.StartRetries || ctx.Err() != nil {
+			s.setState(entry, StageFailed, err)
+			s.log.Error("阶段启动失败", zap.String("stage", name), zap.Int("attempts", attempt), zap.Error(err))
+			return
+		}
+		s.log.Warn("阶段启动失败，等待重试", zap.String("stage", name), zap.Int("attempt", attempt),
+			zap.Duration("retry_interval", retryInterval), zap.Error(err))
+		select {
+		case <-clock.After(retryInterval):
+		case <-ctx.Done():
+			s.setState(entry, StageFailed, ctx.Err())
+			return
+		}
+	}
+}
+
+// start 启动一次阶段，设置了启动超时时超时视为失败
+func (s *Stages) start(ctx context.Context, entry *stageEntry) error {
+	s.mu.Lock()
+	entry.status.Attempts++
+	s.mu.Unlock()
+	if s.config.StartTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.StartTimeout)
+		defer cancel()
+	}
+	return entry.stage.Start(ctx)
+}
+
+// Ready 返回阶段结束启动时关闭的通道，此后可通过 Status 查看阶段是否就绪；阶段不存在时返回nil
+func (s *Stages) Ready(name string) <-chan struct{} {
+	if entry := s.entry(name); entry != nil {
+		return entry.done
+	}
+	return nil
+}
+
+// Status 返回各阶段的状态，按添加顺序排列
+func (s *Stages) Status() []StageStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]StageStatus, 0, len(s.entries))
+	for _, entry := range s.entries {
+		statuses = append(statuses, entry.status)
+	}
+	return statuses
+}
+
+// entry 按名称查找阶段
+func (s *Stages) entry(name string) *stageEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		if entry.stage.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// state 返回阶段当前的状态
+func (s *Stages) state(entry *stageEntry) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return entry.status.State
+}
+
+// setState 更新阶段状态，err 不为nil时记录失败原因
+func (s *Stages) setState(entry *stageEntry, state string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.status.State = state
+	if err != nil {
+		entry.status.Error = err.Error()
+	}
+	if state == StageReady {
+		entry.status.Error = ""
+		entry.status.ReadyAt = clock.Now()
+	}
+}
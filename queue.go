@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/api"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+)
+
+// newQueueCommand 队列运维命令
+func newQueueCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "队列运维命令",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stats",
+		Short: "查看内存队列、Redis队列和死信队列长度",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueueStats()
+		},
+	})
+	return cmd
+}
+
+// runQueueStats 输出队列统计
+// 内存队列位于运行中的服务进程内，通过管理接口获取；Redis队列和死信队列直接从Redis读取
+func runQueueStats() error {
+	loadConfig()
+	storage.NewRedisClient(&configs.GlobalConfig.Redis)
+	defer storage.CloseRedisClients()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "队列\t长度")
+	fmt.Fprintln(w, "----\t----")
+
+	if stats, err := fetchMemoryQueueStats(ctx); err != nil {
+		fmt.Fprintf(w, "内存区块队列\t- (%v)\n", err)
+		fmt.Fprintf(w, "内存交易队列\t- (%v)\n", err)
+	} else {
+		fmt.Fprintf(w, "内存区块队列\t%d\n", stats.Block)
+		fmt.Fprintf(w, "内存交易队列\t%d\n", stats.Transaction)
+	}
+
+	queueRedis := storage.GetRedisClient(storage.WorkloadQueue)
+	if length, err := queueRedis.GetTransactionQueueLength(ctx); err != nil {
+		fmt.Fprintf(w, "Redis交易队列\t- (%v)\n", err)
+	} else {
+		fmt.Fprintf(w, "Redis交易队列\t%d\n", length)
+	}
+	if length, err := queueRedis.GetPendingTransactionsLength(ctx); err != nil {
+		fmt.Fprintf(w, "待载入交易队列\t- (%v)\n", err)
+	} else {
+		fmt.Fprintf(w, "待载入交易队列\t%d\n", length)
+	}
+	for _, queue := range []string{"block", "transaction"} {
+		if length, err := queueRedis.GetDeadLetterLength(ctx, queue); err != nil {
+			fmt.Fprintf(w, "死信队列 %s\t- (%v)\n", queue, err)
+		} else {
+			fmt.Fprintf(w, "死信队列 %s\t%d\n", queue, length)
+		}
+	}
+	return w.Flush()
+}
+
+// fetchMemoryQueueStats 通过管理接口获取运行中服务的内存队列长度
+func fetchMemoryQueueStats(ctx context.Context) (*api.QueueStats, error) {
+	adminConfig := configs.GlobalConfig.Admin
+	if !adminConfig.Enabled {
+		return nil, fmt.Errorf("未启用管理接口")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+adminConfig.Addr+"/admin/queue/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("服务未运行或管理接口不可达")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("管理接口返回状态码 %d", resp.StatusCode)
+	}
+	var stats api.QueueStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("解析管理接口响应失败: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// Solana RPC 和 Helius 的错误码
+const (
+	errCodeBlockCleanedUp             = -32001 // 区块已被清理，节点不再保存
+	errCodeBlockNotAvailable          = -32004 // 区块尚不可用
+	errCodeSlotSkipped                = -32007 // 槽位被跳过，或因节点从快照启动而缺失
+	errCodeLongTermStorageSlotSkipped = -32009 // 槽位被跳过，或在长期存储中缺失
+	errCodeBlockStatusNotAvailableYet = -32014 // 区块状态尚不可用
+	errCodeRateLimited                = -32429 // Helius 限流
+)
+
+// rpc 包返回的错误分类，调用方用 errors.Is 判断后决定重试、跳过或放弃
+var (
+	// ErrSlotSkipped 槽位被跳过，没有区块，重试也不会成功
+	ErrSlotSkipped = errors.New("槽位被跳过")
+	// ErrSlotNotFound 区块暂时不可用或节点不再保存，稍后重试可能成功
+	ErrSlotNotFound = errors.New("区块不可用")
+	// ErrUnauthorized API密钥无效或无权访问，换用其他密钥前重试不会成功
+	ErrUnauthorized = errors.New("API密钥无效或无权访问")
+	// ErrRateLimited 请求被限流，可通过 RetryAfter 获取服务端要求的等待时间
+	ErrRateLimited = errors.New("请求被限流")
+	// ErrNetwork 发送请求或读取响应时的网络错误
+	ErrNetwork = errors.New("网络错误")
+)
+
+// RateLimitError 请求被限流，errors.Is(err, ErrRateLimited) 为true
+type RateLimitError struct {
+	RetryAfter time.Duration // 服务端通过 Retry-After 要求的等待时间，未给出时为0
+	Message    string        // 服务端返回的错误信息
+}
+
+func (e *RateLimitError) Error() string {
+	message := ErrRateLimited.Error()
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if e.RetryAfter > 0 {
+		message += fmt.Sprintf(" (%s后重试)", e.RetryAfter)
+	}
+	return message
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RPCError WebSocket请求被服务端拒绝时返回的JSON-RPC错误，限流时 errors.Is(err, ErrRateLimited) 为true
+type RPCError struct {
+	Method  string // 请求的方法
+	Code    int    // 错误码
+	Message string // 错误信息
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s 返回错误: 代码=%d, 消息=%s", e.Method, e.Code, e.Message)
+}
+
+func (e *RPCError) Unwrap() error {
+	if e.Code == errCodeRateLimited {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// RetryAfter 返回限流错误中服务端要求的等待时间，不是限流错误或服务端未给出时返回false
+func RetryAfter(err error) (time.Duration, bool) {
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter <= 0 {
+		return 0, false
+	}
+	return rateLimitErr.RetryAfter, true
+}
+
+// responseError 将JSON-RPC响应中的错误转换为对应的错误类型，没有错误时返回nil
+func responseError(response *resp.HeliusResponse) error {
+	if response.Error == nil {
+		return nil
+	}
+	switch response.Error.Code {
+	case errCodeSlotSkipped, errCodeLongTermStorageSlotSkipped:
+		return fmt.Errorf("%w: 代码=%d, 消息=%s", ErrSlotSkipped, response.Error.Code, response.Error.Message)
+	case errCodeBlockCleanedUp, errCodeBlockNotAvailable, errCodeBlockStatusNotAvailableYet:
+		return fmt.Errorf("%w: 代码=%d, 消息=%s", ErrSlotNotFound, response.Error.Code, response.Error.Message)
+	case errCodeRateLimited:
+		return &RateLimitError{Message: response.Error.Message}
+	}
+	return fmt.Errorf("API返回错误: 代码=%d, 消息=%s", response.Error.Code, response.Error.Message)
+}
+
+// rejectionError 请求因限流或认证失败被拒绝时返回对应的错误，这类响应的响应体不一定是约定的格式
+func rejectionError(httpResp *http.Response, body []byte) error {
+	switch httpResp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusUnauthorized, http.StatusForbidden:
+		return statusError(httpResp, body)
+	}
+	return nil
+}
+
+// statusError 将非2xx的HTTP响应转换为对应的错误，2xx时返回nil
+// 429 返回 RateLimitError，401/403 包装 ErrUnauthorized，其他状态码只携带错误信息
+func statusError(httpResp *http.Response, body []byte) error {
+	if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+		return nil
+	}
+	message := errorMessage(body)
+	switch httpResp.StatusCode {
+	case http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After")), Message: message}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s (状态码: %d)", ErrUnauthorized, message, httpResp.StatusCode)
+	}
+	if message != "" {
+		return fmt.Errorf("API 返回错误: %s (状态码: %d)", message, httpResp.StatusCode)
+	}
+	return fmt.Errorf("API 请求失败，状态码: %d, 响应: %s", httpResp.StatusCode, string(body))
+}
+
+// errorMessage 从错误响应体中取出错误信息，支持 {"message": ...}、{"error": "..."} 和JSON-RPC的 {"error": {"message": ...}}
+func errorMessage(body []byte) string {
+	var errorResp struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err != nil {
+		return ""
+	}
+	message := errorResp.Message
+	var text string
+	var object struct {
+		Message string `json:"message"`
+	}
+	switch {
+	case json.Unmarshal(errorResp.Error, &text) == nil:
+		message += text
+	case json.Unmarshal(errorResp.Error, &object) == nil:
+		message += object.Message
+	}
+	return message
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期，无法解析时返回0
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(clock.Now()), 0)
+	}
+	return 0
+}
@@ -8,7 +8,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"time"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/life2you/datas-go/models/req"
 	"github.com/life2you/datas-go/models/resp"
@@ -17,12 +18,17 @@ import (
 
 	"github.com/life2you/datas-go/configs"
 	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
 // HeliusClient 表示 Helius HTTP API 客户端
 type HeliusApiClient struct {
 	httpClient *http.Client
+	httpConfig configs.HTTPClientConfig
 	endpoint   string
 	apiKey     string
 	proxyURL   string
@@ -36,26 +42,15 @@ func NewHeliusClient(config *configs.HeliusAPIConfig) *HeliusApiClient {
 	baseURL := config.Endpoint
 	apiKey := config.APIKey
 
-	// 创建一个带有超时设置的 HTTP 客户端
-	httpClient := &http.Client{
-		Timeout: 120 * time.Second,
-	}
-
-	// 如果配置了代理，设置代理
+	// 按配置创建 HTTP 客户端，设置超时、连接池和代理
+	httpClient := NewHTTPClient(&config.HTTP, config.ProxyURL)
 	if config.ProxyURL != "" {
-		proxyURL, err := url.Parse(config.ProxyURL)
-		if err != nil {
-			logger.Error("解析代理URL失败", zap.Error(err))
-		} else {
-			httpClient.Transport = &http.Transport{
-				Proxy: http.ProxyURL(proxyURL),
-			}
-			logger.Info("Helius HTTP API 客户端将使用代理", zap.String("proxy", config.ProxyURL))
-		}
+		logger.Info("Helius HTTP API 客户端将使用代理", zap.String("proxy", config.ProxyURL))
 	}
 
 	client := &HeliusApiClient{
 		httpClient: httpClient,
+		httpConfig: config.HTTP,
 		endpoint:   baseURL,
 		apiKey:     apiKey,
 		proxyURL:   config.ProxyURL,
@@ -73,21 +68,18 @@ func (c *HeliusApiClient) SetProxyURL(proxyURLStr string) error {
 		return nil
 	}
 
-	proxyURL, err := url.Parse(proxyURLStr)
-	if err != nil {
+	if _, err := url.Parse(proxyURLStr); err != nil {
 		return fmt.Errorf("解析代理URL失败: %w", err)
 	}
 
 	c.proxyURL = proxyURLStr
-	c.httpClient.Transport = &http.Transport{
-		Proxy: http.ProxyURL(proxyURL),
-	}
+	c.httpClient.Transport = newHTTPTransport(&c.httpConfig, proxyURLStr)
 
 	return nil
 }
 
-// 发送 HTTP 请求到 Helius API
-func (c *HeliusApiClient) makeRequest(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+// 发送 HTTP 请求到 Helius API，params 通常为数组，DAS 方法使用对象
+func (c *HeliusApiClient) makeRequest(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
 	// 构建请求 URL（添加 API 密钥）
 	requestURL := fmt.Sprintf("%s/?api-key=%s", c.endpoint, c.apiKey)
 
@@ -113,27 +105,35 @@ func (c *HeliusApiClient) makeRequest(ctx context.Context, method string, params
 	req.Header.Set("Content-Type", "application/json")
 
 	// 发送请求
+	metrics.IncRPCRequests()
 	respJson, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("发送HTTP请求失败: %w", err)
+		return nil, fmt.Errorf("%w: 发送HTTP请求失败: %w", ErrNetwork, redactError(err))
 	}
 	defer respJson.Body.Close()
 
 	// 读取响应体
 	respBody, err := io.ReadAll(respJson.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %w", err)
+		return nil, fmt.Errorf("%w: 读取响应失败: %w", ErrNetwork, err)
+	}
+	if err := rejectionError(respJson, respBody); err != nil {
+		return nil, err
 	}
 
 	// 解析响应
 	var response resp.HeliusResponse
 	if err := json.Unmarshal(respBody, &response); err != nil {
+		if statusErr := statusError(respJson, respBody); statusErr != nil {
+			return nil, statusErr
+		}
+		payload.Sample("rpc_response", method, respBody, err)
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	// 检查错误
-	if response.Error != nil {
-		return nil, fmt.Errorf("API返回错误: 代码=%d, 消息=%s", response.Error.Code, response.Error.Message)
+	if err := responseError(&response); err != nil {
+		return nil, err
 	}
 
 	return response.Result, nil
@@ -141,19 +141,8 @@ func (c *HeliusApiClient) makeRequest(ctx context.Context, method string, params
 
 // GetBlock 获取指定槽位的区块数据
 func (c *HeliusApiClient) GetBlock(ctx context.Context, slot uint64, params *req.GetBlockParams) (json.RawMessage, error) {
-	//如果没有提供参数，使用默认参数
-	if params == nil {
-		// 默认不包含奖励信息，减少响应大小
-		params = &req.GetBlockParams{
-			Encoding:                       "json",
-			TransactionDetails:             "full",
-			MaxSupportedTransactionVersion: 0,
-			Commitment:                     "finalized",
-		}
-	}
-
 	// 构建请求参数
-	requestParams := []interface{}{slot, params}
+	requestParams := []interface{}{slot, defaultGetBlockParams(params)}
 
 	// 发送请求
 	logger.Debug("请求区块数据", zap.Uint64("slot", slot))
@@ -166,11 +155,99 @@ func (c *HeliusApiClient) GetBlock(ctx context.Context, slot uint64, params *req
 	return result, nil
 }
 
+// defaultGetBlockParams 没有提供参数时使用默认参数：完整交易、finalized承诺级别
+func defaultGetBlockParams(params *req.GetBlockParams) *req.GetBlockParams {
+	if params != nil {
+		return params
+	}
+	return &req.GetBlockParams{
+		Encoding:                       "json",
+		TransactionDetails:             "full",
+		MaxSupportedTransactionVersion: 0,
+		Commitment:                     "finalized",
+	}
+}
+
+// GetSlot 获取节点当前的槽位，commitment 为空时使用节点默认的承诺级别
+func (c *HeliusApiClient) GetSlot(ctx context.Context, commitment string) (uint64, error) {
+	params := []interface{}{}
+	if commitment != "" {
+		params = []interface{}{map[string]string{"commitment": commitment}}
+	}
+	result, err := c.makeRequest(ctx, "getSlot", params)
+	if err != nil {
+		return 0, fmt.Errorf("获取槽位失败: %w", err)
+	}
+	var slot uint64
+	if err := json.Unmarshal(result, &slot); err != nil {
+		return 0, fmt.Errorf("解析槽位失败: %w", err)
+	}
+	return slot, nil
+}
+
+// GetTransaction 获取指定签名的原始交易数据
+func (c *HeliusApiClient) GetTransaction(ctx context.Context, signature string, params *req.GetTransactionParams) (json.RawMessage, error) {
+	// 构建请求参数
+	requestParams := []interface{}{signature, defaultGetTransactionParams(params)}
+
+	// 发送请求
+	logger.Debug("请求交易数据", zap.String("signature", signature))
+	result, err := c.makeRequest(ctx, "getTransaction", requestParams)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易数据失败 (signature=%s): %w", signature, err)
+	}
+
+	return result, nil
+}
+
+// SendTransaction 发送已签名的交易
+// 参数:
+//   - ctx: 上下文
+//   - transaction: base64编码的已签名交易
+//   - skipPreflight: 是否跳过预检(模拟执行)
+//
+// 返回:
+//   - string: 交易签名
+//   - error: 错误信息
+func (c *HeliusApiClient) SendTransaction(ctx context.Context, transaction string, skipPreflight bool) (string, error) {
+	requestParams := []interface{}{transaction, map[string]interface{}{
+		"encoding":            "base64",
+		"skipPreflight":       skipPreflight,
+		"preflightCommitment": "confirmed",
+	}}
+	result, err := c.makeRequest(ctx, "sendTransaction", requestParams)
+	if err != nil {
+		return "", fmt.Errorf("发送交易失败: %w", err)
+	}
+	var signature string
+	if err := json.Unmarshal(result, &signature); err != nil {
+		return "", fmt.Errorf("解析交易签名失败: %w", err)
+	}
+	return signature, nil
+}
+
+// defaultGetTransactionParams 没有提供参数时使用默认参数
+func defaultGetTransactionParams(params *req.GetTransactionParams) *req.GetTransactionParams {
+	if params != nil {
+		return params
+	}
+	return &req.GetTransactionParams{
+		Encoding:                       "json",
+		MaxSupportedTransactionVersion: 0,
+		Commitment:                     "finalized",
+	}
+}
+
 type HeliusEnhancedApiClient struct {
-	apiKey     string
-	httpClient *http.Client
-	endpoint   string
-	proxyURL   string
+	apiKey      string
+	index       int    // 密钥在 api_keys 中的索引
+	keyID       string // 密钥指纹
+	httpClient  *http.Client
+	endpoint    string
+	proxyURL    string
+	auth        configs.APIAuthConfig // API密钥的传递方式
+	commitment  string                // 默认的解析确认级别，为空时使用服务端默认的 finalized
+	keyRejected atomic.Bool           // 最近一次请求是否因API密钥无效被拒绝
 }
 
 // 全局增强API客户端池
@@ -186,19 +263,26 @@ type ParseTransactionsResponse struct {
 	EnrichedTransactions []json.RawMessage `json:"enriched_transactions"`
 }
 
+// ParseOptions 解析交易的请求选项
+type ParseOptions struct {
+	Commitment string // 确认级别: confirmed 或 finalized，为空时使用 helius_enhanced_api.commitment
+}
+
 // NewHeliusEnhancedApiClient 创建一个新的Helius Enhanced API客户端池
 func NewHeliusEnhancedApiClient(config *configs.HeliusEnhancedAPIConfig) {
-	httpClient := &http.Client{
-		Timeout: 120 * time.Second,
-	}
+	httpClient := NewHTTPClient(&config.HTTP, config.ProxyURL)
 	// 处理多个API key
 	if len(config.APIKeys) > 0 {
 		for i, apiKey := range config.APIKeys {
 			client := &HeliusEnhancedApiClient{
 				apiKey:     apiKey,
+				index:      i,
+				keyID:      KeyID(apiKey),
 				httpClient: httpClient,
 				endpoint:   config.Endpoint,
 				proxyURL:   config.ProxyURL,
+				auth:       config.Auth,
+				commitment: config.Commitment,
 			}
 			GlobalHeliusEnhancedApiClients = append(GlobalHeliusEnhancedApiClients, client)
 			logger.Info("创建Helius增强API客户端", zap.Int("索引", i), zap.String("endpoint", config.Endpoint))
@@ -221,18 +305,26 @@ func GetEnhancedApiClientByIndex(index int) *HeliusEnhancedApiClient {
 // ParseTransactions 解析一个或多个交易并返回人类可读的结构化数据
 // 参数:
 //   - ctx: 上下文
+//   - options: 请求选项，为nil时使用配置的默认值
 //   - signatures: 一个或多个交易签名
 //
 // 返回:
 //   - []ParsedTransaction: 解析后的交易数据
 //   - error: 错误信息
-func (c *HeliusEnhancedApiClient) ParseTransactions(ctx context.Context, signatures ...string) ([]byte, error) {
+func (c *HeliusEnhancedApiClient) ParseTransactions(ctx context.Context, options *ParseOptions, signatures ...string) ([]byte, error) {
 	if len(signatures) == 0 {
 		return nil, fmt.Errorf("至少需要提供一个交易签名")
 	}
 
-	// 构建 Enhanced Transactions API 的 URL
-	apiURL := fmt.Sprintf("%s/v0/transactions?api-key=%s", c.endpoint, c.apiKey)
+	// 构建 Enhanced Transactions API 的 URL，confirmed 级别可以更早拿到解析结果，但交易仍可能被回滚
+	apiURL := c.endpoint + "/v0/transactions"
+	commitment := c.commitment
+	if options != nil && options.Commitment != "" {
+		commitment = options.Commitment
+	}
+	if commitment != "" {
+		apiURL += "?" + url.Values{"commitment": {commitment}}.Encode()
+	}
 
 	// 构建请求体
 	requestBody := ParseTransactionsRequest{
@@ -246,7 +338,10 @@ func (c *HeliusEnhancedApiClient) ParseTransactions(ctx context.Context, signatu
 	}
 
 	// 使用 Authorization 头发送请求
-	respBody, err := c.makeRequestWithAuth(ctx, "POST", apiURL, requestJSON)
+	ctx, span := tracing.Start(ctx, "enhanced.parse",
+		attribute.Int("signatures", len(signatures)), attribute.String("commitment", commitment))
+	respBody, err := c.makeRequestWithAuth(ctx, "POST", apiURL, requestJSON, len(signatures))
+	tracing.End(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("解析交易失败: %w", err)
 	}
@@ -254,8 +349,98 @@ func (c *HeliusEnhancedApiClient) ParseTransactions(ctx context.Context, signatu
 	return respBody, nil
 }
 
-// 添加 Authorization 支持
-func (c *HeliusEnhancedApiClient) makeRequestWithAuth(ctx context.Context, method string, endpoint string, requestJSON []byte) ([]byte, error) {
+// 查询地址历史交易时 Helius 默认返回的交易数
+const defaultAddressTransactionsLimit = 100
+
+// AddressTransactionsParams 查询地址历史交易的参数
+type AddressTransactionsParams struct {
+	Before string // 从该签名之前开始查询，为空时从最新的交易开始
+	Limit  int    // 返回的最大交易数，Helius 最多支持100，0表示使用默认值
+	Type   string // 只返回指定类型的交易，如 SWAP，为空时返回所有类型
+}
+
+// GetAddressTransactions 按时间倒序查询地址的历史交易，返回结构与 ParseTransactions 相同
+// 与 ParseTransactions 按签名数计入用量一致，每次请求按请求的交易数计入密钥用量
+// 参数:
+//   - ctx: 上下文
+//   - address: 账户地址
+//   - params: 查询参数
+//
+// 返回:
+//   - []byte: 解析后的交易数组
+//   - error: 错误信息
+func (c *HeliusEnhancedApiClient) GetAddressTransactions(ctx context.Context, address string, params AddressTransactionsParams) ([]byte, error) {
+	if address == "" {
+		return nil, fmt.Errorf("地址不能为空")
+	}
+
+	query := url.Values{}
+	if params.Before != "" {
+		query.Set("before", params.Before)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Type != "" {
+		query.Set("type", params.Type)
+	}
+	apiURL := c.endpoint + "/v0/addresses/" + url.PathEscape(address) + "/transactions"
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+
+	credits := params.Limit
+	if credits <= 0 {
+		credits = defaultAddressTransactionsLimit
+	}
+	respBody, err := c.makeRequestWithAuth(ctx, http.MethodGet, apiURL, nil, credits)
+	if err != nil {
+		return nil, fmt.Errorf("查询地址历史交易失败: %w", err)
+	}
+	return respBody, nil
+}
+
+// 未配置认证方式时同时使用查询参数和 Basic 认证，与 Helius 的默认行为一致
+var defaultAuthModes = []string{"query", "basic"}
+
+// authorize 按配置的认证方式在请求中携带 API 密钥，未设置密钥时不做任何处理
+func (c *HeliusEnhancedApiClient) authorize(req *http.Request) {
+	if c.apiKey == "" {
+		return
+	}
+	modes := c.auth.Modes
+	if len(modes) == 0 {
+		modes = defaultAuthModes
+	}
+	queryParam := c.auth.QueryParam
+	if queryParam == "" {
+		queryParam = "api-key"
+	}
+	for _, mode := range modes {
+		switch mode {
+		case "query":
+			query := req.URL.Query()
+			query.Set(queryParam, c.apiKey)
+			req.URL.RawQuery = query.Encode()
+		case "basic":
+			// 在 Helius API 中，用户名是 API 密钥，密码可以为空
+			auth := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":"))
+			req.Header.Set("Authorization", "Basic "+auth)
+		case "bearer":
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		case "header":
+			req.Header.Set(c.auth.Header, c.auth.HeaderPrefix+c.apiKey)
+		}
+	}
+}
+
+// KeyRejected 返回最近一次请求是否因API密钥无效被拒绝
+func (c *HeliusEnhancedApiClient) KeyRejected() bool {
+	return c.keyRejected.Load()
+}
+
+// 按配置的认证方式携带 API 密钥发送请求，成功时按 credits 记录消耗的额度
+func (c *HeliusEnhancedApiClient) makeRequestWithAuth(ctx context.Context, method string, endpoint string, requestJSON []byte, credits int) ([]byte, error) {
 	// 创建 HTTP 请求
 	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(requestJSON))
 	if err != nil {
@@ -264,38 +449,40 @@ func (c *HeliusEnhancedApiClient) makeRequestWithAuth(ctx context.Context, metho
 
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
-
-	// 如果设置了 API 密钥，添加 Authorization 头
-	if c.apiKey != "" {
-		// 创建 Basic Auth 字符串 (username:password)
-		// 在 Helius API 中，用户名是 API 密钥，密码可以为空
-		auth := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":"))
-		req.Header.Set("Authorization", "Basic "+auth)
-	}
+	c.authorize(req)
 
 	// 发送请求
+	metrics.IncEnhancedRequests()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("发送 HTTP 请求失败: %w", err)
+		c.recordUsage(0, "network")
+		return nil, fmt.Errorf("%w: 发送 HTTP 请求失败: %w", ErrNetwork, redactError(err))
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %w", err)
+		c.recordUsage(0, "network")
+		return nil, fmt.Errorf("%w: 读取响应失败: %w", ErrNetwork, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		c.recordUsage(credits, "")
+	} else {
+		c.recordUsage(0, strconv.Itoa(resp.StatusCode))
+	}
+
+	// 401/403 表示API密钥无效或已被禁用，其他状态码不影响密钥状态
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.keyRejected.Store(false)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		c.keyRejected.Store(true)
 	}
 
 	// 检查 HTTP 状态码
-	if resp.StatusCode != http.StatusOK {
-		// 尝试解析错误信息
-		var errorResp struct {
-			Message string `json:"message"`
-		}
-		if err := json.Unmarshal(respBody, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, fmt.Errorf("API 返回错误: %s (状态码: %d)", errorResp.Message, resp.StatusCode)
-		}
-		return nil, fmt.Errorf("API 请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	if err := statusError(resp, respBody); err != nil {
+		return nil, err
 	}
 
 	return respBody, nil
@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/models/req"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/payload"
+	"go.uber.org/zap"
+)
+
+// BatchRequest JSON-RPC批量请求中的一个调用
+type BatchRequest struct {
+	Method string        // 方法名，如 getBlock
+	Params []interface{} // 参数
+}
+
+// BatchResult JSON-RPC批量请求中一个调用的结果，与请求按下标一一对应
+type BatchResult struct {
+	Result json.RawMessage // 调用结果
+	Err    error           // 调用返回的错误，槽位被跳过时可用 errors.Is(err, ErrSlotSkipped) 等判断
+}
+
+// batchRequestBody JSON-RPC批量请求中单个调用的请求体
+type batchRequestBody struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// Batch 在一次HTTP请求中发送多个JSON-RPC调用
+// 参数:
+//   - ctx: 上下文
+//   - requests: 调用列表，以下标作为请求ID
+//
+// 返回:
+//   - []BatchResult: 与请求一一对应的结果，单个调用的错误记录在 BatchResult.Err 中
+//   - error: 整个请求失败时的错误信息
+func (c *HeliusApiClient) Batch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	requestURL := fmt.Sprintf("%s/?api-key=%s", c.endpoint, c.apiKey)
+
+	body := make([]batchRequestBody, len(requests))
+	for i, request := range requests {
+		body[i] = batchRequestBody{JSONRPC: "2.0", ID: i, Method: request.Method, Params: request.Params}
+	}
+	requestJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化批量请求失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	metrics.IncRPCRequests()
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 发送HTTP请求失败: %w", ErrNetwork, redactError(err))
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取响应失败: %w", ErrNetwork, err)
+	}
+	if err := rejectionError(httpResp, respBody); err != nil {
+		return nil, err
+	}
+
+	// 整个请求被拒绝(如限流)时，节点返回单个错误对象而不是数组
+	var responses []resp.HeliusResponse
+	if err := json.Unmarshal(respBody, &responses); err != nil {
+		var response resp.HeliusResponse
+		if json.Unmarshal(respBody, &response) == nil && response.Error != nil {
+			return nil, responseError(&response)
+		}
+		if statusErr := statusError(httpResp, respBody); statusErr != nil {
+			return nil, statusErr
+		}
+		payload.Sample("rpc_response", "batch", respBody, err)
+		return nil, fmt.Errorf("解析批量响应失败: %w", err)
+	}
+
+	// 响应的顺序不保证与请求一致，按ID对应
+	results := make([]BatchResult, len(requests))
+	answered := make([]bool, len(requests))
+	for i := range responses {
+		number, ok := responses[i].ID.(float64)
+		id := int(number)
+		if !ok || float64(id) != number || id < 0 || id >= len(requests) {
+			logger.Warn("批量响应的ID无效，已忽略", zap.Any("id", responses[i].ID))
+			continue
+		}
+		answered[id] = true
+		if err := responseError(&responses[i]); err != nil {
+			results[id].Err = err
+			continue
+		}
+		results[id].Result = responses[i].Result
+	}
+	for i := range results {
+		if !answered[i] {
+			results[i].Err = errors.New("批量响应中缺少该请求的结果")
+		}
+	}
+	return results, nil
+}
+
+// BatchGetBlocks 在一次HTTP请求中获取多个槽位的区块数据
+// 参数:
+//   - ctx: 上下文
+//   - slots: 槽位列表
+//   - params: getBlock参数，为nil时使用与 GetBlock 相同的默认参数
+//
+// 返回:
+//   - []BatchResult: 与槽位一一对应的区块数据，槽位被跳过时 Err 包装 ErrSlotSkipped
+//   - error: 整个请求失败时的错误信息
+func (c *HeliusApiClient) BatchGetBlocks(ctx context.Context, slots []uint64, params *req.GetBlockParams) ([]BatchResult, error) {
+	params = defaultGetBlockParams(params)
+	requests := make([]BatchRequest, len(slots))
+	for i, slot := range slots {
+		requests[i] = BatchRequest{Method: "getBlock", Params: []interface{}{slot, params}}
+	}
+	logger.Debug("批量请求区块数据", zap.Int("区块数", len(slots)))
+	results, err := c.Batch(ctx, requests)
+	if err != nil {
+		return nil, fmt.Errorf("批量获取区块数据失败: %w", err)
+	}
+	for i := range results {
+		if results[i].Err != nil {
+			results[i].Err = fmt.Errorf("获取区块数据失败 (slot=%d): %w", slots[i], results[i].Err)
+		}
+	}
+	return results, nil
+}
+
+// BatchGetTransactions 在一次HTTP请求中获取多个签名的原始交易数据
+// 参数:
+//   - ctx: 上下文
+//   - signatures: 交易签名列表
+//   - params: getTransaction参数，为nil时使用与 GetTransaction 相同的默认参数
+//
+// 返回:
+//   - []BatchResult: 与签名一一对应的交易数据，交易不存在时 Result 为 null
+//   - error: 整个请求失败时的错误信息
+func (c *HeliusApiClient) BatchGetTransactions(ctx context.Context, signatures []string, params *req.GetTransactionParams) ([]BatchResult, error) {
+	params = defaultGetTransactionParams(params)
+	requests := make([]BatchRequest, len(signatures))
+	for i, signature := range signatures {
+		requests[i] = BatchRequest{Method: "getTransaction", Params: []interface{}{signature, params}}
+	}
+	logger.Debug("批量请求交易数据", zap.Int("交易数", len(signatures)))
+	results, err := c.Batch(ctx, requests)
+	if err != nil {
+		return nil, fmt.Errorf("批量获取交易数据失败: %w", err)
+	}
+	for i := range results {
+		if results[i].Err != nil {
+			results[i].Err = fmt.Errorf("获取交易数据失败 (signature=%s): %w", signatures[i], results[i].Err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,322 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// defaultBlockChunkSize 分块处理区块通知时默认的每批交易数
+const defaultBlockChunkSize = 100
+
+// blockNotificationMethod 区块订阅的通知方法名
+const blockNotificationMethod = "blockNotification"
+
+// BlockStreamHandler 分块处理区块通知
+// 携带完整交易的区块通知可达数十MB，交易在读取消息的同时逐笔解码并按批交给 OnTransactions，
+// 整个区块不会同时驻留内存。两个方法都在读取协程中同步调用，应尽快返回(如推入队列)
+type BlockStreamHandler interface {
+	// OnTransactions 收到一批交易，同一区块可能调用多次
+	OnTransactions(slot uint64, transactions []resp.Transactions)
+	// OnBlock 区块通知处理完成，block 中不含交易列表；区块为空(跳过的槽位)或通知带有错误时 notificationErr 非空
+	OnBlock(slot uint64, block resp.BlockResp, notificationErr json.RawMessage)
+}
+
+// wsMessage 解析后的WebSocket消息
+type wsMessage struct {
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	ID     *int            `json:"id"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	streamed     bool // 已由分块处理器处理，无需再分发
+	subscription int  // 分块处理的区块通知的服务端订阅ID
+}
+
+// BlockSubscribe 订阅区块，每个区块通知完整地交给 handler
+// 参数:
+//   - filter: 过滤条件，"all" 或 {"mentionsAccountOrProgram": "<地址>"}
+//   - options: 订阅选项，如 commitment、encoding、transactionDetails、maxSupportedTransactionVersion
+//   - handler: 通知处理函数
+//
+// 返回:
+//   - int: 客户端订阅ID，重连后保持不变
+//   - error: 错误信息
+func (c *WebSocketClient) BlockSubscribe(filter interface{}, options map[string]interface{}, handler SubscriptionHandler) (int, error) {
+	return c.subscribe("blockSubscribe", []interface{}{filter, options}, &wsSubscription{handler: handler})
+}
+
+// BlockStreamSubscribe 订阅区块，区块通知按批交给 handler 处理，适合 transactionDetails=full 的大区块
+// 参数:
+//   - filter: 过滤条件，"all" 或 {"mentionsAccountOrProgram": "<地址>"}
+//   - options: 订阅选项，encoding 需为 json
+//   - handler: 分块处理器
+//
+// 返回:
+//   - int: 客户端订阅ID，重连后保持不变
+//   - error: 错误信息
+func (c *WebSocketClient) BlockStreamSubscribe(filter interface{}, options map[string]interface{}, handler BlockStreamHandler) (int, error) {
+	c.subscriptionMutex.Lock()
+	previous := c.blockStream
+	c.blockStream = handler
+	c.subscriptionMutex.Unlock()
+	subscriptionID, err := c.subscribe("blockSubscribe", []interface{}{filter, options}, &wsSubscription{stream: true})
+	if err != nil {
+		c.subscriptionMutex.Lock()
+		c.blockStream = previous
+		c.subscriptionMutex.Unlock()
+	}
+	return subscriptionID, err
+}
+
+// BlockUnsubscribe 取消区块订阅，最后一个分块处理的区块订阅取消后移除分块处理器
+func (c *WebSocketClient) BlockUnsubscribe(subscriptionID int) error {
+	return c.unsubscribe("blockUnsubscribe", subscriptionID)
+}
+
+// decodeMessage 边读取边解析一条消息
+// 区块通知的 params 在 method 之后出现且注册了分块处理器时直接从连接中流式解码，
+// 否则先完整读取 params 再按普通通知处理
+func (c *WebSocketClient) decodeMessage(reader io.Reader) (wsMessage, error) {
+	var message wsMessage
+	decoder := json.NewDecoder(reader)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return message, err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return message, err
+		}
+		switch key {
+		case "method":
+			err = decoder.Decode(&message.Method)
+		case "id":
+			err = decoder.Decode(&message.ID)
+		case "result":
+			err = decoder.Decode(&message.Result)
+		case "error":
+			err = decoder.Decode(&message.Error)
+		case "params":
+			if stream := c.blockStreamHandler(message.Method); stream != nil {
+				message.streamed = true
+				err = c.streamBlockParams(decoder, stream, &message.subscription)
+			} else {
+				err = decoder.Decode(&message.Params)
+			}
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return message, err
+		}
+	}
+
+	// params 出现在 method 之前时只能先缓存再分块处理
+	if !message.streamed && message.Params != nil {
+		if stream := c.blockStreamHandler(message.Method); stream != nil {
+			message.streamed = true
+			return message, c.streamBlockParams(json.NewDecoder(bytes.NewReader(message.Params)), stream, &message.subscription)
+		}
+	}
+	return message, nil
+}
+
+// blockStreamHandler 返回需要分块处理的区块通知处理器，不是区块通知或未注册时返回nil
+func (c *WebSocketClient) blockStreamHandler(method string) BlockStreamHandler {
+	if method != blockNotificationMethod {
+		return nil
+	}
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	return c.blockStream
+}
+
+// streamBlockParams 解析区块通知的 params: {"result": {"context": {...}, "value": {"slot", "block", "err"}}, "subscription": N}
+func (c *WebSocketClient) streamBlockParams(decoder *json.Decoder, stream BlockStreamHandler, subscription *int) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "result":
+			err = c.streamBlockResult(decoder, stream)
+		case "subscription":
+			err = decoder.Decode(subscription)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// streamBlockResult 解析区块通知的 result，交易列表按批交给分块处理器
+func (c *WebSocketClient) streamBlockResult(decoder *json.Decoder, stream BlockStreamHandler) error {
+	var (
+		slot            uint64
+		block           resp.BlockResp
+		notificationErr json.RawMessage
+	)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "context":
+			// context 先于 value 出现，用于在区块字段之前确定槽位
+			var context struct {
+				Slot uint64 `json:"slot"`
+			}
+			err = decoder.Decode(&context)
+			if slot == 0 {
+				slot = context.Slot
+			}
+		case "value":
+			err = c.streamBlockValue(decoder, stream, &slot, &block, &notificationErr)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	stream.OnBlock(slot, block, notificationErr)
+	return nil
+}
+
+// streamBlockValue 解析 value: {"slot": N, "block": {...} | null, "err": ... }
+func (c *WebSocketClient) streamBlockValue(decoder *json.Decoder, stream BlockStreamHandler, slot *uint64, block *resp.BlockResp, notificationErr *json.RawMessage) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "slot":
+			err = decoder.Decode(slot)
+		case "err":
+			var raw json.RawMessage
+			err = decoder.Decode(&raw)
+			if string(raw) != "null" {
+				*notificationErr = raw
+			}
+		case "block":
+			err = c.streamBlock(decoder, stream, *slot, block)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// streamBlock 解析区块字段，transactions 数组逐笔解码，每满 blockChunkSize 笔交给分块处理器
+func (c *WebSocketClient) streamBlock(decoder *json.Decoder, stream BlockStreamHandler, slot uint64, block *resp.BlockResp) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("区块字段格式错误: %v", token)
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "transactions":
+			err = c.streamTransactions(decoder, stream, slot)
+		case "blockTime":
+			err = decoder.Decode(&block.BlockTime)
+		case "blockhash":
+			err = decoder.Decode(&block.Blockhash)
+		case "parentSlot":
+			err = decoder.Decode(&block.ParentSlot)
+		case "previousBlockhash":
+			err = decoder.Decode(&block.PreviousBlockhash)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// streamTransactions 逐笔解码交易数组
+func (c *WebSocketClient) streamTransactions(decoder *json.Decoder, stream BlockStreamHandler, slot uint64) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("交易列表格式错误: %v", token)
+	}
+	batch := make([]resp.Transactions, 0, c.blockChunkSize)
+	for decoder.More() {
+		var transaction resp.Transactions
+		if err := decoder.Decode(&transaction); err != nil {
+			return fmt.Errorf("解码区块交易失败: %w", err)
+		}
+		batch = append(batch, transaction)
+		if len(batch) >= c.blockChunkSize {
+			stream.OnTransactions(slot, batch)
+			batch = make([]resp.Transactions, 0, c.blockChunkSize)
+		}
+	}
+	if len(batch) > 0 {
+		stream.OnTransactions(slot, batch)
+	}
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim 读取下一个分隔符并检查是否符合预期
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("JSON格式错误: 期望 %v，实际为 %v", delim, token)
+	}
+	return nil
+}
+
+// skipValue 跳过下一个JSON值
+func skipValue(decoder *json.Decoder) error {
+	var skipped json.RawMessage
+	return decoder.Decode(&skipped)
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/life2you/datas-go/models/req"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// ErrAccountNotFound 账户不存在
+var ErrAccountNotFound = errors.New("账户不存在")
+
+// GetMintAccount 通过 getAccountInfo(jsonParsed) 查询代币Mint账户的权限和供应量
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//
+// 返回:
+//   - *resp.MintAccount: Mint账户信息
+//   - error: 账户不存在时返回 ErrAccountNotFound，账户不是Mint账户时返回错误
+func (c *HeliusApiClient) GetMintAccount(ctx context.Context, mint string) (*resp.MintAccount, error) {
+	result, err := c.makeRequest(ctx, "getAccountInfo", []interface{}{mint, map[string]string{"encoding": "jsonParsed"}})
+	if err != nil {
+		return nil, fmt.Errorf("获取账户信息失败 (mint=%s): %w", mint, err)
+	}
+	var account struct {
+		Value *struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(result, &account); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+	if account.Value == nil {
+		return nil, ErrAccountNotFound
+	}
+	// 无法解析的账户返回 [base64数据, 编码]，解码为对象时失败
+	var data struct {
+		Program string `json:"program"`
+		Parsed  struct {
+			Type string           `json:"type"`
+			Info resp.MintAccount `json:"info"`
+		} `json:"parsed"`
+	}
+	if err := json.Unmarshal(account.Value.Data, &data); err != nil || data.Parsed.Type != "mint" {
+		return nil, fmt.Errorf("账户不是代币Mint账户: %s", mint)
+	}
+	info := data.Parsed.Info
+	info.Program = data.Program
+	return &info, nil
+}
+
+// GetTokenAccounts 通过 DAS getTokenAccounts 分页查询代币账户
+// 参数:
+//   - ctx: 上下文
+//   - params: 查询参数，Mint 和 Owner 至少设置一个
+//
+// 返回:
+//   - *resp.TokenAccounts: 本页的代币账户
+//   - error: 错误信息
+func (c *HeliusApiClient) GetTokenAccounts(ctx context.Context, params req.GetTokenAccountsParams) (*resp.TokenAccounts, error) {
+	if params.Mint == "" && params.Owner == "" {
+		return nil, fmt.Errorf("mint 和 owner 至少需要设置一个")
+	}
+	result, err := c.makeRequest(ctx, "getTokenAccounts", params)
+	if err != nil {
+		return nil, fmt.Errorf("查询代币账户失败: %w", err)
+	}
+	var accounts resp.TokenAccounts
+	if err := json.Unmarshal(result, &accounts); err != nil {
+		return nil, fmt.Errorf("解析代币账户失败: %w", err)
+	}
+	return &accounts, nil
+}
@@ -3,17 +3,27 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"crypto/tls"
 
 	"github.com/gorilla/websocket"
+	"github.com/life2you/datas-go/clock"
 	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/supervisor"
+	"go.uber.org/zap"
 )
 
 // WebSocketClient 表示Helius WebSocket客户端
@@ -21,9 +31,14 @@ type WebSocketClient struct {
 	conn              *websocket.Conn
 	url               string
 	apiKey            string
-	subscriptions     map[string]SubscriptionHandler
+	subscriptions     map[int]*wsSubscription // 当前连接上服务端确认的订阅，服务端分配的订阅ID -> 订阅
+	handles           map[int]*wsSubscription // 所有订阅，客户端订阅ID -> 订阅，包括连接断开后等待重新订阅的
 	subscriptionMutex sync.Mutex
+	pending           map[int]*pendingRequest // 等待响应的请求，请求ID -> 请求
 	nextID            int
+	requestTimeout    time.Duration // 等待请求响应的超时
+	pingInterval      time.Duration // 发送ping的间隔
+	livenessTimeout   time.Duration // 超过该时长未收到消息或pong时断开，0表示不检测
 	done              chan struct{}
 	reconnect         bool
 	reconnectInterval time.Duration
@@ -31,11 +46,66 @@ type WebSocketClient struct {
 	closed            bool
 	mutex             sync.Mutex
 	proxyURL          string
+	insecureSkipTLS   bool // 是否跳过TLS证书校验
+	log               *zap.Logger
+	lastMessageAt     atomic.Int64 // 最近一次收到消息的时间(Unix纳秒)
+	enableCompression bool
+	readLimit         int64
+	readBufferSize    int
+	writeBufferSize   int
+	blockChunkSize    int
+	blockStream       BlockStreamHandler // 分块处理区块通知的处理器
 }
 
+// pingWriteTimeout 发送ping的写入超时
+const pingWriteTimeout = 10 * time.Second
+
 // SubscriptionHandler 是处理订阅响应的回调接口
 type SubscriptionHandler func(result json.RawMessage)
 
+// nextSubscriptionID 分配客户端订阅ID，在进程内唯一
+var nextSubscriptionID atomic.Int64
+
+// wsSubscription 一个订阅，重连后按相同的方法和参数重新订阅，客户端订阅ID保持不变
+type wsSubscription struct {
+	id           int                 // 客户端订阅ID，返回给调用方
+	serverID     int                 // 服务端分配的订阅ID，随连接失效，等待重新订阅时为0
+	method       string              // 订阅方法，如 slotSubscribe
+	params       []interface{}       // 订阅参数
+	handler      SubscriptionHandler // 通知的处理函数，分块处理的区块订阅为nil
+	stream       bool                // 是否为交给分块处理器的区块订阅
+	subscribedAt time.Time           // 服务端最近一次确认订阅的时间
+
+	messages      atomic.Int64 // 收到的通知数
+	bytes         atomic.Int64 // 收到的通知字节数
+	lastMessageAt atomic.Int64 // 最近一次收到通知的时间(Unix纳秒)
+}
+
+// record 记录收到的一条通知
+func (s *wsSubscription) record(size int64) {
+	s.messages.Add(1)
+	s.bytes.Add(size)
+	s.lastMessageAt.Store(clock.Now().UnixNano())
+}
+
+// SubscriptionStats 单个订阅的状态和收到的通知统计，重新订阅后继续累计
+type SubscriptionStats struct {
+	ID            int             `json:"id"`                        // 客户端订阅ID，重连后保持不变
+	ServerID      int             `json:"server_id"`                 // 服务端分配的订阅ID，等待重新订阅时为0
+	Method        string          `json:"method"`                    // 订阅方法，如 slotSubscribe
+	Params        json.RawMessage `json:"params,omitempty"`          // 订阅参数
+	SubscribedAt  time.Time       `json:"subscribed_at"`             // 服务端最近一次确认订阅的时间
+	Messages      int64           `json:"messages"`                  // 收到的通知数
+	Bytes         int64           `json:"bytes"`                     // 收到的通知字节数
+	LastMessageAt *time.Time      `json:"last_message_at,omitempty"` // 最近一次收到通知的时间
+}
+
+// pendingRequest 等待响应的请求
+type pendingRequest struct {
+	responses    chan wsMessage
+	subscription *wsSubscription // 订阅请求在收到订阅ID时立即注册，之后的通知不会因调用方尚未返回而丢失
+}
+
 // WebSocketOptions 包含WebSocket客户端的配置选项
 type WebSocketOptions struct {
 	ReconnectInterval time.Duration // 重连间隔时间
@@ -45,32 +115,73 @@ type WebSocketOptions struct {
 
 var GlobalWebSocketClient *WebSocketClient
 
+// HeliusWebSocketURL 返回指定网络的Helius WebSocket地址，不含API密钥
+func HeliusWebSocketURL(networkType string) string {
+	return fmt.Sprintf("wss://%s.helius-rpc.com", networkType)
+}
+
+// WebSocketURL 返回配置的WebSocket地址，不含API密钥；没有设置 endpoint 时使用 network_type 对应的Helius地址
+func WebSocketURL(config *configs.WebSocketConfig) string {
+	if config.Endpoint != "" {
+		return strings.TrimSuffix(config.Endpoint, "/")
+	}
+	return HeliusWebSocketURL(config.NetworkType)
+}
+
 // NewWebSocketClientOptions 创建带有自定义选项的WebSocket客户端
 func NewWebSocketClientOptions(config *configs.WebSocketConfig) {
-	if config.NetworkType != "mainnet" && config.NetworkType != "devnet" {
+	GlobalWebSocketClient = newWebSocketClient(config, logger.Named("rpc.websocket"))
+}
+
+// newWebSocketClient 按配置创建WebSocket客户端，不建立连接
+func newWebSocketClient(config *configs.WebSocketConfig, log *zap.Logger) *WebSocketClient {
+	if config.Endpoint == "" && config.NetworkType != "mainnet" && config.NetworkType != "devnet" {
 		panic(fmt.Errorf("不支持的网络: %s, 请使用 'mainnet' 或 'devnet'", config.NetworkType))
 	}
 
-	baseURL := fmt.Sprintf("wss://%s.helius-rpc.com", config.NetworkType)
+	baseURL := WebSocketURL(config)
 	endpoint := fmt.Sprintf("%s/?api-key=%s", baseURL, config.APIKey)
 
 	reconnectInterval := config.ReconnectInterval
 	if reconnectInterval == 0 {
 		reconnectInterval = 5 * time.Second
 	}
+	requestTimeout := config.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = 10 * time.Second
+	}
+	pingInterval := config.PingInterval
+	if pingInterval <= 0 {
+		pingInterval = 30 * time.Second
+	}
 
 	client := &WebSocketClient{
 		url:               endpoint,
 		apiKey:            config.APIKey,
-		subscriptions:     make(map[string]SubscriptionHandler),
+		subscriptions:     make(map[int]*wsSubscription),
+		handles:           make(map[int]*wsSubscription),
+		pending:           make(map[int]*pendingRequest),
 		nextID:            1,
+		requestTimeout:    requestTimeout,
+		pingInterval:      pingInterval,
+		livenessTimeout:   config.LivenessTimeout,
 		done:              make(chan struct{}),
 		reconnect:         true,
 		reconnectInterval: reconnectInterval,
 		onConnect:         config.OnConnect,
 		proxyURL:          config.ProxyURL,
+		insecureSkipTLS:   config.InsecureSkipVerify,
+		log:               log.With(zap.String("url", baseURL)),
+		enableCompression: config.EnableCompression,
+		readLimit:         config.ReadLimit,
+		readBufferSize:    config.ReadBufferSize,
+		writeBufferSize:   config.WriteBufferSize,
+		blockChunkSize:    config.BlockChunkSize,
 	}
-	GlobalWebSocketClient = client
+	if client.blockChunkSize <= 0 {
+		client.blockChunkSize = defaultBlockChunkSize
+	}
+	return client
 }
 
 // Connect 建立WebSocket连接
@@ -88,28 +199,46 @@ func (c *WebSocketClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("解析WebSocket URL失败: %w", err)
 	}
 
-	// 设置拨号选项
-	dialer := websocket.DefaultDialer
+	// 设置拨号选项，区块通知可能很大，缓冲区和压缩按配置设置
+	dialer := &websocket.Dialer{
+		Proxy:             http.ProxyFromEnvironment,
+		HandshakeTimeout:  45 * time.Second,
+		ReadBufferSize:    c.readBufferSize,
+		WriteBufferSize:   c.writeBufferSize,
+		EnableCompression: c.enableCompression,
+	}
 
 	// 如果配置了代理，设置代理
 	if c.proxyURL != "" {
-		proxyURL, err := url.Parse(c.proxyURL)
-		if err != nil {
-			return fmt.Errorf("解析代理URL失败: %w", err)
-		}
-		dialer = &websocket.Dialer{
-			Proxy:            http.ProxyURL(proxyURL),
-			HandshakeTimeout: 45 * time.Second,
-			TLSClientConfig:  &tls.Config{InsecureSkipVerify: true}, // 注意：在生产环境中不建议跳过TLS验证
+		if err := applyWebSocketProxy(dialer, c.proxyURL); err != nil {
+			return err
 		}
-		log.Printf("使用代理连接WebSocket: %s", c.proxyURL)
+		c.log.Info("使用代理连接WebSocket", zap.String("proxy", redactURL(c.proxyURL)))
+	}
+	if c.insecureSkipTLS {
+		dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // 注意：在生产环境中不建议跳过TLS验证
+		c.log.Warn("WebSocket连接已关闭TLS证书校验")
 	}
 
 	// 建立连接
-	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
+	conn, response, err := dialer.DialContext(ctx, u.String(), nil)
 	if err != nil {
 		return fmt.Errorf("连接WebSocket服务器失败: %w", err)
 	}
+	if c.readLimit > 0 {
+		conn.SetReadLimit(c.readLimit)
+	}
+	if c.enableCompression {
+		// 服务端未接受permessage-deflate时gorilla会自动按不压缩处理
+		c.log.Debug("WebSocket压缩协商结果", zap.String("extensions", response.Header.Get("Sec-WebSocket-Extensions")))
+	}
+	// 收到消息或pong时延长读取截止时间，半断开的连接在截止时间到达后读取失败并重连
+	if c.livenessTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(c.livenessTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(c.livenessTimeout))
+		})
+	}
 
 	c.mutex.Lock()
 	c.conn = conn
@@ -121,10 +250,10 @@ func (c *WebSocketClient) Connect(ctx context.Context) error {
 	}
 
 	// 启动消息接收循环
-	go c.readLoop()
+	supervisor.Go(context.Background(), "rpc.helius_socket.read", func(context.Context) { c.readLoop() })
 
-	// 启动心跳检测
-	go c.pingLoop()
+	// 启动心跳检测，随该连接断开而退出
+	supervisor.Go(context.Background(), "rpc.helius_socket.ping", func(context.Context) { c.pingLoop(conn) })
 
 	return nil
 }
@@ -141,6 +270,7 @@ func (c *WebSocketClient) Close() error {
 	c.closed = true
 	close(c.done)
 	c.reconnect = false
+	c.failPending()
 
 	if c.conn != nil {
 		return c.conn.Close()
@@ -148,11 +278,63 @@ func (c *WebSocketClient) Close() error {
 	return nil
 }
 
+// IsConnected 返回WebSocket当前是否处于连接状态
+func (c *WebSocketClient) IsConnected() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn != nil && !c.closed
+}
+
+// LastMessageAt 返回最近一次收到消息的时间，尚未收到消息时返回零值
+func (c *WebSocketClient) LastMessageAt() time.Time {
+	if nanos := c.lastMessageAt.Load(); nanos > 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// Reconnect 断开当前连接，由断开处理重新连接并恢复所有订阅，未连接时不做任何处理
+func (c *WebSocketClient) Reconnect() {
+	c.mutex.Lock()
+	conn := c.conn
+	c.mutex.Unlock()
+	if conn != nil {
+		c.log.Warn("主动断开WebSocket连接，重连后恢复订阅")
+		conn.Close()
+	}
+}
+
+// SubscriptionStats 返回所有订阅的状态和通知统计，按客户端订阅ID排序
+func (c *WebSocketClient) SubscriptionStats() []SubscriptionStats {
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	stats := make([]SubscriptionStats, 0, len(c.handles))
+	for _, subscription := range c.handles {
+		params, _ := json.Marshal(subscription.params)
+		stat := SubscriptionStats{
+			ID:           subscription.id,
+			ServerID:     subscription.serverID,
+			Method:       subscription.method,
+			Params:       params,
+			SubscribedAt: subscription.subscribedAt,
+			Messages:     subscription.messages.Load(),
+			Bytes:        subscription.bytes.Load(),
+		}
+		if nanos := subscription.lastMessageAt.Load(); nanos > 0 {
+			at := time.Unix(0, nanos)
+			stat.LastMessageAt = &at
+		}
+		stats = append(stats, stat)
+	}
+	slices.SortFunc(stats, func(a, b SubscriptionStats) int { return a.ID - b.ID })
+	return stats
+}
+
 // 读取消息的循环
 func (c *WebSocketClient) readLoop() {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("WebSocket读取循环发生意外: %v", r)
+			c.log.Error("WebSocket读取循环发生意外", zap.Any("panic", r))
 		}
 		c.handleDisconnect()
 	}()
@@ -162,27 +344,40 @@ func (c *WebSocketClient) readLoop() {
 		case <-c.done:
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			_, messageReader, err := c.conn.NextReader()
 			if err != nil {
-				log.Printf("读取WebSocket消息错误: %v", err)
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					c.log.Error("超过存活超时未收到消息或pong，断开重连", zap.Duration("liveness_timeout", c.livenessTimeout))
+				} else {
+					c.log.Error("读取WebSocket消息错误", zap.Error(err))
+				}
 				return
 			}
-
-			// 解析响应
-			var response struct {
-				JSONRPC string          `json:"jsonrpc"`
-				Method  string          `json:"method,omitempty"`
-				Params  json.RawMessage `json:"params,omitempty"`
-				Result  json.RawMessage `json:"result,omitempty"`
-				ID      *int            `json:"id"`
-				Error   *struct {
-					Code    int    `json:"code"`
-					Message string `json:"message"`
-				} `json:"error,omitempty"`
+			c.lastMessageAt.Store(clock.Now().UnixNano())
+			if c.livenessTimeout > 0 {
+				c.conn.SetReadDeadline(time.Now().Add(c.livenessTimeout))
 			}
 
-			if err := json.Unmarshal(message, &response); err != nil {
-				log.Printf("解析WebSocket响应错误: %v", err)
+			// 边读取边解析，区块通知的交易按批交给分块处理器，不缓存整条消息
+			reader := &countingReader{reader: messageReader}
+			response, err := c.decodeMessage(reader)
+			if err != nil {
+				// 超过读取上限时连接已不可用，断开重连
+				if errors.Is(err, websocket.ErrReadLimit) {
+					c.log.Error("WebSocket消息超过读取上限，请调大 websocket.read_limit", zap.Int64("read_limit", c.readLimit))
+					return
+				}
+				c.log.Error("解析WebSocket响应错误", zap.String("method", response.Method), zap.Error(err))
+				continue
+			}
+			if response.streamed {
+				c.subscriptionMutex.Lock()
+				subscription := c.subscriptions[response.subscription]
+				c.subscriptionMutex.Unlock()
+				if subscription != nil {
+					subscription.record(reader.n)
+				}
 				continue
 			}
 
@@ -193,31 +388,34 @@ func (c *WebSocketClient) readLoop() {
 					Result       json.RawMessage `json:"result"`
 				}
 				if err := json.Unmarshal(response.Params, &notification); err != nil {
-					log.Printf("解析订阅通知错误: %v", err)
+					if payload.Sample("websocket_notification", response.Method, response.Params, err) {
+						c.log.Error("解析订阅通知错误", zap.String("method", response.Method), zap.Error(err))
+					}
 					continue
 				}
 
 				c.subscriptionMutex.Lock()
-				handler, exists := c.subscriptions[response.Method]
+				subscription, exists := c.subscriptions[notification.Subscription]
 				c.subscriptionMutex.Unlock()
 
 				if exists {
-					go handler(notification.Result)
+					subscription.record(reader.n)
 				}
-			} else if response.ID != nil {
-				// 处理订阅响应
-				// 响应可能包含订阅ID，需要存储以便后续处理通知
-				if response.Result != nil {
-					var subscriptionID int
-					if err := json.Unmarshal(response.Result, &subscriptionID); err == nil {
-						// 成功解析到订阅ID
-						log.Printf("已接收订阅确认，ID: %d", subscriptionID)
-					}
+				if exists && subscription.handler != nil {
+					go func() {
+						defer supervisor.Recover("rpc.subscription_handler", nil)
+						subscription.handler(notification.Result)
+					}()
+				} else if !exists {
+					c.log.Debug("收到未知订阅的通知", zap.String("method", response.Method), zap.Int("subscription", notification.Subscription))
 				}
-
-				// 处理错误响应
-				if response.Error != nil {
-					log.Printf("WebSocket响应错误: 代码=%d, 消息=%s", response.Error.Code, response.Error.Message)
+			} else if response.ID != nil {
+				// 请求的响应交给等待的调用方，调用方已超时返回时只记录错误
+				if !c.deliver(response) && response.Error != nil {
+					c.log.Error("WebSocket响应错误",
+						zap.Int("requestID", *response.ID),
+						zap.Int("code", response.Error.Code),
+						zap.String("message", response.Error.Message))
 				}
 			}
 		}
@@ -234,44 +432,72 @@ func (c *WebSocketClient) handleDisconnect() {
 		return
 	}
 
-	// 清理旧连接
+	// 清理旧连接，等待响应的请求不会再收到响应，服务端分配的订阅ID随连接失效
 	if c.conn != nil {
 		c.conn.Close()
 		c.conn = nil
 	}
+	c.failPending()
+	c.clearSubscriptions()
 
 	// 尝试重新连接
-	go func() {
-		log.Printf("WebSocket连接已断开，%v后尝试重连...", c.reconnectInterval)
-		time.Sleep(c.reconnectInterval)
+	supervisor.Go(context.Background(), "rpc.helius_socket.reconnect", func(context.Context) {
+		c.log.Warn("WebSocket连接已断开，稍后尝试重连", zap.Duration("reconnectInterval", c.reconnectInterval))
+		clock.Sleep(c.reconnectInterval)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		if err := c.Connect(ctx); err != nil {
-			log.Printf("WebSocket重连失败: %v", err)
+			c.log.Error("WebSocket重连失败", zap.Error(err))
 			// 再次触发断开处理，以便继续尝试重连
 			c.handleDisconnect()
 		} else {
-			log.Println("WebSocket重连成功")
+			c.log.Info("WebSocket重连成功")
 
 			// 连接成功后重新订阅
 			c.resubscribe()
 		}
-	}()
+	})
 }
 
-// 重新订阅所有活跃的订阅
+// resubscribe 重连后按原来的方法和参数重新订阅，客户端订阅ID保持不变
+// 服务端拒绝的订阅被移除，由订阅方按 HasSubscription 重新订阅；网络错误时保留，下次重连后再试
 func (c *WebSocketClient) resubscribe() {
-	// 这里应该实现重新订阅的逻辑
-	// 由于每个订阅都需要特定的参数，这里需要根据实际情况来实现
-	// 此处仅为示例，实际项目中可能需要更复杂的实现
-	log.Println("正在重新建立之前的订阅...")
+	c.subscriptionMutex.Lock()
+	subscriptions := make([]*wsSubscription, 0, len(c.handles))
+	for _, subscription := range c.handles {
+		subscriptions = append(subscriptions, subscription)
+	}
+	c.subscriptionMutex.Unlock()
+	if len(subscriptions) == 0 {
+		return
+	}
+	c.log.Info("正在重新建立之前的订阅", zap.Int("count", len(subscriptions)))
+	for _, subscription := range subscriptions {
+		if _, err := c.call(subscription.method, subscription.params, subscription); err != nil {
+			var rpcErr *RPCError
+			if errors.As(err, &rpcErr) {
+				c.subscriptionMutex.Lock()
+				delete(c.handles, subscription.id)
+				c.subscriptionMutex.Unlock()
+				c.log.Error("服务端拒绝重新订阅，已移除该订阅", zap.String("method", subscription.method), zap.Int("id", subscription.id), zap.Error(err))
+			} else {
+				c.log.Warn("重新订阅失败，下次重连后再试", zap.String("method", subscription.method), zap.Int("id", subscription.id), zap.Error(err))
+			}
+			continue
+		}
+		c.subscriptionMutex.Lock()
+		serverID := subscription.serverID
+		c.subscriptionMutex.Unlock()
+		c.log.Info("已重新订阅", zap.String("method", subscription.method), zap.Int("id", subscription.id), zap.Int("subscription", serverID))
+	}
 }
 
-// 定期发送ping以保持连接活跃
-func (c *WebSocketClient) pingLoop() {
-	ticker := time.NewTicker(30 * time.Second)
+// pingLoop 定期在指定连接上发送ping以保持连接活跃，连接被替换或关闭后退出
+// 发送失败时关闭连接，由读取循环处理断开和重连
+func (c *WebSocketClient) pingLoop(conn *websocket.Conn) {
+	ticker := time.NewTicker(c.pingInterval)
 	defer ticker.Stop()
 
 	for {
@@ -280,16 +506,18 @@ func (c *WebSocketClient) pingLoop() {
 			return
 		case <-ticker.C:
 			c.mutex.Lock()
-			if c.conn != nil {
-				if err := c.conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
-					log.Printf("发送ping消息失败: %v", err)
-					c.mutex.Unlock()
-					c.handleDisconnect()
-					return
-				}
-				log.Println("已发送ping")
-			}
+			current := c.conn
 			c.mutex.Unlock()
+			if current != conn {
+				return
+			}
+			// WriteControl 可以与其他写入并发调用
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteTimeout)); err != nil {
+				c.log.Error("发送ping消息失败，断开重连", zap.Error(err))
+				conn.Close()
+				return
+			}
+			c.log.Debug("已发送ping")
 		}
 	}
 }
@@ -303,16 +531,34 @@ func (c *WebSocketClient) getNextID() int {
 	return id
 }
 
-// subscribe 是所有订阅方法的基础方法
-func (c *WebSocketClient) subscribe(method string, params []interface{}, handler SubscriptionHandler) (int, error) {
+// call 发送JSON-RPC请求并等待对应请求ID的响应
+// 参数:
+//   - method: 方法名
+//   - params: 参数
+//   - subscription: 订阅请求成功时注册的订阅，其他请求为nil
+//
+// 返回:
+//   - json.RawMessage: 响应的 result
+//   - error: 连接未建立、发送失败、超时、等待期间连接断开(均包装 ErrNetwork)或服务端返回的 *RPCError
+func (c *WebSocketClient) call(method string, params []interface{}, subscription *wsSubscription) (json.RawMessage, error) {
 	c.mutex.Lock()
 	if c.conn == nil {
 		c.mutex.Unlock()
-		return 0, fmt.Errorf("WebSocket连接未建立")
+		return nil, fmt.Errorf("%w: WebSocket连接未建立", ErrNetwork)
 	}
 	c.mutex.Unlock()
 
 	requestID := c.getNextID()
+	pending := &pendingRequest{responses: make(chan wsMessage, 1), subscription: subscription}
+	c.subscriptionMutex.Lock()
+	c.pending[requestID] = pending
+	c.subscriptionMutex.Unlock()
+	defer func() {
+		c.subscriptionMutex.Lock()
+		delete(c.pending, requestID)
+		c.subscriptionMutex.Unlock()
+	}()
+
 	request := struct {
 		JSONRPC string        `json:"jsonrpc"`
 		ID      int           `json:"id"`
@@ -324,73 +570,221 @@ func (c *WebSocketClient) subscribe(method string, params []interface{}, handler
 		Method:  method,
 		Params:  params,
 	}
-
-	// 发送订阅请求
 	c.mutex.Lock()
+	if c.conn == nil {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("%w: WebSocket连接未建立", ErrNetwork)
+	}
 	err := c.conn.WriteJSON(request)
 	c.mutex.Unlock()
 	if err != nil {
-		return 0, fmt.Errorf("发送订阅请求失败: %w", err)
+		return nil, fmt.Errorf("%w: 发送 %s 请求失败: %v", ErrNetwork, method, err)
+	}
+	c.log.Debug("已发送请求", zap.String("method", method), zap.Int("requestID", requestID))
+
+	timer := time.NewTimer(c.requestTimeout)
+	defer timer.Stop()
+	select {
+	case response, ok := <-pending.responses:
+		if !ok {
+			return nil, fmt.Errorf("%w: 等待 %s 响应时连接已断开", ErrNetwork, method)
+		}
+		if response.Error != nil {
+			return nil, &RPCError{Method: method, Code: response.Error.Code, Message: response.Error.Message}
+		}
+		return response.Result, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: 等待 %s 响应超时(%s)", ErrNetwork, method, c.requestTimeout)
 	}
+}
 
-	// 存储订阅处理器
-	// 注意：这里我们暂时使用请求ID作为订阅ID的占位符
-	// 实际上，服务器返回的订阅ID可能不同，需要在响应中更新
+// deliver 将响应交给等待该请求ID的调用方，没有调用方等待时返回false
+// 订阅请求成功时在读取协程中直接注册订阅，保证之后读取的通知能找到处理函数
+func (c *WebSocketClient) deliver(response wsMessage) bool {
 	c.subscriptionMutex.Lock()
-	c.subscriptions["slotNotification"] = handler
-	c.subscriptionMutex.Unlock()
-	return requestID, nil
+	defer c.subscriptionMutex.Unlock()
+	pending, ok := c.pending[*response.ID]
+	if !ok {
+		return false
+	}
+	delete(c.pending, *response.ID)
+	if pending.subscription != nil && response.Error == nil {
+		var subscriptionID int
+		if err := json.Unmarshal(response.Result, &subscriptionID); err == nil {
+			pending.subscription.serverID = subscriptionID
+			pending.subscription.subscribedAt = clock.Now()
+			c.subscriptions[subscriptionID] = pending.subscription
+		}
+	}
+	pending.responses <- response
+	return true
 }
 
-// unsubscribe 取消指定的订阅
-func (c *WebSocketClient) unsubscribe(method string, subscriptionName string) error {
-	c.mutex.Lock()
-	if c.conn == nil {
-		c.mutex.Unlock()
-		return fmt.Errorf("WebSocket连接未建立")
+// failPending 连接断开或关闭时通知所有等待响应的调用方
+func (c *WebSocketClient) failPending() {
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	for requestID, pending := range c.pending {
+		close(pending.responses)
+		delete(c.pending, requestID)
 	}
-	c.mutex.Unlock()
+}
 
-	requestID := c.getNextID()
-	request := struct {
-		JSONRPC string        `json:"jsonrpc"`
-		ID      int           `json:"id"`
-		Method  string        `json:"method"`
-		Params  []interface{} `json:"params"`
-	}{
-		JSONRPC: "2.0",
-		ID:      requestID,
-		Method:  method,
-		Params:  []interface{}{subscriptionName},
+// clearSubscriptions 连接断开后清除服务端分配的订阅ID，订阅本身保留，重连后重新订阅
+func (c *WebSocketClient) clearSubscriptions() {
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	clear(c.subscriptions)
+	for _, subscription := range c.handles {
+		subscription.serverID = 0
 	}
+}
 
-	// 发送取消订阅请求
-	c.mutex.Lock()
-	err := c.conn.WriteJSON(request)
-	c.mutex.Unlock()
+// subscribe 是所有订阅方法的基础方法，服务端确认后返回客户端订阅ID
+func (c *WebSocketClient) subscribe(method string, params []interface{}, subscription *wsSubscription) (int, error) {
+	subscription.method = method
+	subscription.params = params
+	result, err := c.call(method, params, subscription)
 	if err != nil {
-		return fmt.Errorf("发送取消订阅请求失败: %w", err)
+		return 0, fmt.Errorf("订阅 %s 失败: %w", method, err)
+	}
+	var serverID int
+	if err := json.Unmarshal(result, &serverID); err != nil {
+		return 0, fmt.Errorf("解析 %s 的订阅ID失败: %w", method, err)
 	}
+	c.subscriptionMutex.Lock()
+	subscription.id = int(nextSubscriptionID.Add(1))
+	c.handles[subscription.id] = subscription
+	c.subscriptionMutex.Unlock()
+	c.log.Info("订阅已确认", zap.String("method", method), zap.Int("id", subscription.id), zap.Int("subscription", serverID))
+	return subscription.id, nil
+}
 
-	// 从订阅映射中移除
+// unsubscribe 使用服务端分配的订阅ID取消订阅，服务端确认后移除订阅；连接断开、等待重新订阅的订阅直接移除
+// 服务端返回错误或 false 时返回错误，订阅保持不变
+func (c *WebSocketClient) unsubscribe(method string, subscriptionID int) error {
 	c.subscriptionMutex.Lock()
-	delete(c.subscriptions, subscriptionName)
+	subscription, exists := c.handles[subscriptionID]
+	serverID := 0
+	if exists {
+		serverID = subscription.serverID
+	}
 	c.subscriptionMutex.Unlock()
+	if !exists {
+		return fmt.Errorf("订阅 %d 不存在", subscriptionID)
+	}
+
+	if serverID != 0 {
+		result, err := c.call(method, []interface{}{serverID}, nil)
+		if err != nil {
+			return fmt.Errorf("取消订阅 %d 失败: %w", subscriptionID, err)
+		}
+		var ok bool
+		if err := json.Unmarshal(result, &ok); err != nil || !ok {
+			return fmt.Errorf("取消订阅 %d 失败: 服务端返回 %s", subscriptionID, string(result))
+		}
+	}
+	c.log.Info("已取消订阅", zap.String("method", method), zap.Int("id", subscriptionID), zap.Int("subscription", serverID))
 
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	delete(c.handles, subscriptionID)
+	if c.subscriptions[serverID] == subscription {
+		delete(c.subscriptions, serverID)
+	}
+	// 最后一个分块处理的区块订阅取消后不再分块处理区块通知
+	if subscription.stream {
+		streaming := false
+		for _, other := range c.handles {
+			streaming = streaming || other.stream
+		}
+		if !streaming {
+			c.blockStream = nil
+		}
+	}
 	return nil
 }
 
+// Unsubscribe 按订阅时的方法取消订阅，如 slotSubscribe 的订阅发送 slotUnsubscribe
+// 返回:
+//   - error: 订阅ID不属于该连接、服务端返回错误或拒绝取消时的错误信息
+func (c *WebSocketClient) Unsubscribe(subscriptionID int) error {
+	c.subscriptionMutex.Lock()
+	subscription, exists := c.handles[subscriptionID]
+	c.subscriptionMutex.Unlock()
+	if !exists {
+		return fmt.Errorf("订阅 %d 不存在", subscriptionID)
+	}
+	return c.unsubscribe(strings.TrimSuffix(subscription.method, "Subscribe")+"Unsubscribe", subscriptionID)
+}
+
+// HasSubscription 返回订阅ID是否属于该连接，连接断开后等待重新订阅的订阅也属于该连接
+func (c *WebSocketClient) HasSubscription(subscriptionID int) bool {
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	_, exists := c.handles[subscriptionID]
+	return exists
+}
+
+// SubscriptionCount 返回该连接上的订阅数，包括等待重新订阅的订阅
+func (c *WebSocketClient) SubscriptionCount() int {
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	return len(c.handles)
+}
+
 // AccountUnsubscribe 取消账户订阅
 func (c *WebSocketClient) AccountUnsubscribe(subscriptionID int) error {
-	return c.unsubscribe("accountUnsubscribe", "")
+	return c.unsubscribe("accountUnsubscribe", subscriptionID)
 }
 
-// SlotSubscribe 订阅插槽更新
+// SlotSubscribe 订阅插槽更新，返回客户端订阅ID
 func (c *WebSocketClient) SlotSubscribe(handler SubscriptionHandler) (int, error) {
-	return c.subscribe("slotSubscribe", []interface{}{}, handler)
+	return c.subscribe("slotSubscribe", []interface{}{}, &wsSubscription{handler: handler})
 }
 
 // SlotUnsubscribe 取消插槽订阅
 func (c *WebSocketClient) SlotUnsubscribe(subscriptionID int) error {
-	return c.unsubscribe("slotUnsubscribe", "slotNotification")
+	return c.unsubscribe("slotUnsubscribe", subscriptionID)
+}
+
+// LogsSubscribe 订阅交易日志，返回客户端订阅ID
+// 参数:
+//   - filter: 过滤条件，"all"、"allWithVotes" 或 {"mentions": ["<地址>"]}，mentions 只支持一个地址
+//   - commitment: 确认级别，为空时使用服务端默认值
+//   - handler: 通知处理函数，通知内容为 {"context": {"slot": ...}, "value": {"signature", "err", "logs"}}
+//
+// 返回:
+//   - int: 客户端订阅ID，重连后保持不变
+//   - error: 错误信息
+func (c *WebSocketClient) LogsSubscribe(filter interface{}, commitment string, handler SubscriptionHandler) (int, error) {
+	params := []interface{}{filter}
+	if commitment != "" {
+		params = append(params, map[string]string{"commitment": commitment})
+	}
+	return c.subscribe("logsSubscribe", params, &wsSubscription{handler: handler})
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+// Read 读取并累计字节数
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// redactURL 去除URL中的查询参数和用户信息，避免API密钥等敏感信息写入日志
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
 }
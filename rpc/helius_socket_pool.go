@@ -0,0 +1,267 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// dedupSlots 连接池区块去重保留的槽位数
+const dedupSlots = 256
+
+// GlobalWebSocketPool 全局WebSocket连接池
+var GlobalWebSocketPool *WebSocketPool
+
+// WebSocketPool 多个WebSocket连接组成的连接池
+// 单个连接在大量 mentionsAccountOrProgram 订阅下会丢消息，连接池把订阅分散到订阅数最少的连接上，
+// 每个连接独立重连，所有连接的通知交给同一组处理函数
+type WebSocketPool struct {
+	clients []*WebSocketClient
+
+	mu      sync.Mutex
+	streams map[BlockStreamHandler]*dedupBlockStream // 多个连接共用的分块处理器
+}
+
+// WebSocketConnectionStatus 连接池中一个连接的状态
+type WebSocketConnectionStatus struct {
+	Index         int       `json:"index"`           // 连接序号
+	Connected     bool      `json:"connected"`       // 是否已连接
+	LastMessageAt time.Time `json:"last_message_at"` // 最近一次收到消息的时间
+	Subscriptions int       `json:"subscriptions"`   // 该连接上的订阅数，包括等待重新订阅的订阅
+
+	SubscriptionStats []SubscriptionStats `json:"subscription_stats"` // 各订阅的状态和通知统计
+}
+
+// NewWebSocketPool 按 websocket.pool_size 创建连接池并设置为全局实例，不建立连接
+// 第一个连接同时设置为 GlobalWebSocketClient
+func NewWebSocketPool(config *configs.WebSocketConfig) *WebSocketPool {
+	size := max(config.PoolSize, 1)
+	pool := &WebSocketPool{
+		clients: make([]*WebSocketClient, size),
+		streams: make(map[BlockStreamHandler]*dedupBlockStream),
+	}
+	for i := range pool.clients {
+		log := logger.Named("rpc.websocket")
+		if size > 1 {
+			log = log.With(zap.Int("connection", i))
+		}
+		pool.clients[i] = newWebSocketClient(config, log)
+	}
+	GlobalWebSocketPool = pool
+	GlobalWebSocketClient = pool.clients[0]
+	return pool
+}
+
+// Size 返回连接数
+func (p *WebSocketPool) Size() int {
+	return len(p.clients)
+}
+
+// Connect 建立所有尚未连接的连接，已连接的连接不受影响
+// 返回:
+//   - error: 各连接的失败原因，部分连接成功时已连接的连接仍可订阅
+func (p *WebSocketPool) Connect(ctx context.Context) error {
+	var errs []error
+	for i, client := range p.clients {
+		if client.IsConnected() {
+			continue
+		}
+		if err := client.Connect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("连接 %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭所有连接
+func (p *WebSocketPool) Close() error {
+	var errs []error
+	for _, client := range p.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsConnected 返回是否所有连接都已连接
+func (p *WebSocketPool) IsConnected() bool {
+	for _, client := range p.clients {
+		if !client.IsConnected() {
+			return false
+		}
+	}
+	return true
+}
+
+// LastMessageAt 返回所有连接中最近一次收到消息的时间
+func (p *WebSocketPool) LastMessageAt() time.Time {
+	var latest time.Time
+	for _, client := range p.clients {
+		if at := client.LastMessageAt(); at.After(latest) {
+			latest = at
+		}
+	}
+	return latest
+}
+
+// Status 返回各连接的状态
+func (p *WebSocketPool) Status() []WebSocketConnectionStatus {
+	status := make([]WebSocketConnectionStatus, len(p.clients))
+	for i, client := range p.clients {
+		status[i] = WebSocketConnectionStatus{
+			Index:         i,
+			Connected:     client.IsConnected(),
+			LastMessageAt: client.LastMessageAt(),
+			Subscriptions: client.SubscriptionCount(),
+
+			SubscriptionStats: client.SubscriptionStats(),
+		}
+	}
+	return status
+}
+
+// Reconnect 断开指定序号的连接，重连后恢复该连接上的订阅
+func (p *WebSocketPool) Reconnect(index int) error {
+	if index < 0 || index >= len(p.clients) {
+		return fmt.Errorf("连接 %d 不存在", index)
+	}
+	p.clients[index].Reconnect()
+	return nil
+}
+
+// SlotSubscribe 在订阅数最少的连接上订阅槽位更新
+func (p *WebSocketPool) SlotSubscribe(handler SubscriptionHandler) (int, error) {
+	return p.subscribe(func(client *WebSocketClient) (int, error) {
+		return client.SlotSubscribe(handler)
+	})
+}
+
+// BlockSubscribe 在订阅数最少的连接上订阅区块，参数与 WebSocketClient.BlockSubscribe 相同
+func (p *WebSocketPool) BlockSubscribe(filter interface{}, options map[string]interface{}, handler SubscriptionHandler) (int, error) {
+	return p.subscribe(func(client *WebSocketClient) (int, error) {
+		return client.BlockSubscribe(filter, options, handler)
+	})
+}
+
+// BlockStreamSubscribe 在订阅数最少的连接上订阅区块，参数与 WebSocketClient.BlockStreamSubscribe 相同
+// 同一个 handler 的多个订阅分布在不同连接上时，同一区块中已交给 handler 的交易不会重复交付
+func (p *WebSocketPool) BlockStreamSubscribe(filter interface{}, options map[string]interface{}, handler BlockStreamHandler) (int, error) {
+	stream := handler
+	if len(p.clients) > 1 {
+		p.mu.Lock()
+		dedup, ok := p.streams[handler]
+		if !ok {
+			dedup = newDedupBlockStream(handler)
+			p.streams[handler] = dedup
+		}
+		p.mu.Unlock()
+		stream = dedup
+	}
+	return p.subscribe(func(client *WebSocketClient) (int, error) {
+		return client.BlockStreamSubscribe(filter, options, stream)
+	})
+}
+
+// LogsSubscribe 在订阅数最少的连接上订阅交易日志，参数与 WebSocketClient.LogsSubscribe 相同
+func (p *WebSocketPool) LogsSubscribe(filter interface{}, commitment string, handler SubscriptionHandler) (int, error) {
+	return p.subscribe(func(client *WebSocketClient) (int, error) {
+		return client.LogsSubscribe(filter, commitment, handler)
+	})
+}
+
+// HasSubscription 返回订阅是否仍然有效，连接断开后订阅会在重连后自动恢复，仍然有效
+func (p *WebSocketPool) HasSubscription(subscriptionID int) bool {
+	for _, client := range p.clients {
+		if client.HasSubscription(subscriptionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unsubscribe 在持有该订阅的连接上取消订阅
+func (p *WebSocketPool) Unsubscribe(subscriptionID int) error {
+	for _, client := range p.clients {
+		if client.HasSubscription(subscriptionID) {
+			return client.Unsubscribe(subscriptionID)
+		}
+	}
+	return fmt.Errorf("订阅 %d 不存在", subscriptionID)
+}
+
+// subscribe 选择已连接且订阅数最少的连接执行订阅，返回客户端订阅ID
+func (p *WebSocketPool) subscribe(subscribe func(client *WebSocketClient) (int, error)) (int, error) {
+	var selected *WebSocketClient
+	fewest := 0
+	for _, client := range p.clients {
+		if !client.IsConnected() {
+			continue
+		}
+		if count := client.SubscriptionCount(); selected == nil || count < fewest {
+			selected, fewest = client, count
+		}
+	}
+	if selected == nil {
+		return 0, fmt.Errorf("%w: WebSocket连接未建立", ErrNetwork)
+	}
+	return subscribe(selected)
+}
+
+// dedupBlockStream 合并多个连接推送的区块通知，按签名丢弃同一区块中已交付的交易
+// 不同地址的 mentionsAccountOrProgram 订阅可能推送同一笔交易
+type dedupBlockStream struct {
+	next  BlockStreamHandler
+	mu    sync.Mutex
+	seen  map[uint64]map[string]struct{} // 槽位 -> 已交付的交易签名
+	slots []uint64                       // 按首次出现顺序记录的槽位，超过 dedupSlots 时淘汰最早的
+}
+
+// newDedupBlockStream 创建区块通知去重处理器
+func newDedupBlockStream(next BlockStreamHandler) *dedupBlockStream {
+	return &dedupBlockStream{next: next, seen: make(map[uint64]map[string]struct{})}
+}
+
+// OnTransactions 丢弃已交付的交易后交给下游
+func (d *dedupBlockStream) OnTransactions(slot uint64, transactions []resp.Transactions) {
+	d.mu.Lock()
+	seen, ok := d.seen[slot]
+	if !ok {
+		seen = make(map[string]struct{}, len(transactions))
+		d.seen[slot] = seen
+		d.slots = append(d.slots, slot)
+		if len(d.slots) > dedupSlots {
+			delete(d.seen, d.slots[0])
+			d.slots = d.slots[1:]
+		}
+	}
+	unique := make([]resp.Transactions, 0, len(transactions))
+	for _, transaction := range transactions {
+		if len(transaction.Transaction.Signatures) > 0 {
+			signature := transaction.Transaction.Signatures[0]
+			if _, duplicate := seen[signature]; duplicate {
+				continue
+			}
+			seen[signature] = struct{}{}
+		}
+		unique = append(unique, transaction)
+	}
+	d.mu.Unlock()
+	if len(unique) > 0 {
+		d.next.OnTransactions(slot, unique)
+	}
+}
+
+// OnBlock 直接交给下游，每个连接的区块通知分别结束
+func (d *dedupBlockStream) OnBlock(slot uint64, block resp.BlockResp, notificationErr json.RawMessage) {
+	d.next.OnBlock(slot, block, notificationErr)
+}
@@ -0,0 +1,485 @@
+package rpc
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/payload"
+	"go.uber.org/zap"
+)
+
+// WebhookType 定义了Webhook类型
+type WebhookType string
+
+// 定义Webhook类型常量
+const (
+	EnhancedWebhook       WebhookType = "enhanced"       // 推送Enhanced API解析后的交易
+	RawWebhook            WebhookType = "raw"            // 推送原始交易
+	DiscordWebhook        WebhookType = "discord"        // 推送到Discord频道
+	EnhancedDevnetWebhook WebhookType = "enhancedDevnet" // devnet解析后的交易
+	RawDevnetWebhook      WebhookType = "rawDevnet"      // devnet原始交易
+)
+
+// MaxWebhookAddresses Helius单个Webhook最多监控的地址数
+const MaxWebhookAddresses = 100000
+
+var (
+	// ErrTooManyWebhookAddresses 地址数超过单个Webhook上限
+	ErrTooManyWebhookAddresses = errors.New("Webhook地址数超过上限")
+	// ErrWebhookPayloadTooLarge 请求体超过Helius API限制(HTTP 413)
+	ErrWebhookPayloadTooLarge = errors.New("Webhook请求体超过API限制")
+	// ErrWebhookNotFound Webhook不存在(HTTP 404)
+	ErrWebhookNotFound = errors.New("Webhook不存在")
+)
+
+// WebhookError Webhook管理API返回的错误响应
+// 404 时 errors.Is(err, ErrWebhookNotFound) 为true，413 时为 ErrWebhookPayloadTooLarge，
+// 429 时为 ErrRateLimited 且可通过 RetryAfter 获取等待时间，401/403 时为 ErrUnauthorized
+type WebhookError struct {
+	Method     string // 请求方法
+	StatusCode int    // HTTP状态码
+	Message    string // 服务端返回的错误信息
+	err        error  // 状态码对应的错误分类，没有对应分类时为nil
+}
+
+func (e *WebhookError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Webhook API %s 请求失败，状态码: %d", e.Method, e.StatusCode)
+	}
+	return fmt.Sprintf("Webhook API %s 返回错误: %s (状态码: %d)", e.Method, e.Message, e.StatusCode)
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.err
+}
+
+// Temporary 是否为服务端的临时错误(5xx)，稍后重试可能成功
+func (e *WebhookError) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// Webhook 表示一个Helius Webhook
+type Webhook struct {
+	ID               string                 `json:"webhookID,omitempty"`  // Webhook ID，创建时由Helius生成
+	Wallet           string                 `json:"wallet,omitempty"`     // 所属账户
+	Webhook          string                 `json:"webhookURL"`           // 回调URL
+	WebhookType      WebhookType            `json:"webhookType"`          // Webhook类型
+	AccountAddresses []string               `json:"accountAddresses"`     // 监控的地址
+	TransactionTypes []resp.TransactionType `json:"transactionTypes"`     // 监控的交易类型
+	AuthHeader       string                 `json:"authHeader,omitempty"` // 回调时携带的Authorization头
+	TxnStatus        string                 `json:"txnStatus,omitempty"`  // 交易状态过滤: all, success, failed
+}
+
+// WebhookEvent Enhanced Webhook推送的事件，与Enhanced API解析结果结构相同
+type WebhookEvent = resp.ParsedTransaction
+
+// HeliusWebhookClient Helius Webhook管理API客户端
+type HeliusWebhookClient struct {
+	httpClient      *http.Client
+	endpoint        string
+	apiKey          string
+	maxAttempts     int           // 请求的最大尝试次数
+	retryBackoff    time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	maxRetryBackoff time.Duration // 重试等待时间的上限
+}
+
+var GlobalHeliusWebhookClient *HeliusWebhookClient
+
+// NewHeliusWebhookClient 从配置创建Helius Webhook管理API客户端
+func NewHeliusWebhookClient(config *configs.HeliusWebhookConfig) *HeliusWebhookClient {
+	client := &HeliusWebhookClient{
+		httpClient:      NewHTTPClient(&config.HTTP, config.ProxyURL),
+		endpoint:        strings.TrimSuffix(config.Endpoint, "/"),
+		apiKey:          config.APIKey,
+		maxAttempts:     max(config.MaxAttempts, 1),
+		retryBackoff:    config.RetryBackoff,
+		maxRetryBackoff: config.MaxRetryBackoff,
+	}
+	GlobalHeliusWebhookClient = client
+	return client
+}
+
+// CreateWebhook 创建Webhook
+// 超时或5xx时无法确定是否已经创建，因此只在请求被限流时重试；需要重试时使用 UpsertWebhook，避免创建重复的Webhook
+// 参数:
+//   - ctx: 上下文
+//   - webhook: Webhook配置
+//
+// 返回:
+//   - *Webhook: 创建后的Webhook，包含ID
+//   - error: 错误信息
+func (c *HeliusWebhookClient) CreateWebhook(ctx context.Context, webhook Webhook) (*Webhook, error) {
+	if err := checkWebhookAddresses(webhook); err != nil {
+		return nil, fmt.Errorf("创建Webhook失败: %w", err)
+	}
+	var created Webhook
+	if err := c.do(ctx, http.MethodPost, "", webhook, &created); err != nil {
+		return nil, fmt.Errorf("创建Webhook失败: %w", err)
+	}
+	return &created, nil
+}
+
+// UpsertWebhook 按回调URL创建或修改Webhook：已存在相同回调URL的Webhook时整体替换其配置，否则创建新的Webhook
+// 创建请求超时或返回5xx时先重新按回调URL查找，确认没有创建成功后才再次创建，重试不会产生重复的Webhook。
+// 存在多个相同回调URL的Webhook时修改第一个
+// 参数:
+//   - ctx: 上下文
+//   - webhook: Webhook配置，按 Webhook 字段(回调URL)识别，ID会被忽略
+//
+// 返回:
+//   - *Webhook: 创建或修改后的Webhook
+//   - bool: 是否新建
+//   - error: 错误信息
+func (c *HeliusWebhookClient) UpsertWebhook(ctx context.Context, webhook Webhook) (*Webhook, bool, error) {
+	if webhook.Webhook == "" {
+		return nil, false, errors.New("Webhook回调URL不能为空")
+	}
+	webhook.ID = ""
+	for attempt := 1; ; attempt++ {
+		existing, err := c.findWebhookByURL(ctx, webhook.Webhook)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil {
+			updated, err := c.EditWebhook(ctx, existing.ID, webhook)
+			if err != nil {
+				return nil, false, err
+			}
+			return updated, false, nil
+		}
+		created, err := c.CreateWebhook(ctx, webhook)
+		if err == nil {
+			return created, true, nil
+		}
+		if !retryableWebhookError(err) || attempt >= c.maxAttempts {
+			return nil, false, err
+		}
+		logger.Warn("创建Webhook结果未知，重新查找后重试", zap.String("url", webhook.Webhook), zap.Int("attempt", attempt), zap.Error(err))
+		if err := c.wait(ctx, attempt, err); err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// findWebhookByURL 按回调URL查找Webhook，不存在时返回nil
+func (c *HeliusWebhookClient) findWebhookByURL(ctx context.Context, webhookURL string) (*Webhook, error) {
+	webhooks, err := c.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		if webhooks[i].Webhook == webhookURL {
+			return &webhooks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetWebhooks 获取所有Webhook
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - []Webhook: Webhook列表
+//   - error: 错误信息
+func (c *HeliusWebhookClient) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := c.do(ctx, http.MethodGet, "", nil, &webhooks); err != nil {
+		return nil, fmt.Errorf("获取Webhook列表失败: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook 获取指定Webhook
+// 参数:
+//   - ctx: 上下文
+//   - id: Webhook ID
+//
+// 返回:
+//   - *Webhook: Webhook
+//   - error: 错误信息
+func (c *HeliusWebhookClient) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodGet, id, nil, &webhook); err != nil {
+		return nil, fmt.Errorf("获取Webhook失败: %w", err)
+	}
+	return &webhook, nil
+}
+
+// EditWebhook 修改指定Webhook，Helius会使用新配置整体替换原配置
+// 参数:
+//   - ctx: 上下文
+//   - id: Webhook ID
+//   - webhook: 新的Webhook配置
+//
+// 返回:
+//   - *Webhook: 修改后的Webhook
+//   - error: 错误信息，地址数超过 MaxWebhookAddresses 时不发送请求，返回 ErrTooManyWebhookAddresses
+func (c *HeliusWebhookClient) EditWebhook(ctx context.Context, id string, webhook Webhook) (*Webhook, error) {
+	if err := checkWebhookAddresses(webhook); err != nil {
+		return nil, fmt.Errorf("编辑Webhook失败: %w", err)
+	}
+	var updated Webhook
+	if err := c.do(ctx, http.MethodPut, id, webhook, &updated); err != nil {
+		return nil, fmt.Errorf("编辑Webhook失败: %w", err)
+	}
+	return &updated, nil
+}
+
+// checkWebhookAddresses 发送请求前检查地址数，超过 MaxWebhookAddresses 时返回 ErrTooManyWebhookAddresses
+func checkWebhookAddresses(webhook Webhook) error {
+	if len(webhook.AccountAddresses) > MaxWebhookAddresses {
+		return fmt.Errorf("%w: %d 个，上限 %d 个", ErrTooManyWebhookAddresses, len(webhook.AccountAddresses), MaxWebhookAddresses)
+	}
+	return nil
+}
+
+// DeleteWebhook 删除指定Webhook
+// 参数:
+//   - ctx: 上下文
+//   - id: Webhook ID
+//
+// 返回:
+//   - error: 错误信息
+func (c *HeliusWebhookClient) DeleteWebhook(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodDelete, id, nil, nil); err != nil {
+		return fmt.Errorf("删除Webhook失败: %w", err)
+	}
+	return nil
+}
+
+// AppendAddressesToWebhook 向指定Webhook追加监控地址，已存在的地址会被忽略
+// Helius只支持整体替换地址列表，因此会先获取当前配置，合并新地址后以一次请求提交完整的地址列表
+// 参数:
+//   - ctx: 上下文
+//   - id: Webhook ID
+//   - addresses: 需要追加的地址
+//
+// 返回:
+//   - *Webhook: 追加后的Webhook
+//   - error: 错误信息，合并后超过 MaxWebhookAddresses 时不发送请求，返回 ErrTooManyWebhookAddresses；
+//     请求体超过API限制(HTTP 413)时为 ErrWebhookPayloadTooLarge
+func (c *HeliusWebhookClient) AppendAddressesToWebhook(ctx context.Context, id string, addresses []string) (*Webhook, error) {
+	webhook, err := c.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]struct{}, len(webhook.AccountAddresses))
+	for _, address := range webhook.AccountAddresses {
+		existing[address] = struct{}{}
+	}
+	var added []string
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, ok := existing[address]; ok {
+			continue
+		}
+		existing[address] = struct{}{}
+		added = append(added, address)
+	}
+	if len(added) == 0 {
+		return webhook, nil
+	}
+	if total := len(webhook.AccountAddresses) + len(added); total > MaxWebhookAddresses {
+		return nil, fmt.Errorf("%w: 现有 %d 个，新增 %d 个，上限 %d 个", ErrTooManyWebhookAddresses, len(webhook.AccountAddresses), len(added), MaxWebhookAddresses)
+	}
+
+	next := *webhook
+	next.AccountAddresses = append(slices.Clip(webhook.AccountAddresses), added...)
+	updated, err := c.EditWebhook(ctx, id, next)
+	if err != nil {
+		return nil, fmt.Errorf("追加Webhook地址失败: %w", err)
+	}
+	return updated, nil
+}
+
+// RemoveAddressesFromWebhook 从指定Webhook移除监控地址，不存在的地址会被忽略
+// 参数:
+//   - ctx: 上下文
+//   - id: Webhook ID
+//   - addresses: 需要移除的地址
+//
+// 返回:
+//   - *Webhook: 移除后的Webhook
+//   - error: 错误信息
+func (c *HeliusWebhookClient) RemoveAddressesFromWebhook(ctx context.Context, id string, addresses []string) (*Webhook, error) {
+	webhook, err := c.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		removed[strings.TrimSpace(address)] = struct{}{}
+	}
+	remaining := make([]string, 0, len(webhook.AccountAddresses))
+	for _, address := range webhook.AccountAddresses {
+		if _, ok := removed[address]; !ok {
+			remaining = append(remaining, address)
+		}
+	}
+	if len(remaining) == len(webhook.AccountAddresses) {
+		return webhook, nil
+	}
+
+	next := *webhook
+	next.AccountAddresses = remaining
+	updated, err := c.EditWebhook(ctx, id, next)
+	if err != nil {
+		return nil, fmt.Errorf("移除Webhook地址失败: %w", err)
+	}
+	return updated, nil
+}
+
+// do 发送Webhook管理API请求，id为空时请求Webhook集合
+// 网络错误、限流和5xx时按退避时间重试；POST请求只在被限流时重试，其他失败无法确定是否已经创建。
+// DELETE重试时返回404说明之前的请求已经删除成功，按成功处理
+func (c *HeliusWebhookClient) do(ctx context.Context, method string, id string, body interface{}, out interface{}) error {
+	for attempt := 1; ; attempt++ {
+		err := c.doOnce(ctx, method, id, body, out)
+		if err == nil {
+			return nil
+		}
+		if method == http.MethodDelete && attempt > 1 && errors.Is(err, ErrWebhookNotFound) {
+			return nil
+		}
+		retryable := errors.Is(err, ErrRateLimited) || (method != http.MethodPost && retryableWebhookError(err))
+		if !retryable || attempt >= c.maxAttempts {
+			return err
+		}
+		logger.Warn("Webhook管理API请求失败，稍后重试", zap.String("method", method), zap.Int("attempt", attempt), zap.Error(err))
+		if err := c.wait(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// retryableWebhookError 网络错误、限流和5xx重试可能成功
+func retryableWebhookError(err error) bool {
+	if errors.Is(err, ErrNetwork) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var webhookErr *WebhookError
+	return errors.As(err, &webhookErr) && webhookErr.Temporary()
+}
+
+// wait 第 attempt 次失败后等待重试，限流时按服务端要求的时间等待，ctx结束时返回原错误
+func (c *HeliusWebhookClient) wait(ctx context.Context, attempt int, err error) error {
+	delay, ok := RetryAfter(err)
+	if !ok {
+		delay = c.retryBackoff << (attempt - 1)
+		if c.maxRetryBackoff > 0 && (delay > c.maxRetryBackoff || delay <= 0) {
+			delay = c.maxRetryBackoff
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return err
+	case <-clock.After(delay):
+		return nil
+	}
+}
+
+// doOnce 发送一次Webhook管理API请求
+func (c *HeliusWebhookClient) doOnce(ctx context.Context, method string, id string, body interface{}, out interface{}) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("未配置 helius_webhook.api_key")
+	}
+	apiURL := c.endpoint + "/v0/webhooks"
+	if id != "" {
+		apiURL += "/" + url.PathEscape(id)
+	}
+	apiURL += "?api-key=" + url.QueryEscape(c.apiKey)
+
+	var reader io.Reader
+	var requestSize int
+	if body != nil {
+		requestJSON, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("序列化请求失败: %w", err)
+		}
+		requestSize = len(requestJSON)
+		reader = bytes.NewReader(requestJSON)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return fmt.Errorf("创建 HTTP 请求失败: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: 发送 HTTP 请求失败: %w", ErrNetwork, redactError(err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: 读取响应失败: %w", ErrNetwork, err)
+	}
+	if err := webhookStatusError(method, resp, respBody, requestSize); err != nil {
+		return err
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// webhookStatusError 将非2xx的响应转换为 WebhookError，2xx时返回nil
+func webhookStatusError(method string, httpResp *http.Response, body []byte, requestSize int) error {
+	err := statusError(httpResp, body)
+	if err == nil {
+		return nil
+	}
+	webhookErr := &WebhookError{Method: method, StatusCode: httpResp.StatusCode, Message: errorMessage(body)}
+	switch httpResp.StatusCode {
+	case http.StatusNotFound:
+		webhookErr.err = ErrWebhookNotFound
+	case http.StatusRequestEntityTooLarge:
+		webhookErr.err = ErrWebhookPayloadTooLarge
+		webhookErr.Message = cmp.Or(webhookErr.Message, fmt.Sprintf("请求体 %d 字节", requestSize))
+	case http.StatusTooManyRequests, http.StatusUnauthorized, http.StatusForbidden:
+		webhookErr.err = err
+	}
+	return webhookErr
+}
+
+// HandleWebhookEvent 解析Enhanced Webhook推送的请求体并交给处理函数
+// 参数:
+//   - body: 回调请求体，为交易数组
+//   - handler: 事件处理函数
+//
+// 返回:
+//   - error: 解析或处理失败时的错误信息
+func HandleWebhookEvent(body []byte, handler func(events []WebhookEvent) error) error {
+	var events []WebhookEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		payload.Sample("webhook", "", body, err)
+		return fmt.Errorf("解析Webhook事件失败: %w", err)
+	}
+	return handler(events)
+}
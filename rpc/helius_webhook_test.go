@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// webhookServer 模拟Webhook管理API，记录每次PUT请求的地址列表
+type webhookServer struct {
+	mu        sync.Mutex
+	addresses []string
+	puts      [][]string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var webhook Webhook
+		if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.puts = append(s.puts, webhook.AccountAddresses)
+		s.addresses = webhook.AccountAddresses
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(Webhook{ID: "hook", Webhook: "https://example.com/hook", AccountAddresses: s.addresses})
+}
+
+func newTestWebhookClient(t *testing.T, addresses []string) (*HeliusWebhookClient, *webhookServer) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	server := &webhookServer{addresses: addresses}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	previous := GlobalHeliusWebhookClient
+	t.Cleanup(func() { GlobalHeliusWebhookClient = previous })
+	client := NewHeliusWebhookClient(&configs.HeliusWebhookConfig{Endpoint: httpServer.URL, APIKey: "key", MaxAttempts: 1})
+	return client, server
+}
+
+func TestAppendAddressesToWebhook(t *testing.T) {
+	client, server := newTestWebhookClient(t, []string{"A", "B"})
+	webhook, err := client.AppendAddressesToWebhook(context.Background(), "hook", []string{"B", " C ", "", "D", "C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"A", "B", "C", "D"}
+	if len(server.puts) != 1 || !slices.Equal(server.puts[0], want) {
+		t.Fatalf("应以一次PUT提交完整的地址列表 %v，实际为 %v", want, server.puts)
+	}
+	if !slices.Equal(webhook.AccountAddresses, want) {
+		t.Fatalf("返回的地址列表错误: %v", webhook.AccountAddresses)
+	}
+
+	// 没有新地址时不发送PUT
+	if _, err := client.AppendAddressesToWebhook(context.Background(), "hook", []string{"A"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.puts) != 1 {
+		t.Fatalf("没有新地址时不应发送PUT: %v", server.puts)
+	}
+}
+
+func TestAppendAddressesToWebhookTooMany(t *testing.T) {
+	existing := make([]string, MaxWebhookAddresses-1)
+	for i := range existing {
+		existing[i] = fmt.Sprintf("addr%d", i)
+	}
+	client, server := newTestWebhookClient(t, existing)
+	_, err := client.AppendAddressesToWebhook(context.Background(), "hook", []string{"X", "Y"})
+	if !errors.Is(err, ErrTooManyWebhookAddresses) {
+		t.Fatalf("超过上限应返回 ErrTooManyWebhookAddresses: %v", err)
+	}
+	if len(server.puts) != 0 {
+		t.Fatalf("超过上限时不应发送PUT，实际发送了 %d 次", len(server.puts))
+	}
+	if _, err := client.EditWebhook(context.Background(), "hook", Webhook{AccountAddresses: append(existing, "X", "Y")}); !errors.Is(err, ErrTooManyWebhookAddresses) {
+		t.Fatalf("EditWebhook 超过上限应返回 ErrTooManyWebhookAddresses: %v", err)
+	}
+	if len(server.puts) != 0 {
+		t.Fatalf("超过上限时不应发送PUT，实际发送了 %d 次", len(server.puts))
+	}
+}
+
+func TestRemoveAddressesFromWebhook(t *testing.T) {
+	client, server := newTestWebhookClient(t, []string{"A", "B", "C"})
+	if _, err := client.RemoveAddressesFromWebhook(context.Background(), "hook", []string{"B", "Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.puts) != 1 || !slices.Equal(server.puts[0], []string{"A", "C"}) {
+		t.Fatalf("应以一次PUT提交剩余的地址列表，实际为 %v", server.puts)
+	}
+	if _, err := client.RemoveAddressesFromWebhook(context.Background(), "hook", []string{"Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.puts) != 1 {
+		t.Fatalf("没有需要移除的地址时不应发送PUT: %v", server.puts)
+	}
+}
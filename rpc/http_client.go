@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"go.uber.org/zap"
+)
+
+// NewHTTPClient 按配置创建HTTP客户端
+// 参数:
+//   - config: 超时、连接池和TLS设置
+//   - proxyURL: 代理服务器URL，为空时使用环境变量中的代理
+//
+// 返回:
+//   - *http.Client: HTTP客户端
+func NewHTTPClient(config *configs.HTTPClientConfig, proxyURL string) *http.Client {
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: newHTTPTransport(config, proxyURL),
+	}
+}
+
+// newHTTPTransport 按配置创建Transport，代理URL的协议为 socks5/socks5h 时通过SOCKS5代理建立连接，
+// 代理URL无法解析时记录错误并使用环境变量中的代理
+func newHTTPTransport(config *configs.HTTPClientConfig, proxyURL string) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     config.HTTP2,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+	}
+	if config.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		logger.Warn("HTTP客户端已关闭TLS证书校验，仅用于调试或会替换证书的代理")
+	}
+	if !config.HTTP2 {
+		// 非nil的空map禁止协商HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		switch {
+		case err != nil:
+			logger.Error("解析代理URL失败", zap.Error(err))
+		case isSOCKSProxy(parsed):
+			dialContext, err := socksDialContext(parsed, dialer)
+			if err != nil {
+				logger.Error("设置SOCKS5代理失败", zap.Error(err))
+				break
+			}
+			transport.Proxy = nil
+			transport.DialContext = dialContext
+		default:
+			transport.Proxy = http.ProxyURL(parsed)
+		}
+	}
+	return transport
+}
+
+// redactError 去除请求错误(*url.Error)中URL的查询参数和用户信息，API密钥放在查询参数中的请求失败时避免密钥写入日志
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURL(urlErr.URL)
+	}
+	return err
+}
@@ -0,0 +1,309 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+const (
+	// 价格缓存超过该数量时清理过期的价格
+	maxCachedPrices = 10000
+	// 等待后台刷新的代币数量上限，超过时不再加入，下次查询时重新加入
+	maxPendingPrices = 1000
+	// 后台刷新一次的最长时间
+	refreshTimeout = 30 * time.Second
+)
+
+// GlobalJupiterPriceClient 全局Jupiter价格客户端，未启用时为nil
+var GlobalJupiterPriceClient *JupiterPriceClient
+
+// JupiterPriceClient Jupiter价格API客户端
+// 价格按代币缓存 cache_ttl，请求之间至少间隔 min_interval，多个协程同时查询时排队等待；
+// 解析路径通过 CachedPrice 只读缓存，缺失的代币由后台刷新按 batch_size 合并请求
+type JupiterPriceClient struct {
+	httpClient  *http.Client
+	endpoint    string
+	apiKey      string
+	cacheTTL    time.Duration
+	minInterval time.Duration
+	batchSize   int
+	log         *zap.Logger
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedPrice
+
+	limiterMu   sync.Mutex
+	nextRequest time.Time // 下一次请求最早可以发出的时间
+
+	pendingMu sync.Mutex
+	pending   map[string]struct{} // 等待后台刷新的代币
+	refresh   chan struct{}       // 有代币等待刷新时通知后台刷新
+	cancel    context.CancelFunc
+}
+
+// cachedPrice 缓存的价格，found 为false表示Jupiter没有该代币的价格，同样缓存以免重复请求
+type cachedPrice struct {
+	price    float64
+	found    bool
+	cachedAt time.Time
+}
+
+// jupiterPrice Jupiter价格API v3返回的单个代币价格
+type jupiterPrice struct {
+	USDPrice float64 `json:"usdPrice"`
+}
+
+// NewJupiterPriceClient 创建Jupiter价格客户端并设置为全局实例
+func NewJupiterPriceClient(config *configs.JupiterPriceConfig) *JupiterPriceClient {
+	client := &JupiterPriceClient{
+		httpClient:  NewHTTPClient(&config.HTTP, config.ProxyURL),
+		endpoint:    config.Endpoint,
+		apiKey:      config.APIKey,
+		cacheTTL:    config.CacheTTL,
+		minInterval: config.MinInterval,
+		batchSize:   max(config.BatchSize, 1),
+		log:         logger.Named("rpc.jupiter_price"),
+		cache:       make(map[string]cachedPrice),
+		pending:     make(map[string]struct{}),
+		refresh:     make(chan struct{}, 1),
+	}
+	GlobalJupiterPriceClient = client
+	return client
+}
+
+// GetPrices 查询代币的美元价格，优先使用缓存
+// 参数:
+//   - ctx: 上下文，等待限流和请求都受其控制
+//   - mints: 代币地址
+//
+// 返回:
+//   - map[string]float64: 有价格的代币，Jupiter没有价格的代币不在其中
+//   - error: 请求失败时的错误信息，此前已取得的价格仍会返回
+func (c *JupiterPriceClient) GetPrices(ctx context.Context, mints []string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(mints))
+	var missing []string
+	now := clock.Now()
+	c.cacheMu.Lock()
+	for _, mint := range mints {
+		cached, ok := c.cache[mint]
+		if ok && now.Sub(cached.cachedAt) < c.cacheTTL {
+			if cached.found {
+				prices[mint] = cached.price
+			}
+			continue
+		}
+		if mint != "" && !slices.Contains(missing, mint) {
+			missing = append(missing, mint)
+		}
+	}
+	c.cacheMu.Unlock()
+
+	for batch := range slices.Chunk(missing, c.batchSize) {
+		fetched, err := c.fetch(ctx, batch)
+		if err != nil {
+			return prices, err
+		}
+		cachedAt := clock.Now()
+		c.cacheMu.Lock()
+		for _, mint := range batch {
+			price, found := fetched[mint]
+			c.cache[mint] = cachedPrice{price: price, found: found, cachedAt: cachedAt}
+			if found {
+				prices[mint] = price
+			}
+		}
+		if len(c.cache) > maxCachedPrices {
+			for mint, cached := range c.cache {
+				if cachedAt.Sub(cached.cachedAt) >= c.cacheTTL {
+					delete(c.cache, mint)
+				}
+			}
+		}
+		c.cacheMu.Unlock()
+	}
+	return prices, nil
+}
+
+// CachedPrice 从缓存查询代币的美元价格，不发出请求，用于不能等待网络请求的解析路径
+// 缓存中没有或已过期时把代币加入待刷新列表，由 Start 启动的后台刷新获取；
+// 过期不超过一个 cache_ttl 的价格在刷新完成前仍会返回
+// 返回:
+//   - float64: 美元价格
+//   - time.Time: 价格的获取时间
+//   - bool: 缓存中是否有该代币的价格
+func (c *JupiterPriceClient) CachedPrice(mint string) (float64, time.Time, bool) {
+	if mint == "" {
+		return 0, time.Time{}, false
+	}
+	c.cacheMu.Lock()
+	cached, ok := c.cache[mint]
+	c.cacheMu.Unlock()
+	age := clock.Since(cached.cachedAt)
+	if !ok || age >= c.cacheTTL {
+		c.schedule(mint)
+	}
+	if !ok || !cached.found || age >= 2*c.cacheTTL {
+		return 0, time.Time{}, false
+	}
+	return cached.price, cached.cachedAt, true
+}
+
+// schedule 把代币加入待刷新列表并通知后台刷新
+func (c *JupiterPriceClient) schedule(mint string) {
+	c.pendingMu.Lock()
+	if len(c.pending) < maxPendingPrices {
+		c.pending[mint] = struct{}{}
+	}
+	c.pendingMu.Unlock()
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动后台刷新，获取 CachedPrice 缺失或过期的代币价格
+func (c *JupiterPriceClient) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	supervisor.Go(ctx, "rpc.jupiter_price.refresh", c.run)
+}
+
+// Close 停止后台刷新
+func (c *JupiterPriceClient) Close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// run 收到通知后取出所有待刷新的代币，由 GetPrices 按 batch_size 分批、按 min_interval 限流请求
+func (c *JupiterPriceClient) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.refresh:
+		}
+		c.pendingMu.Lock()
+		mints := make([]string, 0, len(c.pending))
+		for mint := range c.pending {
+			mints = append(mints, mint)
+		}
+		clear(c.pending)
+		c.pendingMu.Unlock()
+		if len(mints) == 0 {
+			continue
+		}
+
+		refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+		// 失败的代币不写入缓存，下次查询时重新加入待刷新列表
+		if _, err := c.GetPrices(refreshCtx, mints); err != nil {
+			c.log.Warn("刷新Jupiter价格失败", zap.Int("mints", len(mints)), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// GetPrice 查询单个代币的美元价格
+// 返回:
+//   - float64: 美元价格
+//   - bool: Jupiter是否有该代币的价格
+//   - error: 请求失败时的错误信息
+func (c *JupiterPriceClient) GetPrice(ctx context.Context, mint string) (float64, bool, error) {
+	prices, err := c.GetPrices(ctx, []string{mint})
+	price, ok := prices[mint]
+	return price, ok, err
+}
+
+// fetch 等待限流后请求一批代币的价格
+func (c *JupiterPriceClient) fetch(ctx context.Context, mints []string) (map[string]float64, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	apiURL := c.endpoint + "?ids=" + url.QueryEscape(strings.Join(mints, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("x-api-key", c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 请求Jupiter价格失败: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取Jupiter价格响应失败: %w", ErrNetwork, err)
+	}
+	if err := statusError(resp, body); err != nil {
+		if wait, ok := RetryAfter(err); ok {
+			c.delay(wait)
+		}
+		return nil, err
+	}
+
+	var result map[string]*jupiterPrice
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析Jupiter价格响应失败: %w", err)
+	}
+	prices := make(map[string]float64, len(result))
+	for mint, price := range result {
+		if price != nil && price.USDPrice > 0 {
+			prices[mint] = price.USDPrice
+		}
+	}
+	c.log.Debug("已获取Jupiter价格", zap.Int("requested", len(mints)), zap.Int("priced", len(prices)))
+	return prices, nil
+}
+
+// wait 占用下一个请求时间点，需要时等待到该时间点；等待期间 ctx 取消时归还占用的时间点
+func (c *JupiterPriceClient) wait(ctx context.Context) error {
+	c.limiterMu.Lock()
+	now := clock.Now()
+	at := c.nextRequest
+	if at.Before(now) {
+		at = now
+	}
+	c.nextRequest = at.Add(c.minInterval)
+	c.limiterMu.Unlock()
+
+	if delay := at.Sub(now); delay > 0 {
+		select {
+		case <-clock.After(delay):
+		case <-ctx.Done():
+			c.limiterMu.Lock()
+			// 之后没有其他请求占用时间点时归还，否则后续请求已排在其后，保持不变
+			if c.nextRequest.Equal(at.Add(c.minInterval)) {
+				c.nextRequest = at
+			}
+			c.limiterMu.Unlock()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// delay 被限流时按服务端要求推迟后续请求
+func (c *JupiterPriceClient) delay(wait time.Duration) {
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+	if at := clock.Now().Add(wait); at.After(c.nextRequest) {
+		c.nextRequest = at
+	}
+}
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// priceServer 模拟Jupiter价格API，记录每次请求的代币
+type priceServer struct {
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func (s *priceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	s.mu.Lock()
+	s.requests = append(s.requests, ids)
+	s.mu.Unlock()
+	result := make(map[string]jupiterPrice, len(ids))
+	for _, id := range ids {
+		result[id] = jupiterPrice{USDPrice: 1.5}
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func newTestPriceClient(t *testing.T, config configs.JupiterPriceConfig) (*JupiterPriceClient, *priceServer) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	server := &priceServer{}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	previous := GlobalJupiterPriceClient
+	t.Cleanup(func() { GlobalJupiterPriceClient = previous })
+	config.Endpoint = httpServer.URL
+	return NewJupiterPriceClient(&config), server
+}
+
+func TestJupiterPriceWaitReleasesSlotOnCancel(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+	client, _ := newTestPriceClient(t, configs.JupiterPriceConfig{MinInterval: time.Second, BatchSize: 1})
+
+	if err := client.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	next := client.nextRequest
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.wait(ctx); err == nil {
+		t.Fatal("ctx 已取消时应返回错误")
+	}
+	if !client.nextRequest.Equal(next) {
+		t.Fatalf("取消的请求应归还占用的时间点: next=%s 期望 %s", client.nextRequest, next)
+	}
+}
+
+func TestJupiterCachedPriceRefreshesInBackground(t *testing.T) {
+	client, server := newTestPriceClient(t, configs.JupiterPriceConfig{CacheTTL: time.Minute, BatchSize: 2})
+	client.Start()
+	t.Cleanup(client.Close)
+
+	mints := []string{"A", "B", "C"}
+	for _, mint := range mints {
+		if _, _, ok := client.CachedPrice(mint); ok {
+			t.Fatalf("缓存中没有 %s 时不应返回价格", mint)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for _, mint := range mints {
+		for {
+			price, _, ok := client.CachedPrice(mint)
+			if ok {
+				if price != 1.5 {
+					t.Fatalf("%s 的价格 = %v", mint, price)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("等待后台刷新 %s 的价格超时", mint)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for _, ids := range server.requests {
+		if len(ids) > 2 {
+			t.Fatalf("每次请求最多查询 batch_size 个代币: %v", ids)
+		}
+	}
+}
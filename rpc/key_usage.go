@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/life2you/datas-go/metrics"
+)
+
+// UsageRecorder 记录每个Enhanced API密钥的用量
+type UsageRecorder interface {
+	// RecordUsage 记录一次请求，index 为密钥在 api_keys 中的索引，code 为失败请求的错误码，为空表示请求成功
+	RecordUsage(index int, credits int, code string)
+}
+
+// usageRecorder 设置后每次Enhanced API请求都会记录用量
+var usageRecorder UsageRecorder
+
+// SetEnhancedUsageRecorder 设置Enhanced API密钥用量的记录方式
+func SetEnhancedUsageRecorder(recorder UsageRecorder) {
+	usageRecorder = recorder
+}
+
+// KeyID 返回API密钥的指纹，用于在统计和Redis键名中区分密钥而不暴露密钥本身
+func KeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:4])
+}
+
+// MaskKey 返回脱敏后的API密钥，只保留最后4个字符
+func MaskKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "****" + apiKey[len(apiKey)-4:]
+}
+
+// Index 返回客户端的密钥在 api_keys 中的索引
+func (c *HeliusEnhancedApiClient) Index() int {
+	return c.index
+}
+
+// KeyID 返回客户端的密钥指纹
+func (c *HeliusEnhancedApiClient) KeyID() string {
+	return c.keyID
+}
+
+// recordUsage 记录一次请求的用量
+func (c *HeliusEnhancedApiClient) recordUsage(credits int, code string) {
+	metrics.AddAPIKeyUsage(c.keyID, credits, code)
+	if usageRecorder != nil {
+		usageRecorder.RecordUsage(c.index, credits, code)
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
 	"net/url"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/life2you/datas-go/clock"
 	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/payload"
+	"github.com/life2you/datas-go/supervisor"
+	"go.uber.org/zap"
 )
 
 const (
@@ -33,6 +38,9 @@ type PumpPortalClient struct {
 	closed          bool
 	connMutex       sync.Mutex
 	proxyURL        string
+	log             *zap.Logger
+	lastMessageAt   atomic.Int64 // 最近一次收到消息的时间(Unix纳秒)
+	reconnectHooks  []func()     // 重连成功后调用，用于重新订阅
 }
 
 // PumpPortalMessage 表示从PumpPortal接收到的消息
@@ -76,6 +84,7 @@ func NewPumpPortalClient(options *configs.PumpPortalOptions, handler MessageHand
 		reconnect:      true,
 		reconnectDelay: options.ReconnectDelay,
 		proxyURL:       options.ProxyURL,
+		log:            logger.Named("rpc.pump_portal").With(zap.String("url", PumpPortalWSURL)),
 	}
 }
 
@@ -104,15 +113,13 @@ func (c *PumpPortalClient) Connect(ctx context.Context) error {
 
 	// 如果配置了代理，设置代理
 	if c.proxyURL != "" {
-		proxyURL, err := url.Parse(c.proxyURL)
-		if err != nil {
-			return fmt.Errorf("解析代理URL失败: %w", err)
-		}
 		dialer = &websocket.Dialer{
-			Proxy:            http.ProxyURL(proxyURL),
 			HandshakeTimeout: 45 * time.Second,
 		}
-		log.Printf("使用代理连接PumpPortal WebSocket: %s", c.proxyURL)
+		if err := applyWebSocketProxy(dialer, c.proxyURL); err != nil {
+			return err
+		}
+		c.log.Info("使用代理连接PumpPortal WebSocket", zap.String("proxy", redactURL(c.proxyURL)))
 	}
 
 	// 建立连接
@@ -122,13 +129,13 @@ func (c *PumpPortalClient) Connect(ctx context.Context) error {
 	}
 
 	c.conn = conn
-	log.Printf("成功连接到PumpPortal WebSocket服务器")
+	c.log.Info("成功连接到PumpPortal WebSocket服务器")
 
 	// 启动消息接收循环
-	go c.readLoop()
+	supervisor.Go(context.Background(), "rpc.pump_portal.read", func(context.Context) { c.readLoop() })
 
 	// 启动心跳检测
-	go c.pingLoop()
+	supervisor.Go(context.Background(), "rpc.pump_portal.ping", func(context.Context) { c.pingLoop() })
 
 	return nil
 }
@@ -157,11 +164,26 @@ func (c *PumpPortalClient) Close() error {
 	return nil
 }
 
+// IsConnected 返回WebSocket当前是否处于连接状态
+func (c *PumpPortalClient) IsConnected() bool {
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
+	return c.conn != nil && !c.closed
+}
+
+// LastMessageAt 返回最近一次收到消息的时间，尚未收到消息时返回零值
+func (c *PumpPortalClient) LastMessageAt() time.Time {
+	if nanos := c.lastMessageAt.Load(); nanos > 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
 // 读取消息的循环
 func (c *PumpPortalClient) readLoop() {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("PumpPortal WebSocket读取循环发生意外: %v", r)
+			c.log.Error("PumpPortal WebSocket读取循环发生意外", zap.Any("panic", r))
 		}
 		c.handleDisconnect()
 	}()
@@ -173,20 +195,27 @@ func (c *PumpPortalClient) readLoop() {
 		default:
 			_, message, err := c.conn.ReadMessage()
 			if err != nil {
-				log.Printf("读取PumpPortal WebSocket消息错误: %v", err)
+				c.log.Error("读取PumpPortal WebSocket消息错误", zap.Error(err))
 				return
 			}
+			c.lastMessageAt.Store(clock.Now().UnixNano())
 
 			var msg PumpPortalMessage
 			if err := json.Unmarshal(message, &msg); err != nil {
-				log.Printf("解析PumpPortal WebSocket消息错误: %v", err)
+				if payload.Sample("pump_portal", "", message, err) {
+					c.log.Error("解析PumpPortal WebSocket消息错误", zap.Error(err))
+				}
 				continue
 			}
 
 			// 根据消息类型调用相应的处理函数
 			c.handlersMutex.RLock()
-			go c.handler(message)
+			handler := c.handler
 			c.handlersMutex.RUnlock()
+			go func() {
+				defer supervisor.Recover("rpc.pump_portal_handler", nil)
+				handler(message)
+			}()
 		}
 	}
 }
@@ -218,7 +247,7 @@ func (c *PumpPortalClient) handleDisconnect() {
 
 	// 启动重连计时器
 	c.reconnectTicker = time.NewTicker(c.reconnectDelay)
-	go func() {
+	supervisor.Go(context.Background(), "rpc.pump_portal.reconnect", func(context.Context) {
 		for {
 			select {
 			case <-c.done:
@@ -226,7 +255,7 @@ func (c *PumpPortalClient) handleDisconnect() {
 			case <-c.reconnectTicker.C:
 				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 				if err := c.Connect(ctx); err != nil {
-					log.Printf("重连PumpPortal WebSocket失败: %v, 将在%v后重试", err, c.reconnectDelay)
+					c.log.Error("重连PumpPortal WebSocket失败，稍后重试", zap.Duration("reconnectDelay", c.reconnectDelay), zap.Error(err))
 					cancel()
 					continue
 				}
@@ -237,13 +266,27 @@ func (c *PumpPortalClient) handleDisconnect() {
 				return
 			}
 		}
-	}()
+	})
+}
+
+// OnReconnect 注册重连成功后的回调
+// PumpPortal不保存订阅状态，调用者需在回调中重新订阅所需的数据流
+func (c *PumpPortalClient) OnReconnect(fn func()) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+	c.reconnectHooks = append(c.reconnectHooks, fn)
 }
 
 // 重新订阅之前的所有订阅
 func (c *PumpPortalClient) resubscribe() {
 	// 由于PumpPortal不保存订阅状态，需要调用者自行保存订阅状态并重新订阅
-	log.Printf("已重连PumpPortal WebSocket，请重新订阅所需的数据流")
+	c.log.Info("已重连PumpPortal WebSocket，请重新订阅所需的数据流")
+	c.handlersMutex.RLock()
+	hooks := slices.Clone(c.reconnectHooks)
+	c.handlersMutex.RUnlock()
+	for _, fn := range hooks {
+		fn()
+	}
 }
 
 // pingLoop 维持连接活跃
@@ -262,7 +305,7 @@ func (c *PumpPortalClient) pingLoop() {
 				return
 			}
 			if err := c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
-				log.Printf("PumpPortal WebSocket发送ping失败: %v", err)
+				c.log.Error("PumpPortal WebSocket发送ping失败", zap.Error(err))
 				c.connMutex.Unlock()
 				c.handleDisconnect()
 				return
@@ -289,6 +332,7 @@ func (c *PumpPortalClient) sendRequest(request interface{}) error {
 	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
 		return fmt.Errorf("发送WebSocket消息失败: %w", err)
 	}
+	c.log.Debug("已发送请求", zap.ByteString("request", data))
 
 	return nil
 }
@@ -0,0 +1,455 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// PumpPortalTradeAction 交易方向
+type PumpPortalTradeAction string
+
+// 定义交易方向常量
+const (
+	PumpPortalBuy  PumpPortalTradeAction = "buy"  // 买入
+	PumpPortalSell PumpPortalTradeAction = "sell" // 卖出
+)
+
+// PumpPortal交易API的下单方式
+const (
+	PumpPortalTradeLightning = "lightning" // PumpPortal使用Lightning钱包代为签名并发送
+	PumpPortalTradeLocal     = "local"     // PumpPortal构建交易，本地签名后通过 helius_api 发送
+)
+
+var (
+	// ErrPumpPortalTradeLimited 超过每小时下单次数或同一代币的下单间隔，本次没有下单
+	ErrPumpPortalTradeLimited = errors.New("超过自动交易频率限制")
+	// ErrPumpPortalTradeInvalid 交易参数无效或超过单笔上限
+	ErrPumpPortalTradeInvalid = errors.New("交易参数无效")
+)
+
+// GlobalPumpPortalTradeClient 全局PumpPortal交易客户端，未启用 pump_portal_trade 时为nil
+var GlobalPumpPortalTradeClient *PumpPortalTradeClient
+
+// PumpPortalTrade 一次买入或卖出
+type PumpPortalTrade struct {
+	Action           PumpPortalTradeAction `json:"action"`                 // 交易方向: buy, sell
+	Mint             string                `json:"mint,omitempty"`         // 代币地址
+	Amount           string                `json:"amount"`                 // 数量，以SOL计价时为SOL数量，否则为代币数量；卖出时可以是持仓百分比，如 "100%"
+	DenominatedInSOL bool                  `json:"denominated_in_sol"`     // Amount 是否以SOL计价，买入必须以SOL计价
+	Slippage         float64               `json:"slippage,omitempty"`     // 滑点(百分比)，0表示使用 pump_portal_trade.slippage
+	PriorityFee      float64               `json:"priority_fee,omitempty"` // 优先费(SOL)，0表示使用 pump_portal_trade.priority_fee
+	Pool             string                `json:"pool,omitempty"`         // 交易池，为空时使用 pump_portal_trade.pool
+}
+
+// PumpPortalTradeResult 下单结果
+type PumpPortalTradeResult struct {
+	Signature string `json:"signature,omitempty"` // 交易签名，dry_run 时为空
+	DryRun    bool   `json:"dry_run,omitempty"`   // 只记录了日志，没有实际下单
+}
+
+// pumpPortalTradeRequest PumpPortal交易API的请求体
+type pumpPortalTradeRequest struct {
+	PublicKey        string      `json:"publicKey,omitempty"` // 钱包公钥，仅 trade-local
+	Action           string      `json:"action"`
+	Mint             string      `json:"mint"`
+	Amount           interface{} `json:"amount"` // 数量或百分比字符串
+	DenominatedInSol string      `json:"denominatedInSol"`
+	Slippage         float64     `json:"slippage"`
+	PriorityFee      float64     `json:"priorityFee"`
+	Pool             string      `json:"pool"`
+}
+
+// PumpPortalTradeClient PumpPortal交易API客户端
+// lightning 模式由PumpPortal使用 api_key 对应的钱包签名并发送；local 模式由PumpPortal构建交易，
+// 使用配置的私钥在本地签名后通过 helius_api 发送。每次下单前检查单笔上限、每小时下单次数和同一代币的下单间隔，
+// 检查通过即计入限制，下单失败也不会立即重试
+type PumpPortalTradeClient struct {
+	httpClient       *http.Client
+	endpoint         string
+	mode             string
+	apiKey           string
+	privateKey       ed25519.PrivateKey
+	publicKey        string
+	dryRun           bool
+	pool             string
+	slippage         float64
+	priorityFee      float64
+	skipPreflight    bool
+	maxSOLPerTrade   float64
+	maxTradesPerHour int
+	mintCooldown     time.Duration
+	log              *zap.Logger
+
+	mu         sync.Mutex
+	recent     []time.Time          // 最近一小时的下单时间
+	lastByMint map[string]time.Time // 各代币各方向最近一次下单的时间
+}
+
+// NewPumpPortalTradeClient 从配置创建PumpPortal交易客户端并设置为全局实例
+// 参数:
+//   - config: 交易配置
+//
+// 返回:
+//   - *PumpPortalTradeClient: 交易客户端
+//   - error: 下单方式无效或私钥无法解析时的错误信息
+func NewPumpPortalTradeClient(config *configs.PumpPortalTradeConfig) (*PumpPortalTradeClient, error) {
+	client := &PumpPortalTradeClient{
+		httpClient:       NewHTTPClient(&config.HTTP, config.ProxyURL),
+		endpoint:         strings.TrimSuffix(config.Endpoint, "/"),
+		mode:             config.Mode,
+		apiKey:           config.APIKey,
+		dryRun:           config.DryRun,
+		pool:             config.Pool,
+		slippage:         config.Slippage,
+		priorityFee:      config.PriorityFee,
+		skipPreflight:    config.SkipPreflight,
+		maxSOLPerTrade:   config.MaxSOLPerTrade,
+		maxTradesPerHour: config.MaxTradesPerHour,
+		mintCooldown:     config.MintCooldown,
+		log:              logger.Named("rpc.pump_portal_trade"),
+		lastByMint:       make(map[string]time.Time),
+	}
+	switch config.Mode {
+	case PumpPortalTradeLightning:
+	case PumpPortalTradeLocal:
+		privateKey, err := parsePrivateKey(config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		client.privateKey = privateKey
+		client.publicKey = base58.Encode(privateKey.Public().(ed25519.PublicKey))
+	default:
+		return nil, fmt.Errorf("无效的下单方式: %q", config.Mode)
+	}
+	GlobalPumpPortalTradeClient = client
+	return client, nil
+}
+
+// Trade 买入或卖出代币
+// 参数:
+//   - ctx: 上下文
+//   - trade: 交易参数，未设置的滑点、优先费和交易池使用配置中的默认值
+//
+// 返回:
+//   - *PumpPortalTradeResult: 下单结果
+//   - error: 参数无效时包装 ErrPumpPortalTradeInvalid，超过频率限制时包装 ErrPumpPortalTradeLimited
+func (c *PumpPortalTradeClient) Trade(ctx context.Context, trade PumpPortalTrade) (*PumpPortalTradeResult, error) {
+	request, err := c.request(trade)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.reserve(trade.Action, trade.Mint); err != nil {
+		return nil, err
+	}
+	fields := []zap.Field{
+		zap.String("action", request.Action),
+		zap.String("mint", request.Mint),
+		zap.Any("amount", request.Amount),
+		zap.Bool("denominated_in_sol", trade.DenominatedInSOL),
+		zap.String("pool", request.Pool),
+	}
+	if c.dryRun {
+		c.log.Info("dry_run，未实际下单", fields...)
+		return &PumpPortalTradeResult{DryRun: true}, nil
+	}
+
+	var signature string
+	if c.mode == PumpPortalTradeLocal {
+		signature, err = c.tradeLocal(ctx, request)
+	} else {
+		signature, err = c.tradeLightning(ctx, request)
+	}
+	if err != nil {
+		c.log.Error("下单失败", append(fields, zap.Error(err))...)
+		return nil, err
+	}
+	c.log.Info("已下单", append(fields, zap.String("signature", signature))...)
+	return &PumpPortalTradeResult{Signature: signature}, nil
+}
+
+// ValidatePumpPortalTrade 校验交易参数，交易规则保存时和每次下单前使用同一校验
+// 参数:
+//   - trade: 交易参数，代币地址可以为空(交易规则从命中的事件中选择代币)
+//   - maxSOLPerTrade: 以SOL计价的单笔交易上限
+//
+// 返回:
+//   - error: 参数无效或超过单笔上限时包装 ErrPumpPortalTradeInvalid
+func ValidatePumpPortalTrade(trade PumpPortalTrade, maxSOLPerTrade float64) error {
+	_, err := tradeAmount(trade, maxSOLPerTrade)
+	return err
+}
+
+// tradeAmount 校验交易参数并返回请求体中的数量，卖出持仓百分比时为原始字符串，否则为数值
+func tradeAmount(trade PumpPortalTrade, maxSOLPerTrade float64) (interface{}, error) {
+	if trade.Action != PumpPortalBuy && trade.Action != PumpPortalSell {
+		return nil, fmt.Errorf("%w: action 必须是 buy 或 sell: %q", ErrPumpPortalTradeInvalid, trade.Action)
+	}
+	if trade.Action == PumpPortalBuy && !trade.DenominatedInSOL {
+		return nil, fmt.Errorf("%w: 买入必须以SOL计价，以便检查单笔上限", ErrPumpPortalTradeInvalid)
+	}
+	if trade.Slippage < 0 || trade.Slippage > 100 {
+		return nil, fmt.Errorf("%w: slippage 必须在0到100之间: %v", ErrPumpPortalTradeInvalid, trade.Slippage)
+	}
+	if trade.PriorityFee < 0 {
+		return nil, fmt.Errorf("%w: priority_fee 不能为负数: %v", ErrPumpPortalTradeInvalid, trade.PriorityFee)
+	}
+	amount := strings.TrimSpace(trade.Amount)
+	if percent, ok := strings.CutSuffix(amount, "%"); ok {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil || !(value > 0) || value > 100 || trade.Action != PumpPortalSell || trade.DenominatedInSOL {
+			return nil, fmt.Errorf("%w: 只有以代币计价的卖出可以使用(0, 100]的百分比: %q", ErrPumpPortalTradeInvalid, trade.Amount)
+		}
+		return amount, nil
+	}
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil || !(value > 0) || math.IsInf(value, 1) {
+		return nil, fmt.Errorf("%w: amount 必须是大于0的数量: %q", ErrPumpPortalTradeInvalid, trade.Amount)
+	}
+	if trade.DenominatedInSOL && value > maxSOLPerTrade {
+		return nil, fmt.Errorf("%w: %v SOL 超过单笔上限 %v SOL", ErrPumpPortalTradeInvalid, value, maxSOLPerTrade)
+	}
+	return value, nil
+}
+
+// request 校验交易参数并转换为PumpPortal的请求体
+func (c *PumpPortalTradeClient) request(trade PumpPortalTrade) (*pumpPortalTradeRequest, error) {
+	amount, err := tradeAmount(trade, c.maxSOLPerTrade)
+	if err != nil {
+		return nil, err
+	}
+	if trade.Mint == "" {
+		return nil, fmt.Errorf("%w: 没有代币地址", ErrPumpPortalTradeInvalid)
+	}
+	request := &pumpPortalTradeRequest{
+		PublicKey:        c.publicKey,
+		Action:           string(trade.Action),
+		Mint:             trade.Mint,
+		Amount:           amount,
+		DenominatedInSol: strconv.FormatBool(trade.DenominatedInSOL),
+		Slippage:         trade.Slippage,
+		PriorityFee:      trade.PriorityFee,
+		Pool:             trade.Pool,
+	}
+	if request.Slippage <= 0 {
+		request.Slippage = c.slippage
+	}
+	if request.PriorityFee <= 0 {
+		request.PriorityFee = c.priorityFee
+	}
+	if request.Pool == "" {
+		request.Pool = c.pool
+	}
+	return request, nil
+}
+
+// reserve 检查并记录本次下单，超过每小时下单次数或同一代币同一方向的下单间隔时返回 ErrPumpPortalTradeLimited
+func (c *PumpPortalTradeClient) reserve(action PumpPortalTradeAction, mint string) error {
+	now := clock.Now()
+	key := string(action) + ":" + mint
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.lastByMint[key]; ok && now.Sub(last) < c.mintCooldown {
+		return fmt.Errorf("%w: %s %s 距上次下单不足 %s", ErrPumpPortalTradeLimited, action, mint, c.mintCooldown)
+	}
+	recent := c.recent[:0]
+	for _, at := range c.recent {
+		if now.Sub(at) < time.Hour {
+			recent = append(recent, at)
+		}
+	}
+	c.recent = recent
+	if len(c.recent) >= c.maxTradesPerHour {
+		return fmt.Errorf("%w: 最近一小时已下单 %d 次", ErrPumpPortalTradeLimited, len(c.recent))
+	}
+	c.recent = append(c.recent, now)
+	c.lastByMint[key] = now
+	for mint, last := range c.lastByMint {
+		if now.Sub(last) >= c.mintCooldown {
+			delete(c.lastByMint, mint)
+		}
+	}
+	return nil
+}
+
+// tradeLightning 通过Lightning API下单，返回交易签名
+func (c *PumpPortalTradeClient) tradeLightning(ctx context.Context, request *pumpPortalTradeRequest) (string, error) {
+	body, err := c.post(ctx, "/trade?api-key="+url.QueryEscape(c.apiKey), request)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Signature string            `json:"signature"`
+		Errors    []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("解析下单结果失败: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, message := range response.Errors {
+			messages[i] = string(message)
+		}
+		return "", fmt.Errorf("PumpPortal拒绝下单: %s", strings.Join(messages, "; "))
+	}
+	if response.Signature == "" {
+		return "", errors.New("PumpPortal没有返回交易签名")
+	}
+	return response.Signature, nil
+}
+
+// tradeLocal 通过 trade-local 获取未签名的交易，本地签名后通过 helius_api 发送，返回交易签名
+func (c *PumpPortalTradeClient) tradeLocal(ctx context.Context, request *pumpPortalTradeRequest) (string, error) {
+	if GlobalHeliusClient == nil {
+		return "", errors.New("Helius RPC 客户端尚未初始化")
+	}
+	transaction, err := c.post(ctx, "/trade-local", request)
+	if err != nil {
+		return "", err
+	}
+	signed, err := signTransaction(transaction, c.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return GlobalHeliusClient.SendTransaction(ctx, base64.StdEncoding.EncodeToString(signed), c.skipPreflight)
+}
+
+// post 发送交易API请求，返回响应体
+func (c *PumpPortalTradeClient) post(ctx context.Context, path string, request *pumpPortalTradeRequest) ([]byte, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(requestJSON))
+	if err != nil {
+		return nil, fmt.Errorf("创建 HTTP 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 发送 HTTP 请求失败: %w", ErrNetwork, redactError(err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取响应失败: %w", ErrNetwork, err)
+	}
+	if err := statusError(resp, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// parsePrivateKey 解析base58编码或solana-keygen JSON数组格式的64字节私钥
+func parsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errors.New("未设置私钥")
+	}
+	var key []byte
+	if strings.HasPrefix(value, "[") {
+		var values []int
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return nil, fmt.Errorf("解析私钥失败: %w", err)
+		}
+		for _, number := range values {
+			if number < 0 || number > 255 {
+				return nil, errors.New("解析私钥失败: 数组元素必须在0到255之间")
+			}
+			key = append(key, byte(number))
+		}
+	} else {
+		decoded, err := base58.Decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("解析私钥失败: %w", err)
+		}
+		key = decoded
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("私钥长度应为 %d 字节，实际为 %d 字节", ed25519.PrivateKeySize, len(key))
+	}
+	privateKey := ed25519.PrivateKey(key)
+	if !bytes.Equal(ed25519.NewKeyFromSeed(key[:ed25519.SeedSize]).Public().(ed25519.PublicKey), key[ed25519.SeedSize:]) {
+		return nil, errors.New("私钥与其中的公钥不匹配")
+	}
+	return privateKey, nil
+}
+
+// signTransaction 使用私钥对序列化的交易签名，签名写入账户列表中对应公钥的签名位置
+// 交易格式: 签名数(compact-u16) + 签名(每个64字节) + 消息；消息以版本前缀(可选)和3字节的消息头开始，之后是账户列表
+func signTransaction(transaction []byte, privateKey ed25519.PrivateKey) ([]byte, error) {
+	signatures, offset, ok := decodeShortVec(transaction)
+	if !ok || signatures == 0 {
+		return nil, errors.New("交易格式无效: 没有签名位置")
+	}
+	messageStart := offset + signatures*ed25519.SignatureSize
+	if messageStart >= len(transaction) {
+		return nil, errors.New("交易格式无效: 长度不足")
+	}
+	message := transaction[messageStart:]
+
+	header := 0
+	if message[0]&0x80 != 0 {
+		header = 1 // 版本化交易的版本前缀
+	}
+	if len(message) < header+3 {
+		return nil, errors.New("交易格式无效: 消息头不完整")
+	}
+	requiredSignatures := int(message[header])
+	accounts, length, ok := decodeShortVec(message[header+3:])
+	if !ok {
+		return nil, errors.New("交易格式无效: 账户数无法解析")
+	}
+	keysStart := header + 3 + length
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	signer := -1
+	for i := 0; i < min(requiredSignatures, accounts, signatures); i++ {
+		start := keysStart + i*ed25519.PublicKeySize
+		if start+ed25519.PublicKeySize > len(message) {
+			break
+		}
+		if bytes.Equal(message[start:start+ed25519.PublicKeySize], publicKey) {
+			signer = i
+			break
+		}
+	}
+	if signer < 0 {
+		return nil, errors.New("交易的签名账户中没有配置的钱包")
+	}
+
+	signed := bytes.Clone(transaction)
+	copy(signed[offset+signer*ed25519.SignatureSize:], ed25519.Sign(privateKey, message))
+	return signed, nil
+}
+
+// decodeShortVec 解码compact-u16编码的长度，返回长度和占用的字节数
+func decodeShortVec(data []byte) (int, int, bool) {
+	value := 0
+	for i := 0; i < 3 && i < len(data); i++ {
+		value |= int(data[i]&0x7f) << (7 * i)
+		if data[i]&0x80 == 0 {
+			return value, i + 1, true
+		}
+	}
+	return 0, 0, false
+}
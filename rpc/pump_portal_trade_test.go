@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+func TestPumpPortalTradeLimits(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+
+	client, err := NewPumpPortalTradeClient(&configs.PumpPortalTradeConfig{
+		DryRun:           true,
+		Mode:             PumpPortalTradeLightning,
+		Endpoint:         "http://127.0.0.1:0",
+		Pool:             "auto",
+		Slippage:         10,
+		MaxSOLPerTrade:   0.1,
+		MaxTradesPerHour: 2,
+		MintCooldown:     10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { GlobalPumpPortalTradeClient = nil })
+	buy := func(mint string) error {
+		_, err := client.Trade(context.Background(), PumpPortalTrade{Action: PumpPortalBuy, Mint: mint, Amount: "0.05", DenominatedInSOL: true})
+		return err
+	}
+
+	if err := buy("A"); err != nil {
+		t.Fatal(err)
+	}
+	// 同一代币同一方向在冷却期内不下单，卖出不受买入冷却影响
+	fake.Advance(10*time.Minute - time.Second)
+	if err := buy("A"); !errors.Is(err, ErrPumpPortalTradeLimited) {
+		t.Fatalf("冷却期内买入应被限制: %v", err)
+	}
+	if _, err := client.Trade(context.Background(), PumpPortalTrade{Action: PumpPortalSell, Mint: "A", Amount: "100%"}); err != nil {
+		t.Fatal(err)
+	}
+	// 最近一小时已下单2次
+	fake.Advance(time.Second)
+	if err := buy("A"); !errors.Is(err, ErrPumpPortalTradeLimited) {
+		t.Fatalf("超过每小时下单次数应被限制: %v", err)
+	}
+	// 第一笔下单滑出一小时窗口后恢复
+	fake.Advance(50 * time.Minute)
+	if err := buy("A"); err != nil {
+		t.Fatal(err)
+	}
+	if err := buy("B"); !errors.Is(err, ErrPumpPortalTradeLimited) {
+		t.Fatalf("窗口内第3笔应被限制: %v", err)
+	}
+
+	// 参数检查先于频率限制
+	if _, err := client.Trade(context.Background(), PumpPortalTrade{Action: PumpPortalBuy, Mint: "C", Amount: "1", DenominatedInSOL: true}); !errors.Is(err, ErrPumpPortalTradeInvalid) {
+		t.Fatalf("超过单笔上限应返回参数无效: %v", err)
+	}
+}
+
+func TestValidatePumpPortalTrade(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		trade PumpPortalTrade
+		valid bool
+	}{
+		{"以SOL计价买入", PumpPortalTrade{Action: PumpPortalBuy, Amount: "0.1", DenominatedInSOL: true, Slippage: 15}, true},
+		{"按持仓百分比卖出", PumpPortalTrade{Action: PumpPortalSell, Amount: "50%"}, true},
+		{"按代币数量卖出", PumpPortalTrade{Action: PumpPortalSell, Amount: "1000000"}, true},
+		{"无效方向", PumpPortalTrade{Action: "hold", Amount: "0.1", DenominatedInSOL: true}, false},
+		{"以代币计价买入", PumpPortalTrade{Action: PumpPortalBuy, Amount: "1000"}, false},
+		{"超过单笔上限", PumpPortalTrade{Action: PumpPortalBuy, Amount: "0.11", DenominatedInSOL: true}, false},
+		{"数量为0", PumpPortalTrade{Action: PumpPortalSell, Amount: "0"}, false},
+		{"数量为NaN", PumpPortalTrade{Action: PumpPortalSell, Amount: "NaN"}, false},
+		{"买入使用百分比", PumpPortalTrade{Action: PumpPortalBuy, Amount: "100%", DenominatedInSOL: true}, false},
+		{"百分比超过100", PumpPortalTrade{Action: PumpPortalSell, Amount: "101%"}, false},
+		{"滑点超过100", PumpPortalTrade{Action: PumpPortalSell, Amount: "100%", Slippage: 101}, false},
+		{"滑点为负数", PumpPortalTrade{Action: PumpPortalSell, Amount: "100%", Slippage: -1}, false},
+		{"优先费为负数", PumpPortalTrade{Action: PumpPortalSell, Amount: "100%", PriorityFee: -0.001}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidatePumpPortalTrade(c.trade, 0.1)
+			if c.valid && err != nil {
+				t.Fatalf("应通过校验: %v", err)
+			}
+			if !c.valid && !errors.Is(err, ErrPumpPortalTradeInvalid) {
+				t.Fatalf("应返回参数无效: %v", err)
+			}
+		})
+	}
+}
+
+func TestPumpPortalTradeRedactsAPIKey(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	client, err := NewPumpPortalTradeClient(&configs.PumpPortalTradeConfig{
+		Mode:             PumpPortalTradeLightning,
+		Endpoint:         "http://127.0.0.1:0",
+		APIKey:           "secret-key",
+		Pool:             "auto",
+		MaxSOLPerTrade:   0.1,
+		MaxTradesPerHour: 10,
+		MintCooldown:     time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { GlobalPumpPortalTradeClient = nil })
+	_, err = client.Trade(context.Background(), PumpPortalTrade{Action: PumpPortalBuy, Mint: "A", Amount: "0.05", DenominatedInSOL: true})
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("连接失败应返回网络错误: %v", err)
+	}
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Fatalf("错误信息不应包含API密钥: %v", err)
+	}
+}
@@ -0,0 +1,543 @@
+package rules
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/idgen"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/watchlist"
+	"go.uber.org/zap"
+)
+
+// 默认保留的告警数量
+const defaultAlertHistory = 1000
+
+// 默认的待下单队列长度
+const defaultTradeQueueSize = 16
+
+// Webhook规则发送事件的超时时间
+const webhookTimeout = 5 * time.Second
+
+// 交易规则下单的超时时间，local 模式需要构建、签名并发送交易
+const tradeTimeout = 30 * time.Second
+
+// 关注地址通知在告警中使用的规则ID
+const watchlistRuleID = "watchlist"
+
+// GlobalEngine 全局规则引擎
+var GlobalEngine *Engine
+
+// compiledRule 已转换为过滤条件的规则
+type compiledRule struct {
+	rule   Rule
+	filter pipeline.Filter
+}
+
+// tradeTask 等待下单的交易规则命中
+type tradeTask struct {
+	rule  Rule
+	event pipeline.Event
+}
+
+// Engine 规则引擎，从Redis加载规则，订阅事件管道并对命中的事件执行告警、路由或发送Webhook
+// 规则通过管理接口增删改后立即生效，并通过Redis通知其他实例重新加载。
+// 交易规则的下单放入有界队列由单独的协程执行，不阻塞事件处理
+type Engine struct {
+	mu            sync.RWMutex
+	rules         map[string]*compiledRule
+	alertHistory  int64
+	httpClient    *http.Client
+	trades        chan tradeTask
+	droppedTrades atomic.Int64
+	log           *zap.Logger
+	cancel        context.CancelFunc
+}
+
+// NewEngine 创建规则引擎并设置为全局规则引擎
+func NewEngine(config *configs.RulesConfig) *Engine {
+	alertHistory := int64(config.AlertHistory)
+	if alertHistory <= 0 {
+		alertHistory = defaultAlertHistory
+	}
+	tradeQueueSize := config.TradeQueueSize
+	if tradeQueueSize <= 0 {
+		tradeQueueSize = defaultTradeQueueSize
+	}
+	engine := &Engine{
+		rules:        make(map[string]*compiledRule),
+		alertHistory: alertHistory,
+		httpClient:   &http.Client{Timeout: webhookTimeout},
+		trades:       make(chan tradeTask, tradeQueueSize),
+		log:          logger.Named("rules"),
+	}
+	GlobalEngine = engine
+	return engine
+}
+
+// redis 规则和告警使用缓存负载的Redis
+func (e *Engine) redis() *storage.RedisClient {
+	return storage.GetRedisClient(storage.WorkloadCache)
+}
+
+// Start 加载规则并在后台开始处理事件
+// 参数:
+//   - p: 事件管道
+//
+// 返回:
+//   - error: 加载规则失败时的错误信息
+func (e *Engine) Start(p *pipeline.Pipeline) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	if err := e.Reload(ctx); err != nil {
+		cancel()
+		return err
+	}
+
+	events, unsubscribe := p.Subscribe(pipeline.Filter{})
+	go func() {
+		defer unsubscribe()
+		supervisor.Run(ctx, "rules.engine", func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					e.evaluate(ctx, event)
+				}
+			}
+		})
+	}()
+
+	// 交易规则命中后在单独的协程中依次下单
+	supervisor.Go(ctx, "rules.engine.trade", e.runTrades)
+
+	// 监听其他实例的规则变更
+	pubsub := e.redis().SubscribeRuleChanges(ctx)
+	go func() {
+		defer pubsub.Close()
+		supervisor.Run(ctx, "rules.engine.reload", func(ctx context.Context) {
+			for range pubsub.Channel() {
+				if err := e.Reload(ctx); err != nil {
+					e.log.Error("重新加载规则失败", zap.Error(err))
+				}
+			}
+		})
+	}()
+
+	e.log.Info("规则引擎已启动", zap.Int("规则数", len(e.Rules())))
+	return nil
+}
+
+// Close 停止处理事件
+func (e *Engine) Close() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// Reload 从Redis重新加载所有规则，无法解析的规则会被跳过
+func (e *Engine) Reload(ctx context.Context) error {
+	values, err := e.redis().GetRules(ctx)
+	if err != nil {
+		return err
+	}
+	rules := make(map[string]*compiledRule, len(values))
+	for id, value := range values {
+		var rule Rule
+		if err := json.Unmarshal(value, &rule); err != nil {
+			e.log.Error("解析规则失败，已跳过", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		if rule.Action == ActionTrade {
+			if err := tradeRuleAllowed(rule.ID); err != nil {
+				e.log.Warn("交易规则不允许下单，已跳过", zap.String("id", id), zap.Error(err))
+				continue
+			}
+		}
+		rules[id] = compile(rule)
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+	return nil
+}
+
+// Rules 返回所有规则，按创建时间排序
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	rules := make([]Rule, 0, len(e.rules))
+	for _, compiled := range e.rules {
+		rules = append(rules, compiled.rule)
+	}
+	slices.SortFunc(rules, func(a, b Rule) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return rules
+}
+
+// GetRule 获取指定规则
+func (e *Engine) GetRule(id string) (Rule, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	compiled, ok := e.rules[id]
+	if !ok {
+		return Rule{}, ErrRuleNotFound
+	}
+	return compiled.rule, nil
+}
+
+// CreateRule 创建规则，未指定ID时自动生成
+func (e *Engine) CreateRule(ctx context.Context, rule Rule) (Rule, error) {
+	if err := rule.Validate(); err != nil {
+		return Rule{}, err
+	}
+	if rule.Action == ActionTrade {
+		if err := tradeRuleAllowed(rule.ID); err != nil {
+			return Rule{}, err
+		}
+	}
+	if rule.ID == "" {
+		rule.ID = idgen.New()
+	} else if _, err := e.GetRule(rule.ID); err == nil {
+		return Rule{}, fmt.Errorf("规则已存在: %s", rule.ID)
+	}
+	now := clock.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return rule, e.save(ctx, rule)
+}
+
+// UpdateRule 使用新规则整体替换指定规则
+func (e *Engine) UpdateRule(ctx context.Context, id string, rule Rule) (Rule, error) {
+	existing, err := e.GetRule(id)
+	if err != nil {
+		return Rule{}, err
+	}
+	if err := rule.Validate(); err != nil {
+		return Rule{}, err
+	}
+	if rule.Action == ActionTrade {
+		if err := tradeRuleAllowed(id); err != nil {
+			return Rule{}, err
+		}
+	}
+	rule.ID = id
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = clock.Now()
+	return rule, e.save(ctx, rule)
+}
+
+// DeleteRule 删除指定规则
+func (e *Engine) DeleteRule(ctx context.Context, id string) error {
+	deleted, err := e.redis().DeleteRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	delete(e.rules, id)
+	e.mu.Unlock()
+	if !deleted {
+		return ErrRuleNotFound
+	}
+	e.log.Info("规则已删除", zap.String("id", id))
+	return nil
+}
+
+// Alerts 返回最近的告警
+func (e *Engine) Alerts(ctx context.Context, count int64) ([]Alert, error) {
+	values, err := e.redis().GetAlerts(ctx, count)
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]Alert, 0, len(values))
+	for _, value := range values {
+		var alert Alert
+		if err := json.Unmarshal(value, &alert); err != nil {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// save 持久化规则并立即在本实例生效
+func (e *Engine) save(ctx context.Context, rule Rule) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("序列化规则失败: %w", err)
+	}
+	if err := e.redis().SaveRule(ctx, rule.ID, value); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.rules[rule.ID] = compile(rule)
+	e.mu.Unlock()
+	e.log.Info("规则已保存", zap.String("id", rule.ID), zap.String("name", rule.Name))
+	return nil
+}
+
+// evaluate 对事件执行所有命中的规则
+func (e *Engine) evaluate(ctx context.Context, event pipeline.Event) {
+	e.mu.RLock()
+	matched := make([]*compiledRule, 0)
+	for _, compiled := range e.rules {
+		if compiled.rule.Enabled && compiled.filter.Matches(event) {
+			matched = append(matched, compiled)
+		}
+	}
+	e.mu.RUnlock()
+
+	for _, compiled := range matched {
+		rule := &compiled.rule
+		if rule.Action == ActionTrade {
+			e.enqueueTrade(tradeTask{rule: *rule, event: event})
+			continue
+		}
+		actionCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		var err error
+		switch rule.Action {
+		case ActionAlert:
+			err = e.alert(actionCtx, rule, event)
+		case ActionRoute:
+			err = e.route(actionCtx, rule, event)
+		case ActionWebhook:
+			err = e.webhook(actionCtx, rule, event)
+		}
+		cancel()
+		if err != nil {
+			e.log.Error("执行规则失败", zap.String("rule", rule.ID), zap.String("action", string(rule.Action)), zap.Error(err))
+		}
+	}
+
+	e.notifyWatchers(ctx, event)
+}
+
+// notifyWatchers 按关注地址各自的通知设置生成告警，并发布到其通知频道
+func (e *Engine) notifyWatchers(ctx context.Context, event pipeline.Event) {
+	if watchlist.GlobalWatchlist == nil {
+		return
+	}
+	accounts, mints := eventParticipants(event)
+	now := clock.Now()
+	for _, entry := range watchlist.GlobalWatchlist.Lookup(append(accounts, mints...)) {
+		if !entry.Preferences.Matches(event, entry.Address) {
+			continue
+		}
+		actionCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := e.notify(actionCtx, &entry, event, now)
+		cancel()
+		if err != nil {
+			e.log.Error("发送关注地址通知失败", zap.String("address", entry.Address), zap.Error(err))
+		}
+	}
+}
+
+// notify 记录关注地址的告警，不在免打扰时段时发布到通知频道
+func (e *Engine) notify(ctx context.Context, entry *watchlist.Entry, event pipeline.Event, now time.Time) error {
+	alert := Alert{
+		RuleID:    watchlistRuleID,
+		RuleName:  entry.Name(),
+		Severity:  Severity(entry.Preferences.Severity),
+		EventType: event.Type,
+		Slot:      event.Slot,
+		Signature: event.Signature,
+		Address:   entry.Address,
+		Message:   watchMessage(entry, event),
+		Quiet:     entry.Preferences.Quiet(now),
+		Time:      now,
+	}
+	value, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+	if err := e.redis().PushAlert(ctx, value, e.alertHistory); err != nil {
+		return err
+	}
+	if alert.Quiet {
+		return nil
+	}
+	for _, channel := range entry.Preferences.Channels {
+		if err := e.redis().PublishMessage(ctx, channel, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alert 记录告警
+func (e *Engine) alert(ctx context.Context, rule *Rule, event pipeline.Event) error {
+	alert := Alert{
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Severity:  rule.Severity,
+		EventType: event.Type,
+		Slot:      event.Slot,
+		Signature: event.Signature,
+		Message:   alertMessage(rule, event),
+		Time:      clock.Now(),
+	}
+	e.log.Warn("规则告警",
+		zap.String("rule", rule.ID),
+		zap.String("severity", string(rule.Severity)),
+		zap.String("message", alert.Message))
+
+	value, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+	return e.redis().PushAlert(ctx, value, e.alertHistory)
+}
+
+// route 将事件转发到规则指定的Redis频道
+func (e *Engine) route(ctx context.Context, rule *Rule, event pipeline.Event) error {
+	value, err := payload(rule, event)
+	if err != nil {
+		return err
+	}
+	return e.redis().PublishMessage(ctx, rule.Channel, value)
+}
+
+// webhook 将事件以HTTP POST发送到规则指定的URL，非2xx响应视为失败
+func (e *Engine) webhook(ctx context.Context, rule *Rule, event pipeline.Event) error {
+	value, err := payload(rule, event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.URL, bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("创建Webhook请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, header := range rule.Headers {
+		req.Header.Set(key, header)
+	}
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送Webhook失败: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("Webhook返回错误状态码: %d", res.StatusCode)
+	}
+	return nil
+}
+
+// tradeRuleAllowed 检查交易规则能否下单，创建、修改和加载交易规则时调用
+// 未启用 pump_portal_trade 或规则ID不在 pump_portal_trade.rule_ids 中时返回错误
+func tradeRuleAllowed(id string) error {
+	if rpc.GlobalPumpPortalTradeClient == nil {
+		return errors.New("未启用 pump_portal_trade，不接受交易规则")
+	}
+	if id == "" || !slices.Contains(configs.GlobalConfig.PumpPortalTrade.RuleIDs, id) {
+		return fmt.Errorf("交易规则的ID必须在 pump_portal_trade.rule_ids 中: %q", id)
+	}
+	return nil
+}
+
+// enqueueTrade 将交易规则的命中放入待下单队列，队列已满时丢弃
+func (e *Engine) enqueueTrade(task tradeTask) {
+	select {
+	case e.trades <- task:
+	default:
+		// 首次丢弃及之后每100次记录一次日志，避免日志刷屏
+		if dropped := e.droppedTrades.Add(1); dropped == 1 || dropped%100 == 0 {
+			e.log.Warn("待下单队列已满，丢弃交易规则命中",
+				zap.String("rule", task.rule.ID),
+				zap.Int64("dropped", dropped))
+		}
+	}
+}
+
+// runTrades 依次执行待下单队列中的交易规则，超过频率限制只记录警告
+func (e *Engine) runTrades(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-e.trades:
+			tradeCtx, cancel := context.WithTimeout(ctx, tradeTimeout)
+			err := e.trade(tradeCtx, &task.rule, task.event)
+			cancel()
+			switch {
+			case errors.Is(err, rpc.ErrPumpPortalTradeLimited):
+				e.log.Warn("交易规则未下单", zap.String("rule", task.rule.ID), zap.Error(err))
+			case err != nil:
+				e.log.Error("执行规则失败", zap.String("rule", task.rule.ID), zap.String("action", string(ActionTrade)), zap.Error(err))
+			}
+		}
+	}
+}
+
+// trade 按规则的下单参数通过PumpPortal交易API下单，并记录一条告警
+func (e *Engine) trade(ctx context.Context, rule *Rule, event pipeline.Event) error {
+	trade := *rule.Trade
+	if trade.Mint == "" {
+		mint, err := eventMint(event)
+		if err != nil {
+			return err
+		}
+		trade.Mint = mint
+	}
+	result, err := rpc.GlobalPumpPortalTradeClient.Trade(ctx, trade)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("规则[%s]%s %s %s，交易签名 %s", rule.Name, trade.Action, trade.Amount, trade.Mint, result.Signature)
+	if result.DryRun {
+		message = fmt.Sprintf("规则[%s]%s %s %s (dry_run，未实际下单)", rule.Name, trade.Action, trade.Amount, trade.Mint)
+	}
+	alert := Alert{
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Severity:  SeverityInfo,
+		EventType: event.Type,
+		Slot:      event.Slot,
+		Signature: event.Signature,
+		Message:   message,
+		Time:      clock.Now(),
+	}
+	value, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+	return e.redis().PushAlert(ctx, value, e.alertHistory)
+}
+
+// payload 序列化事件并按规则的转换配置转换
+func payload(rule *Rule, event pipeline.Event) ([]byte, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("序列化事件失败: %w", err)
+	}
+	return rule.Transform.Apply(value)
+}
+
+// compile 将规则转换为可直接匹配的形式
+func compile(rule Rule) *compiledRule {
+	return &compiledRule{rule: rule, filter: rule.filter()}
+}
@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// setupTrade 设置交易配置，enabled 时创建 dry_run 的交易客户端，测试结束后恢复全局状态
+func setupTrade(t *testing.T, enabled bool, ruleIDs ...string) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	config := &configs.Config{PumpPortalTrade: configs.PumpPortalTradeConfig{
+		Enabled:          enabled,
+		DryRun:           true,
+		RuleIDs:          ruleIDs,
+		Mode:             rpc.PumpPortalTradeLightning,
+		Endpoint:         "http://127.0.0.1:0",
+		MaxSOLPerTrade:   0.1,
+		MaxTradesPerHour: 10,
+		MintCooldown:     time.Minute,
+	}}
+	previous := configs.GlobalConfig
+	configs.SetGlobalConfig(config)
+	t.Cleanup(func() {
+		configs.SetGlobalConfig(previous)
+		rpc.GlobalPumpPortalTradeClient = nil
+	})
+	if enabled {
+		if _, err := rpc.NewPumpPortalTradeClient(&config.PumpPortalTrade); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func tradeRule(id, amount string) Rule {
+	return Rule{
+		ID:      id,
+		Name:    "snipe",
+		Enabled: true,
+		Action:  ActionTrade,
+		Trade:   &rpc.PumpPortalTrade{Action: rpc.PumpPortalBuy, Amount: amount, DenominatedInSOL: true},
+		Match:   Match{Types: []pipeline.EventType{pipeline.EventGraduation}},
+	}
+}
+
+func TestTradeRuleAllowed(t *testing.T) {
+	setupTrade(t, false, "snipe")
+	if err := tradeRuleAllowed("snipe"); err == nil {
+		t.Fatal("未启用 pump_portal_trade 时不应接受交易规则")
+	}
+
+	setupTrade(t, true, "snipe")
+	if err := tradeRuleAllowed("snipe"); err != nil {
+		t.Fatalf("rule_ids 中的交易规则应被接受: %v", err)
+	}
+	for _, id := range []string{"", "other"} {
+		if err := tradeRuleAllowed(id); err == nil {
+			t.Fatalf("不在 rule_ids 中的交易规则不应被接受: %q", id)
+		}
+	}
+}
+
+func TestCreateTradeRuleRejected(t *testing.T) {
+	setupTrade(t, true, "snipe")
+	engine := NewEngine(&configs.RulesConfig{})
+	t.Cleanup(func() { GlobalEngine = nil })
+
+	// 校验和准入检查在写入Redis之前完成
+	for _, rule := range []Rule{tradeRule("", "0.05"), tradeRule("other", "0.05"), tradeRule("snipe", "0.5")} {
+		if _, err := engine.CreateRule(context.Background(), rule); err == nil {
+			t.Fatalf("交易规则应被拒绝: id=%q amount=%s", rule.ID, rule.Trade.Amount)
+		}
+	}
+}
+
+func TestRuleValidateTrade(t *testing.T) {
+	setupTrade(t, true, "snipe")
+	valid := tradeRule("snipe", "0.05")
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("规则应通过校验: %v", err)
+	}
+	for _, trade := range []rpc.PumpPortalTrade{
+		{Action: rpc.PumpPortalBuy, Amount: "0.5", DenominatedInSOL: true},
+		{Action: rpc.PumpPortalBuy, Amount: "0.05", DenominatedInSOL: true, Slippage: 150},
+		{Action: rpc.PumpPortalSell, Amount: "abc"},
+	} {
+		rule := tradeRule("snipe", "")
+		rule.Trade = &trade
+		if err := rule.Validate(); err == nil {
+			t.Fatalf("规则应校验失败: %+v", trade)
+		}
+	}
+}
+
+func TestRuleValidateTradeWithoutConfig(t *testing.T) {
+	previous := configs.GlobalConfig
+	configs.GlobalConfig = nil
+	t.Cleanup(func() { configs.GlobalConfig = previous })
+	// 未加载配置时只检查参数格式，不检查单笔上限
+	large, invalid := tradeRule("snipe", "5"), tradeRule("snipe", "abc")
+	if err := large.Validate(); err != nil {
+		t.Fatalf("未加载配置时规则应通过校验: %v", err)
+	}
+	if err := invalid.Validate(); err == nil {
+		t.Fatal("未加载配置时仍应检查参数格式")
+	}
+}
+
+func TestEnqueueTradeDropsWhenFull(t *testing.T) {
+	setupTrade(t, true, "snipe")
+	engine := NewEngine(&configs.RulesConfig{TradeQueueSize: 1})
+	t.Cleanup(func() { GlobalEngine = nil })
+
+	task := tradeTask{rule: tradeRule("snipe", "0.05"), event: pipeline.Event{Type: pipeline.EventGraduation, Mint: "A"}}
+	engine.enqueueTrade(task)
+	engine.enqueueTrade(task)
+	if len(engine.trades) != 1 || engine.droppedTrades.Load() != 1 {
+		t.Fatalf("队列已满时应丢弃: queued=%d dropped=%d", len(engine.trades), engine.droppedTrades.Load())
+	}
+}
@@ -0,0 +1,318 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/watchlist"
+)
+
+// Action 定义了规则命中后的动作
+type Action string
+
+// 定义规则动作常量
+const (
+	ActionAlert   Action = "alert"   // 记录告警
+	ActionRoute   Action = "route"   // 将事件转发到Redis频道
+	ActionWebhook Action = "webhook" // 将事件以HTTP POST发送到指定URL
+	ActionTrade   Action = "trade"   // 通过PumpPortal交易API下单，需要启用 pump_portal_trade 且规则ID在 rule_ids 中
+)
+
+// Severity 定义了告警级别
+type Severity string
+
+// 定义告警级别常量
+const (
+	SeverityInfo     Severity = "info"     // 提示
+	SeverityWarning  Severity = "warning"  // 警告
+	SeverityCritical Severity = "critical" // 严重
+)
+
+// 规则支持的事件类型
+var eventTypes = []pipeline.EventType{pipeline.EventBlock, pipeline.EventTransaction, pipeline.EventPumpPortal, pipeline.EventOrderFlow, pipeline.EventStall, pipeline.EventGraduation, pipeline.EventPoolCreated, pipeline.EventAPIKeys, pipeline.EventSubscription}
+
+// wrappedSOLMint WSOL的代币地址，交易规则从事件中选择代币时跳过
+const wrappedSOLMint = "So11111111111111111111111111111111111111112"
+
+// ErrRuleNotFound 规则不存在
+var ErrRuleNotFound = errors.New("规则不存在")
+
+// Match 规则匹配条件，各条件之间为"与"关系，为空的条件不参与匹配
+type Match struct {
+	Types            []pipeline.EventType     `json:"types,omitempty"`             // 事件类型
+	Sources          []resp.TransactionSource `json:"sources,omitempty"`           // 交易来源
+	TransactionTypes []resp.TransactionType   `json:"transaction_types,omitempty"` // 交易类型
+	MessageTypes     []resp.MessageType       `json:"message_types,omitempty"`     // PumpPortal消息类型
+	Accounts         []string                 `json:"accounts,omitempty"`          // 涉及的账户，任一命中即可
+	Mints            []string                 `json:"mints,omitempty"`             // 涉及的代币，任一命中即可
+	MinAbsImbalance  *float64                 `json:"min_abs_imbalance,omitempty"` // 买卖盘失衡度绝对值下限，仅对买卖盘失衡事件生效
+}
+
+// Rule 告警/路由/Webhook规则
+type Rule struct {
+	ID        string               `json:"id"`                  // 规则ID
+	Name      string               `json:"name"`                // 规则名称
+	Enabled   bool                 `json:"enabled"`             // 是否启用
+	Action    Action               `json:"action"`              // 命中后的动作
+	Severity  Severity             `json:"severity,omitempty"`  // 告警级别，仅告警规则
+	Channel   string               `json:"channel,omitempty"`   // 转发的Redis频道，仅路由规则
+	URL       string               `json:"url,omitempty"`       // 接收事件的URL，仅Webhook规则
+	Headers   map[string]string    `json:"headers,omitempty"`   // 发送事件时附加的请求头，仅Webhook规则
+	Transform *Transform           `json:"transform,omitempty"` // 发送前对事件的转换，仅路由和Webhook规则
+	Trade     *rpc.PumpPortalTrade `json:"trade,omitempty"`     // 下单参数，仅交易规则；未指定代币时使用事件涉及的唯一代币
+	Match     Match                `json:"match"`               // 匹配条件
+	CreatedAt time.Time            `json:"created_at"`          // 创建时间
+	UpdatedAt time.Time            `json:"updated_at"`          // 更新时间
+}
+
+// Alert 规则命中产生的告警
+type Alert struct {
+	RuleID    string             `json:"rule_id"`             // 规则ID
+	RuleName  string             `json:"rule_name"`           // 规则名称
+	Severity  Severity           `json:"severity"`            // 告警级别
+	EventType pipeline.EventType `json:"event_type"`          // 事件类型
+	Slot      uint64             `json:"slot,omitempty"`      // 区块高度
+	Signature string             `json:"signature,omitempty"` // 交易签名
+	Address   string             `json:"address,omitempty"`   // 关注的地址，仅关注地址通知
+	Message   string             `json:"message"`             // 告警内容
+	Quiet     bool               `json:"quiet,omitempty"`     // 处于免打扰时段，未发布到通知频道
+	Time      time.Time          `json:"time"`                // 告警时间
+}
+
+// maxSOLPerTrade 返回交易规则的单笔上限，未加载配置时(如离线校验规则)不检查上限，下单时交易客户端仍会按配置检查
+func maxSOLPerTrade() float64 {
+	if configs.GlobalConfig == nil {
+		return math.Inf(1)
+	}
+	return configs.GlobalConfig.PumpPortalTrade.MaxSOLPerTrade
+}
+
+// Validate 校验规则
+func (r *Rule) Validate() error {
+	var problems []string
+	if strings.TrimSpace(r.Name) == "" {
+		problems = append(problems, "name 不能为空")
+	}
+	switch r.Action {
+	case ActionAlert:
+		if r.Severity == "" {
+			r.Severity = SeverityWarning
+		}
+		if r.Severity != SeverityInfo && r.Severity != SeverityWarning && r.Severity != SeverityCritical {
+			problems = append(problems, fmt.Sprintf("severity 无效: %q，可选值: info, warning, critical", r.Severity))
+		}
+	case ActionRoute:
+		if r.Channel == "" {
+			problems = append(problems, "路由规则必须指定 channel")
+		}
+	case ActionWebhook:
+		if u, err := url.Parse(r.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("Webhook规则的 url 无效: %q", r.URL))
+		}
+	case ActionTrade:
+		if r.Trade == nil {
+			problems = append(problems, "交易规则必须指定 trade")
+		} else if err := rpc.ValidatePumpPortalTrade(*r.Trade, maxSOLPerTrade()); err != nil {
+			problems = append(problems, err.Error())
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("action 无效: %q，可选值: alert, route, webhook, trade", r.Action))
+	}
+	if r.Transform != nil {
+		if r.Action == ActionAlert || r.Action == ActionTrade {
+			problems = append(problems, "告警和交易规则不支持 transform")
+		}
+		problems = append(problems, r.Transform.validate()...)
+	}
+	for _, eventType := range r.Match.Types {
+		if !slices.Contains(eventTypes, eventType) {
+			problems = append(problems, fmt.Sprintf("match.types 无效: %q", eventType))
+		}
+	}
+	if r.Match.MinAbsImbalance != nil && (*r.Match.MinAbsImbalance < 0 || *r.Match.MinAbsImbalance > 1) {
+		problems = append(problems, "match.min_abs_imbalance 必须在0到1之间")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("规则无效: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// filter 将匹配条件转换为事件管道的过滤条件
+func (r *Rule) filter() pipeline.Filter {
+	match := r.Match
+	filter := pipeline.Filter{
+		Types:            match.Types,
+		Sources:          match.Sources,
+		TransactionTypes: match.TransactionTypes,
+		MessageTypes:     match.MessageTypes,
+	}
+	if len(match.Accounts) > 0 || len(match.Mints) > 0 || match.MinAbsImbalance != nil {
+		filter.Match = func(event pipeline.Event) bool {
+			if match.MinAbsImbalance != nil && (event.OrderFlow == nil || math.Abs(event.OrderFlow.Imbalance) < *match.MinAbsImbalance) {
+				return false
+			}
+			accounts, mints := eventParticipants(event)
+			if len(match.Accounts) > 0 && !containsAny(accounts, match.Accounts) {
+				return false
+			}
+			if len(match.Mints) > 0 && !containsAny(mints, match.Mints) {
+				return false
+			}
+			return true
+		}
+	}
+	return filter
+}
+
+// eventParticipants 返回事件涉及的账户和代币
+func eventParticipants(event pipeline.Event) ([]string, []string) {
+	var accounts, mints []string
+	switch event.Type {
+	case pipeline.EventTransaction:
+		transaction := event.Transaction
+		if transaction == nil {
+			return nil, nil
+		}
+		accounts = append(accounts, transaction.FeePayer)
+		for _, transfer := range transaction.NativeTransfers {
+			accounts = append(accounts, transfer.FromUserAccount, transfer.ToUserAccount)
+		}
+		for _, transfer := range transaction.TokenTransfers {
+			accounts = append(accounts, transfer.FromUserAccount, transfer.ToUserAccount)
+			mints = append(mints, transfer.Mint)
+		}
+		for _, accountData := range transaction.AccountData {
+			accounts = append(accounts, accountData.Account)
+		}
+	case pipeline.EventPumpPortal:
+		var message struct {
+			Mint            string `json:"mint"`
+			TraderPublicKey string `json:"traderPublicKey"`
+		}
+		if err := json.Unmarshal(event.Raw, &message); err == nil {
+			accounts = append(accounts, message.TraderPublicKey)
+			mints = append(mints, message.Mint)
+		}
+	case pipeline.EventOrderFlow, pipeline.EventGraduation:
+		mints = append(mints, event.Mint)
+	case pipeline.EventPoolCreated:
+		if pool := event.Pool; pool != nil {
+			accounts = append(accounts, pool.Creator, pool.Pool)
+			mints = append(mints, pool.MintA, pool.MintB)
+		}
+	}
+	return accounts, mints
+}
+
+// eventMint 返回事件涉及的唯一代币(不含WSOL)，没有或有多个代币时返回错误
+func eventMint(event pipeline.Event) (string, error) {
+	_, mints := eventParticipants(event)
+	var mint string
+	for _, candidate := range mints {
+		if candidate == "" || candidate == wrappedSOLMint || candidate == mint {
+			continue
+		}
+		if mint != "" {
+			return "", errors.New("事件涉及多个代币，交易规则需要指定 trade.mint")
+		}
+		mint = candidate
+	}
+	if mint == "" {
+		return "", errors.New("事件没有涉及代币，交易规则需要指定 trade.mint")
+	}
+	return mint, nil
+}
+
+// containsAny 判断values中是否包含targets中的任一元素
+func containsAny(values, targets []string) bool {
+	for _, target := range targets {
+		if slices.Contains(values, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// alertMessage 生成告警内容
+func alertMessage(rule *Rule, event pipeline.Event) string {
+	switch event.Type {
+	case pipeline.EventTransaction:
+		if event.Transaction != nil {
+			return fmt.Sprintf("规则[%s]命中交易 %s: %s/%s %s", rule.Name, event.Signature, event.Transaction.Source, event.Transaction.Type, event.Transaction.Description)
+		}
+	case pipeline.EventPumpPortal:
+		return fmt.Sprintf("规则[%s]命中PumpPortal消息: %s", rule.Name, event.MessageType)
+	case pipeline.EventBlock:
+		return fmt.Sprintf("规则[%s]命中区块 %d", rule.Name, event.Slot)
+	case pipeline.EventOrderFlow:
+		if flow := event.OrderFlow; flow != nil {
+			return fmt.Sprintf("规则[%s]代币 %s 买卖盘失衡: %.2f (买 %d 笔/卖 %d 笔，窗口 %ds)", rule.Name, event.Mint, flow.Imbalance, flow.BuyCount, flow.SellCount, flow.Window)
+		}
+	case pipeline.EventStall:
+		if stall := event.Stall; stall != nil {
+			return fmt.Sprintf("规则[%s]%s", rule.Name, stall.Message)
+		}
+	case pipeline.EventAPIKeys:
+		if health := event.KeyHealth; health != nil {
+			return fmt.Sprintf("规则[%s]%s", rule.Name, health.Message)
+		}
+	case pipeline.EventSubscription:
+		if silence := event.Silence; silence != nil {
+			return fmt.Sprintf("规则[%s]%s", rule.Name, silence.Message)
+		}
+	case pipeline.EventGraduation:
+		if curve := event.Curve; curve != nil {
+			return fmt.Sprintf("规则[%s]代币 %s 即将毕业: 联合曲线进度 %.1f%%，还需约 %.2f SOL，市值 %.2f SOL", rule.Name, curveName(curve), curve.Progress*100, curve.SOLToGraduate, curve.MarketCapSOL)
+		}
+	case pipeline.EventPoolCreated:
+		if pool := event.Pool; pool != nil {
+			return fmt.Sprintf("规则[%s]%s 新建池子 %s: %s(%g)/%s(%g)", rule.Name, pool.Dex, pool.Pool, pool.MintA, pool.AmountA, pool.MintB, pool.AmountB)
+		}
+	}
+	return fmt.Sprintf("规则[%s]命中事件: %s", rule.Name, event.Type)
+}
+
+// watchMessage 生成关注地址通知的告警内容
+func watchMessage(entry *watchlist.Entry, event pipeline.Event) string {
+	switch event.Type {
+	case pipeline.EventTransaction:
+		if event.Transaction != nil {
+			return fmt.Sprintf("关注地址[%s]参与交易 %s: %s/%s %s", entry.Name(), event.Signature, event.Transaction.Source, event.Transaction.Type, event.Transaction.Description)
+		}
+	case pipeline.EventPumpPortal:
+		return fmt.Sprintf("关注地址[%s]出现PumpPortal消息: %s", entry.Name(), event.MessageType)
+	case pipeline.EventOrderFlow:
+		if flow := event.OrderFlow; flow != nil {
+			return fmt.Sprintf("关注代币[%s]买卖盘失衡: %.2f (买 %d 笔/卖 %d 笔，窗口 %ds)", entry.Name(), flow.Imbalance, flow.BuyCount, flow.SellCount, flow.Window)
+		}
+	case pipeline.EventGraduation:
+		if curve := event.Curve; curve != nil {
+			return fmt.Sprintf("关注代币[%s]即将毕业: 联合曲线进度 %.1f%%，还需约 %.2f SOL", entry.Name(), curve.Progress*100, curve.SOLToGraduate)
+		}
+	case pipeline.EventPoolCreated:
+		if pool := event.Pool; pool != nil {
+			return fmt.Sprintf("关注地址[%s]出现新建池子 %s(%s): %s/%s", entry.Name(), pool.Pool, pool.Dex, pool.MintA, pool.MintB)
+		}
+	}
+	return fmt.Sprintf("关注地址[%s]出现事件: %s", entry.Name(), event.Type)
+}
+
+// curveName 返回告警中显示的代币名称，有符号时附带符号
+func curveName(curve *models.BondingCurveProgress) string {
+	if curve.Symbol != "" {
+		return fmt.Sprintf("%s(%s)", curve.Mint, curve.Symbol)
+	}
+	return curve.Mint
+}
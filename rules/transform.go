@@ -0,0 +1,175 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 展开嵌套对象时默认使用的键名分隔符
+const defaultFlattenSeparator = "."
+
+// Field 输出字段映射
+type Field struct {
+	From    string          `json:"from"`              // 事件中的字段路径，以点分隔，数组元素用下标，*表示数组中所有元素，如 transaction.tokenTransfers.*.mint
+	To      string          `json:"to,omitempty"`      // 输出字段名，为空时使用From；包含点时生成嵌套对象
+	Default json.RawMessage `json:"default,omitempty"` // 事件中没有该字段时的默认值，未设置时不输出该字段
+}
+
+// Transform 事件发送前的转换，用于对接字段固定的下游服务
+// 先按Fields选择并重命名字段，Fields为空时保留完整事件；再按Flatten将嵌套对象展开为单层
+type Transform struct {
+	Fields    []Field `json:"fields,omitempty"`    // 输出的字段
+	Flatten   bool    `json:"flatten,omitempty"`   // 是否将嵌套对象和数组展开为单层，键名为路径
+	Separator string  `json:"separator,omitempty"` // 展开后键名的分隔符，默认为点
+}
+
+// validate 校验转换配置
+func (t *Transform) validate() []string {
+	var problems []string
+	seen := make(map[string]bool, len(t.Fields))
+	for i, field := range t.Fields {
+		if strings.TrimSpace(field.From) == "" {
+			problems = append(problems, fmt.Sprintf("transform.fields[%d].from 不能为空", i))
+			continue
+		}
+		to := field.target()
+		if seen[to] {
+			problems = append(problems, fmt.Sprintf("transform.fields[%d] 输出字段重复: %q", i, to))
+		}
+		seen[to] = true
+		if len(field.Default) > 0 && !json.Valid(field.Default) {
+			problems = append(problems, fmt.Sprintf("transform.fields[%d].default 不是有效的JSON", i))
+		}
+	}
+	return problems
+}
+
+// target 返回字段的输出名
+func (f *Field) target() string {
+	if f.To != "" {
+		return f.To
+	}
+	return f.From
+}
+
+// Apply 转换事件
+// 参数:
+//   - value: 序列化后的事件
+//
+// 返回:
+//   - json.RawMessage: 转换后的JSON
+//   - error: 事件无法解析时的错误信息
+func (t *Transform) Apply(value []byte) (json.RawMessage, error) {
+	if t == nil || (len(t.Fields) == 0 && !t.Flatten) {
+		return value, nil
+	}
+	var document any
+	if err := json.Unmarshal(value, &document); err != nil {
+		return nil, fmt.Errorf("解析事件失败: %w", err)
+	}
+
+	if len(t.Fields) > 0 {
+		output := make(map[string]any, len(t.Fields))
+		for _, field := range t.Fields {
+			selected, ok := lookup(document, strings.Split(field.From, "."))
+			if !ok {
+				if len(field.Default) == 0 {
+					continue
+				}
+				if err := json.Unmarshal(field.Default, &selected); err != nil {
+					return nil, fmt.Errorf("解析默认值失败: %w", err)
+				}
+			}
+			assign(output, strings.Split(field.target(), "."), selected)
+		}
+		document = output
+	}
+
+	if t.Flatten {
+		separator := t.Separator
+		if separator == "" {
+			separator = defaultFlattenSeparator
+		}
+		flat := make(map[string]any)
+		flatten(flat, "", separator, document)
+		document = flat
+	}
+	return json.Marshal(document)
+}
+
+// lookup 按路径取值，路径中的*对数组中每个元素继续按剩余路径取值，返回取到的值组成的数组
+func lookup(value any, path []string) (any, bool) {
+	for i, key := range path {
+		switch node := value.(type) {
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			if key == "*" {
+				values := make([]any, 0, len(node))
+				for _, element := range node {
+					if selected, ok := lookup(element, path[i+1:]); ok {
+						values = append(values, selected)
+					}
+				}
+				return values, true
+			}
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			value = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// assign 按路径写入值，中间的对象不存在时创建
+func assign(output map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := output[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			output[key] = next
+		}
+		output = next
+	}
+	output[path[len(path)-1]] = value
+}
+
+// flatten 将嵌套的对象和数组展开为单层，键名为以分隔符连接的路径，空对象和空数组保留原值
+func flatten(output map[string]any, prefix, separator string, value any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + separator + key
+	}
+	switch node := value.(type) {
+	case map[string]any:
+		if len(node) == 0 && prefix != "" {
+			output[prefix] = node
+			return
+		}
+		for key, child := range node {
+			flatten(output, join(key), separator, child)
+		}
+	case []any:
+		if len(node) == 0 {
+			output[prefix] = node
+			return
+		}
+		for i, child := range node {
+			flatten(output, join(strconv.Itoa(i)), separator, child)
+		}
+	default:
+		output[prefix] = value
+	}
+}
@@ -1,21 +1,35 @@
 package service
 
 import (
+	"context"
 	"time"
 
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
 	"github.com/life2you/datas-go/handler"
 	"github.com/life2you/datas-go/logger"
 )
 
-func ScanBlockQueue() {
-	go func() {
+// ScanBlockQueue 启动区块队列扫描服务，扫描过程中panic时按退避时间重启，StopLoops 后退出
+// 启用 block_prefetch 时先启动区块预取
+func ScanBlockQueue(h *handler.Handler) {
+	if configs.GlobalConfig.BlockPrefetch.Enabled {
+		if prefetch := h.StartBlockPrefetch(&configs.GlobalConfig.BlockPrefetch); prefetch != nil {
+			goLoop("handler.block_prefetch", prefetch)
+		}
+	}
+	goLoop("service.block_queue", func(ctx context.Context) {
 		for {
 			// 处理一个区块
-			handler.StartScanBlockQueue()
+			h.StartScanBlockQueue()
 
 			// 添加延迟以避免过快处理
 			logger.Debug("区块扫描完成，等待下一次扫描")
-			time.Sleep(5 * time.Second)
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(5 * time.Second):
+			}
 		}
-	}()
+	})
 }
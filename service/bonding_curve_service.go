@@ -0,0 +1,29 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// StartBondingCurveService 启动联合曲线进度统计
+// 配置的代币额外订阅PumpPortal买卖消息，配置热更新时同步增减订阅
+func StartBondingCurveService() {
+	bondingCurveConfig := configs.GlobalConfig.BondingCurve
+	tracker := analytics.NewBondingCurveTracker(&bondingCurveConfig)
+	tracker.Start(pipeline.GlobalPipeline)
+	subscribeTokenTrades(bondingCurveConfig.Mints, nil)
+
+	configs.OnChange("bonding_curve", func(oldConfig, newConfig *configs.Config) {
+		if slices.Equal(oldConfig.BondingCurve.Mints, newConfig.BondingCurve.Mints) {
+			return
+		}
+		subscribeTokenTrades(newConfig.BondingCurve.Mints, oldConfig.BondingCurve.Mints)
+		logger.Info("联合曲线进度统计的代币已热更新", zap.Strings("mints", newConfig.BondingCurve.Mints))
+	})
+}
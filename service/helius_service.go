@@ -2,34 +2,184 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/cursor"
 	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/monitor"
 
 	"github.com/life2you/datas-go/logger"
 	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/supervisor"
 	"go.uber.org/zap"
 )
 
-// StartHeliusService 启动Helius服务
-func StartHeliusService() {
+// ingestSubscriptions ConnectHelius 建立的摄取订阅，失去摄取领导者身份时取消
+var (
+	ingestMu            sync.Mutex
+	ingestSubscriptions []int
+)
+
+// 摄取模式
+const (
+	IngestionModeSlot          = "slot"           // slotSubscribe，槽位推入区块队列后通过getBlock获取
+	IngestionModeBlockAll      = "block-all"      // blockSubscribe 订阅所有区块
+	IngestionModeBlockMentions = "block-mentions" // blockSubscribe 只订阅包含指定账户或程序的交易
+)
+
+// StartHeliusService 启动Helius服务，按 websocket.ingestion_mode 订阅槽位或区块
+// 启用领导者选举时只有成为领导者后才订阅
+func StartHeliusService(h *handler.Handler) {
+	if configs.GlobalConfig.Distributed.LeaderElection.Enabled {
+		StartIngestLeaderElection(h)
+		return
+	}
 	// 在后台协程中处理连接和订阅
-	go func() {
-		// 连接WebSocket
-		err := rpc.GlobalWebSocketClient.Connect(context.Background())
-		if err != nil {
-			logger.Fatal("连接WebSocket服务器失败", zap.Error(err))
-			return
+	supervisor.Go(context.Background(), "service.helius_connect", func(ctx context.Context) {
+		if err := ConnectHelius(ctx, h); err != nil {
+			logger.Fatal("启动Helius服务失败", zap.Error(err))
+		}
+	})
+
+	logger.Info("Helius服务已启动")
+}
+
+// ConnectHelius 连接Helius WebSocket并按摄取模式订阅，订阅成功后开始检测出块停滞
+// 已连接时只重新订阅，可在订阅失败后重复调用；订阅完成前 ctx 已取消(如失去摄取领导者身份)时取消刚建立的订阅
+func ConnectHelius(ctx context.Context, h *handler.Handler) error {
+	pool := rpc.GlobalWebSocketPool
+	if !pool.IsConnected() {
+		if err := pool.Connect(ctx); err != nil {
+			return fmt.Errorf("连接WebSocket服务器失败: %w", err)
 		}
-		logger.Info("成功连接到Helius WebSocket服务")
+		logger.Info("成功连接到Helius WebSocket服务", zap.Int("connections", pool.Size()))
+	}
+
+	// 订阅区块
+	subscriptionIDs, err := subscribe(pool, h, &configs.GlobalConfig.WebSocket)
+	// 降级时先取消 ctx 再调用 DisconnectHelius，持锁检查可保证订阅要么由这里取消，要么由 DisconnectHelius 取消
+	ingestMu.Lock()
+	if ctx.Err() != nil {
+		ingestMu.Unlock()
+		unsubscribe(subscriptionIDs)
+		return fmt.Errorf("订阅完成前已失去摄取领导者身份或启动已取消: %w", ctx.Err())
+	}
+	ingestSubscriptions = append(ingestSubscriptions, subscriptionIDs...)
+	ingestMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("订阅区块更新失败: %w", err)
+	}
+	logger.Info("成功订阅Helius区块更新",
+		zap.Ints("subscriptionIDs", subscriptionIDs),
+		zap.String("mode", configs.GlobalConfig.WebSocket.IngestionMode))
 
-		// 订阅区块
-		subscriptionID, err := rpc.GlobalWebSocketClient.SlotSubscribe(handler.HeliusSlotHandler)
+	// 订阅成功后开始检测出块停滞
+	if configs.GlobalConfig.StallDetection.Enabled && monitor.GlobalStallDetector == nil {
+		monitor.NewStallDetector(&configs.GlobalConfig.StallDetection).Start()
+	}
+	if configs.GlobalConfig.SubscriptionWatchdog.Enabled && monitor.GlobalSubscriptionWatchdog == nil {
+		monitor.NewSubscriptionWatchdog(&configs.GlobalConfig.SubscriptionWatchdog).Start()
+	}
+	return nil
+}
+
+// DisconnectHelius 取消 ConnectHelius 建立的摄取订阅，连接保持不变，失去摄取领导者身份时调用
+// 取消失败的订阅仍会推送通知，由处理器按领导者身份丢弃
+func DisconnectHelius() {
+	ingestMu.Lock()
+	subscriptionIDs := ingestSubscriptions
+	ingestSubscriptions = nil
+	ingestMu.Unlock()
+	unsubscribe(subscriptionIDs)
+	logger.Info("已取消摄取订阅", zap.Ints("subscriptionIDs", subscriptionIDs))
+}
+
+// unsubscribe 取消摄取订阅，失败时只记录日志
+func unsubscribe(subscriptionIDs []int) {
+	for _, subscriptionID := range subscriptionIDs {
+		if err := rpc.GlobalWebSocketPool.Unsubscribe(subscriptionID); err != nil {
+			logger.Warn("取消摄取订阅失败", zap.Int("subscriptionID", subscriptionID), zap.Error(err))
+		}
+	}
+}
+
+// StartIngestLeaderElection 启动WebSocket摄取的领导者选举，成为领导者后订阅，失去领导者身份后取消订阅
+// 接管时先从Redis重新同步区块游标，备用期间缺失的槽位按游标回补
+func StartIngestLeaderElection(h *handler.Handler) {
+	monitor.NewLeaderElector(&configs.GlobalConfig.Distributed, func(ctx context.Context) {
+		if err := cursor.Resync(ctx); err != nil {
+			logger.Warn("重新同步区块游标失败，接管前缺失的槽位需要通过 backfill 命令回补", zap.Error(err))
+		}
+		for {
+			err := ConnectHelius(ctx, h)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			logger.Error("接管摄取后订阅失败，稍后重试", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(5 * time.Second):
+			}
+		}
+	}, DisconnectHelius).Start()
+}
+
+// subscribe 按摄取模式订阅，返回客户端订阅ID
+// slot 模式的槽位通知和 transactionDetails=none 的区块通知只推入区块队列，
+// transactionDetails=full 的区块通知按批汇总交易签名，直接推入交易队列。
+// block-mentions 的每个地址单独订阅，由连接池分散到各连接
+func subscribe(pool *rpc.WebSocketPool, h *handler.Handler, config *configs.WebSocketConfig) ([]int, error) {
+	mode, addresses, _ := strings.Cut(config.IngestionMode, ":")
+	var filters []interface{}
+	switch mode {
+	case "", IngestionModeSlot:
+		subscriptionID, err := pool.SlotSubscribe(h.HeliusSlotHandler)
 		if err != nil {
-			logger.Fatal("订阅区块更新失败", zap.Error(err))
-			return
+			return nil, err
+		}
+		return []int{subscriptionID}, nil
+	case IngestionModeBlockAll:
+		filters = []interface{}{"all"}
+	case IngestionModeBlockMentions:
+		for _, address := range strings.Split(addresses, ",") {
+			filters = append(filters, map[string]string{"mentionsAccountOrProgram": strings.TrimSpace(address)})
 		}
-		logger.Info("成功订阅Helius区块更新", zap.Int("subscriptionID", subscriptionID))
-	}()
+	default:
+		return nil, fmt.Errorf("不支持的摄取模式: %s", config.IngestionMode)
+	}
 
-	logger.Info("Helius服务已启动")
+	options := map[string]interface{}{
+		"commitment":                     config.BlockCommitment,
+		"encoding":                       "json",
+		"maxSupportedTransactionVersion": 0,
+		"showRewards":                    false,
+	}
+	details := config.BlockTransactionDetails
+	if details != "none" {
+		details = "full"
+	}
+	options["transactionDetails"] = details
+	// 所有订阅共用一个分块处理器，同一区块的交易在连接池中去重
+	stream := h.NewHeliusBlockStreamHandler()
+	subscriptionIDs := make([]int, 0, len(filters))
+	for _, filter := range filters {
+		var subscriptionID int
+		var err error
+		if details == "none" {
+			subscriptionID, err = pool.BlockSubscribe(filter, options, h.HeliusBlockHandler)
+		} else {
+			subscriptionID, err = pool.BlockStreamSubscribe(filter, options, stream)
+		}
+		if err != nil {
+			return subscriptionIDs, fmt.Errorf("订阅 %v 失败: %w", filter, err)
+		}
+		subscriptionIDs = append(subscriptionIDs, subscriptionID)
+	}
+	return subscriptionIDs, nil
 }
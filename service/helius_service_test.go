@@ -0,0 +1,36 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/service"
+	"github.com/life2you/datas-go/testutil"
+)
+
+// TestConnectHeliusUnsubscribesAfterDemotion 订阅完成前已降级(ctx 已取消)时取消刚建立的订阅，不会重复推送槽位
+func TestConnectHeliusUnsubscribesAfterDemotion(t *testing.T) {
+	h := testutil.NewHarness(t, nil)
+	pool := rpc.NewWebSocketPool(&h.Config.WebSocket)
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := service.ConnectHelius(ctx, h.Handler); err == nil {
+		t.Fatal("ctx 已取消时应返回错误")
+	}
+	testutil.Eventually(t, 5*time.Second, func() bool { return !h.Helius.Subscribed("slotNotification") }, "取消槽位订阅")
+
+	// DisconnectHelius 没有需要取消的订阅
+	service.DisconnectHelius()
+	if err := service.ConnectHelius(context.Background(), h.Handler); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Eventually(t, 5*time.Second, func() bool { return h.Helius.Subscribed("slotNotification") }, "槽位订阅")
+	service.DisconnectHelius()
+	testutil.Eventually(t, 5*time.Second, func() bool { return !h.Helius.Subscribed("slotNotification") }, "取消槽位订阅")
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/jobs"
+	"github.com/life2you/datas-go/monitor"
+)
+
+// RegisterJobs 注册内置的定时任务，作为库嵌入时可以在创建调度器之前通过 jobs.Register 注册更多任务
+func RegisterJobs() {
+	jobs.Register("transaction_index_cleanup", cleanupTransactionIndex)
+	jobs.Register("retention", applyRetention)
+}
+
+// jobScheduled 返回启用定时任务时是否配置了按调度表达式执行的任务 name
+func jobScheduled(name string) bool {
+	config := configs.GlobalConfig.Jobs
+	if !config.Enabled {
+		return false
+	}
+	return slices.ContainsFunc(config.Jobs, func(job configs.JobConfig) bool {
+		return job.Name == name && job.Schedule != ""
+	})
+}
+
+// StartJobs 注册内置任务并按 jobs 配置启动定时任务调度
+// 返回:
+//   - error: 配置的任务没有注册或调度表达式无效时返回错误
+func StartJobs() error {
+	RegisterJobs()
+	scheduler, err := jobs.NewScheduler(&configs.GlobalConfig.Jobs, monitor.InstanceID())
+	if err != nil {
+		return err
+	}
+	scheduler.Start()
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// loops 区块队列扫描、交易队列处理等后台循环的生命周期，StopLoops 取消后等待循环退出
+var loops struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// goLoop 在新协程中运行后台循环，panic时按退避时间重启，StopLoops 取消 ctx 后退出
+func goLoop(name string, fn func(ctx context.Context)) {
+	loops.mu.Lock()
+	if loops.ctx == nil {
+		loops.ctx, loops.cancel = context.WithCancel(context.Background())
+	}
+	ctx := loops.ctx
+	loops.wg.Add(1)
+	loops.mu.Unlock()
+
+	go func() {
+		defer loops.wg.Done()
+		supervisor.Run(ctx, name, fn)
+	}()
+}
+
+// StopLoops 停止 ScanBlockQueue、ProcessTransactionQueue 启动的后台循环，
+// 等待正在处理的区块和交易批次完成后返回；之后启动的循环使用新的上下文
+func StopLoops() {
+	loops.mu.Lock()
+	cancel := loops.cancel
+	loops.ctx, loops.cancel = nil, nil
+	loops.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	loops.wg.Wait()
+}
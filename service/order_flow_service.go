@@ -0,0 +1,59 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// StartOrderFlowService 启动关注代币的买卖盘失衡统计
+// 关注的代币同时订阅PumpPortal买卖消息，配置热更新时同步增减订阅
+func StartOrderFlowService() {
+	orderFlowConfig := configs.GlobalConfig.OrderFlow
+	tracker := analytics.NewOrderFlowTracker(&orderFlowConfig)
+	tracker.Start(pipeline.GlobalPipeline)
+	subscribeTokenTrades(orderFlowConfig.Mints, nil)
+
+	configs.OnChange("order_flow", func(oldConfig, newConfig *configs.Config) {
+		if slices.Equal(oldConfig.OrderFlow.Mints, newConfig.OrderFlow.Mints) {
+			return
+		}
+		tracker.SetMints(newConfig.OrderFlow.Mints)
+		subscribeTokenTrades(newConfig.OrderFlow.Mints, oldConfig.OrderFlow.Mints)
+		logger.Info("关注代币已热更新", zap.Strings("mints", newConfig.OrderFlow.Mints))
+	})
+}
+
+// subscribeTokenTrades 订阅新增代币、取消订阅移除代币的PumpPortal买卖消息
+func subscribeTokenTrades(mints, oldMints []string) {
+	if rpc.GlobalPumpPortalClient == nil {
+		return
+	}
+	var added, removed []string
+	for _, mint := range mints {
+		if !slices.Contains(oldMints, mint) {
+			added = append(added, mint)
+		}
+	}
+	for _, mint := range oldMints {
+		if !slices.Contains(mints, mint) {
+			removed = append(removed, mint)
+		}
+	}
+	if len(added) > 0 {
+		if err := rpc.GlobalPumpPortalClient.SubscribeTokenTrade(added); err != nil {
+			logger.Error("订阅代币买卖消息失败", zap.Strings("mints", added), zap.Error(err))
+		}
+	}
+	if len(removed) > 0 {
+		if err := rpc.GlobalPumpPortalClient.UnsubscribeTokenTrade(removed); err != nil {
+			logger.Error("取消订阅代币买卖消息失败", zap.Strings("mints", removed), zap.Error(err))
+		}
+	}
+}
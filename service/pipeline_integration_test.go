@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/testutil"
+)
+
+// TestPipelineIndexesParsedTransactions 槽位通知 → getBlock → Enhanced API解析 → Redis索引的完整流程
+func TestPipelineIndexesParsedTransactions(t *testing.T) {
+	h := testutil.NewHarness(t, nil)
+	ts := time.Now().Unix()
+	const slot = 100
+	h.Helius.SetSlot(slot)
+	h.Helius.AddBlock(slot, testutil.BlockFixture(slot, ts, "sigA", "sigB"))
+	h.Helius.AddTransaction("sigA", testutil.ParsedTransactionFixture("sigA", slot, ts, ""))
+	h.Helius.AddTransaction("sigB", testutil.ParsedTransactionFixture("sigB", slot, ts, "OtherMint"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := h.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Eventually(t, 5*time.Second, func() bool { return h.Helius.Subscribed("slotNotification") }, "槽位订阅")
+	if err := h.Helius.NotifySlot(slot); err != nil {
+		t.Fatal(err)
+	}
+
+	redis := storage.GetRedisClient(storage.WorkloadAnalytics)
+	day := storage.IndexDay(ts)
+	var indexed []string
+	testutil.Eventually(t, 10*time.Second, func() bool {
+		signatures, err := redis.GetIndexedTransactions(ctx, string(resp.SourceRaydium), string(resp.TransactionTypeSwap), day, 10)
+		if err != nil {
+			return false
+		}
+		indexed = signatures
+		return len(indexed) == 2
+	}, "交易按来源和类型索引")
+	slices.Sort(indexed)
+	if !slices.Equal(indexed, []string{"sigA", "sigB"}) {
+		t.Fatalf("索引的签名 = %v，期望 [sigA sigB]", indexed)
+	}
+
+	mintSignatures, err := redis.GetMintTransactions(ctx, testutil.FixtureMint, day, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(mintSignatures, []string{"sigA"}) {
+		t.Fatalf("代币索引的签名 = %v，期望 [sigA]", mintSignatures)
+	}
+
+	if got := h.Helius.Requests("getBlock"); got == 0 {
+		t.Fatal("没有请求 getBlock")
+	}
+	if got := h.Helius.Requests("/v0/transactions"); got == 0 {
+		t.Fatal("没有请求 /v0/transactions")
+	}
+}
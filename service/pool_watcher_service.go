@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models/req"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/parser"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+const (
+	// poolCandidateQueueSize 等待获取交易的池子初始化签名数上限，超过时丢弃新的签名
+	poolCandidateQueueSize = 1000
+	// poolSeenLimit 已处理签名的去重记录上限，超过时清空
+	poolSeenLimit = 10000
+)
+
+// poolCandidate 日志显示可能创建了池子的交易
+type poolCandidate struct {
+	signature string
+	program   string
+}
+
+// poolWatcher 通过logsSubscribe订阅AMM程序的日志，发现池子初始化后获取完整交易解析代币对和初始流动性
+type poolWatcher struct {
+	config        configs.PoolWatcherConfig
+	programs      []string
+	subscriptions map[string]int // 程序ID -> 客户端订阅ID，只在订阅协程中访问
+	candidates    chan poolCandidate
+	seen          map[string]struct{} // 已处理的签名，只在处理协程中访问
+	log           *zap.Logger
+}
+
+// StartPoolWatcher 启动流动性池创建监控
+// 订阅在Helius WebSocket连接建立后进行，并按 check_interval 检查，连接断开导致订阅失效后重新订阅
+// 参数:
+//   - config: 监控配置，programs 中不支持的程序会被忽略
+func StartPoolWatcher(config *configs.PoolWatcherConfig) {
+	w := &poolWatcher{
+		config:        *config,
+		subscriptions: make(map[string]int),
+		candidates:    make(chan poolCandidate, poolCandidateQueueSize),
+		seen:          make(map[string]struct{}),
+		log:           logger.Named("service.pool_watcher"),
+	}
+	supported := parser.PoolPrograms()
+	for _, name := range config.Programs {
+		program := parser.ResolveProgram(name)
+		if !slices.Contains(supported, program) {
+			w.log.Warn("不支持识别该程序创建的池子，已忽略", zap.String("program", name))
+			continue
+		}
+		if !slices.Contains(w.programs, program) {
+			w.programs = append(w.programs, program)
+		}
+	}
+	if len(w.programs) == 0 {
+		return
+	}
+	if rpc.GlobalHeliusClient == nil {
+		rpc.NewHeliusClient(&configs.GlobalConfig.HeliusAPI)
+	}
+	supervisor.Go(context.Background(), "service.pool_watcher", func(context.Context) { w.run() })
+	supervisor.Go(context.Background(), "service.pool_watcher.process", func(context.Context) { w.process() })
+	w.log.Info("流动性池创建监控已启动", zap.Strings("programs", w.programs))
+}
+
+// run 定期检查订阅，缺失的订阅重新建立
+func (w *poolWatcher) run() {
+	ticker := time.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		w.ensureSubscriptions()
+		<-ticker.C
+	}
+}
+
+// ensureSubscriptions 为没有有效订阅的程序订阅日志，WebSocket未连接时等待下次检查
+func (w *poolWatcher) ensureSubscriptions() {
+	pool := rpc.GlobalWebSocketPool
+	if pool == nil || !pool.IsConnected() {
+		return
+	}
+	for _, program := range w.programs {
+		if subscriptionID, ok := w.subscriptions[program]; ok && pool.HasSubscription(subscriptionID) {
+			continue
+		}
+		filter := map[string][]string{"mentions": {program}}
+		subscriptionID, err := pool.LogsSubscribe(filter, w.config.Commitment, w.handleLogs(program))
+		if err != nil {
+			w.log.Error("订阅程序日志失败，等待下次检查", zap.String("program", program), zap.Error(err))
+			continue
+		}
+		w.subscriptions[program] = subscriptionID
+	}
+}
+
+// handleLogs 返回程序日志通知的处理函数，只有日志显示可能创建了池子的成功交易才会获取完整交易
+func (w *poolWatcher) handleLogs(program string) rpc.SubscriptionHandler {
+	return func(result json.RawMessage) {
+		var notification struct {
+			Value struct {
+				Signature string          `json:"signature"`
+				Err       json.RawMessage `json:"err"`
+				Logs      []string        `json:"logs"`
+			} `json:"value"`
+		}
+		if err := json.Unmarshal(result, &notification); err != nil {
+			w.log.Debug("解析日志通知失败", zap.Error(err))
+			return
+		}
+		value := notification.Value
+		if value.Signature == "" || (len(value.Err) > 0 && string(value.Err) != "null") {
+			return
+		}
+		if !parser.IsPoolCreationLog(program, value.Logs) {
+			return
+		}
+		select {
+		case w.candidates <- poolCandidate{signature: value.Signature, program: program}:
+		default:
+			w.log.Warn("待处理的池子创建交易过多，已丢弃", zap.String("signature", value.Signature))
+		}
+	}
+}
+
+// process 逐个获取候选交易并解析池子创建
+func (w *poolWatcher) process() {
+	for candidate := range w.candidates {
+		if _, ok := w.seen[candidate.signature]; ok {
+			continue
+		}
+		if len(w.seen) >= poolSeenLimit {
+			clear(w.seen)
+		}
+		w.seen[candidate.signature] = struct{}{}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if err := w.handleCandidate(ctx, candidate); err != nil {
+			w.log.Error("解析池子创建交易失败", zap.String("signature", candidate.signature), zap.String("program", candidate.program), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// handleCandidate 获取交易，记录其中创建的池子并发布到事件管道
+func (w *poolWatcher) handleCandidate(ctx context.Context, candidate poolCandidate) error {
+	transaction, err := w.fetch(ctx, candidate.signature)
+	if err != nil {
+		return err
+	}
+	creations := parser.DecodePoolCreations(resp.Transactions{
+		Meta:        transaction.Meta,
+		Transaction: transaction.Transaction,
+		Version:     transaction.Version,
+	})
+	now := clock.Now()
+	for _, creation := range creations {
+		creation.Slot = transaction.Slot
+		creation.BlockTime = transaction.BlockTime
+		creation.DetectedAt = now.Unix()
+		w.log.Info("发现新建池子",
+			zap.String("dex", creation.Dex),
+			zap.String("pool", creation.Pool),
+			zap.String("mint_a", creation.MintA),
+			zap.String("mint_b", creation.MintB),
+			zap.Float64("amount_a", creation.AmountA),
+			zap.Float64("amount_b", creation.AmountB))
+		if err := storage.GetRedisClient(storage.WorkloadAnalytics).RecordPoolCreation(ctx, creation, w.config.MaxHistory); err != nil {
+			w.log.Error("记录池子创建失败", zap.String("pool", creation.Pool), zap.Error(err))
+		}
+		pipeline.Publish(pipeline.Event{
+			Type:      pipeline.EventPoolCreated,
+			Slot:      creation.Slot,
+			Signature: creation.Signature,
+			Pool:      &creation,
+			Time:      now,
+		})
+	}
+	return nil
+}
+
+// fetch 获取交易，交易刚确认时getTransaction可能尚未返回，按 fetch_delay 重试
+func (w *poolWatcher) fetch(ctx context.Context, signature string) (*resp.GetTransactionResp, error) {
+	params := &req.GetTransactionParams{
+		Encoding:                       "json",
+		MaxSupportedTransactionVersion: 0,
+		Commitment:                     w.config.Commitment,
+	}
+	for attempt := 1; ; attempt++ {
+		body, err := rpc.GlobalHeliusClient.GetTransaction(ctx, signature, params)
+		if err == nil && len(body) > 0 && string(body) != "null" {
+			var transaction resp.GetTransactionResp
+			if err := json.Unmarshal(body, &transaction); err != nil {
+				return nil, fmt.Errorf("解析交易数据失败: %w", err)
+			}
+			return &transaction, nil
+		}
+		if attempt >= w.config.FetchAttempts {
+			if err == nil {
+				err = errors.New("getTransaction未找到该交易")
+			}
+			return nil, err
+		}
+		select {
+		case <-clock.After(w.config.FetchDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// StartPositionService 启动持仓统计，配置热更新时同步统计的代币
+func StartPositionService() {
+	tracker := analytics.NewPositionTracker(&configs.GlobalConfig.Positions)
+	tracker.Start(pipeline.GlobalPipeline)
+
+	configs.OnChange("positions", func(oldConfig, newConfig *configs.Config) {
+		if slices.Equal(oldConfig.Positions.Mints, newConfig.Positions.Mints) {
+			return
+		}
+		tracker.SetMints(newConfig.Positions.Mints)
+		logger.Info("持仓统计的代币已热更新", zap.Strings("mints", newConfig.Positions.Mints))
+	})
+}
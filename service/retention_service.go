@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/storage"
+)
+
+// applyRetention 按 retention 配置处理一次Redis中的旧键，作为定时任务 retention 执行
+func applyRetention(ctx context.Context) error {
+	// 每次读取最新配置，策略支持热更新
+	config := configs.GlobalConfig.Retention
+	report, err := storage.ApplyRetention(ctx, &config, false)
+	metrics.RecordRetention(report)
+	log := logger.Named("storage.retention")
+	for _, result := range report.Policies {
+		log.Info("保留策略执行完成",
+			zap.String("policy", result.Policy),
+			zap.Bool("dryRun", report.DryRun),
+			zap.Int64("scanned", result.Scanned),
+			zap.Int64("matched", result.Matched),
+			zap.Int64("expired", result.Expired),
+			zap.Int64("deleted", result.Deleted),
+			zap.Int64("trimmed", result.Trimmed),
+			zap.Strings("samples", result.Samples))
+	}
+	return err
+}
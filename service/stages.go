@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/export"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/monitor"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/storage"
+)
+
+// 采集流程的阶段，数据依次流经 ingest → block_fetch → parse → sink
+const (
+	StageIngest     = "ingest"      // 连接Helius WebSocket并订阅，槽位和区块推入队列
+	StageBlockFetch = "block_fetch" // 从区块队列取出槽位并获取区块
+	StageParse      = "parse"       // 从交易队列取出签名并通过Enhanced API解析
+	StageSink       = "sink"        // 解析结果写入的存储
+)
+
+// RegisterStages 将采集流程的各阶段加入编排器
+// block_fetch 在订阅成功后启动；parse 在 block_fetch 和 sink 都就绪后启动，避免解析结果无处写入
+func RegisterStages(stages *pipeline.Stages, h *handler.Handler) {
+	stages.Add(pipeline.Stage{
+		Name: StageSink,
+		Start: func(ctx context.Context) error {
+			for _, workload := range []storage.Workload{storage.WorkloadQueue, storage.WorkloadCache, storage.WorkloadAnalytics} {
+				if err := storage.GetRedisClient(workload).Ping(ctx); err != nil {
+					return fmt.Errorf("Redis(%s)不可用: %w", workload, err)
+				}
+			}
+			if export.GlobalClickHouseSink != nil {
+				if err := export.GlobalClickHouseSink.Ping(ctx); err != nil {
+					return fmt.Errorf("ClickHouse不可用: %w", err)
+				}
+			}
+			return nil
+		},
+	})
+	stages.Add(pipeline.Stage{
+		Name: StageIngest,
+		Start: func(ctx context.Context) error {
+			if rpc.GlobalWebSocketPool == nil {
+				rpc.NewWebSocketPool(&configs.GlobalConfig.WebSocket)
+			}
+			// 启用领导者选举时备用实例也视为就绪，下游阶段继续处理共享的交易队列和回收的区块
+			if configs.GlobalConfig.Distributed.LeaderElection.Enabled {
+				if monitor.GlobalLeaderElector == nil {
+					StartIngestLeaderElection(h)
+				}
+				return nil
+			}
+			return ConnectHelius(ctx, h)
+		},
+	})
+	stages.Add(pipeline.Stage{
+		Name:      StageBlockFetch,
+		DependsOn: []string{StageIngest},
+		Start: func(ctx context.Context) error {
+			if rpc.GlobalHeliusClient == nil {
+				rpc.NewHeliusClient(&configs.GlobalConfig.HeliusAPI)
+			}
+			// 先确认RPC节点可用，再开始扫描区块队列
+			if _, err := rpc.GlobalHeliusClient.GetSlot(ctx, ""); err != nil {
+				return fmt.Errorf("Helius RPC不可用: %w", err)
+			}
+			ScanBlockQueue(h)
+			return nil
+		},
+	})
+	stages.Add(pipeline.Stage{
+		Name:      StageParse,
+		DependsOn: []string{StageBlockFetch, StageSink},
+		Start: func(ctx context.Context) error {
+			if rpc.GetEnhancedApiClientCount() == 0 {
+				rpc.NewHeliusEnhancedApiClient(&configs.GlobalConfig.HeliusEnhancedAPI)
+			}
+			if rpc.GetEnhancedApiClientCount() == 0 {
+				return errors.New("没有可用的Enhanced API客户端，请检查 helius_enhanced_api.api_keys")
+			}
+			ProcessTransactionQueue(h)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// StartTokenAccountService 启动代币账户创建/关闭统计，配置热更新时同步统计的代币
+func StartTokenAccountService() {
+	tracker := analytics.NewTokenAccountTracker(&configs.GlobalConfig.TokenAccounts)
+	tracker.Start()
+
+	configs.OnChange("token_accounts", func(oldConfig, newConfig *configs.Config) {
+		if slices.Equal(oldConfig.TokenAccounts.Mints, newConfig.TokenAccounts.Mints) {
+			return
+		}
+		tracker.SetMints(newConfig.TokenAccounts.Mints)
+		logger.Info("代币账户统计的代币已热更新", zap.Strings("mints", newConfig.TokenAccounts.Mints))
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/analytics"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// StartTokenStatsService 启动代币24小时统计，配置热更新时同步统计的代币
+func StartTokenStatsService() {
+	tracker := analytics.NewTokenStatsTracker(&configs.GlobalConfig.TokenStats)
+	tracker.Start(pipeline.GlobalPipeline)
+
+	configs.OnChange("token_stats", func(oldConfig, newConfig *configs.Config) {
+		if slices.Equal(oldConfig.TokenStats.Mints, newConfig.TokenStats.Mints) {
+			return
+		}
+		tracker.SetMints(newConfig.TokenStats.Mints)
+		logger.Info("代币24小时统计的代币已热更新", zap.Strings("mints", newConfig.TokenStats.Mints))
+	})
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// StartTransactionIndexCleanup 按 transaction_index.cleanup_interval 定期清理按天索引的交易，
+// 删除超过保留时长的键、为没有过期时间的键补设过期时间并裁剪超过签名数上限的键；
+// 已配置定时任务 transaction_index_cleanup 时由定时任务执行，不再单独定期清理
+func StartTransactionIndexCleanup() {
+	interval := configs.GlobalConfig.TransactionIndex.CleanupInterval
+	if interval <= 0 {
+		return
+	}
+	log := logger.Named("storage.index_cleanup")
+	if jobScheduled("transaction_index_cleanup") {
+		log.Info("交易索引清理由定时任务 transaction_index_cleanup 执行，忽略 cleanup_interval")
+		return
+	}
+	supervisor.Go(context.Background(), "storage.index_cleanup", func(context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := cleanupTransactionIndex(ctx); err != nil {
+				log.Warn("清理交易索引失败", zap.Error(err))
+			}
+			cancel()
+		}
+	})
+	log.Info("交易索引清理任务已启动", zap.Duration("interval", interval))
+}
+
+// cleanupTransactionIndex 清理一次按天索引的交易，也作为定时任务 transaction_index_cleanup 执行
+func cleanupTransactionIndex(ctx context.Context) error {
+	// 每次读取最新配置，保留时长和签名数上限支持热更新
+	config := configs.GlobalConfig.TransactionIndex
+	result, err := storage.GetRedisClient(storage.WorkloadAnalytics).CleanupTransactionIndex(ctx, &config, 500)
+	if err != nil {
+		return err
+	}
+	if result.Deleted > 0 || result.Expired > 0 || result.Trimmed > 0 {
+		logger.Named("storage.index_cleanup").Info("交易索引已清理",
+			zap.Int64("scanned", result.Scanned),
+			zap.Int64("deleted", result.Deleted),
+			zap.Int64("expired", result.Expired),
+			zap.Int64("trimmed", result.Trimmed))
+	}
+	return nil
+}
@@ -1,23 +1,25 @@
 package service
 
 import (
+	"context"
+
 	"github.com/life2you/datas-go/handler"
 	"github.com/life2you/datas-go/logger"
 )
 
-// ProcessTransactionQueue 启动队列处理服务
-func ProcessTransactionQueue() {
-	go func() {
+// ProcessTransactionQueue 启动队列处理服务，处理过程中panic时按退避时间重启，StopLoops 后退出
+func ProcessTransactionQueue(h *handler.Handler) {
+	goLoop("service.transaction_queue", func(ctx context.Context) {
 		// 等待系统初始化完成
 
 		logger.Info("启动交易队列处理服务")
 
-		for {
+		for ctx.Err() == nil {
 			// 处理交易队列
-			handler.StartProcessTransactionQueue()
+			h.StartProcessTransactionQueue()
 			// 添加处理间隔，防止过度消耗系统资源
 		}
-	}()
+	})
 
 	logger.Info("交易队列处理服务已启动")
 }
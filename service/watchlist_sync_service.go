@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/supervisor"
+	"github.com/life2you/datas-go/watchlist"
+)
+
+// watchlistSync 将关注地址同步到Helius Webhook的监控地址和PumpPortal账户交易订阅，使各接入渠道跟踪同一组地址
+type watchlistSync struct {
+	config      configs.WatchlistConfig
+	list        *watchlist.Watchlist
+	webhooks    *rpc.HeliusWebhookClient
+	pumpPortal  *rpc.PumpPortalClient
+	trigger     chan struct{}
+	reconnected atomic.Bool // PumpPortal重连后订阅已丢失，下次同步时重新订阅全部地址
+	log         *zap.Logger
+
+	subscribed []string // 已通过PumpPortal订阅的地址，只在同步协程中访问
+}
+
+// StartWatchlistSync 启动关注地址同步
+// 地址增删(包括其他实例的修改)后立即同步，并按 sync_interval 定期全量同步以修复失败和手动修改造成的差异
+// 参数:
+//   - list: 已加载的关注地址列表
+//   - config: 同步配置，webhook_id 为空且未开启 pump_portal 时不启动
+func StartWatchlistSync(list *watchlist.Watchlist, config *configs.WatchlistConfig) {
+	if config.WebhookID == "" && !config.PumpPortal {
+		return
+	}
+	s := &watchlistSync{
+		config:  *config,
+		list:    list,
+		trigger: make(chan struct{}, 1),
+		log:     logger.Named("service.watchlist_sync"),
+	}
+	if config.WebhookID != "" {
+		s.webhooks = rpc.NewHeliusWebhookClient(&configs.GlobalConfig.HeliusWebhook)
+	}
+	if config.PumpPortal && rpc.GlobalPumpPortalClient != nil {
+		s.pumpPortal = rpc.GlobalPumpPortalClient
+		s.pumpPortal.OnReconnect(func() {
+			s.reconnected.Store(true)
+			s.notify()
+		})
+	}
+	list.OnChange(s.notify)
+	s.notify()
+	supervisor.Go(context.Background(), "service.watchlist_sync", func(context.Context) { s.run() })
+}
+
+// notify 触发一次同步，已有待处理的同步时合并
+func (s *watchlistSync) notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// run 收到变更通知或定期执行同步
+func (s *watchlistSync) run() {
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.trigger:
+		case <-ticker.C:
+		}
+		if err := s.sync(); err != nil {
+			s.log.Error("同步关注地址失败，等待下次同步", zap.Error(err))
+		}
+	}
+}
+
+// sync 将当前关注地址同步到各接入渠道
+func (s *watchlistSync) sync() error {
+	addresses := s.list.Addresses()
+	var errs []error
+	if s.webhooks != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		errs = append(errs, s.syncWebhook(ctx, addresses))
+		cancel()
+	}
+	if s.pumpPortal != nil {
+		errs = append(errs, s.syncPumpPortal(addresses))
+	}
+	return errors.Join(errs...)
+}
+
+// syncWebhook 使Webhook的监控地址与关注地址一致，不在关注地址中的地址会被移除
+// 关注地址为空时除非开启 allow_empty，否则不清空Webhook的监控地址
+func (s *watchlistSync) syncWebhook(ctx context.Context, addresses []string) error {
+	webhook, err := s.webhooks.GetWebhook(ctx, s.config.WebhookID)
+	if err != nil {
+		return fmt.Errorf("获取Webhook失败: %w", err)
+	}
+	if len(addresses) == 0 && len(webhook.AccountAddresses) > 0 && !s.config.AllowEmpty {
+		return fmt.Errorf("关注地址为空，拒绝移除Webhook的全部 %d 个监控地址，确需清空时设置 watchlist.allow_empty", len(webhook.AccountAddresses))
+	}
+	added, removed := diffAddresses(addresses, webhook.AccountAddresses)
+	if len(removed) > 0 {
+		if _, err := s.webhooks.RemoveAddressesFromWebhook(ctx, s.config.WebhookID, removed); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		if _, err := s.webhooks.AppendAddressesToWebhook(ctx, s.config.WebhookID, added); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		s.log.Info("已同步关注地址到Webhook",
+			zap.String("webhook_id", s.config.WebhookID),
+			zap.Int("added", len(added)),
+			zap.Int("removed", len(removed)))
+	}
+	return nil
+}
+
+// syncPumpPortal 订阅新增地址、取消订阅移除地址的PumpPortal账户交易，重连后重新订阅全部地址
+func (s *watchlistSync) syncPumpPortal(addresses []string) error {
+	if s.reconnected.Swap(false) {
+		s.subscribed = nil
+	}
+	if !s.pumpPortal.IsConnected() {
+		return errors.New("PumpPortal未连接")
+	}
+	added, removed := diffAddresses(addresses, s.subscribed)
+	if len(added) > 0 {
+		if err := s.pumpPortal.SubscribeAccountTrade(added); err != nil {
+			return fmt.Errorf("订阅账户交易失败: %w", err)
+		}
+	}
+	if len(removed) > 0 {
+		if err := s.pumpPortal.UnsubscribeAccountTrade(removed); err != nil {
+			return fmt.Errorf("取消订阅账户交易失败: %w", err)
+		}
+	}
+	s.subscribed = addresses
+	if len(added) > 0 || len(removed) > 0 {
+		s.log.Info("已同步关注地址到PumpPortal", zap.Int("added", len(added)), zap.Int("removed", len(removed)))
+	}
+	return nil
+}
+
+// diffAddresses 对比期望的地址和实际的地址，返回需要新增和移除的地址
+func diffAddresses(desired, actual []string) (added, removed []string) {
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, address := range desired {
+		desiredSet[address] = struct{}{}
+	}
+	actualSet := make(map[string]struct{}, len(actual))
+	for _, address := range actual {
+		actualSet[address] = struct{}{}
+		if _, ok := desiredSet[address]; !ok {
+			removed = append(removed, address)
+		}
+	}
+	for _, address := range desired {
+		if _, ok := actualSet[address]; !ok {
+			added = append(added, address)
+		}
+	}
+	return added, removed
+}
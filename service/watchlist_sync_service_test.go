@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/rpc"
+)
+
+func TestWatchlistSyncRefusesEmptyWebhook(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	var edits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			edits++
+		}
+		json.NewEncoder(w).Encode(rpc.Webhook{ID: "watchlist", AccountAddresses: []string{"alice", "bob"}})
+	}))
+	t.Cleanup(server.Close)
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.HeliusWebhook.Endpoint = server.URL
+	cfg.HeliusWebhook.APIKey = "test"
+	s := &watchlistSync{
+		config:   configs.WatchlistConfig{WebhookID: "watchlist"},
+		webhooks: rpc.NewHeliusWebhookClient(&cfg.HeliusWebhook),
+		log:      logger.Named("service.watchlist_sync"),
+	}
+	t.Cleanup(func() { rpc.GlobalHeliusWebhookClient = nil })
+
+	if err := s.syncWebhook(context.Background(), nil); err == nil || edits != 0 {
+		t.Fatalf("关注地址为空时应拒绝同步，错误 %v，修改请求 %d 次", err, edits)
+	}
+	s.config.AllowEmpty = true
+	if err := s.syncWebhook(context.Background(), nil); err != nil || edits != 1 {
+		t.Fatalf("开启 allow_empty 后应清空Webhook，错误 %v，修改请求 %d 次", err, edits)
+	}
+}
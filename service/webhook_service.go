@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/rpc"
+)
+
+// WebhookSyncPlan 声明的Webhook与Helius上实际Webhook的差异
+type WebhookSyncPlan struct {
+	Create []rpc.Webhook // 需要创建的Webhook
+	Update []rpc.Webhook // 需要更新的Webhook，ID为Helius上已有的Webhook
+	Delete []rpc.Webhook // 需要删除的Webhook，仅在开启prune时生成
+}
+
+// Empty 判断是否无需变更
+func (p *WebhookSyncPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// PlanWebhookSync 对比声明的Webhook与Helius上的实际Webhook，生成同步计划
+// 参数:
+//   - ctx: 上下文
+//   - client: Webhook管理客户端
+//   - config: Webhook配置
+//   - watchlistWebhookID: 关注地址同步使用的Webhook(watchlist.webhook_id)，其地址由关注地址同步维护，prune时不删除
+//
+// 返回:
+//   - *WebhookSyncPlan: 同步计划
+//   - error: 错误信息，开启prune但没有声明任何Webhook时拒绝生成计划
+func PlanWebhookSync(ctx context.Context, client *rpc.HeliusWebhookClient, config *configs.HeliusWebhookConfig, watchlistWebhookID string) (*WebhookSyncPlan, error) {
+	if config.Prune && len(config.Webhooks) == 0 {
+		return nil, errors.New("helius_webhook.prune=true 但没有声明任何Webhook，拒绝删除账户下的所有Webhook")
+	}
+	actual, err := client.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return planWebhookSync(actual, config, watchlistWebhookID), nil
+}
+
+// planWebhookSync 对比声明的Webhook与实际Webhook，生成同步计划
+func planWebhookSync(actual []rpc.Webhook, config *configs.HeliusWebhookConfig, watchlistWebhookID string) *WebhookSyncPlan {
+	actualByURL := make(map[string]rpc.Webhook, len(actual))
+	for _, webhook := range actual {
+		actualByURL[webhook.Webhook] = webhook
+	}
+
+	plan := &WebhookSyncPlan{}
+	declared := make(map[string]struct{}, len(config.Webhooks))
+	for _, definition := range config.Webhooks {
+		desired := desiredWebhook(definition, config.CallbackURL)
+		declared[desired.Webhook] = struct{}{}
+
+		existing, ok := actualByURL[desired.Webhook]
+		if !ok {
+			plan.Create = append(plan.Create, desired)
+			continue
+		}
+		if !webhookEqual(existing, desired) {
+			desired.ID = existing.ID
+			plan.Update = append(plan.Update, desired)
+		}
+	}
+
+	if config.Prune && len(config.Webhooks) > 0 {
+		for _, webhook := range actual {
+			if _, ok := declared[webhook.Webhook]; ok || (watchlistWebhookID != "" && webhook.ID == watchlistWebhookID) {
+				continue
+			}
+			plan.Delete = append(plan.Delete, webhook)
+		}
+	}
+	return plan
+}
+
+// ApplyWebhookSync 执行同步计划，遇到错误时继续执行剩余变更并汇总返回
+func ApplyWebhookSync(ctx context.Context, client *rpc.HeliusWebhookClient, plan *WebhookSyncPlan) error {
+	var failed []string
+	// 按回调URL创建，上次同步创建请求超时但实际已创建时改为修改，不会产生重复的Webhook
+	for _, webhook := range plan.Create {
+		upserted, created, err := client.UpsertWebhook(ctx, webhook)
+		if err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		if created {
+			logger.Info("已创建Webhook", zap.String("id", upserted.ID), zap.String("url", webhook.Webhook))
+		} else {
+			logger.Info("已更新Webhook", zap.String("id", upserted.ID), zap.String("url", webhook.Webhook))
+		}
+	}
+	for _, webhook := range plan.Update {
+		if _, err := client.EditWebhook(ctx, webhook.ID, webhook); err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		logger.Info("已更新Webhook", zap.String("id", webhook.ID), zap.String("url", webhook.Webhook))
+	}
+	for _, webhook := range plan.Delete {
+		if err := client.DeleteWebhook(ctx, webhook.ID); err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		logger.Info("已删除未声明的Webhook", zap.String("id", webhook.ID), zap.String("url", webhook.Webhook))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("同步Webhook部分失败: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// SyncWebhooks 按配置声明同步Helius上的Webhook
+// 参数:
+//   - ctx: 上下文
+//   - config: Webhook配置，热更新时传入新的配置
+//
+// 返回:
+//   - error: 错误信息
+func SyncWebhooks(ctx context.Context, config *configs.HeliusWebhookConfig) error {
+	client := rpc.NewHeliusWebhookClient(config)
+	plan, err := PlanWebhookSync(ctx, client, config, configs.GlobalConfig.Watchlist.WebhookID)
+	if err != nil {
+		return err
+	}
+	if plan.Empty() {
+		logger.Info("Webhook已与配置一致，无需同步", zap.Int("webhooks", len(config.Webhooks)))
+		return nil
+	}
+	logger.Info("开始同步Webhook",
+		zap.Int("create", len(plan.Create)),
+		zap.Int("update", len(plan.Update)),
+		zap.Int("delete", len(plan.Delete)))
+	return ApplyWebhookSync(ctx, client, plan)
+}
+
+// desiredWebhook 将声明转换为Webhook
+func desiredWebhook(definition configs.WebhookDefinition, callbackURL string) rpc.Webhook {
+	webhook := rpc.Webhook{
+		Webhook:          definition.URL,
+		WebhookType:      rpc.WebhookType(definition.Type),
+		AccountAddresses: definition.Addresses,
+		AuthHeader:       definition.AuthHeader,
+	}
+	if webhook.Webhook == "" {
+		webhook.Webhook = callbackURL
+	}
+	if webhook.WebhookType == "" {
+		webhook.WebhookType = rpc.EnhancedWebhook
+	}
+	for _, transactionType := range definition.TransactionTypes {
+		webhook.TransactionTypes = append(webhook.TransactionTypes, resp.TransactionType(strings.ToUpper(transactionType)))
+	}
+	if len(webhook.TransactionTypes) == 0 {
+		webhook.TransactionTypes = []resp.TransactionType{resp.TransactionTypeAny}
+	}
+	return webhook
+}
+
+// webhookEqual 判断实际Webhook是否已符合声明，地址和交易类型不区分顺序
+func webhookEqual(actual, desired rpc.Webhook) bool {
+	return actual.WebhookType == desired.WebhookType &&
+		actual.AuthHeader == desired.AuthHeader &&
+		sameSet(actual.AccountAddresses, desired.AccountAddresses) &&
+		sameSet(actual.TransactionTypes, desired.TransactionTypes)
+}
+
+// sameSet 判断两个切片包含的元素是否相同，忽略顺序和重复
+func sameSet[T comparable](a, b []T) bool {
+	setA := make(map[T]struct{}, len(a))
+	for _, v := range a {
+		setA[v] = struct{}{}
+	}
+	setB := make(map[T]struct{}, len(b))
+	for _, v := range b {
+		if _, ok := setA[v]; !ok {
+			return false
+		}
+		setB[v] = struct{}{}
+	}
+	return len(setA) == len(setB)
+}
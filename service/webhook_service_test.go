@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/rpc"
+)
+
+func TestPlanWebhookSyncPruneKeepsWatchlistWebhook(t *testing.T) {
+	config := &configs.HeliusWebhookConfig{
+		Prune:    true,
+		Webhooks: []configs.WebhookDefinition{{URL: "https://example.com/declared"}},
+	}
+	actual := []rpc.Webhook{
+		{ID: "declared", Webhook: "https://example.com/declared", WebhookType: rpc.EnhancedWebhook, TransactionTypes: desiredWebhook(config.Webhooks[0], "").TransactionTypes},
+		{ID: "watchlist", Webhook: "https://example.com/watchlist"},
+		{ID: "manual", Webhook: "https://example.com/manual"},
+	}
+	plan := planWebhookSync(actual, config, "watchlist")
+	if len(plan.Create) != 0 || len(plan.Update) != 0 {
+		t.Fatalf("声明的Webhook已一致，不应创建或更新: %+v", plan)
+	}
+	if len(plan.Delete) != 1 || plan.Delete[0].ID != "manual" {
+		t.Fatalf("prune 只应删除未声明且不是关注地址Webhook的Webhook: %+v", plan.Delete)
+	}
+}
+
+func TestPlanWebhookSyncRefusesPruneWithoutDeclarations(t *testing.T) {
+	config := &configs.HeliusWebhookConfig{Prune: true}
+	// 没有声明时不请求Helius，直接拒绝
+	if _, err := PlanWebhookSync(context.Background(), nil, config, ""); err == nil {
+		t.Fatal("没有声明任何Webhook时应拒绝prune")
+	}
+	plan := planWebhookSync([]rpc.Webhook{{ID: "manual", Webhook: "https://example.com/manual"}}, config, "")
+	if len(plan.Delete) != 0 {
+		t.Fatalf("没有声明任何Webhook时不应删除: %+v", plan.Delete)
+	}
+}
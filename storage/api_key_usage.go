@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// Enhanced API密钥每日用量的键前缀，后接 <密钥指纹>:<UTC日期起始时间>
+	// 每个密钥每天一个Hash，字段为 requests、credits、errors 和 code:<错误码>
+	APIKeyUsageKeyPrefix = "apikey:usage:"
+)
+
+// 获取密钥每日用量的键名
+func getAPIKeyUsageKey(keyID string, day int64) string {
+	return Key(APIKeyUsageKeyPrefix) + keyID + ":" + strconv.FormatInt(day, 10)
+}
+
+// IncrAPIKeyUsage 累加密钥当天的请求数、消耗的额度和错误码
+// 参数:
+//   - ctx: 上下文
+//   - keyID: 密钥指纹
+//   - day: UTC日期起始时间
+//   - credits: 消耗的额度
+//   - code: 失败请求的错误码，为空表示请求成功
+//   - retention: 保留时长，0表示不过期
+//
+// 返回:
+//   - int64: 累加后当天消耗的额度，多个进程共用同一个密钥时为所有进程的合计
+//   - error: 错误信息，Redis不可用时写入已缓存但返回 ErrRedisUnavailable
+func (r *RedisClient) IncrAPIKeyUsage(ctx context.Context, keyID string, day, credits int64, code string, retention time.Duration) (int64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	key := getAPIKeyUsageKey(keyID, day)
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, "requests", 1)
+	total := pipe.HIncrBy(ctx, key, "credits", credits)
+	if code != "" {
+		pipe.HIncrBy(ctx, key, "errors", 1)
+		pipe.HIncrBy(ctx, key, "code:"+code, 1)
+	}
+	if retention > 0 {
+		pipe.ExpireAt(ctx, key, time.Unix(day, 0).Add(24*time.Hour+retention))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("累加API密钥用量失败: %w", err)
+	}
+	// Redis不可用时写入被缓存等待重放，合计值未知，返回错误由调用方按本进程的用量判断预算
+	if r.degraded != nil && r.degraded.Degraded() {
+		return 0, fmt.Errorf("累加API密钥用量失败: %w", ErrRedisUnavailable)
+	}
+	return total.Val(), nil
+}
+
+// GetAPIKeyUsage 获取密钥最近几天的用量
+// 参数:
+//   - ctx: 上下文
+//   - keyID: 密钥指纹
+//   - day: 最近一天的UTC日期起始时间
+//   - days: 天数
+//
+// 返回:
+//   - []models.APIKeyDailyUsage: 从最近一天开始按日期降序的用量，没有记录的日期各项为0
+//   - error: 错误信息
+func (r *RedisClient) GetAPIKeyUsage(ctx context.Context, keyID string, day int64, days int) ([]models.APIKeyDailyUsage, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	usages := make([]models.APIKeyDailyUsage, 0, days)
+	for i := range days {
+		usage := models.APIKeyDailyUsage{Day: day - int64(i)*86400}
+		fields, err := r.client.HGetAll(ctx, getAPIKeyUsageKey(keyID, usage.Day)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("获取API密钥用量失败: %w", err)
+		}
+		for field, value := range fields {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			switch field {
+			case "requests":
+				usage.Requests = n
+			case "credits":
+				usage.Credits = n
+			case "errors":
+				usage.Errors = n
+			default:
+				if code, ok := strings.CutPrefix(field, "code:"); ok {
+					if usage.Codes == nil {
+						usage.Codes = make(map[string]int64)
+					}
+					usage.Codes[code] = n
+				}
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestIncrAPIKeyUsageWhileDegraded Redis不可用时写入被缓存，返回错误而不是为0的合计值，调用方按本进程的用量判断预算
+func TestIncrAPIKeyUsageWhileDegraded(t *testing.T) {
+	client, _ := newTestRedis(t)
+	ctx := context.Background()
+
+	if total, err := client.IncrAPIKeyUsage(ctx, "key", 0, 40, "", 0); err != nil || total != 40 {
+		t.Fatalf("累加后的用量 %d(%v)，期望40", total, err)
+	}
+	// 直接标记为不可用，不启动恢复探测
+	client.degraded.mu.Lock()
+	client.degraded.status.Degraded = true
+	client.degraded.mu.Unlock()
+
+	if total, err := client.IncrAPIKeyUsage(ctx, "key", 0, 40, "", 0); !errors.Is(err, ErrRedisUnavailable) {
+		t.Fatalf("Redis不可用时应返回 ErrRedisUnavailable，实际 %d(%v)", total, err)
+	}
+	if client.degraded.Status().Buffered == 0 {
+		t.Fatal("Redis不可用时的写入应缓存等待重放")
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 区块哈希索引键前缀，值为 models.BlockMeta 的JSON
+	BlockhashKeyPrefix = "blockhash:"
+)
+
+// getBlockhashKey 获取区块哈希索引的键
+func getBlockhashKey(blockhash string) string {
+	return Key(BlockhashKeyPrefix) + blockhash
+}
+
+// StoreBlockMeta 按区块哈希保存区块元数据
+// 参数:
+//   - ctx: 上下文
+//   - meta: 区块元数据
+//   - expiration: 过期时间，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreBlockMeta(ctx context.Context, meta models.BlockMeta, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if meta.Blockhash == "" {
+		return nil
+	}
+	value, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("序列化区块元数据失败: %w", err)
+	}
+	if err := r.client.Set(ctx, getBlockhashKey(meta.Blockhash), value, expiration).Err(); err != nil {
+		return fmt.Errorf("保存区块哈希索引失败: %w", err)
+	}
+	return nil
+}
+
+// GetBlockMeta 按区块哈希查询区块元数据
+// 参数:
+//   - ctx: 上下文
+//   - blockhash: 区块哈希
+//
+// 返回:
+//   - *models.BlockMeta: 区块元数据，没有记录时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetBlockMeta(ctx context.Context, blockhash string) (*models.BlockMeta, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := r.client.Get(ctx, getBlockhashKey(blockhash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询区块哈希索引失败: %w", err)
+	}
+	var meta models.BlockMeta
+	if err := json.Unmarshal(value, &meta); err != nil {
+		return nil, fmt.Errorf("解析区块元数据失败: %w", err)
+	}
+	return &meta, nil
+}
+
+// DeleteBlockMeta 删除区块哈希索引，用于没有被最终确认的区块
+// 参数:
+//   - ctx: 上下文
+//   - blockhash: 区块哈希
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) DeleteBlockMeta(ctx context.Context, blockhash string) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if blockhash == "" {
+		return nil
+	}
+	if err := r.client.Del(ctx, getBlockhashKey(blockhash)).Err(); err != nil {
+		return fmt.Errorf("删除区块哈希索引失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 区块处理状态的键前缀，后接槽位，值为哈希
+	BlockStateKeyPrefix = "block:state:"
+	// 各状态下区块的有序集合键前缀，后接状态，score为进入该状态的时间
+	BlockStateIndexKeyPrefix = "block:states:"
+)
+
+// ErrBlockStateNotFound 找不到区块处理状态
+var ErrBlockStateNotFound = errors.New("找不到区块处理状态")
+
+// 获取区块处理状态的键名
+func getBlockStateKey(slot uint64) string {
+	return Key(BlockStateKeyPrefix) + strconv.FormatUint(slot, 10)
+}
+
+// 获取状态索引的键名
+func getBlockStateIndexKey(state models.BlockState) string {
+	return Key(BlockStateIndexKeyPrefix) + string(state)
+}
+
+// SetBlockState 将区块切换到指定状态，状态索引和状态记录在同一个事务中更新
+// 参数:
+//   - ctx: 上下文
+//   - slot: 区块槽位
+//   - state: 新状态
+//   - reason: 失败原因，仅FAILED和ORPHANED状态使用
+//   - expiration: 状态记录的过期时间，如果为0则不设置过期时间
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) SetBlockState(ctx context.Context, slot uint64, state models.BlockState, reason string, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	now := clock.Now().Unix()
+	key := getBlockStateKey(slot)
+	member := strconv.FormatUint(slot, 10)
+
+	pipe := r.client.TxPipeline()
+	for _, other := range models.BlockStates {
+		if other != state {
+			pipe.ZRem(ctx, getBlockStateIndexKey(other), member)
+		}
+	}
+	pipe.ZAdd(ctx, getBlockStateIndexKey(state), redis.Z{Score: float64(now), Member: member})
+	fields := []interface{}{"state", string(state), "updated_at", now}
+	switch state {
+	case models.BlockQueued:
+		fields = append(fields, "queued_at", now)
+	case models.BlockFetching:
+		fields = append(fields, "fetching_at", now)
+		pipe.HIncrBy(ctx, key, "attempts", 1)
+	case models.BlockParsing:
+		fields = append(fields, "parsing_at", now)
+	case models.BlockDone, models.BlockFailed:
+		fields = append(fields, "finished_at", now)
+	}
+	if reason != "" {
+		fields = append(fields, "error", reason)
+	} else {
+		pipe.HDel(ctx, key, "error")
+	}
+	pipe.HSet(ctx, key, fields...)
+	if expiration > 0 {
+		pipe.Expire(ctx, key, expiration)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("更新区块处理状态失败: %w", err)
+	}
+	return nil
+}
+
+// GetBlockState 读取区块处理状态
+// 参数:
+//   - ctx: 上下文
+//   - slot: 区块槽位
+//
+// 返回:
+//   - *models.BlockStatus: 区块处理状态
+//   - error: 错误信息
+func (r *RedisClient) GetBlockState(ctx context.Context, slot uint64) (*models.BlockStatus, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.HGetAll(ctx, getBlockStateKey(slot)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取区块处理状态失败: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, ErrBlockStateNotFound
+	}
+	parseInt := func(field string) int64 {
+		value, _ := strconv.ParseInt(values[field], 10, 64)
+		return value
+	}
+	return &models.BlockStatus{
+		Slot:       slot,
+		State:      models.BlockState(values["state"]),
+		Attempts:   int(parseInt("attempts")),
+		Error:      values["error"],
+		QueuedAt:   parseInt("queued_at"),
+		FetchingAt: parseInt("fetching_at"),
+		ParsingAt:  parseInt("parsing_at"),
+		FinishedAt: parseInt("finished_at"),
+		UpdatedAt:  parseInt("updated_at"),
+	}, nil
+}
+
+// CountBlockStates 统计各状态下的区块数量
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - map[models.BlockState]int64: 各状态下的区块数量
+//   - error: 错误信息
+func (r *RedisClient) CountBlockStates(ctx context.Context) (map[models.BlockState]int64, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	pipe := r.client.Pipeline()
+	cmds := make(map[models.BlockState]*redis.IntCmd, len(models.BlockStates))
+	for _, state := range models.BlockStates {
+		cmds[state] = pipe.ZCard(ctx, getBlockStateIndexKey(state))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("统计区块处理状态失败: %w", err)
+	}
+	counts := make(map[models.BlockState]int64, len(cmds))
+	for state, cmd := range cmds {
+		counts[state] = cmd.Val()
+	}
+	return counts, nil
+}
+
+// GetBlocksInState 获取在指定时间之前进入某状态且仍处于该状态的区块，按进入时间升序
+// 参数:
+//   - ctx: 上下文
+//   - state: 状态
+//   - before: Unix时间戳
+//   - limit: 最多返回的数量
+//
+// 返回:
+//   - []uint64: 区块槽位
+//   - error: 错误信息
+func (r *RedisClient) GetBlocksInState(ctx context.Context, state models.BlockState, before int64, limit int64) ([]uint64, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	members, err := r.client.ZRangeByScore(ctx, getBlockStateIndexKey(state), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before, 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询区块处理状态失败: %w", err)
+	}
+	slots := make([]uint64, 0, len(members))
+	for _, member := range members {
+		if slot, err := strconv.ParseUint(member, 10, 64); err == nil {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// TrimBlockStates 从状态索引中移除在指定时间之前进入该状态的区块
+// 参数:
+//   - ctx: 上下文
+//   - state: 状态
+//   - before: Unix时间戳
+//
+// 返回:
+//   - int64: 移除的数量
+//   - error: 错误信息
+func (r *RedisClient) TrimBlockStates(ctx context.Context, state models.BlockState, before int64) (int64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	removed, err := r.client.ZRemRangeByScore(ctx, getBlockStateIndexKey(state), "-inf", strconv.FormatInt(before, 10)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("清理区块处理状态失败: %w", err)
+	}
+	return removed, nil
+}
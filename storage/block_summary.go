@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// BlockSummaryKey 区块交易汇总的ZSet键名，成员为 models.BlockSummary 的JSON，分数为槽位
+	BlockSummaryKey = "block_summaries"
+)
+
+// StoreBlockSummary 保存区块交易汇总，同一槽位重新处理时覆盖之前的汇总
+// 参数:
+//   - ctx: 上下文
+//   - summary: 区块交易汇总
+//   - maxEntries: 最多保留的区块数，超过时删除槽位最小的汇总，0表示不限制
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreBlockSummary(ctx context.Context, summary models.BlockSummary, maxEntries int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("序列化区块汇总失败: %w", err)
+	}
+	key := Key(BlockSummaryKey)
+	slot := strconv.FormatUint(summary.Slot, 10)
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, slot, slot)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(summary.Slot), Member: value})
+	if maxEntries > 0 {
+		pipe.ZRemRangeByRank(ctx, key, 0, -maxEntries-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("保存区块汇总失败: %w", err)
+	}
+	return nil
+}
+
+// GetBlockSummaries 按槽位范围查询区块交易汇总
+// 参数:
+//   - ctx: 上下文
+//   - fromSlot: 起始槽位(包含)，0表示不限制
+//   - toSlot: 结束槽位(包含)，0表示不限制
+//   - limit: 最多返回的区块数，从结束槽位开始向前取
+//
+// 返回:
+//   - []models.BlockSummary: 按槽位降序排列的汇总
+//   - error: 错误信息
+func (r *RedisClient) GetBlockSummaries(ctx context.Context, fromSlot, toSlot uint64, limit int64) ([]models.BlockSummary, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	by := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: limit}
+	if fromSlot > 0 {
+		by.Min = strconv.FormatUint(fromSlot, 10)
+	}
+	if toSlot > 0 {
+		by.Max = strconv.FormatUint(toSlot, 10)
+	}
+	values, err := r.client.ZRevRangeByScore(ctx, Key(BlockSummaryKey), by).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询区块汇总失败: %w", err)
+	}
+	summaries := make([]models.BlockSummary, 0, len(values))
+	for _, value := range values {
+		var summary models.BlockSummary
+		if err := json.Unmarshal([]byte(value), &summary); err != nil {
+			return nil, fmt.Errorf("解析区块汇总失败: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// GetBlockSummary 查询单个区块的交易汇总
+// 返回:
+//   - *models.BlockSummary: 区块交易汇总，没有记录时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetBlockSummary(ctx context.Context, slot uint64) (*models.BlockSummary, error) {
+	if slot == 0 {
+		return nil, nil
+	}
+	summaries, err := r.GetBlockSummaries(ctx, slot, slot, 1)
+	if err != nil || len(summaries) == 0 {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
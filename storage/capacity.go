@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 容量规划指标快照的键名，有序集合的分数为快照时间
+	CapacitySnapshotsKey = "capacity:snapshots"
+)
+
+// StoreCapacitySnapshot 追加指标快照，并删除超过保留时长的旧快照
+// 参数:
+//   - ctx: 上下文
+//   - snapshot: 快照
+//   - retention: 保留时长，0表示不删除
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreCapacitySnapshot(ctx context.Context, snapshot models.CapacitySnapshot, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("序列化指标快照失败: %w", err)
+	}
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, Key(CapacitySnapshotsKey), redis.Z{Score: float64(snapshot.Timestamp), Member: value})
+	if retention > 0 {
+		minScore := snapshot.Timestamp - int64(retention.Seconds())
+		pipe.ZRemRangeByScore(ctx, Key(CapacitySnapshotsKey), "-inf", "("+strconv.FormatInt(minScore, 10))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("存储指标快照失败: %w", err)
+	}
+	return nil
+}
+
+// GetCapacitySnapshots 获取时间范围内的指标快照
+// 参数:
+//   - ctx: 上下文
+//   - since: 起始时间(Unix时间戳，包含)
+//   - until: 结束时间(Unix时间戳，包含)
+//
+// 返回:
+//   - []models.CapacitySnapshot: 按时间升序的快照
+//   - error: 错误信息
+func (r *RedisClient) GetCapacitySnapshots(ctx context.Context, since, until int64) ([]models.CapacitySnapshot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.ZRangeByScore(ctx, Key(CapacitySnapshotsKey), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: strconv.FormatInt(until, 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取指标快照失败: %w", err)
+	}
+	snapshots := make([]models.CapacitySnapshot, 0, len(values))
+	for _, value := range values {
+		var snapshot models.CapacitySnapshot
+		if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// GetUsedMemory 读取Redis实例已用内存
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - int64: 已用内存(字节)
+//   - error: 错误信息
+func (r *RedisClient) GetUsedMemory(ctx context.Context) (int64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	info, err := r.client.Info(ctx, "memory").Result()
+	if err != nil {
+		return 0, fmt.Errorf("读取Redis内存信息失败: %w", err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "used_memory:"); ok {
+			return strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return 0, errors.New("Redis内存信息中没有 used_memory")
+}
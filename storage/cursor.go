@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 区块游标的键名，值为已处理完成的最大槽位
+	SlotCursorKey = "cursor:slot"
+)
+
+// advanceSlotCursorScript 仅在新槽位大于当前值时更新游标，返回更新后的游标
+// 多个实例或协程并发推进时游标不会回退
+var advanceSlotCursorScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local slot = tonumber(ARGV[1])
+if slot > current then
+	redis.call('SET', KEYS[1], ARGV[1])
+	return ARGV[1]
+end
+return tostring(current)
+`)
+
+// AdvanceSlotCursor 原子地将区块游标推进到指定槽位，槽位不大于当前游标时保持不变
+// 参数:
+//   - ctx: 上下文
+//   - slot: 已处理完成的槽位
+//
+// 返回:
+//   - uint64: 更新后的游标
+//   - error: 错误信息
+func (r *RedisClient) AdvanceSlotCursor(ctx context.Context, slot uint64) (uint64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	result, err := advanceSlotCursorScript.Run(ctx, r.client, []string{Key(SlotCursorKey)}, strconv.FormatUint(slot, 10)).Text()
+	if err != nil {
+		return 0, fmt.Errorf("更新区块游标失败: %w", err)
+	}
+	cursor, err := strconv.ParseUint(result, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析区块游标失败: %w", err)
+	}
+	return cursor, nil
+}
+
+// GetSlotCursor 读取区块游标
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - uint64: 已处理完成的最大槽位，未保存过游标时为0
+//   - error: 错误信息
+func (r *RedisClient) GetSlotCursor(ctx context.Context) (uint64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	cursor, err := r.client.Get(ctx, Key(SlotCursorKey)).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("读取区块游标失败: %w", err)
+	}
+	return cursor, nil
+}
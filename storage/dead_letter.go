@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 死信队列键前缀，后接队列标识(block、transaction)
+	DeadLetterKeyPrefix = "dlq:"
+)
+
+// DeadLetterItem 表示被丢弃到死信队列中的元素
+type DeadLetterItem struct {
+	Queue       string          `json:"queue"`        // 来源队列标识
+	Priority    int64           `json:"priority"`     // 原优先级(区块高度)
+	Value       json.RawMessage `json:"value"`        // 原队列元素
+	Reason      string          `json:"reason"`       // 丢弃原因
+	EnqueuedAt  int64           `json:"enqueued_at"`  // 入队时间(Unix时间戳)
+	DiscardedAt int64           `json:"discarded_at"` // 丢弃时间(Unix时间戳)
+}
+
+// 获取死信队列的键名
+func getDeadLetterKey(queue string) string {
+	return Key(DeadLetterKeyPrefix) + queue
+}
+
+// PushDeadLetter 将元素写入死信队列
+// 参数:
+//   - ctx: 上下文
+//   - item: 死信元素
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) PushDeadLetter(ctx context.Context, item DeadLetterItem) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	itemJSON, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("序列化死信元素失败: %w", err)
+	}
+	if err := r.client.RPush(ctx, getDeadLetterKey(item.Queue), itemJSON).Err(); err != nil {
+		return fmt.Errorf("写入死信队列失败: %w", err)
+	}
+	return nil
+}
+
+// PopDeadLetters 从死信队列头部取出最多count个元素，用于回补
+// 参数:
+//   - ctx: 上下文
+//   - queue: 队列标识
+//   - count: 最大数量
+//
+// 返回:
+//   - []DeadLetterItem: 死信元素列表
+//   - error: 错误信息
+func (r *RedisClient) PopDeadLetters(ctx context.Context, queue string, count int) ([]DeadLetterItem, error) {
+	if count <= 0 {
+		count = 1
+	}
+	values, err := r.client.LPopCount(ctx, getDeadLetterKey(queue), count).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("读取死信队列失败: %w", err)
+	}
+
+	items := make([]DeadLetterItem, 0, len(values))
+	for _, value := range values {
+		var item DeadLetterItem
+		if err := json.Unmarshal([]byte(value), &item); err != nil {
+			return items, fmt.Errorf("解析死信元素失败: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetDeadLetterLength 获取死信队列长度
+func (r *RedisClient) GetDeadLetterLength(ctx context.Context, queue string) (int64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	length, err := r.client.LLen(ctx, getDeadLetterKey(queue)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("获取死信队列长度失败: %w", err)
+	}
+	return length, nil
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// Redis不可用时的处理策略
+const (
+	DegradedPolicyBuffer = "buffer" // 写入缓存在内存中(有上限)，恢复后按顺序重放
+	DegradedPolicyPause  = "pause"  // 同样缓存写入，并暂停从队列中取出区块和交易，停止新的写入
+	DegradedPolicyCrash  = "crash"  // 立即退出进程，由进程管理器重启
+)
+
+// ErrRedisUnavailable Redis处于不可用状态，读取类命令直接返回该错误，不再等待超时
+var ErrRedisUnavailable = errors.New("Redis 暂时不可用")
+
+// 可以缓存重放的写入命令，这些命令的返回值通常不被使用
+// 取出数据的命令(LPOP、ZPOPMIN等)、脚本和条件写入(SETNX)不在其中，不可用时直接返回错误
+var bufferableCommands = map[string]bool{
+	"set": true, "setex": true, "psetex": true, "mset": true, "del": true, "unlink": true,
+	"expire": true, "pexpire": true, "expireat": true,
+	"hset": true, "hsetnx": true, "hmset": true, "hdel": true, "hincrby": true, "hincrbyfloat": true,
+	"zadd": true, "zrem": true, "zincrby": true, "zremrangebyscore": true, "zremrangebyrank": true,
+	"sadd": true, "srem": true, "lpush": true, "rpush": true, "ltrim": true,
+	"incr": true, "incrby": true, "incrbyfloat": true, "decr": true, "decrby": true,
+	"pfadd": true, "xadd": true, "publish": true,
+}
+
+// bypassKey 上下文标记，探测和重放时绕过不可用检查
+type bypassKey struct{}
+
+// DegradedStatus 单个Redis客户端的可用状态
+type DegradedStatus struct {
+	Degraded  bool      `json:"degraded"`         // 是否处于不可用状态
+	Since     time.Time `json:"since,omitzero"`   // 进入不可用状态的时间
+	LastError string    `json:"last_error"`       // 最近一次连接错误
+	Buffered  int       `json:"buffered"`         // 等待重放的写入数
+	Dropped   int64     `json:"dropped"`          // 缓存已满被丢弃的写入数(累计)
+	Replayed  int64     `json:"replayed"`         // 恢复后已重放的写入数(累计)
+	Outages   int64     `json:"outages"`          // 不可用次数(累计)
+	Policy    string    `json:"policy,omitempty"` // 处理策略
+}
+
+// degradedState Redis客户端的可用状态与待重放的写入，以go-redis钩子的形式拦截命令
+type degradedState struct {
+	mu            sync.Mutex
+	name          string
+	client        *redis.Client
+	policy        string
+	bufferSize    int
+	probeInterval time.Duration
+	status        DegradedStatus
+	buffer        [][]interface{}
+	probing       bool
+	log           *zap.Logger
+}
+
+// newDegradedState 按配置创建可用状态并注册到客户端
+func newDegradedState(name string, client *redis.Client, config configs.RedisDegradedConfig) *degradedState {
+	state := &degradedState{
+		name:          name,
+		client:        client,
+		policy:        config.Policy,
+		bufferSize:    config.BufferSize,
+		probeInterval: config.ProbeInterval,
+		log:           logger.Named("storage.redis").With(zap.String("workload", name)),
+	}
+	if state.policy == "" {
+		state.policy = DegradedPolicyBuffer
+	}
+	if state.bufferSize <= 0 {
+		state.bufferSize = 100000
+	}
+	if state.probeInterval <= 0 {
+		state.probeInterval = 5 * time.Second
+	}
+	state.status.Policy = state.policy
+	client.AddHook(state)
+	return state
+}
+
+// Status 返回当前可用状态
+func (s *degradedState) Status() DegradedStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Buffered = len(s.buffer)
+	return status
+}
+
+// Degraded 是否处于不可用状态
+func (s *degradedState) Degraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status.Degraded
+}
+
+// DialHook 不拦截建立连接
+func (s *degradedState) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 不可用时缓存写入命令、读取命令直接返回错误；执行中出现连接错误时进入不可用状态
+func (s *degradedState) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if ctx.Value(bypassKey{}) != nil {
+			return next(ctx, cmd)
+		}
+		if s.Degraded() {
+			return s.handleUnavailable(cmd)
+		}
+		err := next(ctx, cmd)
+		if !isConnectionError(err) {
+			return err
+		}
+		s.markDegraded(err)
+		return s.handleUnavailable(cmd)
+	}
+}
+
+// ProcessPipelineHook 流水线中的命令全部可缓存时整体缓存，否则整体返回错误
+func (s *degradedState) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if ctx.Value(bypassKey{}) != nil {
+			return next(ctx, cmds)
+		}
+		if s.Degraded() {
+			return s.handlePipelineUnavailable(cmds)
+		}
+		err := next(ctx, cmds)
+		if !isConnectionError(err) {
+			return err
+		}
+		s.markDegraded(err)
+		return s.handlePipelineUnavailable(cmds)
+	}
+}
+
+// handleUnavailable 按策略处理不可用期间的单条命令
+func (s *degradedState) handleUnavailable(cmd redis.Cmder) error {
+	if !bufferableCommands[cmd.Name()] {
+		cmd.SetErr(ErrRedisUnavailable)
+		return ErrRedisUnavailable
+	}
+	s.push(cmd.Args())
+	cmd.SetErr(nil)
+	return nil
+}
+
+// handlePipelineUnavailable 按策略处理不可用期间的流水线，MULTI/EXEC 包装命令不参与判断也不缓存
+func (s *degradedState) handlePipelineUnavailable(cmds []redis.Cmder) error {
+	writes := make([][]interface{}, 0, len(cmds))
+	for _, cmd := range cmds {
+		switch cmd.Name() {
+		case "multi", "exec":
+			continue
+		}
+		if !bufferableCommands[cmd.Name()] {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrRedisUnavailable)
+			}
+			return ErrRedisUnavailable
+		}
+		writes = append(writes, cmd.Args())
+	}
+	for _, args := range writes {
+		s.push(args)
+	}
+	for _, cmd := range cmds {
+		cmd.SetErr(nil)
+	}
+	return nil
+}
+
+// push 缓存一条写入，缓存已满时丢弃最早的写入
+func (s *degradedState) push(args []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buffer) >= s.bufferSize {
+		s.buffer = s.buffer[1:]
+		s.status.Dropped++
+		if s.status.Dropped%1000 == 1 {
+			s.log.Warn("Redis不可用期间的写入缓存已满，丢弃最早的写入",
+				zap.Int("buffer_size", s.bufferSize),
+				zap.Int64("dropped", s.status.Dropped))
+		}
+	}
+	s.buffer = append(s.buffer, args)
+}
+
+// markDegraded 进入不可用状态并启动恢复探测，crash 策略直接退出进程
+func (s *degradedState) markDegraded(err error) {
+	s.mu.Lock()
+	s.status.LastError = err.Error()
+	if s.status.Degraded {
+		s.mu.Unlock()
+		return
+	}
+	if s.policy == DegradedPolicyCrash {
+		s.mu.Unlock()
+		s.log.Fatal("Redis不可用，按 crash 策略退出", zap.Error(err))
+		return
+	}
+	s.status.Degraded = true
+	s.status.Since = clock.Now()
+	s.status.Outages++
+	startProbe := !s.probing
+	s.probing = true
+	s.mu.Unlock()
+
+	s.log.Error("Redis不可用，进入降级模式", zap.String("policy", s.policy), zap.Error(err))
+	if startProbe {
+		supervisor.Go(context.Background(), "storage.degraded_probe", func(context.Context) { s.probe() })
+	}
+}
+
+// probe 定期探测Redis，恢复后按顺序重放缓存的写入，全部重放完成后退出降级模式
+func (s *degradedState) probe() {
+	ctx := context.WithValue(context.Background(), bypassKey{}, true)
+	ticker := time.NewTicker(s.probeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		pingCtx, cancel := context.WithTimeout(ctx, s.probeInterval)
+		err := s.client.Ping(pingCtx).Err()
+		cancel()
+		if errors.Is(err, redis.ErrClosed) {
+			return
+		}
+		if err != nil {
+			s.mu.Lock()
+			s.status.LastError = err.Error()
+			s.mu.Unlock()
+			continue
+		}
+		if s.replay(ctx) {
+			return
+		}
+	}
+}
+
+// replay 重放缓存的写入，重放期间新的写入继续进入缓存，缓存清空后退出降级模式
+// 重放中再次出现连接错误时保留剩余写入，返回false继续探测
+func (s *degradedState) replay(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		batch := s.buffer
+		s.buffer = nil
+		if len(batch) == 0 {
+			duration := clock.Since(s.status.Since)
+			s.status.Degraded = false
+			s.status.Since = time.Time{}
+			s.probing = false
+			replayed := s.status.Replayed
+			s.mu.Unlock()
+			s.log.Info("Redis已恢复，退出降级模式",
+				zap.Duration("duration", duration),
+				zap.Int64("replayed", replayed))
+			return true
+		}
+		s.mu.Unlock()
+
+		for i, args := range batch {
+			err := s.client.Do(ctx, args...).Err()
+			if isConnectionError(err) {
+				s.mu.Lock()
+				s.buffer = append(batch[i:], s.buffer...)
+				s.status.LastError = err.Error()
+				s.mu.Unlock()
+				return false
+			}
+			if err != nil && !errors.Is(err, redis.Nil) {
+				s.log.Warn("重放写入失败", zap.Any("command", args[0]), zap.Error(err))
+			}
+			s.mu.Lock()
+			s.status.Replayed++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// isConnectionError 判断是否为连接类错误(网络错误、连接断开、实例正在加载数据)
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	message := err.Error()
+	return strings.HasPrefix(message, "LOADING") || strings.Contains(message, "connection refused")
+}
+
+// RedisStatus 返回默认客户端及各负载客户端的可用状态，键与 RedisClients 相同
+func RedisStatus() map[string]DegradedStatus {
+	statuses := make(map[string]DegradedStatus)
+	for name, client := range RedisClients() {
+		if client.degraded != nil {
+			statuses[name] = client.degraded.Status()
+		}
+	}
+	return statuses
+}
+
+// RedisDegraded 是否有Redis客户端处于不可用状态
+func RedisDegraded() bool {
+	for _, client := range RedisClients() {
+		if client.degraded != nil && client.degraded.Degraded() {
+			return true
+		}
+	}
+	return false
+}
+
+// IntakePaused 按 pause 策略，Redis不可用期间暂停从队列中取出区块和交易
+func IntakePaused() bool {
+	for _, client := range RedisClients() {
+		if client.degraded != nil && client.degraded.policy == DegradedPolicyPause && client.degraded.Degraded() {
+			return true
+		}
+	}
+	return false
+}
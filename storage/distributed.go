@@ -0,0 +1,476 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+// 分布式协调的键名使用相同的哈希标签 {cluster}，Redis Cluster 中位于同一个哈希槽，脚本可以同时访问
+const (
+	// 实例心跳有序集合的键名，成员为实例ID，分数为最近一次心跳的Unix毫秒时间戳
+	ClusterInstancesKey = "{cluster}:instances"
+	// 区块租约的前缀，值为持有租约的实例ID，处理完成后为完成标记
+	SlotLeaseKeyPrefix = "{cluster}:lease:"
+	// 实例持有的区块集合的前缀，实例失效时按此集合重新分配区块
+	InstanceClaimsKeyPrefix = "{cluster}:claims:"
+	// WebSocket摄取领导者的键名，值为领导者的实例ID
+	IngestLeaderKey = "{cluster}:leader"
+
+	// 区块处理完成后租约的值，实例ID不能与之相同
+	slotLeaseDone = "done"
+
+	// 租约的持有实例在读取和执行脚本之间变化时的最大重试次数
+	slotLeaseRetries = 3
+	// 清除完成标记时每个管道的槽位数
+	clearSlotDoneBatch = 1000
+)
+
+// slotLeaseKey 返回区块租约的键名
+func slotLeaseKey(slot uint64) string {
+	return Key(SlotLeaseKeyPrefix) + strconv.FormatUint(slot, 10)
+}
+
+// instanceClaimsKey 返回实例持有的区块集合的键名
+func instanceClaimsKey(instance string) string {
+	return Key(InstanceClaimsKeyPrefix) + instance
+}
+
+// acquireSlotLeaseScript 租约不存在时由实例持有并记录到实例的区块集合，返回1；已被持有或已完成时返回0
+var acquireSlotLeaseScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	redis.call('SADD', KEYS[2], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// completeSlotLeaseScript 将租约改为完成标记，并从持有实例的区块集合中移除
+// 持有实例由调用方预先读取(ARGV[1]，没有持有实例时为空)，KEYS[2] 为它的区块集合；持有实例已变化时返回-1，由调用方重试
+// 持有租约的实例不一定是完成处理的实例(如交易由其他消费者认领)
+var completeSlotLeaseScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1]) or ''
+if owner ~= ARGV[1] then
+	return -1
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+if KEYS[2] then
+	redis.call('SREM', KEYS[2], ARGV[4])
+end
+return 1
+`)
+
+// releaseSlotLeaseScript 删除租约和完成标记，并从持有实例的区块集合中移除，之后任意实例都可以重新获取
+// 参数约定与 completeSlotLeaseScript 相同
+var releaseSlotLeaseScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1]) or ''
+if owner ~= ARGV[1] then
+	return -1
+end
+redis.call('DEL', KEYS[1])
+if KEYS[2] then
+	redis.call('SREM', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// clearSlotDoneScript 区块已处理完成时删除完成标记，被实例持有的租约保持不变
+var clearSlotDoneScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// heartbeatScript 记录实例心跳，并为实例仍然持有的租约续期，已不属于该实例的区块从集合中移除
+// KEYS[3:] 为调用方预先读取的区块集合中各区块的租约，与 ARGV[4:] 的区块一一对应；之后新获取的租约在下一次心跳时续期
+// 返回续期的租约数
+var heartbeatScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+local renewed = 0
+for i = 3, #KEYS do
+	if redis.call('GET', KEYS[i]) == ARGV[1] then
+		redis.call('PEXPIRE', KEYS[i], ARGV[3])
+		renewed = renewed + 1
+	else
+		redis.call('SREM', KEYS[2], ARGV[i + 1])
+	end
+end
+return renewed
+`)
+
+// reclaimInstanceScript 实例最近一次心跳早于 ARGV[2] 时，删除它持有的租约和区块集合并移出实例列表，返回1
+// KEYS[3:] 为调用方预先读取的区块集合中各区块的租约；区块集合已变化时返回-1，由调用方重试
+// 多个实例同时回收时只有一个返回1，其他返回0
+var reclaimInstanceScript = redis.NewScript(`
+local heartbeat = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not heartbeat or tonumber(heartbeat) >= tonumber(ARGV[2]) then
+	return 0
+end
+if redis.call('SCARD', KEYS[2]) ~= #KEYS - 2 then
+	return -1
+end
+for i = 3, #KEYS do
+	if redis.call('GET', KEYS[i]) == ARGV[1] then
+		redis.call('DEL', KEYS[i])
+	end
+end
+redis.call('DEL', KEYS[2])
+redis.call('ZREM', KEYS[1], ARGV[1])
+return 1
+`)
+
+// acquireLeaderScript 没有领导者时成为领导者，已是领导者时续期，返回1；由其他实例持有时返回0
+var acquireLeaderScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// resignLeaderScript 仍是领导者时删除领导者键
+var resignLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireSlotLease 为实例获取区块租约，同一区块同一时间只有一个实例持有
+// 参数:
+//   - ctx: 上下文
+//   - slot: 区块槽位
+//   - instance: 实例ID
+//   - ttl: 租约有效期，持有期间通过 HeartbeatInstance 续期
+//
+// 返回:
+//   - bool: 是否获取成功，租约被其他实例持有或区块已处理完成时为false
+//   - error: 错误信息
+func (r *RedisClient) AcquireSlotLease(ctx context.Context, slot uint64, instance string, ttl time.Duration) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	acquired, err := acquireSlotLeaseScript.Run(ctx, r.client,
+		[]string{slotLeaseKey(slot), instanceClaimsKey(instance)},
+		instance, ttl.Milliseconds(), slot).Int()
+	if err != nil {
+		return false, fmt.Errorf("获取区块租约失败: %w", err)
+	}
+	return acquired == 1, nil
+}
+
+// CompleteSlotLease 区块处理完成，租约改为完成标记，保留期间任何实例都不能再获取该区块
+// 参数:
+//   - ctx: 上下文
+//   - slot: 区块槽位
+//   - ttl: 完成标记的保留时长
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) CompleteSlotLease(ctx context.Context, slot uint64, ttl time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	err := r.updateSlotLease(ctx, completeSlotLeaseScript, slot, slotLeaseDone, ttl.Milliseconds(), slot)
+	if err != nil {
+		return fmt.Errorf("完成区块租约失败: %w", err)
+	}
+	return nil
+}
+
+// ReleaseSlotLease 释放区块租约，用于处理失败等需要重新处理的区块
+// 参数:
+//   - ctx: 上下文
+//   - slot: 区块槽位
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) ReleaseSlotLease(ctx context.Context, slot uint64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	err := r.updateSlotLease(ctx, releaseSlotLeaseScript, slot, slot)
+	if err != nil {
+		return fmt.Errorf("释放区块租约失败: %w", err)
+	}
+	return nil
+}
+
+// updateSlotLease 读取租约的持有实例，连同它的区块集合作为 KEYS 执行脚本，持有实例在两次调用之间变化时重试
+func (r *RedisClient) updateSlotLease(ctx context.Context, script *redis.Script, slot uint64, args ...interface{}) error {
+	lease := slotLeaseKey(slot)
+	for range slotLeaseRetries {
+		owner, err := r.client.Get(ctx, lease).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		keys := []string{lease}
+		if owner != "" && owner != slotLeaseDone {
+			keys = append(keys, instanceClaimsKey(owner))
+		}
+		result, err := script.Run(ctx, r.client, keys, append([]interface{}{owner}, args...)...).Int()
+		if err != nil {
+			return err
+		}
+		if result == 1 {
+			return nil
+		}
+	}
+	return errors.New("租约的持有实例频繁变化")
+}
+
+// ClearSlotDone 删除区块的完成标记，用于显式重新入队(如回补、停止排空)的区块，之后任意实例都可以重新获取
+// 被实例持有、尚未处理完成的租约保持不变
+// 参数:
+//   - ctx: 上下文
+//   - from: 起始槽位(包含)
+//   - to: 结束槽位(包含)
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) ClearSlotDone(ctx context.Context, from, to uint64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := clearSlotDoneScript.Load(ctx, r.client).Err(); err != nil {
+		return fmt.Errorf("清除区块完成标记失败: %w", err)
+	}
+	for start := from; start <= to; start += clearSlotDoneBatch {
+		end := min(to, start+clearSlotDoneBatch-1)
+		pipe := r.client.Pipeline()
+		for slot := start; slot <= end; slot++ {
+			clearSlotDoneScript.EvalSha(ctx, pipe, []string{slotLeaseKey(slot)}, slotLeaseDone)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("清除区块完成标记失败: %w", err)
+		}
+		if end == to {
+			break
+		}
+	}
+	return nil
+}
+
+// HeartbeatInstance 记录实例心跳并为实例持有的租约续期
+// 参数:
+//   - ctx: 上下文
+//   - instance: 实例ID
+//   - now: 心跳时间
+//   - ttl: 租约续期后的有效期
+//
+// 返回:
+//   - int: 续期的租约数，即实例正在处理的区块数
+//   - error: 错误信息
+func (r *RedisClient) HeartbeatInstance(ctx context.Context, instance string, now time.Time, ttl time.Duration) (int, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	claims := instanceClaimsKey(instance)
+	slots, err := r.client.SMembers(ctx, claims).Result()
+	if err != nil {
+		return 0, fmt.Errorf("读取实例持有的区块失败: %w", err)
+	}
+	keys := []string{Key(ClusterInstancesKey), claims}
+	args := []interface{}{instance, now.UnixMilli(), ttl.Milliseconds()}
+	for _, slot := range slots {
+		keys = append(keys, Key(SlotLeaseKeyPrefix)+slot)
+		args = append(args, slot)
+	}
+	renewed, err := heartbeatScript.Run(ctx, r.client, keys, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("记录实例心跳失败: %w", err)
+	}
+	return renewed, nil
+}
+
+// MarkInstanceDown 将实例的心跳时间置为0，其他实例下一次检查时立即回收它持有的区块，用于正常退出
+// 参数:
+//   - ctx: 上下文
+//   - instance: 实例ID
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) MarkInstanceDown(ctx context.Context, instance string) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := r.client.ZAdd(ctx, Key(ClusterInstancesKey), redis.Z{Score: 0, Member: instance}).Err(); err != nil {
+		return fmt.Errorf("标记实例退出失败: %w", err)
+	}
+	return nil
+}
+
+// ReclaimDeadInstances 回收最近一次心跳早于 deadBefore 的实例持有的区块
+// 参数:
+//   - ctx: 上下文
+//   - deadBefore: 早于该时间没有心跳的实例视为失效
+//
+// 返回:
+//   - map[string][]uint64: 失效的实例ID -> 回收的区块，由调用方重新推入区块队列
+//   - error: 错误信息
+func (r *RedisClient) ReclaimDeadInstances(ctx context.Context, deadBefore time.Time) (map[string][]uint64, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	threshold := deadBefore.UnixMilli()
+	instances, err := r.client.ZRangeByScore(ctx, Key(ClusterInstancesKey), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(threshold, 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取失效实例失败: %w", err)
+	}
+	reclaimed := make(map[string][]uint64)
+	for _, instance := range instances {
+		members, ok, err := r.reclaimInstance(ctx, instance, threshold)
+		if err != nil {
+			return reclaimed, fmt.Errorf("回收实例 %s 的区块失败: %w", instance, err)
+		}
+		if !ok {
+			continue
+		}
+		slots := make([]uint64, 0, len(members))
+		for _, member := range members {
+			slot, err := strconv.ParseUint(member, 10, 64)
+			if err != nil {
+				continue
+			}
+			slots = append(slots, slot)
+		}
+		reclaimed[instance] = slots
+	}
+	return reclaimed, nil
+}
+
+// reclaimInstance 回收单个失效实例，返回它持有的区块，已由其他实例回收或实例仍然存活时返回false
+func (r *RedisClient) reclaimInstance(ctx context.Context, instance string, threshold int64) ([]string, bool, error) {
+	claims := instanceClaimsKey(instance)
+	for range slotLeaseRetries {
+		members, err := r.client.SMembers(ctx, claims).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		keys := []string{Key(ClusterInstancesKey), claims}
+		for _, member := range members {
+			keys = append(keys, Key(SlotLeaseKeyPrefix)+member)
+		}
+		result, err := reclaimInstanceScript.Run(ctx, r.client, keys, instance, threshold).Int()
+		if err != nil {
+			return nil, false, err
+		}
+		switch result {
+		case 1:
+			return members, true, nil
+		case 0:
+			return nil, false, nil
+		}
+	}
+	return nil, false, errors.New("实例持有的区块频繁变化")
+}
+
+// ListInstances 返回所有实例的心跳时间和持有的区块数，按实例ID排序
+// 参数:
+//   - ctx: 上下文
+//   - deadBefore: 早于该时间没有心跳的实例标记为失效
+//
+// 返回:
+//   - []models.ClusterInstance: 实例列表
+//   - error: 错误信息
+func (r *RedisClient) ListInstances(ctx context.Context, deadBefore time.Time) ([]models.ClusterInstance, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	members, err := r.client.ZRangeWithScores(ctx, Key(ClusterInstancesKey), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取实例列表失败: %w", err)
+	}
+	pipe := r.client.Pipeline()
+	claims := make([]*redis.IntCmd, len(members))
+	for i, member := range members {
+		claims[i] = pipe.SCard(ctx, instanceClaimsKey(member.Member.(string)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("读取实例持有的区块数失败: %w", err)
+	}
+	instances := make([]models.ClusterInstance, 0, len(members))
+	for i, member := range members {
+		heartbeat := int64(member.Score)
+		instances = append(instances, models.ClusterInstance{
+			ID:        member.Member.(string),
+			Heartbeat: heartbeat / 1000,
+			Alive:     heartbeat >= deadBefore.UnixMilli(),
+			Claims:    claims[i].Val(),
+		})
+	}
+	slices.SortFunc(instances, func(a, b models.ClusterInstance) int { return strings.Compare(a.ID, b.ID) })
+	return instances, nil
+}
+
+// AcquireIngestLeader 竞选或续期WebSocket摄取领导者
+// 参数:
+//   - ctx: 上下文
+//   - instance: 实例ID
+//   - ttl: 领导者租期，领导者在到期前续期，失效后其他实例最迟在 ttl 之后接管
+//
+// 返回:
+//   - bool: 当前实例是否为领导者
+//   - error: 错误信息
+func (r *RedisClient) AcquireIngestLeader(ctx context.Context, instance string, ttl time.Duration) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	leader, err := acquireLeaderScript.Run(ctx, r.client, []string{Key(IngestLeaderKey)}, instance, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("竞选摄取领导者失败: %w", err)
+	}
+	return leader == 1, nil
+}
+
+// ResignIngestLeader 放弃WebSocket摄取领导者，其他实例下一次竞选时立即接管
+// 参数:
+//   - ctx: 上下文
+//   - instance: 实例ID，不是领导者时不做任何处理
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) ResignIngestLeader(ctx context.Context, instance string) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := resignLeaderScript.Run(ctx, r.client, []string{Key(IngestLeaderKey)}, instance).Err(); err != nil {
+		return fmt.Errorf("放弃摄取领导者失败: %w", err)
+	}
+	return nil
+}
+
+// GetIngestLeader 返回当前的WebSocket摄取领导者
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - string: 领导者的实例ID，没有领导者时为空
+//   - error: 错误信息
+func (r *RedisClient) GetIngestLeader(ctx context.Context) (string, error) {
+	if r == nil || r.client == nil {
+		return "", errors.New("Redis 客户端尚未初始化")
+	}
+	leader, err := r.client.Get(ctx, Key(IngestLeaderKey)).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("读取摄取领导者失败: %w", err)
+	}
+	return leader, nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+// newTestRedis 创建连接miniredis的Redis客户端，测试结束后关闭
+func newTestRedis(t *testing.T) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	logger.Init(&configs.LogConfig{Level: "error"})
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := miniredis.RunT(t)
+	cfg.Redis.Addr = server.Addr()
+	NewRedisClient(&cfg.Redis)
+	t.Cleanup(CloseRedisClients)
+	return GetRedisClient(WorkloadQueue), server
+}
+
+// TestSlotLeaseLifecycle 获取、完成和释放租约时同步更新实例的区块集合
+func TestSlotLeaseLifecycle(t *testing.T) {
+	client, server := newTestRedis(t)
+	ctx := context.Background()
+
+	if ok, err := client.AcquireSlotLease(ctx, 100, "a", time.Minute); err != nil || !ok {
+		t.Fatalf("首次获取租约: %v %v", ok, err)
+	}
+	if ok, err := client.AcquireSlotLease(ctx, 100, "b", time.Minute); err != nil || ok {
+		t.Fatalf("租约被持有时不应获取成功: %v %v", ok, err)
+	}
+	if members, _ := server.SMembers(instanceClaimsKey("a")); !slices.Equal(members, []string{"100"}) {
+		t.Fatalf("实例a持有的区块: %v", members)
+	}
+
+	if err := client.CompleteSlotLease(ctx, 100, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := server.Get(slotLeaseKey(100)); value != slotLeaseDone {
+		t.Fatalf("完成后租约的值为 %q", value)
+	}
+	if server.Exists(instanceClaimsKey("a")) {
+		t.Fatal("完成后应从实例的区块集合中移除")
+	}
+	if ok, _ := client.AcquireSlotLease(ctx, 100, "b", time.Minute); ok {
+		t.Fatal("已完成的区块不应再被获取")
+	}
+
+	if _, err := client.AcquireSlotLease(ctx, 101, "b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ReleaseSlotLease(ctx, 101); err != nil {
+		t.Fatal(err)
+	}
+	if server.Exists(slotLeaseKey(101)) || server.Exists(instanceClaimsKey("b")) {
+		t.Fatal("释放后租约和区块集合都应删除")
+	}
+	// 没有持有实例时完成租约同样成功
+	if err := client.CompleteSlotLease(ctx, 102, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestClearSlotDone 清除完成标记后区块可以重新获取，被持有的租约保持不变
+func TestClearSlotDone(t *testing.T) {
+	client, server := newTestRedis(t)
+	ctx := context.Background()
+
+	for slot := uint64(10); slot <= 12; slot++ {
+		if err := client.CompleteSlotLease(ctx, slot, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.AcquireSlotLease(ctx, 13, "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ClearSlotDone(ctx, 10, 13); err != nil {
+		t.Fatal(err)
+	}
+	for slot := uint64(10); slot <= 12; slot++ {
+		if server.Exists(slotLeaseKey(slot)) {
+			t.Fatalf("区块 %d 的完成标记未清除", slot)
+		}
+	}
+	if owner, _ := server.Get(slotLeaseKey(13)); owner != "a" {
+		t.Fatalf("被持有的租约不应清除，当前值 %q", owner)
+	}
+	if ok, _ := client.AcquireSlotLease(ctx, 10, "b", time.Minute); !ok {
+		t.Fatal("清除完成标记后应可以重新获取")
+	}
+}
+
+// TestHeartbeatAndReclaim 心跳只为仍然持有的租约续期，失效实例的区块由存活实例回收一次
+func TestHeartbeatAndReclaim(t *testing.T) {
+	client, server := newTestRedis(t)
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	for _, slot := range []uint64{1, 2, 3} {
+		if _, err := client.AcquireSlotLease(ctx, slot, "a", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 区块2被其他实例完成，下一次心跳时从集合中移除
+	if err := client.CompleteSlotLease(ctx, 2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	server.Set(slotLeaseKey(3), "b")
+	renewed, err := client.HeartbeatInstance(ctx, "a", now, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed != 1 {
+		t.Fatalf("续期 %d 个租约，期望1", renewed)
+	}
+	if ttl := server.TTL(slotLeaseKey(1)); ttl != 10*time.Minute {
+		t.Fatalf("续期后租约的有效期为 %s", ttl)
+	}
+	if members, _ := server.SMembers(instanceClaimsKey("a")); !slices.Equal(members, []string{"1"}) {
+		t.Fatalf("心跳后实例a持有的区块: %v", members)
+	}
+
+	// 实例仍然存活时不回收
+	if reclaimed, err := client.ReclaimDeadInstances(ctx, now); err != nil || len(reclaimed) != 0 {
+		t.Fatalf("存活的实例不应被回收: %v %v", reclaimed, err)
+	}
+	reclaimed, err := client.ReclaimDeadInstances(ctx, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slots, ok := reclaimed["a"]; !ok || !slices.Equal(slots, []uint64{1}) {
+		t.Fatalf("回收的区块: %v", reclaimed)
+	}
+	if server.Exists(slotLeaseKey(1)) || server.Exists(instanceClaimsKey("a")) {
+		t.Fatal("回收后租约和区块集合都应删除")
+	}
+	if reclaimed, err := client.ReclaimDeadInstances(ctx, now.Add(time.Minute)); err != nil || len(reclaimed) != 0 {
+		t.Fatalf("已回收的实例不应再次回收: %v %v", reclaimed, err)
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// Enhanced API解析结果缓存的键前缀，后接交易签名
+	EnrichedTransactionKeyPrefix = "enriched:tx:"
+)
+
+// 获取解析结果缓存的键名
+func getEnrichedTransactionKey(signature string) string {
+	return Key(EnrichedTransactionKeyPrefix) + signature
+}
+
+// GetEnrichedTransactions 按交易签名批量读取缓存的Enhanced API解析结果
+// 参数:
+//   - ctx: 上下文
+//   - signatures: 交易签名
+//
+// 返回:
+//   - map[string]json.RawMessage: 命中缓存的解析结果，键为交易签名
+//   - error: 错误信息
+func (r *RedisClient) GetEnrichedTransactions(ctx context.Context, signatures []string) (map[string]json.RawMessage, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	if len(signatures) == 0 {
+		return map[string]json.RawMessage{}, nil
+	}
+
+	keys := make([]string, len(signatures))
+	for i, signature := range signatures {
+		keys[i] = getEnrichedTransactionKey(signature)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取解析结果缓存失败: %w", err)
+	}
+
+	cached := make(map[string]json.RawMessage, len(values))
+	for i, value := range values {
+		if data, ok := value.(string); ok {
+			cached[signatures[i]] = json.RawMessage(data)
+		}
+	}
+	return cached, nil
+}
+
+// StoreEnrichedTransactions 批量缓存Enhanced API解析结果
+// 参数:
+//   - ctx: 上下文
+//   - transactions: 解析结果，键为交易签名
+//   - expiration: 过期时间，如果为0则不设置过期时间
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreEnrichedTransactions(ctx context.Context, transactions map[string]json.RawMessage, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for signature, raw := range transactions {
+		pipe.Set(ctx, getEnrichedTransactionKey(signature), []byte(raw), expiration)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("缓存解析结果失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 定时任务执行锁的前缀，后接任务名称，值为持有锁的实例ID
+	JobLockKeyPrefix = "jobs:lock:"
+	// 定时任务调度标记的前缀，后接 <任务名称>:<计划执行时间>，每次调度只有设置成功的实例执行
+	JobFiredKeyPrefix = "jobs:fired:"
+	// 定时任务执行记录的前缀，后接任务名称，List 的元素为 models.JobRun 的JSON，最新的在前
+	JobHistoryKeyPrefix = "jobs:history:"
+)
+
+// acquireJobLockScript 任务没有正在执行且本次调度尚未被其他实例执行时获取执行锁并设置调度标记，返回1，否则返回0
+// 手动触发时只传入执行锁的键
+var acquireJobLockScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if KEYS[2] and not redis.call('SET', KEYS[2], ARGV[1], 'NX', 'PX', ARGV[3]) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// releaseJobLockScript 执行锁仍由该实例持有时删除
+var releaseJobLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireJobLock 获取定时任务的执行锁
+// 参数:
+//   - ctx: 上下文
+//   - job: 任务名称
+//   - instance: 实例ID
+//   - scheduledAt: 计划执行时间，手动触发时为零值，不检查调度标记
+//   - ttl: 执行锁的有效期
+//   - firedTTL: 调度标记的保留时长，应覆盖各实例之间的时钟偏差
+//
+// 返回:
+//   - bool: 是否获取成功，任务正在执行或本次调度已由其他实例执行时为false
+//   - error: 错误信息
+func (r *RedisClient) AcquireJobLock(ctx context.Context, job, instance string, scheduledAt time.Time, ttl, firedTTL time.Duration) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	keys := []string{Key(JobLockKeyPrefix) + job}
+	if !scheduledAt.IsZero() {
+		keys = append(keys, fmt.Sprintf("%s%s:%d", Key(JobFiredKeyPrefix), job, scheduledAt.Unix()))
+	}
+	acquired, err := acquireJobLockScript.Run(ctx, r.client, keys, instance, ttl.Milliseconds(), firedTTL.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("获取任务执行锁失败: %w", err)
+	}
+	return acquired == 1, nil
+}
+
+// ReleaseJobLock 释放该实例持有的定时任务执行锁
+// 参数:
+//   - ctx: 上下文
+//   - job: 任务名称
+//   - instance: 实例ID
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) ReleaseJobLock(ctx context.Context, job, instance string) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := releaseJobLockScript.Run(ctx, r.client, []string{Key(JobLockKeyPrefix) + job}, instance).Err(); err != nil {
+		return fmt.Errorf("释放任务执行锁失败: %w", err)
+	}
+	return nil
+}
+
+// GetJobLockOwner 返回持有定时任务执行锁的实例，没有实例在执行时返回空字符串
+// 参数:
+//   - ctx: 上下文
+//   - job: 任务名称
+//
+// 返回:
+//   - string: 实例ID
+//   - error: 错误信息
+func (r *RedisClient) GetJobLockOwner(ctx context.Context, job string) (string, error) {
+	if r == nil || r.client == nil {
+		return "", errors.New("Redis 客户端尚未初始化")
+	}
+	owner, err := r.client.Get(ctx, Key(JobLockKeyPrefix)+job).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取任务执行锁失败: %w", err)
+	}
+	return owner, nil
+}
+
+// RecordJobRun 记录定时任务的执行结果，只保留最近的 maxHistory 条
+// 参数:
+//   - ctx: 上下文
+//   - run: 执行记录
+//   - maxHistory: 每个任务最多保留的记录数
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) RecordJobRun(ctx context.Context, run models.JobRun, maxHistory int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("序列化任务执行记录失败: %w", err)
+	}
+	key := Key(JobHistoryKeyPrefix) + run.Job
+	pipe := r.client.TxPipeline()
+	pipe.LPush(ctx, key, value)
+	pipe.LTrim(ctx, key, 0, maxHistory-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录任务执行结果失败: %w", err)
+	}
+	return nil
+}
+
+// GetJobRuns 获取定时任务最近的执行记录
+// 参数:
+//   - ctx: 上下文
+//   - job: 任务名称
+//   - limit: 最多返回的记录数
+//
+// 返回:
+//   - []models.JobRun: 执行记录，最新的在前
+//   - error: 错误信息
+func (r *RedisClient) GetJobRuns(ctx context.Context, job string, limit int64) ([]models.JobRun, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.LRange(ctx, Key(JobHistoryKeyPrefix)+job, 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取任务执行记录失败: %w", err)
+	}
+	runs := make([]models.JobRun, 0, len(values))
+	for _, value := range values {
+		var run models.JobRun
+		if err := json.Unmarshal([]byte(value), &run); err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "strings"
+
+// DefaultKeyPrefix 默认的键名前缀，与引入前缀前的键名一致，升级后无需迁移数据
+const DefaultKeyPrefix = "solana"
+
+// keyPrefix 所有Redis键名和发布订阅频道的前缀，由 NewRedisClient 按 redis.key_prefix 设置
+var keyPrefix = DefaultKeyPrefix
+
+// SetKeyPrefix 设置键名前缀，多个实例共用一个Redis时使用不同前缀避免键名冲突
+// 需要在读写Redis之前调用，为空时使用默认前缀
+func SetKeyPrefix(prefix string) {
+	prefix = strings.TrimSuffix(prefix, ":")
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+	keyPrefix = prefix
+}
+
+// KeyPrefix 返回当前的键名前缀
+func KeyPrefix() string {
+	return keyPrefix
+}
+
+// Key 返回带命名空间前缀的完整键名，name 为各存储定义的键名或键名前缀，如 Key(BlocksZSetKey)
+func Key(name string) string {
+	return keyPrefix + ":" + name
+}
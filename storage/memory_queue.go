@@ -2,60 +2,137 @@ package storage
 
 import (
 	"container/heap"
+	"context"
+	"encoding/json"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
 	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+	"go.uber.org/zap"
 )
 
-// 区块队列
-var GlobalBlockQueue *PriorityQueue
+// 区块队列，元素为区块槽位
+var GlobalBlockQueue *PriorityQueue[uint64]
 
-// 交易队列
-var GlobalTransactionQueue *PriorityQueue
+// 交易队列，元素为区块中需要解析的交易签名
+var GlobalTransactionQueue *PriorityQueue[models.TransactionQueueModel]
 
 func InitQueue() {
 	// 区块队列
-	GlobalBlockQueue = NewPriorityQueue("区块队列")
+	GlobalBlockQueue = NewPriorityQueue[uint64]("区块队列")
 	// 交易队列
-	GlobalTransactionQueue = NewPriorityQueue("交易队列")
+	GlobalTransactionQueue = NewPriorityQueue[models.TransactionQueueModel]("交易队列")
+
+	// 超时元素移入Redis死信队列，等待后续回补
+	queueConfig := configs.GlobalConfig.Queue
+	SetQueueMaxAge(queueConfig.BlockMaxAge, queueConfig.TransactionMaxAge)
+
+	// 实时与回补分道调度，避免大量回补的旧区块阻塞新区块
+	if queueConfig.Lanes.Enabled {
+		lanes := QueueLanes{
+			LiveWindow:     queueConfig.Lanes.LiveWindow,
+			LiveWeight:     queueConfig.Lanes.LiveWeight,
+			BackfillWeight: queueConfig.Lanes.BackfillWeight,
+		}
+		GlobalBlockQueue.SetLanes(lanes)
+		GlobalTransactionQueue.SetLanes(lanes)
+	}
+
+	// 交易队列使用Redis Streams时，需在Redis客户端初始化之后调用
+	if queueConfig.Backend == QueueBackendRedisStream {
+		stream, err := NewStreamTransactionStore(GetRedisClient(WorkloadQueue), &queueConfig.Stream)
+		if err != nil {
+			logger.Fatal("初始化Redis Streams交易队列失败", zap.Error(err))
+		}
+		GlobalTransactionStream = stream
+	}
+}
+
+// SetQueueMaxAge 设置区块队列和交易队列的最大等待时间，超时元素移入Redis死信队列
+// 队列尚未初始化时不做任何处理
+func SetQueueMaxAge(blockMaxAge, transactionMaxAge time.Duration) {
+	if GlobalBlockQueue != nil {
+		GlobalBlockQueue.SetMaxAge(blockMaxAge, deadLetterHandler[uint64]("block"))
+	}
+	if GlobalTransactionQueue != nil {
+		GlobalTransactionQueue.SetMaxAge(transactionMaxAge, deadLetterHandler[models.TransactionQueueModel]("transaction"))
+	}
+}
+
+// deadLetterHandler 返回将超时元素写入指定死信队列的处理函数
+func deadLetterHandler[T any](queue string) ExpiredHandler[T] {
+	return func(item *Item[T]) {
+		age := clock.Since(item.EnqueuedAt)
+		logger.Warn("队列元素等待超时，移入死信队列",
+			zap.String("queue", queue),
+			zap.Int64("priority", item.Priority),
+			zap.Duration("age", age))
+
+		value, err := json.Marshal(item.Value)
+		if err != nil {
+			logger.Error("序列化超时元素失败", zap.String("queue", queue), zap.Error(err))
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = GetRedisClient(WorkloadQueue).PushDeadLetter(ctx, DeadLetterItem{
+			Queue:       queue,
+			Priority:    item.Priority,
+			Value:       value,
+			Reason:      "等待超时: " + age.Truncate(time.Second).String(),
+			EnqueuedAt:  item.EnqueuedAt.Unix(),
+			DiscardedAt: clock.Now().Unix(),
+		})
+		if err != nil {
+			logger.Error("写入死信队列失败", zap.String("queue", queue), zap.Int64("priority", item.Priority), zap.Error(err))
+		}
+	}
 }
 
 // Item 是存储在优先队列中的元素
-type Item struct {
-	Value    interface{} // 元素的值，可以使用任何类型
-	Priority int64       // 元素的优先级，数值越小优先级越高
-	index    int         // 堆中元素的索引，由 container/heap 维护
+type Item[T any] struct {
+	Value      T         // 元素的值
+	Priority   int64     // 元素的优先级，数值越小优先级越高
+	EnqueuedAt time.Time // 入队时间
+	index      int       // 堆中元素的索引，由 container/heap 维护
 }
 
+// ExpiredHandler 处理等待超时被丢弃的元素
+type ExpiredHandler[T any] func(item *Item[T])
+
 // priorityQueueImpl 实现了 container/heap.Interface 接口
 // 这是优先队列底层使用的数据结构（最小堆）
-type priorityQueueImpl []*Item
+type priorityQueueImpl[T any] []*Item[T]
 
-func (pq priorityQueueImpl) Len() int { return len(pq) }
+func (pq priorityQueueImpl[T]) Len() int { return len(pq) }
 
 // Less 用于比较两个元素的优先级
 // 我们希望数值越小优先级越高，所以这里比较的是优先级数值
-func (pq priorityQueueImpl) Less(i, j int) bool {
+func (pq priorityQueueImpl[T]) Less(i, j int) bool {
 	return pq[i].Priority < pq[j].Priority
 }
 
 // Swap 交换两个元素，并更新它们的索引
-func (pq priorityQueueImpl) Swap(i, j int) {
+func (pq priorityQueueImpl[T]) Swap(i, j int) {
 	pq[i], pq[j] = pq[j], pq[i]
 	pq[i].index = i
 	pq[j].index = j
 }
 
 // Push 将一个元素添加到堆中 (由 heap.Push 调用)
-func (pq *priorityQueueImpl) Push(x any) {
+func (pq *priorityQueueImpl[T]) Push(x any) {
 	n := len(*pq)
-	item := x.(*Item)
+	item := x.(*Item[T])
 	item.index = n // 设置新元素的索引
 	*pq = append(*pq, item)
 }
 
 // Pop 从堆中移除并返回优先级最高的元素 (由 heap.Pop 调用)
-func (pq *priorityQueueImpl) Pop() any {
+func (pq *priorityQueueImpl[T]) Pop() any {
 	old := *pq
 	n := len(old)
 	item := old[n-1]   // 获取最后一个元素
@@ -65,74 +142,307 @@ func (pq *priorityQueueImpl) Pop() any {
 	return item        // 返回的是被移除的元素 (原堆顶元素)
 }
 
+// QueueLanes 实时与回补分道调度的参数
+// 优先级(槽位)落后已入队的最大优先级超过 LiveWindow 的元素进入回补通道，其余进入实时通道；
+// 最大优先级增大后，实时通道中落后超过窗口的元素移入回补通道，因此第一个实时槽位到达之前入队的回补槽位
+// (如启动时的回补)不会一直留在实时通道中排在实时槽位之前。
+// 两个通道都有元素时按 LiveWeight:BackfillWeight 的比例轮流出队，通道内仍按优先级出队；
+// 出队时跳过的超时元素不计入轮转
+type QueueLanes struct {
+	LiveWindow     int64 // 实时通道的优先级窗口，0表示不分道
+	LiveWeight     int   // 实时通道的权重
+	BackfillWeight int   // 回补通道的权重
+}
+
 // PriorityQueue 是线程安全的优先队列
-type PriorityQueue struct {
-	heap      *priorityQueueImpl // 底层堆实现
-	mu        sync.Mutex         // 用于同步访问堆的互斥锁
-	QueueName string             // 队列名称
+type PriorityQueue[T any] struct {
+	heap      *priorityQueueImpl[T] // 底层堆实现，分道调度时为实时通道
+	backfill  *priorityQueueImpl[T] // 分道调度时的回补通道
+	mu        sync.Mutex            // 用于同步访问堆的互斥锁
+	QueueName string                // 队列名称
+	maxAge    time.Duration         // 元素最大等待时间，0表示不限制
+	onExpired ExpiredHandler[T]     // 超时元素的处理函数
+	peak      int                   // 上次 TakePeak 以来的最大长度
+	lastPop   time.Time             // 最近一次出队的时间，队列由空变为非空时重置为入队时间
+	lanes     QueueLanes            // 分道调度参数
+	newest    int64                 // 入队过的最大优先级
+	turn      int                   // 加权轮转的位置
 }
 
 // NewPriorityQueue 创建一个新的线程安全的优先队列
-func NewPriorityQueue(queueName string) *PriorityQueue {
-	pqImpl := &priorityQueueImpl{}
+func NewPriorityQueue[T any](queueName string) *PriorityQueue[T] {
+	pqImpl := &priorityQueueImpl[T]{}
 	heap.Init(pqImpl) // 初始化堆
-	return &PriorityQueue{
+	return &PriorityQueue[T]{
 		heap:      pqImpl,
+		backfill:  &priorityQueueImpl[T]{},
 		QueueName: queueName,
+		lastPop:   clock.Now(),
 	}
 }
 
+// SetMaxAge 设置元素最大等待时间，出队时超时的元素会被丢弃并交给onExpired处理
+// maxAge 为0时不限制
+func (pq *PriorityQueue[T]) SetMaxAge(maxAge time.Duration, onExpired ExpiredHandler[T]) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.maxAge = maxAge
+	pq.onExpired = onExpired
+}
+
+// SetLanes 设置实时与回补分道调度，LiveWindow 为0时取消分道，回补通道中的元素并入实时通道；
+// 设置分道时已在队列中、落后超过窗口的元素移入回补通道
+func (pq *PriorityQueue[T]) SetLanes(lanes QueueLanes) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.lanes = lanes
+	if lanes.LiveWindow <= 0 {
+		for pq.backfill.Len() > 0 {
+			heap.Push(pq.heap, heap.Pop(pq.backfill))
+		}
+		return
+	}
+	pq.demote()
+}
+
+// len 返回两个通道的元素总数，调用方需持有锁
+func (pq *PriorityQueue[T]) len() int {
+	return pq.heap.Len() + pq.backfill.Len()
+}
+
+// next 返回下一个出队的通道：两个通道都有元素时按权重轮转，否则返回有元素的通道，调用方需持有锁
+func (pq *PriorityQueue[T]) next() *priorityQueueImpl[T] {
+	if pq.backfill.Len() == 0 {
+		return pq.heap
+	}
+	if pq.heap.Len() == 0 {
+		return pq.backfill
+	}
+	if pq.lanes.live(pq.turn) {
+		return pq.heap
+	}
+	return pq.backfill
+}
+
+// live 返回加权轮转中第 turn 次出队是否轮到实时通道
+func (l QueueLanes) live(turn int) bool {
+	live, backfill := max(l.LiveWeight, 1), max(l.BackfillWeight, 1)
+	return turn%(live+backfill) < live
+}
+
 // Push 将一个值及其优先级推入队列
-func (pq *PriorityQueue) Push(value interface{}, priority int64) {
+func (pq *PriorityQueue[T]) Push(value T, priority int64) {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
 
-	item := &Item{
-		Value:    value,
-		Priority: priority,
+	item := &Item[T]{
+		Value:      value,
+		Priority:   priority,
+		EnqueuedAt: clock.Now(),
+	}
+	if pq.len() == 0 {
+		pq.lastPop = item.EnqueuedAt
+	}
+	lane := pq.heap
+	if pq.lanes.LiveWindow > 0 && priority < pq.newest-pq.lanes.LiveWindow {
+		lane = pq.backfill
 	}
 	// heap.Push 会调用 pq.heap 的 Push 方法并调整堆结构
-	heap.Push(pq.heap, item)
+	heap.Push(lane, item)
+	if priority > pq.newest {
+		pq.newest = priority
+		pq.demote()
+	}
+	pq.peak = max(pq.peak, pq.len())
 }
 
-// Pop 移除并返回优先级最高的元素。
-// 如果队列为空，返回 nil, 0, false。
-func (pq *PriorityQueue) Pop() (interface{}, int64, bool) {
+// demote 把实时通道中落后最大优先级超过窗口的元素移入回补通道，每个元素最多移动一次，调用方需持有锁
+func (pq *PriorityQueue[T]) demote() {
+	if pq.lanes.LiveWindow <= 0 {
+		return
+	}
+	for pq.heap.Len() > 0 && (*pq.heap)[0].Priority < pq.newest-pq.lanes.LiveWindow {
+		heap.Push(pq.backfill, heap.Pop(pq.heap))
+	}
+}
+
+// Pop 移除并返回优先级最高的元素，分道调度时按权重在两个通道之间轮流出队。
+// 设置了最大等待时间时，超时的元素会被跳过并交给超时处理函数。
+// 如果队列为空，返回零值, 0, false。
+func (pq *PriorityQueue[T]) Pop() (T, int64, bool) {
 	pq.mu.Lock()
-	defer pq.mu.Unlock()
+	var expired []*Item[T]
+	var result *Item[T]
+	for pq.len() > 0 {
+		contested := pq.heap.Len() > 0 && pq.backfill.Len() > 0
+		// heap.Pop 会调用 pq.heap 的 Pop 方法并调整堆结构
+		item := heap.Pop(pq.next()).(*Item[T])
+		if pq.maxAge > 0 && clock.Since(item.EnqueuedAt) > pq.maxAge {
+			expired = append(expired, item)
+			continue
+		}
+		if contested {
+			pq.turn++
+		}
+		result = item
+		break
+	}
+	if result != nil {
+		pq.lastPop = clock.Now()
+	}
+	onExpired := pq.onExpired
+	pq.mu.Unlock()
 
-	if pq.heap.Len() == 0 {
-		return nil, 0, false // 队列为空
+	// 在锁外处理超时元素，避免阻塞队列
+	if onExpired != nil {
+		for _, item := range expired {
+			onExpired(item)
+		}
 	}
 
-	// heap.Pop 会调用 pq.heap 的 Pop 方法并调整堆结构
-	item := heap.Pop(pq.heap).(*Item)
-	logger.Infof("队列 %s 移除元素 %d ", pq.QueueName, item.Priority)
-	return item.Value, item.Priority, true
+	if result == nil {
+		var zero T
+		return zero, 0, false // 队列为空
+	}
+	logger.Infof("队列 %s 移除元素 %d ", pq.QueueName, result.Priority)
+	return result.Value, result.Priority, true
 }
 
-// Peek 查看优先级最高的元素，但不从队列中移除。
-// 如果队列为空，返回 nil, 0, false。
-func (pq *PriorityQueue) Peek() (interface{}, int64, bool) {
+// Peek 查看下一个出队的元素，但不从队列中移除。
+// 如果队列为空，返回零值, 0, false。
+func (pq *PriorityQueue[T]) Peek() (T, int64, bool) {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
 
-	if pq.heap.Len() == 0 {
-		return nil, 0, false // 队列为空
+	lane := pq.next()
+	if lane.Len() == 0 {
+		var zero T
+		return zero, 0, false // 队列为空
 	}
 
-	item := (*pq.heap)[0] // 直接访问堆顶元素 (索引 0)
+	item := (*lane)[0] // 直接访问堆顶元素 (索引 0)
 	return item.Value, item.Priority, true
 }
 
+// PeekN 按出队顺序查看至多n个元素，不从队列中移除
+// 每个通道只取优先级最高的n个元素，不复制和排序整个堆，持有锁的时间与队列长度基本无关
+func (pq *PriorityQueue[T]) PeekN(n int) []T {
+	pq.mu.Lock()
+	live := pq.heap.smallest(n)
+	backfill := pq.backfill.smallest(n)
+	lanes, turn := pq.lanes, pq.turn
+	pq.mu.Unlock()
+
+	values := make([]T, 0, min(n, len(live)+len(backfill)))
+	for len(values) < n && len(live)+len(backfill) > 0 {
+		var item *Item[T]
+		contested := len(live) > 0 && len(backfill) > 0
+		if len(backfill) == 0 || (contested && lanes.live(turn)) {
+			item, live = live[0], live[1:]
+		} else {
+			item, backfill = backfill[0], backfill[1:]
+		}
+		if contested {
+			turn++
+		}
+		values = append(values, item.Value)
+	}
+	return values
+}
+
+// smallest 按优先级升序返回堆中至多n个元素，不修改堆
+// 从堆顶开始，每取出一个元素只将它的两个子节点加入候选堆，候选堆最多 n+1 个元素
+func (pq priorityQueueImpl[T]) smallest(n int) []*Item[T] {
+	items := make([]*Item[T], 0, min(n, len(pq)))
+	if n <= 0 || len(pq) == 0 {
+		return items
+	}
+	candidates := &heapCandidates[T]{heap: pq, indexes: []int{0}}
+	for len(items) < n && candidates.Len() > 0 {
+		i := heap.Pop(candidates).(int)
+		items = append(items, pq[i])
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(pq) {
+				heap.Push(candidates, child)
+			}
+		}
+	}
+	return items
+}
+
+// heapCandidates 按优先级排列的堆元素索引，用于不修改堆地按顺序取出元素
+type heapCandidates[T any] struct {
+	heap    priorityQueueImpl[T]
+	indexes []int
+}
+
+func (c *heapCandidates[T]) Len() int { return len(c.indexes) }
+
+func (c *heapCandidates[T]) Less(i, j int) bool {
+	return c.heap[c.indexes[i]].Priority < c.heap[c.indexes[j]].Priority
+}
+
+func (c *heapCandidates[T]) Swap(i, j int) { c.indexes[i], c.indexes[j] = c.indexes[j], c.indexes[i] }
+
+func (c *heapCandidates[T]) Push(x any) { c.indexes = append(c.indexes, x.(int)) }
+
+func (c *heapCandidates[T]) Pop() any {
+	n := len(c.indexes)
+	i := c.indexes[n-1]
+	c.indexes = c.indexes[:n-1]
+	return i
+}
+
 // Len 返回队列中元素的数量
-func (pq *PriorityQueue) Len() int {
+func (pq *PriorityQueue[T]) Len() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.len()
+}
+
+// LaneLen 返回实时通道和回补通道中的元素数量，没有分道调度时回补通道为0
+func (pq *PriorityQueue[T]) LaneLen() (live, backfill int) {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
-	return pq.heap.Len()
+	return pq.heap.Len(), pq.backfill.Len()
+}
+
+// TakePeak 返回上次调用以来队列的最大长度，并以当前长度重新开始统计
+func (pq *PriorityQueue[T]) TakePeak() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	peak := max(pq.peak, pq.len())
+	pq.peak = pq.len()
+	return peak
+}
+
+// Staleness 返回队列非空时距最近一次出队的时长，用于发现消费者停滞，队列为空时返回0
+func (pq *PriorityQueue[T]) Staleness() time.Duration {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	if pq.len() == 0 {
+		return 0
+	}
+	return clock.Since(pq.lastPop)
+}
+
+// OldestAge 返回队列中等待最久的元素已等待的时长，队列为空时返回0
+func (pq *PriorityQueue[T]) OldestAge() time.Duration {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	var oldest time.Time
+	for _, item := range slices.Concat(*pq.heap, *pq.backfill) {
+		if oldest.IsZero() || item.EnqueuedAt.Before(oldest) {
+			oldest = item.EnqueuedAt
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return clock.Since(oldest)
 }
 
 // IsEmpty 检查队列是否为空
-func (pq *PriorityQueue) IsEmpty() bool {
+func (pq *PriorityQueue[T]) IsEmpty() bool {
 	return pq.Len() == 0 // Len 方法内部已加锁
 }
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+func TestPriorityQueueMaxAge(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+
+	queue := NewPriorityQueue[uint64]("测试队列")
+	var expired []uint64
+	queue.SetMaxAge(time.Minute, func(item *Item[uint64]) {
+		expired = append(expired, item.Value)
+	})
+
+	queue.Push(100, 100)
+	fake.Advance(30 * time.Second)
+	queue.Push(101, 101)
+	fake.Advance(31 * time.Second)
+
+	// 槽位100已等待61秒，超过最大等待时间，出队时被跳过并交给超时处理函数
+	value, _, ok := queue.Pop()
+	if !ok || value != 101 {
+		t.Fatalf("出队 %d(%v)，期望 101", value, ok)
+	}
+	if len(expired) != 1 || expired[0] != 100 {
+		t.Fatalf("超时的元素 = %v，期望 [100]", expired)
+	}
+
+	// 刚好等于最大等待时间的元素不算超时
+	queue.Push(102, 102)
+	fake.Advance(time.Minute)
+	if value, _, ok := queue.Pop(); !ok || value != 102 {
+		t.Fatalf("出队 %d(%v)，期望 102", value, ok)
+	}
+	if _, _, ok := queue.Pop(); ok {
+		t.Fatal("队列应为空")
+	}
+	if queue.Staleness() != 0 {
+		t.Fatalf("出队后停滞时间 = %s，期望 0", queue.Staleness())
+	}
+}
+
+// drain 依次出队所有元素，返回出队的优先级
+func drain(queue *PriorityQueue[uint64]) []int64 {
+	var priorities []int64
+	for {
+		_, priority, ok := queue.Pop()
+		if !ok {
+			return priorities
+		}
+		priorities = append(priorities, priority)
+	}
+}
+
+func TestPriorityQueueLaneClassification(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	for _, tc := range []struct {
+		name           string
+		pushes         []int64
+		live, backfill int
+	}{
+		{"窗口内的槽位都在实时通道", []int64{1000, 995, 990}, 3, 0},
+		{"刚好等于窗口边界的槽位在实时通道", []int64{1000, 990}, 2, 0},
+		{"落后超过窗口的槽位进入回补通道", []int64{1000, 989, 500}, 1, 2},
+		{"最大槽位增大后落后的实时槽位移入回补通道", []int64{1000, 1005, 1011}, 2, 1},
+		{"第一个实时槽位之前入队的回补槽位移入回补通道", []int64{100, 101, 102, 5000}, 1, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := NewPriorityQueue[uint64]("测试队列")
+			queue.SetLanes(QueueLanes{LiveWindow: 10, LiveWeight: 1, BackfillWeight: 1})
+			for _, priority := range tc.pushes {
+				queue.Push(uint64(priority), priority)
+			}
+			if live, backfill := queue.LaneLen(); live != tc.live || backfill != tc.backfill {
+				t.Fatalf("实时 %d 回补 %d，期望实时 %d 回补 %d", live, backfill, tc.live, tc.backfill)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueLaneRotation(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	for _, tc := range []struct {
+		name                   string
+		liveWeight, backWeight int
+		backfill, live         []int64
+		want                   []int64
+	}{
+		{"权重1:1交替出队", 1, 1, []int64{1, 2, 3}, []int64{1000, 1001, 1002}, []int64{1000, 1, 1001, 2, 1002, 3}},
+		{"权重2:1", 2, 1, []int64{1, 2}, []int64{1000, 1001, 1002, 1003}, []int64{1000, 1001, 1, 1002, 1003, 2}},
+		{"权重1:3", 1, 3, []int64{1, 2, 3, 4}, []int64{1000, 1001}, []int64{1000, 1, 2, 3, 1001, 4}},
+		{"一个通道为空时直接从另一个通道出队", 3, 1, []int64{1, 2, 3}, []int64{1000}, []int64{1000, 1, 2, 3}},
+		{"权重为0按1处理", 0, 0, []int64{1, 2}, []int64{1000, 1001}, []int64{1000, 1, 1001, 2}},
+		{"启动时先入队的回补不排在实时槽位之前", 1, 1, nil, []int64{100, 101, 102, 5000, 5001}, []int64{5000, 100, 5001, 101, 102}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := NewPriorityQueue[uint64]("测试队列")
+			queue.SetLanes(QueueLanes{LiveWindow: 10, LiveWeight: tc.liveWeight, BackfillWeight: tc.backWeight})
+			// 先推入最大的实时槽位，之后的回补槽位按窗口进入回补通道
+			for _, priority := range append(slices.Clone(tc.live), tc.backfill...) {
+				queue.Push(uint64(priority), priority)
+			}
+			// PeekN 按相同的轮转顺序查看，不改变出队顺序
+			for n := 0; n <= len(tc.want)+1; n++ {
+				var peeked []int64
+				for _, value := range queue.PeekN(n) {
+					peeked = append(peeked, int64(value))
+				}
+				if want := tc.want[:min(n, len(tc.want))]; !slices.Equal(peeked, want) {
+					t.Fatalf("PeekN(%d) = %v，期望 %v", n, peeked, want)
+				}
+			}
+			if got := drain(queue); !slices.Equal(got, tc.want) {
+				t.Fatalf("出队顺序 = %v，期望 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueLaneExpiryKeepsTurn(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+
+	queue := NewPriorityQueue[uint64]("测试队列")
+	queue.SetLanes(QueueLanes{LiveWindow: 10, LiveWeight: 1, BackfillWeight: 1})
+	var expired []uint64
+	queue.SetMaxAge(time.Minute, func(item *Item[uint64]) { expired = append(expired, item.Value) })
+
+	// 实时通道的1000已超时，跳过后仍轮到实时通道，出队1001
+	queue.Push(1000, 1000)
+	fake.Advance(2 * time.Minute)
+	for _, priority := range []int64{1001, 1002, 1, 2} {
+		queue.Push(uint64(priority), priority)
+	}
+	if got := drain(queue); !slices.Equal(got, []int64{1001, 1, 1002, 2}) {
+		t.Fatalf("出队顺序 = %v，期望 [1001 1 1002 2]", got)
+	}
+	if !slices.Equal(expired, []uint64{1000}) {
+		t.Fatalf("超时的元素 = %v，期望 [1000]", expired)
+	}
+}
+
+func TestPriorityQueueSmallest(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	queue := NewPriorityQueue[uint64]("测试队列")
+	priorities := rand.Perm(200)
+	for _, priority := range priorities {
+		queue.Push(uint64(priority), int64(priority))
+	}
+	for _, n := range []int{0, 1, 7, 64, 200, 300} {
+		var got []int64
+		for _, item := range queue.heap.smallest(n) {
+			got = append(got, item.Priority)
+		}
+		var want []int64
+		for priority := range min(n, len(priorities)) {
+			want = append(want, int64(priority))
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("smallest(%d) = %v，期望 %v", n, got, want)
+		}
+	}
+	// 查看不修改堆
+	if got := drain(queue); len(got) != len(priorities) || !slices.IsSorted(got) {
+		t.Fatalf("查看后出队 %d 个元素，是否有序 %v", len(got), slices.IsSorted(got))
+	}
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+)
+
+// MemoryStore 同时实现 BlockStore、TransactionQueueStore 和 ResultStore 的内存存储，
+// 用于在测试中替代内存队列和Redis，过期时间会被忽略
+type MemoryStore struct {
+	blocks       *PriorityQueue[uint64]
+	transactions *PriorityQueue[models.TransactionQueueModel]
+
+	mu         sync.Mutex
+	index      map[string]map[string]string   // 来源:类型 或 mint:代币 -> 签名 -> 类型
+	enriched   map[string]json.RawMessage     // 签名 -> 解析结果
+	raw        map[string]json.RawMessage     // 签名 -> 原始响应
+	failures   map[string]models.ParseFailure // 签名 -> 解析失败记录
+	blockMetas map[string]models.BlockMeta    // 区块哈希 -> 区块元数据
+	summaries  map[uint64]models.BlockSummary // 槽位 -> 区块交易汇总
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blocks:       NewPriorityQueue[uint64]("内存区块队列"),
+		transactions: NewPriorityQueue[models.TransactionQueueModel]("内存交易队列"),
+		index:        make(map[string]map[string]string),
+		enriched:     make(map[string]json.RawMessage),
+		raw:          make(map[string]json.RawMessage),
+		failures:     make(map[string]models.ParseFailure),
+		blockMetas:   make(map[string]models.BlockMeta),
+		summaries:    make(map[uint64]models.BlockSummary),
+	}
+}
+
+// PushBlock 将区块槽位推入队列
+func (s *MemoryStore) PushBlock(slot uint64) {
+	s.blocks.Push(slot, int64(slot))
+}
+
+// PopBlock 取出槽位最小的区块
+func (s *MemoryStore) PopBlock() (uint64, bool) {
+	value, _, ok := s.blocks.Pop()
+	return value, ok
+}
+
+// BlockQueueLen 返回队列中的区块数量
+func (s *MemoryStore) BlockQueueLen() int {
+	return s.blocks.Len()
+}
+
+// PushTransactions 将区块中需要解析的交易签名推入队列
+func (s *MemoryStore) PushTransactions(item models.TransactionQueueModel) {
+	s.transactions.Push(item, int64(item.Slot))
+}
+
+// PopTransactions 取出槽位最小的区块的交易签名
+func (s *MemoryStore) PopTransactions() (models.TransactionQueueModel, bool) {
+	value, _, ok := s.transactions.Pop()
+	return value, ok
+}
+
+// TransactionQueueLen 返回队列中的区块数量
+func (s *MemoryStore) TransactionQueueLen() int {
+	return s.transactions.Len()
+}
+
+// IndexTransaction 按来源/类型和代币索引交易签名，不区分天，也不限制签名数
+func (s *MemoryStore) IndexTransaction(ctx context.Context, transaction models.IndexedTransaction, ttl, mintTTL time.Duration, maxPerKey int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := []string{transaction.Source + ":" + transaction.Type}
+	for _, mint := range transaction.Mints {
+		keys = append(keys, "mint:"+mint)
+	}
+	for _, key := range keys {
+		if s.index[key] == nil {
+			s.index[key] = make(map[string]string)
+		}
+		s.index[key][transaction.Signature] = transaction.Type
+	}
+	return nil
+}
+
+// IndexedSignatures 返回按来源和类型索引的签名，按签名排序
+func (s *MemoryStore) IndexedSignatures(source, transactionType string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.index[source+":"+transactionType]))
+}
+
+// GetEnrichedTransactions 按签名读取缓存的解析结果
+func (s *MemoryStore) GetEnrichedTransactions(ctx context.Context, signatures []string) (map[string]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]json.RawMessage, len(signatures))
+	for _, signature := range signatures {
+		if raw, ok := s.enriched[signature]; ok {
+			result[signature] = raw
+		}
+	}
+	return result, nil
+}
+
+// StoreEnrichedTransactions 缓存解析结果
+func (s *MemoryStore) StoreEnrichedTransactions(ctx context.Context, transactions map[string]json.RawMessage, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	maps.Copy(s.enriched, transactions)
+	return nil
+}
+
+// StoreRawTransaction 归档Enhanced API原始响应
+func (s *MemoryStore) StoreRawTransaction(ctx context.Context, signature string, slot uint64, raw json.RawMessage, compress bool, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw[signature] = raw
+	return nil
+}
+
+// RawTransaction 返回归档的原始响应
+func (s *MemoryStore) RawTransaction(signature string) (json.RawMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.raw[signature]
+	return raw, ok
+}
+
+// RecordParseFailures 记录签名的解析失败
+func (s *MemoryStore) RecordParseFailures(ctx context.Context, slot uint64, failures map[string]models.ParseFailureReason, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := clock.Now().Unix()
+	for signature, reason := range failures {
+		failure, ok := s.failures[signature]
+		if !ok {
+			failure = models.ParseFailure{Signature: signature, FirstSeen: now}
+		}
+		failure.Reason = reason
+		failure.Attempts++
+		failure.Slot = slot
+		failure.LastSeen = now
+		s.failures[signature] = failure
+	}
+	return nil
+}
+
+// GetParseFailures 按签名读取解析失败记录
+func (s *MemoryStore) GetParseFailures(ctx context.Context, signatures []string) (map[string]models.ParseFailure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]models.ParseFailure)
+	for _, signature := range signatures {
+		if failure, ok := s.failures[signature]; ok {
+			result[signature] = failure
+		}
+	}
+	return result, nil
+}
+
+// ClearParseFailures 删除签名的解析失败记录
+func (s *MemoryStore) ClearParseFailures(ctx context.Context, signatures []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, signature := range signatures {
+		delete(s.failures, signature)
+	}
+	return nil
+}
+
+// StoreBlockMeta 按区块哈希保存区块元数据
+func (s *MemoryStore) StoreBlockMeta(ctx context.Context, meta models.BlockMeta, expiration time.Duration) error {
+	if meta.Blockhash == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blockMetas[meta.Blockhash] = meta
+	return nil
+}
+
+// BlockMeta 按区块哈希返回保存的区块元数据
+func (s *MemoryStore) BlockMeta(blockhash string) (models.BlockMeta, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.blockMetas[blockhash]
+	return meta, ok
+}
+
+// StoreBlockSummary 保存区块交易汇总，内存存储不限制保留的区块数
+func (s *MemoryStore) StoreBlockSummary(ctx context.Context, summary models.BlockSummary, maxEntries int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaries[summary.Slot] = summary
+	return nil
+}
+
+// BlockSummary 按槽位返回保存的区块交易汇总
+func (s *MemoryStore) BlockSummary(slot uint64) (models.BlockSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary, ok := s.summaries[slot]
+	return summary, ok
+}
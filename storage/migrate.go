@@ -0,0 +1,440 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 存储结构版本的键名，未设置时为v1
+	SchemaVersionKey = "schema:version"
+	// 迁移进度的键名，迁移中断后从记录的SCAN游标继续
+	MigrationProgressKey = "schema:migration"
+	// CurrentSchemaVersion 当前代码写入的存储结构版本
+	CurrentSchemaVersion = "v3"
+
+	// v1 交易索引的键前缀: solana:hash:<来源> 与 solana:hash:<来源>_<类型>
+	legacyTransactionHashPrefix = "hash:"
+	// v2 交易索引的键前缀: solana:tx:<来源>:<类型>，字段为签名，值为交易类型
+	TransactionIndexKeyPrefix = "tx:"
+	// v2/v3 来源出现过的交易类型集合的键前缀: solana:tx:types:<来源>
+	TransactionTypesKeyPrefix = "tx:types:"
+	// v3 交易索引按天拆分并带过期时间，见 TransactionDayIndexKeyPrefix
+)
+
+// MigrationOptions 迁移选项
+type MigrationOptions struct {
+	BatchSize      int64                   // 每次SCAN/HSCAN的数量
+	Cleanup        bool                    // 改写后删除旧键，不删除时可直接回滚
+	DryRun         bool                    // 只统计需要改写的键，不执行写入
+	KeepUnresolved bool                    // v2迁移到v3时无法确定槽位和时间的签名保留在v2索引中继续迁移，该键不删除；不开启时返回错误
+	Progress       func(MigrationProgress) // 每批处理完成后的进度回调
+}
+
+// MigrationProgress 迁移进度
+type MigrationProgress struct {
+	From     string `json:"from"`     // 源版本
+	To       string `json:"to"`       // 目标版本
+	Cursor   uint64 `json:"cursor"`   // 当前SCAN游标
+	Scanned  int64  `json:"scanned"`  // 已扫描的键数量
+	Migrated int64  `json:"migrated"` // 已改写的键数量
+	Done     bool   `json:"done"`     // 是否已完成
+}
+
+// migration 两个版本之间的迁移，回滚通过反向迁移实现
+type migration struct {
+	from    string
+	to      string
+	pattern string                                                                                              // 需要改写的键
+	rewrite func(ctx context.Context, client *redis.Client, key string, options MigrationOptions) (bool, error) // 改写单个键，返回是否改写
+}
+
+// migrations 已注册的迁移
+var migrations = []migration{
+	{from: "v1", to: "v2", pattern: Key(legacyTransactionHashPrefix) + "*", rewrite: upgradeTransactionIndex},
+	{from: "v2", to: "v1", pattern: Key(TransactionIndexKeyPrefix) + "*", rewrite: downgradeTransactionIndex},
+	{from: "v2", to: "v3", pattern: Key(TransactionIndexKeyPrefix) + "*", rewrite: upgradeDayIndex},
+	{from: "v3", to: "v2", pattern: Key(TransactionDayIndexKeyPrefix) + "*", rewrite: downgradeDayIndex},
+}
+
+// getTransactionIndexKey 获取v2交易索引的键名
+func getTransactionIndexKey(source, transactionType string) string {
+	return Key(TransactionIndexKeyPrefix) + source + ":" + transactionType
+}
+
+// GetSchemaVersion 读取存储结构版本
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - string: 存储结构版本，未设置时为v1
+//   - error: 错误信息
+func (r *RedisClient) GetSchemaVersion(ctx context.Context) (string, error) {
+	if r == nil || r.client == nil {
+		return "", errors.New("Redis 客户端尚未初始化")
+	}
+	version, err := r.client.Get(ctx, Key(SchemaVersionKey)).Result()
+	if err == redis.Nil {
+		return "v1", nil
+	} else if err != nil {
+		return "", fmt.Errorf("读取存储结构版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// GetMigrationProgress 读取未完成的迁移进度
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *MigrationProgress: 迁移进度，没有未完成的迁移时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetMigrationProgress(ctx context.Context) (*MigrationProgress, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.HGetAll(ctx, Key(MigrationProgressKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移进度失败: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	progress := &MigrationProgress{From: values["from"], To: values["to"]}
+	progress.Cursor, _ = strconv.ParseUint(values["cursor"], 10, 64)
+	progress.Scanned, _ = strconv.ParseInt(values["scanned"], 10, 64)
+	progress.Migrated, _ = strconv.ParseInt(values["migrated"], 10, 64)
+	return progress, nil
+}
+
+// Migrate 在线将存储结构从from版本迁移到to版本，反向迁移即回滚
+// 迁移按SCAN游标分批改写键，每批完成后记录进度，中断后再次执行会从记录的游标继续
+// 参数:
+//   - ctx: 上下文
+//   - from: 源版本
+//   - to: 目标版本
+//   - options: 迁移选项
+//
+// 返回:
+//   - *MigrationProgress: 最终进度
+//   - error: 错误信息
+func (r *RedisClient) Migrate(ctx context.Context, from, to string, options MigrationOptions) (*MigrationProgress, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	var current *migration
+	for i := range migrations {
+		if migrations[i].from == from && migrations[i].to == to {
+			current = &migrations[i]
+			break
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("不支持从 %s 迁移到 %s", from, to)
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+
+	progress, err := r.GetMigrationProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil && (progress.From != from || progress.To != to) {
+		return nil, fmt.Errorf("存在未完成的迁移 %s -> %s，请先完成该迁移", progress.From, progress.To)
+	}
+	if progress == nil {
+		version, err := r.GetSchemaVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if version == to {
+			return &MigrationProgress{From: from, To: to, Done: true}, nil
+		}
+		if version != from {
+			return nil, fmt.Errorf("当前存储结构版本为 %s，无法从 %s 迁移", version, from)
+		}
+		progress = &MigrationProgress{From: from, To: to}
+	}
+
+	for {
+		keys, cursor, err := r.client.Scan(ctx, progress.Cursor, current.pattern, options.BatchSize).Result()
+		if err != nil {
+			return progress, fmt.Errorf("扫描键失败: %w", err)
+		}
+		for _, key := range keys {
+			progress.Scanned++
+			migrated, err := current.rewrite(ctx, r.client, key, options)
+			if err != nil {
+				return progress, fmt.Errorf("改写键 %s 失败: %w", key, err)
+			}
+			if migrated {
+				progress.Migrated++
+			}
+		}
+		progress.Cursor = cursor
+		progress.Done = cursor == 0
+		if !options.DryRun && !progress.Done {
+			err := r.client.HSet(ctx, Key(MigrationProgressKey),
+				"from", from, "to", to, "cursor", cursor, "scanned", progress.Scanned, "migrated", progress.Migrated).Err()
+			if err != nil {
+				return progress, fmt.Errorf("保存迁移进度失败: %w", err)
+			}
+		}
+		if options.Progress != nil {
+			options.Progress(*progress)
+		}
+		if progress.Done {
+			break
+		}
+	}
+
+	if options.DryRun {
+		return progress, nil
+	}
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, Key(SchemaVersionKey), to, 0)
+	pipe.Del(ctx, Key(MigrationProgressKey))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return progress, fmt.Errorf("更新存储结构版本失败: %w", err)
+	}
+	return progress, nil
+}
+
+// scanHash 分批遍历哈希的字段
+func scanHash(ctx context.Context, client *redis.Client, key string, batchSize int64, fn func(fields map[string]string) error) error {
+	var cursor uint64
+	for {
+		values, next, err := client.HScan(ctx, key, cursor, "*", batchSize).Result()
+		if err != nil {
+			return err
+		}
+		fields := make(map[string]string, len(values)/2)
+		for i := 0; i+1 < len(values); i += 2 {
+			fields[values[i]] = values[i+1]
+		}
+		if err := fn(fields); err != nil {
+			return err
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// upgradeTransactionIndex 将v1交易索引改写为v2
+// v1中 solana:hash:<来源> 只有一个字段，字段名为来源、值为最近的交易类型；
+// solana:hash:<来源>_<类型> 的字段为签名、值为交易类型，来源和类型都可能包含下划线，因此按值截取来源
+func upgradeTransactionIndex(ctx context.Context, client *redis.Client, key string, options MigrationOptions) (bool, error) {
+	name := strings.TrimPrefix(key, Key(legacyTransactionHashPrefix))
+	migrated := false
+	err := scanHash(ctx, client, key, options.BatchSize, func(fields map[string]string) error {
+		pipe := client.Pipeline()
+		for field, transactionType := range fields {
+			if field == name {
+				// 来源记录
+				pipe.SAdd(ctx, Key(TransactionTypesKeyPrefix)+name, transactionType)
+				migrated = true
+				continue
+			}
+			source, ok := strings.CutSuffix(name, "_"+transactionType)
+			if !ok || source == "" {
+				continue
+			}
+			pipe.SAdd(ctx, Key(TransactionTypesKeyPrefix)+source, transactionType)
+			pipe.HSet(ctx, getTransactionIndexKey(source, transactionType), field, transactionType)
+			migrated = true
+		}
+		if options.DryRun || pipe.Len() == 0 {
+			return nil
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if migrated && options.Cleanup && !options.DryRun {
+		if err := client.Del(ctx, key).Err(); err != nil {
+			return false, err
+		}
+	}
+	return migrated, nil
+}
+
+// downgradeTransactionIndex 将v2交易索引改写回v1，用于回滚
+func downgradeTransactionIndex(ctx context.Context, client *redis.Client, key string, options MigrationOptions) (bool, error) {
+	name := strings.TrimPrefix(key, Key(TransactionIndexKeyPrefix))
+	if source, ok := strings.CutPrefix(name, "types:"); ok {
+		// 来源记录，v1只保存一个类型
+		transactionType, err := client.SRandMember(ctx, key).Result()
+		if err == redis.Nil {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if !options.DryRun {
+			if err := client.HSet(ctx, Key(legacyTransactionHashPrefix)+source, source, transactionType).Err(); err != nil {
+				return false, err
+			}
+		}
+	} else {
+		i := strings.LastIndex(name, ":")
+		if i <= 0 {
+			return false, nil
+		}
+		legacyKey := Key(legacyTransactionHashPrefix) + name[:i] + "_" + name[i+1:]
+		err := scanHash(ctx, client, key, options.BatchSize, func(fields map[string]string) error {
+			if options.DryRun || len(fields) == 0 {
+				return nil
+			}
+			return client.HSet(ctx, legacyKey, fields).Err()
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if options.Cleanup && !options.DryRun {
+		if err := client.Del(ctx, key).Err(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// upgradeDayIndex 将v2交易索引改写为v3的按天索引
+// v2没有记录槽位和时间，按缓存的Enhanced API解析结果确定签名所在的天和槽位；
+// 没有缓存的签名无法归入正确的天，未开启 KeepUnresolved 时返回错误，开启时保留在v2索引中
+// 过期时间由清理任务按配置补设
+func upgradeDayIndex(ctx context.Context, client *redis.Client, key string, options MigrationOptions) (bool, error) {
+	name := strings.TrimPrefix(key, Key(TransactionIndexKeyPrefix))
+	if strings.HasPrefix(name, "types:") {
+		// 来源出现过的交易类型集合在v3中沿用
+		return false, nil
+	}
+	i := strings.LastIndex(name, ":")
+	if i <= 0 {
+		return false, nil
+	}
+	source, transactionType := name[:i], name[i+1:]
+	var unresolved int
+	err := scanHash(ctx, client, key, options.BatchSize, func(fields map[string]string) error {
+		if len(fields) == 0 {
+			return nil
+		}
+		byDay, missing, err := resolveIndexedSignatures(ctx, client, fields)
+		if err != nil {
+			return err
+		}
+		unresolved += missing
+		if options.DryRun {
+			return nil
+		}
+		pipe := client.Pipeline()
+		for day, members := range byDay {
+			pipe.ZAdd(ctx, getTransactionDayIndexKey(source, transactionType, day), members...)
+		}
+		if pipe.Len() == 0 {
+			return nil
+		}
+		_, err = pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if unresolved > 0 && !options.KeepUnresolved {
+		return false, fmt.Errorf("%d 个签名没有缓存的解析结果，无法确定所在的天和槽位；确认后可开启 KeepUnresolved(storage migrate --keep-unresolved)将其保留在v2索引中继续迁移", unresolved)
+	}
+	if options.Cleanup && !options.DryRun && unresolved == 0 {
+		if err := client.Del(ctx, key).Err(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// resolveIndexedSignatures 按缓存的解析结果将签名按区块时间所在的天分组，分数为槽位
+// 返回:
+//   - map[int64][]redis.Z: 按UTC日起始时间分组的成员
+//   - int: 没有缓存或缓存中没有槽位的签名数量
+//   - error: 错误信息
+func resolveIndexedSignatures(ctx context.Context, client *redis.Client, fields map[string]string) (map[int64][]redis.Z, int, error) {
+	signatures := make([]string, 0, len(fields))
+	keys := make([]string, 0, len(fields))
+	for signature := range fields {
+		signatures = append(signatures, signature)
+		keys = append(keys, getEnrichedTransactionKey(signature))
+	}
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取解析结果缓存失败: %w", err)
+	}
+	byDay := make(map[int64][]redis.Z)
+	missing := 0
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			missing++
+			continue
+		}
+		var transaction struct {
+			Slot      uint64 `json:"slot"`
+			Timestamp int64  `json:"timestamp"`
+		}
+		if err := json.Unmarshal([]byte(data), &transaction); err != nil || transaction.Slot == 0 || transaction.Timestamp <= 0 {
+			missing++
+			continue
+		}
+		day := IndexDay(transaction.Timestamp)
+		byDay[day] = append(byDay[day], redis.Z{Score: float64(transaction.Slot), Member: signatures[i]})
+	}
+	return byDay, missing, nil
+}
+
+// downgradeDayIndex 将v3的按天索引合并回v2交易索引，用于回滚；代币索引在v2中没有对应结构，保留到过期
+func downgradeDayIndex(ctx context.Context, client *redis.Client, key string, options MigrationOptions) (bool, error) {
+	name := strings.TrimPrefix(key, Key(TransactionDayIndexKeyPrefix))
+	i := strings.LastIndex(name, ":")
+	if i <= 0 {
+		return false, nil
+	}
+	j := strings.LastIndex(name[:i], ":")
+	if j <= 0 {
+		return false, nil
+	}
+	source, transactionType := name[:j], name[j+1:i]
+	var cursor uint64
+	for {
+		values, next, err := client.ZScan(ctx, key, cursor, "*", options.BatchSize).Result()
+		if err != nil {
+			return false, err
+		}
+		if !options.DryRun && len(values) > 0 {
+			fields := make(map[string]string, len(values)/2)
+			for k := 0; k+1 < len(values); k += 2 {
+				fields[values[k]] = transactionType
+			}
+			if err := client.HSet(ctx, getTransactionIndexKey(source, transactionType), fields).Err(); err != nil {
+				return false, err
+			}
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if options.Cleanup && !options.DryRun {
+		if err := client.Del(ctx, key).Err(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
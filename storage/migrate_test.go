@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestMigrateDayIndexUsesCachedSlots v2迁移到v3时按缓存的解析结果归入区块时间所在的天，分数为槽位
+func TestMigrateDayIndexUsesCachedSlots(t *testing.T) {
+	client, server := newTestRedis(t)
+	ctx := context.Background()
+	const day = int64(1700006400)
+
+	server.Set(Key(SchemaVersionKey), "v2")
+	server.HSet(getTransactionIndexKey("PUMP_FUN", "SWAP"), "sigA", "SWAP", "sigB", "SWAP", "sigC", "SWAP")
+	server.Set(getEnrichedTransactionKey("sigA"), `{"signature":"sigA","slot":100,"timestamp":1700006500}`)
+	server.Set(getEnrichedTransactionKey("sigB"), `{"signature":"sigB","slot":200,"timestamp":1700092900}`)
+
+	// sigC 没有缓存的解析结果，默认迁移失败且不更新版本
+	_, err := client.Migrate(ctx, "v2", "v3", MigrationOptions{Cleanup: true})
+	if err == nil || !strings.Contains(err.Error(), "1 个签名") {
+		t.Fatalf("存在无法确定槽位的签名时应迁移失败: %v", err)
+	}
+	if version, _ := client.GetSchemaVersion(ctx); version != "v2" {
+		t.Fatalf("迁移失败后版本为 %s", version)
+	}
+
+	progress, err := client.Migrate(ctx, "v2", "v3", MigrationOptions{Cleanup: true, KeepUnresolved: true})
+	if err != nil || !progress.Done {
+		t.Fatalf("保留无法确定槽位的签名后迁移: %+v %v", progress, err)
+	}
+	for _, tt := range []struct {
+		day       int64
+		signature string
+		slot      float64
+	}{
+		{day, "sigA", 100},
+		{day + 86400, "sigB", 200},
+	} {
+		if score, err := server.ZScore(getTransactionDayIndexKey("PUMP_FUN", "SWAP", tt.day), tt.signature); err != nil || score != tt.slot {
+			t.Fatalf("%s 在 %d 的分数为 %v(%v)，期望 %v", tt.signature, tt.day, score, err, tt.slot)
+		}
+	}
+	// 仍有未迁移的签名，开启 Cleanup 也保留v2索引
+	if !server.Exists(getTransactionIndexKey("PUMP_FUN", "SWAP")) {
+		t.Fatal("存在未迁移的签名时不应删除v2索引")
+	}
+	if version, _ := client.GetSchemaVersion(ctx); version != "v3" {
+		t.Fatalf("迁移后版本为 %s", version)
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 买卖盘失衡时间序列键前缀，后接代币地址，有序集合的分数为快照时间
+	OrderFlowKeyPrefix = "orderflow:"
+)
+
+// 获取买卖盘失衡时间序列的键名
+func getOrderFlowKey(mint string) string {
+	return Key(OrderFlowKeyPrefix) + mint
+}
+
+// StoreOrderFlowSnapshot 追加买卖盘失衡快照，并删除超过保留时长的旧快照
+// 参数:
+//   - ctx: 上下文
+//   - snapshot: 快照
+//   - retention: 保留时长，0表示不删除
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreOrderFlowSnapshot(ctx context.Context, snapshot models.OrderFlowSnapshot, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("序列化买卖盘失衡快照失败: %w", err)
+	}
+	key := getOrderFlowKey(snapshot.Mint)
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(snapshot.Timestamp), Member: value})
+	if retention > 0 {
+		minScore := snapshot.Timestamp - int64(retention.Seconds())
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(minScore, 10))
+		pipe.Expire(ctx, key, retention)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("存储买卖盘失衡快照失败: %w", err)
+	}
+	return nil
+}
+
+// GetOrderFlowSeries 获取时间范围内的买卖盘失衡快照
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - since: 起始时间(Unix时间戳，包含)
+//   - until: 结束时间(Unix时间戳，包含)
+//
+// 返回:
+//   - []models.OrderFlowSnapshot: 按时间升序的快照
+//   - error: 错误信息
+func (r *RedisClient) GetOrderFlowSeries(ctx context.Context, mint string, since, until int64) ([]models.OrderFlowSnapshot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.ZRangeByScore(ctx, getOrderFlowKey(mint), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: strconv.FormatInt(until, 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取买卖盘失衡快照失败: %w", err)
+	}
+	series := make([]models.OrderFlowSnapshot, 0, len(values))
+	for _, value := range values {
+		var snapshot models.OrderFlowSnapshot
+		if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+			continue
+		}
+		series = append(series, snapshot)
+	}
+	return series, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 没有被最终确认的槽位记录，Sorted Set 的分数为槽位，成员为 models.OrphanedSlot 的JSON
+	OrphanedSlotsKey = "orphaned:slots"
+)
+
+// RemoveSlotTransactions 删除槽位中交易的解析结果缓存、原始响应、解析失败记录和来源/类型、代币索引
+// 参数:
+//   - ctx: 上下文
+//   - signatures: 槽位中入队解析的交易签名
+//   - indexed: 已索引的交易
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) RemoveSlotTransactions(ctx context.Context, signatures []string, indexed []models.IndexedTransaction) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(signatures) == 0 && len(indexed) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for _, signature := range signatures {
+		pipe.Del(ctx, getEnrichedTransactionKey(signature), getRawTransactionKey(signature), getParseFailureKey(signature))
+	}
+	for _, transaction := range indexed {
+		day := IndexDay(transaction.Timestamp)
+		pipe.ZRem(ctx, getTransactionDayIndexKey(transaction.Source, transaction.Type, day), transaction.Signature)
+		for _, mint := range transaction.Mints {
+			pipe.ZRem(ctx, getMintDayIndexKey(mint, day), transaction.Signature)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("删除槽位交易数据失败: %w", err)
+	}
+	return nil
+}
+
+// RecordOrphanedSlot 记录没有被最终确认的槽位，只保留槽位最大的 maxHistory 条
+// 参数:
+//   - ctx: 上下文
+//   - orphaned: 槽位记录
+//   - maxHistory: 最多保留的记录数，0表示不限制
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) RecordOrphanedSlot(ctx context.Context, orphaned models.OrphanedSlot, maxHistory int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(orphaned)
+	if err != nil {
+		return fmt.Errorf("序列化槽位记录失败: %w", err)
+	}
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, Key(OrphanedSlotsKey), redis.Z{Score: float64(orphaned.Slot), Member: value})
+	if maxHistory > 0 {
+		pipe.ZRemRangeByRank(ctx, Key(OrphanedSlotsKey), 0, -maxHistory-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录未最终确认的槽位失败: %w", err)
+	}
+	return nil
+}
+
+// GetOrphanedSlots 获取没有被最终确认的槽位记录
+// 参数:
+//   - ctx: 上下文
+//   - limit: 最多返回的记录数，0表示全部
+//
+// 返回:
+//   - []models.OrphanedSlot: 按槽位降序排列的记录
+//   - error: 错误信息
+func (r *RedisClient) GetOrphanedSlots(ctx context.Context, limit int64) ([]models.OrphanedSlot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.ZRevRange(ctx, Key(OrphanedSlotsKey), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取未最终确认的槽位失败: %w", err)
+	}
+	slots := make([]models.OrphanedSlot, 0, len(values))
+	for _, value := range values {
+		var orphaned models.OrphanedSlot
+		if err := json.Unmarshal([]byte(value), &orphaned); err != nil {
+			continue
+		}
+		slots = append(slots, orphaned)
+	}
+	return slots, nil
+}
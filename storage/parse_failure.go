@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 解析失败记录(不再解析缓存)的键前缀，后接交易签名，值为哈希
+	ParseFailureKeyPrefix = "negative:tx:"
+)
+
+// 获取解析失败记录的键名
+func getParseFailureKey(signature string) string {
+	return Key(ParseFailureKeyPrefix) + signature
+}
+
+// RecordParseFailures 批量记录签名的解析失败，失败次数累加
+// 参数:
+//   - ctx: 上下文
+//   - slot: 所在区块槽位
+//   - failures: 失败原因，键为交易签名
+//   - expiration: 记录保留时长，每次失败后重新计时，如果为0则不设置过期时间
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) RecordParseFailures(ctx context.Context, slot uint64, failures map[string]models.ParseFailureReason, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	now := clock.Now().Unix()
+	pipe := r.client.Pipeline()
+	for signature, reason := range failures {
+		key := getParseFailureKey(signature)
+		pipe.HIncrBy(ctx, key, "attempts", 1)
+		pipe.HSet(ctx, key, "reason", string(reason), "slot", slot, "last_seen", now)
+		pipe.HSetNX(ctx, key, "first_seen", now)
+		if expiration > 0 {
+			pipe.Expire(ctx, key, expiration)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录解析失败失败: %w", err)
+	}
+	return nil
+}
+
+// GetParseFailures 按交易签名批量读取解析失败记录
+// 参数:
+//   - ctx: 上下文
+//   - signatures: 交易签名
+//
+// 返回:
+//   - map[string]models.ParseFailure: 有失败记录的签名，键为交易签名
+//   - error: 错误信息
+func (r *RedisClient) GetParseFailures(ctx context.Context, signatures []string) (map[string]models.ParseFailure, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	failures := make(map[string]models.ParseFailure)
+	if len(signatures) == 0 {
+		return failures, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(signatures))
+	for i, signature := range signatures {
+		cmds[i] = pipe.HGetAll(ctx, getParseFailureKey(signature))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("读取解析失败记录失败: %w", err)
+	}
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		if len(values) == 0 {
+			continue
+		}
+		attempts, _ := strconv.Atoi(values["attempts"])
+		slot, _ := strconv.ParseUint(values["slot"], 10, 64)
+		firstSeen, _ := strconv.ParseInt(values["first_seen"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(values["last_seen"], 10, 64)
+		failures[signatures[i]] = models.ParseFailure{
+			Signature: signatures[i],
+			Reason:    models.ParseFailureReason(values["reason"]),
+			Attempts:  attempts,
+			Slot:      slot,
+			FirstSeen: firstSeen,
+			LastSeen:  lastSeen,
+		}
+	}
+	return failures, nil
+}
+
+// ClearParseFailures 删除签名的解析失败记录，签名之后成功解析或需要强制重新解析时调用
+// 参数:
+//   - ctx: 上下文
+//   - signatures: 交易签名
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) ClearParseFailures(ctx context.Context, signatures []string) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(signatures) == 0 {
+		return nil
+	}
+	keys := make([]string, len(signatures))
+	for i, signature := range signatures {
+		keys[i] = getParseFailureKey(signature)
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("删除解析失败记录失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 新创建的流动性池，Sorted Set 的分数为槽位，成员为 models.PoolCreation 的JSON
+	PoolCreationsKey = "pools:created"
+)
+
+// RecordPoolCreation 记录新创建的流动性池，只保留槽位最大的 maxHistory 条
+// 参数:
+//   - ctx: 上下文
+//   - creation: 池子创建记录
+//   - maxHistory: 最多保留的记录数，0表示不限制
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) RecordPoolCreation(ctx context.Context, creation models.PoolCreation, maxHistory int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(creation)
+	if err != nil {
+		return fmt.Errorf("序列化池子创建记录失败: %w", err)
+	}
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, Key(PoolCreationsKey), redis.Z{Score: float64(creation.Slot), Member: value})
+	if maxHistory > 0 {
+		pipe.ZRemRangeByRank(ctx, Key(PoolCreationsKey), 0, -maxHistory-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录池子创建失败: %w", err)
+	}
+	return nil
+}
+
+// GetPoolCreations 获取新创建的流动性池
+// 参数:
+//   - ctx: 上下文
+//   - limit: 最多返回的记录数，0表示全部
+//
+// 返回:
+//   - []models.PoolCreation: 按槽位降序排列的记录
+//   - error: 错误信息
+func (r *RedisClient) GetPoolCreations(ctx context.Context, limit int64) ([]models.PoolCreation, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.ZRevRange(ctx, Key(PoolCreationsKey), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取池子创建记录失败: %w", err)
+	}
+	creations := make([]models.PoolCreation, 0, len(values))
+	for _, value := range values {
+		var creation models.PoolCreation
+		if err := json.Unmarshal([]byte(value), &creation); err != nil {
+			continue
+		}
+		creations = append(creations, creation)
+	}
+	return creations, nil
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 持仓的键前缀，后接代币地址
+	// 每个代币一个Hash，字段为 <钱包>:balance、<钱包>:bought、<钱包>:sold、<钱包>:buy_sol、<钱包>:sell_sol、<钱包>:updated
+	PositionKeyPrefix = "position:"
+	// 每日净流入的键前缀，后接 <代币地址>:<UTC日起始时间>，Sorted Set 的成员为钱包，分数为当天的净流入数量
+	AccumulationKeyPrefix = "accumulation:"
+)
+
+// 获取持仓的键名
+func getPositionKey(mint string) string {
+	return Key(PositionKeyPrefix) + mint
+}
+
+// 获取每日净流入的键名
+func getAccumulationKey(mint string, day int64) string {
+	return Key(AccumulationKeyPrefix) + mint + ":" + strconv.FormatInt(day, 10)
+}
+
+// IncrPositions 累加钱包持仓和每日净流入
+// 参数:
+//   - ctx: 上下文
+//   - deltas: 增量，Day 为UTC日起始时间
+//   - positionTTL: 持仓保留时长，每次变化后重新计时，0表示不过期
+//   - retention: 每日净流入保留时长，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) IncrPositions(ctx context.Context, deltas []models.PositionDelta, positionTTL, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+	pipe := r.client.TxPipeline()
+	expired := make(map[string]bool)
+	for _, delta := range deltas {
+		key := getPositionKey(delta.Mint)
+		fields := map[string]float64{
+			"balance":  delta.Balance,
+			"bought":   delta.Bought,
+			"sold":     delta.Sold,
+			"buy_sol":  delta.BuySOL,
+			"sell_sol": delta.SellSOL,
+		}
+		for field, value := range fields {
+			if value != 0 {
+				pipe.HIncrByFloat(ctx, key, delta.Wallet+":"+field, value)
+			}
+		}
+		pipe.HSet(ctx, key, delta.Wallet+":updated", delta.UpdatedAt)
+		if positionTTL > 0 && !expired[key] {
+			expired[key] = true
+			pipe.Expire(ctx, key, positionTTL)
+		}
+
+		if delta.Balance == 0 {
+			continue
+		}
+		accumulationKey := getAccumulationKey(delta.Mint, delta.Day)
+		pipe.ZIncrBy(ctx, accumulationKey, delta.Balance, delta.Wallet)
+		if retention > 0 && !expired[accumulationKey] {
+			expired[accumulationKey] = true
+			pipe.ExpireAt(ctx, accumulationKey, time.Unix(delta.Day, 0).Add(24*time.Hour+retention))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("累加持仓失败: %w", err)
+	}
+	return nil
+}
+
+// GetPositions 获取代币所有钱包的持仓，按净数量降序排列
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//
+// 返回:
+//   - []models.Position: 持仓列表
+//   - error: 错误信息
+func (r *RedisClient) GetPositions(ctx context.Context, mint string) ([]models.Position, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	fields, err := r.client.HGetAll(ctx, getPositionKey(mint)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	byWallet := make(map[string]*models.Position)
+	for field, value := range fields {
+		index := strings.LastIndex(field, ":")
+		if index < 0 {
+			continue
+		}
+		wallet, metric := field[:index], field[index+1:]
+		position, ok := byWallet[wallet]
+		if !ok {
+			position = &models.Position{Mint: mint, Wallet: wallet}
+			byWallet[wallet] = position
+		}
+		setPositionField(position, metric, value)
+	}
+
+	positions := make([]models.Position, 0, len(byWallet))
+	for _, position := range byWallet {
+		derivePosition(position)
+		positions = append(positions, *position)
+	}
+	slices.SortFunc(positions, func(a, b models.Position) int {
+		if c := cmp.Compare(b.Balance, a.Balance); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Wallet, b.Wallet)
+	})
+	return positions, nil
+}
+
+// GetPosition 获取单个钱包在代币上的持仓
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - wallet: 钱包地址
+//
+// 返回:
+//   - models.Position: 持仓
+//   - bool: 是否有记录
+//   - error: 错误信息
+func (r *RedisClient) GetPosition(ctx context.Context, mint, wallet string) (models.Position, bool, error) {
+	if r == nil || r.client == nil {
+		return models.Position{}, false, errors.New("Redis 客户端尚未初始化")
+	}
+	metrics := []string{"balance", "bought", "sold", "buy_sol", "sell_sol", "updated"}
+	fields := make([]string, len(metrics))
+	for i, metric := range metrics {
+		fields[i] = wallet + ":" + metric
+	}
+	values, err := r.client.HMGet(ctx, getPositionKey(mint), fields...).Result()
+	if err != nil {
+		return models.Position{}, false, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	position := models.Position{Mint: mint, Wallet: wallet}
+	found := false
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			found = true
+			setPositionField(&position, metrics[i], text)
+		}
+	}
+	derivePosition(&position)
+	return position, found, nil
+}
+
+// GetAccumulations 汇总时间范围内各钱包对代币的净流入，返回净流入最多的钱包
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - since: 起始时间(Unix时间戳，按所在UTC日计算，包含)
+//   - until: 结束时间(Unix时间戳，按所在UTC日计算，包含)
+//   - limit: 最多返回的钱包数
+//
+// 返回:
+//   - []models.Accumulation: 按净流入降序排列，只包含净流入大于0的钱包
+//   - error: 错误信息
+func (r *RedisClient) GetAccumulations(ctx context.Context, mint string, since, until int64, limit int) ([]models.Accumulation, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	daySeconds := int64((24 * time.Hour).Seconds())
+	pipe := r.client.Pipeline()
+	var cmds []*redis.ZSliceCmd
+	for day := since - since%daySeconds; day <= until; day += daySeconds {
+		cmds = append(cmds, pipe.ZRangeWithScores(ctx, getAccumulationKey(mint, day), 0, -1))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("获取净流入失败: %w", err)
+	}
+
+	totals := make(map[string]float64)
+	for _, cmd := range cmds {
+		for _, member := range cmd.Val() {
+			totals[member.Member.(string)] += member.Score
+		}
+	}
+	accumulations := make([]models.Accumulation, 0, len(totals))
+	for wallet, amount := range totals {
+		if amount > 0 {
+			accumulations = append(accumulations, models.Accumulation{Wallet: wallet, NetAmount: amount})
+		}
+	}
+	slices.SortFunc(accumulations, func(a, b models.Accumulation) int {
+		if c := cmp.Compare(b.NetAmount, a.NetAmount); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Wallet, b.Wallet)
+	})
+	if limit > 0 && len(accumulations) > limit {
+		accumulations = accumulations[:limit]
+	}
+	return accumulations, nil
+}
+
+// setPositionField 按字段名设置持仓的值
+func setPositionField(position *models.Position, metric, value string) {
+	number, _ := strconv.ParseFloat(value, 64)
+	switch metric {
+	case "balance":
+		position.Balance = number
+	case "bought":
+		position.Bought = number
+	case "sold":
+		position.Sold = number
+	case "buy_sol":
+		position.BuySOL = number
+	case "sell_sol":
+		position.SellSOL = number
+	case "updated":
+		position.UpdatedAt = int64(number)
+	}
+}
+
+// derivePosition 按平均买入价计算持仓成本和已实现盈亏，没有SOL买入记录时无法计算
+func derivePosition(position *models.Position) {
+	if position.Bought <= 0 || position.BuySOL <= 0 {
+		return
+	}
+	position.AvgBuyPrice = position.BuySOL / position.Bought
+	if position.Balance > 0 {
+		position.CostBasis = position.Balance * position.AvgBuyPrice
+	}
+	position.RealizedPnL = position.SellSOL - position.Sold*position.AvgBuyPrice
+}
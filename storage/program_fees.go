@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 按程序统计手续费的小时数据键前缀，后接小时起始时间(Unix时间戳)
+	// 每个小时一个Hash，字段为 <程序ID>:<指标>，指标为 tx、failed、prioritized、fee、priority、cu、price
+	ProgramFeesKeyPrefix = "programfees:"
+)
+
+// 获取程序手续费小时统计的键名
+func getProgramFeesKey(hour int64) string {
+	return Key(ProgramFeesKeyPrefix) + strconv.FormatInt(hour, 10)
+}
+
+// IncrProgramFees 累加各程序在对应小时内的交易数、手续费和计算单元价格
+// 参数:
+//   - ctx: 上下文
+//   - fees: 增量，Hour 为小时起始时间
+//   - retention: 保留时长，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) IncrProgramFees(ctx context.Context, fees []models.ProgramFees, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(fees) == 0 {
+		return nil
+	}
+	pipe := r.client.TxPipeline()
+	expired := make(map[int64]bool)
+	for _, fee := range fees {
+		key := getProgramFeesKey(fee.Hour)
+		for metric, value := range map[string]int64{
+			"tx":          fee.Transactions,
+			"failed":      fee.Failed,
+			"prioritized": fee.Prioritized,
+			"fee":         fee.Fees,
+			"priority":    fee.PriorityFees,
+			"cu":          fee.ComputeUnits,
+			"price":       fee.UnitPriceSum,
+		} {
+			if value != 0 {
+				pipe.HIncrBy(ctx, key, fee.Program+":"+metric, value)
+			}
+		}
+		if retention > 0 && !expired[fee.Hour] {
+			expired[fee.Hour] = true
+			pipe.ExpireAt(ctx, key, time.Unix(fee.Hour, 0).Add(time.Hour+retention))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("累加程序手续费统计失败: %w", err)
+	}
+	return nil
+}
+
+// GetProgramFees 获取时间范围内各小时、各程序的手续费统计
+// 参数:
+//   - ctx: 上下文
+//   - since: 起始时间(Unix时间戳，按所在小时计算，包含)
+//   - until: 结束时间(Unix时间戳，按所在小时计算，包含)
+//   - program: 只返回该程序的统计，为空时返回所有程序
+//
+// 返回:
+//   - []models.ProgramFees: 按小时升序、同一小时内按程序ID排序的统计，平均值未计算
+//   - error: 错误信息
+func (r *RedisClient) GetProgramFees(ctx context.Context, since, until int64, program string) ([]models.ProgramFees, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	hourSeconds := int64(time.Hour.Seconds())
+	// 每个小时一个Hash，通过管道一次读取整个时间范围
+	var hours []int64
+	var results []*redis.MapStringStringCmd
+	pipe := r.client.Pipeline()
+	for hour := since - since%hourSeconds; hour <= until; hour += hourSeconds {
+		hours = append(hours, hour)
+		results = append(results, pipe.HGetAll(ctx, getProgramFeesKey(hour)))
+	}
+	if len(hours) == 0 {
+		return nil, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("获取程序手续费统计失败: %w", err)
+	}
+	var fees []models.ProgramFees
+	for i, hour := range hours {
+		fees = append(fees, decodeProgramFees(hour, results[i].Val(), program)...)
+	}
+	return fees, nil
+}
+
+// decodeProgramFees 将一个小时的Hash字段还原为各程序的统计，按程序ID排序，program 不为空时只返回该程序
+func decodeProgramFees(hour int64, fields map[string]string, program string) []models.ProgramFees {
+	byProgram := make(map[string]*models.ProgramFees)
+	var programs []string
+	for field, value := range fields {
+		name, metric, ok := strings.Cut(field, ":")
+		if !ok || (program != "" && name != program) {
+			continue
+		}
+		fee, ok := byProgram[name]
+		if !ok {
+			fee = &models.ProgramFees{Program: name, Hour: hour}
+			byProgram[name] = fee
+			programs = append(programs, name)
+		}
+		count, _ := strconv.ParseInt(value, 10, 64)
+		switch metric {
+		case "tx":
+			fee.Transactions = count
+		case "failed":
+			fee.Failed = count
+		case "prioritized":
+			fee.Prioritized = count
+		case "fee":
+			fee.Fees = count
+		case "priority":
+			fee.PriorityFees = count
+		case "cu":
+			fee.ComputeUnits = count
+		case "price":
+			fee.UnitPriceSum = count
+		}
+	}
+	slices.Sort(programs)
+	fees := make([]models.ProgramFees, 0, len(programs))
+	for _, name := range programs {
+		fees = append(fees, *byProgram[name])
+	}
+	return fees
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+)
+
+func TestProgramFeesRoundTrip(t *testing.T) {
+	client, server := newTestRedis(t)
+	ctx := context.Background()
+	hour := time.Now().Truncate(time.Hour).Unix()
+
+	fees := []models.ProgramFees{
+		{Program: "jup", Hour: hour, Transactions: 3, Failed: 1, Prioritized: 2, Fees: 30000, PriorityFees: 15000, ComputeUnits: 600000, UnitPriceSum: 2000},
+		{Program: "amm", Hour: hour, Transactions: 1, Fees: 5000, ComputeUnits: 100000},
+		{Program: "jup", Hour: hour + 7200, Transactions: 1, Fees: 5000},
+	}
+	if err := client.IncrProgramFees(ctx, fees, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// 同一小时再次累加
+	if err := client.IncrProgramFees(ctx, fees[:1], 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL(getProgramFeesKey(hour)); ttl <= 0 {
+		t.Fatalf("小时统计应设置过期时间，实际 %s", ttl)
+	}
+
+	got, err := client.GetProgramFees(ctx, hour+1800, hour+7200, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.ProgramFees{
+		{Program: "amm", Hour: hour, Transactions: 1, Fees: 5000, ComputeUnits: 100000},
+		{Program: "jup", Hour: hour, Transactions: 6, Failed: 2, Prioritized: 4, Fees: 60000, PriorityFees: 30000, ComputeUnits: 1200000, UnitPriceSum: 4000},
+		{Program: "jup", Hour: hour + 7200, Transactions: 1, Fees: 5000},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("统计: %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("第 %d 条统计 %+v，期望 %+v", i, got[i], want[i])
+		}
+	}
+
+	got, err = client.GetProgramFees(ctx, hour, hour+3600, "amm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Program != "amm" {
+		t.Fatalf("按程序过滤的统计: %+v", got)
+	}
+	if got, err := client.GetProgramFees(ctx, hour+7200, hour, ""); err != nil || len(got) != 0 {
+		t.Fatalf("空时间范围: %+v %v", got, err)
+	}
+}
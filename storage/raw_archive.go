@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/clock"
+)
+
+const (
+	// Enhanced API原始响应的键前缀，后接交易签名
+	RawTransactionKeyPrefix = "raw:tx:"
+)
+
+// ErrRawTransactionNotFound 找不到原始响应
+var ErrRawTransactionNotFound = errors.New("找不到交易原始响应")
+
+// RawTransactionRecord 表示归档的原始响应
+type RawTransactionRecord struct {
+	Signature  string          `json:"signature"`   // 交易签名，与解析记录关联
+	Slot       uint64          `json:"slot"`        // 所属区块高度
+	Compressed bool            `json:"compressed"`  // 是否经过gzip压缩
+	Data       []byte          `json:"data"`        // 原始JSON(压缩时为gzip数据)
+	CreateTime int64           `json:"create_time"` // 归档时间(Unix时间戳)
+	Raw        json.RawMessage `json:"-"`           // 解压后的原始JSON，仅读取时填充
+}
+
+// 获取原始响应的键名
+func getRawTransactionKey(signature string) string {
+	return Key(RawTransactionKeyPrefix) + signature
+}
+
+// StoreRawTransaction 保存单笔交易的Enhanced API原始响应
+// 参数:
+//   - ctx: 上下文
+//   - signature: 交易签名
+//   - slot: 区块高度
+//   - raw: 原始JSON
+//   - compress: 是否使用gzip压缩
+//   - expiration: 过期时间，如果为0则不设置过期时间
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreRawTransaction(ctx context.Context, signature string, slot uint64, raw json.RawMessage, compress bool, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if signature == "" {
+		return errors.New("交易签名不能为空")
+	}
+
+	data := []byte(raw)
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(raw); err != nil {
+			return fmt.Errorf("压缩原始响应失败: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("压缩原始响应失败: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	record := RawTransactionRecord{
+		Signature:  signature,
+		Slot:       slot,
+		Compressed: compress,
+		Data:       data,
+		CreateTime: clock.Now().Unix(),
+	}
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化原始响应失败: %w", err)
+	}
+
+	if err := r.client.Set(ctx, getRawTransactionKey(signature), recordJSON, expiration).Err(); err != nil {
+		return fmt.Errorf("存储原始响应失败: %w", err)
+	}
+	return nil
+}
+
+// GetRawTransaction 根据交易签名读取归档的原始响应，返回的记录中Raw为解压后的JSON
+// 参数:
+//   - ctx: 上下文
+//   - signature: 交易签名
+//
+// 返回:
+//   - *RawTransactionRecord: 原始响应记录
+//   - error: 错误信息
+func (r *RedisClient) GetRawTransaction(ctx context.Context, signature string) (*RawTransactionRecord, error) {
+	recordJSON, err := r.client.Get(ctx, getRawTransactionKey(signature)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrRawTransactionNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("获取原始响应失败: %w", err)
+	}
+
+	var record RawTransactionRecord
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, fmt.Errorf("解析原始响应记录失败: %w", err)
+	}
+
+	if !record.Compressed {
+		record.Raw = record.Data
+		return &record, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(record.Data))
+	if err != nil {
+		return nil, fmt.Errorf("解压原始响应失败: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("解压原始响应失败: %w", err)
+	}
+	record.Raw = raw
+	return &record, nil
+}
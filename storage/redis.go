@@ -11,23 +11,25 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/life2you/datas-go/clock"
 	"github.com/life2you/datas-go/configs"
 	"github.com/life2you/datas-go/logger"
 )
 
+// 本包的键名常量都不含命名空间前缀，读写时通过 Key 拼接 redis.key_prefix
 const (
 	// 区块有序集合的键名
-	BlocksZSetKey = "solana:blocks:sorted"
+	BlocksZSetKey = "blocks:sorted"
 	// 区块详情Hash表的前缀
-	BlockHashPrefix = "solana:block:"
+	BlockHashPrefix = "block:"
 	// 区块数据过期时间(30天)
 	BlockExpiration = 30 * 24 * time.Hour
 	// 默认扫描批次大小
 	DefaultScanCount = 100
 	// 交易签名队列前缀 (按区块划分)
-	TransactionQueueKeyPrefix = "solana:transaction:queue"
+	TransactionQueueKeyPrefix = "transaction:queue"
 	// 区块处理记录集合
-	ProcessedBlocksKey = "solana:blocks:processed"
+	ProcessedBlocksKey = "blocks:processed"
 )
 
 // 定义常见错误
@@ -51,7 +53,8 @@ var (
 
 // RedisClient 包装Redis客户端
 type RedisClient struct {
-	client *redis.Client
+	client   *redis.Client
+	degraded *degradedState // 不可用时的处理策略与待重放的写入
 }
 
 func (r *RedisClient) GetClient() *redis.Client {
@@ -60,6 +63,8 @@ func (r *RedisClient) GetClient() *redis.Client {
 
 // NewRedisClient 创建新的Redis客户端
 func NewRedisClient(options *configs.RedisConfig) {
+	SetKeyPrefix(options.KeyPrefix)
+
 	client := redis.NewClient(&redis.Options{
 		Addr:     options.Addr,
 		Password: options.Password,
@@ -67,25 +72,50 @@ func NewRedisClient(options *configs.RedisConfig) {
 		PoolSize: options.PoolSize,
 	})
 
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	GlobalRedisClient = newRedisClient("default", client, options.Degraded)
 
-	_, err := client.Ping(ctx).Result()
-	if err != nil {
-		panic(fmt.Errorf("%w: %v", ErrRedisConnection, err))
+	// 按负载拆分的客户端
+	newWorkloadRedisClients(options)
+}
+
+// newRedisClient 注册不可用处理策略并测试连接
+// 连接失败时 crash 策略直接panic，其他策略以不可用状态启动，恢复后自动重放期间的写入
+func newRedisClient(name string, client *redis.Client, config configs.RedisDegradedConfig) *RedisClient {
+	redisClient := &RedisClient{
+		client:   client,
+		degraded: newDegradedState(name, client, config),
 	}
 
-	GlobalRedisClient = &RedisClient{
-		client: client,
+	// 测试连接
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), bypassKey{}, true), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		if redisClient.degraded.policy == DegradedPolicyCrash {
+			panic(fmt.Errorf("%w (%s): %v", ErrRedisConnection, name, err))
+		}
+		redisClient.degraded.markDegraded(err)
 	}
+	return redisClient
 }
 
-// Close 关闭Redis连接
+// Close 关闭Redis连接，仍有未重放的写入时记录警告
 func (r *RedisClient) Close() error {
+	if r.degraded != nil {
+		if buffered := r.degraded.Status().Buffered; buffered > 0 {
+			r.degraded.log.Warn("关闭时仍有未重放的写入，这些写入将丢失", zap.Int("buffered", buffered))
+		}
+	}
 	return r.client.Close()
 }
 
+// Ping 检查Redis连接是否可用
+func (r *RedisClient) Ping(ctx context.Context) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	return r.client.Ping(ctx).Err()
+}
+
 // StoreBlock 存储区块数据到Redis
 // 参数:
 //   - ctx: 上下文
@@ -100,7 +130,7 @@ func (r *RedisClient) StoreBlock(ctx context.Context, slot uint64) error {
 	pipe := r.client.Pipeline()
 
 	// 1. 将区块高度添加到有序集合，score为区块高度
-	pipe.ZAdd(ctx, BlocksZSetKey, redis.Z{
+	pipe.ZAdd(ctx, Key(BlocksZSetKey), redis.Z{
 		Score:  float64(slot),
 		Member: slot,
 	})
@@ -122,7 +152,7 @@ func (r *RedisClient) StoreBlock(ctx context.Context, slot uint64) error {
 //   - error: 错误信息
 func (r *RedisClient) GetBlockBySlot(ctx context.Context, slot uint64) (*rpc.GetBlockResult, error) {
 	// 构建区块详情的键
-	blockKey := fmt.Sprintf("%s%d", BlockHashPrefix, slot)
+	blockKey := fmt.Sprintf("%s%d", Key(BlockHashPrefix), slot)
 
 	// 从Redis获取区块数据
 	blockJSON, err := r.client.Get(ctx, blockKey).Result()
@@ -150,7 +180,7 @@ func (r *RedisClient) GetBlockBySlot(ctx context.Context, slot uint64) (*rpc.Get
 //   - error: 错误信息
 func (r *RedisClient) GetMinBlock(ctx context.Context) (uint64, error) {
 	// 使用ZRANGE获取最小score的元素(即最小区块高度)
-	slots, err := r.client.ZRange(ctx, BlocksZSetKey, 0, 0).Result()
+	slots, err := r.client.ZRange(ctx, Key(BlocksZSetKey), 0, 0).Result()
 	if err != nil {
 		return 0, fmt.Errorf("获取最小区块高度失败: %w", err)
 	}
@@ -167,7 +197,7 @@ func (r *RedisClient) GetMinBlock(ctx context.Context) (uint64, error) {
 
 	// 从有序集合中移除该区块
 	// 使用原始字符串格式的成员进行删除，与添加时的格式保持一致
-	_, err = r.client.ZRem(ctx, BlocksZSetKey, slots[0]).Result()
+	_, err = r.client.ZRem(ctx, Key(BlocksZSetKey), slots[0]).Result()
 	if err != nil {
 		return 0, fmt.Errorf("移除最小区块失败: %w", err)
 	}
@@ -184,7 +214,7 @@ func (r *RedisClient) GetMinBlock(ctx context.Context) (uint64, error) {
 //   - error: 错误信息
 func (r *RedisClient) GetMaxBlock(ctx context.Context) (uint64, *rpc.GetBlockResult, error) {
 	// 使用ZRANGE获取最大score的元素(即最大区块高度)，使用ZREVRANGE获取倒序第一个元素
-	slots, err := r.client.ZRevRange(ctx, BlocksZSetKey, 0, 0).Result()
+	slots, err := r.client.ZRevRange(ctx, Key(BlocksZSetKey), 0, 0).Result()
 	if err != nil {
 		return 0, nil, fmt.Errorf("获取最大区块高度失败: %w", err)
 	}
@@ -217,13 +247,13 @@ func (r *RedisClient) GetMaxBlock(ctx context.Context) (uint64, *rpc.GetBlockRes
 //   - error: 错误信息
 func (r *RedisClient) RemoveBlock(ctx context.Context, slot uint64) error {
 	// 区块详情的Hash键
-	blockKey := fmt.Sprintf("%s%d", BlockHashPrefix, slot)
+	blockKey := fmt.Sprintf("%s%d", Key(BlockHashPrefix), slot)
 
 	// 使用管道执行多个命令
 	pipe := r.client.Pipeline()
 
 	// 1. 从有序集合中移除区块高度
-	pipe.ZRem(ctx, BlocksZSetKey, slot)
+	pipe.ZRem(ctx, Key(BlocksZSetKey), slot)
 
 	// 2. 删除区块详情
 	pipe.Del(ctx, blockKey)
@@ -247,7 +277,7 @@ func (r *RedisClient) RemoveBlock(ctx context.Context, slot uint64) error {
 //   - error: 错误信息
 func (r *RedisClient) BlockExists(ctx context.Context, slot uint64) (bool, error) {
 	// 检查区块是否存在于有序集合中
-	exists, err := r.client.ZScore(ctx, BlocksZSetKey, fmt.Sprintf("%d", slot)).Result()
+	exists, err := r.client.ZScore(ctx, Key(BlocksZSetKey), fmt.Sprintf("%d", slot)).Result()
 	if err == redis.Nil {
 		return false, nil
 	} else if err != nil {
@@ -265,7 +295,7 @@ func (r *RedisClient) BlockExists(ctx context.Context, slot uint64) (bool, error
 //   - int64: 区块数量
 //   - error: 错误信息
 func (r *RedisClient) GetBlockCount(ctx context.Context) (int64, error) {
-	count, err := r.client.ZCard(ctx, BlocksZSetKey).Result()
+	count, err := r.client.ZCard(ctx, Key(BlocksZSetKey)).Result()
 	if err != nil {
 		return 0, fmt.Errorf("获取区块数量失败: %w", err)
 	}
@@ -283,7 +313,7 @@ func (r *RedisClient) GetBlockCount(ctx context.Context) (int64, error) {
 //   - error: 错误信息
 func (r *RedisClient) GetBlocksRange(ctx context.Context, start, stop int64) ([]uint64, error) {
 	// 从有序集合中获取指定范围的元素
-	slotsStr, err := r.client.ZRange(ctx, BlocksZSetKey, start, stop).Result()
+	slotsStr, err := r.client.ZRange(ctx, Key(BlocksZSetKey), start, stop).Result()
 	if err != nil {
 		return nil, fmt.Errorf("获取区块范围失败: %w", err)
 	}
@@ -309,7 +339,7 @@ func (r *RedisClient) GetBlocksRange(ctx context.Context, start, stop int64) ([]
 //   - error: 错误信息
 func (r *RedisClient) ClearBlocks(ctx context.Context) error {
 	// 获取所有区块高度
-	slots, err := r.client.ZRange(ctx, BlocksZSetKey, 0, -1).Result()
+	slots, err := r.client.ZRange(ctx, Key(BlocksZSetKey), 0, -1).Result()
 	if err != nil {
 		return fmt.Errorf("获取所有区块高度失败: %w", err)
 	}
@@ -321,7 +351,7 @@ func (r *RedisClient) ClearBlocks(ctx context.Context) error {
 	// 构建所有区块详情的键
 	blockKeys := make([]string, 0, len(slots))
 	for _, slot := range slots {
-		blockKey := fmt.Sprintf("%s%s", BlockHashPrefix, slot)
+		blockKey := fmt.Sprintf("%s%s", Key(BlockHashPrefix), slot)
 		blockKeys = append(blockKeys, blockKey)
 	}
 
@@ -329,7 +359,7 @@ func (r *RedisClient) ClearBlocks(ctx context.Context) error {
 	pipe := r.client.Pipeline()
 
 	// 1. 删除有序集合
-	pipe.Del(ctx, BlocksZSetKey)
+	pipe.Del(ctx, Key(BlocksZSetKey))
 
 	// 2. 删除所有区块详情
 	if len(blockKeys) > 0 {
@@ -363,7 +393,7 @@ func (r *RedisClient) StoreHash(ctx context.Context, key string, field string, v
 	}
 
 	// 构建Redis键名
-	redisKey := fmt.Sprintf("solana:hash:%s", key)
+	redisKey := Key("hash:" + key)
 
 	// 使用管道执行多个命令以提高性能
 	pipe := r.client.Pipeline()
@@ -395,7 +425,7 @@ type TransactionItem struct {
 
 // 获取区块对应的队列键名
 func getBlockQueueKey(blockSlot uint64) string {
-	return fmt.Sprintf("%s", TransactionQueueKeyPrefix)
+	return fmt.Sprintf("%s", Key(TransactionQueueKeyPrefix))
 }
 
 // PushTransactionsForBlock 将交易签名存入指定区块的队列
@@ -415,13 +445,13 @@ func (r *RedisClient) PushTransactionsForBlock(ctx context.Context, blockSlot ui
 	queueKey := getBlockQueueKey(blockSlot)
 
 	// 将区块添加到处理记录
-	_, err := r.client.SAdd(ctx, ProcessedBlocksKey, blockSlot).Result()
+	_, err := r.client.SAdd(ctx, Key(ProcessedBlocksKey), blockSlot).Result()
 	if err != nil {
 		return fmt.Errorf("添加区块处理记录失败: %w", err)
 	}
 
 	// 当前时间戳
-	now := time.Now().Unix()
+	now := clock.Now().Unix()
 
 	// 使用管道执行多个命令
 	pipe := r.client.Pipeline()
@@ -458,7 +488,7 @@ func (r *RedisClient) PushTransactionsForBlock(ctx context.Context, blockSlot ui
 //   - error: 错误信息
 func (r *RedisClient) LPopTransactionQueue(ctx context.Context) (*TransactionItem, error) {
 	// 从队列中获取一个元素
-	itemJSON, err := r.client.LPop(ctx, TransactionQueueKeyPrefix).Result()
+	itemJSON, err := r.client.LPop(ctx, Key(TransactionQueueKeyPrefix)).Result()
 	if err == redis.Nil {
 		// 队列为空
 		return nil, nil
@@ -570,7 +600,7 @@ func (r *RedisClient) GetTransactionsFromBlock(ctx context.Context, blockSlot ui
 //   - int64: 队列长度
 //   - error: 错误信息
 func (r *RedisClient) GetTransactionQueueLength(ctx context.Context) (int64, error) {
-	length, err := r.client.LLen(ctx, TransactionQueueKeyPrefix).Result()
+	length, err := r.client.LLen(ctx, Key(TransactionQueueKeyPrefix)).Result()
 	if err != nil {
 		return 0, fmt.Errorf("获取交易队列长度失败: %w", err)
 	}
@@ -585,7 +615,7 @@ func (r *RedisClient) GetTransactionQueueLength(ctx context.Context) (int64, err
 // 返回:
 //   - error: 错误信息
 func (r *RedisClient) RemoveProcessedBlock(ctx context.Context, blockSlot uint64) error {
-	_, err := r.client.SRem(ctx, ProcessedBlocksKey, blockSlot).Result()
+	_, err := r.client.SRem(ctx, Key(ProcessedBlocksKey), blockSlot).Result()
 	if err != nil {
 		return fmt.Errorf("从已处理区块集合移除区块失败: %w", err)
 	}
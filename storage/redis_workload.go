@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/configs"
+)
+
+// Workload 表示Redis负载类型，不同负载可以使用独立的实例、数据库和连接池
+type Workload string
+
+const (
+	WorkloadQueue     Workload = "queue"     // 队列、死信队列
+	WorkloadCache     Workload = "cache"     // 原始响应归档等缓存数据
+	WorkloadAnalytics Workload = "analytics" // 解析结果与统计数据
+)
+
+// 各负载独立的Redis客户端，未配置的负载使用GlobalRedisClient
+var workloadRedisClients = make(map[Workload]*RedisClient)
+
+// newWorkloadRedisClients 根据配置创建各负载的Redis客户端
+func newWorkloadRedisClients(options *configs.RedisConfig) {
+	for name, workloadConfig := range options.Workloads {
+		addr := workloadConfig.Addr
+		password := workloadConfig.Password
+		if addr == "" {
+			addr = options.Addr
+			if password == "" {
+				password = options.Password
+			}
+		}
+		db := options.DB
+		if workloadConfig.DB != nil {
+			db = *workloadConfig.DB
+		}
+		poolSize := workloadConfig.PoolSize
+		if poolSize == 0 {
+			poolSize = options.PoolSize
+		}
+
+		client := redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+			PoolSize: poolSize,
+		})
+
+		workloadRedisClients[Workload(name)] = newRedisClient(name, client, options.Degraded)
+	}
+}
+
+// GetRedisClient 获取指定负载的Redis客户端，未单独配置时返回默认客户端
+func GetRedisClient(workload Workload) *RedisClient {
+	if client, ok := workloadRedisClients[workload]; ok {
+		return client
+	}
+	return GlobalRedisClient
+}
+
+// CloseRedisClients 关闭默认客户端及所有负载客户端
+func CloseRedisClients() {
+	for _, client := range workloadRedisClients {
+		client.Close()
+	}
+	if GlobalRedisClient != nil {
+		GlobalRedisClient.Close()
+	}
+}
+
+// RedisClients 返回默认客户端及所有负载客户端，键为负载名称，默认客户端为 default
+func RedisClients() map[string]*RedisClient {
+	clients := make(map[string]*RedisClient, len(workloadRedisClients)+1)
+	if GlobalRedisClient != nil {
+		clients["default"] = GlobalRedisClient
+	}
+	for workload, client := range workloadRedisClients {
+		clients[string(workload)] = client
+	}
+	return clients
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models"
+)
+
+// DefaultRetentionPolicies 没有配置 retention.policies 时使用的内置策略
+// 区块数据和旧版交易哈希写入时没有设置过期时间，按 BlockExpiration 补设过期时间
+func DefaultRetentionPolicies() []configs.RetentionPolicy {
+	return []configs.RetentionPolicy{
+		{Name: "block_data", Pattern: BlockHashPrefix + "[0-9]*", Action: models.RetentionActionExpire, MaxAge: BlockExpiration},
+		{Name: "legacy_hash", Pattern: legacyTransactionHashPrefix + "*", Action: models.RetentionActionExpire, MaxAge: BlockExpiration},
+	}
+}
+
+// RetentionOptions 数据保留选项
+type RetentionOptions struct {
+	BatchSize int64 // 每次SCAN的数量
+	Samples   int   // 结果中列出的键名样例数
+	DryRun    bool  // 只统计会被处理的键，不修改数据
+}
+
+// ApplyRetention 按配置的策略依次处理各负载中的旧键，单个策略失败时继续执行其余策略
+// 参数:
+//   - config: 数据保留配置，没有配置策略时使用 DefaultRetentionPolicies
+//   - dryRun: 是否试运行，与 config.DryRun 任一为true时不修改数据
+//
+// 返回:
+//   - models.RetentionReport: 各策略的执行结果
+//   - error: 所有失败策略的错误
+func ApplyRetention(ctx context.Context, config *configs.RetentionConfig, dryRun bool) (models.RetentionReport, error) {
+	policies := config.Policies
+	if len(policies) == 0 {
+		policies = DefaultRetentionPolicies()
+	}
+	options := RetentionOptions{
+		BatchSize: config.BatchSize,
+		Samples:   config.Samples,
+		DryRun:    dryRun || config.DryRun,
+	}
+	report := models.RetentionReport{
+		DryRun:    options.DryRun,
+		StartedAt: clock.Now(),
+		Policies:  make([]models.RetentionResult, 0, len(policies)),
+	}
+	var errs []error
+	for _, policy := range policies {
+		result, err := GetRedisClient(Workload(policy.Workload)).ApplyRetentionPolicy(ctx, policy, options)
+		if err != nil {
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("保留策略 %s: %w", policy.Name, err))
+		}
+		report.Policies = append(report.Policies, result)
+	}
+	report.DurationMs = clock.Now().Sub(report.StartedAt).Milliseconds()
+	return report, errors.Join(errs...)
+}
+
+// ApplyRetentionPolicy 扫描匹配策略的键并按策略处理
+// 参数:
+//   - policy: 保留策略
+//   - options: 数据保留选项
+//
+// 返回:
+//   - models.RetentionResult: 执行结果，出错时包含出错前已处理的数量
+//   - error: 错误信息
+func (r *RedisClient) ApplyRetentionPolicy(ctx context.Context, policy configs.RetentionPolicy, options RetentionOptions) (models.RetentionResult, error) {
+	result := models.RetentionResult{
+		Policy:   policy.Name,
+		Workload: policy.Workload,
+		Pattern:  policy.Pattern,
+		Action:   policy.Action,
+	}
+	if r == nil || r.client == nil {
+		return result, errors.New("Redis 客户端尚未初始化")
+	}
+	batchSize := cmp.Or(options.BatchSize, DefaultScanCount)
+
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, Key(policy.Pattern), batchSize).Result()
+		if err != nil {
+			return result, fmt.Errorf("扫描键失败: %w", err)
+		}
+		result.Scanned += int64(len(keys))
+		if len(keys) > 0 {
+			if err := r.applyRetentionBatch(ctx, policy, options, keys, &result); err != nil {
+				return result, err
+			}
+		}
+		if next == 0 {
+			return result, nil
+		}
+		cursor = next
+	}
+}
+
+// applyRetentionBatch 处理一批扫描到的键
+func (r *RedisClient) applyRetentionBatch(ctx context.Context, policy configs.RetentionPolicy, options RetentionOptions, keys []string, result *models.RetentionResult) error {
+	switch policy.Action {
+	case models.RetentionActionExpire:
+		return r.expireRetentionKeys(ctx, policy, options, keys, result)
+	case models.RetentionActionDelete:
+		return r.deleteRetentionKeys(ctx, policy, options, keys, result)
+	case models.RetentionActionTrim:
+		return r.trimRetentionKeys(ctx, policy, options, keys, result)
+	default:
+		return fmt.Errorf("未知的保留策略处理方式: %s", policy.Action)
+	}
+}
+
+// expireRetentionKeys 为没有过期时间的键设置过期时间，已有过期时间的键不变
+func (r *RedisClient) expireRetentionKeys(ctx context.Context, policy configs.RetentionPolicy, options RetentionOptions, keys []string, result *models.RetentionResult) error {
+	ttls, err := r.retentionTTLs(ctx, keys)
+	if err != nil {
+		return err
+	}
+	matched := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if ttls[i].Val() == -1 {
+			matched = append(matched, key)
+		}
+	}
+	result.Matched += int64(len(matched))
+	addRetentionSamples(result, matched, options.Samples)
+	if len(matched) == 0 {
+		return nil
+	}
+	if options.DryRun {
+		result.Expired += int64(len(matched))
+		return nil
+	}
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.BoolCmd, len(matched))
+	for i, key := range matched {
+		cmds[i] = pipe.Expire(ctx, key, policy.MaxAge)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("设置过期时间失败: %w", err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Val() {
+			result.Expired++
+		}
+	}
+	return nil
+}
+
+// deleteRetentionKeys 删除没有过期时间且超过 max_age 未被访问的键
+// 空闲时间来自 OBJECT IDLETIME，Redis使用LFU淘汰策略时不可用
+func (r *RedisClient) deleteRetentionKeys(ctx context.Context, policy configs.RetentionPolicy, options RetentionOptions, keys []string, result *models.RetentionResult) error {
+	pipe := r.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	idles := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.TTL(ctx, key)
+		idles[i] = pipe.ObjectIdleTime(ctx, key)
+	}
+	// 扫描之后被删除的键 OBJECT IDLETIME 返回nil
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("查询键的空闲时间失败: %w", err)
+	}
+	matched := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if ttls[i].Val() == -1 && idles[i].Err() == nil && idles[i].Val() >= policy.MaxAge {
+			matched = append(matched, key)
+		}
+	}
+	result.Matched += int64(len(matched))
+	addRetentionSamples(result, matched, options.Samples)
+	if len(matched) == 0 {
+		return nil
+	}
+	if options.DryRun {
+		result.Deleted += int64(len(matched))
+		return nil
+	}
+	deleted, err := r.client.Unlink(ctx, matched...).Result()
+	if err != nil {
+		return fmt.Errorf("删除键失败: %w", err)
+	}
+	result.Deleted += deleted
+	return nil
+}
+
+// trimRetentionKeys 将超过 max_len 的 ZSet 裁剪为分数最高的 max_len 个元素，List 裁剪为头部的 max_len 个元素，其他类型的键不处理
+func (r *RedisClient) trimRetentionKeys(ctx context.Context, policy configs.RetentionPolicy, options RetentionOptions, keys []string, result *models.RetentionResult) error {
+	pipe := r.client.Pipeline()
+	types := make([]*redis.StatusCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("查询键的类型失败: %w", err)
+	}
+	pipe = r.client.Pipeline()
+	lengths := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		switch types[i].Val() {
+		case "zset":
+			lengths[i] = pipe.ZCard(ctx, key)
+		case "list":
+			lengths[i] = pipe.LLen(ctx, key)
+		}
+	}
+	if pipe.Len() == 0 {
+		return nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("查询键的长度失败: %w", err)
+	}
+
+	matched := make([]string, 0, len(keys))
+	pipe = r.client.Pipeline()
+	for i, key := range keys {
+		if lengths[i] == nil || lengths[i].Val() <= policy.MaxLen {
+			continue
+		}
+		matched = append(matched, key)
+		result.Trimmed += lengths[i].Val() - policy.MaxLen
+		if types[i].Val() == "zset" {
+			pipe.ZRemRangeByRank(ctx, key, 0, -policy.MaxLen-1)
+		} else {
+			pipe.LTrim(ctx, key, 0, policy.MaxLen-1)
+		}
+	}
+	result.Matched += int64(len(matched))
+	addRetentionSamples(result, matched, options.Samples)
+	if options.DryRun || len(matched) == 0 {
+		return nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("裁剪键失败: %w", err)
+	}
+	return nil
+}
+
+// retentionTTLs 批量查询键的剩余过期时间，没有过期时间的键为-1
+func (r *RedisClient) retentionTTLs(ctx context.Context, keys []string) ([]*redis.DurationCmd, error) {
+	pipe := r.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("查询键的过期时间失败: %w", err)
+	}
+	return ttls, nil
+}
+
+// addRetentionSamples 记录需要处理的键名样例，最多 limit 个
+func addRetentionSamples(result *models.RetentionResult, keys []string, limit int) {
+	for _, key := range keys {
+		if len(result.Samples) >= limit {
+			return
+		}
+		result.Samples = append(result.Samples, key)
+	}
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 规则Hash表的键名，字段为规则ID，值为规则JSON
+	RulesHashKey = "rules"
+	// 规则变更通知频道，规则增删改后发布，各实例收到后重新加载规则
+	RulesChangedChannel = "rules:changed"
+	// 告警记录列表的键名，最新的告警位于列表头部
+	AlertsListKey = "alerts"
+)
+
+// SaveRule 保存规则并通知其他实例
+// 参数:
+//   - ctx: 上下文
+//   - id: 规则ID
+//   - rule: 规则JSON
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) SaveRule(ctx context.Context, id string, rule json.RawMessage) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := r.client.HSet(ctx, Key(RulesHashKey), id, []byte(rule)).Err(); err != nil {
+		return fmt.Errorf("保存规则失败: %w", err)
+	}
+	r.client.Publish(ctx, Key(RulesChangedChannel), id)
+	return nil
+}
+
+// DeleteRule 删除规则并通知其他实例
+// 参数:
+//   - ctx: 上下文
+//   - id: 规则ID
+//
+// 返回:
+//   - bool: 规则是否存在
+//   - error: 错误信息
+func (r *RedisClient) DeleteRule(ctx context.Context, id string) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	deleted, err := r.client.HDel(ctx, Key(RulesHashKey), id).Result()
+	if err != nil {
+		return false, fmt.Errorf("删除规则失败: %w", err)
+	}
+	if deleted > 0 {
+		r.client.Publish(ctx, Key(RulesChangedChannel), id)
+	}
+	return deleted > 0, nil
+}
+
+// GetRules 获取所有规则
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - map[string]json.RawMessage: 规则ID到规则JSON的映射
+//   - error: 错误信息
+func (r *RedisClient) GetRules(ctx context.Context) (map[string]json.RawMessage, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.HGetAll(ctx, Key(RulesHashKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取规则失败: %w", err)
+	}
+	rules := make(map[string]json.RawMessage, len(values))
+	for id, value := range values {
+		rules[id] = json.RawMessage(value)
+	}
+	return rules, nil
+}
+
+// SubscribeRuleChanges 订阅规则变更通知
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *redis.PubSub: 订阅对象，使用完毕后需关闭
+func (r *RedisClient) SubscribeRuleChanges(ctx context.Context) *redis.PubSub {
+	return r.client.Subscribe(ctx, Key(RulesChangedChannel))
+}
+
+// PublishMessage 向Redis频道发布消息，用于规则路由
+// 参数:
+//   - ctx: 上下文
+//   - channel: 频道名
+//   - message: 消息内容
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) PublishMessage(ctx context.Context, channel string, message []byte) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := r.client.Publish(ctx, channel, message).Err(); err != nil {
+		return fmt.Errorf("发布消息失败: %w", err)
+	}
+	return nil
+}
+
+// PushAlert 记录告警，列表超过maxLength时删除最旧的告警
+// 参数:
+//   - ctx: 上下文
+//   - alert: 告警JSON
+//   - maxLength: 最多保留的告警数量
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) PushAlert(ctx context.Context, alert json.RawMessage, maxLength int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	pipe := r.client.TxPipeline()
+	pipe.LPush(ctx, Key(AlertsListKey), []byte(alert))
+	if maxLength > 0 {
+		pipe.LTrim(ctx, Key(AlertsListKey), 0, maxLength-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录告警失败: %w", err)
+	}
+	return nil
+}
+
+// GetAlerts 获取最近的告警
+// 参数:
+//   - ctx: 上下文
+//   - count: 最大数量
+//
+// 返回:
+//   - []json.RawMessage: 告警JSON列表，按时间倒序
+//   - error: 错误信息
+func (r *RedisClient) GetAlerts(ctx context.Context, count int64) ([]json.RawMessage, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.LRange(ctx, Key(AlertsListKey), 0, count-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取告警失败: %w", err)
+	}
+	alerts := make([]json.RawMessage, 0, len(values))
+	for _, value := range values {
+		alerts = append(alerts, json.RawMessage(value))
+	}
+	return alerts, nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// SkippedSlotsKey 跳过列表的ZSet键名，成员为槽位，分数为下一次重试的时间(Unix秒)，放弃自动重试的槽位为+inf
+	SkippedSlotsKey = "skipped_slots"
+	// SkippedSlotInfoKey 跳过列表详情的Hash键名，字段为槽位，值为 models.SkippedSlot 的JSON
+	SkippedSlotInfoKey = "skipped_slots:info"
+)
+
+// GetSkippedSlot 查询跳过列表中的槽位
+// 返回:
+//   - *models.SkippedSlot: 槽位详情，不在跳过列表中时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetSkippedSlot(ctx context.Context, slot uint64) (*models.SkippedSlot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := r.client.HGet(ctx, Key(SkippedSlotInfoKey), strconv.FormatUint(slot, 10)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询跳过的槽位失败: %w", err)
+	}
+	var skipped models.SkippedSlot
+	if err := json.Unmarshal(value, &skipped); err != nil {
+		return nil, fmt.Errorf("解析跳过的槽位失败: %w", err)
+	}
+	return &skipped, nil
+}
+
+// SaveSkippedSlot 写入跳过列表，按 NextRetryAt 安排下一次重试，为空时不再自动重试
+func (r *RedisClient) SaveSkippedSlot(ctx context.Context, skipped models.SkippedSlot) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	value, err := json.Marshal(skipped)
+	if err != nil {
+		return fmt.Errorf("序列化跳过的槽位失败: %w", err)
+	}
+	score := math.Inf(1)
+	if skipped.NextRetryAt != nil {
+		score = float64(skipped.NextRetryAt.Unix())
+	}
+	member := strconv.FormatUint(skipped.Slot, 10)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, Key(SkippedSlotInfoKey), member, value)
+	pipe.ZAdd(ctx, Key(SkippedSlotsKey), redis.Z{Score: score, Member: member})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入跳过列表失败: %w", err)
+	}
+	return nil
+}
+
+// ScheduleSkippedSlot 修改跳过列表中槽位的下一次重试时间，不修改详情
+func (r *RedisClient) ScheduleSkippedSlot(ctx context.Context, slot uint64, at time.Time) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	err := r.client.ZAddXX(ctx, Key(SkippedSlotsKey), redis.Z{Score: float64(at.Unix()), Member: strconv.FormatUint(slot, 10)}).Err()
+	if err != nil {
+		return fmt.Errorf("更新跳过的槽位的重试时间失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteSkippedSlot 从跳过列表中删除槽位
+// 返回:
+//   - bool: 槽位是否在跳过列表中
+//   - error: 错误信息
+func (r *RedisClient) DeleteSkippedSlot(ctx context.Context, slot uint64) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	member := strconv.FormatUint(slot, 10)
+	pipe := r.client.TxPipeline()
+	deleted := pipe.HDel(ctx, Key(SkippedSlotInfoKey), member)
+	pipe.ZRem(ctx, Key(SkippedSlotsKey), member)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("删除跳过的槽位失败: %w", err)
+	}
+	return deleted.Val() > 0, nil
+}
+
+// DueSkippedSlots 返回重试时间已到的槽位
+// 参数:
+//   - ctx: 上下文
+//   - now: 当前时间
+//   - limit: 最多返回的槽位数
+//
+// 返回:
+//   - []uint64: 按重试时间排列的槽位
+//   - error: 错误信息
+func (r *RedisClient) DueSkippedSlots(ctx context.Context, now time.Time, limit int64) ([]uint64, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	members, err := r.client.ZRangeByScore(ctx, Key(SkippedSlotsKey), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询需要重试的槽位失败: %w", err)
+	}
+	slots := make([]uint64, 0, len(members))
+	for _, member := range members {
+		if slot, err := strconv.ParseUint(member, 10, 64); err == nil {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// ListSkippedSlots 返回跳过列表中的所有槽位
+// 返回:
+//   - []models.SkippedSlot: 按槽位降序排列的槽位详情
+//   - error: 错误信息
+func (r *RedisClient) ListSkippedSlots(ctx context.Context) ([]models.SkippedSlot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.HVals(ctx, Key(SkippedSlotInfoKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询跳过列表失败: %w", err)
+	}
+	skipped := make([]models.SkippedSlot, 0, len(values))
+	for _, value := range values {
+		var entry models.SkippedSlot
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return nil, fmt.Errorf("解析跳过的槽位失败: %w", err)
+		}
+		skipped = append(skipped, entry)
+	}
+	slices.SortFunc(skipped, func(a, b models.SkippedSlot) int { return cmp.Compare(b.Slot, a.Slot) })
+	return skipped, nil
+}
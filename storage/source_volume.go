@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 按来源统计的小时数据键前缀，后接小时起始时间(Unix时间戳)
+	// 每个小时一个Hash，字段为 <来源>:tx、<来源>:swaps、<来源>:sol
+	SourceVolumeKeyPrefix = "sourcevolume:"
+)
+
+// 获取小时统计的键名
+func getSourceVolumeKey(hour int64) string {
+	return Key(SourceVolumeKeyPrefix) + strconv.FormatInt(hour, 10)
+}
+
+// IncrSourceVolumes 累加各来源在对应小时内的交易数和SOL成交量
+// 参数:
+//   - ctx: 上下文
+//   - volumes: 增量，Hour 为小时起始时间
+//   - retention: 保留时长，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) IncrSourceVolumes(ctx context.Context, volumes []models.SourceVolume, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(volumes) == 0 {
+		return nil
+	}
+	pipe := r.client.TxPipeline()
+	expired := make(map[int64]bool)
+	for _, volume := range volumes {
+		key := getSourceVolumeKey(volume.Hour)
+		if volume.Transactions != 0 {
+			pipe.HIncrBy(ctx, key, volume.Source+":tx", volume.Transactions)
+		}
+		if volume.Swaps != 0 {
+			pipe.HIncrBy(ctx, key, volume.Source+":swaps", volume.Swaps)
+		}
+		if volume.SOLVolume != 0 {
+			pipe.HIncrByFloat(ctx, key, volume.Source+":sol", volume.SOLVolume)
+		}
+		if retention > 0 && !expired[volume.Hour] {
+			expired[volume.Hour] = true
+			pipe.ExpireAt(ctx, key, time.Unix(volume.Hour, 0).Add(time.Hour+retention))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("累加来源统计失败: %w", err)
+	}
+	return nil
+}
+
+// GetSourceVolumes 获取时间范围内各小时、各来源的统计
+// 参数:
+//   - ctx: 上下文
+//   - since: 起始时间(Unix时间戳，按所在小时计算，包含)
+//   - until: 结束时间(Unix时间戳，按所在小时计算，包含)
+//
+// 返回:
+//   - []models.SourceVolume: 按小时升序的统计
+//   - error: 错误信息
+func (r *RedisClient) GetSourceVolumes(ctx context.Context, since, until int64) ([]models.SourceVolume, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	hourSeconds := int64(time.Hour.Seconds())
+	var volumes []models.SourceVolume
+	for hour := since - since%hourSeconds; hour <= until; hour += hourSeconds {
+		fields, err := r.client.HGetAll(ctx, getSourceVolumeKey(hour)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("获取来源统计失败: %w", err)
+		}
+		bySource := make(map[string]*models.SourceVolume)
+		var sources []string
+		for field, value := range fields {
+			source, metric, ok := strings.Cut(field, ":")
+			if !ok {
+				continue
+			}
+			volume, ok := bySource[source]
+			if !ok {
+				volume = &models.SourceVolume{Source: source, Hour: hour}
+				bySource[source] = volume
+				sources = append(sources, source)
+			}
+			switch metric {
+			case "tx":
+				volume.Transactions, _ = strconv.ParseInt(value, 10, 64)
+			case "swaps":
+				volume.Swaps, _ = strconv.ParseInt(value, 10, 64)
+			case "sol":
+				volume.SOLVolume, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		slices.Sort(sources)
+		for _, source := range sources {
+			volumes = append(volumes, *bySource[source])
+		}
+	}
+	return volumes, nil
+}
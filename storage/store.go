@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+)
+
+// BlockStore 等待获取的区块槽位队列，按槽位从小到大出队
+type BlockStore interface {
+	// PushBlock 将区块槽位推入队列
+	PushBlock(slot uint64)
+	// PopBlock 取出槽位最小的区块，队列为空时返回false
+	PopBlock() (uint64, bool)
+	// BlockQueueLen 返回队列中的区块数量
+	BlockQueueLen() int
+}
+
+// BlockPeeker 可以预先查看即将取出的区块的区块队列，用于预取区块
+type BlockPeeker interface {
+	// PeekBlocks 按出队顺序查看即将取出的至多n个区块，不从队列中移除
+	PeekBlocks(n int) []uint64
+}
+
+// TransactionQueueStore 等待解析的交易签名队列，按区块槽位从小到大出队
+type TransactionQueueStore interface {
+	// PushTransactions 将区块中需要解析的交易签名推入队列
+	PushTransactions(item models.TransactionQueueModel)
+	// PopTransactions 取出槽位最小的区块的交易签名，队列为空时返回false
+	PopTransactions() (models.TransactionQueueModel, bool)
+	// TransactionQueueLen 返回队列中的区块数量
+	TransactionQueueLen() int
+}
+
+// ResultStore 交易解析结果的存储
+type ResultStore interface {
+	// IndexTransaction 按来源/类型和代币按天索引交易签名，ttl 和 mintTTL 为当天结束后的保留时长，maxPerKey 为每个键的签名数上限
+	IndexTransaction(ctx context.Context, transaction models.IndexedTransaction, ttl, mintTTL time.Duration, maxPerKey int64) error
+	// GetEnrichedTransactions 按签名读取缓存的解析结果，未命中的签名不在结果中
+	GetEnrichedTransactions(ctx context.Context, signatures []string) (map[string]json.RawMessage, error)
+	// StoreEnrichedTransactions 缓存解析结果
+	StoreEnrichedTransactions(ctx context.Context, transactions map[string]json.RawMessage, expiration time.Duration) error
+	// StoreRawTransaction 归档Enhanced API原始响应
+	StoreRawTransaction(ctx context.Context, signature string, slot uint64, raw json.RawMessage, compress bool, expiration time.Duration) error
+	// RecordParseFailures 记录签名的解析失败，失败次数累加
+	RecordParseFailures(ctx context.Context, slot uint64, failures map[string]models.ParseFailureReason, expiration time.Duration) error
+	// GetParseFailures 按签名读取解析失败记录，没有记录的签名不在结果中
+	GetParseFailures(ctx context.Context, signatures []string) (map[string]models.ParseFailure, error)
+	// ClearParseFailures 删除签名的解析失败记录
+	ClearParseFailures(ctx context.Context, signatures []string) error
+	// StoreBlockMeta 按区块哈希保存区块元数据
+	StoreBlockMeta(ctx context.Context, meta models.BlockMeta, expiration time.Duration) error
+	// StoreBlockSummary 保存区块交易汇总，maxEntries 为最多保留的区块数
+	StoreBlockSummary(ctx context.Context, summary models.BlockSummary, maxEntries int64) error
+}
+
+// queueBlockStore 基于内存优先队列的区块队列
+type queueBlockStore struct {
+	queue *PriorityQueue[uint64]
+}
+
+// NewQueueBlockStore 使用内存优先队列作为区块队列，如 GlobalBlockQueue
+func NewQueueBlockStore(queue *PriorityQueue[uint64]) BlockStore {
+	return &queueBlockStore{queue: queue}
+}
+
+// PushBlock 将区块槽位推入队列
+func (s *queueBlockStore) PushBlock(slot uint64) {
+	s.queue.Push(slot, int64(slot))
+}
+
+// PopBlock 取出槽位最小的区块
+func (s *queueBlockStore) PopBlock() (uint64, bool) {
+	value, _, ok := s.queue.Pop()
+	return value, ok
+}
+
+// BlockQueueLen 返回队列中的区块数量
+func (s *queueBlockStore) BlockQueueLen() int {
+	return s.queue.Len()
+}
+
+// PeekBlocks 按出队顺序查看即将取出的至多n个区块
+func (s *queueBlockStore) PeekBlocks(n int) []uint64 {
+	return s.queue.PeekN(n)
+}
+
+// queueTransactionStore 基于内存优先队列的交易队列
+type queueTransactionStore struct {
+	queue *PriorityQueue[models.TransactionQueueModel]
+}
+
+// NewQueueTransactionStore 使用内存优先队列作为交易队列，如 GlobalTransactionQueue
+func NewQueueTransactionStore(queue *PriorityQueue[models.TransactionQueueModel]) TransactionQueueStore {
+	return &queueTransactionStore{queue: queue}
+}
+
+// NewDefaultTransactionStore 按 queue.backend 返回默认的交易队列：
+// 使用Redis Streams时为 GlobalTransactionStream，否则为基于 GlobalTransactionQueue 的内存队列
+func NewDefaultTransactionStore() TransactionQueueStore {
+	if GlobalTransactionStream != nil {
+		return GlobalTransactionStream
+	}
+	return NewQueueTransactionStore(GlobalTransactionQueue)
+}
+
+// PushTransactions 将区块中需要解析的交易签名推入队列
+func (s *queueTransactionStore) PushTransactions(item models.TransactionQueueModel) {
+	s.queue.Push(item, int64(item.Slot))
+}
+
+// PopTransactions 取出槽位最小的区块的交易签名
+func (s *queueTransactionStore) PopTransactions() (models.TransactionQueueModel, bool) {
+	value, _, ok := s.queue.Pop()
+	return value, ok
+}
+
+// TransactionQueueLen 返回队列中的区块数量
+func (s *queueTransactionStore) TransactionQueueLen() int {
+	return s.queue.Len()
+}
+
+// redisResultStore 按负载选择Redis实例的解析结果存储：索引写入analytics，缓存和归档写入cache
+type redisResultStore struct{}
+
+// NewRedisResultStore 使用按负载拆分的Redis实例保存解析结果
+func NewRedisResultStore() ResultStore {
+	return redisResultStore{}
+}
+
+// IndexTransaction 按来源/类型和代币按天索引交易签名
+func (redisResultStore) IndexTransaction(ctx context.Context, transaction models.IndexedTransaction, ttl, mintTTL time.Duration, maxPerKey int64) error {
+	return GetRedisClient(WorkloadAnalytics).IndexTransaction(ctx, transaction, ttl, mintTTL, maxPerKey)
+}
+
+// GetEnrichedTransactions 按签名读取缓存的解析结果
+func (redisResultStore) GetEnrichedTransactions(ctx context.Context, signatures []string) (map[string]json.RawMessage, error) {
+	return GetRedisClient(WorkloadCache).GetEnrichedTransactions(ctx, signatures)
+}
+
+// StoreEnrichedTransactions 缓存解析结果
+func (redisResultStore) StoreEnrichedTransactions(ctx context.Context, transactions map[string]json.RawMessage, expiration time.Duration) error {
+	return GetRedisClient(WorkloadCache).StoreEnrichedTransactions(ctx, transactions, expiration)
+}
+
+// StoreRawTransaction 归档Enhanced API原始响应
+func (redisResultStore) StoreRawTransaction(ctx context.Context, signature string, slot uint64, raw json.RawMessage, compress bool, expiration time.Duration) error {
+	return GetRedisClient(WorkloadCache).StoreRawTransaction(ctx, signature, slot, raw, compress, expiration)
+}
+
+// RecordParseFailures 记录签名的解析失败
+func (redisResultStore) RecordParseFailures(ctx context.Context, slot uint64, failures map[string]models.ParseFailureReason, expiration time.Duration) error {
+	return GetRedisClient(WorkloadCache).RecordParseFailures(ctx, slot, failures, expiration)
+}
+
+// GetParseFailures 按签名读取解析失败记录
+func (redisResultStore) GetParseFailures(ctx context.Context, signatures []string) (map[string]models.ParseFailure, error) {
+	return GetRedisClient(WorkloadCache).GetParseFailures(ctx, signatures)
+}
+
+// ClearParseFailures 删除签名的解析失败记录
+func (redisResultStore) ClearParseFailures(ctx context.Context, signatures []string) error {
+	return GetRedisClient(WorkloadCache).ClearParseFailures(ctx, signatures)
+}
+
+// StoreBlockMeta 按区块哈希保存区块元数据
+func (redisResultStore) StoreBlockMeta(ctx context.Context, meta models.BlockMeta, expiration time.Duration) error {
+	return GetRedisClient(WorkloadAnalytics).StoreBlockMeta(ctx, meta, expiration)
+}
+
+// StoreBlockSummary 保存区块交易汇总
+func (redisResultStore) StoreBlockSummary(ctx context.Context, summary models.BlockSummary, maxEntries int64) error {
+	return GetRedisClient(WorkloadAnalytics).StoreBlockSummary(ctx, summary, maxEntries)
+}
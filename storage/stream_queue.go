@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// QueueBackendMemory 交易队列使用进程内优先队列
+	QueueBackendMemory = "memory"
+	// QueueBackendRedisStream 交易队列使用Redis Streams消费者组
+	QueueBackendRedisStream = "redis-stream"
+
+	// StreamTransactionKey 未配置 queue.stream.key 时使用的Stream键名
+	StreamTransactionKey = "transaction:stream"
+
+	// Stream消息中保存交易队列元素的字段
+	streamItemField = "item"
+)
+
+// GlobalTransactionStream 基于Redis Streams的交易队列，queue.backend 为 redis-stream 时由 InitQueue 创建
+var GlobalTransactionStream *StreamTransactionStore
+
+// TransactionAcker 需要在处理完成后确认元素的交易队列，未确认的元素在消费者崩溃后会重新投递
+type TransactionAcker interface {
+	// AckTransactions 确认元素已处理完成(成功、重新入队或标记为失败)
+	AckTransactions(item models.TransactionQueueModel)
+}
+
+// StreamTransactionStore 基于Redis Streams消费者组的交易队列，实现 TransactionQueueStore 和 TransactionAcker
+// 取出的消息在确认之前留在消费者组的待确认列表中：本消费者重启后先处理自己未确认的消息，
+// 其他消费者定期认领空闲超过 claim_idle 的消息，因此处理中途崩溃不会丢失区块。
+// 与内存队列不同，消息按写入顺序而不是槽位顺序取出
+type StreamTransactionStore struct {
+	client        *RedisClient
+	key           string
+	group         string
+	consumer      string
+	claimIdle     time.Duration
+	claimInterval time.Duration
+	maxLen        int64
+	log           *zap.Logger
+
+	mu            sync.Mutex
+	pendingCursor string                         // 读取本消费者未确认消息的游标，读完后为空
+	claimCursor   string                         // XAUTOCLAIM 的游标
+	lastClaim     time.Time                      // 上次认领的时间
+	claimed       []models.TransactionQueueModel // 已认领、尚未取出的消息
+}
+
+// NewStreamTransactionStore 创建基于Redis Streams的交易队列，消费者组不存在时创建
+// 参数:
+//   - client: Redis客户端
+//   - config: Stream配置
+//
+// 返回:
+//   - *StreamTransactionStore: 交易队列
+//   - error: 创建消费者组失败时的错误信息
+func NewStreamTransactionStore(client *RedisClient, config *configs.StreamQueueConfig) (*StreamTransactionStore, error) {
+	if client == nil || client.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	key := config.Key
+	if key == "" {
+		key = Key(StreamTransactionKey)
+	}
+	consumer := config.Consumer
+	if consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("获取主机名失败，请设置 queue.stream.consumer: %w", err)
+		}
+		consumer = hostname
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := client.client.XGroupCreateMkStream(ctx, key, config.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("创建消费者组失败: %w", err)
+	}
+
+	return &StreamTransactionStore{
+		client:        client,
+		key:           key,
+		group:         config.Group,
+		consumer:      consumer,
+		claimIdle:     config.ClaimIdle,
+		claimInterval: config.ClaimInterval,
+		maxLen:        config.MaxLen,
+		log:           logger.Named("storage.stream").With(zap.String("stream", key), zap.String("consumer", consumer)),
+		pendingCursor: "0",
+		claimCursor:   "0-0",
+	}, nil
+}
+
+// PushTransactions 将区块中需要解析的交易签名写入Stream
+func (s *StreamTransactionStore) PushTransactions(item models.TransactionQueueModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	args := &redis.XAddArgs{
+		Stream: s.key,
+		Values: map[string]interface{}{streamItemField: item},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	if err := s.client.client.XAdd(ctx, args).Err(); err != nil {
+		s.log.Error("写入交易队列失败", zap.Uint64("slot", item.Slot), zap.Error(err))
+	}
+}
+
+// PopTransactions 依次取出本消费者未确认的消息、认领的空闲消息和新消息，没有消息时返回false
+func (s *StreamTransactionStore) PopTransactions() (models.TransactionQueueModel, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 上次运行时取出但没有确认的消息
+	if s.pendingCursor != "" {
+		messages, err := s.read(ctx, s.pendingCursor)
+		if err != nil {
+			s.log.Warn("读取未确认的消息失败", zap.Error(err))
+			return models.TransactionQueueModel{}, false
+		}
+		if len(messages) == 0 {
+			s.pendingCursor = ""
+		} else {
+			s.pendingCursor = messages[0].ID
+			if item, ok := s.decode(ctx, messages[0]); ok {
+				return item, true
+			}
+			return models.TransactionQueueModel{}, false
+		}
+	}
+
+	// 其他消费者崩溃后遗留的消息
+	if len(s.claimed) == 0 && clock.Since(s.lastClaim) >= s.claimInterval {
+		s.lastClaim = clock.Now()
+		messages, cursor, err := s.client.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   s.key,
+			Group:    s.group,
+			Consumer: s.consumer,
+			MinIdle:  s.claimIdle,
+			Start:    s.claimCursor,
+			Count:    10,
+		}).Result()
+		if err != nil {
+			s.log.Warn("认领空闲消息失败", zap.Error(err))
+		} else {
+			s.claimCursor = cursor
+			for _, message := range messages {
+				if item, ok := s.decode(ctx, message); ok {
+					s.claimed = append(s.claimed, item)
+				}
+			}
+			if len(messages) > 0 {
+				s.log.Info("已认领空闲消息", zap.Int("count", len(messages)))
+			}
+		}
+	}
+	if len(s.claimed) > 0 {
+		item := s.claimed[0]
+		s.claimed = s.claimed[1:]
+		return item, true
+	}
+
+	messages, err := s.read(ctx, ">")
+	if err != nil {
+		s.log.Warn("读取交易队列失败", zap.Error(err))
+		return models.TransactionQueueModel{}, false
+	}
+	if len(messages) == 0 {
+		return models.TransactionQueueModel{}, false
+	}
+	return s.decode(ctx, messages[0])
+}
+
+// read 以消费者组读取一条消息，id 为 ">" 时读取新消息，否则读取本消费者ID大于 id 的未确认消息
+func (s *StreamTransactionStore) read(ctx context.Context, id string) ([]redis.XMessage, error) {
+	streams, err := s.client.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.consumer,
+		Streams:  []string{s.key, id},
+		Count:    1,
+		Block:    -1,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(streams) == 0 {
+		return nil, nil
+	}
+	return streams[0].Messages, nil
+}
+
+// decode 解析消息中的交易队列元素，无法解析的消息直接确认并丢弃
+func (s *StreamTransactionStore) decode(ctx context.Context, message redis.XMessage) (models.TransactionQueueModel, bool) {
+	var item models.TransactionQueueModel
+	value, _ := message.Values[streamItemField].(string)
+	if err := item.UnmarshalBinary([]byte(value)); err != nil {
+		s.log.Error("交易队列消息无法解析，已丢弃", zap.String("id", message.ID), zap.Error(err))
+		s.ack(ctx, message.ID)
+		return item, false
+	}
+	item.StreamID = message.ID
+	return item, true
+}
+
+// AckTransactions 确认消息并从Stream中删除
+func (s *StreamTransactionStore) AckTransactions(item models.TransactionQueueModel) {
+	if item.StreamID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.ack(ctx, item.StreamID)
+}
+
+// ack 确认消息，已确认的消息不会再被投递，随后删除以免Stream无限增长
+func (s *StreamTransactionStore) ack(ctx context.Context, id string) {
+	pipe := s.client.client.TxPipeline()
+	pipe.XAck(ctx, s.key, s.group, id)
+	pipe.XDel(ctx, s.key, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.log.Warn("确认交易队列消息失败", zap.String("id", id), zap.Error(err))
+	}
+}
+
+// TransactionQueueLen 返回Stream中尚未确认的消息数，包括未读取和处理中的消息
+func (s *StreamTransactionStore) TransactionQueueLen() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	length, err := s.client.client.XLen(ctx, s.key).Result()
+	if err != nil {
+		s.log.Warn("获取交易队列长度失败", zap.Error(err))
+		return 0
+	}
+	return int(length)
+}
+
+// OldestAge 返回Stream中最早一条尚未确认的消息已等待的时长，按消息ID中的写入时间计算，Stream为空时返回0
+func (s *StreamTransactionStore) OldestAge(ctx context.Context) (time.Duration, error) {
+	messages, err := s.client.client.XRangeN(ctx, s.key, "-", "+", 1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("查询交易队列最早的消息失败: %w", err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+	millis, _, _ := strings.Cut(messages[0].ID, "-")
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析消息ID失败: %s", messages[0].ID)
+	}
+	return clock.Since(time.UnixMilli(ms)), nil
+}
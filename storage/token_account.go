@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 代币账户创建/关闭时间序列键前缀，后接代币地址，有序集合的分数为快照时间
+	TokenAccountKeyPrefix = "tokenaccounts:"
+)
+
+// 获取代币账户创建/关闭时间序列的键名
+func getTokenAccountKey(mint string) string {
+	return Key(TokenAccountKeyPrefix) + mint
+}
+
+// StoreTokenAccountSnapshots 批量追加代币账户创建/关闭快照，并删除超过保留时长的旧快照
+// 参数:
+//   - ctx: 上下文
+//   - snapshots: 快照
+//   - retention: 保留时长，0表示不删除
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreTokenAccountSnapshots(ctx context.Context, snapshots []models.TokenAccountSnapshot, retention time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+	pipe := r.client.Pipeline()
+	for _, snapshot := range snapshots {
+		value, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("序列化代币账户快照失败: %w", err)
+		}
+		key := getTokenAccountKey(snapshot.Mint)
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(snapshot.Timestamp), Member: value})
+		if retention > 0 {
+			minScore := snapshot.Timestamp - int64(retention.Seconds())
+			pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(minScore, 10))
+			pipe.Expire(ctx, key, retention)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("存储代币账户快照失败: %w", err)
+	}
+	return nil
+}
+
+// GetTokenAccountSeries 获取时间范围内的代币账户创建/关闭快照
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - since: 起始时间(Unix时间戳，包含)
+//   - until: 结束时间(Unix时间戳，包含)
+//
+// 返回:
+//   - []models.TokenAccountSnapshot: 按时间升序的快照
+//   - error: 错误信息
+func (r *RedisClient) GetTokenAccountSeries(ctx context.Context, mint string, since, until int64) ([]models.TokenAccountSnapshot, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.ZRangeByScore(ctx, getTokenAccountKey(mint), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: strconv.FormatInt(until, 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取代币账户快照失败: %w", err)
+	}
+	series := make([]models.TokenAccountSnapshot, 0, len(values))
+	for _, value := range values {
+		var snapshot models.TokenAccountSnapshot
+		if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+			continue
+		}
+		series = append(series, snapshot)
+	}
+	return series, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 新代币风险检测结果的键前缀，后接代币地址
+	TokenRiskKeyPrefix = "risk:token:"
+)
+
+// 获取新代币风险检测结果的键名
+func getTokenRiskKey(mint string) string {
+	return Key(TokenRiskKeyPrefix) + mint
+}
+
+// StoreTokenRisk 保存新代币的风险检测结果，覆盖之前的结果
+// 参数:
+//   - ctx: 上下文
+//   - report: 检测结果
+//   - expiration: 保存时长，0表示不过期
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreTokenRisk(ctx context.Context, report models.TokenRiskReport, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("序列化代币风险检测结果失败: %w", err)
+	}
+	if err := r.client.Set(ctx, getTokenRiskKey(report.Mint), data, expiration).Err(); err != nil {
+		return fmt.Errorf("保存代币风险检测结果失败: %w", err)
+	}
+	return nil
+}
+
+// GetTokenRisk 读取新代币的风险检测结果
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//
+// 返回:
+//   - *models.TokenRiskReport: 检测结果，没有检测过时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetTokenRisk(ctx context.Context, mint string) (*models.TokenRiskReport, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	data, err := r.client.Get(ctx, getTokenRiskKey(mint)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取代币风险检测结果失败: %w", err)
+	}
+	var report models.TokenRiskReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析代币风险检测结果失败: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models"
+)
+
+const (
+	// 交易索引(v3)的键前缀，后接 <来源>:<类型>:<UTC日起始时间>，Sorted Set 的成员为签名，分数为槽位
+	TransactionDayIndexKeyPrefix = "idx:tx:"
+	// 代币交易索引的键前缀，后接 <代币地址>:<UTC日起始时间>，Sorted Set 的成员为签名，分数为槽位
+	MintDayIndexKeyPrefix = "idx:mint:"
+	// 所有按天索引的键的匹配模式，供清理任务扫描
+	dayIndexKeyPattern = "idx:*"
+)
+
+// IndexDay 返回区块时间所在的UTC日起始时间，区块时间为0时使用当前时间
+func IndexDay(timestamp int64) int64 {
+	if timestamp <= 0 {
+		timestamp = clock.Now().Unix()
+	}
+	return timestamp - timestamp%86400
+}
+
+// 获取按来源、类型和天索引交易的键名
+func getTransactionDayIndexKey(source, transactionType string, day int64) string {
+	return Key(TransactionDayIndexKeyPrefix) + source + ":" + transactionType + ":" + strconv.FormatInt(day, 10)
+}
+
+// 获取按代币和天索引交易的键名
+func getMintDayIndexKey(mint string, day int64) string {
+	return Key(MintDayIndexKeyPrefix) + mint + ":" + strconv.FormatInt(day, 10)
+}
+
+// parseDayIndexKey 从按天索引的键名中解析交易类型和UTC日起始时间，代币索引没有交易类型
+func parseDayIndexKey(key string) (transactionType string, day int64, ok bool) {
+	var name string
+	if rest, found := strings.CutPrefix(key, Key(TransactionDayIndexKeyPrefix)); found {
+		name = rest
+	} else if rest, found := strings.CutPrefix(key, Key(MintDayIndexKeyPrefix)); found {
+		name = rest
+	} else {
+		return "", 0, false
+	}
+	i := strings.LastIndex(name, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	day, err := strconv.ParseInt(name[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	if strings.HasPrefix(key, Key(TransactionDayIndexKeyPrefix)) {
+		j := strings.LastIndex(name[:i], ":")
+		if j <= 0 {
+			return "", 0, false
+		}
+		transactionType = name[j+1 : i]
+	}
+	return transactionType, day, true
+}
+
+// TransactionIndexTTL 返回交易类型的索引保留时长，type_ttl 中没有配置的类型使用默认保留时长
+// 代币索引不区分类型，transactionType 为空时返回默认保留时长
+func TransactionIndexTTL(config *configs.TransactionIndexConfig, transactionType string) time.Duration {
+	for name, ttl := range config.TypeTTL {
+		// viper 会将配置中的键转为小写
+		if transactionType != "" && strings.EqualFold(name, transactionType) {
+			return ttl
+		}
+	}
+	return config.TTL
+}
+
+// IndexTransaction 按来源/类型和涉及的代币索引交易签名，每天一个键
+// 参数:
+//   - ctx: 上下文
+//   - transaction: 需要索引的交易
+//   - ttl: 来源/类型索引在当天结束后的保留时长，0表示不过期；代币索引使用 mintTTL
+//   - mintTTL: 代币索引在当天结束后的保留时长，0表示不过期
+//   - maxPerKey: 每个键最多保留的签名数，超过时删除槽位最小的，0表示不限制
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) IndexTransaction(ctx context.Context, transaction models.IndexedTransaction, ttl, mintTTL time.Duration, maxPerKey int64) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	day := IndexDay(transaction.Timestamp)
+	member := redis.Z{Score: float64(transaction.Slot), Member: transaction.Signature}
+
+	pipe := r.client.Pipeline()
+	add := func(key string, ttl time.Duration) {
+		pipe.ZAdd(ctx, key, member)
+		if maxPerKey > 0 {
+			pipe.ZRemRangeByRank(ctx, key, 0, -maxPerKey-1)
+		}
+		if ttl > 0 {
+			pipe.ExpireAt(ctx, key, time.Unix(day, 0).Add(24*time.Hour+ttl))
+		}
+	}
+	pipe.SAdd(ctx, Key(TransactionTypesKeyPrefix)+transaction.Source, transaction.Type)
+	add(getTransactionDayIndexKey(transaction.Source, transaction.Type, day), ttl)
+	for _, mint := range transaction.Mints {
+		add(getMintDayIndexKey(mint, day), mintTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("索引交易失败: %w", err)
+	}
+	return nil
+}
+
+// GetIndexedTransactions 按来源、类型和天查询索引的交易签名
+// 参数:
+//   - ctx: 上下文
+//   - source: 交易来源
+//   - transactionType: 交易类型
+//   - day: UTC日起始时间，见 IndexDay
+//   - limit: 最多返回的签名数，0表示全部
+//
+// 返回:
+//   - []string: 按槽位降序排列的签名
+//   - error: 错误信息
+func (r *RedisClient) GetIndexedTransactions(ctx context.Context, source, transactionType string, day int64, limit int64) ([]string, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	signatures, err := r.client.ZRevRange(ctx, getTransactionDayIndexKey(source, transactionType, day), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询交易索引失败: %w", err)
+	}
+	return signatures, nil
+}
+
+// GetMintTransactions 按代币和天查询索引的交易签名
+// 参数:
+//   - ctx: 上下文
+//   - mint: 代币地址
+//   - day: UTC日起始时间，见 IndexDay
+//   - limit: 最多返回的签名数，0表示全部
+//
+// 返回:
+//   - []string: 按槽位降序排列的签名
+//   - error: 错误信息
+func (r *RedisClient) GetMintTransactions(ctx context.Context, mint string, day int64, limit int64) ([]string, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	signatures, err := r.client.ZRevRange(ctx, getMintDayIndexKey(mint, day), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询代币交易索引失败: %w", err)
+	}
+	return signatures, nil
+}
+
+// TransactionDayIndex 按来源、类型和天索引交易的一个键
+type TransactionDayIndex struct {
+	Source string // 交易来源
+	Type   string // 交易类型
+	Day    int64  // UTC日起始时间，见 IndexDay
+}
+
+// IndexedSignature 索引中的交易签名及其槽位
+type IndexedSignature struct {
+	Signature string
+	Slot      uint64
+}
+
+// ListTransactionDayIndexes 扫描来源/类型的按天索引，返回指定天范围内的键
+// 参数:
+//   - ctx: 上下文
+//   - fromDay: 最早的UTC日起始时间，0表示不限制
+//   - toDay: 最晚的UTC日起始时间，0表示不限制
+//
+// 返回:
+//   - []TransactionDayIndex: 按天、来源、类型排序的索引键
+//   - error: 错误信息
+func (r *RedisClient) ListTransactionDayIndexes(ctx context.Context, fromDay, toDay int64) ([]TransactionDayIndex, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	var indexes []TransactionDayIndex
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, Key(TransactionDayIndexKeyPrefix)+"*", 500).Result()
+		if err != nil {
+			return nil, fmt.Errorf("扫描交易索引失败: %w", err)
+		}
+		for _, key := range keys {
+			transactionType, day, ok := parseDayIndexKey(key)
+			if !ok || (fromDay > 0 && day < fromDay) || (toDay > 0 && day > toDay) {
+				continue
+			}
+			source, ok := strings.CutSuffix(strings.TrimPrefix(key, Key(TransactionDayIndexKeyPrefix)), ":"+transactionType+":"+strconv.FormatInt(day, 10))
+			if !ok || source == "" {
+				continue
+			}
+			indexes = append(indexes, TransactionDayIndex{Source: source, Type: transactionType, Day: day})
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	slices.SortFunc(indexes, func(a, b TransactionDayIndex) int {
+		return cmp.Or(cmp.Compare(a.Day, b.Day), cmp.Compare(a.Source, b.Source), cmp.Compare(a.Type, b.Type))
+	})
+	// SCAN 可能重复返回同一个键
+	return slices.Compact(indexes), nil
+}
+
+// GetIndexedTransactionsInSlots 按槽位范围查询索引键中的交易签名
+// 参数:
+//   - ctx: 上下文
+//   - index: 索引键
+//   - fromSlot: 起始槽位(包含)
+//   - toSlot: 结束槽位(包含)，0表示不限制
+//
+// 返回:
+//   - []IndexedSignature: 按槽位升序排列的签名
+//   - error: 错误信息
+func (r *RedisClient) GetIndexedTransactionsInSlots(ctx context.Context, index TransactionDayIndex, fromSlot, toSlot uint64) ([]IndexedSignature, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	maxScore := "+inf"
+	if toSlot > 0 {
+		maxScore = strconv.FormatUint(toSlot, 10)
+	}
+	members, err := r.client.ZRangeByScoreWithScores(ctx, getTransactionDayIndexKey(index.Source, index.Type, index.Day), &redis.ZRangeBy{
+		Min: strconv.FormatUint(fromSlot, 10),
+		Max: maxScore,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询交易索引失败: %w", err)
+	}
+	signatures := make([]IndexedSignature, 0, len(members))
+	for _, member := range members {
+		if signature, ok := member.Member.(string); ok {
+			signatures = append(signatures, IndexedSignature{Signature: signature, Slot: uint64(member.Score)})
+		}
+	}
+	return signatures, nil
+}
+
+// TransactionIndexCleanup 交易索引清理结果
+type TransactionIndexCleanup struct {
+	Scanned int64 `json:"scanned"` // 扫描的键数量
+	Deleted int64 `json:"deleted"` // 超过保留时长而删除的键数量
+	Expired int64 `json:"expired"` // 补设过期时间的键数量，如迁移生成或修改保留时长前写入的键
+	Trimmed int64 `json:"trimmed"` // 超过签名数上限而裁剪的键数量
+}
+
+// CleanupTransactionIndex 清理按天索引的交易，作为过期时间之外的兜底：
+// 删除超过保留时长的键，为没有过期时间的键补设过期时间，并裁剪超过签名数上限的键
+// 参数:
+//   - ctx: 上下文
+//   - config: 交易索引配置
+//   - batchSize: 每次SCAN的数量
+//
+// 返回:
+//   - TransactionIndexCleanup: 清理结果
+//   - error: 错误信息
+func (r *RedisClient) CleanupTransactionIndex(ctx context.Context, config *configs.TransactionIndexConfig, batchSize int64) (TransactionIndexCleanup, error) {
+	var result TransactionIndexCleanup
+	if r == nil || r.client == nil {
+		return result, errors.New("Redis 客户端尚未初始化")
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	now := clock.Now()
+
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, Key(dayIndexKeyPattern), batchSize).Result()
+		if err != nil {
+			return result, fmt.Errorf("扫描交易索引失败: %w", err)
+		}
+		for _, key := range keys {
+			transactionType, day, ok := parseDayIndexKey(key)
+			if !ok {
+				continue
+			}
+			result.Scanned++
+			ttl := TransactionIndexTTL(config, transactionType)
+			if ttl > 0 {
+				expireAt := time.Unix(day, 0).Add(24*time.Hour + ttl)
+				if !expireAt.After(now) {
+					if err := r.client.Del(ctx, key).Err(); err != nil {
+						return result, fmt.Errorf("删除过期的交易索引 %s 失败: %w", key, err)
+					}
+					result.Deleted++
+					continue
+				}
+				remaining, err := r.client.TTL(ctx, key).Result()
+				if err != nil {
+					return result, fmt.Errorf("读取交易索引 %s 的过期时间失败: %w", key, err)
+				}
+				// -1 表示键没有过期时间
+				if remaining == -1 {
+					if err := r.client.ExpireAt(ctx, key, expireAt).Err(); err != nil {
+						return result, fmt.Errorf("设置交易索引 %s 的过期时间失败: %w", key, err)
+					}
+					result.Expired++
+				}
+			}
+			if config.MaxPerKey > 0 {
+				removed, err := r.client.ZRemRangeByRank(ctx, key, 0, -config.MaxPerKey-1).Result()
+				if err != nil {
+					return result, fmt.Errorf("裁剪交易索引 %s 失败: %w", key, err)
+				}
+				if removed > 0 {
+					result.Trimmed++
+				}
+			}
+		}
+		if next == 0 {
+			return result, nil
+		}
+		cursor = next
+	}
+}
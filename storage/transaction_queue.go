@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/life2you/datas-go/models"
+)
+
+// PendingTransactionsKey 服务停止时未处理完的交易队列元素，启动时重新载入内存交易队列
+const PendingTransactionsKey = "transaction:pending"
+
+// PushTransactionQueueModels 将交易队列元素追加到Redis列表尾部
+// 参数:
+//   - ctx: 上下文
+//   - items: 交易队列元素
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) PushTransactionQueueModels(ctx context.Context, items ...models.TransactionQueueModel) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		values = append(values, item)
+	}
+	if err := r.client.RPush(ctx, Key(PendingTransactionsKey), values...).Err(); err != nil {
+		return fmt.Errorf("保存交易队列元素失败: %w", err)
+	}
+	return nil
+}
+
+// PopTransactionQueueModel 从Redis列表头部取出一个交易队列元素
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - models.TransactionQueueModel: 交易队列元素
+//   - bool: 列表为空时为false
+//   - error: 错误信息
+func (r *RedisClient) PopTransactionQueueModel(ctx context.Context) (models.TransactionQueueModel, bool, error) {
+	var item models.TransactionQueueModel
+	if r == nil || r.client == nil {
+		return item, false, errors.New("Redis 客户端尚未初始化")
+	}
+	err := r.client.LPop(ctx, Key(PendingTransactionsKey)).Scan(&item)
+	if errors.Is(err, redis.Nil) {
+		return item, false, nil
+	}
+	if err != nil {
+		return item, false, fmt.Errorf("读取交易队列元素失败: %w", err)
+	}
+	return item, true, nil
+}
+
+// GetPendingTransactionsLength 获取等待重新载入的交易队列元素数量
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - int64: 元素数量
+//   - error: 错误信息
+func (r *RedisClient) GetPendingTransactionsLength(ctx context.Context) (int64, error) {
+	if r == nil || r.client == nil {
+		return 0, errors.New("Redis 客户端尚未初始化")
+	}
+	length, err := r.client.LLen(ctx, Key(PendingTransactionsKey)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("获取交易队列长度失败: %w", err)
+	}
+	return length, nil
+}
+
+// SaveTransactionQueue 将内存交易队列中的剩余元素全部转存到Redis，服务停止前调用
+// 返回:
+//   - int: 转存的元素数量
+//   - error: 错误信息，失败时未保存的元素会放回内存队列
+func SaveTransactionQueue(ctx context.Context) (int, error) {
+	if GlobalTransactionQueue == nil {
+		return 0, nil
+	}
+	var items []models.TransactionQueueModel
+	for {
+		item, _, ok := GlobalTransactionQueue.Pop()
+		if !ok {
+			break
+		}
+		items = append(items, item)
+	}
+	if err := GetRedisClient(WorkloadQueue).PushTransactionQueueModels(ctx, items...); err != nil {
+		for _, item := range items {
+			GlobalTransactionQueue.Push(item, int64(item.Slot))
+		}
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// RestoreTransactionQueue 将上次停止时转存到Redis的元素重新载入内存交易队列，服务启动时调用
+// 返回:
+//   - int: 载入的元素数量
+//   - error: 错误信息
+func RestoreTransactionQueue(ctx context.Context) (int, error) {
+	if GlobalTransactionQueue == nil {
+		return 0, errors.New("交易队列尚未初始化")
+	}
+	client := GetRedisClient(WorkloadQueue)
+	restored := 0
+	for {
+		item, ok, err := client.PopTransactionQueueModel(ctx)
+		if err != nil {
+			return restored, err
+		}
+		if !ok {
+			return restored, nil
+		}
+		GlobalTransactionQueue.Push(item, int64(item.Slot))
+		restored++
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/life2you/datas-go/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 钱包历史摘要缓存的键前缀，后接钱包地址；摘要以哈希保存，处理该钱包的新交易时原子地累加
+	WalletHistoryKeyPrefix = "wallet:history:"
+)
+
+// 获取钱包历史摘要缓存的键名
+func getWalletHistoryKey(wallet string) string {
+	return Key(WalletHistoryKeyPrefix) + wallet
+}
+
+// advanceWalletHistoryScript 返回计入交易之前的摘要，交易的槽位晚于摘要中最新的交易时计入
+// KEYS[1] 摘要键，ARGV[1] 交易槽位，ARGV[2] 交易时间
+// 返回 {是否计入, HGETALL结果}，摘要不存在时返回nil
+var advanceWalletHistoryScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return nil
+end
+local before = redis.call('HGETALL', KEYS[1])
+if tonumber(ARGV[1]) <= tonumber(redis.call('HGET', KEYS[1], 'latest_slot') or '0') then
+	return {0, before}
+end
+redis.call('HINCRBY', KEYS[1], 'transactions', 1)
+redis.call('HINCRBY', KEYS[1], 'trades', 1)
+redis.call('HSET', KEYS[1], 'latest_slot', ARGV[1])
+if tonumber(redis.call('HGET', KEYS[1], 'first_seen') or '0') == 0 then
+	redis.call('HSET', KEYS[1], 'first_seen', ARGV[2])
+end
+return {1, before}
+`)
+
+// GetWalletHistory 读取缓存的钱包历史摘要
+// 参数:
+//   - ctx: 上下文
+//   - wallet: 钱包地址
+//
+// 返回:
+//   - *models.WalletHistory: 缓存的历史摘要，未命中时为nil
+//   - error: 错误信息
+func (r *RedisClient) GetWalletHistory(ctx context.Context, wallet string) (*models.WalletHistory, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	fields, err := r.client.HGetAll(ctx, getWalletHistoryKey(wallet)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取钱包历史缓存失败: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return decodeWalletHistory(wallet, fields), nil
+}
+
+// StoreWalletHistory 缓存钱包历史摘要，替换已有的摘要
+// 参数:
+//   - ctx: 上下文
+//   - history: 历史摘要
+//   - expiration: 缓存时长
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) StoreWalletHistory(ctx context.Context, history models.WalletHistory, expiration time.Duration) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	key := getWalletHistoryKey(history.Wallet)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key,
+			"first_seen", history.FirstSeen,
+			"transactions", history.Transactions,
+			"trades", history.Trades,
+			"complete", history.Complete,
+			"fetched_at", history.FetchedAt,
+			"latest_slot", history.LatestSlot)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("缓存钱包历史失败: %w", err)
+	}
+	return nil
+}
+
+// AdvanceWalletHistory 读取钱包历史摘要，swap交易的槽位晚于摘要中最新的交易时把该交易计入摘要，
+// 之后同一钱包的交易看到的历史包含本交易
+// 参数:
+//   - ctx: 上下文
+//   - wallet: 钱包地址
+//   - slot: 交易所在的槽位
+//   - timestamp: 交易时间(Unix时间戳)
+//
+// 返回:
+//   - *models.WalletHistory: 计入本交易之前的摘要，未缓存时为nil
+//   - bool: 是否已计入；槽位不晚于摘要中最新的交易时摘要可能已包含本交易或更晚的交易，不计入
+//   - error: 错误信息
+func (r *RedisClient) AdvanceWalletHistory(ctx context.Context, wallet string, slot uint64, timestamp int64) (*models.WalletHistory, bool, error) {
+	if r == nil || r.client == nil {
+		return nil, false, errors.New("Redis 客户端尚未初始化")
+	}
+	result, err := advanceWalletHistoryScript.Run(ctx, r.client, []string{getWalletHistoryKey(wallet)}, slot, timestamp).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("更新钱包历史缓存失败: %w", err)
+	}
+	counted, _ := result[0].(int64)
+	values, _ := result[1].([]interface{})
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		field, _ := values[i].(string)
+		value, _ := values[i+1].(string)
+		fields[field] = value
+	}
+	return decodeWalletHistory(wallet, fields), counted == 1, nil
+}
+
+// decodeWalletHistory 将摘要哈希的字段转换为历史摘要
+func decodeWalletHistory(wallet string, fields map[string]string) *models.WalletHistory {
+	history := &models.WalletHistory{Wallet: wallet}
+	history.FirstSeen, _ = strconv.ParseInt(fields["first_seen"], 10, 64)
+	history.Transactions, _ = strconv.Atoi(fields["transactions"])
+	history.Trades, _ = strconv.Atoi(fields["trades"])
+	history.Complete, _ = strconv.ParseBool(fields["complete"])
+	history.FetchedAt, _ = strconv.ParseInt(fields["fetched_at"], 10, 64)
+	history.LatestSlot, _ = strconv.ParseUint(fields["latest_slot"], 10, 64)
+	return history
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 关注地址Hash表的键名，字段为地址，值为关注设置JSON
+	WatchlistHashKey = "watchlist"
+	// 关注地址变更通知频道，增删改后发布，各实例收到后重新加载
+	WatchlistChangedChannel = "watchlist:changed"
+)
+
+// SaveWatchedAddress 保存关注地址并通知其他实例
+// 参数:
+//   - ctx: 上下文
+//   - address: 关注的地址
+//   - entry: 关注设置JSON
+//
+// 返回:
+//   - error: 错误信息
+func (r *RedisClient) SaveWatchedAddress(ctx context.Context, address string, entry json.RawMessage) error {
+	if r == nil || r.client == nil {
+		return errors.New("Redis 客户端尚未初始化")
+	}
+	if err := r.client.HSet(ctx, Key(WatchlistHashKey), address, []byte(entry)).Err(); err != nil {
+		return fmt.Errorf("保存关注地址失败: %w", err)
+	}
+	r.client.Publish(ctx, Key(WatchlistChangedChannel), address)
+	return nil
+}
+
+// DeleteWatchedAddress 删除关注地址并通知其他实例
+// 参数:
+//   - ctx: 上下文
+//   - address: 关注的地址
+//
+// 返回:
+//   - bool: 地址是否存在
+//   - error: 错误信息
+func (r *RedisClient) DeleteWatchedAddress(ctx context.Context, address string) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	deleted, err := r.client.HDel(ctx, Key(WatchlistHashKey), address).Result()
+	if err != nil {
+		return false, fmt.Errorf("删除关注地址失败: %w", err)
+	}
+	if deleted > 0 {
+		r.client.Publish(ctx, Key(WatchlistChangedChannel), address)
+	}
+	return deleted > 0, nil
+}
+
+// GetWatchlist 获取所有关注地址
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - map[string]json.RawMessage: 地址到关注设置JSON的映射
+//   - error: 错误信息
+func (r *RedisClient) GetWatchlist(ctx context.Context) (map[string]json.RawMessage, error) {
+	if r == nil || r.client == nil {
+		return nil, errors.New("Redis 客户端尚未初始化")
+	}
+	values, err := r.client.HGetAll(ctx, Key(WatchlistHashKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取关注地址失败: %w", err)
+	}
+	entries := make(map[string]json.RawMessage, len(values))
+	for address, value := range values {
+		entries[address] = json.RawMessage(value)
+	}
+	return entries, nil
+}
+
+// SubscribeWatchlistChanges 订阅关注地址变更通知
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *redis.PubSub: 订阅对象，使用完毕后需关闭
+func (r *RedisClient) SubscribeWatchlistChanges(ctx context.Context) *redis.PubSub {
+	return r.client.Subscribe(ctx, Key(WatchlistChangedChannel))
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// 已接收的Webhook事件的键前缀，后接交易签名和事件时间戳
+	WebhookSeenKeyPrefix = "webhook:seen:"
+)
+
+// 获取已接收Webhook事件的键名
+func getWebhookSeenKey(signature string, timestamp int64) string {
+	return Key(WebhookSeenKeyPrefix) + signature + ":" + strconv.FormatInt(timestamp, 10)
+}
+
+// MarkWebhookEventSeen 通过SETNX记录Webhook事件已接收，用于丢弃Helius重试投递的重复事件
+// 参数:
+//   - ctx: 上下文
+//   - signature: 交易签名
+//   - timestamp: 事件中的区块时间(Unix时间戳)，重试投递时不变
+//   - window: 去重窗口，窗口内相同签名和时间戳的事件视为重复
+//
+// 返回:
+//   - bool: 是否首次接收，为false表示窗口内已接收过
+//   - error: 错误信息
+func (r *RedisClient) MarkWebhookEventSeen(ctx context.Context, signature string, timestamp int64, window time.Duration) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, errors.New("Redis 客户端尚未初始化")
+	}
+	first, err := r.client.SetNX(ctx, getWebhookSeenKey(signature, timestamp), 1, window).Result()
+	if err != nil {
+		return false, fmt.Errorf("记录Webhook事件失败: %w", err)
+	}
+	return first, nil
+}
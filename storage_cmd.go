@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+)
+
+// newStorageCommand 存储运维命令
+func newStorageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "存储运维命令",
+	}
+	cmd.AddCommand(newStorageVersionCommand(), newStorageMigrateCommand(), newStorageCleanupCommand())
+	return cmd
+}
+
+// newStorageVersionCommand 查看存储结构版本
+func newStorageVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "查看Redis中的存储结构版本和未完成的迁移",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loadConfig()
+			storage.NewRedisClient(&configs.GlobalConfig.Redis)
+			defer storage.CloseRedisClients()
+
+			ctx := context.Background()
+			client := storage.GetRedisClient(storage.WorkloadAnalytics)
+			version, err := client.GetSchemaVersion(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("存储结构版本: %s (当前程序写入 %s)\n", version, storage.CurrentSchemaVersion)
+			progress, err := client.GetMigrationProgress(ctx)
+			if err != nil {
+				return err
+			}
+			if progress != nil {
+				fmt.Printf("未完成的迁移: %s -> %s，已扫描 %d 个键，已改写 %d 个\n", progress.From, progress.To, progress.Scanned, progress.Migrated)
+			}
+			return nil
+		},
+	}
+}
+
+// newStorageMigrateCommand 迁移存储结构
+func newStorageMigrateCommand() *cobra.Command {
+	var (
+		from, to  string
+		batchSize int64
+		cleanup   bool
+		dryRun    bool
+		keep      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "在线迁移Redis存储结构，反向迁移(如 --from v2 --to v1)即回滚",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" || to == "" {
+				return fmt.Errorf("必须指定 --from 和 --to")
+			}
+			loadConfig()
+			storage.NewRedisClient(&configs.GlobalConfig.Redis)
+			defer storage.CloseRedisClients()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			progress, err := storage.GetRedisClient(storage.WorkloadAnalytics).Migrate(ctx, from, to, storage.MigrationOptions{
+				BatchSize:      batchSize,
+				Cleanup:        cleanup,
+				DryRun:         dryRun,
+				KeepUnresolved: keep,
+				Progress: func(progress storage.MigrationProgress) {
+					fmt.Printf("迁移进度: 已扫描 %d 个键，已改写 %d 个\n", progress.Scanned, progress.Migrated)
+				},
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("迁移已中断，再次执行相同命令可从中断处继续: %w", err)
+				}
+				return err
+			}
+			switch {
+			case dryRun:
+				fmt.Printf("预演完成: 共需改写 %d 个键\n", progress.Migrated)
+			case progress.Scanned == 0 && progress.Migrated == 0:
+				fmt.Printf("存储结构已是 %s\n", to)
+			default:
+				fmt.Printf("迁移完成: %s -> %s，共改写 %d 个键\n", from, to, progress.Migrated)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "源版本，如 v1")
+	cmd.Flags().StringVar(&to, "to", storage.CurrentSchemaVersion, "目标版本")
+	cmd.Flags().Int64Var(&batchSize, "batch", 500, "每批扫描的键数量")
+	cmd.Flags().BoolVar(&cleanup, "cleanup", false, "改写后删除旧键(删除后仍可通过反向迁移回滚)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只统计需要改写的键，不执行写入")
+	cmd.Flags().BoolVar(&keep, "keep-unresolved", false, "v2迁移到v3时没有缓存解析结果的签名保留在v2索引中继续迁移，不开启时迁移失败")
+	return cmd
+}
+
+// newStorageCleanupCommand 立即清理按天索引的交易
+func newStorageCleanupCommand() *cobra.Command {
+	var batchSize int64
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "按 transaction_index 配置清理交易索引：删除过期的键、补设过期时间并裁剪超过上限的键",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loadConfig()
+			storage.NewRedisClient(&configs.GlobalConfig.Redis)
+			defer storage.CloseRedisClients()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			result, err := storage.GetRedisClient(storage.WorkloadAnalytics).CleanupTransactionIndex(ctx, &configs.GlobalConfig.TransactionIndex, batchSize)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("清理完成: 扫描 %d 个键，删除 %d 个，补设过期时间 %d 个，裁剪 %d 个\n", result.Scanned, result.Deleted, result.Expired, result.Trimmed)
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&batchSize, "batch", 500, "每批扫描的键数量")
+	return cmd
+}
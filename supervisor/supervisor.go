@@ -0,0 +1,100 @@
+// Package supervisor 为长期运行的协程提供panic恢复和重启
+// 服务循环通过 Go/Run 运行，panic 时记录日志和计数并按退避时间重启；
+// 每次处理一个任务的协程(如订阅回调)通过 defer Recover 恢复，避免panic导致整个进程退出
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/metrics"
+)
+
+const (
+	// DefaultInitialBackoff 未配置时第一次重启前的等待时间
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff 未配置时重启前的最长等待时间
+	DefaultMaxBackoff = time.Minute
+)
+
+// Go 在新协程中运行 fn，见 Run
+func Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	go Run(ctx, name, fn)
+}
+
+// Run 在当前协程中运行 fn，fn panic 时记录日志和计数，等待退避时间后重新运行
+// 退避时间从 supervisor.initial_backoff 开始每次翻倍，不超过 supervisor.max_backoff；
+// fn 运行超过 max_backoff 后才panic时，退避时间重新从 initial_backoff 开始
+// fn 正常返回或 ctx 取消后不再重启；fn 中的 defer 在panic时也会执行，
+// 订阅取消等只应执行一次的清理需要放在 Run 之外
+func Run(ctx context.Context, name string, fn func(ctx context.Context)) {
+	initial, maxBackoff := backoffs()
+	backoff := initial
+	for {
+		startedAt := clock.Now()
+		if !call(ctx, name, fn) {
+			return
+		}
+		if clock.Since(startedAt) > maxBackoff {
+			backoff = initial
+		}
+		logger.Named("supervisor").Warn("协程panic，稍后重启", zap.String("name", name), zap.Duration("backoff", backoff))
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(backoff):
+		}
+		metrics.IncGoroutineRestarts(name)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// call 运行一次 fn，返回是否发生了panic
+func call(ctx context.Context, name string, fn func(ctx context.Context)) (panicked bool) {
+	var err error
+	defer func() {
+		panicked = err != nil
+	}()
+	defer Recover(name, &err)
+	fn(ctx)
+	return false
+}
+
+// Recover 恢复当前协程的panic，记录日志、堆栈和计数，err 不为nil时把panic转换为错误写入 *err
+// 必须直接通过 defer 调用: defer supervisor.Recover("handler.block", nil)
+func Recover(name string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	metrics.RecordGoroutinePanic(name, fmt.Sprint(recovered))
+	logger.Named("supervisor").Error("协程发生panic",
+		zap.String("name", name),
+		zap.Any("panic", recovered),
+		zap.ByteString("stack", debug.Stack()))
+	if err != nil {
+		*err = fmt.Errorf("%s panic: %v", name, recovered)
+	}
+}
+
+// backoffs 返回配置的退避时间，未加载配置时使用默认值
+func backoffs() (initial, maxBackoff time.Duration) {
+	initial, maxBackoff = DefaultInitialBackoff, DefaultMaxBackoff
+	if configs.GlobalConfig != nil {
+		config := configs.GlobalConfig.Supervisor
+		if config.InitialBackoff > 0 {
+			initial = config.InitialBackoff
+		}
+		if config.MaxBackoff > 0 {
+			maxBackoff = config.MaxBackoff
+		}
+	}
+	return initial, max(initial, maxBackoff)
+}
@@ -0,0 +1,57 @@
+package supervisor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/logger"
+)
+
+func TestRunBackoff(t *testing.T) {
+	logger.Init(&configs.LogConfig{Level: "error"})
+	fake := clock.NewFake(time.Unix(1700000000, 0), false)
+	t.Cleanup(clock.SetClock(fake))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, "supervisor.test", func(ctx context.Context) {
+			runs <- struct{}{}
+			panic("boom")
+		})
+	}()
+
+	// waitRestart 等待协程进入退避，推进到退避结束前一刻确认没有重启，再推进到退避结束确认重启
+	waitRestart := func(backoff time.Duration) {
+		t.Helper()
+		for fake.Waiters() == 0 {
+			runtime.Gosched()
+		}
+		fake.Advance(backoff - time.Millisecond)
+		select {
+		case <-runs:
+			t.Fatalf("退避 %s 结束前不应重启", backoff)
+		default:
+		}
+		fake.Advance(time.Millisecond)
+		<-runs
+	}
+
+	<-runs
+	waitRestart(DefaultInitialBackoff)
+	waitRestart(2 * DefaultInitialBackoff)
+	waitRestart(4 * DefaultInitialBackoff)
+
+	// 退避期间取消后不再重启
+	for fake.Waiters() == 0 {
+		runtime.Gosched()
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,79 @@
+package testutil
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/life2you/datas-go/models/resp"
+)
+
+// 固定数据中使用的账户
+const (
+	FixtureFeePayer = "FixtureFeePayer1111111111111111111111111111"
+	FixtureMint     = "FixtureMint11111111111111111111111111111111"
+)
+
+// BlockFixture 生成 getBlock 的 result：每个签名对应一笔执行成功的非投票交易，会被推入解析队列
+// 参数:
+//   - slot: 槽位，父槽位为 slot-1
+//   - blockTime: 区块时间(Unix时间戳)
+//   - signatures: 区块中的交易签名
+func BlockFixture(slot uint64, blockTime int64, signatures ...string) json.RawMessage {
+	block := resp.BlockResp{
+		BlockTime:         blockTime,
+		Blockhash:         "FixtureBlockhash" + strconv.FormatUint(slot, 10),
+		PreviousBlockhash: "FixtureBlockhash" + strconv.FormatUint(slot-1, 10),
+		ParentSlot:        slot - 1,
+		Transactions:      make([]resp.Transactions, 0, len(signatures)),
+	}
+	for _, signature := range signatures {
+		block.Transactions = append(block.Transactions, resp.Transactions{
+			Meta: resp.Meta{
+				Fee:         5000,
+				LogMessages: []string{"Program 11111111111111111111111111111111 invoke [1]", "Program 11111111111111111111111111111111 success"},
+			},
+			Transaction: resp.Transaction{
+				Signatures: []string{signature},
+				Message: resp.Message{
+					AccountKeys:  []string{FixtureFeePayer, "11111111111111111111111111111111"},
+					Header:       resp.Header{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+					Instructions: []resp.Instructions{{ProgramIDIndex: 1, Accounts: []int{0}}},
+				},
+			},
+		})
+	}
+	data, _ := json.Marshal(block)
+	return data
+}
+
+// ParsedTransactionFixture 生成Enhanced API返回的一笔SWAP交易，代币转账涉及 mint
+// 参数:
+//   - signature: 交易签名
+//   - slot: 槽位
+//   - timestamp: 区块时间(Unix时间戳)，交易索引按天过期，应使用当前时间附近的时间戳
+//   - mint: 交易的代币，为空时使用 FixtureMint
+func ParsedTransactionFixture(signature string, slot uint64, timestamp int64, mint string) json.RawMessage {
+	if mint == "" {
+		mint = FixtureMint
+	}
+	transaction := resp.ParsedTransaction{
+		Description: "fixture swap",
+		Type:        resp.TransactionTypeSwap,
+		Source:      resp.SourceRaydium,
+		Fee:         5000,
+		FeePayer:    FixtureFeePayer,
+		Signature:   signature,
+		Slot:        slot,
+		Timestamp:   timestamp,
+		NativeTransfers: []resp.NativeTransfer{
+			{FromUserAccount: FixtureFeePayer, ToUserAccount: "FixturePool", Amount: 1_000_000_000},
+		},
+		TokenTransfers: []resp.TokenTransfer{
+			{FromUserAccount: "FixturePool", ToUserAccount: FixtureFeePayer, TokenAmount: decimal.NewFromInt(1000), Mint: mint},
+		},
+	}
+	data, _ := json.Marshal(transaction)
+	return data
+}
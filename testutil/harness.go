@@ -0,0 +1,123 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/handler"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/pipeline"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/service"
+	"github.com/life2you/datas-go/storage"
+)
+
+// 模拟服务使用的API密钥
+const TestAPIKey = "test-api-key"
+
+// NewRedis 启动miniredis并初始化 storage 的全局Redis客户端，测试结束时关闭
+func NewRedis(tb testing.TB, config *configs.RedisConfig) *miniredis.Miniredis {
+	tb.Helper()
+	server := miniredis.RunT(tb)
+	config.Addr = server.Addr()
+	config.Password = ""
+	storage.NewRedisClient(config)
+	tb.Cleanup(storage.CloseRedisClients)
+	return server
+}
+
+// Harness 完整采集流程的测试环境：模拟的Helius服务、miniredis，以及指向它们的全局配置和客户端
+// 采集流程依赖包级的全局变量(配置、客户端、队列)，使用 Harness 的测试不能并行执行
+type Harness struct {
+	Helius  *MockHelius
+	Redis   *miniredis.Miniredis
+	Config  *configs.Config
+	Handler *handler.Handler
+}
+
+// NewHarness 创建测试环境
+// 参数:
+//   - tb: 测试对象
+//   - configure: 在默认配置基础上修改配置，可为nil；各客户端的地址和密钥已指向模拟服务
+//
+// 返回:
+//   - *Harness: 测试环境，测试结束时停止后台循环、恢复真实时钟并关闭客户端
+func NewHarness(tb testing.TB, configure func(*configs.Config)) *Harness {
+	tb.Helper()
+	cfg, err := configs.DefaultConfig()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	helius := NewMockHelius(tb)
+
+	cfg.Log = configs.LogConfig{Level: "warn", Format: "console", Stdout: true}
+	cfg.WebSocket.Enabled = true
+	cfg.WebSocket.Endpoint = helius.WebSocketURL()
+	cfg.WebSocket.APIKey = TestAPIKey
+	cfg.WebSocket.ReconnectInterval = 100 * time.Millisecond
+	cfg.HeliusAPI.Endpoint = helius.URL()
+	cfg.HeliusAPI.APIKey = TestAPIKey
+	cfg.HeliusEnhancedAPI.Endpoint = helius.URL()
+	cfg.HeliusEnhancedAPI.APIKeys = []string{TestAPIKey}
+	if configure != nil {
+		configure(cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		tb.Fatal(err)
+	}
+
+	configs.SetGlobalConfig(cfg)
+	logger.Init(&cfg.Log)
+
+	// 扫描和处理循环之间按固定时间等待，使用自动推进的模拟时钟，等待只占用约1毫秒
+	tb.Cleanup(clock.SetClock(clock.NewFake(time.Now(), true)))
+
+	redis := NewRedis(tb, &cfg.Redis)
+	pipeline.NewPipeline(&cfg.Pipeline)
+	storage.InitQueue()
+
+	// 清除之前的客户端，阶段启动时按当前配置重新创建
+	rpc.GlobalWebSocketPool = nil
+	rpc.GlobalWebSocketClient = nil
+	rpc.GlobalHeliusClient = nil
+	rpc.GlobalHeliusEnhancedApiClients = nil
+	// 最后注册，最先执行: 关闭订阅并等待扫描和处理循环退出后，再恢复时钟、关闭Redis
+	tb.Cleanup(func() {
+		if rpc.GlobalWebSocketPool != nil {
+			rpc.GlobalWebSocketPool.Close()
+		}
+		service.StopLoops()
+	})
+
+	return &Harness{
+		Helius:  helius,
+		Redis:   redis,
+		Config:  cfg,
+		Handler: handler.NewDefaultHandler(),
+	}
+}
+
+// Start 按 ingest → block_fetch → parse → sink 的依赖顺序启动采集流程，所有阶段就绪后返回
+// 区块队列扫描和交易队列处理在后台持续运行，测试结束时停止
+func (h *Harness) Start(ctx context.Context) error {
+	stages := pipeline.NewStages(&h.Config.Pipeline.Stages)
+	service.RegisterStages(stages, h.Handler)
+	return stages.Run(ctx)
+}
+
+// Eventually 在超时前反复检查条件，条件满足时返回，超时后测试失败
+func Eventually(tb testing.TB, timeout time.Duration, condition func() bool, message string) {
+	tb.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			tb.Fatalf("等待超时(%s): %s", timeout, message)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,348 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// JSON-RPC错误码，与Helius节点返回的一致
+const (
+	codeMethodNotFound = -32601 // 不支持的方法
+	codeInvalidParams  = -32602 // 参数无效，如取消订阅时订阅ID不存在
+	codeSlotSkipped    = -32007 // 槽位被跳过或没有区块
+)
+
+// MockHelius 进程内的Helius模拟服务，同一个地址上提供：
+//   - WebSocket订阅: slotSubscribe、blockSubscribe 等 *Subscribe 方法，通过 NotifySlot/Notify 推送通知
+//   - HTTP JSON-RPC: getBlock、getSlot、getBlockHeight，支持批量请求
+//   - Enhanced API: POST /v0/transactions，按签名返回预先设置的解析结果
+//
+// 没有设置的区块返回槽位被跳过的错误，没有设置的签名不出现在解析结果中，与真实服务一致
+type MockHelius struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu           sync.Mutex
+	slot         uint64
+	blocks       map[uint64]json.RawMessage
+	transactions map[string]json.RawMessage
+	requests     map[string]int
+	conns        map[*mockConn]struct{}
+	nextSubID    int
+}
+
+// mockConn 一个WebSocket连接及其订阅，订阅ID -> 通知方法名
+type mockConn struct {
+	conn          *websocket.Conn
+	writeMu       sync.Mutex
+	subscriptions map[int]string
+}
+
+// rpcRequest JSON-RPC请求
+type rpcRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+// rpcError JSON-RPC错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcResponse JSON-RPC响应
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// NewMockHelius 启动模拟服务，测试结束时自动关闭
+func NewMockHelius(tb testing.TB) *MockHelius {
+	tb.Helper()
+	m := &MockHelius{
+		blocks:       make(map[uint64]json.RawMessage),
+		transactions: make(map[string]json.RawMessage),
+		requests:     make(map[string]int),
+		conns:        make(map[*mockConn]struct{}),
+		nextSubID:    1,
+	}
+	m.server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	tb.Cleanup(m.Close)
+	return m
+}
+
+// URL 返回HTTP地址，用作 helius_api.endpoint 和 helius_enhanced_api.endpoint
+func (m *MockHelius) URL() string {
+	return m.server.URL
+}
+
+// WebSocketURL 返回WebSocket地址，用作 websocket.endpoint
+func (m *MockHelius) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(m.server.URL, "http")
+}
+
+// Close 断开所有WebSocket连接并关闭服务
+func (m *MockHelius) Close() {
+	m.mu.Lock()
+	for c := range m.conns {
+		c.conn.Close()
+	}
+	m.mu.Unlock()
+	m.server.Close()
+}
+
+// SetSlot 设置 getSlot 返回的槽位
+func (m *MockHelius) SetSlot(slot uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slot = slot
+}
+
+// AddBlock 设置 getBlock 返回的区块，block 为 getBlock 的 result，可使用 BlockFixture 生成
+func (m *MockHelius) AddBlock(slot uint64, block json.RawMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocks[slot] = block
+	if slot > m.slot {
+		m.slot = slot
+	}
+}
+
+// AddTransaction 设置 /v0/transactions 返回的解析结果，transaction 可使用 ParsedTransactionFixture 生成
+func (m *MockHelius) AddTransaction(signature string, transaction json.RawMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transactions[signature] = transaction
+}
+
+// Requests 返回收到的请求数，键为JSON-RPC方法名(如 getBlock、slotSubscribe)或Enhanced API路径(/v0/transactions)
+func (m *MockHelius) Requests(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[method]
+}
+
+// Subscribed 返回是否有连接订阅了指定的通知，如 slotNotification
+func (m *MockHelius) Subscribed(notification string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for c := range m.conns {
+		for _, subscribed := range c.subscriptions {
+			if subscribed == notification {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NotifySlot 向订阅了槽位的连接推送槽位通知
+func (m *MockHelius) NotifySlot(slot uint64) error {
+	parent := uint64(0)
+	if slot > 0 {
+		parent = slot - 1
+	}
+	return m.Notify("slotNotification", map[string]uint64{"slot": slot, "parent": parent, "root": parent})
+}
+
+// Notify 向订阅了指定通知的每个订阅推送通知，result 为通知中的 result 字段
+// 返回:
+//   - error: 没有连接订阅该通知或推送失败时的错误信息
+func (m *MockHelius) Notify(notification string, result interface{}) error {
+	type target struct {
+		conn         *mockConn
+		subscription int
+	}
+	m.mu.Lock()
+	var targets []target
+	for c := range m.conns {
+		for subscription, subscribed := range c.subscriptions {
+			if subscribed == notification {
+				targets = append(targets, target{conn: c, subscription: subscription})
+			}
+		}
+	}
+	m.mu.Unlock()
+	if len(targets) == 0 {
+		return fmt.Errorf("没有连接订阅 %s", notification)
+	}
+	for _, t := range targets {
+		message := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  notification,
+			"params":  map[string]interface{}{"subscription": t.subscription, "result": result},
+		}
+		if err := t.conn.write(message); err != nil {
+			return fmt.Errorf("推送 %s 失败: %w", notification, err)
+		}
+	}
+	return nil
+}
+
+// serveHTTP 区分WebSocket升级、Enhanced API和JSON-RPC请求
+func (m *MockHelius) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case websocket.IsWebSocketUpgrade(r):
+		m.serveWebSocket(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/v0/transactions":
+		m.serveTransactions(w, r)
+	case r.Method == http.MethodPost:
+		m.serveRPC(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveTransactions 按签名返回预先设置的解析结果
+func (m *MockHelius) serveTransactions(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Transactions []string `json:"transactions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	m.requests[r.URL.Path]++
+	parsed := make([]json.RawMessage, 0, len(request.Transactions))
+	for _, signature := range request.Transactions {
+		if transaction, ok := m.transactions[signature]; ok {
+			parsed = append(parsed, transaction)
+		}
+	}
+	m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(parsed)
+}
+
+// serveRPC 处理单个或批量JSON-RPC请求
+func (m *MockHelius) serveRPC(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		var requests []rpcRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses := make([]rpcResponse, 0, len(requests))
+		for _, request := range requests {
+			responses = append(responses, m.call(request))
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+	var request rpcRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(m.call(request))
+}
+
+// call 执行一个JSON-RPC方法
+func (m *MockHelius) call(request rpcRequest) rpcResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[request.Method]++
+	response := rpcResponse{JSONRPC: "2.0", ID: request.ID}
+	switch request.Method {
+	case "getSlot", "getBlockHeight":
+		response.Result = m.slot
+	case "getBlock":
+		var slot uint64
+		if len(request.Params) > 0 {
+			json.Unmarshal(request.Params[0], &slot)
+		}
+		if block, ok := m.blocks[slot]; ok {
+			response.Result = block
+		} else {
+			response.Error = &rpcError{Code: codeSlotSkipped, Message: fmt.Sprintf("Slot %d was skipped, or missing in long-term storage", slot)}
+		}
+	default:
+		response.Error = &rpcError{Code: codeMethodNotFound, Message: "Method not found: " + request.Method}
+	}
+	return response
+}
+
+// serveWebSocket 接受订阅和取消订阅请求，订阅ID在所有连接中递增，取消订阅时订阅ID需属于该连接且方法匹配
+func (m *MockHelius) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := m.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &mockConn{conn: conn, subscriptions: make(map[int]string)}
+	m.mu.Lock()
+	m.conns[c] = struct{}{}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.conns, c)
+		m.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var request rpcRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			continue
+		}
+		response := rpcResponse{JSONRPC: "2.0", ID: request.ID}
+		m.mu.Lock()
+		m.requests[request.Method]++
+		switch {
+		case strings.HasSuffix(request.Method, "Unsubscribe"):
+			notification := strings.TrimSuffix(request.Method, "Unsubscribe") + "Notification"
+			var subscription int
+			if len(request.Params) > 0 {
+				json.Unmarshal(request.Params[0], &subscription)
+			}
+			if c.subscriptions[subscription] == notification {
+				delete(c.subscriptions, subscription)
+				response.Result = true
+			} else {
+				response.Error = &rpcError{Code: codeInvalidParams, Message: "Invalid subscription id."}
+			}
+		case strings.HasSuffix(request.Method, "Subscribe"):
+			notification := strings.TrimSuffix(request.Method, "Subscribe") + "Notification"
+			c.subscriptions[m.nextSubID] = notification
+			response.Result = m.nextSubID
+			m.nextSubID++
+		default:
+			response.Error = &rpcError{Code: codeMethodNotFound, Message: "Method not found: " + request.Method}
+		}
+		m.mu.Unlock()
+		if err := c.write(response); err != nil {
+			return
+		}
+	}
+}
+
+// write 写入一条JSON消息，同一连接的写入需要串行
+func (c *mockConn) write(message interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return c.conn.WriteJSON(message)
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/metrics"
+	"github.com/life2you/datas-go/monitor"
+)
+
+// errAdminUnavailable 管理接口返回503，对应的功能未启用
+var errAdminUnavailable = errors.New("功能未启用")
+
+// topOptions top 命令的参数
+type topOptions struct {
+	addr     string        // 管理接口地址
+	interval time.Duration // 刷新间隔
+	errors   int           // 显示的最近错误条数
+}
+
+// newTopCommand 终端仪表盘，定时查询管理接口并刷新显示
+func newTopCommand() *cobra.Command {
+	var options topOptions
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "在终端中实时显示运行中服务的队列、处理速度、API密钥用量、延迟和最近的错误",
+		Long: `定时查询运行中服务的管理接口(/stats/lag、/admin/diagnostics、/stats/api-keys)并刷新显示，
+不需要查看日志即可了解服务状态。
+
+按键:
+  q、Ctrl+C  退出
+  r          立即刷新`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTop(options)
+		},
+	}
+	cmd.Flags().StringVar(&options.addr, "addr", "", "管理接口地址，默认使用配置中的 admin.addr")
+	cmd.Flags().DurationVar(&options.interval, "interval", 2*time.Second, "刷新间隔")
+	cmd.Flags().IntVar(&options.errors, "errors", 8, "显示的最近错误条数")
+	return cmd
+}
+
+// topSnapshot 一次刷新查询到的数据，查询失败的部分记录错误
+type topSnapshot struct {
+	at             time.Time
+	lag            *metrics.LagStats
+	lagErr         error
+	diagnostics    *monitor.Diagnostics
+	diagnosticsErr error
+	usage          []monitor.APIKeyUsage
+	usageErr       error
+}
+
+// runTop 进入终端仪表盘，直到按下q或收到中断信号
+func runTop(options topOptions) error {
+	if options.addr == "" {
+		loadConfig()
+		adminConfig := configs.GlobalConfig.Admin
+		if !adminConfig.Enabled {
+			return fmt.Errorf("未启用管理接口，可通过 --addr 指定地址")
+		}
+		options.addr = adminConfig.Addr
+	}
+	if options.interval <= 0 {
+		return fmt.Errorf("--interval 必须大于0")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 标准输入是终端时进入原始模式读取按键，否则只能通过中断信号退出
+	refresh := make(chan struct{}, 1)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("设置终端原始模式失败: %w", err)
+		}
+		defer term.Restore(fd, state)
+		go readTopKeys(stop, refresh)
+	}
+	// 使用备用屏幕并隐藏光标，退出后恢复原来的终端内容
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	ticker := time.NewTicker(options.interval)
+	defer ticker.Stop()
+	var previous *topSnapshot
+	for {
+		snapshot := fetchTopSnapshot(ctx, client, options.addr)
+		if ctx.Err() != nil {
+			return nil
+		}
+		width := 120
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			width = w
+		}
+		os.Stdout.Write(renderTop(options, snapshot, previous, width))
+		previous = snapshot
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-refresh:
+		}
+	}
+}
+
+// readTopKeys 读取按键，q或Ctrl+C退出，r立即刷新
+func readTopKeys(stop context.CancelFunc, refresh chan<- struct{}) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(buf); err != nil {
+			return
+		}
+		switch buf[0] {
+		case 'q', 'Q', 3: // 3 为原始模式下的Ctrl+C
+			stop()
+			return
+		case 'r', 'R':
+			select {
+			case refresh <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// fetchTopSnapshot 查询仪表盘需要的数据
+func fetchTopSnapshot(ctx context.Context, client *http.Client, addr string) *topSnapshot {
+	snapshot := &topSnapshot{at: time.Now()}
+	var lag metrics.LagStats
+	if snapshot.lagErr = getAdminJSON(ctx, client, addr, "/stats/lag", &lag); snapshot.lagErr == nil {
+		snapshot.lag = &lag
+	}
+	var diagnostics monitor.Diagnostics
+	if snapshot.diagnosticsErr = getAdminJSON(ctx, client, addr, "/admin/diagnostics", &diagnostics); snapshot.diagnosticsErr == nil {
+		snapshot.diagnostics = &diagnostics
+	}
+	snapshot.usageErr = getAdminJSON(ctx, client, addr, "/stats/api-keys", &snapshot.usage)
+	return snapshot
+}
+
+// getAdminJSON 请求管理接口并解析JSON响应
+// 返回:
+//   - error: 服务端返回503时返回 errAdminUnavailable，其他非200状态返回服务端的错误信息
+func getAdminJSON(ctx context.Context, client *http.Client, addr, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("服务未运行或管理接口不可达")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return errAdminUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return fmt.Errorf("管理接口返回错误: %s", failure.Error)
+		}
+		return fmt.Errorf("管理接口返回状态码 %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("解析管理接口响应失败: %w", err)
+	}
+	return nil
+}
+
+// renderTop 绘制一帧仪表盘，previous 为上一次刷新的数据，用于计算处理速度
+func renderTop(options topOptions, snapshot, previous *topSnapshot, width int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&buf, "\x1b[1mdatas-go top\x1b[0m  %s  %s  每%s刷新  q退出 r刷新\n\n",
+		options.addr, snapshot.at.Format("15:04:05"), options.interval)
+
+	// 延迟和处理速度
+	section(&buf, "延迟")
+	if snapshot.lag == nil {
+		fmt.Fprintf(&buf, "  %v\n", snapshot.lagErr)
+	} else {
+		lag := snapshot.lag
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  网络槽位\t%d\t已处理槽位\t%d\n", lag.NetworkSlot, lag.ProcessedSlot)
+		lagSeconds := fmt.Sprintf("%.1fs", lag.LagSeconds)
+		if lag.LagEstimated {
+			lagSeconds += "(估算)"
+		}
+		// 颜色控制符会被计入列宽，只在行的最后一列使用颜色
+		fmt.Fprintf(w, "  落后时长\t%s\t落后槽位\t%s\n", lagSeconds, colorize(fmt.Sprint(lag.LagSlots), lag.LagSlots > 150))
+		processed := "-"
+		if previous != nil && previous.lag != nil && lag.ProcessedSlot >= previous.lag.ProcessedSlot {
+			if elapsed := snapshot.at.Sub(previous.at).Seconds(); elapsed > 0 {
+				processed = fmt.Sprintf("%.2f", float64(lag.ProcessedSlot-previous.lag.ProcessedSlot)/elapsed)
+			}
+		}
+		fmt.Fprintf(w, "  出块速度\t%.2f 槽位/秒\t处理速度\t%s 槽位/秒\n", lag.SlotRate, processed)
+		w.Flush()
+	}
+	buf.WriteString("\n")
+
+	// 队列深度
+	section(&buf, "队列")
+	if snapshot.diagnostics == nil {
+		fmt.Fprintf(&buf, "  %v\n", snapshot.diagnosticsErr)
+	} else {
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  名称\t后端\t长度\t最久等待\t距上次出队")
+		for _, queue := range snapshot.diagnostics.Queues {
+			if queue.Error != "" {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", queue.Name, queue.Backend, colorize(queue.Error, true))
+				continue
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%s\n", queue.Name, queue.Backend, queue.Length,
+				formatMillis(queue.OldestAgeMs), formatMillis(queue.StalenessMs))
+		}
+		w.Flush()
+		fmt.Fprintf(&buf, "  协程数 %d", snapshot.diagnostics.Goroutines.Total)
+		if supervised := len(snapshot.diagnostics.Goroutines.Supervised); supervised > 0 {
+			fmt.Fprintf(&buf, "，%s", colorize(fmt.Sprintf("%d 个受监管协程发生过panic", supervised), true))
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+
+	// Enhanced API密钥用量
+	section(&buf, "Enhanced API密钥")
+	if snapshot.usageErr != nil {
+		fmt.Fprintf(&buf, "  %v\n", snapshot.usageErr)
+	} else {
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  #\t密钥\t今日用量\t剩余\t本进程请求\t本进程失败\t状态")
+		for _, usage := range snapshot.usage {
+			today := int64(0)
+			if len(usage.Days) > 0 {
+				today = usage.Days[0].Credits
+			}
+			remaining := "不限"
+			if usage.Remaining >= 0 {
+				remaining = fmt.Sprint(usage.Remaining)
+			}
+			fmt.Fprintf(w, "  %d\t%s\t%d\t%s\t%d\t%d\t%s\n", usage.Index, usage.Key, today, remaining,
+				usage.Process.Requests, usage.Process.Errors, keyStatus(usage))
+		}
+		w.Flush()
+	}
+	buf.WriteString("\n")
+
+	// 最近的错误日志
+	section(&buf, "最近的错误")
+	switch {
+	case snapshot.diagnostics == nil:
+		fmt.Fprintf(&buf, "  %v\n", snapshot.diagnosticsErr)
+	case len(snapshot.diagnostics.RecentErrors) == 0:
+		buf.WriteString("  无\n")
+	default:
+		for i, entry := range snapshot.diagnostics.RecentErrors {
+			if i >= options.errors {
+				break
+			}
+			line := fmt.Sprintf("  %s %s %s", entry.Time.Local().Format("15:04:05"), entry.Logger, entry.Message)
+			buf.WriteString(truncate(line, width) + "\n")
+		}
+	}
+
+	// 原始模式下换行不会回到行首
+	return bytes.ReplaceAll(buf.Bytes(), []byte("\n"), []byte("\r\n"))
+}
+
+// section 输出分区标题
+func section(buf *bytes.Buffer, title string) {
+	fmt.Fprintf(buf, "\x1b[1;36m%s\x1b[0m\n", title)
+}
+
+// colorize 需要关注的值以红色显示
+func colorize(text string, alert bool) string {
+	if !alert {
+		return text
+	}
+	return "\x1b[31m" + text + "\x1b[0m"
+}
+
+// keyStatus 返回密钥的状态文本
+func keyStatus(usage monitor.APIKeyUsage) string {
+	switch {
+	case !usage.Healthy:
+		return colorize("隔离中: "+usage.QuarantineReason, true)
+	case usage.Exhausted:
+		return colorize("已达预算", true)
+	default:
+		return "正常"
+	}
+}
+
+// formatMillis 将毫秒数格式化为时长，0显示为 -
+func formatMillis(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return (time.Duration(ms) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
+
+// truncate 按终端宽度截断一行，避免折行打乱布局
+func truncate(line string, width int) string {
+	line = strings.ReplaceAll(line, "\n", " ")
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:max(width-1, 0)]) + "…"
+}
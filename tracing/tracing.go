@@ -0,0 +1,260 @@
+// Package tracing 使用OpenTelemetry记录槽位从收到通知到解析结果写入的完整链路
+// 收到槽位通知时为槽位创建根span，区块获取、签名分批、Enhanced API解析和结果写入都作为它的子span，
+// 区块进入终态时结束根span，通过OTLP导出后可以按槽位查看各阶段的耗时。未启用时所有函数都不做任何处理
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/configs"
+)
+
+// instrumentationName 本项目创建的span所属的instrumentation名称
+const instrumentationName = "github.com/life2you/datas-go"
+
+var (
+	mu       sync.RWMutex
+	tracer   trace.Tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	provider *sdktrace.TracerProvider
+	slots    *slotSpans
+)
+
+// propagator 队列元素中的链路上下文使用W3C traceparent格式
+var propagator = propagation.TraceContext{}
+
+// Init 按配置创建OTLP导出器并启用链路追踪，未启用时不做任何处理
+// 参数:
+//   - config: 链路追踪配置
+//   - instance: 当前实例ID，记录为 service.instance.id
+//
+// 返回:
+//   - error: 创建导出器失败时返回错误
+func Init(config *configs.TracingConfig, instance string) error {
+	if !config.Enabled {
+		return nil
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.URLPath != "" {
+		options = append(options, otlptracehttp.WithURLPath(config.URLPath))
+	}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(config.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return fmt.Errorf("创建OTLP导出器失败: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", config.ServiceName),
+		attribute.String("service.instance.id", instance),
+	))
+	if err != nil {
+		return fmt.Errorf("创建链路追踪资源失败: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+
+	mu.Lock()
+	defer mu.Unlock()
+	provider = tp
+	tracer = tp.Tracer(instrumentationName)
+	slots = newSlotSpans(config.MaxSlots, config.SlotTimeout)
+	return nil
+}
+
+// Shutdown 结束所有未结束的槽位span并导出剩余的span，未启用时不做任何处理
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	tp, spans := provider, slots
+	provider, slots = nil, nil
+	tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	mu.Unlock()
+	if tp == nil {
+		return nil
+	}
+	spans.endAll("服务退出")
+	return tp.Shutdown(ctx)
+}
+
+// Start 在 ctx 中的span下创建子span，ctx 中没有span时创建新的链路
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	mu.RLock()
+	t := tracer
+	mu.RUnlock()
+	return t.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 结束span，err 不为nil时记录错误并将状态设置为失败
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// StartSlot 收到槽位通知时创建槽位的根span，槽位已有根span时不做任何处理
+func StartSlot(slot uint64) {
+	mu.RLock()
+	spans, t := slots, tracer
+	mu.RUnlock()
+	if spans != nil {
+		spans.start(t, slot)
+	}
+}
+
+// SlotEvent 在槽位的根span上记录事件，如区块状态变化
+func SlotEvent(slot uint64, name string) {
+	if span := slotSpan(slot); span != nil {
+		span.AddEvent(name)
+	}
+}
+
+// EndSlot 区块进入终态时结束槽位的根span
+func EndSlot(slot uint64, err error) {
+	mu.RLock()
+	spans := slots
+	mu.RUnlock()
+	if spans == nil {
+		return
+	}
+	if span := spans.remove(slot); span != nil {
+		End(span, err)
+	}
+}
+
+// SlotContext 返回以槽位链路为父级的上下文：本实例有该槽位的根span时使用根span，
+// 否则使用队列元素中携带的 traceParent(由其他实例创建)，都没有时返回原上下文
+func SlotContext(ctx context.Context, slot uint64, traceParent string) context.Context {
+	if span := slotSpan(slot); span != nil {
+		return trace.ContextWithSpan(ctx, span)
+	}
+	if traceParent != "" {
+		return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+	}
+	return ctx
+}
+
+// TraceParent 返回 ctx 中链路的W3C traceparent，写入队列元素后由取出的实例继续同一条链路，没有链路时返回空字符串
+func TraceParent(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier["traceparent"]
+}
+
+// slotSpan 返回槽位的根span，没有时返回nil
+func slotSpan(slot uint64) trace.Span {
+	mu.RLock()
+	spans := slots
+	mu.RUnlock()
+	if spans == nil {
+		return nil
+	}
+	return spans.get(slot)
+}
+
+// slotEntry 进行中的槽位根span
+type slotEntry struct {
+	span    trace.Span
+	started time.Time
+}
+
+// slotSpans 进行中的槽位根span，超过 timeout 仍未结束的span在容量不足时结束，避免跳过或丢失的槽位一直占用内存
+type slotSpans struct {
+	mu      sync.Mutex
+	max     int
+	timeout time.Duration
+	entries map[uint64]slotEntry
+}
+
+// newSlotSpans 创建槽位根span表
+func newSlotSpans(max int, timeout time.Duration) *slotSpans {
+	return &slotSpans{max: max, timeout: timeout, entries: make(map[uint64]slotEntry)}
+}
+
+// start 创建槽位的根span，达到容量上限时先结束超时的span，仍然没有空间时不记录该槽位
+func (s *slotSpans) start(t trace.Tracer, slot uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[slot]; ok {
+		return
+	}
+	now := clock.Now()
+	if len(s.entries) >= s.max {
+		for expired, entry := range s.entries {
+			if now.Sub(entry.started) >= s.timeout {
+				entry.span.SetStatus(codes.Error, "槽位处理超时")
+				entry.span.End()
+				delete(s.entries, expired)
+			}
+		}
+		if len(s.entries) >= s.max {
+			return
+		}
+	}
+	// 槽位之间没有父子关系，每个槽位是一条新的链路
+	_, span := t.Start(context.Background(), "slot",
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.Int64("solana.slot", int64(slot))))
+	s.entries[slot] = slotEntry{span: span, started: now}
+}
+
+// get 返回槽位的根span
+func (s *slotSpans) get(slot uint64) trace.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[slot]; ok {
+		return entry.span
+	}
+	return nil
+}
+
+// remove 移除并返回槽位的根span
+func (s *slotSpans) remove(slot uint64) trace.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[slot]
+	if !ok {
+		return nil
+	}
+	delete(s.entries, slot)
+	return entry.span
+}
+
+// endAll 结束所有进行中的槽位根span
+func (s *slotSpans) endAll(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for slot, entry := range s.entries {
+		entry.span.SetStatus(codes.Error, reason)
+		entry.span.End()
+		delete(s.entries, slot)
+	}
+}
@@ -0,0 +1,190 @@
+package watchlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/pipeline"
+)
+
+// 每个SOL对应的lamports数量
+const lamportsPerSOL = 1e9
+
+// 通知支持的事件类型，只有交易、PumpPortal和代币相关的事件带有地址
+var eventTypes = []pipeline.EventType{pipeline.EventTransaction, pipeline.EventPumpPortal, pipeline.EventOrderFlow, pipeline.EventGraduation, pipeline.EventPoolCreated}
+
+// 通知支持的告警级别
+var severities = []string{"info", "warning", "critical"}
+
+// Preferences 关注地址的通知设置，各条件之间为"与"关系，为空的条件不参与判断
+type Preferences struct {
+	Channels         []string               `json:"channels,omitempty"`          // 通知发布到的Redis频道，为空时只记录告警
+	Severity         string                 `json:"severity,omitempty"`          // 告警级别，默认 warning
+	EventTypes       []pipeline.EventType   `json:"event_types,omitempty"`       // 通知的事件类型
+	TransactionTypes []resp.TransactionType `json:"transaction_types,omitempty"` // 交易类型，仅对交易事件生效
+	MinSOL           float64                `json:"min_sol,omitempty"`           // 地址涉及的SOL金额下限
+	MinTokenAmount   float64                `json:"min_token_amount,omitempty"`  // 地址涉及的代币数量下限，与 min_sol 满足其一即可
+	QuietHours       *QuietHours            `json:"quiet_hours,omitempty"`       // 免打扰时段，期间只记录告警不发布到频道
+}
+
+// QuietHours 每天的免打扰时段，结束时间早于开始时间表示跨越午夜
+type QuietHours struct {
+	Start    string `json:"start"`              // 开始时间，格式 HH:MM
+	End      string `json:"end"`                // 结束时间，格式 HH:MM
+	Timezone string `json:"timezone,omitempty"` // IANA时区，为空时使用UTC
+}
+
+// validate 校验通知设置，返回所有问题
+func (p *Preferences) validate() []string {
+	var problems []string
+	if p.Severity == "" {
+		p.Severity = "warning"
+	}
+	if !slices.Contains(severities, p.Severity) {
+		problems = append(problems, fmt.Sprintf("preferences.severity 无效: %q，可选值: info, warning, critical", p.Severity))
+	}
+	for _, eventType := range p.EventTypes {
+		if !slices.Contains(eventTypes, eventType) {
+			problems = append(problems, fmt.Sprintf("preferences.event_types 无效: %q", eventType))
+		}
+	}
+	for _, channel := range p.Channels {
+		if strings.TrimSpace(channel) == "" {
+			problems = append(problems, "preferences.channels 不能包含空频道")
+			break
+		}
+	}
+	if p.MinSOL < 0 || p.MinTokenAmount < 0 {
+		problems = append(problems, "preferences.min_sol 和 preferences.min_token_amount 不能为负数")
+	}
+	if p.QuietHours != nil {
+		if _, _, err := p.QuietHours.parse(); err != nil {
+			problems = append(problems, "preferences.quiet_hours "+err.Error())
+		}
+		if _, err := time.LoadLocation(p.QuietHours.Timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("preferences.quiet_hours.timezone 无效: %q", p.QuietHours.Timezone))
+		}
+	}
+	return problems
+}
+
+// Matches 判断事件是否需要通知关注的地址
+// 参数:
+//   - event: 事件
+//   - address: 关注的地址，事件涉及的账户或代币
+//
+// 返回:
+//   - bool: 是否需要通知
+func (p *Preferences) Matches(event pipeline.Event, address string) bool {
+	if len(p.EventTypes) > 0 && !slices.Contains(p.EventTypes, event.Type) {
+		return false
+	}
+	if len(p.TransactionTypes) > 0 && (event.Transaction == nil || !slices.Contains(p.TransactionTypes, event.Transaction.Type)) {
+		return false
+	}
+	if p.MinSOL <= 0 && p.MinTokenAmount <= 0 {
+		return true
+	}
+	sol, token := Amounts(event, address)
+	return (p.MinSOL > 0 && sol >= p.MinSOL) || (p.MinTokenAmount > 0 && token >= p.MinTokenAmount)
+}
+
+// Quiet 判断指定时间是否处于免打扰时段
+func (p *Preferences) Quiet(now time.Time) bool {
+	if p.QuietHours == nil {
+		return false
+	}
+	return p.QuietHours.Contains(now)
+}
+
+// Contains 判断指定时间是否处于免打扰时段，配置无效时返回false
+func (q *QuietHours) Contains(now time.Time) bool {
+	start, end, err := q.parse()
+	if err != nil {
+		return false
+	}
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// parse 将开始和结束时间转换为当天的分钟数
+func (q *QuietHours) parse() (int, int, error) {
+	start, err := time.Parse("15:04", q.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("start 格式无效: %q，应为 HH:MM", q.Start)
+	}
+	end, err := time.Parse("15:04", q.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("end 格式无效: %q，应为 HH:MM", q.End)
+	}
+	if start.Equal(end) {
+		return 0, 0, fmt.Errorf("start 和 end 不能相同")
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), nil
+}
+
+// Amounts 返回事件中与地址相关的SOL金额和代币数量
+// 交易事件累加地址转入和转出的金额，地址为代币时累加该代币的转账数量；PumpPortal事件取交易者或代币匹配时的成交金额
+// 参数:
+//   - event: 事件
+//   - address: 账户或代币地址
+//
+// 返回:
+//   - float64: SOL金额
+//   - float64: 代币数量
+func Amounts(event pipeline.Event, address string) (float64, float64) {
+	var sol, token decimal.Decimal
+	switch event.Type {
+	case pipeline.EventTransaction:
+		transaction := event.Transaction
+		if transaction == nil {
+			return 0, 0
+		}
+		var lamports int64
+		for _, transfer := range transaction.NativeTransfers {
+			if transfer.FromUserAccount == address || transfer.ToUserAccount == address {
+				lamports += transfer.Amount
+			}
+		}
+		sol = decimal.NewFromInt(lamports).Div(decimal.NewFromInt(lamportsPerSOL))
+		for _, transfer := range transaction.TokenTransfers {
+			if transfer.FromUserAccount == address || transfer.ToUserAccount == address || transfer.Mint == address {
+				token = token.Add(transfer.TokenAmount.Abs())
+			}
+		}
+	case pipeline.EventPumpPortal:
+		var message struct {
+			Mint            string          `json:"mint"`
+			TraderPublicKey string          `json:"traderPublicKey"`
+			SolAmount       decimal.Decimal `json:"solAmount"`
+			TokenAmount     decimal.Decimal `json:"tokenAmount"`
+			InitialBuy      decimal.Decimal `json:"initialBuy"`
+		}
+		if err := json.Unmarshal(event.Raw, &message); err != nil {
+			return 0, 0
+		}
+		if message.TraderPublicKey != address && message.Mint != address {
+			return 0, 0
+		}
+		sol = message.SolAmount
+		token = message.TokenAmount
+		if token.IsZero() {
+			token = message.InitialBuy
+		}
+	}
+	return sol.InexactFloat64(), token.InexactFloat64()
+}
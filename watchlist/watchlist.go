@@ -0,0 +1,272 @@
+package watchlist
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/life2you/datas-go/clock"
+	"github.com/life2you/datas-go/logger"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/supervisor"
+)
+
+// GlobalWatchlist 全局关注地址列表，未启用规则引擎时为nil
+var GlobalWatchlist *Watchlist
+
+// ErrEntryNotFound 关注地址不存在
+var ErrEntryNotFound = errors.New("关注地址不存在")
+
+// Entry 关注的地址及其通知设置
+type Entry struct {
+	Address     string      `json:"address"`         // 关注的账户或代币地址
+	Label       string      `json:"label,omitempty"` // 备注，用于告警内容
+	Enabled     bool        `json:"enabled"`         // 是否启用通知
+	Preferences Preferences `json:"preferences"`     // 通知设置
+	CreatedAt   time.Time   `json:"created_at"`      // 创建时间
+	UpdatedAt   time.Time   `json:"updated_at"`      // 更新时间
+}
+
+// Validate 校验关注地址
+func (e *Entry) Validate() error {
+	var problems []string
+	if strings.TrimSpace(e.Address) == "" {
+		problems = append(problems, "address 不能为空")
+	}
+	problems = append(problems, e.Preferences.validate()...)
+	if len(problems) > 0 {
+		return fmt.Errorf("关注地址无效: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Name 告警中展示的名称，有备注时使用备注
+func (e *Entry) Name() string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return e.Address
+}
+
+// Watchlist 关注地址列表，保存在Redis中并在内存中缓存，修改后通过Redis通知其他实例重新加载
+type Watchlist struct {
+	mu        sync.RWMutex
+	entries   map[string]Entry
+	listeners []func() // 地址集合变化后调用
+	log       *zap.Logger
+	cancel    context.CancelFunc
+}
+
+// NewWatchlist 创建关注地址列表并设置为全局实例
+func NewWatchlist() *Watchlist {
+	watchlist := &Watchlist{
+		entries: make(map[string]Entry),
+		log:     logger.Named("watchlist"),
+	}
+	GlobalWatchlist = watchlist
+	return watchlist
+}
+
+// redis 关注地址与规则一样使用缓存负载的Redis
+func (w *Watchlist) redis() *storage.RedisClient {
+	return storage.GetRedisClient(storage.WorkloadCache)
+}
+
+// Start 加载关注地址并监听其他实例的变更
+// 返回:
+//   - error: 加载失败时的错误信息
+func (w *Watchlist) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	if err := w.Reload(ctx); err != nil {
+		cancel()
+		return err
+	}
+
+	pubsub := w.redis().SubscribeWatchlistChanges(ctx)
+	go func() {
+		defer pubsub.Close()
+		supervisor.Run(ctx, "watchlist.reload", func(ctx context.Context) {
+			for range pubsub.Channel() {
+				if err := w.Reload(ctx); err != nil {
+					w.log.Error("重新加载关注地址失败", zap.Error(err))
+				}
+			}
+		})
+	}()
+
+	w.log.Info("关注地址列表已加载", zap.Int("地址数", len(w.Entries())))
+	return nil
+}
+
+// Close 停止监听变更
+func (w *Watchlist) Close() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+}
+
+// Reload 从Redis重新加载所有关注地址，无法解析的记录会被跳过
+func (w *Watchlist) Reload(ctx context.Context) error {
+	values, err := w.redis().GetWatchlist(ctx)
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]Entry, len(values))
+	for address, value := range values {
+		var entry Entry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			w.log.Error("解析关注地址失败，已跳过", zap.String("address", address), zap.Error(err))
+			continue
+		}
+		entries[address] = entry
+	}
+
+	w.mu.Lock()
+	changed := len(entries) != len(w.entries)
+	for address := range entries {
+		if _, ok := w.entries[address]; !ok {
+			changed = true
+		}
+	}
+	w.entries = entries
+	w.mu.Unlock()
+	if changed {
+		w.notify()
+	}
+	return nil
+}
+
+// OnChange 注册地址集合变化的回调，新增或删除地址(包括其他实例的修改)后调用，只修改通知设置时不调用
+// 回调在修改地址的协程中同步执行，耗时的操作应交给其他协程
+func (w *Watchlist) OnChange(fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// notify 调用地址集合变化的回调
+func (w *Watchlist) notify() {
+	w.mu.RLock()
+	listeners := slices.Clone(w.listeners)
+	w.mu.RUnlock()
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+// Entries 返回所有关注地址，按创建时间排序
+func (w *Watchlist) Entries() []Entry {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	entries := make([]Entry, 0, len(w.entries))
+	for _, entry := range w.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.Address, b.Address))
+	})
+	return entries
+}
+
+// Addresses 返回所有关注的地址，包括未启用通知的地址，按地址排序
+func (w *Watchlist) Addresses() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	addresses := make([]string, 0, len(w.entries))
+	for address := range w.entries {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+// Get 获取指定地址的关注设置
+func (w *Watchlist) Get(address string) (Entry, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	entry, ok := w.entries[address]
+	if !ok {
+		return Entry{}, ErrEntryNotFound
+	}
+	return entry, nil
+}
+
+// Lookup 返回addresses中被关注且已启用通知的地址，每个地址只返回一次
+func (w *Watchlist) Lookup(addresses []string) []Entry {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if len(w.entries) == 0 {
+		return nil
+	}
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		if entry, ok := w.entries[address]; ok && entry.Enabled {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Save 新增或整体替换指定地址的关注设置
+func (w *Watchlist) Save(ctx context.Context, entry Entry) (Entry, error) {
+	if err := entry.Validate(); err != nil {
+		return Entry{}, err
+	}
+	now := clock.Now()
+	entry.CreatedAt = now
+	if existing, err := w.Get(entry.Address); err == nil {
+		entry.CreatedAt = existing.CreatedAt
+	}
+	entry.UpdatedAt = now
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("序列化关注地址失败: %w", err)
+	}
+	if err := w.redis().SaveWatchedAddress(ctx, entry.Address, value); err != nil {
+		return Entry{}, err
+	}
+	w.mu.Lock()
+	_, existed := w.entries[entry.Address]
+	w.entries[entry.Address] = entry
+	w.mu.Unlock()
+	w.log.Info("关注地址已保存", zap.String("address", entry.Address), zap.String("label", entry.Label))
+	if !existed {
+		w.notify()
+	}
+	return entry, nil
+}
+
+// Delete 取消关注指定地址
+func (w *Watchlist) Delete(ctx context.Context, address string) error {
+	deleted, err := w.redis().DeleteWatchedAddress(ctx, address)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	_, existed := w.entries[address]
+	delete(w.entries, address)
+	w.mu.Unlock()
+	if existed {
+		w.notify()
+	}
+	if !deleted {
+		return ErrEntryNotFound
+	}
+	w.log.Info("关注地址已删除", zap.String("address", address))
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/storage"
+	"github.com/life2you/datas-go/watchlist"
+)
+
+// newWatchlistCommand 关注地址管理命令
+// 修改直接写入Redis并通知运行中的实例，由开启同步的实例同步到Helius Webhook和PumpPortal订阅
+func newWatchlistCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchlist",
+		Short: "管理关注地址",
+	}
+	cmd.AddCommand(newWatchlistListCommand(), newWatchlistGetCommand(), newWatchlistAddCommand(), newWatchlistRemoveCommand())
+	return cmd
+}
+
+// newWatchlistListCommand 列出关注地址
+func newWatchlistListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出所有关注地址",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := loadWatchlist()
+			if err != nil {
+				return err
+			}
+			defer storage.CloseRedisClients()
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "地址\t备注\t通知\t创建时间")
+			for _, entry := range list.Entries() {
+				fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", entry.Address, entry.Label, entry.Enabled, entry.CreatedAt.Format(time.DateTime))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newWatchlistGetCommand 查看关注地址的通知设置
+func newWatchlistGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <address>",
+		Short: "查看关注地址的通知设置",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := loadWatchlist()
+			if err != nil {
+				return err
+			}
+			defer storage.CloseRedisClients()
+
+			entry, err := list.Get(args[0])
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(entry)
+		},
+	}
+}
+
+// newWatchlistAddCommand 关注地址，已关注的地址只更新指定的选项
+func newWatchlistAddCommand() *cobra.Command {
+	var (
+		label    string
+		disabled bool
+	)
+	cmd := &cobra.Command{
+		Use:   "add <address>...",
+		Short: "关注一个或多个地址，已关注的地址保留原有的通知设置",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := loadWatchlist()
+			if err != nil {
+				return err
+			}
+			defer storage.CloseRedisClients()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			for _, address := range args {
+				entry, err := list.Get(address)
+				if err != nil {
+					entry = watchlist.Entry{Address: address, Enabled: true}
+				}
+				if cmd.Flags().Changed("label") {
+					entry.Label = label
+				}
+				if cmd.Flags().Changed("disabled") {
+					entry.Enabled = !disabled
+				}
+				if _, err := list.Save(ctx, entry); err != nil {
+					return err
+				}
+				fmt.Printf("已关注: %s\n", address)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&label, "label", "", "备注，用于告警内容")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "只跟踪地址的交易，不发送通知")
+	return cmd
+}
+
+// newWatchlistRemoveCommand 取消关注地址
+func newWatchlistRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <address>...",
+		Short: "取消关注一个或多个地址",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := loadWatchlist()
+			if err != nil {
+				return err
+			}
+			defer storage.CloseRedisClients()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			for _, address := range args {
+				if err := list.Delete(ctx, address); err != nil {
+					return fmt.Errorf("%s: %w", address, err)
+				}
+				fmt.Printf("已取消关注: %s\n", address)
+			}
+			return nil
+		},
+	}
+}
+
+// loadWatchlist 加载配置、连接Redis并读取关注地址，不监听其他实例的变更
+func loadWatchlist() (*watchlist.Watchlist, error) {
+	loadConfig()
+	storage.NewRedisClient(&configs.GlobalConfig.Redis)
+	list := watchlist.NewWatchlist()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := list.Reload(ctx); err != nil {
+		storage.CloseRedisClients()
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/life2you/datas-go/configs"
+	"github.com/life2you/datas-go/models/resp"
+	"github.com/life2you/datas-go/rpc"
+	"github.com/life2you/datas-go/service"
+)
+
+// newWebhookCommand Helius Webhook管理命令
+func newWebhookCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "管理Helius Webhook",
+	}
+	cmd.AddCommand(newWebhookCreateCommand(), newWebhookListCommand(), newWebhookDeleteCommand(), newWebhookSyncCommand(),
+		newWebhookAddressesCommand(true), newWebhookAddressesCommand(false))
+	return cmd
+}
+
+// newWebhookCreateCommand 创建Webhook
+func newWebhookCreateCommand() *cobra.Command {
+	var (
+		callbackURL      string
+		webhookType      string
+		addresses        []string
+		transactionTypes []string
+		authHeader       string
+		upsert           bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "创建Webhook",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := initWebhookClient()
+			if callbackURL == "" {
+				callbackURL = configs.GlobalConfig.HeliusWebhook.CallbackURL
+			}
+			if callbackURL == "" {
+				return fmt.Errorf("必须指定 --url 或配置 helius_webhook.callback_url")
+			}
+			if len(addresses) == 0 {
+				return fmt.Errorf("至少需要指定一个 --address")
+			}
+			types := make([]resp.TransactionType, 0, len(transactionTypes))
+			for _, transactionType := range transactionTypes {
+				types = append(types, resp.TransactionType(strings.ToUpper(transactionType)))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			definition := rpc.Webhook{
+				Webhook:          callbackURL,
+				WebhookType:      rpc.WebhookType(webhookType),
+				AccountAddresses: addresses,
+				TransactionTypes: types,
+				AuthHeader:       authHeader,
+			}
+			if upsert {
+				webhook, created, err := client.UpsertWebhook(ctx, definition)
+				if err != nil {
+					return err
+				}
+				if created {
+					fmt.Printf("已创建Webhook: %s\n", webhook.ID)
+				} else {
+					fmt.Printf("已更新回调URL相同的Webhook: %s\n", webhook.ID)
+				}
+				return nil
+			}
+			webhook, err := client.CreateWebhook(ctx, definition)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已创建Webhook: %s\n", webhook.ID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&callbackURL, "url", "", "回调URL，默认使用 helius_webhook.callback_url")
+	cmd.Flags().StringVar(&webhookType, "type", string(rpc.EnhancedWebhook), "Webhook类型: enhanced, raw, discord, enhancedDevnet, rawDevnet")
+	cmd.Flags().StringSliceVar(&addresses, "address", nil, "监控的地址，可重复指定或以逗号分隔")
+	cmd.Flags().StringSliceVar(&transactionTypes, "transaction-type", []string{string(resp.TransactionTypeAny)}, "监控的交易类型，如 SWAP、TRANSFER、ANY")
+	cmd.Flags().StringVar(&authHeader, "auth-header", "", "回调时携带的Authorization头")
+	cmd.Flags().BoolVar(&upsert, "upsert", false, "已存在回调URL相同的Webhook时修改它而不是新建，重试时不会创建重复的Webhook")
+	return cmd
+}
+
+// newWebhookListCommand 列出Webhook
+func newWebhookListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出所有Webhook",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := initWebhookClient()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			webhooks, err := client.GetWebhooks(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\t类型\t回调URL\t地址数\t交易类型")
+			for _, webhook := range webhooks {
+				types := make([]string, 0, len(webhook.TransactionTypes))
+				for _, transactionType := range webhook.TransactionTypes {
+					types = append(types, string(transactionType))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", webhook.ID, webhook.WebhookType, webhook.Webhook, len(webhook.AccountAddresses), strings.Join(types, ","))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newWebhookDeleteCommand 删除Webhook
+func newWebhookDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>...",
+		Short: "删除一个或多个Webhook",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := initWebhookClient()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			for _, id := range args {
+				if err := client.DeleteWebhook(ctx, id); err != nil {
+					return err
+				}
+				fmt.Printf("已删除Webhook: %s\n", id)
+			}
+			return nil
+		},
+	}
+}
+
+// newWebhookSyncCommand 按配置声明同步Webhook
+func newWebhookSyncCommand() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "按 helius_webhook.webhooks 声明创建/更新/删除Webhook",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := initWebhookClient()
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+			plan, err := service.PlanWebhookSync(ctx, client, &configs.GlobalConfig.HeliusWebhook, configs.GlobalConfig.Watchlist.WebhookID)
+			if err != nil {
+				return err
+			}
+			if plan.Empty() {
+				fmt.Println("Webhook已与配置一致，无需同步")
+				return nil
+			}
+			for _, webhook := range plan.Create {
+				fmt.Printf("+ 创建 %s (%s, %d个地址)\n", webhook.Webhook, webhook.WebhookType, len(webhook.AccountAddresses))
+			}
+			for _, webhook := range plan.Update {
+				fmt.Printf("~ 更新 %s %s (%s, %d个地址)\n", webhook.ID, webhook.Webhook, webhook.WebhookType, len(webhook.AccountAddresses))
+			}
+			for _, webhook := range plan.Delete {
+				fmt.Printf("- 删除 %s %s\n", webhook.ID, webhook.Webhook)
+			}
+			if dryRun {
+				return nil
+			}
+			if err := service.ApplyWebhookSync(ctx, client, plan); err != nil {
+				return err
+			}
+			fmt.Println("同步完成")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只输出同步计划，不执行变更")
+	return cmd
+}
+
+// newWebhookAddressesCommand 向Webhook追加或从Webhook移除地址，add为true时追加
+func newWebhookAddressesCommand(add bool) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "add-addresses <id> [address...]",
+		Short: "向Webhook追加监控地址",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addresses := args[1:]
+			if file != "" {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("读取地址文件失败: %w", err)
+				}
+				addresses = append(addresses, strings.Fields(string(data))...)
+			}
+			if len(addresses) == 0 {
+				return fmt.Errorf("至少需要指定一个地址或 --file")
+			}
+
+			client := initWebhookClient()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			var (
+				webhook *rpc.Webhook
+				err     error
+			)
+			if add {
+				webhook, err = client.AppendAddressesToWebhook(ctx, args[0], addresses)
+			} else {
+				webhook, err = client.RemoveAddressesFromWebhook(ctx, args[0], addresses)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Webhook %s 当前监控 %d 个地址\n", webhook.ID, len(webhook.AccountAddresses))
+			return nil
+		},
+	}
+	if !add {
+		cmd.Use = "remove-addresses <id> [address...]"
+		cmd.Short = "从Webhook移除监控地址"
+	}
+	cmd.Flags().StringVar(&file, "file", "", "地址文件，每行一个地址")
+	return cmd
+}
+
+// initWebhookClient 加载配置并创建Webhook管理客户端
+func initWebhookClient() *rpc.HeliusWebhookClient {
+	loadConfig()
+	applyProxyConfig()
+	return rpc.NewHeliusWebhookClient(&configs.GlobalConfig.HeliusWebhook)
+}